@@ -14,8 +14,10 @@
 
 #include "cache.h"
 
-DBCache* DBNewCache(uint64_t size) {
-  const int num_cache_shard_bits = 4;
+DBCache* DBNewCache(uint64_t size, int num_shard_bits) {
+  const int default_num_cache_shard_bits = 4;
+  const int num_cache_shard_bits =
+      num_shard_bits > 0 ? num_shard_bits : default_num_cache_shard_bits;
   DBCache* cache = new DBCache;
   cache->rep = rocksdb::NewLRUCache(size, num_cache_shard_bits);
   return cache;
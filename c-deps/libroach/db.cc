@@ -16,6 +16,7 @@
 #include <algorithm>
 #include <rocksdb/convenience.h>
 #include <rocksdb/perf_context.h>
+#include <rocksdb/persistent_cache.h>
 #include <rocksdb/sst_file_writer.h>
 #include <rocksdb/table.h>
 #include <stdarg.h>
@@ -161,6 +162,26 @@ DBStatus DBOpen(DBEngine** db, DBSlice dir, DBOptions db_opts) {
     }
   }
 
+  const std::string persistent_cache_path = ToString(db_opts.persistent_cache_path);
+  if (!persistent_cache_path.empty()) {
+    // Back the block cache with a persistent cache on a (presumably faster)
+    // device, which holds blocks evicted from the in-memory cache so that
+    // they needn't be read from the store's own disk again.
+    std::shared_ptr<rocksdb::PersistentCache> persistent_cache;
+    rocksdb::Status status = rocksdb::NewPersistentCache(
+        rocksdb::Env::Default(), persistent_cache_path, db_opts.persistent_cache_size,
+        options.info_log, false /* optimized_for_nvm */, &persistent_cache);
+    if (!status.ok()) {
+      return ToDBStatus(status);
+    }
+    auto table_options =
+        static_cast<rocksdb::BlockBasedTableOptions*>(options.table_factory->GetOptions());
+    if (table_options == nullptr) {
+      return FmtStatus("secondary cache requires a block based table factory");
+    }
+    table_options->persistent_cache = persistent_cache;
+  }
+
   const std::string db_dir = ToString(dir);
 
   // Make the default options.env the default. It points to Env::Default which does not
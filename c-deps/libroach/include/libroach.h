@@ -57,6 +57,9 @@ typedef struct {
   bool with_stats;
   DBTimestamp min_timestamp_hint;
   DBTimestamp max_timestamp_hint;
+  // If positive, the number of bytes to read ahead when the iterator reads
+  // from disk.
+  int64_t readahead_size;
 } DBIterOptions;
 
 typedef struct {
@@ -81,10 +84,16 @@ typedef struct {
   bool read_only;
   DBSlice rocksdb_options;
   DBSlice extra_options;
+  // If non-empty, the directory in which to keep a persistent secondary block
+  // cache of up to persistent_cache_size bytes.
+  DBSlice persistent_cache_path;
+  uint64_t persistent_cache_size;
 } DBOptions;
 
-// Create a new cache with the specified size.
-DBCache* DBNewCache(uint64_t size);
+// Create a new cache with the specified size, split into
+// 2^num_shard_bits shards. A non-positive num_shard_bits selects the
+// default number of shards.
+DBCache* DBNewCache(uint64_t size, int num_shard_bits);
 
 // Add a reference to an existing cache. Note that the underlying
 // RocksDB cache is shared between the original and new reference.
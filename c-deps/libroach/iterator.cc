@@ -26,6 +26,10 @@ DBIterator::DBIterator(std::atomic<int64_t>* iters, DBIterOptions iter_options)
   SetUpperBound(iter_options.upper_bound);
   read_opts.iterate_upper_bound = &upper_bound;
 
+  if (iter_options.readahead_size > 0) {
+    read_opts.readahead_size = iter_options.readahead_size;
+  }
+
   if (!EmptyTimestamp(iter_options.min_timestamp_hint) ||
       !EmptyTimestamp(iter_options.max_timestamp_hint)) {
     assert(!EmptyTimestamp(iter_options.min_timestamp_hint));
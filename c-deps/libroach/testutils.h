@@ -33,6 +33,8 @@ inline DBOptions defaultDBOptions() {
       false,      // read_only
       DBSlice(),  // rocksdb_options
       DBSlice(),  // extra_options
+      DBSlice(),  // persistent_cache_path
+      0,          // persistent_cache_size
   };
 }
 
@@ -41,6 +41,10 @@ import (
 // hard coded to 640MiB.
 const MinimumStoreSize = 10 * 64 << 20
 
+// maxBlockCacheShardBits is the largest number of shard bits RocksDB accepts
+// for a block cache.
+const maxBlockCacheShardBits = 19
+
 // GetAbsoluteStorePath takes a (possibly relative) and returns the absolute path.
 // Returns an error if the path begins with '~' or Abs fails.
 // 'fieldName' is used in error strings.
@@ -174,6 +178,17 @@ type StoreSpec struct {
 	// ExtraOptions is a serialized protobuf set by Go CCL code and passed through
 	// to C CCL code.
 	ExtraOptions []byte
+	// BlockCacheSize, if non-zero, gives the store a dedicated block cache of
+	// this size instead of sharing the node-wide cache.
+	BlockCacheSize int64
+	// BlockCacheShardBits, if non-zero, splits the store's dedicated block
+	// cache into 2^BlockCacheShardBits shards.
+	BlockCacheShardBits int
+	// SecondaryCachePath, if set, is a directory on a fast local device (such
+	// as an SSD) which holds a persistent secondary block cache of
+	// SecondaryCacheSize bytes. This is useful for stores on slow disks.
+	SecondaryCachePath string
+	SecondaryCacheSize int64
 }
 
 // String returns a fully parsable version of the store spec.
@@ -201,6 +216,18 @@ func (ss StoreSpec) String() string {
 		}
 		fmt.Fprintf(&buffer, ",")
 	}
+	if ss.BlockCacheSize > 0 {
+		fmt.Fprintf(&buffer, "cache-size=%s,", humanizeutil.IBytes(ss.BlockCacheSize))
+	}
+	if ss.BlockCacheShardBits > 0 {
+		fmt.Fprintf(&buffer, "cache-shard-bits=%d,", ss.BlockCacheShardBits)
+	}
+	if len(ss.SecondaryCachePath) != 0 {
+		fmt.Fprintf(&buffer, "secondary-cache-path=%s,", ss.SecondaryCachePath)
+	}
+	if ss.SecondaryCacheSize > 0 {
+		fmt.Fprintf(&buffer, "secondary-cache-size=%s,", humanizeutil.IBytes(ss.SecondaryCacheSize))
+	}
 	// Trim the extra comma from the end if it exists.
 	if l := buffer.Len(); l > 0 {
 		buffer.Truncate(l - 1)
@@ -238,6 +265,14 @@ var fractionRegex = regexp.MustCompile(`^([-]?([0-9]+\.[0-9]*|[0-9]*\.[0-9]+|[0-
 //   - 20%             -> 20% of the available space
 //   - 0.2             -> 20% of the available space
 // - attrs=xxx:yyy:zzz A colon separated list of optional attributes.
+// - cache-size=xxx The size of a block cache dedicated to this store, in
+//   bytes (e.g. 1GiB). By default, all stores share the node's block cache.
+// - cache-shard-bits=n The store's dedicated block cache is split into 2^n
+//   shards.
+// - secondary-cache-path=xxx A directory on a fast local device (e.g. an SSD)
+//   in which to keep a persistent secondary block cache for a store on a slow
+//   disk. Requires secondary-cache-size.
+// - secondary-cache-size=xxx The size of the secondary cache, in bytes.
 // Note that commas are forbidden within any field name or value.
 func NewStoreSpec(value string) (StoreSpec, error) {
 	const pathField = "path"
@@ -313,6 +348,40 @@ func NewStoreSpec(value string) (StoreSpec, error) {
 			}
 		case "rocksdb":
 			ss.RocksDBOptions = value
+		case "cache-size":
+			var err error
+			ss.BlockCacheSize, err = humanizeutil.ParseBytes(value)
+			if err != nil {
+				return StoreSpec{}, errors.Wrapf(err, "could not parse cache size (%s)", value)
+			}
+			if ss.BlockCacheSize <= 0 {
+				return StoreSpec{}, fmt.Errorf("cache size (%s) must be positive", value)
+			}
+		case "cache-shard-bits":
+			var err error
+			ss.BlockCacheShardBits, err = strconv.Atoi(value)
+			if err != nil {
+				return StoreSpec{}, errors.Wrapf(err, "could not parse cache shard bits (%s)", value)
+			}
+			if ss.BlockCacheShardBits < 1 || ss.BlockCacheShardBits > maxBlockCacheShardBits {
+				return StoreSpec{}, fmt.Errorf("cache shard bits (%s) must be between 1 and %d",
+					value, maxBlockCacheShardBits)
+			}
+		case "secondary-cache-path":
+			var err error
+			ss.SecondaryCachePath, err = GetAbsoluteStorePath(field, value)
+			if err != nil {
+				return StoreSpec{}, err
+			}
+		case "secondary-cache-size":
+			var err error
+			ss.SecondaryCacheSize, err = humanizeutil.ParseBytes(value)
+			if err != nil {
+				return StoreSpec{}, errors.Wrapf(err, "could not parse secondary cache size (%s)", value)
+			}
+			if ss.SecondaryCacheSize <= 0 {
+				return StoreSpec{}, fmt.Errorf("secondary cache size (%s) must be positive", value)
+			}
 		default:
 			return StoreSpec{}, fmt.Errorf("%s is not a valid store field", field)
 		}
@@ -325,9 +394,19 @@ func NewStoreSpec(value string) (StoreSpec, error) {
 		if ss.Size.Percent == 0 && ss.Size.InBytes == 0 {
 			return StoreSpec{}, fmt.Errorf("size must be specified for an in memory store")
 		}
+		if ss.SecondaryCachePath != "" {
+			return StoreSpec{}, fmt.Errorf("secondary cache specified for in memory store")
+		}
 	} else if ss.Path == "" {
 		return StoreSpec{}, fmt.Errorf("no path specified")
 	}
+	if ss.BlockCacheShardBits != 0 && ss.BlockCacheSize == 0 {
+		return StoreSpec{}, fmt.Errorf("cache shard bits specified without a cache size")
+	}
+	if (ss.SecondaryCachePath == "") != (ss.SecondaryCacheSize == 0) {
+		return StoreSpec{}, fmt.Errorf(
+			"secondary-cache-path and secondary-cache-size must be specified together")
+	}
 	return ss, nil
 }
 
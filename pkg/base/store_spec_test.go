@@ -119,6 +119,27 @@ func TestNewStoreSpec(t *testing.T) {
 		// RocksDB
 		{"path=/,rocksdb=key1=val1;key2=val2", "", StoreSpec{Path: "/", RocksDBOptions: "key1=val1;key2=val2"}},
 
+		// block cache
+		{"path=/mnt/hda1,cache-size=1GiB", "", StoreSpec{Path: "/mnt/hda1", BlockCacheSize: 1073741824}},
+		{"path=/mnt/hda1,cache-size=1GiB,cache-shard-bits=6", "", StoreSpec{
+			Path:                "/mnt/hda1",
+			BlockCacheSize:      1073741824,
+			BlockCacheShardBits: 6,
+		}},
+		{"path=/mnt/hda1,cache-size=0", "cache size (0) must be positive", StoreSpec{}},
+		{"path=/mnt/hda1,cache-shard-bits=6", "cache shard bits specified without a cache size", StoreSpec{}},
+		{"path=/mnt/hda1,cache-size=1GiB,cache-shard-bits=20", "cache shard bits (20) must be between 1 and 19", StoreSpec{}},
+
+		// secondary cache
+		{"path=/mnt/hda1,secondary-cache-path=/mnt/ssd1,secondary-cache-size=10GiB", "", StoreSpec{
+			Path:               "/mnt/hda1",
+			SecondaryCachePath: "/mnt/ssd1",
+			SecondaryCacheSize: 10737418240,
+		}},
+		{"path=/mnt/hda1,secondary-cache-path=/mnt/ssd1", "secondary-cache-path and secondary-cache-size must be specified together", StoreSpec{}},
+		{"path=/mnt/hda1,secondary-cache-size=10GiB", "secondary-cache-path and secondary-cache-size must be specified together", StoreSpec{}},
+		{"type=mem,size=20GiB,secondary-cache-path=/mnt/ssd1,secondary-cache-size=10GiB", "secondary cache specified for in memory store", StoreSpec{}},
+
 		// all together
 		{"path=/mnt/hda1,attrs=hdd:ssd,size=20GiB", "", StoreSpec{
 			Path:       "/mnt/hda1",
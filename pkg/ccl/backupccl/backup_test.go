@@ -224,6 +224,52 @@ func TestBackupRestoreStatementResult(t *testing.T) {
 	}
 }
 
+func TestRestoreRenameTables(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numAccounts = 10
+	_, _, sqlDB, _, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, initNone)
+	defer cleanupFn()
+
+	sqlDB.Exec(t, `CREATE TABLE data.other (a INT PRIMARY KEY)`)
+	sqlDB.Exec(t, `INSERT INTO data.other VALUES (1), (2)`)
+	sqlDB.Exec(t, `CREATE VIEW data.v AS SELECT a FROM data.other`)
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1`, localFoo)
+
+	sqlDB.Exec(t, `RESTORE data.bank FROM $1 WITH rename_tables = 'bank=bank2'`, localFoo)
+	sqlDB.CheckQueryResults(t, `SELECT count(*) FROM data.bank2`, [][]string{{"10"}})
+
+	sqlDB.Exec(t, `CREATE DATABASE restoredb`)
+	sqlDB.Exec(t, `RESTORE data.bank, data.other FROM $1 WITH into_db = 'restoredb', `+
+		`rename_tables = 'data.bank = accounts, other = numbers'`, localFoo)
+	sqlDB.CheckQueryResults(t, `SELECT count(*) FROM restoredb.accounts`, [][]string{{"10"}})
+	sqlDB.CheckQueryResults(t, `SELECT a FROM restoredb.numbers`, [][]string{{"1"}, {"2"}})
+
+	for _, tc := range []struct {
+		query string
+		err   string
+	}{
+		{`RESTORE data.bank FROM $1 WITH rename_tables = 'bank=bank2'`,
+			`relation "bank2" already exists`},
+		{`RESTORE data.bank FROM $1 WITH rename_tables = 'missing=foo'`,
+			`table "missing" is not being restored`},
+		{`RESTORE data.bank FROM $1 WITH rename_tables = 'bank'`,
+			`expected old_name=new_name`},
+		{`RESTORE data.bank FROM $1 WITH rename_tables = 'bank=a, bank=b'`,
+			`table "bank" renamed more than once`},
+		{`RESTORE data.* FROM $1 WITH into_db = 'restoredb', rename_tables = 'other=foo'`,
+			`cannot rename table "other": view "v" depends on it`},
+		{`RESTORE data.bank, data.other FROM $1 WITH into_db = 'restoredb', rename_tables = 'bank=other'`,
+			`more than one table would be restored as "other"`},
+		{`RESTORE DATABASE data FROM $1 WITH rename_tables = 'bank=foo'`,
+			`cannot use "rename_tables" option when restoring database\(s\)`},
+	} {
+		if _, err := sqlDB.DB.Exec(tc.query, localFoo); !testutils.IsError(err, tc.err) {
+			t.Errorf("%s: expected error %q, got %v", tc.query, tc.err, err)
+		}
+	}
+}
+
 func TestBackupRestoreLocal(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
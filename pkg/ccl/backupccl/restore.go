@@ -13,6 +13,7 @@ import (
 	"math"
 	"runtime"
 	"sort"
+	"strings"
 	"sync/atomic"
 
 	opentracing "github.com/opentracing/opentracing-go"
@@ -47,12 +48,14 @@ type tableRewriteMap map[sqlbase.ID]*jobspb.RestoreDetails_TableRewrite
 
 const (
 	restoreOptIntoDB               = "into_db"
+	restoreOptRenameTables         = "rename_tables"
 	restoreOptSkipMissingFKs       = "skip_missing_foreign_keys"
 	restoreOptSkipMissingSequences = "skip_missing_sequences"
 )
 
 var restoreOptionExpectValues = map[string]bool{
 	restoreOptIntoDB:               true,
+	restoreOptRenameTables:         true,
 	restoreOptSkipMissingFKs:       false,
 	restoreOptSkipMissingSequences: false,
 }
@@ -216,6 +219,15 @@ func allocateTableRewrites(
 		}
 	}
 
+	if renames, ok := opts[restoreOptRenameTables]; ok {
+		if len(restoreDBNames) > 0 {
+			return nil, errors.Errorf("cannot use %q option when restoring database(s)", restoreOptRenameTables)
+		}
+		if err := renameTables(tablesByID, databasesByID, renames, overrideDB); err != nil {
+			return nil, err
+		}
+	}
+
 	// The logic at the end of this function leaks table IDs, so fail fast if
 	// we can be certain the restore will fail.
 
@@ -373,6 +385,97 @@ func allocateTableRewrites(
 	return tableRewrites, nil
 }
 
+// renameTables renames the tables being restored as specified by the value of
+// the rename_tables option: a comma-separated list of old_name=new_name pairs,
+// where old_name may be qualified with the name of the table's database in the
+// backup.
+func renameTables(
+	tablesByID map[sqlbase.ID]*sqlbase.TableDescriptor,
+	databasesByID map[sqlbase.ID]*sqlbase.DatabaseDescriptor,
+	renames string,
+	overrideDB string,
+) error {
+	dbName := func(table *sqlbase.TableDescriptor) string {
+		if db, ok := databasesByID[table.ParentID]; ok {
+			return db.Name
+		}
+		return ""
+	}
+
+	renamed := make(map[sqlbase.ID]string)
+	for _, pair := range strings.Split(renames, ",") {
+		parts := strings.Split(pair, "=")
+		if len(parts) != 2 {
+			return errors.Errorf("invalid %q option %q: expected old_name=new_name", restoreOptRenameTables, pair)
+		}
+		oldName, newName := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		if newName == "" {
+			return errors.Errorf("invalid %q option %q: empty table name", restoreOptRenameTables, pair)
+		}
+		oldDB := ""
+		if i := strings.LastIndexByte(oldName, '.'); i >= 0 {
+			oldDB, oldName = oldName[:i], oldName[i+1:]
+		}
+
+		var found *sqlbase.TableDescriptor
+		for _, table := range tablesByID {
+			if table.Name != oldName || (oldDB != "" && dbName(table) != oldDB) {
+				continue
+			}
+			if found != nil {
+				return errors.Errorf("table name %q is ambiguous: qualify it with its database", oldName)
+			}
+			found = table
+		}
+		if found == nil {
+			return errors.Errorf("table %q is not being restored", strings.TrimSpace(parts[0]))
+		}
+		if _, ok := renamed[found.ID]; ok {
+			return errors.Errorf("table %q renamed more than once", strings.TrimSpace(parts[0]))
+		}
+
+		// Views and column defaults refer to the tables and sequences they use
+		// by name, which we don't rewrite.
+		for _, ref := range found.DependedOnBy {
+			if view, ok := tablesByID[ref.ID]; ok {
+				return errors.Errorf("cannot rename %s %q: view %q depends on it",
+					found.TypeName(), found.Name, view.Name)
+			}
+		}
+		for _, table := range tablesByID {
+			for _, col := range table.Columns {
+				for _, seqID := range col.UsesSequenceIds {
+					if seqID == found.ID {
+						return errors.Errorf("cannot rename %s %q: table %q uses it",
+							found.TypeName(), found.Name, table.Name)
+					}
+				}
+			}
+		}
+		renamed[found.ID] = newName
+	}
+
+	for id, name := range renamed {
+		tablesByID[id].Name = name
+	}
+
+	// Check that no two restored tables end up with the same name in the same
+	// database.
+	type qualifiedName struct{ db, table string }
+	names := make(map[qualifiedName]struct{}, len(tablesByID))
+	for _, table := range tablesByID {
+		name := qualifiedName{db: overrideDB, table: table.Name}
+		if overrideDB == "" {
+			name.db = dbName(table)
+		}
+		if _, ok := names[name]; ok {
+			return errors.Errorf("more than one table would be restored as %q", table.Name)
+		}
+		names[name] = struct{}{}
+	}
+	return nil
+}
+
 // CheckTableExists returns an error if a table already exists with given
 // parent and name.
 func CheckTableExists(
@@ -1395,6 +1498,17 @@ func (r *restoreResumer) Resume(
 		job,
 		resultsCh,
 	)
+	// Tables may have been renamed when the restore was planned, and the
+	// descriptors saved in the job details have their new names.
+	newNames := make(map[sqlbase.ID]string, len(details.TableDescs))
+	for _, desc := range details.TableDescs {
+		newNames[desc.ID] = desc.Name
+	}
+	for _, table := range tables {
+		if name, ok := newNames[table.ID]; ok {
+			table.Name = name
+		}
+	}
 	r.res = res
 	r.databases = databases
 	r.tables = tables
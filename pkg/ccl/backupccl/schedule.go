@@ -0,0 +1,153 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/pkg/errors"
+)
+
+// scheduledBackupDirFormat is the format of the name of the subdirectory of
+// the destination of a scheduled BACKUP that each of its runs writes to.
+const scheduledBackupDirFormat = "20060102-150405"
+
+// createScheduleBackupPlanHook implements PlanHookFn for CREATE SCHEDULE ...
+// FOR BACKUP. The BACKUP is validated and its arguments evaluated when the
+// schedule is created, so that it can be stored with constant arguments.
+func createScheduleBackupPlanHook(
+	_ context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (sql.PlanHookRowFn, sqlbase.ResultColumns, []sql.PlanNode, error) {
+	schedStmt, ok := stmt.(*tree.CreateSchedule)
+	if !ok {
+		return nil, nil, nil, nil
+	}
+	backupStmt := schedStmt.Backup
+
+	recurrenceFn, err := p.TypeAsString(schedStmt.Recurrence, "CREATE SCHEDULE")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	toFn, err := p.TypeAsString(backupStmt.To, "CREATE SCHEDULE")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	incrementalFromFn, err := p.TypeAsStringArray(backupStmt.IncrementalFrom, "CREATE SCHEDULE")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	optsFn, err := p.TypeAsStringOpts(backupStmt.Options, backupOptionExpectValues)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, _ chan<- tree.Datums) error {
+		// TODO(dan): Move this span into sql.
+		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
+		defer tracing.FinishSpan(span)
+
+		if err := utilccl.CheckEnterpriseEnabled(
+			p.ExecCfg().Settings, p.ExecCfg().ClusterID(), p.ExecCfg().Organization(), "CREATE SCHEDULE",
+		); err != nil {
+			return err
+		}
+
+		if err := p.RequireSuperUser(ctx, "CREATE SCHEDULE"); err != nil {
+			return err
+		}
+
+		recurrence, err := recurrenceFn()
+		if err != nil {
+			return err
+		}
+		to, err := toFn()
+		if err != nil {
+			return err
+		}
+		if _, err := url.Parse(to); err != nil {
+			return err
+		}
+		incrementalFrom, err := incrementalFromFn()
+		if err != nil {
+			return err
+		}
+		opts, err := optsFn()
+		if err != nil {
+			return err
+		}
+
+		// Check that the targets exist now; they're resolved again every time
+		// the BACKUP runs.
+		if _, _, err := ResolveTargetsToDescriptors(
+			ctx, p, p.ExecCfg().Clock.Now(), backupStmt.Targets,
+		); err != nil {
+			return err
+		}
+
+		scheduled := &tree.Backup{
+			Targets: backupStmt.Targets,
+			To:      tree.NewDString(to),
+		}
+		for _, uri := range incrementalFrom {
+			scheduled.IncrementalFrom = append(scheduled.IncrementalFrom, tree.NewDString(uri))
+		}
+		for _, opt := range backupStmt.Options {
+			kv := tree.KVOption{Key: opt.Key}
+			if opt.Value != nil {
+				kv.Value = tree.NewDString(opts[string(opt.Key)])
+			}
+			scheduled.Options = append(scheduled.Options, kv)
+		}
+
+		return sql.CreateSchedule(ctx, p, string(schedStmt.Name), recurrence, scheduled)
+	}
+	return fn, nil, nil, nil
+}
+
+// scheduledBackupHook implements sql.ScheduleHookFn. Each run of a scheduled
+// BACKUP writes to its own subdirectory of the destination, named after the
+// time of the run, since a BACKUP can't overwrite an existing one.
+func scheduledBackupHook(stmt tree.Statement, runAt time.Time) (tree.Statement, error) {
+	backupStmt, ok := stmt.(*tree.Backup)
+	if !ok {
+		return nil, nil
+	}
+
+	var to string
+	switch t := backupStmt.To.(type) {
+	case *tree.StrVal:
+		to = t.RawString()
+	case *tree.DString:
+		to = string(*t)
+	default:
+		return nil, errors.Errorf("unexpected destination %s in scheduled BACKUP", backupStmt.To)
+	}
+	uri, err := url.Parse(to)
+	if err != nil {
+		return nil, err
+	}
+	uri.Path = path.Join(uri.Path, runAt.UTC().Format(scheduledBackupDirFormat))
+
+	run := *backupStmt
+	run.To = tree.NewDString(uri.String())
+	return &run, nil
+}
+
+func init() {
+	sql.AddPlanHook(createScheduleBackupPlanHook)
+	sql.AddScheduleHook(scheduledBackupHook)
+}
@@ -0,0 +1,116 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl_test
+
+import (
+	gosql "database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/pkg/errors"
+)
+
+func TestScheduledBackup(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numAccounts = 1
+	_, _, sqlDB, _, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, initNone)
+	defer cleanupFn()
+
+	sqlDB.Exec(t, `SET CLUSTER SETTING jobs.scheduler.poll_interval = '10ms'`)
+
+	const create = `CREATE SCHEDULE nightly FOR BACKUP DATABASE data TO $1 RECURRING '@daily'`
+	sqlDB.Exec(t, create, localFoo)
+
+	for _, tc := range []struct {
+		query string
+		err   string
+	}{
+		{create, `schedule "nightly" already exists`},
+		{`CREATE SCHEDULE bad FOR BACKUP DATABASE data TO $1 RECURRING '* * *'`, `must have 5 fields`},
+		{`CREATE SCHEDULE bad FOR BACKUP DATABASE data TO $1 RECURRING '0 0 30 2 *'`, `never fires`},
+		{`CREATE SCHEDULE bad FOR BACKUP DATABASE nope TO $1 RECURRING '@daily'`, `does not exist`},
+	} {
+		if _, err := sqlDB.DB.Exec(tc.query, localFoo); !testutils.IsError(err, tc.err) {
+			t.Fatalf("%s: expected error %q, got %v", tc.query, tc.err, err)
+		}
+	}
+
+	var owner, command string
+	var nextRun time.Time
+	sqlDB.QueryRow(t,
+		`SELECT owner, command, next_run FROM crdb_internal.scheduled_jobs WHERE name = 'nightly'`,
+	).Scan(&owner, &command, &nextRun)
+	if owner != "root" {
+		t.Fatalf("expected owner root, got %q", owner)
+	}
+	if expected := `BACKUP DATABASE data TO '` + localFoo + `'`; command != expected {
+		t.Fatalf("expected command %q, got %q", expected, command)
+	}
+	if !nextRun.After(time.Now()) {
+		t.Fatalf("expected the next run in the future, got %s", nextRun)
+	}
+
+	// Make the schedule due, and wait for it to be run.
+	sqlDB.Exec(t, `UPDATE system.scheduled_jobs SET next_run = '2018-01-01' WHERE name = 'nightly'`)
+	testutils.SucceedsSoon(t, func() error {
+		var lastRun, lastError gosql.NullString
+		sqlDB.QueryRow(t,
+			`SELECT last_run::STRING, last_error FROM system.scheduled_jobs WHERE name = 'nightly'`,
+		).Scan(&lastRun, &lastError)
+		if lastError.Valid {
+			t.Fatalf("scheduled backup failed: %s", lastError.String)
+		}
+		var status, description string
+		if err := sqlDB.DB.QueryRow(
+			`SELECT status, description FROM crdb_internal.jobs WHERE type = 'BACKUP'`,
+		).Scan(&status, &description); err != nil {
+			return err
+		}
+		if status != "succeeded" {
+			return errors.Errorf("expected the backup to succeed, got %s", status)
+		}
+		// The backup is written to a subdirectory named after the time of the run.
+		if !strings.Contains(description, localFoo+"/") {
+			return errors.Errorf("unexpected backup description %q", description)
+		}
+		if !lastRun.Valid {
+			return errors.New("last run not recorded")
+		}
+		return nil
+	})
+	sqlDB.QueryRow(t,
+		`SELECT next_run FROM system.scheduled_jobs WHERE name = 'nightly'`,
+	).Scan(&nextRun)
+	if !nextRun.After(time.Now()) {
+		t.Fatalf("expected the next run in the future, got %s", nextRun)
+	}
+
+	var paused bool
+	sqlDB.Exec(t, `PAUSE SCHEDULE nightly`)
+	sqlDB.QueryRow(t, `SELECT paused FROM system.scheduled_jobs WHERE name = 'nightly'`).Scan(&paused)
+	if !paused {
+		t.Fatal("expected the schedule to be paused")
+	}
+	sqlDB.Exec(t, `RESUME SCHEDULE nightly`)
+	sqlDB.QueryRow(t, `SELECT paused FROM system.scheduled_jobs WHERE name = 'nightly'`).Scan(&paused)
+	if paused {
+		t.Fatal("expected the schedule to be resumed")
+	}
+
+	sqlDB.Exec(t, `DROP SCHEDULE nightly`)
+	if _, err := sqlDB.DB.Exec(`DROP SCHEDULE nightly`); !testutils.IsError(
+		err, `schedule "nightly" does not exist`,
+	) {
+		t.Fatalf("expected the schedule to be dropped, got %v", err)
+	}
+}
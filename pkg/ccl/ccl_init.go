@@ -22,5 +22,6 @@ import (
 	_ "github.com/cockroachdb/cockroach/pkg/ccl/roleccl"
 	_ "github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
 	_ "github.com/cockroachdb/cockroach/pkg/ccl/storageccl/engineccl"
+	_ "github.com/cockroachdb/cockroach/pkg/ccl/streamingccl"
 	_ "github.com/cockroachdb/cockroach/pkg/ccl/utilccl/intervalccl"
 )
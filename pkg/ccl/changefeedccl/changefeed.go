@@ -24,8 +24,10 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/util/bufalloc"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -39,6 +41,13 @@ var changefeedPollInterval = settings.RegisterDurationSetting(
 	1*time.Second,
 )
 
+var changefeedPushEnabled = settings.RegisterBoolSetting(
+	"changefeed.push.enabled",
+	"if set, changed kvs are pushed to changefeeds by rangefeeds instead of "+
+		"being polled for; this also requires the kv.rangefeed.enabled setting",
+	true,
+)
+
 func init() {
 	changefeedPollInterval.Hide()
 }
@@ -51,6 +60,9 @@ type changedKVs struct {
 	// sst, if non-nil, is an sstable with mvcc key values as returned by
 	// ExportRequest.
 	sst []byte
+	// kv, if its key is non-nil, is a single mvcc key value as returned by a
+	// RangeFeed.
+	kv roachpb.KeyValue
 	// resolved, if non-zero, is a guarantee that all key values in subsequent
 	// changedKVs will have an equal or higher timestamp.
 	resolved hlc.Timestamp
@@ -100,13 +112,24 @@ func runChangefeedFlow(
 		})
 	}
 
+	// Make sure any goroutines started to feed the flow are shut down when it
+	// returns.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	// The changefeed flow is intentionally structured as a pull model so it's
 	// easy to later make it into a DistSQL processor.
 	//
 	// TODO(dan): Make this into a DistSQL flow.
-	changedKVsFn := exportRequestPoll(execCfg, details, progress)
+	var changedKVsFn func(context.Context) (changedKVs, error)
+	if changefeedPushEnabled.Get(&execCfg.Settings.SV) &&
+		storage.RangefeedEnabled.Get(&execCfg.Settings.SV) {
+		changedKVsFn = rangefeedPoll(execCfg, details, progress)
+	} else {
+		changedKVsFn = exportRequestPoll(execCfg, details, progress)
+	}
 	rowsFn := kvsToRows(execCfg, details, changedKVsFn)
-	emitRowsFn, closeFn, err := emitRows(details, jobProgressedFn, rowsFn, resultsCh)
+	emitRowsFn, closeFn, err := emitRows(ctx, execCfg, details, jobProgressedFn, rowsFn, resultsCh)
 	if err != nil {
 		return err
 	}
@@ -140,10 +163,7 @@ func exportRequestPoll(
 	execCfg *sql.ExecutorConfig, details jobspb.ChangefeedDetails, progress jobspb.ChangefeedProgress,
 ) func(context.Context) (changedKVs, error) {
 	sender := execCfg.DB.NonTransactionalSender()
-	var spans []roachpb.Span
-	for _, tableDesc := range details.TableDescs {
-		spans = append(spans, tableDesc.PrimaryIndexSpan())
-	}
+	spans := changefeedSpans(details)
 
 	var buffer changefeedBuffer
 	highwater := progress.Highwater
@@ -167,23 +187,8 @@ func exportRequestPoll(
 		log.VEventf(ctx, 1, `changefeed poll [%s,%s): %s`,
 			highwater, nextHighwater, time.Duration(nextHighwater.WallTime-highwater.WallTime))
 
-		// TODO(dan): Send these out in parallel.
-		for _, span := range spans {
-			header := roachpb.Header{Timestamp: nextHighwater}
-			req := &roachpb.ExportRequest{
-				RequestHeader: roachpb.RequestHeaderFromSpan(span),
-				StartTime:     highwater,
-				MVCCFilter:    roachpb.MVCCFilter_Latest,
-				ReturnSST:     true,
-			}
-			res, pErr := client.SendWrappedWith(ctx, sender, header, req)
-			if pErr != nil {
-				return changedKVs{}, errors.Wrapf(
-					pErr.GoError(), `fetching changes for [%s,%s)`, span.Key, span.EndKey)
-			}
-			for _, file := range res.(*roachpb.ExportResponse).Files {
-				buffer.append(changedKVs{sst: file.SST})
-			}
+		if err := exportSpans(ctx, sender, spans, highwater, nextHighwater, &buffer); err != nil {
+			return changedKVs{}, err
 		}
 		log.VEventf(ctx, 2, `poll took %s`,
 			time.Duration(execCfg.Clock.Now().WallTime-nextHighwater.WallTime))
@@ -197,6 +202,125 @@ func exportRequestPoll(
 	}
 }
 
+// rangefeedPoll uses RangeFeeds to receive every kv that changed after the
+// highwater. It returns a closure that may be repeatedly called to pull new
+// changes. The returned closure is not threadsafe.
+//
+// RangeFeeds only return changes after their starting timestamp, so if the
+// changefeed has no highwater yet, the initial contents of the tables are
+// fetched with an ExportRequest first, as in exportRequestPoll. Afterward, a
+// RangeFeed is established for each relevant span and the changed kvs are
+// returned as they arrive. Whenever the minimum checkpoint over all the spans
+// advances, it is returned as resolved.
+func rangefeedPoll(
+	execCfg *sql.ExecutorConfig, details jobspb.ChangefeedDetails, progress jobspb.ChangefeedProgress,
+) func(context.Context) (changedKVs, error) {
+	sender := execCfg.DB.NonTransactionalSender()
+	spans := changefeedSpans(details)
+	frontier := makeSpanFrontier(spans...)
+
+	var buffer changefeedBuffer
+	var eventC chan *roachpb.RangeFeedEvent
+	var errC chan error
+	highwater := progress.Highwater
+	return func(ctx context.Context) (changedKVs, error) {
+		if ret, ok := buffer.get(); ok {
+			return ret, nil
+		}
+
+		if highwater == (hlc.Timestamp{}) {
+			highwater = execCfg.Clock.Now()
+			log.VEventf(ctx, 1, `changefeed initial scan at %s`, highwater)
+			if err := exportSpans(ctx, sender, spans, hlc.Timestamp{}, highwater, &buffer); err != nil {
+				return changedKVs{}, err
+			}
+			buffer.append(changedKVs{resolved: highwater})
+			ret, _ := buffer.get()
+			return ret, nil
+		}
+
+		if eventC == nil {
+			log.VEventf(ctx, 1, `starting rangefeeds at %s`, highwater)
+			eventC = make(chan *roachpb.RangeFeedEvent, 128)
+			errC = make(chan error, 1)
+			g := ctxgroup.WithContext(ctx)
+			for _, span := range spans {
+				req := &roachpb.RangeFeedRequest{
+					Header: roachpb.Header{Timestamp: highwater},
+					Span:   span,
+				}
+				g.GoCtx(func(ctx context.Context) error {
+					return execCfg.DistSender.RangeFeed(ctx, req, eventC).GoError()
+				})
+			}
+			go func() { errC <- g.Wait() }()
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return changedKVs{}, ctx.Err()
+			case err := <-errC:
+				return changedKVs{}, errors.Wrap(err, `rangefeed failed`)
+			case event := <-eventC:
+				switch t := event.GetValue().(type) {
+				case *roachpb.RangeFeedValue:
+					return changedKVs{kv: roachpb.KeyValue{Key: t.Key, Value: t.Value}}, nil
+				case *roachpb.RangeFeedCheckpoint:
+					frontier.Forward(t.Span, t.ResolvedTS)
+					if resolved := frontier.Frontier(); highwater.Less(resolved) {
+						highwater = resolved
+						return changedKVs{resolved: resolved}, nil
+					}
+				default:
+					return changedKVs{}, errors.Errorf(`unexpected rangefeed event: %v`, event)
+				}
+			}
+		}
+	}
+}
+
+// changefeedSpans returns the spans that contain the kvs of the tables watched
+// by a changefeed.
+func changefeedSpans(details jobspb.ChangefeedDetails) []roachpb.Span {
+	var spans []roachpb.Span
+	for _, tableDesc := range details.TableDescs {
+		spans = append(spans, tableDesc.PrimaryIndexSpan())
+	}
+	return spans
+}
+
+// exportSpans uses ExportRequest to fetch the latest version of every kv in
+// the given spans that changed in (start, end] and appends the resulting
+// sstables to the buffer.
+func exportSpans(
+	ctx context.Context,
+	sender client.Sender,
+	spans []roachpb.Span,
+	start, end hlc.Timestamp,
+	buffer *changefeedBuffer,
+) error {
+	// TODO(dan): Send these out in parallel.
+	for _, span := range spans {
+		header := roachpb.Header{Timestamp: end}
+		req := &roachpb.ExportRequest{
+			RequestHeader: roachpb.RequestHeaderFromSpan(span),
+			StartTime:     start,
+			MVCCFilter:    roachpb.MVCCFilter_Latest,
+			ReturnSST:     true,
+		}
+		res, pErr := client.SendWrappedWith(ctx, sender, header, req)
+		if pErr != nil {
+			return errors.Wrapf(
+				pErr.GoError(), `fetching changes for [%s,%s)`, span.Key, span.EndKey)
+		}
+		for _, file := range res.(*roachpb.ExportResponse).Files {
+			buffer.append(changedKVs{sst: file.SST})
+		}
+	}
+	return nil
+}
+
 // kvsToRows gets changed kvs from a closure and converts them into sql rows. It
 // returns a closure that may be repeatedly called to advance the changefeed.
 // The returned closure is not threadsafe.
@@ -210,6 +334,38 @@ func kvsToRows(
 	var output []emitRow
 	var kvs sqlbase.SpanKVFetcher
 	var scratch bufalloc.ByteAllocator
+	appendRows := func(ctx context.Context, kv roachpb.KeyValue) error {
+		key := engine.MVCCKey{Key: kv.Key, Timestamp: kv.Value.Timestamp}
+		rf, err := rfCache.RowFetcherForKey(ctx, key)
+		if err != nil {
+			return err
+		}
+		if log.V(3) {
+			log.Infof(ctx, "changed key %s", key)
+		}
+		// TODO(dan): Handle tables with multiple column families.
+		kvs.KVs = append(kvs.KVs, kv)
+		if err := rf.StartScanFrom(ctx, &kvs); err != nil {
+			return err
+		}
+
+		for {
+			var r emitRow
+			r.row, r.tableDesc, _, err = rf.NextRowDecoded(ctx)
+			if err != nil {
+				return err
+			}
+			if r.row == nil {
+				break
+			}
+			r.row = append(tree.Datums(nil), r.row...)
+
+			r.deleted = rf.RowIsDeleted()
+			r.rowTimestamp = kv.Value.Timestamp
+			output = append(output, r)
+		}
+		return nil
+	}
 	return func(ctx context.Context) ([]emitRow, error) {
 		// Reuse output, kvs, scratch to save allocations.
 		output, kvs.KVs, scratch = output[:0], kvs.KVs[:0], scratch[:0]
@@ -232,43 +388,23 @@ func kvsToRows(
 				}
 
 				unsafeKey := it.UnsafeKey()
-				rf, err := rfCache.RowFetcherForKey(ctx, unsafeKey)
-				if err != nil {
-					return nil, err
-				}
-				if log.V(3) {
-					log.Infof(ctx, "changed key %s", unsafeKey)
-				}
 				var key, value []byte
 				scratch, key = scratch.Copy(unsafeKey.Key, 0 /* extraCap */)
 				scratch, value = scratch.Copy(it.UnsafeValue(), 0 /* extraCap */)
-				// TODO(dan): Handle tables with multiple column families.
-				kvs.KVs = append(kvs.KVs, roachpb.KeyValue{
+				if err := appendRows(ctx, roachpb.KeyValue{
 					Key: key,
 					Value: roachpb.Value{
 						Timestamp: unsafeKey.Timestamp,
 						RawBytes:  value,
 					},
-				})
-				if err := rf.StartScanFrom(ctx, &kvs); err != nil {
+				}); err != nil {
 					return nil, err
 				}
-
-				for {
-					var r emitRow
-					r.row, r.tableDesc, _, err = rf.NextRowDecoded(ctx)
-					if err != nil {
-						return nil, err
-					}
-					if r.row == nil {
-						break
-					}
-					r.row = append(tree.Datums(nil), r.row...)
-
-					r.deleted = rf.RowIsDeleted()
-					r.rowTimestamp = unsafeKey.Timestamp
-					output = append(output, r)
-				}
+			}
+		}
+		if input.kv.Key != nil {
+			if err := appendRows(ctx, input.kv); err != nil {
+				return nil, err
 			}
 		}
 		if input.resolved != (hlc.Timestamp{}) {
@@ -283,6 +419,8 @@ func kvsToRows(
 // be repeatedly called to advance the changefeed. The returned closure is not
 // threadsafe.
 func emitRows(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
 	details jobspb.ChangefeedDetails,
 	jobProgressedFn func(context.Context, hlc.Timestamp) error,
 	inputFn func(context.Context) ([]emitRow, error),
@@ -294,13 +432,17 @@ func emitRows(
 	if err != nil {
 		return nil, nil, err
 	}
-	switch sinkURI.Scheme {
-	case sinkSchemeChannel:
+	switch {
+	case sinkURI.Scheme == sinkSchemeChannel:
 		sink = &channelSink{resultsCh: resultsCh}
 		closeFn = sink.Close
-	case sinkSchemeKafka:
-		kafkaTopicPrefix := sinkURI.Query().Get(sinkParamTopicPrefix)
-		sink, err = getKafkaSink(kafkaTopicPrefix, sinkURI.Host)
+	case sinkURI.Scheme == sinkSchemeKafka || isCloudStorageSink(sinkURI):
+		if sinkURI.Scheme == sinkSchemeKafka {
+			kafkaTopicPrefix := sinkURI.Query().Get(sinkParamTopicPrefix)
+			sink, err = getKafkaSink(kafkaTopicPrefix, sinkURI.Host)
+		} else {
+			sink, err = makeCloudStorageSink(ctx, details.SinkURI, execCfg.Settings)
+		}
 		if err != nil {
 			return nil, nil, err
 		}
@@ -396,6 +538,9 @@ func emitRows(
 				if err := emitRows(ctx); err != nil {
 					return err
 				}
+				if err := sink.Flush(ctx); err != nil {
+					return err
+				}
 
 				// NB: To minimize the chance that a user sees duplicates from
 				// below this resolved timestamp, keep this update of the
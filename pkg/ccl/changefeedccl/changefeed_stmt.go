@@ -10,8 +10,10 @@ package changefeedccl
 
 import (
 	"context"
+	"net/url"
 
 	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl"
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
@@ -31,15 +33,20 @@ func init() {
 }
 
 type envelopeType string
+type formatType string
 
 const (
 	optCursor     = `cursor`
 	optEnvelope   = `envelope`
+	optFormat     = `format`
 	optTimestamps = `timestamps`
 
 	optEnvelopeKeyOnly envelopeType = `key_only`
 	optEnvelopeRow     envelopeType = `row`
 
+	optFormatJSON formatType = `json`
+	optFormatAvro formatType = `avro`
+
 	sinkSchemeChannel    = ``
 	sinkSchemeKafka      = `kafka`
 	sinkParamTopicPrefix = `topic_prefix`
@@ -48,6 +55,7 @@ const (
 var changefeedOptionExpectValues = map[string]bool{
 	optCursor:     true,
 	optEnvelope:   true,
+	optFormat:     true,
 	optTimestamps: false,
 }
 
@@ -159,9 +167,13 @@ func changefeedPlanHook(
 		// Make a channel for runChangefeedFlow to signal once everything has
 		// been setup okay. This intentionally abuses what would normally be
 		// hooked up to resultsCh to avoid a bunch of extra plumbing.
+		jobDescription, err := changefeedJobDescription(changefeedStmt, sinkURI)
+		if err != nil {
+			return err
+		}
 		startedCh := make(chan tree.Datums)
 		job, errCh, err := p.ExecCfg().JobRegistry.StartJob(ctx, startedCh, jobs.Record{
-			Description: changefeedJobDescription(changefeedStmt),
+			Description: jobDescription,
 			Username:    p.User(),
 			DescriptorIDs: func() (sqlDescIDs []sqlbase.ID) {
 				for _, desc := range targetDescs {
@@ -192,8 +204,24 @@ func changefeedPlanHook(
 	return fn, header, nil, nil
 }
 
-func changefeedJobDescription(changefeed *tree.CreateChangefeed) string {
-	return tree.AsStringWithFlags(changefeed, tree.FmtAlwaysQualifyTableNames)
+func changefeedJobDescription(changefeed *tree.CreateChangefeed, sinkURI string) (string, error) {
+	c := &tree.CreateChangefeed{
+		Targets: changefeed.Targets,
+		SinkURI: tree.NewDString(sinkURI),
+		Options: changefeed.Options,
+	}
+	// Cloud storage sinks keep their credentials in the URI, like the
+	// destinations of BACKUP.
+	if u, err := url.Parse(sinkURI); err != nil {
+		return "", err
+	} else if isCloudStorageSink(u) {
+		sanitized, err := storageccl.SanitizeExportStorageURI(sinkURI)
+		if err != nil {
+			return "", err
+		}
+		c.SinkURI = tree.NewDString(sanitized)
+	}
+	return tree.AsStringWithFlags(c, tree.FmtAlwaysQualifyTableNames), nil
 }
 
 func validateChangefeed(details jobspb.ChangefeedDetails) (jobspb.ChangefeedDetails, error) {
@@ -214,6 +242,19 @@ func validateChangefeed(details jobspb.ChangefeedDetails) (jobspb.ChangefeedDeta
 			`unknown %s: %s`, optEnvelope, details.Opts[optEnvelope])
 	}
 
+	switch formatType(details.Opts[optFormat]) {
+	case ``, optFormatJSON:
+		details.Opts[optFormat] = string(optFormatJSON)
+	case optFormatAvro:
+		// TODO(dan): Encode rows with Avro, which also needs a schema
+		// registry for the sink to be usable with Kafka consumers.
+		return jobspb.ChangefeedDetails{}, errors.Errorf(
+			`%s=%s is not supported yet`, optFormat, optFormatAvro)
+	default:
+		return jobspb.ChangefeedDetails{}, errors.Errorf(
+			`unknown %s: %s`, optFormat, details.Opts[optFormat])
+	}
+
 	for _, tableDesc := range details.TableDescs {
 		if len(tableDesc.Families) != 1 {
 			return jobspb.ChangefeedDetails{}, errors.Errorf(
@@ -68,6 +68,43 @@ func TestChangefeedBasics(t *testing.T) {
 	})
 }
 
+func TestChangefeedRangefeed(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer utilccl.TestingEnableEnterprise()()
+
+	ctx := context.Background()
+	s, sqlDBRaw, _ := serverutils.StartServer(t, base.TestServerArgs{
+		UseDatabase: "d",
+		// TODO(dan): HACK until the changefeed can control pgwire flushing.
+		ConnResultsBufferBytes: 1,
+	})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(sqlDBRaw)
+	sqlDB.Exec(t, `SET CLUSTER SETTING kv.rangefeed.enabled = true`)
+	sqlDB.Exec(t, `CREATE DATABASE d`)
+	sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING)`)
+	sqlDB.Exec(t, `INSERT INTO foo VALUES (0, 'initial')`)
+
+	rows := sqlDB.Query(t, `CREATE CHANGEFEED FOR foo`)
+	defer closeFeedRowsHack(t, sqlDB, rows)
+
+	// The initial scan is done with an ExportRequest.
+	assertPayloads(t, rows, []string{
+		`foo: [0]->{"a": 0, "b": "initial"}`,
+	})
+
+	// Later changes are pushed by rangefeeds, including each version of a
+	// key that's written more than once.
+	sqlDB.Exec(t, `INSERT INTO foo VALUES (1, 'a')`)
+	sqlDB.Exec(t, `UPSERT INTO foo VALUES (1, 'b')`)
+	sqlDB.Exec(t, `DELETE FROM foo WHERE a = 0`)
+	assertPayloads(t, rows, []string{
+		`foo: [1]->{"a": 1, "b": "a"}`,
+		`foo: [1]->{"a": 1, "b": "b"}`,
+		`foo: [0]->`,
+	})
+}
+
 func TestChangefeedEnvelope(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer utilccl.TestingEnableEnterprise()()
@@ -286,6 +323,16 @@ func TestChangefeedErrors(t *testing.T) {
 	); !testutils.IsError(err, `omit the SINK clause`) {
 		t.Fatalf(`expected 'omit the SINK clause' error got: %+v`, err)
 	}
+	if _, err := sqlDB.DB.Exec(
+		`CREATE CHANGEFEED FOR foo WITH format='avro'`,
+	); !testutils.IsError(err, `format=avro is not supported yet`) {
+		t.Fatalf(`expected 'format=avro is not supported yet' error got: %+v`, err)
+	}
+	if _, err := sqlDB.DB.Exec(
+		`CREATE CHANGEFEED FOR foo WITH format='nope'`,
+	); !testutils.IsError(err, `unknown format: nope`) {
+		t.Fatalf(`expected 'unknown format: nope' error got: %+v`, err)
+	}
 
 }
 
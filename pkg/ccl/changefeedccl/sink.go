@@ -31,6 +31,9 @@ type SinkRow struct {
 type Sink interface {
 	EmitRows(ctx context.Context, rows []SinkRow) error
 	EmitResolvedTimestamp(ctx context.Context, payload []byte) error
+	// Flush blocks until every row emitted so far is durably written to the
+	// sink. It's called before the changefeed's progress is checkpointed.
+	Flush(ctx context.Context) error
 	Close() error
 }
 
@@ -153,6 +156,12 @@ func (s *kafkaSink) EmitResolvedTimestamp(ctx context.Context, payload []byte) e
 	return s.SendMessages(messages)
 }
 
+// Flush implements the Sink interface. Messages are sent synchronously, so
+// there's nothing to do.
+func (s *kafkaSink) Flush(ctx context.Context) error {
+	return nil
+}
+
 type changefeedPartitioner struct {
 	hash sarama.Partitioner
 }
@@ -211,6 +220,10 @@ func (s *channelSink) emitDatums(ctx context.Context, row tree.Datums) error {
 	}
 }
 
+func (s *channelSink) Flush(ctx context.Context) error {
+	return nil
+}
+
 func (s *channelSink) Close() error {
 	// nil the channel so any later calls to EmitRow (there shouldn't be any)
 	// don't work.
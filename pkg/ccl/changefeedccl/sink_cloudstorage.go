@@ -0,0 +1,180 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/pkg/errors"
+)
+
+// isCloudStorageSink returns whether the sink URI is handled by
+// cloudStorageSink, which is the case for every scheme supported by
+// storageccl.ExportStorage.
+func isCloudStorageSink(u *url.URL) bool {
+	switch u.Scheme {
+	case `s3`, `gs`, `azure`, `http`, `https`, `nodelocal`:
+		return true
+	default:
+		return false
+	}
+}
+
+// cloudStorageSinkFlushBytes is the size above which buffered rows are written
+// out as files even if no resolved timestamp has been emitted.
+const cloudStorageSinkFlushBytes = 16 << 20
+
+// cloudStorageSink writes changefeed output to files in cloud storage (or
+// anywhere else supported by storageccl.ExportStorage), for users that don't
+// run Kafka.
+//
+// Rows are buffered in memory per topic and written out as newline-delimited
+// JSON files when the buffer grows large or when the changefeed flushes before
+// checkpointing its progress. Each line is an object with the `key` and
+// `value` of a row; the value is null for deletions and key_only envelopes.
+// Resolved timestamps are written to their own files, after every file holding
+// rows below them. Filenames are
+//
+//	<date>/<time>-<sink id>-<seq>-<topic>.ndjson
+//	<date>/<time>-<sink id>-<seq>.RESOLVED
+//
+// where <time> is the wall time the file was written and <seq> increases by
+// one for every file written by the sink. Listing and sorting the files of a
+// date (and then the dates) thus yields them in the order they were written,
+// as long as clocks don't move backward between changefeed restarts, and a
+// consumer that has seen a RESOLVED file has seen all the rows below its
+// timestamp. As with Kafka, rows may be repeated (in a later file) but are
+// never lost.
+type cloudStorageSink struct {
+	es     storageccl.ExportStorage
+	sinkID string
+
+	// fileSeq is the sequence number of the next file written.
+	fileSeq int
+	// buffers holds the rows not yet written, keyed by topic.
+	buffers       map[string]*bytes.Buffer
+	bufferedBytes int
+
+	rowsEmitted  uint64
+	bytesEmitted uint64
+}
+
+func makeCloudStorageSink(
+	ctx context.Context, sinkURI string, settings *cluster.Settings,
+) (Sink, error) {
+	es, err := storageccl.ExportStorageFromURI(ctx, sinkURI, settings)
+	if err != nil {
+		return nil, err
+	}
+	return &cloudStorageSink{
+		es: es,
+		// Every incarnation of the changefeed gets a new ID so that its files
+		// never overwrite those of a previous one.
+		sinkID:  uuid.MakeV4().Short(),
+		buffers: make(map[string]*bytes.Buffer),
+	}, nil
+}
+
+// EmitRows implements the Sink interface.
+func (s *cloudStorageSink) EmitRows(ctx context.Context, rows []SinkRow) error {
+	for _, row := range rows {
+		buf, ok := s.buffers[row.Topic]
+		if !ok {
+			buf = &bytes.Buffer{}
+			s.buffers[row.Topic] = buf
+		}
+		before := buf.Len()
+		buf.WriteString(`{"key":`)
+		buf.Write(row.Key)
+		buf.WriteString(`,"value":`)
+		if len(row.Value) > 0 {
+			buf.Write(row.Value)
+		} else {
+			buf.WriteString(`null`)
+		}
+		buf.WriteString("}\n")
+		s.bufferedBytes += buf.Len() - before
+	}
+	s.rowsEmitted += uint64(len(rows))
+
+	if s.bufferedBytes >= cloudStorageSinkFlushBytes {
+		return s.Flush(ctx)
+	}
+	return nil
+}
+
+// EmitResolvedTimestamp implements the Sink interface.
+func (s *cloudStorageSink) EmitResolvedTimestamp(ctx context.Context, payload []byte) error {
+	// Consumers rely on every row emitted before a resolved timestamp being
+	// written before it.
+	if err := s.Flush(ctx); err != nil {
+		return err
+	}
+	return s.writeFile(ctx, `.RESOLVED`, append(payload, '\n'))
+}
+
+// Flush implements the Sink interface.
+func (s *cloudStorageSink) Flush(ctx context.Context) error {
+	if s.bufferedBytes == 0 {
+		return nil
+	}
+	// Write the topics in a deterministic order.
+	topics := make([]string, 0, len(s.buffers))
+	for topic, buf := range s.buffers {
+		if buf.Len() > 0 {
+			topics = append(topics, topic)
+		}
+	}
+	sort.Strings(topics)
+	for _, topic := range topics {
+		buf := s.buffers[topic]
+		suffix := `-` + url.PathEscape(topic) + `.ndjson`
+		if err := s.writeFile(ctx, suffix, buf.Bytes()); err != nil {
+			return err
+		}
+		s.bytesEmitted += uint64(buf.Len())
+		buf.Reset()
+	}
+	if log.V(1) {
+		log.Infof(ctx, "flushed %s to cloud storage. total %d records (%s)",
+			humanize.IBytes(uint64(s.bufferedBytes)), s.rowsEmitted, humanize.IBytes(s.bytesEmitted))
+	}
+	s.bufferedBytes = 0
+	return nil
+}
+
+func (s *cloudStorageSink) writeFile(ctx context.Context, suffix string, content []byte) error {
+	now := timeutil.Now()
+	filename := fmt.Sprintf(`%s/%s-%s-%08d%s`,
+		now.Format(`2006-01-02`), now.Format(`150405.000000000`), s.sinkID, s.fileSeq, suffix)
+	if err := s.es.WriteFile(ctx, filename, bytes.NewReader(content)); err != nil {
+		return errors.Wrapf(err, `writing %s to cloud storage`, filename)
+	}
+	s.fileSeq++
+	return nil
+}
+
+// Close implements the Sink interface. Buffered rows are dropped; they were
+// never flushed, so the changefeed's progress doesn't include them and they'll
+// be emitted again when it's resumed.
+func (s *cloudStorageSink) Close() error {
+	s.buffers = nil
+	return s.es.Close()
+}
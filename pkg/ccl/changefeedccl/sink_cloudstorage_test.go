@@ -0,0 +1,120 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestCloudStorageSink(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	dir, dirCleanupFn := testutils.TempDir(t)
+	defer dirCleanupFn()
+
+	settings := cluster.MakeTestingClusterSettings()
+	settings.ExternalIODir = dir
+	sink, err := makeCloudStorageSink(ctx, `nodelocal:///feed`, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := sink.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// readFiles returns the names, without the time and sink id, and contents
+	// of the files written so far, in the order a consumer would read them.
+	readFiles := func() (names []string, contents []string) {
+		var paths []string
+		if err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() {
+				paths = append(paths, path)
+			}
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			buf, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			names = append(names, strings.SplitN(filepath.Base(path), `-`, 3)[2])
+			contents = append(contents, string(buf))
+		}
+		return names, contents
+	}
+
+	if err := sink.EmitRows(ctx, []SinkRow{
+		{Topic: `foo`, Key: []byte(`[1]`), Value: []byte(`{"a": 1}`)},
+		{Topic: `bar`, Key: []byte(`[2]`), Value: []byte(`{"b": 2}`)},
+		{Topic: `foo`, Key: []byte(`[3]`)},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// Nothing is written until the sink is flushed.
+	if names, _ := readFiles(); len(names) != 0 {
+		t.Fatalf(`expected no files got %v`, names)
+	}
+
+	if err := sink.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.EmitRows(ctx, []SinkRow{
+		{Topic: `foo`, Key: []byte(`[4]`), Value: []byte(`{"a": 4}`)},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// Emitting a resolved timestamp flushes the rows before it.
+	if err := sink.EmitResolvedTimestamp(ctx, []byte(`{"resolved": "1"}`)); err != nil {
+		t.Fatal(err)
+	}
+	// Flushing without buffered rows doesn't write anything.
+	if err := sink.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	names, contents := readFiles()
+	expectedNames := []string{
+		`00000000-bar.ndjson`,
+		`00000001-foo.ndjson`,
+		`00000002-foo.ndjson`,
+		`00000003.RESOLVED`,
+	}
+	if !reflect.DeepEqual(expectedNames, names) {
+		t.Fatalf("expected\n%v\ngot\n%v", expectedNames, names)
+	}
+	expectedContents := []string{
+		"{\"key\":[2],\"value\":{\"b\": 2}}\n",
+		"{\"key\":[1],\"value\":{\"a\": 1}}\n{\"key\":[3],\"value\":null}\n",
+		"{\"key\":[4],\"value\":{\"a\": 4}}\n",
+		"{\"resolved\": \"1\"}\n",
+	}
+	if !reflect.DeepEqual(expectedContents, contents) {
+		t.Fatalf("expected\n%q\ngot\n%q", expectedContents, contents)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+)
+
+type spanFrontierEntry struct {
+	span roachpb.Span
+	ts   hlc.Timestamp
+}
+
+// spanFrontier tracks the minimum timestamp of a set of spans, where each span
+// is independently forwarded to higher timestamps. It's used to turn the
+// per-range checkpoints of RangeFeeds into a single resolved timestamp for a
+// changefeed.
+//
+// The tracked spans are kept as a sorted slice of non-overlapping entries,
+// which are split and merged as parts of them are forwarded. Changefeeds watch
+// few spans and each of them only ever splits on range boundaries, so this is
+// simple and cheap enough.
+type spanFrontier struct {
+	entries []spanFrontierEntry
+}
+
+// makeSpanFrontier returns a spanFrontier that tracks the given spans, each
+// starting at the zero timestamp. The spans must not overlap.
+func makeSpanFrontier(spans ...roachpb.Span) *spanFrontier {
+	f := &spanFrontier{entries: make([]spanFrontierEntry, 0, len(spans))}
+	for _, s := range spans {
+		f.entries = append(f.entries, spanFrontierEntry{span: s})
+	}
+	sort.Slice(f.entries, func(i, j int) bool {
+		return bytes.Compare(f.entries[i].span.Key, f.entries[j].span.Key) < 0
+	})
+	return f
+}
+
+// Frontier returns the minimum timestamp being tracked.
+func (f *spanFrontier) Frontier() hlc.Timestamp {
+	var frontier hlc.Timestamp
+	for i, e := range f.entries {
+		if i == 0 || e.ts.Less(frontier) {
+			frontier = e.ts
+		}
+	}
+	return frontier
+}
+
+// Forward advances the timestamp of the parts of the tracked spans that
+// overlap the given span to at least the given timestamp. Parts of the given
+// span that aren't tracked are ignored.
+func (f *spanFrontier) Forward(span roachpb.Span, ts hlc.Timestamp) {
+	entries := make([]spanFrontierEntry, 0, len(f.entries)+2)
+	appendEntry := func(e spanFrontierEntry) {
+		if bytes.Compare(e.span.Key, e.span.EndKey) >= 0 {
+			return
+		}
+		// Merge the entry into the previous one if they're adjacent and have
+		// the same timestamp, to keep the number of entries bounded.
+		if n := len(entries); n > 0 && entries[n-1].ts == e.ts &&
+			entries[n-1].span.EndKey.Equal(e.span.Key) {
+			entries[n-1].span.EndKey = e.span.EndKey
+			return
+		}
+		entries = append(entries, e)
+	}
+	for _, e := range f.entries {
+		if !e.span.Overlaps(span) || !e.ts.Less(ts) {
+			appendEntry(e)
+			continue
+		}
+		start, end := e.span.Key, e.span.EndKey
+		if bytes.Compare(span.Key, start) > 0 {
+			start = span.Key
+		}
+		if bytes.Compare(span.EndKey, end) < 0 {
+			end = span.EndKey
+		}
+		appendEntry(spanFrontierEntry{span: roachpb.Span{Key: e.span.Key, EndKey: start}, ts: e.ts})
+		appendEntry(spanFrontierEntry{span: roachpb.Span{Key: start, EndKey: end}, ts: ts})
+		appendEntry(spanFrontierEntry{span: roachpb.Span{Key: end, EndKey: e.span.EndKey}, ts: e.ts})
+	}
+	f.entries = entries
+}
@@ -0,0 +1,61 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestSpanFrontier(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	span := func(start, end string) roachpb.Span {
+		return roachpb.Span{Key: roachpb.Key(start), EndKey: roachpb.Key(end)}
+	}
+	ts := func(wallTime int64) hlc.Timestamp {
+		return hlc.Timestamp{WallTime: wallTime}
+	}
+
+	f := makeSpanFrontier(span(`d`, `f`), span(`a`, `c`))
+	if expected, actual := (hlc.Timestamp{}), f.Frontier(); expected != actual {
+		t.Fatalf(`expected %s got %s`, expected, actual)
+	}
+
+	tests := []struct {
+		span     roachpb.Span
+		ts       hlc.Timestamp
+		expected hlc.Timestamp
+	}{
+		// Only part of the tracked spans is forwarded.
+		{span(`a`, `c`), ts(2), ts(0)},
+		// An untracked span is ignored.
+		{span(`x`, `z`), ts(5), ts(0)},
+		// A span overlapping the end of a tracked span only forwards the
+		// overlap, so the frontier stays where the rest of the span is.
+		{span(`e`, `z`), ts(3), ts(0)},
+		{span(`c`, `e`), ts(4), ts(2)},
+		// Timestamps never regress.
+		{span(`a`, `f`), ts(1), ts(2)},
+		// Part of a tracked span lags behind the rest.
+		{span(`a`, `b`), ts(5), ts(2)},
+		{span(`b`, `z`), ts(6), ts(5)},
+		{span(`a`, `z`), ts(7), ts(7)},
+	}
+	for i, test := range tests {
+		f.Forward(test.span, test.ts)
+		if actual := f.Frontier(); test.expected != actual {
+			t.Fatalf(`%d: forwarding %s to %s: expected %s got %s`,
+				i, test.span, test.ts, test.expected, actual)
+		}
+	}
+}
@@ -58,6 +58,8 @@ const (
 
 	pgCopyDelimiter = "delimiter"
 	pgCopyNull      = "nullif"
+
+	pgURLSourceTable = "source_table"
 )
 
 var importOptionExpectValues = map[string]bool{
@@ -71,6 +73,8 @@ var importOptionExpectValues = map[string]bool{
 	mysqlOutfileEnclose:  true,
 	mysqlOutfileEscape:   true,
 
+	pgURLSourceTable: true,
+
 	importOptionTransform:  true,
 	importOptionSSTSize:    true,
 	importOptionDecompress: true,
@@ -217,8 +221,12 @@ func importJobDescription(
 	stmt.CreateFile = nil
 	stmt.CreateDefs = defs
 	stmt.Files = nil
+	sanitize := storageccl.SanitizeExportStorageURI
+	if orig.FileFormat == "PGURL" {
+		sanitize = sanitizePgURL
+	}
 	for _, file := range files {
-		clean, err := storageccl.SanitizeExportStorageURI(file)
+		clean, err := sanitize(file)
 		if err != nil {
 			return "", err
 		}
@@ -437,6 +445,15 @@ func importPlanHook(
 			}
 		case "PGDUMP":
 			format.Format = roachpb.IOFileFormat_PgDump
+		case "PGURL":
+			if !p.ExecCfg().Settings.Version.IsMinSupported(cluster.VersionImportPgURL) {
+				return errors.Errorf("Using %s requires all nodes to be upgraded to %s",
+					importStmt.FileFormat, cluster.VersionByKey(cluster.VersionImportPgURL))
+			}
+			format.Format = roachpb.IOFileFormat_PgURL
+			if override, ok := opts[pgURLSourceTable]; ok {
+				format.PgURL.Table = override
+			}
 		default:
 			return errors.Errorf("unsupported import format: %q", importStmt.FileFormat)
 		}
@@ -477,7 +494,7 @@ func importPlanHook(
 		var tableDescs []*sqlbase.TableDescriptor
 		var jobDesc string
 		var names []string
-		if importStmt.Bundle {
+		if importStmt.Bundle && format.Format != roachpb.IOFileFormat_PgURL {
 			store, err := storageccl.ExportStorageFromURI(ctx, files[0], p.ExecCfg().Settings)
 			if err != nil {
 				return err
@@ -515,11 +532,19 @@ func importPlanHook(
 			jobDesc = descStr
 		} else {
 			if table == nil {
-				return errors.Errorf("non-bundle format %q should always have a table name", importStmt.FileFormat)
+				return errors.Errorf("format %q should always have a table name", importStmt.FileFormat)
 			}
 			var create *tree.CreateTable
 			if importStmt.CreateDefs != nil {
 				create = &tree.CreateTable{Table: importStmt.Table, Defs: importStmt.CreateDefs}
+			} else if importStmt.Bundle {
+				// A PGURL bundle reads the schema of the source table, which has the
+				// same name as the table being imported, from the source server.
+				create, err = readPgURLCreateTable(ctx, files[0], table.TableName.String())
+				if err != nil {
+					return err
+				}
+				create.Table = importStmt.Table
 			} else {
 				filename, err := createFileFn()
 				if err != nil {
@@ -543,7 +568,12 @@ func importPlanHook(
 				return err
 			}
 			tableDescs = []*sqlbase.TableDescriptor{tbl}
-			descStr, err := importJobDescription(importStmt, create.Defs, files, opts)
+			jobDefs := create.Defs
+			if importStmt.Bundle {
+				// The schema read from a bundle isn't part of the statement.
+				jobDefs = nil
+			}
+			descStr, err := importJobDescription(importStmt, jobDefs, files, opts)
 			if err != nil {
 				return err
 			}
@@ -17,9 +17,11 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"strings"
 	"testing"
@@ -1856,3 +1858,76 @@ func TestImportPgDump(t *testing.T) {
 		})
 	}
 }
+
+func TestImportPgURL(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const (
+		nodes = 3
+	)
+	ctx := context.Background()
+	tc := testcluster.StartTestCluster(t, nodes, base.TestClusterArgs{})
+	defer tc.Stopper().Stop(ctx)
+	conn := tc.Conns[0]
+	sqlDB := sqlutils.MakeSQLRunner(conn)
+
+	sqlDB.Exec(t, `SET CLUSTER SETTING kv.import.batch_size = '10KB'`)
+
+	// The source table is read from the same cluster, via its pgwire port.
+	sqlDB.Exec(t, `CREATE DATABASE src`)
+	sqlDB.Exec(t, `CREATE TABLE src.t (
+		i INT PRIMARY KEY, s STRING, b BYTES, f FLOAT, ts TIMESTAMP, d DATE, ok BOOL, INDEX (s)
+	)`)
+	sqlDB.Exec(t, `INSERT INTO src.t SELECT
+		i, 'str' || i::STRING, b'\x00\x5c' || i::STRING::BYTES, i::FLOAT / 2,
+		'2018-01-01'::TIMESTAMP + i * INTERVAL '1s', '2018-01-01'::DATE + i, i % 2 = 0
+	FROM generate_series(1, 1000) AS g(i)`)
+	sqlDB.Exec(t, `INSERT INTO src.t (i) VALUES (0)`)
+	sqlDB.Exec(t, `CREATE DATABASE foo; SET DATABASE = foo`)
+
+	srcURL, cleanup := sqlutils.PGUrl(t, tc.Server(0).ServingAddr(), "TestImportPgURL", url.User(security.RootUser))
+	defer cleanup()
+	srcURL.Path = "src"
+
+	expected := sqlDB.QueryStr(t, `SELECT * FROM src.t ORDER BY i`)
+
+	t.Run("schema from source", func(t *testing.T) {
+		sqlDB.Exec(t, `IMPORT TABLE t FROM PGURL ($1)`, srcURL.String())
+		if actual := sqlDB.QueryStr(t, `SELECT * FROM foo.t ORDER BY i`); !reflect.DeepEqual(expected, actual) {
+			t.Fatalf("expected %v, got %v", expected, actual)
+		}
+		sqlDB.CheckQueryResults(t, `SELECT count(*) FROM foo.t@t_s_idx`, [][]string{{"1001"}})
+	})
+
+	t.Run("explicit schema", func(t *testing.T) {
+		sqlDB.Exec(t, `IMPORT TABLE u (
+			i INT PRIMARY KEY, s STRING, b BYTES, f FLOAT, ts TIMESTAMP, d DATE, ok BOOL
+		) PGURL DATA ($1) WITH source_table = 't'`, srcURL.String())
+		if actual := sqlDB.QueryStr(t, `SELECT * FROM foo.u ORDER BY i`); !reflect.DeepEqual(expected, actual) {
+			t.Fatalf("expected %v, got %v", expected, actual)
+		}
+	})
+
+	t.Run("password redacted", func(t *testing.T) {
+		// The test server authenticates root with its client certificate, so the
+		// password is ignored by the source but must not end up in the job.
+		withPassword := srcURL
+		withPassword.User = url.UserPassword(security.RootUser, "secret")
+		sqlDB.Exec(t, `IMPORT TABLE v (i INT PRIMARY KEY) PGURL DATA ($1) WITH source_table = 't'`,
+			withPassword.String())
+		var description string
+		sqlDB.QueryRow(t,
+			`SELECT description FROM [SHOW JOBS] WHERE description LIKE 'IMPORT TABLE foo.public.v%'`,
+		).Scan(&description)
+		if strings.Contains(description, "secret") {
+			t.Fatalf("expected password to be redacted from job description: %s", description)
+		}
+	})
+
+	t.Run("unsupported scheme", func(t *testing.T) {
+		_, err := conn.Exec(`IMPORT TABLE w (i INT PRIMARY KEY) PGURL DATA ('nodelocal:///t')`)
+		if !testutils.IsError(err, "unsupported PGURL scheme") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
@@ -0,0 +1,259 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"bytes"
+	"context"
+	gosql "database/sql"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
+	"github.com/pkg/errors"
+	// Register the postgres driver used to connect to the source server.
+	_ "github.com/lib/pq"
+)
+
+// pgURLReader reads the rows of a table directly from a postgres-compatible
+// server (such as another CockroachDB cluster), instead of from a file.
+//
+// The source table is scanned with a plain SELECT, without any transaction
+// spanning the separate sampling and reading phases of the IMPORT, so the
+// source table should not be modified while it is being imported.
+type pgURLReader struct {
+	conv rowConverter
+	opts roachpb.PgURLOptions
+}
+
+var _ inputConverter = &pgURLReader{}
+
+func newPgURLReader(
+	kvCh chan kvBatch,
+	opts roachpb.PgURLOptions,
+	tableDesc *sqlbase.TableDescriptor,
+	evalCtx *tree.EvalContext,
+) (*pgURLReader, error) {
+	conv, err := newRowConverter(tableDesc, evalCtx, kvCh)
+	if err != nil {
+		return nil, err
+	}
+	return &pgURLReader{
+		conv: *conv,
+		opts: opts,
+	}, nil
+}
+
+func (d *pgURLReader) start(ctx ctxgroup.Group) {
+}
+
+func (d *pgURLReader) inputFinished(ctx context.Context) {
+	close(d.conv.kvCh)
+}
+
+// readFile implements inputConverter. There is no file to read: input is nil
+// and inputName is the URL of the server to read the table from.
+func (d *pgURLReader) readFile(
+	ctx context.Context, _ io.Reader, inputIdx int32, inputName string, progressFn progressFn,
+) error {
+	table := d.opts.Table
+	if table == "" {
+		table = d.conv.tableDesc.Name
+	}
+	tn, err := parser.ParseTableName(table)
+	if err != nil {
+		return errors.Wrapf(err, "invalid source table %q", table)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("SELECT ")
+	for i := range d.conv.visibleCols {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(tree.NameString(d.conv.visibleCols[i].Name))
+	}
+	fmt.Fprintf(&buf, " FROM %s", tree.AsString(tn))
+
+	db, err := gosql.Open("postgres", inputName)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, buf.String())
+	if err != nil {
+		return errors.Wrapf(err, "reading %s", tree.AsString(tn))
+	}
+	defer rows.Close()
+
+	vals := make([]interface{}, len(d.conv.visibleCols))
+	dests := make([]interface{}, len(vals))
+	for i := range vals {
+		dests[i] = &vals[i]
+	}
+
+	var count int64 = 1
+	for rows.Next() {
+		if err := rows.Scan(dests...); err != nil {
+			return makeRowErr(table, count, "%s", err)
+		}
+		for i, v := range vals {
+			datum, err := pgURLValueToDatum(v, d.conv.visibleColTypes[i], d.conv.evalCtx)
+			if err != nil {
+				col := d.conv.visibleCols[i]
+				return makeRowErr(table, count, "parse %q as %s: %s", col.Name, col.Type.SQLString(), err)
+			}
+			d.conv.datums[i] = datum
+		}
+		if err := d.conv.row(ctx, inputIdx, count); err != nil {
+			return makeRowErr(table, count, "%s", err)
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return errors.Wrapf(err, "reading %s", tree.AsString(tn))
+	}
+	if err := progressFn(true /* finished */); err != nil {
+		return err
+	}
+	return d.conv.sendBatch(ctx)
+}
+
+// pgURLValueToDatum converts a value returned by the postgres driver to a
+// datum of type typ. The driver decodes some types itself (integers, floats,
+// bools, times and byte arrays); everything else is returned in its text
+// representation, which is parsed like any other imported string.
+func pgURLValueToDatum(v interface{}, typ types.T, evalCtx *tree.EvalContext) (tree.Datum, error) {
+	var s string
+	switch v := v.(type) {
+	case nil:
+		return tree.DNull, nil
+	case []byte:
+		if typ == types.Bytes {
+			return tree.NewDBytes(tree.DBytes(v)), nil
+		}
+		s = string(v)
+	case string:
+		s = v
+	case int64:
+		s = strconv.FormatInt(v, 10)
+	case float64:
+		s = strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		s = strconv.FormatBool(v)
+	case time.Time:
+		switch typ {
+		case types.Timestamp:
+			return tree.MakeDTimestamp(v, time.Microsecond), nil
+		case types.TimestampTZ:
+			return tree.MakeDTimestampTZ(v, time.Microsecond), nil
+		case types.Date:
+			return tree.NewDDateFromTime(v, time.UTC), nil
+		case types.Time:
+			return tree.MakeDTime(timeofday.FromTime(v)), nil
+		}
+		s = v.Format(time.RFC3339Nano)
+	default:
+		return nil, errors.Errorf("unexpected value of type %T", v)
+	}
+	return tree.ParseStringAs(typ, s, evalCtx)
+}
+
+// readPgURLInputs reads each of the passed source URLs using the passed func.
+// Unlike readInputFiles, it doesn't open the inputs itself: fileFunc connects
+// to the server named by each URL. Progress is reported after each URL.
+func readPgURLInputs(
+	ctx context.Context,
+	dataFiles map[int32]string,
+	fileFunc readFileFunc,
+	progressFn func(float32) error,
+) error {
+	noProgress := func(finished bool) error { return nil }
+	currentFile := 0
+	for dataFileIndex, dataFile := range dataFiles {
+		currentFile++
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fileFunc(ctx, nil /* input */, dataFileIndex, dataFile, noProgress); err != nil {
+			clean, cleanErr := sanitizePgURL(dataFile)
+			if cleanErr != nil {
+				return err
+			}
+			return errors.Wrap(err, clean)
+		}
+		if progressFn != nil {
+			if err := progressFn(float32(currentFile) / float32(len(dataFiles))); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// readPgURLCreateTable reads the schema of the named table from the server at
+// uri. The schema is read with SHOW CREATE TABLE, so this requires the server
+// to be a CockroachDB cluster; importing from other servers requires
+// specifying the schema of the table explicitly.
+func readPgURLCreateTable(ctx context.Context, uri string, table string) (*tree.CreateTable, error) {
+	tn, err := parser.ParseTableName(table)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid source table %q", table)
+	}
+	db, err := gosql.Open("postgres", uri)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	var name, createStmt string
+	if err := db.QueryRowContext(
+		ctx, fmt.Sprintf("SHOW CREATE TABLE %s", tree.AsString(tn)),
+	).Scan(&name, &createStmt); err != nil {
+		return nil, errors.Wrapf(err, "reading schema of %s", tree.AsString(tn))
+	}
+	stmt, err := parser.ParseOne(createStmt)
+	if err != nil {
+		return nil, err
+	}
+	create, ok := stmt.(*tree.CreateTable)
+	if !ok {
+		return nil, errors.Errorf("expected CREATE TABLE statement for %s", tree.AsString(tn))
+	}
+	return create, nil
+}
+
+// sanitizePgURL returns the passed postgres URL with its password redacted and
+// its query parameters, which may name client certificates, removed.
+func sanitizePgURL(path string) (string, error) {
+	uri, err := url.Parse(path)
+	if err != nil {
+		return "", err
+	}
+	if uri.Scheme != "postgres" && uri.Scheme != "postgresql" {
+		return "", errors.Errorf("unsupported PGURL scheme: %q", uri.Scheme)
+	}
+	if uri.User != nil {
+		if _, ok := uri.User.Password(); ok {
+			uri.User = url.UserPassword(uri.User.Username(), "redacted")
+		}
+	}
+	uri.RawQuery = ""
+	return uri.String(), nil
+}
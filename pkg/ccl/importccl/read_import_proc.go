@@ -62,6 +62,10 @@ func readInputFiles(
 	progressFn func(float32) error,
 	settings *cluster.Settings,
 ) error {
+	if format.Format == roachpb.IOFileFormat_PgURL {
+		return readPgURLInputs(ctx, dataFiles, fileFunc, progressFn)
+	}
+
 	done := ctx.Done()
 
 	var totalBytes, readBytes int64
@@ -440,6 +444,8 @@ func (cp *readImportDataProcessor) doRun(ctx context.Context, wg *sync.WaitGroup
 		conv, err = newPgCopyReader(kvCh, cp.spec.Format.PgCopy, singleTable, evalCtx)
 	case roachpb.IOFileFormat_PgDump:
 		conv, err = newPgDumpReader(kvCh, cp.spec.Tables, evalCtx)
+	case roachpb.IOFileFormat_PgURL:
+		conv, err = newPgURLReader(kvCh, cp.spec.Format.PgURL, singleTable, evalCtx)
 	default:
 		err = errors.Errorf("Requested IMPORT format (%d) not supported by this node", cp.spec.Format.Format)
 	}
@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/pkg/errors"
 )
@@ -71,6 +72,10 @@ func evalAddSSTable(
 	}
 	ms.Add(stats)
 
+	if err := checkSSTableTimestamps(batch, args.Data, args.EndKey); err != nil {
+		return result.Result{}, err
+	}
+
 	return result.Result{
 		Replicated: storagebase.ReplicatedEvalResult{
 			AddSSTable: &storagebase.ReplicatedEvalResult_AddSSTable{
@@ -131,3 +136,81 @@ func verifySSTable(
 	}
 	return stats, nil
 }
+
+// checkSSTableTimestamps verifies that the newest version of every key in the
+// sstable is at or above the newest existing version of the key, so that
+// ingesting it can't rewrite history that may already have been read. A
+// version that already exists with the same value is allowed anywhere, since
+// a retried request or a replayed stream ingests the same versions again, but
+// one with a different value is rejected, as ingesting it would replace the
+// existing one.
+func checkSSTableTimestamps(reader engine.Reader, data []byte, end roachpb.Key) error {
+	dataIter, err := engineccl.NewMemSSTIterator(data, false /* verify */)
+	if err != nil {
+		return err
+	}
+	defer dataIter.Close()
+	existingIter := reader.NewIterator(engine.IterOptions{UpperBound: end})
+	defer existingIter.Close()
+
+	var prevKey roachpb.Key
+	for dataIter.Seek(engine.MVCCKey{Key: keys.MinKey}); ; dataIter.Next() {
+		if ok, err := dataIter.Valid(); err != nil {
+			return err
+		} else if !ok {
+			return nil
+		}
+		sstKey := dataIter.UnsafeKey()
+		if sstKey.Key.Equal(prevKey) {
+			// The versions of a key are sorted newest first, and only the newest
+			// one needs to be checked.
+			continue
+		}
+		prevKey = append(prevKey[:0], sstKey.Key...)
+
+		// Walk the existing versions of the key, newest first, until one at or
+		// below the sstable's. The metadata of an intent sorts before them.
+		var newer hlc.Timestamp
+		for existingIter.Seek(engine.MVCCKey{Key: sstKey.Key}); ; existingIter.Next() {
+			if ok, err := existingIter.Valid(); err != nil {
+				return err
+			} else if !ok {
+				break
+			}
+			existingKey := existingIter.UnsafeKey()
+			if !existingKey.Key.Equal(sstKey.Key) {
+				break
+			}
+			if !existingKey.IsValue() {
+				continue
+			}
+			if existingKey.Timestamp == sstKey.Timestamp {
+				if !equalValueData(existingIter.UnsafeValue(), dataIter.UnsafeValue()) {
+					return errors.Errorf("key %s at %s in sstable differs from the existing version",
+						sstKey.Key, sstKey.Timestamp)
+				}
+				newer = hlc.Timestamp{}
+				break
+			}
+			if existingKey.Timestamp.Less(sstKey.Timestamp) {
+				break
+			}
+			if newer == (hlc.Timestamp{}) {
+				newer = existingKey.Timestamp
+			}
+		}
+		if newer != (hlc.Timestamp{}) {
+			return errors.Errorf("key %s at %s in sstable is below an existing version at %s",
+				sstKey.Key, sstKey.Timestamp, newer)
+		}
+	}
+}
+
+// equalValueData compares two encoded values, ignoring their checksums.
+func equalValueData(a, b []byte) bool {
+	// Deletion tombstones are empty and have no checksum.
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+	return roachpb.Value{RawBytes: a}.EqualData(roachpb.Value{RawBytes: b})
+}
@@ -136,8 +136,8 @@ func runTestDBAddSSTable(ctx context.Context, t *testing.T, db *client.DB, store
 		}
 	}
 
-	// Check that ingesting a key with an earlier mvcc timestamp doesn't affect
-	// the value returned by Get.
+	// Check that a key can't be ingested with an earlier mvcc timestamp than its
+	// existing version.
 	{
 		key := engine.MVCCKey{Key: []byte("bb"), Timestamp: hlc.Timestamp{WallTime: 1}}
 		data, err := singleKVSSTable(key, roachpb.MakeValueFromString("2").RawBytes)
@@ -145,8 +145,10 @@ func runTestDBAddSSTable(ctx context.Context, t *testing.T, db *client.DB, store
 			t.Fatalf("%+v", err)
 		}
 
-		if err := db.AddSSTable(ctx, "b", "c", data); err != nil {
-			t.Fatalf("%+v", err)
+		if err := db.AddSSTable(
+			ctx, "b", "c", data,
+		); !testutils.IsError(err, "below an existing version") {
+			t.Fatalf("expected existing version error got: %+v", err)
 		}
 		if r, err := db.Get(ctx, "bb"); err != nil {
 			t.Fatalf("%+v", err)
@@ -155,7 +157,7 @@ func runTestDBAddSSTable(ctx context.Context, t *testing.T, db *client.DB, store
 		}
 		if store != nil {
 			metrics := store.Metrics()
-			if expected, got := int64(2), metrics.AddSSTableApplications.Count(); expected != got {
+			if expected, got := int64(1), metrics.AddSSTableApplications.Count(); expected != got {
 				t.Fatalf("expected %d sst ingestions, got %d", expected, got)
 			}
 		}
@@ -204,7 +206,7 @@ func runTestDBAddSSTable(ctx context.Context, t *testing.T, db *client.DB, store
 			}
 		}
 		if store != nil {
-			if expected, got := int64(4), metrics.AddSSTableApplications.Count(); expected != got {
+			if expected, got := int64(3), metrics.AddSSTableApplications.Count(); expected != got {
 				t.Fatalf("expected %d sst ingestions, got %d", expected, got)
 			}
 			// The second time though we had to make a copy of the SST since rocks saw
@@ -232,6 +234,47 @@ func runTestDBAddSSTable(ctx context.Context, t *testing.T, db *client.DB, store
 	}
 }
 
+func TestAddSSTableBelowExistingVersions(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	e := engine.NewInMem(roachpb.Attributes{}, 1<<20)
+	defer e.Close()
+
+	// Key "a" has versions at 2 and 4.
+	for _, wallTime := range []int64{2, 4} {
+		key := engine.MVCCKey{Key: roachpb.Key("a"), Timestamp: hlc.Timestamp{WallTime: wallTime}}
+		if err := e.Put(key, roachpb.MakeValueFromString("1").RawBytes); err != nil {
+			t.Fatalf("%+v", err)
+		}
+	}
+
+	testCases := []struct {
+		wallTime int64
+		value    string
+		err      string
+	}{
+		{5, "2", ``},
+		// The newest version, or an older one, being ingested again.
+		{4, "1", ``},
+		{2, "1", ``},
+		// An existing version being replaced.
+		{4, "2", `key "a" at 0.000000004,0 in sstable differs from the existing version`},
+		{2, "2", `differs from the existing version`},
+		{3, "2", `key "a" at 0.000000003,0 in sstable is below an existing version at 0.000000004,0`},
+		{1, "2", `below an existing version`},
+	}
+	for _, tc := range testCases {
+		key := engine.MVCCKey{Key: roachpb.Key("a"), Timestamp: hlc.Timestamp{WallTime: tc.wallTime}}
+		data, err := singleKVSSTable(key, roachpb.MakeValueFromString(tc.value).RawBytes)
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		if err := checkSSTableTimestamps(e, data, roachpb.Key("b")); !testutils.IsError(err, tc.err) {
+			t.Errorf("%d/%s: expected error %q, got %v", tc.wallTime, tc.value, tc.err, err)
+		}
+	}
+}
+
 func randomMVCCKeyValues(rng *rand.Rand, numKVs int) []engine.MVCCKeyValue {
 	kvs := make([]engine.MVCCKeyValue, numKVs)
 	for i := range kvs {
@@ -270,7 +313,7 @@ func TestAddSSTableMVCCStats(t *testing.T) {
 	// This test repeatedly:
 	// - puts some random data in an engine
 	// - randomly makes one key an intent
-	// - puts some random data in an sst
+	// - puts some random data, newer than the existing data, in an sst
 	// - computes pre-ingest mvcc stats for the engine
 	// - gets the mvcc stats diff from evalAddSSTable for the sst
 	// - ingests the sstable into the engine
@@ -281,7 +324,9 @@ func TestAddSSTableMVCCStats(t *testing.T) {
 	// ingested sstables.
 	var nowNanos int64
 	for i := 0; i < numIterations; i++ {
-		nowNanos += rng.Int63n(1e9)
+		// Leave room for the timestamps of the random data, which can't be
+		// ingested below the existing versions of its keys.
+		nowNanos += 20 + rng.Int63n(1e9)
 		for _, kv := range randomMVCCKeyValues(rng, 1+rand.Intn(maxKVs)) {
 			if err := e.Put(kv.Key, kv.Value); err != nil {
 				t.Fatalf("%+v", err)
@@ -314,6 +359,9 @@ func TestAddSSTableMVCCStats(t *testing.T) {
 			}
 			defer sst.Close()
 			sstKVs := mvccKeyValues(randomMVCCKeyValues(rng, 1+rand.Intn(maxKVs)))
+			for i := range sstKVs {
+				sstKVs[i].Key.Timestamp.WallTime += nowNanos + 10
+			}
 			sort.Sort(sstKVs)
 			var prevKey engine.MVCCKey
 			for _, kv := range sstKVs {
@@ -0,0 +1,108 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package storageccl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/bulk"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// TestBufferingAdder lives here rather than in the bulk package since it needs
+// AddSSTable, which is evaluated by this package.
+func TestBufferingAdder(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	s, _, db := serverutils.StartServer(t, base.TestServerArgs{Insecure: true})
+	defer s.Stopper().Stop(ctx)
+
+	ts := hlc.Timestamp{WallTime: s.Clock().Now().WallTime}
+	key := func(i int) engine.MVCCKey {
+		return engine.MVCCKey{Key: roachpb.Key(fmt.Sprintf("bulk-%04d", i)), Timestamp: ts}
+	}
+	value := func(i int) []byte {
+		v := roachpb.MakeValueFromString(fmt.Sprintf("value-%d", i))
+		v.InitChecksum(key(i).Key)
+		return v.RawBytes
+	}
+
+	for _, splitAndScatter := range []bool{false, true} {
+		t.Run(fmt.Sprintf("splitAndScatter=%t", splitAndScatter), func(t *testing.T) {
+			const numKeys = 100
+			// Small buffers and sstables make the adder flush several times, and
+			// send several sstables per flush.
+			adder := bulk.NewBufferingAdder(db, 1024, 128, splitAndScatter)
+
+			// Add the keys out of order, and some of them twice.
+			for i := numKeys - 1; i >= 0; i-- {
+				k := i
+				if splitAndScatter {
+					k += numKeys
+				}
+				if err := adder.Add(ctx, key(k), value(k)); err != nil {
+					t.Fatalf("%+v", err)
+				}
+				if i%10 == 0 {
+					if err := adder.Add(ctx, key(k), value(k)); err != nil {
+						t.Fatalf("%+v", err)
+					}
+				}
+			}
+			if err := adder.Flush(ctx); err != nil {
+				t.Fatalf("%+v", err)
+			}
+			if adder.IngestedDataSize() == 0 {
+				t.Fatal("expected a non-zero ingested data size")
+			}
+
+			start, end := 0, numKeys
+			if splitAndScatter {
+				start, end = numKeys, 2*numKeys
+			}
+			kvs, err := db.Scan(ctx, key(start).Key, key(end).Key, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(kvs) != numKeys {
+				t.Fatalf("expected %d keys, got %d", numKeys, len(kvs))
+			}
+			for i, kv := range kvs {
+				expected := fmt.Sprintf("value-%d", start+i)
+				if v, err := kv.Value.GetBytes(); err != nil {
+					t.Fatal(err)
+				} else if string(v) != expected {
+					t.Errorf("%s: expected %q, got %q", kv.Key, expected, v)
+				}
+			}
+		})
+	}
+
+	t.Run("different values", func(t *testing.T) {
+		adder := bulk.NewBufferingAdder(db, 1<<20, 1<<20, false /* splitAndScatter */)
+		if err := adder.Add(ctx, key(1000), value(1000)); err != nil {
+			t.Fatal(err)
+		}
+		if err := adder.Add(ctx, key(1000), value(1001)); err != nil {
+			t.Fatal(err)
+		}
+		if err := adder.Flush(ctx); !testutils.IsError(err, "different values added for key") {
+			t.Fatalf("expected a different values error, got %+v", err)
+		}
+	})
+}
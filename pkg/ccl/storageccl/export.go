@@ -192,6 +192,12 @@ func evalExport(
 		if err := rows.count(iter.UnsafeKey().Key); err != nil {
 			return result.Result{}, errors.Wrapf(err, "decoding %s", iter.UnsafeKey())
 		}
+		// The sstable is built in memory, so register its data with the store's
+		// evaluation memory budget as it grows.
+		kvSize := int64(iter.UnsafeKey().EncodedSize() + len(iter.UnsafeValue()))
+		if err := batcheval.GrowEvalMemory(ctx, cArgs, kvSize); err != nil {
+			return result.Result{}, err
+		}
 		if err := sst.Add(engine.MVCCKeyValue{Key: iter.UnsafeKey(), Value: iter.UnsafeValue()}); err != nil {
 			return result.Result{}, errors.Wrapf(err, "adding key %s", iter.UnsafeKey())
 		}
@@ -24,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/batcheval"
+	"github.com/cockroachdb/cockroach/pkg/storage/bulk"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
@@ -68,76 +69,6 @@ func MaxImportBatchSize(st *cluster.Settings) int64 {
 	return desiredSize
 }
 
-type sstBatcher struct {
-	maxSize int64
-	// rows written in the current batch.
-	rowCounter rowCounter
-	totalRows  roachpb.BulkOpSummary
-
-	sstWriter     engine.RocksDBSstFileWriter
-	batchStartKey []byte
-	batchEndKey   []byte
-}
-
-func (b *sstBatcher) add(key engine.MVCCKey, value []byte) error {
-	// Update the range currently represented in this batch, as
-	// necessary.
-	if len(b.batchStartKey) == 0 || bytes.Compare(key.Key, b.batchStartKey) < 0 {
-		b.batchStartKey = append(b.batchStartKey[:0], key.Key...)
-	}
-	if len(b.batchEndKey) == 0 || bytes.Compare(key.Key, b.batchEndKey) > 0 {
-		b.batchEndKey = append(b.batchEndKey[:0], key.Key...)
-	}
-	if err := b.rowCounter.count(key.Key); err != nil {
-		return err
-	}
-	return b.sstWriter.Add(engine.MVCCKeyValue{Key: key, Value: value})
-}
-
-func (b *sstBatcher) reset() error {
-	b.sstWriter.Close()
-	w, err := engine.MakeRocksDBSstFileWriter()
-	if err != nil {
-		return err
-	}
-	b.sstWriter = w
-	b.batchStartKey = b.batchStartKey[:0]
-	b.batchEndKey = b.batchEndKey[:0]
-	b.rowCounter.BulkOpSummary.Reset()
-	return nil
-}
-
-func (b *sstBatcher) shouldFlush(nextKey roachpb.Key) bool {
-	return b.sstWriter.DataSize >= b.maxSize
-}
-
-func (b *sstBatcher) flush(ctx context.Context, db *client.DB) error {
-	if b.sstWriter.DataSize == 0 {
-		return nil
-	}
-	start := roachpb.Key(append([]byte(nil), b.batchStartKey...))
-	// The end key of the WriteBatch request is exclusive, but batchEndKey is
-	// currently the largest key in the batch. Increment it.
-	end := roachpb.Key(append([]byte(nil), b.batchEndKey...)).Next()
-
-	sstBytes, err := b.sstWriter.Finish()
-	if err != nil {
-		return errors.Wrapf(err, "finishing constructed sstable")
-	}
-	if err := AddSSTable(ctx, db, start, end, sstBytes); err != nil {
-		// TODO(dt): if we get a RangeKeyMismatchError, update batching split points
-		// and then tell the caller to try again.
-		return err
-	}
-	b.rowCounter.DataSize = b.sstWriter.DataSize
-	b.totalRows.Add(b.rowCounter.BulkOpSummary)
-	return nil
-}
-
-func (b *sstBatcher) Close() {
-	b.sstWriter.Close()
-}
-
 // AddSSTable retries db.AddSSTable if retryable errors occur.
 func AddSSTable(ctx context.Context, db *client.DB, start, end roachpb.Key, sstBytes []byte) error {
 	const maxAddSSTableRetries = 10
@@ -290,11 +221,9 @@ func evalImport(ctx context.Context, cArgs batcheval.CommandArgs) (*roachpb.Impo
 		iters = append(iters, iter)
 	}
 
-	batcher := &sstBatcher{maxSize: MaxImportBatchSize(cArgs.EvalCtx.ClusterSettings())}
-	if err := batcher.reset(); err != nil {
-		return nil, err
-	}
-	defer batcher.Close()
+	maxSize := MaxImportBatchSize(cArgs.EvalCtx.ClusterSettings())
+	adder := bulk.NewBufferingAdder(db, maxSize, maxSize, false /* splitAndScatter */)
+	var rows rowCounter
 
 	startKeyMVCC, endKeyMVCC := engine.MVCCKey{Key: args.DataSpan.Key}, engine.MVCCKey{Key: args.DataSpan.EndKey}
 	iter := engineccl.MakeMultiIterator(iters)
@@ -347,18 +276,6 @@ func evalImport(ctx context.Context, cArgs batcheval.CommandArgs) (*roachpb.Impo
 			continue
 		}
 
-		// Check if we need to flush current batch *before* adding the next k/v --
-		// the batcher may want to flush the keys it already has, either because it
-		// is full or because it wants this key in a separate batch due to splits.
-		if batcher.shouldFlush(key.Key) {
-			if err := batcher.flush(ctx, db); err != nil {
-				return nil, errors.Wrapf(err, "import [%s, %s)", startKeyMVCC.Key, endKeyMVCC.Key)
-			}
-			if err := batcher.reset(); err != nil {
-				return nil, err
-			}
-		}
-
 		// Rewriting the key means the checksum needs to be updated.
 		value.ClearChecksum()
 		value.InitChecksum(key.Key)
@@ -366,14 +283,18 @@ func evalImport(ctx context.Context, cArgs batcheval.CommandArgs) (*roachpb.Impo
 		if log.V(3) {
 			log.Infof(ctx, "Put %s -> %s", key.Key, value.PrettyPrint())
 		}
-		if err := batcher.add(key, value.RawBytes); err != nil {
-			return nil, errors.Wrapf(err, "adding to batch: %s -> %s", key, value.PrettyPrint())
+		if err := rows.count(key.Key); err != nil {
+			return nil, err
+		}
+		if err := adder.Add(ctx, key, value.RawBytes); err != nil {
+			return nil, errors.Wrapf(err, "import [%s, %s)", startKeyMVCC.Key, endKeyMVCC.Key)
 		}
 	}
 	// Flush out the last batch.
-	if err := batcher.flush(ctx, db); err != nil {
-		return nil, err
+	if err := adder.Flush(ctx); err != nil {
+		return nil, errors.Wrapf(err, "import [%s, %s)", startKeyMVCC.Key, endKeyMVCC.Key)
 	}
+	rows.DataSize = adder.IngestedDataSize()
 	log.Event(ctx, "done")
-	return &roachpb.ImportResponse{Imported: batcher.totalRows}, nil
+	return &roachpb.ImportResponse{Imported: rows.BulkOpSummary}, nil
 }
@@ -0,0 +1,237 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package streamingccl
+
+import (
+	"context"
+	gosql "database/sql"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
+	"github.com/cockroachdb/cockroach/pkg/sql/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/sql/lex"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage/bulk"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/pkg/errors"
+	// Register the postgres driver used to connect to the source cluster.
+	_ "github.com/lib/pq"
+)
+
+// sourceError wraps errors communicating with the source cluster. These are
+// retried by reconnecting and resuming the stream from the last checkpoint;
+// every other error fails the ingestion job.
+type sourceError struct {
+	cause error
+}
+
+func (e *sourceError) Error() string {
+	return e.cause.Error()
+}
+
+// streamIngester consumes a replication stream from a source cluster and
+// ingests it into a table in this cluster.
+//
+// Ingested key values keep their source MVCC timestamps, so the ingested table
+// ends up with the same history as the source. This is done by buffering the
+// key values into sstables and ingesting them with AddSSTable, which bypasses
+// the timestamp cache, so the destination table must not be written to while
+// the stream is running.
+type streamIngester struct {
+	execCfg   *sql.ExecutorConfig
+	job       *jobs.Job
+	details   jobspb.StreamIngestionDetails
+	highwater hlc.Timestamp
+	tableDesc *sqlbase.TableDescriptor
+
+	// kr rewrites keys from the source table to the destination table. It's
+	// created once the source table ID is known.
+	kr *storageccl.KeyRewriter
+
+	// adder buffers the key values that have been received but not yet
+	// ingested.
+	adder *bulk.BufferingAdder
+
+	// started is set once the stream has been set up, after which errors
+	// communicating with the source cluster are retried.
+	started bool
+}
+
+func makeStreamIngester(
+	execCfg *sql.ExecutorConfig,
+	job *jobs.Job,
+	details jobspb.StreamIngestionDetails,
+	progress jobspb.StreamIngestionProgress,
+	tableDesc *sqlbase.TableDescriptor,
+) *streamIngester {
+	return &streamIngester{
+		execCfg:   execCfg,
+		job:       job,
+		details:   details,
+		highwater: progress.Highwater,
+		tableDesc: tableDesc,
+		adder: bulk.NewBufferingAdder(
+			execCfg.DB,
+			storageccl.MaxImportBatchSize(execCfg.Settings), /* bufferSize */
+			storageccl.MaxImportBatchSize(execCfg.Settings), /* sstSize */
+			false, /* splitAndScatter */
+		),
+	}
+}
+
+// run ingests the stream until ctx is canceled or a non-retryable error
+// occurs. startedCh is sent to once the stream is set up.
+func (si *streamIngester) run(ctx context.Context, startedCh chan<- tree.Datums) error {
+	opts := retry.Options{
+		InitialBackoff: 1 * time.Second,
+		MaxBackoff:     1 * time.Minute,
+		Multiplier:     2,
+	}
+	for r := retry.StartWithCtx(ctx, opts); r.Next(); {
+		err := si.runOnce(ctx, startedCh, r.Reset)
+		if _, ok := err.(*sourceError); !ok || !si.started {
+			return err
+		}
+		log.Warningf(ctx, "replication stream from %s interrupted, resuming from %s: %+v",
+			si.details.SourceTable, si.highwater, err)
+	}
+	return ctx.Err()
+}
+
+// runOnce connects to the source cluster and ingests the stream from the
+// last checkpoint until an error occurs.
+func (si *streamIngester) runOnce(
+	ctx context.Context, startedCh chan<- tree.Datums, progressedFn func(),
+) error {
+	// Anything buffered since the last checkpoint will be streamed again.
+	si.adder.Reset()
+
+	db, err := gosql.Open("postgres", si.details.SourceURI)
+	if err != nil {
+		return &sourceError{err}
+	}
+	defer db.Close()
+
+	stmt := `CREATE REPLICATION STREAM FOR TABLE ` + si.details.SourceTable
+	if si.highwater != (hlc.Timestamp{}) {
+		cursor := tree.TimestampToDecimal(si.highwater).Decimal.String()
+		stmt += ` WITH cursor = ` + lex.EscapeSQLString(cursor)
+	}
+	rows, err := db.QueryContext(ctx, stmt)
+	if err != nil {
+		return &sourceError{errors.Wrapf(err, "starting replication stream from %s", si.details.SourceTable)}
+	}
+	defer rows.Close()
+
+	if !si.started {
+		si.started = true
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case startedCh <- tree.Datums(nil):
+		}
+	}
+
+	for rows.Next() {
+		var key, value []byte
+		var resolved gosql.NullString
+		if err := rows.Scan(&key, &value, &resolved); err != nil {
+			return err
+		}
+		if !resolved.Valid {
+			if err := si.add(ctx, key, value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		ts, err := sql.EvalAsOfTimestamp(
+			nil, tree.AsOfClause{Expr: tree.NewStrVal(resolved.String)}, hlc.MaxTimestamp)
+		if err != nil {
+			return errors.Wrapf(err, "parsing checkpoint %q", resolved.String)
+		}
+		if err := si.adder.Flush(ctx); err != nil {
+			return err
+		}
+		if err := si.checkpoint(ctx, ts); err != nil {
+			return err
+		}
+		progressedFn()
+	}
+	if err := rows.Err(); err != nil {
+		return &sourceError{err}
+	}
+	return &sourceError{errors.Errorf("replication stream from %s ended", si.details.SourceTable)}
+}
+
+// add rewrites a key value from the stream for the destination table and
+// buffers it for ingestion.
+func (si *streamIngester) add(ctx context.Context, encodedKey, value []byte) error {
+	key, err := engine.DecodeKey(encodedKey)
+	if err != nil {
+		return errors.Wrap(err, "decoding streamed key")
+	}
+	if si.kr == nil {
+		if si.details.SourceTableID == 0 {
+			_, tableID, err := encoding.DecodeUvarintAscending(key.Key)
+			if err != nil {
+				return errors.Wrapf(err, "decoding table ID of streamed key %s", key.Key)
+			}
+			si.details.SourceTableID = sqlbase.ID(tableID)
+		}
+		si.kr, err = storageccl.MakeKeyRewriter(map[sqlbase.ID]*sqlbase.TableDescriptor{
+			si.details.SourceTableID: si.tableDesc,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	newKey, ok, err := si.kr.RewriteKey(key.Key)
+	if err != nil {
+		return err
+	} else if !ok {
+		return errors.Errorf("streamed key %s does not belong to an index of table %d",
+			key.Key, si.details.SourceTableID)
+	}
+	key.Key = newKey
+
+	// Rewriting the key means the checksum needs to be updated. Empty values
+	// are deletion tombstones and have no checksum.
+	if len(value) > 0 {
+		v := roachpb.Value{RawBytes: value}
+		v.ClearChecksum()
+		v.InitChecksum(key.Key)
+		value = v.RawBytes
+	}
+
+	return si.adder.Add(ctx, key, value)
+}
+
+// checkpoint records that everything in the stream up to ts has been
+// ingested.
+func (si *streamIngester) checkpoint(ctx context.Context, ts hlc.Timestamp) error {
+	si.highwater = ts
+	return si.job.DetailProgressed(ctx, func(
+		ctx context.Context, details jobspb.Details, progress jobspb.ProgressDetails,
+	) float32 {
+		details.(*jobspb.Payload_StreamIngestion).StreamIngestion.SourceTableID = si.details.SourceTableID
+		progress.(*jobspb.Progress_StreamIngestion).StreamIngestion.Highwater = ts
+		// A replication stream never completes.
+		return 0.0
+	})
+}
@@ -0,0 +1,139 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package streamingccl
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl/engineccl"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/pkg/errors"
+)
+
+var streamPollInterval = settings.RegisterDurationSetting(
+	"replication_stream.experimental_poll_interval",
+	"polling interval for replication stream producers",
+	1*time.Second,
+)
+
+func init() {
+	streamPollInterval.Hide()
+}
+
+// streamColumns are the result columns of a replication stream. Every row is
+// either a changed key value, with the MVCC key encoded by engine.EncodeKey
+// and the raw bytes of the MVCC value, or a checkpoint. A checkpoint has only
+// the resolved column set, to a decimal timestamp, and guarantees that every
+// key value in the stream at or below that timestamp has already been emitted.
+var streamColumns = sqlbase.ResultColumns{
+	{Name: "key", Typ: types.Bytes},
+	{Name: "value", Typ: types.Bytes},
+	{Name: "resolved", Typ: types.String},
+}
+
+// runStreamProducer emits every change to the span since highwater to
+// resultsCh, interspersed with checkpoints, until ctx is canceled. Every
+// revision is emitted, not only the latest one, so that a consumer ingesting
+// the stream ends up with the same MVCC history as the source. Like
+// changefeeds, changes are found by periodically polling with ExportRequest.
+func runStreamProducer(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	span roachpb.Span,
+	highwater hlc.Timestamp,
+	resultsCh chan<- tree.Datums,
+) error {
+	sender := execCfg.DB.NonTransactionalSender()
+	var alloc sqlbase.DatumAlloc
+	emit := func(row tree.Datums) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case resultsCh <- row:
+			return nil
+		}
+	}
+
+	for {
+		pollDuration := streamPollInterval.Get(&execCfg.Settings.SV)
+		pollDuration = pollDuration - timeutil.Since(timeutil.Unix(0, highwater.WallTime))
+		if pollDuration > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(pollDuration):
+			}
+		}
+
+		nextHighwater := execCfg.Clock.Now()
+		log.VEventf(ctx, 1, `replication stream poll [%s,%s)`, highwater, nextHighwater)
+
+		header := roachpb.Header{Timestamp: nextHighwater}
+		req := &roachpb.ExportRequest{
+			RequestHeader: roachpb.RequestHeaderFromSpan(span),
+			StartTime:     highwater,
+			MVCCFilter:    roachpb.MVCCFilter_All,
+			ReturnSST:     true,
+		}
+		res, pErr := client.SendWrappedWith(ctx, sender, header, req)
+		if pErr != nil {
+			return errors.Wrapf(
+				pErr.GoError(), `fetching changes for [%s,%s)`, span.Key, span.EndKey)
+		}
+		for _, file := range res.(*roachpb.ExportResponse).Files {
+			if err := emitSST(file.SST, &alloc, emit); err != nil {
+				return err
+			}
+		}
+
+		highwater = nextHighwater
+		resolved := tree.TimestampToDecimal(highwater).Decimal.String()
+		if err := emit(tree.Datums{
+			tree.DNull, tree.DNull, alloc.NewDString(tree.DString(resolved)),
+		}); err != nil {
+			return err
+		}
+	}
+}
+
+// emitSST emits every key value in an sstable returned by ExportRequest.
+func emitSST(sst []byte, alloc *sqlbase.DatumAlloc, emit func(tree.Datums) error) error {
+	it, err := engineccl.NewMemSSTIterator(sst, false /* verify */)
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+	for it.Seek(engine.NilKey); ; it.Next() {
+		if ok, err := it.Valid(); err != nil {
+			return err
+		} else if !ok {
+			return nil
+		}
+		key := engine.EncodeKey(it.UnsafeKey())
+		value := append([]byte(nil), it.UnsafeValue()...)
+		if err := emit(tree.Datums{
+			alloc.NewDBytes(tree.DBytes(key)),
+			alloc.NewDBytes(tree.DBytes(value)),
+			tree.DNull,
+		}); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,290 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package streamingccl
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl"
+	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
+	"github.com/cockroachdb/cockroach/pkg/sql/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	sql.AddPlanHook(replicationStreamPlanHook)
+	jobs.AddResumeHook(streamIngestionResumeHook)
+}
+
+const (
+	optCursor      = `cursor`
+	optSourceTable = `source_table`
+)
+
+var replicationStreamOptionExpectValues = map[string]bool{
+	optCursor:      true,
+	optSourceTable: true,
+}
+
+// replicationStreamPlanHook implements sql.PlanHookFn.
+//
+// Without a source, `CREATE REPLICATION STREAM FOR TABLE foo` produces a
+// replication stream: it blocks forever and returns every change to foo as
+// rows directly over pgwire. With one, `CREATE REPLICATION STREAM FOR TABLE
+// foo FROM 'postgres://...'` starts a job that consumes the stream of the
+// same table from the source cluster and ingests it into foo.
+func replicationStreamPlanHook(
+	_ context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (sql.PlanHookRowFn, sqlbase.ResultColumns, []sql.PlanNode, error) {
+	streamStmt, ok := stmt.(*tree.CreateReplicationStream)
+	if !ok {
+		return nil, nil, nil, nil
+	}
+
+	optsFn, err := p.TypeAsStringOpts(streamStmt.Options, replicationStreamOptionExpectValues)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if streamStmt.SourceURI == nil {
+		fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+			ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
+			defer tracing.FinishSpan(span)
+
+			if err := checkReplicationStreamPrivileges(ctx, p); err != nil {
+				return err
+			}
+			opts, err := optsFn()
+			if err != nil {
+				return err
+			}
+			if _, ok := opts[optSourceTable]; ok {
+				return errors.Errorf(`%s can only be specified along with FROM`, optSourceTable)
+			}
+
+			now := p.ExecCfg().Clock.Now()
+			var highwater hlc.Timestamp
+			if cursor, ok := opts[optCursor]; ok {
+				asOf := tree.AsOfClause{Expr: tree.NewStrVal(cursor)}
+				if highwater, err = sql.EvalAsOfTimestamp(nil, asOf, now); err != nil {
+					return err
+				}
+			}
+			tableDesc, err := resolveReplicationStreamTable(ctx, p, now, streamStmt.Targets)
+			if err != nil {
+				return err
+			}
+			return runStreamProducer(ctx, p.ExecCfg(), tableDesc.TableSpan(), highwater, resultsCh)
+		}
+		return fn, streamColumns, nil, nil
+	}
+
+	sourceURIFn, err := p.TypeAsString(streamStmt.SourceURI, `CREATE REPLICATION STREAM`)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	header := sqlbase.ResultColumns{
+		{Name: "job_id", Typ: types.Int},
+	}
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
+		defer tracing.FinishSpan(span)
+
+		if err := checkReplicationStreamPrivileges(ctx, p); err != nil {
+			return err
+		}
+		if !p.ExtendedEvalContext().TxnImplicit {
+			return errors.Errorf("%s cannot be used inside a transaction", stmt.StatementTag())
+		}
+
+		sourceURI, err := sourceURIFn()
+		if err != nil {
+			return err
+		}
+		description, err := streamIngestionJobDescription(streamStmt, sourceURI)
+		if err != nil {
+			return err
+		}
+		opts, err := optsFn()
+		if err != nil {
+			return err
+		}
+		if _, ok := opts[optCursor]; ok {
+			return errors.Errorf(`%s cannot be specified along with FROM`, optCursor)
+		}
+
+		tableDesc, err := resolveReplicationStreamTable(
+			ctx, p, p.ExecCfg().Clock.Now(), streamStmt.Targets)
+		if err != nil {
+			return err
+		}
+		// The stream starts with the full history of the source table, which
+		// would be interleaved with any existing data.
+		tableSpan := tableDesc.TableSpan()
+		if kvs, err := p.ExecCfg().DB.Scan(ctx, tableSpan.Key, tableSpan.EndKey, 1); err != nil {
+			return err
+		} else if len(kvs) > 0 {
+			return errors.Errorf("table %q must be empty to ingest a replication stream", tableDesc.Name)
+		}
+
+		sourceTable, ok := opts[optSourceTable]
+		if !ok {
+			sourceTable = tree.AsString(streamStmt.Targets.Tables[0])
+		}
+
+		// Make a channel for the ingestion to signal once it has connected to
+		// the source cluster, so that errors setting up the stream are
+		// returned to the user instead of only failing the job.
+		startedCh := make(chan tree.Datums)
+		job, errCh, err := p.ExecCfg().JobRegistry.StartJob(ctx, startedCh, jobs.Record{
+			Description:   description,
+			Username:      p.User(),
+			DescriptorIDs: sqlbase.IDs{tableDesc.ID},
+			Details: jobspb.StreamIngestionDetails{
+				SourceURI:   sourceURI,
+				SourceTable: sourceTable,
+				TableID:     tableDesc.ID,
+			},
+			Progress: jobspb.StreamIngestionProgress{},
+		})
+		if err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-errCh:
+			return err
+		case <-startedCh:
+		}
+
+		resultsCh <- tree.Datums{
+			tree.NewDInt(tree.DInt(*job.ID())),
+		}
+		return nil
+	}
+	return fn, header, nil, nil
+}
+
+// checkReplicationStreamPrivileges returns an error if the current user can't
+// produce or ingest replication streams. Both read and write raw KVs without
+// regard for table privileges, so they're restricted to superusers.
+func checkReplicationStreamPrivileges(ctx context.Context, p sql.PlanHookState) error {
+	if err := utilccl.CheckEnterpriseEnabled(
+		p.ExecCfg().Settings, p.ExecCfg().ClusterID(), p.ExecCfg().Organization(),
+		"CREATE REPLICATION STREAM",
+	); err != nil {
+		return err
+	}
+	return p.RequireSuperUser(ctx, "CREATE REPLICATION STREAM")
+}
+
+// resolveReplicationStreamTable returns the descriptor of the single table
+// targeted by a replication stream.
+func resolveReplicationStreamTable(
+	ctx context.Context, p sql.PlanHookState, ts hlc.Timestamp, targets tree.TargetList,
+) (*sqlbase.TableDescriptor, error) {
+	if len(targets.Databases) > 0 || len(targets.Tables) != 1 {
+		return nil, errors.New("replication streams currently support exactly one table")
+	}
+	descs, _, err := backupccl.ResolveTargetsToDescriptors(ctx, p, ts, targets)
+	if err != nil {
+		return nil, err
+	}
+	var tableDescs []*sqlbase.TableDescriptor
+	for i := range descs {
+		if tableDesc := descs[i].GetTable(); tableDesc != nil {
+			tableDescs = append(tableDescs, tableDesc)
+		}
+	}
+	if len(tableDescs) != 1 {
+		return nil, errors.New("replication streams currently support exactly one table")
+	}
+	tableDesc := tableDescs[0]
+	if tableDesc.IsInterleaved() {
+		return nil, errors.Errorf("replication streams are not supported for interleaved table %q",
+			tableDesc.Name)
+	}
+	return tableDesc, nil
+}
+
+// streamIngestionJobDescription formats the statement for use as a job
+// description, with the password in the source URI redacted.
+func streamIngestionJobDescription(
+	stmt *tree.CreateReplicationStream, sourceURI string,
+) (string, error) {
+	uri, err := url.Parse(sourceURI)
+	if err != nil {
+		return "", err
+	}
+	if uri.Scheme != "postgres" && uri.Scheme != "postgresql" {
+		return "", errors.Errorf("unsupported replication stream source: %s", uri.Scheme)
+	}
+	if uri.User != nil {
+		if _, ok := uri.User.Password(); ok {
+			uri.User = url.UserPassword(uri.User.Username(), "redacted")
+		}
+	}
+	s := &tree.CreateReplicationStream{
+		Targets:   stmt.Targets,
+		SourceURI: tree.NewDString(uri.String()),
+		Options:   stmt.Options,
+	}
+	return tree.AsStringWithFlags(s, tree.FmtAlwaysQualifyTableNames), nil
+}
+
+type streamIngestionResumer struct{}
+
+func (r *streamIngestionResumer) Resume(
+	ctx context.Context, job *jobs.Job, planHookState interface{}, startedCh chan<- tree.Datums,
+) error {
+	execCfg := planHookState.(sql.PlanHookState).ExecCfg()
+	details := job.Details().(jobspb.StreamIngestionDetails)
+	progress := job.Progress().Details.(*jobspb.Progress_StreamIngestion).StreamIngestion
+
+	var tableDesc *sqlbase.TableDescriptor
+	if err := execCfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		var err error
+		tableDesc, err = sqlbase.GetTableDescFromID(ctx, txn, details.TableID)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	si := makeStreamIngester(execCfg, job, details, *progress, tableDesc)
+	return si.run(ctx, startedCh)
+}
+func (r *streamIngestionResumer) OnFailOrCancel(context.Context, *client.Txn, *jobs.Job) error {
+	return nil
+}
+func (r *streamIngestionResumer) OnSuccess(context.Context, *client.Txn, *jobs.Job) error {
+	return nil
+}
+func (r *streamIngestionResumer) OnTerminal(
+	context.Context, *jobs.Job, jobs.Status, chan<- tree.Datums,
+) {
+}
+
+func streamIngestionResumeHook(typ jobspb.Type, _ *cluster.Settings) jobs.Resumer {
+	if typ != jobspb.TypeStreamIngestion {
+		return nil
+	}
+	return &streamIngestionResumer{}
+}
@@ -232,6 +232,9 @@ var certCmds = []*cobra.Command{
 	createCACertCmd,
 	createNodeCertCmd,
 	createClientCertCmd,
+	createJoinTokenCmd,
+	joinNodeCmd,
+	joinClientCmd,
 	listCertsCmd,
 }
 
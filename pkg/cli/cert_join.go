@@ -0,0 +1,243 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/cli/cliflags"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/server"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/util/httputil"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const defaultJoinTokenLifetime = time.Hour
+
+// defaultJoinTokenPrincipal is security.NodeUser: by default, join tokens can
+// only be used to obtain node certificates.
+const defaultJoinTokenPrincipal = security.NodeUser
+
+var joinTokenLifetime time.Duration
+var joinTokenPrincipal string
+var joinToken string
+
+// A createJoinToken command creates a token allowing a node or client to
+// obtain certificates from a node started with --ca-key.
+var createJoinTokenCmd = &cobra.Command{
+	Use:   "create-join-token --certs-dir=<path to cockroach certs dir> --ca-key=<path-to-ca-key>",
+	Short: "create a token for obtaining certificates from the cluster",
+	Long: `
+Create a join token for use with "cockroach cert join-node" or "cockroach cert
+join-client" and print it to standard output.
+
+A join token can be used once to obtain a certificate for --principal from any
+node started with --ca-key: a node certificate if --principal is "node" (the
+default), or a client certificate for the SQL user named by --principal. It
+expires after --lifetime.
+
+Requires a CA cert in "<certs-dir>/ca.crt" and matching key in "--ca-key".
+If "ca.crt" contains more than one certificate, the first is used.
+`,
+	Args: cobra.NoArgs,
+	RunE: MaybeDecorateGRPCError(runCreateJoinToken),
+}
+
+func runCreateJoinToken(cmd *cobra.Command, args []string) error {
+	principal := joinTokenPrincipal
+	if principal != security.NodeUser {
+		var err error
+		if principal, err = sql.NormalizeAndValidateUsername(principal); err != nil {
+			return errors.Wrap(err, "failed to create join token")
+		}
+	}
+	token, err := security.CreateJoinToken(
+		baseCfg.SSLCertsDir, baseCfg.SSLCAKey, principal, joinTokenLifetime)
+	if err != nil {
+		return errors.Wrap(err, "failed to create join token")
+	}
+	fmt.Fprintln(os.Stdout, token)
+	return nil
+}
+
+// A joinNode command obtains a node certificate from a node serving as the
+// cluster's CA.
+var joinNodeCmd = &cobra.Command{
+	Use:   "join-node --certs-dir=<path to cockroach certs dir> --token=<join token> <http address> <host 1> <host 2> ... <host N>",
+	Short: "obtain node certificate and key from the cluster",
+	Long: `
+Obtain a node certificate "<certs-dir>/node.crt" from the node started with
+--ca-key listening for HTTP connections at <http address>, using a join token.
+The key "<certs-dir>/node.key" is generated locally and never leaves this
+machine. The CA certificate is written to "<certs-dir>/ca.crt".
+The certs directory is created if it does not exist.
+
+If --overwrite is true, any existing files are overwritten.
+
+At least one host should be passed in (either IP address or dns name).
+`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 2 {
+			return errors.Errorf("join-node requires an address and at least one host name or address")
+		}
+		return nil
+	},
+	RunE: MaybeDecorateGRPCError(runJoinNode),
+}
+
+func runJoinNode(cmd *cobra.Command, args []string) error {
+	return errors.Wrap(
+		joinCluster(args[0], security.NodeUser, args[1:]),
+		"failed to obtain node certificate and key")
+}
+
+// A joinClient command obtains a client certificate from a node serving as the
+// cluster's CA.
+var joinClientCmd = &cobra.Command{
+	Use:   "join-client --certs-dir=<path to cockroach certs dir> --token=<join token> <http address> <username>",
+	Short: "obtain client certificate and key from the cluster",
+	Long: `
+Obtain a client certificate "<certs-dir>/client.<username>.crt" from the node
+started with --ca-key listening for HTTP connections at <http address>, using a
+join token. The key "<certs-dir>/client.<username>.key" is generated locally and
+never leaves this machine. The CA certificate is written to "<certs-dir>/ca.crt".
+The certs directory is created if it does not exist.
+
+If --overwrite is true, any existing files are overwritten.
+`,
+	Args: cobra.ExactArgs(2),
+	RunE: MaybeDecorateGRPCError(runJoinClient),
+}
+
+func runJoinClient(cmd *cobra.Command, args []string) error {
+	username, err := sql.NormalizeAndValidateUsername(args[1])
+	if err != nil {
+		return errors.Wrap(err, "failed to obtain client certificate and key")
+	}
+	return errors.Wrap(
+		joinCluster(args[0], username, nil),
+		"failed to obtain client certificate and key")
+}
+
+// joinCluster obtains a certificate for the given user from the node at the
+// given HTTP address and writes it to the certs directory along with its key
+// and the CA certificate.
+func joinCluster(addr string, user string, hosts []string) error {
+	if len(joinToken) == 0 {
+		return errors.Errorf("--%s is required", cliflags.JoinToken.Name)
+	}
+	token, err := security.ParseJoinToken(joinToken)
+	if err != nil {
+		return err
+	}
+	if token.Principal != user {
+		return errors.Errorf("join token was created for user %q, not %q", token.Principal, user)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	// We don't have the CA certificate yet, so we can't authenticate the node
+	// when fetching it. Instead, we check it against the fingerprint in the
+	// token before trusting it.
+	caPEM, err := joinFetch(ctx, &tls.Config{InsecureSkipVerify: true},
+		http.MethodGet, "https://"+addr+server.JoinCAPath, nil)
+	if err != nil {
+		return errors.Wrap(err, "could not fetch CA certificate")
+	}
+	caCerts, err := security.PEMToCertificates(caPEM)
+	if err != nil {
+		return errors.Wrap(err, "could not parse CA certificates")
+	}
+	if len(caCerts) == 0 {
+		return errors.New("no CA certificates received")
+	}
+	if !bytes.Equal(security.CAFingerprint(caCerts[0].Bytes), token.CAFingerprint) {
+		return errors.New("CA certificate does not match the join token")
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caPEM) {
+		return errors.New("could not parse CA certificates")
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, keySize)
+	if err != nil {
+		return errors.Wrap(err, "could not generate new key")
+	}
+	csr, err := security.CreateCertificateRequest(key, user, hosts)
+	if err != nil {
+		return errors.Wrap(err, "could not create certificate request")
+	}
+	reqBody, err := json.Marshal(server.JoinCertRequest{Token: joinToken, CSR: csr})
+	if err != nil {
+		return err
+	}
+	respBody, err := joinFetch(ctx, &tls.Config{RootCAs: roots},
+		http.MethodPost, "https://"+addr+server.JoinCertPath, reqBody)
+	if err != nil {
+		return errors.Wrap(err, "could not obtain certificate")
+	}
+	var resp server.JoinCertResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return err
+	}
+	certs, err := security.PEMToCertificates(resp.Certificate)
+	if err != nil || len(certs) != 1 {
+		return errors.Errorf("could not parse certificate: %v", err)
+	}
+
+	return security.WriteJoinedCertificates(
+		baseCfg.SSLCertsDir, caPEM, certs[0].Bytes, key, user, overwriteFiles)
+}
+
+// joinFetch issues an HTTP request to a node serving as the cluster's CA and
+// returns the body of the response.
+func joinFetch(
+	ctx context.Context, tlsConfig *tls.Config, method, url string, body []byte,
+) ([]byte, error) {
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set(httputil.ContentTypeHeader, httputil.JSONContentType)
+	}
+	client := http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("%s: %s", resp.Status, bytes.TrimSpace(respBody))
+	}
+	return respBody, nil
+}
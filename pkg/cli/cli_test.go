@@ -2306,18 +2306,28 @@ writing ` + os.DevNull + `
   debug/nodes/1/ranges/20
   debug/nodes/1/ranges/21
   debug/nodes/1/ranges/22
+  debug/nodes/1/ranges/23
+  debug/nodes/1/ranges/24
+  debug/nodes/1/ranges/25
+  debug/nodes/1/ranges/26
+  debug/nodes/1/ranges/27
   debug/schema/defaultdb@details
   debug/schema/postgres@details
   debug/schema/system@details
   debug/schema/system/descriptor
   debug/schema/system/eventlog
+  debug/schema/system/index_usage_statistics
   debug/schema/system/jobs
+  debug/schema/system/join_tokens
   debug/schema/system/lease
   debug/schema/system/locations
   debug/schema/system/namespace
   debug/schema/system/rangelog
+  debug/schema/system/replication_reports
   debug/schema/system/role_members
+  debug/schema/system/scheduled_jobs
   debug/schema/system/settings
+  debug/schema/system/statement_statistics
   debug/schema/system/table_statistics
   debug/schema/system/ui
   debug/schema/system/users
@@ -138,6 +138,27 @@ Including more tiers is better than including fewer. For example:
 File to read the zone configuration from. Specify "-" to read from standard input.`,
 	}
 
+	InitBootstrapSQL = FlagInfo{
+		Name: "bootstrap-sql",
+		Description: `
+File of SQL statements to execute once the cluster is initialized, e.g. to
+create databases and users. The statements are executed as the user of the
+init command, after the settings from --settings are applied.`,
+	}
+
+	InitSettings = FlagInfo{
+		Name: "settings",
+		Description: `
+YAML file of cluster settings to apply once the cluster is initialized, as a
+map from setting names to values. For example:
+<PRE>
+
+  server.time_until_store_dead: 10m
+  kv.rangefeed.enabled: true
+
+</PRE>`,
+	}
+
 	ZoneDisableReplication = FlagInfo{
 		Name: "disable-replication",
 		Description: `
@@ -463,6 +484,35 @@ disabled by setting the environment variable COCKROACH_SKIP_KEY_PERMISSION_CHECK
 		Description: `Path to the CA key.`,
 	}
 
+	// Server version of the CA key flag, cannot be set through environment.
+	ServerCAKey = FlagInfo{
+		Name: "ca-key",
+		Description: `
+Path to the CA key. If specified, the node serves as a certificate authority
+for the cluster: it issues node and client certificates to parties presenting
+a join token created with "cockroach cert create-join-token". The CA
+certificate must be in the certs directory. Not allowed with --insecure.`,
+	}
+
+	JoinToken = FlagInfo{
+		Name:        "token",
+		EnvVar:      "COCKROACH_JOIN_TOKEN",
+		Description: `Join token created with "cockroach cert create-join-token".`,
+	}
+
+	JoinTokenLifetime = FlagInfo{
+		Name:        "lifetime",
+		Description: `Join token lifetime.`,
+	}
+
+	JoinTokenPrincipal = FlagInfo{
+		Name: "principal",
+		Description: `
+The user a certificate may be obtained for with the join token: "node" for a
+node certificate, or the name of a SQL user for a client certificate. A token
+for "root" must be requested explicitly.`,
+	}
+
 	// TODO(tschottdorf): once clockless mode becomes non-experimental, explain it here:
 	// <PRE>
 	//
@@ -542,6 +592,19 @@ memory that the store may consume, for example:
   --store=type=mem,size=20GiB
   --store=type=mem,size=90%
 
+</PRE>
+Each on-disk store shares the node-wide block cache (see --cache) by default.
+The "cache-size" field gives a store a dedicated block cache of the given size
+instead, and the "cache-shard-bits" field controls the number of shards (as a
+power of two) that the dedicated cache is split into. The
+"secondary-cache-path" and "secondary-cache-size" fields, which must be
+specified together, add a secondary block cache kept on a local (typically
+faster) device, for example:
+<PRE>
+
+  --store=path=/mnt/hdd01,cache-size=1GiB,cache-shard-bits=6
+  --store=path=/mnt/hdd01,secondary-cache-path=/mnt/ssd01/cache,secondary-cache-size=50GiB
+
 </PRE>
 Commas are forbidden in all values, since they are used to separate fields.
 Also, if you use equal signs in the file path to a store, you must use the
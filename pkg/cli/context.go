@@ -96,6 +96,9 @@ func initCLIDefaults() {
 	debugCtx.maxResults = 1000
 	debugCtx.ballastSize = base.SizeSpec{}
 
+	initCtx.bootstrapSQL = ""
+	initCtx.settingsFile = ""
+
 	zoneCtx.zoneConfig = ""
 	zoneCtx.zoneDisableReplication = false
 
@@ -104,6 +107,7 @@ func initCLIDefaults() {
 	serverCfg.PIDFile = ""
 	startCtx.serverInsecure = baseCfg.Insecure
 	startCtx.serverSSLCertsDir = base.DefaultCertsDirectory
+	startCtx.serverSSLCAKey = ""
 	startCtx.serverConnHost = ""
 	startCtx.tempDir = ""
 	startCtx.externalIODir = ""
@@ -203,6 +207,13 @@ var debugCtx struct {
 	maxResults        int64
 }
 
+// initCtx captures the command-line parameters of the `init` command.
+// Defaults set by InitCLIDefaults() above.
+var initCtx struct {
+	bootstrapSQL string
+	settingsFile string
+}
+
 // zoneCtx captures the command-line parameters of the `zone` command.
 // Defaults set by InitCLIDefaults() above.
 var zoneCtx struct {
@@ -216,6 +227,7 @@ var startCtx struct {
 	// server-specific values of some flags.
 	serverInsecure    bool
 	serverSSLCertsDir string
+	serverSSLCAKey    string
 	serverConnHost    string
 
 	// temporary directory to use to spill computation results to disk.
@@ -693,6 +693,7 @@ func runDebugGCCmd(cmd *cobra.Command, args []string) error {
 			snap,
 			hlc.Timestamp{WallTime: timeutil.Now().UnixNano()},
 			config.GCPolicy{TTLSeconds: 24 * 60 * 60 /* 1 day */},
+			hlc.MaxTimestamp, /* maxThreshold */
 			storage.NoopGCer{},
 			func(_ context.Context, _ []roachpb.Intent) error { return nil },
 			func(_ context.Context, _ *roachpb.Transaction, _ []roachpb.Intent) error { return nil },
@@ -968,6 +969,72 @@ func runDebugCompact(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+var debugRewriteStoreCmd = &cobra.Command{
+	Use:   "rewrite-store <directory> [rewrite...]",
+	Short: "rewrite the data of a store into the current format",
+	Long: `
+Runs the given rewrites of the data of a stopped store into the current
+format, or all of them if none is given. The rewrites that have already been
+completed on the store are skipped. An interrupted rewrite resumes where it
+stopped when the command is run again. Each rewrite is verified before it's
+marked as completed.
+
+Running the rewrites offline keeps the node from having to run them when it
+starts.
+`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: MaybeDecorateGRPCError(runDebugRewriteStore),
+}
+
+func runDebugRewriteStore(cmd *cobra.Command, args []string) error {
+	rewrites := storage.StoreRewrites
+	if len(args) > 1 {
+		rewrites = nil
+		for _, name := range args[1:] {
+			var found bool
+			for _, rw := range storage.StoreRewrites {
+				if rw.Name == name {
+					rewrites = append(rewrites, rw)
+					found = true
+					break
+				}
+			}
+			if !found {
+				var names []string
+				for _, rw := range storage.StoreRewrites {
+					names = append(names, rw.Name)
+				}
+				return errors.Errorf("unknown rewrite %q; available rewrites: %s",
+					name, strings.Join(names, ", "))
+			}
+		}
+	}
+
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.Background())
+
+	db, err := openExistingStore(args[0], stopper, false /* readOnly */)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	for _, rw := range rewrites {
+		fmt.Printf("%s: %s\n", rw.Name, rw.Description)
+		start := timeutil.Now()
+		alreadyDone, err := storage.RunStoreRewrite(ctx, db, rw)
+		if err != nil {
+			return err
+		}
+		if alreadyDone {
+			fmt.Printf("%s: already completed\n", rw.Name)
+		} else {
+			fmt.Printf("%s: completed and verified in %s\n", rw.Name, timeutil.Since(start))
+		}
+	}
+	return nil
+}
+
 var debugSSTablesCmd = &cobra.Command{
 	Use:   "sstables <directory>",
 	Short: "list the sstables in a store",
@@ -1164,6 +1231,7 @@ var DebugCmdsForRocksDB = []*cobra.Command{
 	debugRaftLogCmd,
 	debugRangeDataCmd,
 	debugRangeDescriptorsCmd,
+	debugRewriteStoreCmd,
 	debugSSTablesCmd,
 }
 
@@ -1173,6 +1241,7 @@ var debugCmds = append(DebugCmdsForRocksDB,
 	debugDecodeKeyCmd,
 	debugRocksDBCmd,
 	debugGossipValuesCmd,
+	debugSettingsCmd,
 	debugSyncTestCmd,
 	debugEnvCmd,
 	debugZipCmd,
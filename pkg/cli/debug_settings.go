@@ -0,0 +1,71 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var debugSettingsExportCmd = &cobra.Command{
+	Use:   "export [options]",
+	Short: "export the cluster configuration as a SQL script",
+	Long: `
+Outputs the SQL statements that recreate the configuration of the cluster:
+the cluster settings that have been changed from their defaults, the users
+and roles, the privileges on databases and tables, and the zone
+configurations. Passwords are not exported.
+
+The statements are idempotent, so the script can be replayed on a rebuilt
+cluster, e.g. with 'cockroach sql', once its databases and tables have been
+restored.
+`,
+	Args: cobra.NoArgs,
+	RunE: MaybeDecorateGRPCError(runDebugSettingsExport),
+}
+
+func runDebugSettingsExport(cmd *cobra.Command, args []string) error {
+	conn, err := getPasswordAndMakeSQLClient("cockroach debug settings export")
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, rows, err := runQuery(conn, makeQuery(`SHOW CLUSTER CONFIGURATION`), true /* showMoreChars */)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		fmt.Printf("%s;\n", row[0])
+	}
+	return nil
+}
+
+var debugSettingsCmds = []*cobra.Command{
+	debugSettingsExportCmd,
+}
+
+var debugSettingsCmd = &cobra.Command{
+	Use:   "settings",
+	Short: "export the cluster configuration",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmd.Usage()
+	},
+}
+
+func init() {
+	debugSettingsCmd.AddCommand(debugSettingsCmds...)
+}
@@ -222,6 +222,9 @@ func init() {
 		// Certificates directory. Use a server-specific flag and value to ignore environment
 		// variables, but share the same default.
 		StringFlag(f, &startCtx.serverSSLCertsDir, cliflags.ServerCertsDir, startCtx.serverSSLCertsDir)
+		// Path to the CA key, which enables the built-in CA. Also server-specific
+		// so that COCKROACH_CA_KEY doesn't turn it on inadvertently.
+		StringFlag(f, &startCtx.serverSSLCAKey, cliflags.ServerCAKey, startCtx.serverSSLCAKey)
 
 		// Cluster joining flags.
 		VarFlag(f, &serverCfg.JoinList, cliflags.Join)
@@ -258,6 +261,20 @@ func init() {
 		DurationFlag(f, &certificateLifetime, cliflags.CertificateLifetime, defaultCertLifetime)
 	}
 
+	{
+		f := createJoinTokenCmd.Flags()
+		StringFlag(f, &baseCfg.SSLCAKey, cliflags.CAKey, baseCfg.SSLCAKey)
+		DurationFlag(f, &joinTokenLifetime, cliflags.JoinTokenLifetime, defaultJoinTokenLifetime)
+		StringFlag(f, &joinTokenPrincipal, cliflags.JoinTokenPrincipal, defaultJoinTokenPrincipal)
+	}
+
+	for _, cmd := range []*cobra.Command{joinNodeCmd, joinClientCmd} {
+		f := cmd.Flags()
+		StringFlag(f, &joinToken, cliflags.JoinToken, joinToken)
+		IntFlag(f, &keySize, cliflags.KeySize, defaultKeySize)
+		BoolFlag(f, &overwriteFiles, cliflags.OverwriteFiles, false)
+	}
+
 	// The remaining flags are shared between all cert-generating functions.
 	for _, cmd := range []*cobra.Command{createCACertCmd, createNodeCertCmd, createClientCertCmd} {
 		f := cmd.Flags()
@@ -278,6 +295,7 @@ func init() {
 		/* StartCmd is covered above */
 	}
 	clientCmds = append(clientCmds, userCmds...)
+	clientCmds = append(clientCmds, debugSettingsCmds...)
 	clientCmds = append(clientCmds, zoneCmds...)
 	clientCmds = append(clientCmds, nodeCmds...)
 	clientCmds = append(clientCmds, initCmd)
@@ -320,6 +338,13 @@ func init() {
 	// Quit command.
 	BoolFlag(quitCmd.Flags(), &quitCtx.serverDecommission, cliflags.Decommission, quitCtx.serverDecommission)
 
+	// Init command.
+	{
+		f := initCmd.Flags()
+		StringFlag(f, &initCtx.bootstrapSQL, cliflags.InitBootstrapSQL, initCtx.bootstrapSQL)
+		StringFlag(f, &initCtx.settingsFile, cliflags.InitSettings, initCtx.settingsFile)
+	}
+
 	zf := setZoneCmd.Flags()
 	StringFlag(zf, &zoneCtx.zoneConfig, cliflags.ZoneConfig, zoneCtx.zoneConfig)
 	BoolFlag(zf, &zoneCtx.zoneDisableReplication, cliflags.ZoneDisableReplication, zoneCtx.zoneDisableReplication)
@@ -338,6 +363,7 @@ func init() {
 	sqlCmds := []*cobra.Command{sqlShellCmd, dumpCmd, demoCmd}
 	sqlCmds = append(sqlCmds, zoneCmds...)
 	sqlCmds = append(sqlCmds, userCmds...)
+	sqlCmds = append(sqlCmds, debugSettingsCmds...)
 	for _, cmd := range sqlCmds {
 		f := cmd.PersistentFlags()
 		BoolFlag(f, &sqlCtx.echo, cliflags.EchoSQL, sqlCtx.echo)
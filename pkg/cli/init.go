@@ -15,13 +15,24 @@
 package cli
 
 import (
+	"bytes"
 	"context"
+	"database/sql/driver"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"regexp"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	yaml "gopkg.in/yaml.v2"
 
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/lex"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
 )
 
 var initCmd = &cobra.Command{
@@ -37,6 +48,12 @@ must appear in the --join flags of other nodes.
 
 A node started without the --join flag initializes itself as a
 single-node cluster, so the init command is not used in that case.
+
+The cluster can be provisioned as part of initialization: the cluster
+settings in the file passed with --settings are applied, then the SQL
+statements in the file passed with --bootstrap-sql are executed, and
+the provisioning is recorded in the event log. Both files are read
+before the cluster is initialized.
 `,
 	Args: cobra.NoArgs,
 	RunE: maybeShoutError(MaybeDecorateGRPCError(runInit)),
@@ -46,6 +63,13 @@ func runInit(cmd *cobra.Command, args []string) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Read the provisioning files before initializing the cluster, so that
+	// mistakes in them don't leave it half-provisioned.
+	p, err := readProvisioning()
+	if err != nil {
+		return err
+	}
+
 	conn, _, finish, err := getClientGRPCConn(ctx)
 	if err != nil {
 		return err
@@ -59,5 +83,120 @@ func runInit(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Fprintln(os.Stdout, "Cluster successfully initialized")
+
+	if p.empty() {
+		return nil
+	}
+	if err := p.apply(ctx); err != nil {
+		return errors.Wrap(err, "cluster initialized but provisioning failed")
+	}
+	fmt.Fprintln(os.Stdout, "Cluster successfully provisioned")
 	return nil
 }
+
+// provisioning holds the contents of the provisioning files passed to the init
+// command.
+type provisioning struct {
+	// settings are the names and SQL literals of the values of the cluster
+	// settings to apply, in the order they appear in the file.
+	settings [][2]string
+	// bootstrapSQL are the SQL statements to execute.
+	bootstrapSQL string
+}
+
+// settingNameRE matches valid cluster setting names. Setting names are
+// spliced into SET CLUSTER SETTING statements, so be strict.
+var settingNameRE = regexp.MustCompile(`^[a-z0-9_]+(\.[a-z0-9_]+)*$`)
+
+func readProvisioning() (provisioning, error) {
+	var p provisioning
+	if initCtx.settingsFile != "" {
+		contents, err := ioutil.ReadFile(initCtx.settingsFile)
+		if err != nil {
+			return p, err
+		}
+		var settings yaml.MapSlice
+		if err := yaml.UnmarshalStrict(contents, &settings); err != nil {
+			return p, errors.Wrapf(err, "could not parse %s", initCtx.settingsFile)
+		}
+		for _, item := range settings {
+			name, ok := item.Key.(string)
+			if !ok || !settingNameRE.MatchString(name) {
+				return p, errors.Errorf("%s: invalid setting name: %v", initCtx.settingsFile, item.Key)
+			}
+			var value bytes.Buffer
+			switch v := item.Value.(type) {
+			case string:
+				lex.EncodeSQLString(&value, v)
+			case bool, int, int64, uint64, float64:
+				fmt.Fprint(&value, v)
+			default:
+				return p, errors.Errorf("%s: invalid value for setting %s: %v",
+					initCtx.settingsFile, name, item.Value)
+			}
+			p.settings = append(p.settings, [2]string{name, value.String()})
+		}
+	}
+	if initCtx.bootstrapSQL != "" {
+		contents, err := ioutil.ReadFile(initCtx.bootstrapSQL)
+		if err != nil {
+			return p, err
+		}
+		p.bootstrapSQL = string(contents)
+	}
+	return p, nil
+}
+
+func (p provisioning) empty() bool {
+	return len(p.settings) == 0 && p.bootstrapSQL == ""
+}
+
+// apply provisions a newly initialized cluster over a SQL connection.
+func (p provisioning) apply(ctx context.Context) error {
+	conn, err := getPasswordAndMakeSQLClient("cockroach init")
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// The node may take a moment to start serving SQL after the cluster is
+	// initialized. Its ID and our user are needed for the event log.
+	var vals []driver.Value
+	opts := retry.Options{MaxBackoff: time.Second, MaxRetries: 30}
+	for r := retry.StartWithCtx(ctx, opts); r.Next(); {
+		if vals, err = conn.QueryRow(
+			`SELECT node_id, current_user() FROM crdb_internal.node_build_info LIMIT 1`, nil,
+		); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return err
+	}
+	nodeID := vals[0].(int64)
+
+	detail := sql.EventLogClusterProvisionedDetail{User: formatVal(vals[1], false, false)}
+	for _, setting := range p.settings {
+		if err := conn.Exec(
+			fmt.Sprintf(`SET CLUSTER SETTING %s = %s`, setting[0], setting[1]), nil,
+		); err != nil {
+			return errors.Wrapf(err, "could not apply setting %s", setting[0])
+		}
+		detail.Settings = append(detail.Settings, setting[0])
+	}
+	if p.bootstrapSQL != "" {
+		if err := conn.Exec(p.bootstrapSQL, nil); err != nil {
+			return errors.Wrapf(err, "could not execute %s", initCtx.bootstrapSQL)
+		}
+		detail.BootstrapSQL = initCtx.bootstrapSQL
+	}
+
+	info, err := json.Marshal(detail)
+	if err != nil {
+		return err
+	}
+	return conn.Exec(`
+INSERT INTO system.eventlog (timestamp, "eventType", "targetID", "reportingID", info)
+VALUES (now(), $1, 0, $2, $3)`,
+		[]driver.Value{string(sql.EventLogClusterProvisioned), nodeID, string(info)})
+}
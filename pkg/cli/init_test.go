@@ -0,0 +1,84 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cli
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestReadProvisioning(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer initCLIDefaults()
+
+	dir, cleanupFn := testutils.TempDir(t)
+	defer cleanupFn()
+
+	write := func(name, contents string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	initCtx.settingsFile = write("settings.yaml", `
+server.time_until_store_dead: 10m
+kv.rangefeed.enabled: true
+kv.snapshot_rebalance.max_rate: 4 MiB
+sql.metrics.statement_details.threshold: 0.5
+kv.range_split.load_qps_threshold: 250
+cluster.organization: "Cockroach 'Labs'"
+`)
+	initCtx.bootstrapSQL = write("bootstrap.sql", `CREATE DATABASE foo; CREATE USER bar;`)
+
+	p, err := readProvisioning()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := provisioning{
+		settings: [][2]string{
+			{`server.time_until_store_dead`, `'10m'`},
+			{`kv.rangefeed.enabled`, `true`},
+			{`kv.snapshot_rebalance.max_rate`, `'4 MiB'`},
+			{`sql.metrics.statement_details.threshold`, `0.5`},
+			{`kv.range_split.load_qps_threshold`, `250`},
+			{`cluster.organization`, `e'Cockroach \'Labs\''`},
+		},
+		bootstrapSQL: `CREATE DATABASE foo; CREATE USER bar;`,
+	}
+	if !reflect.DeepEqual(expected, p) {
+		t.Fatalf("expected\n%+v\ngot\n%+v", expected, p)
+	}
+
+	for _, tc := range []struct {
+		settings string
+		expected string
+	}{
+		{`not a map`, `could not parse`},
+		{`"kv.foo; DROP DATABASE system": 1`, `invalid setting name`},
+		{`kv.foo: [1, 2]`, `invalid value for setting kv.foo`},
+	} {
+		initCtx.settingsFile = write("settings.yaml", tc.settings)
+		if _, err := readProvisioning(); !testutils.IsError(err, tc.expected) {
+			t.Errorf("%q: expected error %q, got %v", tc.settings, tc.expected, err)
+		}
+	}
+}
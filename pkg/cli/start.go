@@ -437,6 +437,7 @@ func runStart(cmd *cobra.Command, args []string) error {
 	// flags specified for the command.
 	serverCfg.Insecure = startCtx.serverInsecure
 	serverCfg.SSLCertsDir = startCtx.serverSSLCertsDir
+	serverCfg.SSLCAKey = startCtx.serverSSLCAKey
 	serverCfg.User = security.NodeUser
 	// As well as derived temporary/auxiliary directory specifications.
 	if serverCfg.Settings.ExternalIODir, err = initExternalIODir(ctx, serverCfg.Stores.Specs[0]); err != nil {
@@ -216,6 +216,10 @@ EOF
 
 Note that the specified zone config is merged with the existing zone config for
 the database or table.
+
+The zone configs of the .meta, .liveness and .system ranges and of the system
+database are validated further: num_replicas must be odd, and lease preferences
+must not contradict the constraints that apply to all replicas.
 `,
 	Args: cobra.ExactArgs(1),
 	RunE: MaybeDecorateGRPCError(runSetZone),
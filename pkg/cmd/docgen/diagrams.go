@@ -604,7 +604,7 @@ var specs = []stmtSpec{
 		name:   "drop_view",
 		stmt:   "drop_view_stmt",
 		inline: []string{"opt_drop_behavior", "table_name_list"},
-		match:  []*regexp.Regexp{regexp.MustCompile("'DROP' 'VIEW'")},
+		match:  []*regexp.Regexp{regexp.MustCompile("'DROP' ('MATERIALIZED' )?'VIEW'")},
 	},
 	{
 		name:   "experimental_audit",
@@ -742,6 +742,10 @@ var specs = []stmtSpec{
 		replace: map[string]string{"stmt_list": "'CREATE' 'TABLE' table_name '(' ( column_def ( ',' column_def )* ) ( 'CONSTRAINT' name | ) 'PRIMARY KEY' '(' ( column_name ( ',' column_name )* ) ')' ( table_constraints | ) ')'"},
 		unlink:  []string{"table_name", "column_name", "table_constraints"},
 	},
+	{
+		name: "refresh_materialized_view",
+		stmt: "refresh_stmt",
+	},
 	{
 		name:   "release_savepoint",
 		stmt:   "release_stmt",
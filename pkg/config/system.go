@@ -174,6 +174,15 @@ func (s SystemConfig) GetLargestObjectID(maxID uint32) (uint32, error) {
 // GetZoneConfigForKey looks up the zone config for the range containing 'key'.
 // It is the caller's responsibility to ensure that the range does not need to be split.
 func (s SystemConfig) GetZoneConfigForKey(key roachpb.RKey) (ZoneConfig, error) {
+	objectID, keySuffix := ObjectIDForKey(key)
+	return s.getZoneConfigForID(objectID, keySuffix)
+}
+
+// ObjectIDForKey returns the ID of the object whose zone config applies to
+// 'key': a table or database ID, or one of the pseudo-IDs of the system
+// ranges. It also returns the remainder of the key after the table prefix,
+// which selects the subzone.
+func ObjectIDForKey(key roachpb.RKey) (objectID uint32, keySuffix []byte) {
 	objectID, keySuffix, ok := DecodeObjectID(key)
 	if !ok {
 		// Not in the structured data namespace.
@@ -198,8 +207,7 @@ func (s SystemConfig) GetZoneConfigForKey(key roachpb.RKey) (ZoneConfig, error)
 			objectID = keys.SystemRangesID
 		}
 	}
-
-	return s.getZoneConfigForID(objectID, keySuffix)
+	return objectID, keySuffix
 }
 
 // getZoneConfigForID looks up the zone config for the object (table or database)
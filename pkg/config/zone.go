@@ -57,6 +57,20 @@ var NamedZonesByID = func() map[uint32]string {
 	return out
 }()
 
+// IsSystemRangesZoneID returns whether the zone with the given ID governs
+// ranges which the whole cluster depends on: the meta ranges, the node liveness
+// range, the remaining system ranges, and the system database, whose zone also
+// applies to the system config range. These zones commonly use a higher
+// replication factor and lease preferences than user data, so they are held to
+// stricter validation.
+func IsSystemRangesZoneID(id uint32) bool {
+	switch id {
+	case keys.MetaRangesID, keys.LivenessRangesID, keys.SystemRangesID, keys.SystemDatabaseID:
+		return true
+	}
+	return false
+}
+
 // ZoneSpecifierFromID creates a tree.ZoneSpecifier for the zone with the
 // given ID.
 func ZoneSpecifierFromID(
@@ -372,6 +386,16 @@ func (z *ZoneConfig) Validate() error {
 		}
 	}
 
+	if z.NumLearners < 0 {
+		return fmt.Errorf("NumLearners %d less than minimum allowed 0", z.NumLearners)
+	}
+	for _, constraint := range z.LearnerConstraints {
+		if constraint.Type == Constraint_DEPRECATED_POSITIVE {
+			return fmt.Errorf("learner constraints must either be required " +
+				"(prefixed with a '+') or prohibited (prefixed with a '-')")
+		}
+	}
+
 	for _, leasePref := range z.LeasePreferences {
 		if len(leasePref.Constraints) == 0 {
 			return fmt.Errorf("every lease preference must include at least one constraint")
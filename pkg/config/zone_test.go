@@ -215,6 +215,35 @@ func TestZoneConfigValidate(t *testing.T) {
 			},
 			"lease preference constraints must either be required .+ or prohibited .+",
 		},
+		{
+			ZoneConfig{
+				NumReplicas:   1,
+				RangeMaxBytes: DefaultZoneConfig().RangeMaxBytes,
+				GC:            GCPolicy{TTLSeconds: 1},
+				NumLearners:   -1,
+			},
+			"NumLearners -1 less than minimum allowed 0",
+		},
+		{
+			ZoneConfig{
+				NumReplicas:        1,
+				RangeMaxBytes:      DefaultZoneConfig().RangeMaxBytes,
+				GC:                 GCPolicy{TTLSeconds: 1},
+				NumLearners:        1,
+				LearnerConstraints: []Constraint{{Value: "a", Type: Constraint_DEPRECATED_POSITIVE}},
+			},
+			"learner constraints must either be required .+ or prohibited .+",
+		},
+		{
+			ZoneConfig{
+				NumReplicas:        3,
+				RangeMaxBytes:      DefaultZoneConfig().RangeMaxBytes,
+				GC:                 GCPolicy{TTLSeconds: 1},
+				NumLearners:        2,
+				LearnerConstraints: []Constraint{{Key: "region", Value: "eu", Type: Constraint_REQUIRED}},
+			},
+			"",
+		},
 		{
 			ZoneConfig{
 				NumReplicas:   1,
@@ -342,9 +371,11 @@ func TestZoneConfigMarshalYAML(t *testing.T) {
 	}
 
 	testCases := []struct {
-		constraints      []Constraints
-		leasePreferences []LeasePreference
-		expected         string
+		constraints        []Constraints
+		leasePreferences   []LeasePreference
+		numLearners        int32
+		learnerConstraints []Constraint
+		expected           string
 	}{
 		{
 			expected: `range_min_bytes: 1
@@ -566,6 +597,25 @@ gc:
 num_replicas: 1
 constraints: [+duck=foo]
 experimental_lease_preferences: [[+duck=bar1, +duck=bar2], [-duck=foo]]
+`,
+		},
+		{
+			numLearners: 2,
+			learnerConstraints: []Constraint{
+				{
+					Type:  Constraint_REQUIRED,
+					Key:   "region",
+					Value: "eu",
+				},
+			},
+			expected: `range_min_bytes: 1
+range_max_bytes: 1
+gc:
+  ttlseconds: 1
+num_replicas: 1
+constraints: []
+num_learners: 2
+learner_constraints: [+region=eu]
 `,
 		},
 	}
@@ -574,6 +624,8 @@ experimental_lease_preferences: [[+duck=bar1, +duck=bar2], [-duck=foo]]
 		t.Run("", func(t *testing.T) {
 			original.Constraints = tc.constraints
 			original.LeasePreferences = tc.leasePreferences
+			original.NumLearners = tc.numLearners
+			original.LearnerConstraints = tc.learnerConstraints
 			body, err := yaml.Marshal(original)
 			if err != nil {
 				t.Fatal(err)
@@ -51,6 +51,28 @@ func (l *LeasePreference) UnmarshalYAML(unmarshal func(interface{}) error) error
 	return nil
 }
 
+// constraintsConjunction is an alias for a slice of Constraint that can be
+// marshaled to/from YAML in the same short form as lease preferences.
+type constraintsConjunction []Constraint
+
+var _ yaml.Marshaler = constraintsConjunction{}
+var _ yaml.Unmarshaler = &constraintsConjunction{}
+
+// MarshalYAML implements yaml.Marshaler.
+func (c constraintsConjunction) MarshalYAML() (interface{}, error) {
+	return LeasePreference{Constraints: c}.MarshalYAML()
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (c *constraintsConjunction) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var l LeasePreference
+	if err := l.UnmarshalYAML(unmarshal); err != nil {
+		return err
+	}
+	*c = l.Constraints
+	return nil
+}
+
 var _ yaml.Marshaler = Constraints{}
 var _ yaml.Unmarshaler = &Constraints{}
 
@@ -188,14 +210,16 @@ func (c *ConstraintsList) UnmarshalYAML(unmarshal func(interface{}) error) error
 // auto-generated ZoneConfig type, but with []Constraints changed to
 // ConstraintsList for backwards-compatible yaml marshaling and unmarshaling.
 type marshalableZoneConfig struct {
-	RangeMinBytes    int64             `protobuf:"varint,2,opt,name=range_min_bytes,json=rangeMinBytes" json:"range_min_bytes" yaml:"range_min_bytes"`
-	RangeMaxBytes    int64             `protobuf:"varint,3,opt,name=range_max_bytes,json=rangeMaxBytes" json:"range_max_bytes" yaml:"range_max_bytes"`
-	GC               GCPolicy          `protobuf:"bytes,4,opt,name=gc" json:"gc"`
-	NumReplicas      int32             `protobuf:"varint,5,opt,name=num_replicas,json=numReplicas" json:"num_replicas" yaml:"num_replicas"`
-	Constraints      ConstraintsList   `protobuf:"bytes,6,rep,name=constraints" json:"constraints" yaml:"constraints,flow"`
-	LeasePreferences []LeasePreference `protobuf:"bytes,9,rep,name=lease_preferences,json=leasePreferences" json:"lease_preferences" yaml:"experimental_lease_preferences,flow,omitempty"`
-	Subzones         []Subzone         `protobuf:"bytes,8,rep,name=subzones" json:"subzones" yaml:"-"`
-	SubzoneSpans     []SubzoneSpan     `protobuf:"bytes,7,rep,name=subzone_spans,json=subzoneSpans" json:"subzone_spans" yaml:"-"`
+	RangeMinBytes      int64                  `protobuf:"varint,2,opt,name=range_min_bytes,json=rangeMinBytes" json:"range_min_bytes" yaml:"range_min_bytes"`
+	RangeMaxBytes      int64                  `protobuf:"varint,3,opt,name=range_max_bytes,json=rangeMaxBytes" json:"range_max_bytes" yaml:"range_max_bytes"`
+	GC                 GCPolicy               `protobuf:"bytes,4,opt,name=gc" json:"gc"`
+	NumReplicas        int32                  `protobuf:"varint,5,opt,name=num_replicas,json=numReplicas" json:"num_replicas" yaml:"num_replicas"`
+	Constraints        ConstraintsList        `protobuf:"bytes,6,rep,name=constraints" json:"constraints" yaml:"constraints,flow"`
+	LeasePreferences   []LeasePreference      `protobuf:"bytes,9,rep,name=lease_preferences,json=leasePreferences" json:"lease_preferences" yaml:"experimental_lease_preferences,flow,omitempty"`
+	Subzones           []Subzone              `protobuf:"bytes,8,rep,name=subzones" json:"subzones" yaml:"-"`
+	SubzoneSpans       []SubzoneSpan          `protobuf:"bytes,7,rep,name=subzone_spans,json=subzoneSpans" json:"subzone_spans" yaml:"-"`
+	NumLearners        int32                  `protobuf:"varint,10,opt,name=num_learners,json=numLearners" json:"num_learners" yaml:"num_learners,omitempty"`
+	LearnerConstraints constraintsConjunction `protobuf:"bytes,11,rep,name=learner_constraints,json=learnerConstraints" json:"learner_constraints" yaml:"learner_constraints,flow,omitempty"`
 }
 
 func zoneConfigToMarshalable(c ZoneConfig) marshalableZoneConfig {
@@ -210,6 +234,8 @@ func zoneConfigToMarshalable(c ZoneConfig) marshalableZoneConfig {
 	m.LeasePreferences = c.LeasePreferences
 	m.Subzones = c.Subzones
 	m.SubzoneSpans = c.SubzoneSpans
+	m.NumLearners = c.NumLearners
+	m.LearnerConstraints = constraintsConjunction(c.LearnerConstraints)
 	return m
 }
 
@@ -223,6 +249,8 @@ func zoneConfigFromMarshalable(m marshalableZoneConfig) ZoneConfig {
 	c.LeasePreferences = m.LeasePreferences
 	c.Subzones = m.Subzones
 	c.SubzoneSpans = m.SubzoneSpans
+	c.NumLearners = m.NumLearners
+	c.LearnerConstraints = []Constraint(m.LearnerConstraints)
 	return c
 }
 
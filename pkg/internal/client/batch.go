@@ -228,6 +228,17 @@ func (b *Batch) fillResults(ctx context.Context) {
 						dst.Value = &src.Value
 					}
 				}
+			case *roachpb.ScanAndLockRequest:
+				if result.Err == nil {
+					t := reply.(*roachpb.ScanAndLockResponse)
+					result.Rows = make([]KeyValue, len(t.Rows))
+					for j := range t.Rows {
+						src := &t.Rows[j]
+						dst := &result.Rows[j]
+						dst.Key = src.Key
+						dst.Value = &src.Value
+					}
+				}
 			case *roachpb.DeleteRequest:
 				row := &result.Rows[k]
 				row.Key = []byte(args.(*roachpb.DeleteRequest).Key)
@@ -475,6 +486,23 @@ func (b *Batch) Inc(key interface{}, value int64) {
 	b.initResult(1, 1, notRaw, nil)
 }
 
+// IncBatched is like Inc, but allows the leaseholder to combine the increment
+// with concurrent batched increments of the same key. This only has an effect
+// for a non-transactional batch containing just this request.
+//
+// key can be either a byte slice or a string.
+func (b *Batch) IncBatched(key interface{}, value int64) {
+	k, err := marshalKey(key)
+	if err != nil {
+		b.initResult(0, 1, notRaw, err)
+		return
+	}
+	req := roachpb.NewIncrement(k, value).(*roachpb.IncrementRequest)
+	req.Batched = true
+	b.appendReqs(req)
+	b.initResult(1, 1, notRaw, nil)
+}
+
 func (b *Batch) scan(s, e interface{}, isReverse bool) {
 	begin, err := marshalKey(s)
 	if err != nil {
@@ -516,6 +544,31 @@ func (b *Batch) ReverseScan(s, e interface{}) {
 	b.scan(s, e, true)
 }
 
+// ScanAndLock retrieves the key/values between begin (inclusive) and end
+// (exclusive) in ascending order, and locks every key returned on behalf of
+// the batch's transaction so that no other transaction can write them until
+// it finishes. It is only valid in a transactional batch, and is rejected until
+// the cluster version is at least VersionScanAndLock.
+//
+// A new result will be appended to the batch which will contain "rows" (each
+// row is a key/value pair) and Result.Err will indicate success or failure.
+//
+// key can be either a byte slice or a string.
+func (b *Batch) ScanAndLock(s, e interface{}) {
+	begin, err := marshalKey(s)
+	if err != nil {
+		b.initResult(0, 0, notRaw, err)
+		return
+	}
+	end, err := marshalKey(e)
+	if err != nil {
+		b.initResult(0, 0, notRaw, err)
+		return
+	}
+	b.appendReqs(roachpb.NewScanAndLock(begin, end))
+	b.initResult(1, 0, notRaw, nil)
+}
+
 // CheckConsistency creates a batch request to check the consistency of the
 // ranges holding the span of keys from s to e. It logs a diff of all the
 // keys that are inconsistent when withDiff is set to true.
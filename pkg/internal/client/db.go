@@ -677,3 +677,33 @@ func IncrementValRetryable(ctx context.Context, db *DB, key roachpb.Key, inc int
 	}
 	return res.ValueInt(), err
 }
+
+// maxBatchedIncrementRetries bounds the number of times IncrementValBatched
+// retries an increment.
+const maxBatchedIncrementRetries = 10
+
+// IncrementValBatched increments a key's value by a specified amount and
+// returns the new value.
+//
+// It is like IncrementValRetryable, but the increment may be combined with
+// concurrent increments of the same key by the leaseholder, which relieves
+// contention on hot counters, and it gives up after a bounded number of
+// retries instead of retrying indefinitely. As with IncrementValRetryable, the
+// key might be incremented multiple times because of the retries.
+func IncrementValBatched(ctx context.Context, db *DB, key roachpb.Key, inc int64) (int64, error) {
+	opts := base.DefaultRetryOptions()
+	opts.MaxRetries = maxBatchedIncrementRetries
+	var err error
+	var res KeyValue
+	for r := retry.StartWithCtx(ctx, opts); r.Next(); {
+		b := &Batch{}
+		b.IncBatched(key, inc)
+		res, err = getOneRow(db.Run(ctx, b), b)
+		switch err.(type) {
+		case *roachpb.UnhandledRetryableError, *roachpb.AmbiguousResultError:
+			continue
+		}
+		break
+	}
+	return res.ValueInt(), err
+}
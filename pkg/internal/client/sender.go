@@ -200,3 +200,34 @@ func Wrap(sender Sender, f func(roachpb.BatchRequest) roachpb.BatchRequest) Send
 		return sender.Send(ctx, f(ba))
 	})
 }
+
+// PartialResponseHandler is invoked by the DistSender with the response of
+// every range that a read-only batch spans, as soon as that response and
+// those of all the preceding ranges have arrived. This lets callers start
+// processing the results of a batch spanning many ranges, which are sent in
+// parallel when possible, before all of them are in.
+//
+// The positions map the requests in br to the requests of the batch passed
+// to Send. The partial responses are not final: every time the batch is sent
+// to the DistSender, including when it is retried by a layer above it (for
+// example after a refresh of the transaction's reads), the handler is first
+// invoked with a nil br, after which the partial responses delivered so far
+// must be discarded. They must also be discarded if the batch fails. The
+// handler must not modify br, but it may retain the rows it contains.
+type PartialResponseHandler func(br *roachpb.BatchResponse, positions []int)
+
+type partialResponseHandlerKey struct{}
+
+// WithPartialResponseHandler returns a context with which the read-only
+// batches sent through a DistSender stream their partial responses to the
+// given handler. Batches that fit in a single range are not streamed.
+func WithPartialResponseHandler(ctx context.Context, h PartialResponseHandler) context.Context {
+	return context.WithValue(ctx, partialResponseHandlerKey{}, h)
+}
+
+// PartialResponseHandlerFromContext returns the PartialResponseHandler
+// installed in the context with WithPartialResponseHandler, if any.
+func PartialResponseHandlerFromContext(ctx context.Context) PartialResponseHandler {
+	h, _ := ctx.Value(partialResponseHandlerKey{}).(PartialResponseHandler)
+	return h
+}
@@ -17,6 +17,7 @@ package client
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -27,6 +28,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
@@ -93,6 +95,9 @@ type Txn struct {
 		// sender is a stateful sender for use with transactions. A new sender is
 		// created on transaction restarts (not retries).
 		sender TxnSender
+		// kvStats accumulates statistics about the batches sent through this
+		// Txn, across restarts.
+		kvStats KVStats
 	}
 }
 
@@ -493,6 +498,28 @@ func (txn *Txn) ReverseScan(
 	return txn.scan(ctx, begin, end, maxRows, true)
 }
 
+// ScanAndLock retrieves the rows between begin (inclusive) and end (exclusive)
+// in ascending order and locks them, so that no other transaction can write
+// them until this one commits or aborts. This is the building block for
+// SELECT ... FOR UPDATE. It is rejected until the cluster version is at least
+// VersionScanAndLock.
+//
+// The returned []KeyValue will contain up to maxRows elements (or all results
+// when zero is supplied).
+//
+// key can be either a byte slice or a string.
+func (txn *Txn) ScanAndLock(
+	ctx context.Context, begin, end interface{}, maxRows int64,
+) ([]KeyValue, error) {
+	b := txn.NewBatch()
+	if maxRows > 0 {
+		b.Header.MaxSpanRequestKeys = maxRows
+	}
+	b.ScanAndLock(begin, end)
+	r, err := getOneResult(txn.Run(ctx, b), b)
+	return r.Rows, err
+}
+
 // Iterate performs a paginated scan and applying the function f to every page.
 // The semantics of retrieval and ordering are the same as for Scan. Note that
 // Txn auto-retries the transaction if necessary. Hence, the paginated data
@@ -1054,11 +1081,14 @@ func (txn *Txn) Send(
 
 	// Send call through the DB.
 	requestTxnID := ba.Txn.ID
+	start := timeutil.Now()
 	br, pErr := txn.db.sendUsingSender(ctx, ba, sender)
+	latency := timeutil.Since(start)
 
 	// Lock for the entire response postlude.
 	txn.mu.Lock()
 	defer txn.mu.Unlock()
+	txn.mu.kvStats.record(ba, br, latency)
 
 	// If we inserted a begin transaction request, remove it here.
 	if needBeginTxn {
@@ -1198,6 +1228,19 @@ func (txn *Txn) GetTxnCoordMeta() roachpb.TxnCoordMeta {
 	return txn.mu.sender.GetMeta()
 }
 
+// GetStrippedTxnCoordMeta is like GetTxnCoordMeta, but it strips out the
+// information that only the root transaction needs: the intents, the command
+// count and the refresh spans. The result is suitable for initializing a leaf
+// transaction, which reports its own metadata back to the root once it's done.
+func (txn *Txn) GetStrippedTxnCoordMeta() roachpb.TxnCoordMeta {
+	meta := txn.GetTxnCoordMeta()
+	meta.Intents = nil
+	meta.CommandCount = 0
+	meta.RefreshReads = nil
+	meta.RefreshWrites = nil
+	return meta
+}
+
 // AugmentTxnCoordMeta augments this transaction's TxnCoordMeta
 // information with the supplied meta. For use with GetTxnCoordMeta().
 func (txn *Txn) AugmentTxnCoordMeta(ctx context.Context, meta roachpb.TxnCoordMeta) {
@@ -1376,3 +1419,45 @@ func (txn *Txn) IsSerializablePushAndRefreshNotPossible() bool {
 func (txn *Txn) Type() TxnType {
 	return txn.typ
 }
+
+// KVStats accumulates statistics about the KV batches sent through a Txn.
+type KVStats struct {
+	// Requests is the number of requests sent.
+	Requests int64
+	// BytesRead is the size of the responses received.
+	BytesRead int64
+	// BytesWritten is the size of the batches sent which contained writes.
+	BytesWritten int64
+	// Latency is the cumulative time spent waiting for responses.
+	Latency time.Duration
+}
+
+func (s *KVStats) record(ba roachpb.BatchRequest, br *roachpb.BatchResponse, latency time.Duration) {
+	s.Requests += int64(len(ba.Requests))
+	if ba.IsWrite() {
+		s.BytesWritten += int64(ba.Size())
+	}
+	if br != nil {
+		s.BytesRead += int64(br.Size())
+	}
+	s.Latency += latency
+}
+
+// Sub returns the statistics accumulated since the earlier snapshot o.
+func (s KVStats) Sub(o KVStats) KVStats {
+	return KVStats{
+		Requests:     s.Requests - o.Requests,
+		BytesRead:    s.BytesRead - o.BytesRead,
+		BytesWritten: s.BytesWritten - o.BytesWritten,
+		Latency:      s.Latency - o.Latency,
+	}
+}
+
+// KVStats returns a snapshot of the statistics about the KV batches sent
+// through the transaction so far. Statements running concurrently in the
+// same transaction are not told apart.
+func (txn *Txn) KVStats() KVStats {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	return txn.mu.kvStats
+}
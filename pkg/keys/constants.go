@@ -76,6 +76,9 @@ var (
 	// localStoreSuggestedCompactionSuffix stores suggested compactions to
 	// be aggregated and processed on the store.
 	localStoreSuggestedCompactionSuffix = []byte("comp")
+	// localStoreRewriteSuffix stores the offline rewrites (see `cockroach
+	// debug rewrite-store`) that have been completed on the store.
+	localStoreRewriteSuffix = []byte("rwrt")
 
 	// LocalStoreSuggestedCompactionsMin is the start of the span of
 	// possible suggested compaction keys for a store.
@@ -233,6 +236,13 @@ var (
 	// StoreIDGenerator is the global store ID generator sequence.
 	StoreIDGenerator = roachpb.Key(makeKey(SystemPrefix, roachpb.RKey("store-idgen")))
 
+	// ProtectedTimestampPrefix is the key prefix for the protected timestamp
+	// records, which are keyed by their IDs.
+	ProtectedTimestampPrefix = roachpb.Key(makeKey(SystemPrefix, roachpb.RKey("protectedts-")))
+	// ProtectedTimestampKeyMax is the maximum value for any protected
+	// timestamp record key.
+	ProtectedTimestampKeyMax = ProtectedTimestampPrefix.PrefixEnd()
+
 	// StatusPrefix specifies the key prefix to store all status details.
 	StatusPrefix = roachpb.Key(makeKey(SystemPrefix, roachpb.RKey("status-")))
 	// StatusNodePrefix stores all status info for nodes.
@@ -311,17 +321,22 @@ const (
 	// to "Ranges" instead of a Table - these IDs are needed to store custom
 	// configuration for non-table ranges (e.g. Zone Configs).
 	// NOTE: IDs must be <= MaxReservedDescID.
-	LeaseTableID           = 11
-	EventLogTableID        = 12
-	RangeEventTableID      = 13
-	UITableID              = 14
-	JobsTableID            = 15
-	MetaRangesID           = 16
-	SystemRangesID         = 17
-	TimeseriesRangesID     = 18
-	WebSessionsTableID     = 19
-	TableStatisticsTableID = 20
-	LocationsTableID       = 21
-	LivenessRangesID       = 22
-	RoleMembersTableID     = 23
+	LeaseTableID              = 11
+	EventLogTableID           = 12
+	RangeEventTableID         = 13
+	UITableID                 = 14
+	JobsTableID               = 15
+	MetaRangesID              = 16
+	SystemRangesID            = 17
+	TimeseriesRangesID        = 18
+	WebSessionsTableID        = 19
+	TableStatisticsTableID    = 20
+	LocationsTableID          = 21
+	LivenessRangesID          = 22
+	RoleMembersTableID        = 23
+	IndexUsageStatsTableID    = 24
+	ScheduledJobsTableID      = 25
+	ReplicationReportsTableID = 26
+	StatementStatsTableID     = 27
+	JoinTokensTableID         = 28
 )
@@ -90,6 +90,12 @@ func StoreSuggestedCompactionKey(start, end roachpb.Key) roachpb.Key {
 	return MakeStoreKey(localStoreSuggestedCompactionSuffix, detail)
 }
 
+// StoreRewriteKey returns a store-local key that marks the completion of the
+// named offline rewrite of the store.
+func StoreRewriteKey(name string) roachpb.Key {
+	return MakeStoreKey(localStoreRewriteSuffix, roachpb.RKey(name))
+}
+
 // DecodeStoreSuggestedCompactionKey returns the start and end keys of
 // the suggested compaction's span.
 func DecodeStoreSuggestedCompactionKey(key roachpb.Key) (start, end roachpb.Key, err error) {
@@ -132,6 +138,15 @@ func NodeStatusKey(nodeID roachpb.NodeID) roachpb.Key {
 	return key
 }
 
+// ProtectedTimestampKey returns the key for the protected timestamp record
+// with the given ID.
+func ProtectedTimestampKey(id uuid.UUID) roachpb.Key {
+	key := make(roachpb.Key, 0, len(ProtectedTimestampPrefix)+id.Size())
+	key = append(key, ProtectedTimestampPrefix...)
+	key = append(key, id.GetBytes()...)
+	return key
+}
+
 func makePrefixWithRangeID(prefix []byte, rangeID roachpb.RangeID, infix roachpb.RKey) roachpb.Key {
 	// Size the key buffer so that it is large enough for most callers.
 	key := make(roachpb.Key, 0, 32)
@@ -181,6 +181,7 @@ var constSubKeyDict = []struct {
 	{"/gossipBootstrap", localStoreGossipSuffix},
 	{"/clusterVersion", localStoreClusterVersionSuffix},
 	{"/suggestedCompaction", localStoreSuggestedCompactionSuffix},
+	{"/rewrite", localStoreRewriteSuffix},
 }
 
 func suggestedCompactionKeyPrint(key roachpb.Key) string {
@@ -199,6 +200,9 @@ func localStoreKeyPrint(_ []encoding.Direction, key roachpb.Key) string {
 					append(roachpb.Key(nil), append(localStorePrefix, key...)...),
 				)
 			}
+			if v.key.Equal(localStoreRewriteSuffix) {
+				return v.name + "/" + string(key[len(v.key):])
+			}
 			return v.name
 		}
 	}
@@ -212,6 +216,9 @@ func localStoreKeyParse(input string) (remainder string, output roachpb.Key) {
 			if s.key.Equal(localStoreSuggestedCompactionSuffix) {
 				panic(&errUglifyUnsupported{errors.New("cannot parse suggested compaction key")})
 			}
+			if s.key.Equal(localStoreRewriteSuffix) {
+				panic(&errUglifyUnsupported{errors.New("cannot parse store rewrite key")})
+			}
 			output = MakeStoreKey(s.key, nil)
 			return
 		}
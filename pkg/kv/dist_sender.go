@@ -34,6 +34,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
 	"github.com/cockroachdb/cockroach/pkg/util/retry"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
 
 const (
@@ -92,6 +93,12 @@ var (
 		Measurement: "Errors",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaDistSenderRangeCachePushCount = metric.Metadata{
+		Name:        "distsender.rangecache.pushed",
+		Help:        "Number of range descriptor cache updates using descriptors pushed by leaseholders",
+		Measurement: "Updates",
+		Unit:        metric.Unit_COUNT,
+	}
 )
 
 var rangeDescriptorCacheSize = settings.RegisterIntSetting(
@@ -100,6 +107,17 @@ var rangeDescriptorCacheSize = settings.RegisterIntSetting(
 	1e6,
 )
 
+// MaxBatchSize is the maximum size of a batch sent to a range in a single RPC.
+// The DistSender splits larger batches into sequential chunks where that
+// preserves their semantics, and nodes reject larger batches received over
+// the network.
+var MaxBatchSize = settings.RegisterByteSizeSetting(
+	"kv.rpc.max_batch_size",
+	"maximum size of a batch sent in a single RPC; larger batches are split by the sender "+
+		"where possible and rejected by the receiver otherwise (0 disables)",
+	64<<20,
+)
+
 // DistSenderMetrics is the set of metrics for a given distributed sender.
 type DistSenderMetrics struct {
 	BatchCount             *metric.Counter
@@ -110,6 +128,7 @@ type DistSenderMetrics struct {
 	LocalSentCount         *metric.Counter
 	NextReplicaErrCount    *metric.Counter
 	NotLeaseHolderErrCount *metric.Counter
+	RangeCachePushCount    *metric.Counter
 }
 
 func makeDistSenderMetrics() DistSenderMetrics {
@@ -122,6 +141,7 @@ func makeDistSenderMetrics() DistSenderMetrics {
 		LocalSentCount:         metric.NewCounter(metaTransportLocalSentCount),
 		NextReplicaErrCount:    metric.NewCounter(metaTransportSenderNextReplicaErrCount),
 		NotLeaseHolderErrCount: metric.NewCounter(metaDistSenderNotLeaseHolderErrCount),
+		RangeCachePushCount:    metric.NewCounter(metaDistSenderRangeCachePushCount),
 	}
 }
 
@@ -428,6 +448,10 @@ func (ds *DistSender) sendSingleRange(
 		}
 	}
 
+	if chunks := chunkBatch(ba, MaxBatchSize.Get(&ds.st.SV)); len(chunks) > 1 {
+		return ds.sendChunks(ctx, ba, chunks, desc)
+	}
+
 	br, err := ds.sendRPC(ctx, desc.RangeID, replicas, ba)
 	if err != nil {
 		log.VErrEvent(ctx, 2, err.Error())
@@ -447,6 +471,93 @@ func (ds *DistSender) sendSingleRange(
 	return br, pErr
 }
 
+// chunkBatch splits a batch which is larger than maxSize into a sequence of
+// smaller batches which together contain the batch's requests, in order. It
+// returns nil if the batch is small enough or can't be split without changing
+// its semantics: a non-transactional write batch is atomic within a range and
+// a batch with a key or byte limit applies the limit across its requests. A
+// single request larger than maxSize ends up in a chunk of its own.
+func chunkBatch(ba roachpb.BatchRequest, maxSize int64) []roachpb.BatchRequest {
+	if maxSize <= 0 || len(ba.Requests) < 2 {
+		return nil
+	}
+	if ba.MaxSpanRequestKeys != 0 || ba.TargetBytes != 0 {
+		return nil
+	}
+	if ba.Txn == nil && (!ba.IsReadOnly() || ba.Timestamp == (hlc.Timestamp{})) {
+		// Chunks of a non-transactional read must share a timestamp to observe
+		// a consistent snapshot.
+		return nil
+	}
+	size := int64(ba.Size())
+	if size <= maxSize {
+		return nil
+	}
+	var reqsSize int64
+	for i := range ba.Requests {
+		reqsSize += int64(ba.Requests[i].Size())
+	}
+	// Every chunk carries the batch header.
+	budget := maxSize - (size - reqsSize)
+
+	var chunks []roachpb.BatchRequest
+	start := 0
+	var chunkSize int64
+	for i := range ba.Requests {
+		reqSize := int64(ba.Requests[i].Size())
+		if i > start && chunkSize+reqSize > budget {
+			chunk := ba
+			chunk.Requests = ba.Requests[start:i]
+			chunks = append(chunks, chunk)
+			start, chunkSize = i, 0
+		}
+		chunkSize += reqSize
+	}
+	chunk := ba
+	chunk.Requests = ba.Requests[start:]
+	return append(chunks, chunk)
+}
+
+// sendChunks sends the chunks of ba (see chunkBatch) to the range one after
+// another and combines their responses. Each chunk is sent with the
+// transaction as updated by the responses to the chunks preceding it. The
+// first error is returned with its index adjusted to refer to ba.
+func (ds *DistSender) sendChunks(
+	ctx context.Context,
+	ba roachpb.BatchRequest,
+	chunks []roachpb.BatchRequest,
+	desc *roachpb.RangeDescriptor,
+) (*roachpb.BatchResponse, *roachpb.Error) {
+	log.VEventf(ctx, 2, "sending batch of %d requests in %d chunks", len(ba.Requests), len(chunks))
+	br := &roachpb.BatchResponse{
+		Responses: make([]roachpb.ResponseUnion, len(ba.Requests)),
+	}
+	var txn *roachpb.Transaction
+	offset := 0
+	for _, chunk := range chunks {
+		// Each chunk is a batch of its own as far as replay detection goes.
+		chunk.RequestID = nil
+		chunk.UpdateTxn(txn)
+		reply, pErr := ds.sendSingleRange(ctx, chunk, desc)
+		if pErr != nil {
+			if pErr.Index != nil && pErr.Index.Index != -1 {
+				pErr.Index.Index += int32(offset)
+			}
+			return nil, pErr
+		}
+		positions := make([]int, len(chunk.Requests))
+		for i := range positions {
+			positions[i] = offset + i
+		}
+		if err := br.Combine(reply, positions); err != nil {
+			return nil, roachpb.NewError(err)
+		}
+		txn = reply.Txn
+		offset += len(chunk.Requests)
+	}
+	return br, nil
+}
+
 // initAndVerifyBatch initializes timestamp-related information and
 // verifies batch constraints before splitting.
 func (ds *DistSender) initAndVerifyBatch(
@@ -467,7 +578,7 @@ func (ds *DistSender) initAndVerifyBatch(
 		return roachpb.NewErrorf("empty batch")
 	}
 
-	if ba.MaxSpanRequestKeys != 0 {
+	if ba.MaxSpanRequestKeys != 0 || ba.TargetBytes != 0 {
 		// Verify that the batch contains only specific range requests or the
 		// Begin/EndTransactionRequest. Verify that a batch with a ReverseScan
 		// only contains ReverseScan range requests.
@@ -475,7 +586,7 @@ func (ds *DistSender) initAndVerifyBatch(
 		for _, req := range ba.Requests {
 			inner := req.GetInner()
 			switch inner.(type) {
-			case *roachpb.ScanRequest, *roachpb.DeleteRangeRequest:
+			case *roachpb.ScanRequest, *roachpb.ScanAndLockRequest, *roachpb.DeleteRangeRequest:
 				// Accepted range requests. All other range requests are still
 				// not supported. Note that ReverseScanRequest is _not_ handled here.
 				// TODO(vivek): don't enumerate all range requests.
@@ -602,10 +713,20 @@ func (ds *DistSender) Send(
 		splitET = true
 	}
 	parts := splitBatchAndCheckForRefreshSpans(ba, splitET)
-	if len(parts) > 1 && ba.MaxSpanRequestKeys != 0 {
+	if len(parts) > 1 && (ba.MaxSpanRequestKeys != 0 || ba.TargetBytes != 0) {
 		// We already verified above that the batch contains only scan requests of the same type.
 		// Such a batch should never need splitting.
-		panic("batch with MaxSpanRequestKeys needs splitting")
+		panic("batch with MaxSpanRequestKeys or TargetBytes needs splitting")
+	}
+
+	var onPartial client.PartialResponseHandler
+	if !ba.IsWrite() {
+		onPartial = client.PartialResponseHandlerFromContext(ctx)
+	}
+	if onPartial != nil {
+		// Let the handler know that it's receiving the responses of a new
+		// attempt at the batch.
+		onPartial(nil /* br */, nil /* positions */)
 	}
 
 	var pErr *roachpb.Error
@@ -622,8 +743,25 @@ func (ds *DistSender) Send(
 			return nil, roachpb.NewError(err)
 		}
 
+		var partOnPartial client.PartialResponseHandler
+		if onPartial != nil {
+			// The positions of the partial responses are relative to the
+			// part of the batch.
+			offset := errIdxOffset
+			partOnPartial = func(br *roachpb.BatchResponse, positions []int) {
+				if offset != 0 {
+					shifted := make([]int, len(positions))
+					for i, pos := range positions {
+						shifted[i] = pos + offset
+					}
+					positions = shifted
+				}
+				onPartial(br, positions)
+			}
+		}
+
 		var rpl *roachpb.BatchResponse
-		rpl, pErr = ds.divideAndSendBatchToRanges(ctx, ba, rs, 0 /* batchIdx */)
+		rpl, pErr = ds.divideAndSendBatchToRanges(ctx, ba, rs, 0 /* batchIdx */, partOnPartial)
 
 		if pErr == errNo1PCTxn {
 			// If we tried to send a single round-trip EndTransaction but
@@ -664,9 +802,17 @@ func (ds *DistSender) Send(
 		for _, rpl := range rplChunks[1:] {
 			reply.Responses = append(reply.Responses, rpl.Responses...)
 			reply.CollectedSpans = append(reply.CollectedSpans, rpl.CollectedSpans...)
+			reply.KeysRead += rpl.KeysRead
+			reply.BytesRead += rpl.BytesRead
+			reply.BytesWritten += rpl.BytesWritten
+			reply.RaftCommands += rpl.RaftCommands
 		}
 		lastHeader := rplChunks[len(rplChunks)-1].BatchResponse_Header
 		lastHeader.CollectedSpans = reply.CollectedSpans
+		lastHeader.KeysRead = reply.KeysRead
+		lastHeader.BytesRead = reply.BytesRead
+		lastHeader.BytesWritten = reply.BytesWritten
+		lastHeader.RaftCommands = reply.RaftCommands
 		reply.BatchResponse_Header = lastHeader
 	}
 
@@ -685,9 +831,14 @@ type response struct {
 // either serially or in parallel, if possible. batchIdx indicates
 // which partial fragment of the larger batch is being processed by
 // this method. It's specified as non-zero when this method is invoked
-// recursively.
+// recursively. If onPartial is set, the responses of the ranges are passed to
+// it in order as they arrive.
 func (ds *DistSender) divideAndSendBatchToRanges(
-	ctx context.Context, ba roachpb.BatchRequest, rs roachpb.RSpan, batchIdx int,
+	ctx context.Context,
+	ba roachpb.BatchRequest,
+	rs roachpb.RSpan,
+	batchIdx int,
+	onPartial client.PartialResponseHandler,
 ) (br *roachpb.BatchResponse, pErr *roachpb.Error) {
 	// Clone the BatchRequest's transaction so that future mutations to the
 	// proto don't affect the proto in this batch.
@@ -752,6 +903,13 @@ func (ds *DistSender) divideAndSendBatchToRanges(
 			}
 			hadSuccess = true
 
+			// The responses are read in the order of the ranges, so this one
+			// and all the preceding ones have arrived. Stream it, unless an
+			// error has already doomed the batch.
+			if onPartial != nil && pErr == nil {
+				onPartial(resp.reply, resp.positions)
+			}
+
 			// Combine the new response with the existing one (including updating
 			// the headers).
 			if err := br.Combine(resp.reply, resp.positions); err != nil {
@@ -787,7 +945,8 @@ func (ds *DistSender) divideAndSendBatchToRanges(
 	// If min_results is set, num_results will count how many results scans have
 	// accumulated so far.
 	var numResults int64
-	canParallelize := (ba.Header.MaxSpanRequestKeys == 0) && !stopAtRangeBoundary
+	canParallelize := (ba.Header.MaxSpanRequestKeys == 0) && (ba.Header.TargetBytes == 0) &&
+		!stopAtRangeBoundary
 
 	for ; ri.Valid(); ri.Seek(ctx, seekKey, scanDir) {
 		responseCh := make(chan response, 1)
@@ -809,10 +968,13 @@ func (ds *DistSender) divideAndSendBatchToRanges(
 			}
 			// If the request is more than but ends with EndTransaction, we
 			// want the caller to come again with the EndTransaction in an
-			// extra call.
+			// extra call. An EndTransaction carrying in-flight writes is a
+			// parallel commit and is sent alongside the writes it stages.
 			if l := len(ba.Requests) - 1; l > 0 && ba.Requests[l].GetInner().Method() == roachpb.EndTransaction {
-				responseCh <- response{pErr: errNo1PCTxn}
-				return
+				if et := ba.Requests[l].GetEndTransaction(); len(et.InFlightWrites) == 0 {
+					responseCh <- response{pErr: errNo1PCTxn}
+					return
+				}
 			}
 		}
 
@@ -861,12 +1023,13 @@ func (ds *DistSender) divideAndSendBatchToRanges(
 			// use it.
 			ba.UpdateTxn(resp.reply.Txn)
 
-			mightStopEarly := ba.MaxSpanRequestKeys > 0 || stopAtRangeBoundary
+			mightStopEarly := ba.MaxSpanRequestKeys > 0 || ba.TargetBytes > 0 || stopAtRangeBoundary
 			// Check whether we've received enough responses to exit query loop.
 			if mightStopEarly {
-				var replyResults int64
+				var replyResults, replyBytes int64
 				for _, r := range resp.reply.Responses {
 					replyResults += r.GetInner().Header().NumKeys
+					replyBytes += r.GetInner().Header().NumBytes
 				}
 				// Do accounting for results. It's important that we update
 				// MaxSpanRequestKeys and ScanOptions.MinResults, as ba might be
@@ -885,6 +1048,17 @@ func (ds *DistSender) divideAndSendBatchToRanges(
 						return
 					}
 				}
+				if ba.TargetBytes > 0 {
+					// Like MaxSpanRequestKeys, TargetBytes is updated for any
+					// further ranges. The target may be overshot, in which case
+					// we stop just the same.
+					ba.TargetBytes -= replyBytes
+					if ba.TargetBytes <= 0 {
+						couldHaveSkippedResponses = true
+						resumeReason = roachpb.RESUME_BYTE_LIMIT
+						return
+					}
+				}
 				var minResultsSatisfied bool
 				if !stopAtRangeBoundary {
 					minResultsSatisfied = true
@@ -1030,6 +1204,7 @@ func (ds *DistSender) sendPartialBatch(
 
 		// If sending succeeded, return immediately.
 		if pErr == nil {
+			ds.updateRangeCacheFromReply(ctx, desc, reply)
 			return response{reply: reply, positions: positions}
 		}
 
@@ -1092,7 +1267,7 @@ func (ds *DistSender) sendPartialBatch(
 			// batch here would give a potentially larger response slice
 			// with unknown mapping to our truncated reply).
 			log.VEventf(ctx, 1, "likely split; resending batch to span: %s", tErr)
-			reply, pErr = ds.divideAndSendBatchToRanges(ctx, ba, rs, batchIdx)
+			reply, pErr = ds.divideAndSendBatchToRanges(ctx, ba, rs, batchIdx, nil /* onPartial */)
 			return response{reply: reply, positions: positions, pErr: pErr}
 		}
 		break
@@ -1109,6 +1284,35 @@ func (ds *DistSender) sendPartialBatch(
 	return response{pErr: pErr}
 }
 
+// updateRangeCacheFromReply inserts the range descriptors that the range
+// which served a batch attached to its response after a recent split or merge
+// into the range descriptor cache, unless the descriptor used to address the
+// batch was already up to date. The descriptors are removed from the reply,
+// as they're of no use further up the stack.
+func (ds *DistSender) updateRangeCacheFromReply(
+	ctx context.Context, desc *roachpb.RangeDescriptor, reply *roachpb.BatchResponse,
+) {
+	descs := reply.RangeDescriptors
+	if len(descs) == 0 {
+		return
+	}
+	reply.RangeDescriptors = nil
+	for i := range descs {
+		if descs[i].RangeID == desc.RangeID && descs[i].RSpan().Equal(desc.RSpan()) {
+			return
+		}
+	}
+	log.VEventf(ctx, 2, "updating range descriptor cache with pushed descriptors %v", descs)
+	ds.metrics.RangeCachePushCount.Inc(1)
+	for i := range descs {
+		// Insert the descriptors one by one: InsertRangeDescriptors stops at
+		// the first descriptor that's already cached.
+		if err := ds.rangeCache.InsertRangeDescriptors(ctx, descs[i]); err != nil {
+			log.Warningf(ctx, "unable to insert pushed range descriptor %s: %s", &descs[i], err)
+		}
+	}
+}
+
 func (ds *DistSender) deduceRetryEarlyExitError(ctx context.Context) *roachpb.Error {
 	select {
 	case <-ds.rpcRetryOptions.Closer:
@@ -1236,6 +1440,18 @@ func (ds *DistSender) sendToReplicas(
 		haveCommit = etArg.(*roachpb.EndTransactionRequest).Commit
 	}
 
+	// Tag the batches that require replay detection with a request ID which
+	// lets the leaseholder detect replays, so that a batch whose first attempt
+	// failed ambiguously can be retried on another replica. The ID is copied
+	// since its Retry flag is mutated below and the transport holds on to it.
+	if args.RequestID != nil {
+		id := *args.RequestID
+		args.RequestID = &id
+	} else if args.RequiresReplayDetection() &&
+		ds.st.Version.IsActive(cluster.VersionReplayDetection) {
+		args.RequestID = &roachpb.RequestID{ID: uuid.MakeV4(), Issued: ds.clock.Now()}
+	}
+
 	transport, err := ds.transportFactory(opts, rpcContext, replicas, args)
 	if err != nil {
 		return nil, err
@@ -1269,6 +1485,12 @@ func (ds *DistSender) sendToReplicas(
 			if haveCommit && !grpcutil.RequestDidNotStart(err) {
 				ambiguousError = err
 			}
+			// If the batch carries a request ID, the replica receiving the
+			// retry will either recognize it as a replay or tell us that it
+			// cannot rule one out.
+			if args.RequestID != nil && !grpcutil.RequestDidNotStart(err) {
+				args.RequestID.Retry = true
+			}
 			log.VErrEventf(ctx, 2, "RPC error: %s", err)
 		} else {
 			propagateError := false
@@ -1301,6 +1523,16 @@ func (ds *DistSender) sendToReplicas(
 			}
 
 			if propagateError {
+				// A replica which evaluated a retried batch under replay
+				// detection has resolved the ambiguity, unless the batch never
+				// made it to evaluation.
+				if args.RequestID != nil && args.RequestID.Retry {
+					switch br.Error.GetDetail().(type) {
+					case *roachpb.RangeNotFoundError, *roachpb.RangeKeyMismatchError:
+					default:
+						ambiguousError = nil
+					}
+				}
 				if ambiguousError != nil {
 					return nil, roachpb.NewAmbiguousResultError(fmt.Sprintf("error=%s [propagate]", ambiguousError))
 				}
@@ -0,0 +1,300 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package kv
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/grpcutil"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+)
+
+// singleRangeInfo is a single range's worth of a RangeFeed request: the
+// descriptor of the range, the part of the requested span which it covers and
+// the timestamp to (re)start the RangeFeed at.
+type singleRangeInfo struct {
+	desc  *roachpb.RangeDescriptor
+	rs    roachpb.RSpan
+	ts    hlc.Timestamp
+	token *EvictionToken
+}
+
+// RangeFeed divides a RangeFeed request on range boundaries and establishes a
+// RangeFeed to each of the individual ranges. It streams back results on the
+// provided channel until the context is canceled or an unrecoverable error is
+// encountered.
+//
+// Ranges which split or merge while the RangeFeed is running are transparently
+// re-established over the new range boundaries, starting at the latest
+// checkpoint seen for the old range. The same value may therefore be sent on
+// the channel more than once, and checkpoints for different parts of the span
+// may be interleaved arbitrarily.
+func (ds *DistSender) RangeFeed(
+	ctx context.Context, args *roachpb.RangeFeedRequest, eventCh chan<- *roachpb.RangeFeedEvent,
+) *roachpb.Error {
+	ctx = ds.AnnotateCtx(ctx)
+
+	startRKey, err := keys.Addr(args.Span.Key)
+	if err != nil {
+		return roachpb.NewError(err)
+	}
+	endRKey, err := keys.Addr(args.Span.EndKey)
+	if err != nil {
+		return roachpb.NewError(err)
+	}
+	rs := roachpb.RSpan{Key: startRKey, EndKey: endRKey}
+
+	g := ctxgroup.WithContext(ctx)
+	// The goroutine below receives the subdivided ranges and establishes a
+	// RangeFeed for each. Ranges are sent to it both initially and whenever a
+	// partial RangeFeed finds that its range has split or merged.
+	rangeCh := make(chan singleRangeInfo, 16)
+	g.GoCtx(func(ctx context.Context) error {
+		for {
+			select {
+			case sri := <-rangeCh:
+				g.GoCtx(func(ctx context.Context) error {
+					return ds.partialRangeFeed(ctx, &sri, eventCh, rangeCh)
+				})
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	})
+	g.GoCtx(func(ctx context.Context) error {
+		return ds.divideAndSendRangeFeedToRanges(ctx, rs, args.Timestamp, rangeCh)
+	})
+	return roachpb.NewError(g.Wait())
+}
+
+// divideAndSendRangeFeedToRanges looks up the ranges covering the provided
+// span and sends a singleRangeInfo for each of them on rangeCh.
+func (ds *DistSender) divideAndSendRangeFeedToRanges(
+	ctx context.Context, rs roachpb.RSpan, ts hlc.Timestamp, rangeCh chan<- singleRangeInfo,
+) error {
+	nextRS := rs
+	ri := NewRangeIterator(ds)
+	for ri.Seek(ctx, nextRS.Key, Ascending); ri.Valid(); ri.Next(ctx) {
+		desc := ri.Desc()
+		partialRS, err := nextRS.Intersect(desc)
+		if err != nil {
+			return err
+		}
+		nextRS.Key = partialRS.EndKey
+		select {
+		case rangeCh <- singleRangeInfo{
+			desc:  desc,
+			rs:    partialRS,
+			ts:    ts,
+			token: ri.Token(),
+		}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if !ri.NeedAnother(nextRS) {
+			break
+		}
+	}
+	return ri.Error().GoError()
+}
+
+// partialRangeFeed establishes a RangeFeed to the range specified by
+// rangeInfo. The method will run until the context is canceled or an error
+// occurs. Errors that indicate that the range descriptor is stale lead to the
+// span being divided and sent to the new ranges over rangeCh.
+func (ds *DistSender) partialRangeFeed(
+	ctx context.Context,
+	rangeInfo *singleRangeInfo,
+	eventCh chan<- *roachpb.RangeFeedEvent,
+	rangeCh chan<- singleRangeInfo,
+) error {
+	// Bound the partial RangeFeed to the partial span.
+	span := rangeInfo.rs.AsRawSpanWithNoLocals()
+	ts := rangeInfo.ts
+
+	// Start a retry loop for sending the request to the range.
+	for r := retry.StartWithCtx(ctx, ds.rpcRetryOptions); r.Next(); {
+		// If we've cleared the descriptor on a send failure, re-lookup.
+		if rangeInfo.desc == nil {
+			var err error
+			rangeInfo.desc, rangeInfo.token, err = ds.getDescriptor(ctx, rangeInfo.rs.Key, nil, false)
+			if err != nil {
+				log.VErrEventf(ctx, 1, "range descriptor re-lookup failed: %s", err)
+				continue
+			}
+		}
+
+		// Establish a RangeFeed for a single range.
+		maxTS, pErr := ds.singleRangeFeed(ctx, span, ts, rangeInfo.desc, eventCh)
+
+		// Forward the timestamp in case we end up sending it again.
+		ts.Forward(maxTS)
+
+		if pErr == nil {
+			// The RangeFeed only ends without an error if its context is
+			// canceled, which the retry loop will notice.
+			continue
+		}
+		log.VErrEventf(ctx, 1, "RangeFeed %s disconnected with last checkpoint %s: %s", span, ts, pErr)
+		switch pErr.GetDetail().(type) {
+		case *roachpb.SendError, *roachpb.RangeNotFoundError:
+			// Evict the descriptor from the cache and reload on the next
+			// attempt.
+			if err := rangeInfo.token.Evict(ctx); err != nil {
+				return err
+			}
+			rangeInfo.desc = nil
+			continue
+		case *roachpb.NodeUnavailableError:
+			// The replica is shutting down or was unable to serve the
+			// RangeFeed. Try again.
+			continue
+		case *roachpb.RangeKeyMismatchError:
+			// The range split or merged. Evict the descriptor from the cache
+			// and divide the span over the new ranges.
+			if err := rangeInfo.token.Evict(ctx); err != nil {
+				return err
+			}
+			return ds.divideAndSendRangeFeedToRanges(ctx, rangeInfo.rs, ts, rangeCh)
+		default:
+			return pErr.GoError()
+		}
+	}
+	return ctx.Err()
+}
+
+// singleRangeFeed gathers and rearranges the replicas, and makes a RangeFeed
+// RPC call. Results will be sent on the provided channel. Returns the
+// timestamp of the maximum rangefeed checkpoint seen, which can be used to
+// re-establish the RangeFeed with a larger starting timestamp, reflecting the
+// fact that all values up to the last checkpoint have already been observed.
+// Returns the request's timestamp if no checkpoints are seen.
+func (ds *DistSender) singleRangeFeed(
+	ctx context.Context,
+	span roachpb.Span,
+	ts hlc.Timestamp,
+	desc *roachpb.RangeDescriptor,
+	eventCh chan<- *roachpb.RangeFeedEvent,
+) (hlc.Timestamp, *roachpb.Error) {
+	args := roachpb.RangeFeedRequest{
+		Span: span,
+		Header: roachpb.Header{
+			Timestamp: ts,
+			RangeID:   desc.RangeID,
+		},
+	}
+
+	replicas := NewReplicaSlice(ds.gossip, desc)
+	var latencyFn LatencyFunc
+	if ds.rpcContext != nil {
+		latencyFn = ds.rpcContext.RemoteClocks.Latency
+	}
+	replicas.OptimizeReplicaOrder(ds.getNodeDescriptor(), latencyFn)
+	// RangeFeeds are served by the lease holder, so if we know who that is, move
+	// it to the front.
+	if storeID, ok := ds.leaseHolderCache.Lookup(ctx, desc.RangeID); ok {
+		if i := replicas.FindReplica(storeID); i >= 0 {
+			replicas.MoveToFront(i)
+		}
+	}
+	if len(replicas) == 0 || ds.rpcContext == nil {
+		return args.Timestamp, roachpb.NewError(roachpb.NewSendError(
+			fmt.Sprintf("no replica node addresses available via gossip for r%d", desc.RangeID)))
+	}
+
+	for _, replica := range replicas {
+		args.Replica = replica.ReplicaDescriptor
+		pErr, tryNext := ds.singleReplicaRangeFeed(ctx, replica.addr(), &args, eventCh)
+		if !tryNext {
+			return args.Timestamp, pErr
+		}
+	}
+	return args.Timestamp, roachpb.NewError(roachpb.NewSendError(
+		fmt.Sprintf("sending RangeFeed to r%d failed on all replicas", desc.RangeID)))
+}
+
+// singleReplicaRangeFeed establishes a RangeFeed to the replica at the provided
+// address and sends the events it receives on the provided channel, forwarding
+// the request's timestamp as checkpoints are received. It returns whether the
+// RangeFeed should be attempted on the next replica, which is the case if the
+// replica couldn't be reached or isn't the lease holder.
+func (ds *DistSender) singleReplicaRangeFeed(
+	ctx context.Context,
+	addr string,
+	args *roachpb.RangeFeedRequest,
+	eventCh chan<- *roachpb.RangeFeedEvent,
+) (_ *roachpb.Error, tryNext bool) {
+	// Cancel the stream when we're done with it, however that happens.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := ds.rangeFeedStream(ctx, addr, args)
+	if err != nil {
+		log.VErrEventf(ctx, 2, "RPC error: %s", err)
+		return nil, true
+	}
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			return nil, false
+		}
+		if err != nil {
+			log.VErrEventf(ctx, 2, "RPC error: %s", err)
+			return nil, true
+		}
+		switch t := event.GetValue().(type) {
+		case *roachpb.RangeFeedCheckpoint:
+			if t.Span.Contains(args.Span) {
+				args.Timestamp.Forward(t.ResolvedTS)
+			}
+		case *roachpb.RangeFeedError:
+			log.VErrEventf(ctx, 2, "RangeFeedError: %s", t.Error.GoError())
+			if tErr, ok := t.Error.GetDetail().(*roachpb.NotLeaseHolderError); ok {
+				if tErr.LeaseHolder != nil {
+					ds.leaseHolderCache.Update(ctx, args.RangeID, tErr.LeaseHolder.StoreID)
+				}
+				return nil, true
+			}
+			return &t.Error, false
+		}
+		select {
+		case eventCh <- event:
+		case <-ctx.Done():
+			return roachpb.NewError(ctx.Err()), false
+		}
+	}
+}
+
+// rangeFeedStream dials the node at the provided address and opens a RangeFeed
+// stream to it.
+func (ds *DistSender) rangeFeedStream(
+	ctx context.Context, addr string, args *roachpb.RangeFeedRequest,
+) (roachpb.Internal_RangeFeedClient, error) {
+	conn, err := ds.rpcContext.GRPCDial(addr).Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := grpcutil.ConnectionReady(conn); err != nil {
+		return nil, err
+	}
+	return roachpb.NewInternalClient(conn).RangeFeed(ctx, args)
+}
@@ -17,6 +17,7 @@ package kv_test
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"sort"
 	"strings"
 	"sync/atomic"
@@ -588,6 +589,73 @@ func TestMultiRangeBoundedBatchScanUnsortedOrder(t *testing.T) {
 	checkScanResults(t, spans, b.Results, expResults, nil /* satisfied */, checkOptions{mode: Strict})
 }
 
+// TestMultiRangeBoundedBatchScanTargetBytes verifies that scans with a byte
+// target return partial results along with a resume span, and that resuming
+// them eventually returns all the rows, across range boundaries.
+func TestMultiRangeBoundedBatchScanTargetBytes(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, _ := startNoSplitServer(t)
+	ctx := context.TODO()
+	defer s.Stopper().Stop(ctx)
+
+	db := s.DB()
+	if err := setupMultipleRanges(ctx, db, "a", "b", "c", "d", "e", "f"); err != nil {
+		t.Fatal(err)
+	}
+
+	keys := []string{"a1", "a2", "a3", "b1", "b2", "c1", "d1", "f1", "f2"}
+	for _, key := range keys {
+		if err := db.Put(ctx, key, "value"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, reverse := range []bool{false, true} {
+		t.Run(fmt.Sprintf("reverse=%t", reverse), func(t *testing.T) {
+			expKeys := append([]string(nil), keys...)
+			if reverse {
+				for i, j := 0, len(expKeys)-1; i < j; i, j = i+1, j-1 {
+					expKeys[i], expKeys[j] = expKeys[j], expKeys[i]
+				}
+			}
+
+			// A target of a single byte is reached by the first row, so each
+			// batch returns exactly one row.
+			span := roachpb.Span{Key: roachpb.Key("a"), EndKey: roachpb.Key("z")}
+			var found []string
+			for {
+				b := &client.Batch{}
+				b.Header.TargetBytes = 1
+				if reverse {
+					b.ReverseScan(span.Key, span.EndKey)
+				} else {
+					b.Scan(span.Key, span.EndKey)
+				}
+				if err := db.Run(ctx, b); err != nil {
+					t.Fatal(err)
+				}
+				res := b.Results[0]
+				if len(res.Rows) > 1 {
+					t.Fatalf("expected at most one row, got %d", len(res.Rows))
+				}
+				for _, kv := range res.Rows {
+					found = append(found, string(kv.Key))
+				}
+				if res.ResumeSpan.Key == nil {
+					break
+				}
+				if res.ResumeReason != roachpb.RESUME_BYTE_LIMIT {
+					t.Fatalf("expected resume reason BYTE_LIMIT, got %s", res.ResumeReason)
+				}
+				span = res.ResumeSpan
+			}
+			if !reflect.DeepEqual(found, expKeys) {
+				t.Fatalf("expected keys %s, got %s", expKeys, found)
+			}
+		})
+	}
+}
+
 // TestMultiRangeBoundedBatchScanSortedOverlapping runs two overlapping
 // ordered (by start key) scan requests, and shows how the batch response can
 // contain two partial responses.
@@ -1400,6 +1400,80 @@ func TestMultiRangeMergeStaleDescriptor(t *testing.T) {
 	}
 }
 
+// TestRangeCacheUpdateFromPushedDescriptors verifies that the DistSender
+// updates its range descriptor cache with the descriptors that a recently
+// split range attaches to its responses.
+func TestRangeCacheUpdateFromPushedDescriptors(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+
+	g, clock := makeGossip(t, stopper)
+	replicas := []roachpb.ReplicaDescriptor{{NodeID: 1, StoreID: 1}}
+	// The descriptor from before the split, [a,KeyMax), and the two
+	// descriptors resulting from splitting it at c.
+	staleDesc := roachpb.RangeDescriptor{
+		RangeID: 2, StartKey: roachpb.RKey("a"), EndKey: roachpb.RKeyMax, Replicas: replicas,
+	}
+	leftDesc := roachpb.RangeDescriptor{
+		RangeID: 2, StartKey: roachpb.RKey("a"), EndKey: roachpb.RKey("c"), Replicas: replicas,
+	}
+	rightDesc := roachpb.RangeDescriptor{
+		RangeID: 3, StartKey: roachpb.RKey("c"), EndKey: roachpb.RKeyMax, Replicas: replicas,
+	}
+	var testFn rpcSendFn = func(
+		_ context.Context,
+		_ SendOptions,
+		_ ReplicaSlice,
+		ba roachpb.BatchRequest,
+		_ *rpc.Context,
+	) (*roachpb.BatchResponse, error) {
+		br := ba.CreateReply()
+		br.RangeDescriptors = []roachpb.RangeDescriptor{leftDesc, rightDesc}
+		return br, nil
+	}
+	cfg := DistSenderConfig{
+		AmbientCtx: log.AmbientContext{Tracer: tracing.NewTracer()},
+		Clock:      clock,
+		TestingKnobs: ClientTestingKnobs{
+			TransportFactory: adaptLegacyTransport(testFn),
+		},
+		RangeDescriptorDB: MockRangeDescriptorDB(func(key roachpb.RKey, _ bool) ([]roachpb.RangeDescriptor, []roachpb.RangeDescriptor, error) {
+			if key.Less(testMetaRangeDescriptor.EndKey) {
+				return []roachpb.RangeDescriptor{testMetaRangeDescriptor}, nil, nil
+			}
+			return []roachpb.RangeDescriptor{staleDesc}, nil, nil
+		}),
+	}
+	ds := NewDistSender(cfg, g)
+
+	for i := 0; i < 2; i++ {
+		var ba roachpb.BatchRequest
+		ba.Add(roachpb.NewGet(roachpb.Key("b")))
+		br, pErr := ds.Send(context.Background(), ba)
+		if pErr != nil {
+			t.Fatal(pErr)
+		}
+		if len(br.RangeDescriptors) != 0 {
+			t.Fatalf("expected pushed descriptors to be consumed, got %v", br.RangeDescriptors)
+		}
+		for _, expected := range []roachpb.RangeDescriptor{leftDesc, rightDesc} {
+			desc, err := ds.rangeCache.GetCachedRangeDescriptor(expected.StartKey, false /* inverted */)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if desc == nil || !desc.Equal(expected) {
+				t.Fatalf("expected cached descriptor %s, got %s", &expected, desc)
+			}
+		}
+		// The second batch is addressed using the up to date descriptor, so
+		// the cache isn't updated again.
+		if n := ds.metrics.RangeCachePushCount.Count(); n != 1 {
+			t.Fatalf("expected 1 range cache update, got %d", n)
+		}
+	}
+}
+
 // TestRangeLookupOptionOnReverseScan verifies that a lookup triggered by a
 // ReverseScan request has the useReverseScan specified.
 func TestRangeLookupOptionOnReverseScan(t *testing.T) {
@@ -2017,6 +2091,91 @@ func TestGatewayNodeID(t *testing.T) {
 	}
 }
 
+// TestDistSenderChunksOversizedBatches verifies that a transactional batch
+// larger than kv.rpc.max_batch_size is sent in chunks which respect the limit,
+// while a non-transactional write batch is sent as a whole.
+func TestDistSenderChunksOversizedBatches(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+
+	g, clock := makeGossip(t, stopper)
+	if err := g.SetNodeDescriptor(&roachpb.NodeDescriptor{NodeID: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	const maxSize = 4 << 10
+	st := cluster.MakeTestingClusterSettings()
+	MaxBatchSize.Override(&st.SV, maxSize)
+
+	var sent []int
+	var testFn rpcSendFn = func(
+		_ context.Context,
+		_ SendOptions,
+		_ ReplicaSlice,
+		ba roachpb.BatchRequest,
+		_ *rpc.Context,
+	) (*roachpb.BatchResponse, error) {
+		if ba.Txn != nil && ba.Size() > maxSize {
+			t.Errorf("sent batch of size %d exceeding %d", ba.Size(), maxSize)
+		}
+		sent = append(sent, len(ba.Requests))
+		return ba.CreateReply(), nil
+	}
+
+	cfg := DistSenderConfig{
+		AmbientCtx: log.AmbientContext{Tracer: tracing.NewTracer()},
+		Clock:      clock,
+		TestingKnobs: ClientTestingKnobs{
+			TransportFactory: adaptLegacyTransport(testFn),
+		},
+		RangeDescriptorDB: defaultMockRangeDescriptorDB,
+		Settings:          st,
+	}
+	ds := NewDistSender(cfg, g)
+
+	const numPuts = 10
+	value := roachpb.MakeValueFromBytes(bytes.Repeat([]byte("x"), 1<<10))
+	makeBatch := func() roachpb.BatchRequest {
+		var ba roachpb.BatchRequest
+		for i := 0; i < numPuts; i++ {
+			ba.Add(roachpb.NewPut(roachpb.Key(fmt.Sprintf("a%d", i)), value))
+		}
+		return ba
+	}
+
+	ba := makeBatch()
+	txn := roachpb.MakeTransaction("test", nil, 1.0, enginepb.SERIALIZABLE, clock.Now(), 0)
+	ba.Txn = &txn
+	br, pErr := ds.Send(context.Background(), ba)
+	if pErr != nil {
+		t.Fatal(pErr)
+	}
+	if len(sent) < 2 {
+		t.Fatalf("expected the batch to be sent in chunks, got %v", sent)
+	}
+	var total int
+	for _, n := range sent {
+		total += n
+	}
+	if total != numPuts {
+		t.Fatalf("expected %d requests to be sent, got %v", numPuts, sent)
+	}
+	for i, ru := range br.Responses {
+		if _, ok := ru.GetInner().(*roachpb.PutResponse); !ok {
+			t.Errorf("%d: expected a PutResponse, got %T", i, ru.GetInner())
+		}
+	}
+
+	sent = nil
+	if _, pErr := ds.Send(context.Background(), makeBatch()); pErr != nil {
+		t.Fatal(pErr)
+	}
+	if exp := []int{numPuts}; !reflect.DeepEqual(sent, exp) {
+		t.Fatalf("expected a non-transactional batch to be sent whole, got %v", sent)
+	}
+}
+
 // Regression test for #20067.
 // If a batch is partitioned into multiple partial batches, the
 // roachpb.Error.Index of each batch should correspond to its original index in
@@ -2171,3 +2330,94 @@ func TestErrorIndexAlignment(t *testing.T) {
 	}
 
 }
+
+// TestPartialResponseHandler verifies that the responses of the ranges that a
+// read-only batch spans are streamed in order to the PartialResponseHandler.
+func TestPartialResponseHandler(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+
+	g, clock := makeGossip(t, stopper)
+	if err := g.SetNodeDescriptor(&roachpb.NodeDescriptor{NodeID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	nd := &roachpb.NodeDescriptor{
+		NodeID:  roachpb.NodeID(1),
+		Address: util.MakeUnresolvedAddr(testAddress.Network(), testAddress.String()),
+	}
+	if err := g.AddInfoProto(gossip.MakeNodeIDKey(roachpb.NodeID(1)), nd, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	replicas := []roachpb.ReplicaDescriptor{{NodeID: 1, StoreID: 1}}
+	descDB := mockRangeDescriptorDBForDescs(
+		testMetaRangeDescriptor,
+		roachpb.RangeDescriptor{RangeID: 2, StartKey: testMetaEndKey, EndKey: roachpb.RKey("b"), Replicas: replicas},
+		roachpb.RangeDescriptor{RangeID: 3, StartKey: roachpb.RKey("b"), EndKey: roachpb.RKey("c"), Replicas: replicas},
+		roachpb.RangeDescriptor{RangeID: 4, StartKey: roachpb.RKey("c"), EndKey: roachpb.RKeyMax, Replicas: replicas},
+	)
+
+	// Every scan returns a row for the start key of its span.
+	var testFn rpcSendFn = func(
+		_ context.Context,
+		_ SendOptions,
+		_ ReplicaSlice,
+		ba roachpb.BatchRequest,
+		_ *rpc.Context,
+	) (*roachpb.BatchResponse, error) {
+		reply := ba.CreateReply()
+		for i, ru := range ba.Requests {
+			if scan, ok := ru.GetInner().(*roachpb.ScanRequest); ok {
+				reply.Responses[i].GetScan().Rows = []roachpb.KeyValue{{Key: scan.Key}}
+			}
+		}
+		return reply, nil
+	}
+
+	cfg := DistSenderConfig{
+		AmbientCtx: log.AmbientContext{Tracer: tracing.NewTracer()},
+		Clock:      clock,
+		TestingKnobs: ClientTestingKnobs{
+			TransportFactory: adaptLegacyTransport(testFn),
+		},
+		RangeDescriptorDB: descDB,
+	}
+	ds := NewDistSender(cfg, g)
+
+	var ba roachpb.BatchRequest
+	ba.Txn = &roachpb.Transaction{Name: "test"}
+	ba.Add(roachpb.NewScan(roachpb.Key("a"), roachpb.Key("d")))
+	ba.Add(roachpb.NewGet(roachpb.Key("bb")))
+
+	var partials []string
+	ctx := client.WithPartialResponseHandler(context.Background(),
+		func(br *roachpb.BatchResponse, positions []int) {
+			if br == nil {
+				partials = append(partials, "reset")
+				return
+			}
+			var rows []string
+			for i, ru := range br.Responses {
+				if scan, ok := ru.GetInner().(*roachpb.ScanResponse); ok {
+					for _, kv := range scan.Rows {
+						rows = append(rows, string(kv.Key))
+					}
+				}
+				rows = append(rows, strconv.Itoa(positions[i]))
+			}
+			partials = append(partials, fmt.Sprint(rows))
+		})
+	br, pErr := ds.Send(ctx, ba)
+	if pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	expected := []string{"reset", "[a 0]", "[b 0 1]", "[c 0]"}
+	if !reflect.DeepEqual(partials, expected) {
+		t.Errorf("expected partial responses %v, got %v", expected, partials)
+	}
+	if rows := br.Responses[0].GetScan().Rows; len(rows) != 3 {
+		t.Errorf("expected 3 rows in the combined response, got %d", len(rows))
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/rpc"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -46,6 +47,10 @@ func (n Node) Batch(
 	return &roachpb.BatchResponse{}, nil
 }
 
+func (n Node) RangeFeed(_ *roachpb.RangeFeedRequest, _ roachpb.Internal_RangeFeedServer) error {
+	panic("unimplemented")
+}
+
 // TestSendToOneClient verifies that Send correctly sends a request
 // to one server using the heartbeat RPC.
 func TestSendToOneClient(t *testing.T) {
@@ -188,6 +193,113 @@ func TestComplexScenarios(t *testing.T) {
 	}
 }
 
+// requestIDTransport is a firstNErrorTransport which records the request ID
+// carried by each attempt.
+type requestIDTransport struct {
+	firstNErrorTransport
+	attempts []roachpb.RequestID
+}
+
+func (r *requestIDTransport) SendNext(ctx context.Context) (*roachpb.BatchResponse, error) {
+	if id := r.args.RequestID; id != nil {
+		r.attempts = append(r.attempts, *id)
+	}
+	return r.firstNErrorTransport.SendNext(ctx)
+}
+
+// TestRetryWithRequestID verifies that a committing batch whose first
+// attempt failed ambiguously is retried on the next replica with the replay
+// flag set, rather than failing with an AmbiguousResultError, and that
+// transactional writes which don't commit are retried without a request ID.
+func TestRetryWithRequestID(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	txn := roachpb.MakeTransaction(
+		"test", roachpb.Key("a"), roachpb.NormalUserPriority, enginepb.SERIALIZABLE,
+		hlc.Timestamp{WallTime: 1}, 0,
+	)
+	testCases := []struct {
+		name         string
+		txn          *roachpb.Transaction
+		req          roachpb.Request
+		expRequestID bool
+	}{
+		{
+			name: "commit",
+			req: &roachpb.EndTransactionRequest{
+				RequestHeader: roachpb.RequestHeader{Key: roachpb.Key("a")},
+				Commit:        true,
+			},
+			expRequestID: true,
+		},
+		{
+			name: "non-transactional put",
+			req: &roachpb.PutRequest{
+				RequestHeader: roachpb.RequestHeader{Key: roachpb.Key("a")},
+			},
+			expRequestID: true,
+		},
+		{
+			name: "transactional put",
+			txn:  &txn,
+			req: &roachpb.PutRequest{
+				RequestHeader: roachpb.RequestHeader{Key: roachpb.Key("a")},
+			},
+			expRequestID: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var transport *requestIDTransport
+			ds := NewDistSender(DistSenderConfig{
+				AmbientCtx: log.AmbientContext{Tracer: tracing.NewTracer()},
+				Clock:      hlc.NewClock(hlc.UnixNano, time.Nanosecond),
+				TestingKnobs: ClientTestingKnobs{
+					TransportFactory: func(
+						_ SendOptions, _ *rpc.Context, replicas ReplicaSlice, args roachpb.BatchRequest,
+					) (Transport, error) {
+						transport = &requestIDTransport{
+							firstNErrorTransport: firstNErrorTransport{
+								replicas:  replicas,
+								args:      args,
+								numErrors: 1,
+							},
+						}
+						return transport, nil
+					},
+				},
+			}, nil)
+
+			var ba roachpb.BatchRequest
+			ba.Txn = tc.txn
+			ba.Add(tc.req)
+			replicas := makeReplicas(util.NewUnresolvedAddr("dummy", "1"), util.NewUnresolvedAddr("dummy", "2"))
+			if _, err := ds.sendToReplicas(
+				context.Background(), SendOptions{metrics: &ds.metrics}, 0, replicas, ba, nil,
+			); err != nil {
+				t.Fatal(err)
+			}
+
+			if !tc.expRequestID {
+				if len(transport.attempts) != 0 {
+					t.Fatalf("expected no attempt with a request ID, got %+v", transport.attempts)
+				}
+				return
+			}
+			if len(transport.attempts) != 2 {
+				t.Fatalf("expected two attempts with a request ID, got %+v", transport.attempts)
+			}
+			first, second := transport.attempts[0], transport.attempts[1]
+			if first.Retry || !second.Retry {
+				t.Errorf("expected only the second attempt to be flagged as a retry, got %+v", transport.attempts)
+			}
+			if first.ID != second.ID {
+				t.Errorf("expected both attempts to carry the same ID, got %s and %s", first.ID, second.ID)
+			}
+		})
+	}
+}
+
 // TestSplitHealthy tests that the splitHealthy helper function sorts healthy
 // nodes before unhealthy nodes.
 func TestSplitHealthy(t *testing.T) {
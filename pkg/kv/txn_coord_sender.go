@@ -25,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -41,6 +42,24 @@ const (
 	opHeartbeatLoop  = "heartbeat txn"
 )
 
+// lazyHeartbeatEnabled controls whether the heartbeat loop of a transaction is
+// only started once the first heartbeat is due. Most transactions finish well
+// within one heartbeat interval, and don't need a heartbeat goroutine at all.
+var lazyHeartbeatEnabled = settings.RegisterBoolSetting(
+	"kv.transaction.lazy_heartbeat.enabled",
+	"if set, defer starting a transaction's heartbeat loop until its first heartbeat is due",
+	false,
+)
+
+// maxTxnDuration is the age after which the heartbeat loop of a transaction
+// gives up and aborts the transaction. This keeps a stuck client from holding
+// on to its intents indefinitely.
+var maxTxnDuration = settings.RegisterNonNegativeDurationSetting(
+	"kv.transaction.max_duration",
+	"abort transactions which are still pending after this duration (0 disables)",
+	0,
+)
+
 // txnCoordState represents the state of the transaction coordinator.
 // It is an intermediate state which indicates we've finished the
 // transaction at the coordinator level and it's no longer legitimate
@@ -105,8 +124,11 @@ type TxnCoordSender struct {
 		txn roachpb.Transaction
 
 		// hbRunning is set if the TxnCoordSender has a heartbeat loop running for
-		// the transaction record.
+		// the transaction record, or one scheduled to start.
 		hbRunning bool
+		// hbTimer, if set, starts the heartbeat loop once the first heartbeat is
+		// due. See lazyHeartbeatEnabled.
+		hbTimer *time.Timer
 
 		// commandCount indicates how many requests have been sent through
 		// this transaction. Reset on retryable txn errors.
@@ -142,10 +164,12 @@ type TxnCoordSender struct {
 	// is embedded in the interceptorAlloc struct, so the entire stack is
 	// allocated together with TxnCoordSender without any additional heap
 	// allocations necessary.
-	interceptorStack [2]txnInterceptor
+	interceptorStack [4]txnInterceptor
 	interceptorAlloc struct {
 		txnIntentCollector
+		txnPipeliner
 		txnSpanRefresher
+		txnCommitter
 		txnLockGatekeeper // not in interceptorStack array.
 	}
 
@@ -234,10 +258,15 @@ type TxnMetrics struct {
 	Restarts *metric.Histogram
 
 	// Counts of restart types.
-	RestartsWriteTooOld    *metric.Counter
-	RestartsDeleteRange    *metric.Counter
-	RestartsSerializable   *metric.Counter
-	RestartsPossibleReplay *metric.Counter
+	RestartsWriteTooOld       *metric.Counter
+	RestartsDeleteRange       *metric.Counter
+	RestartsSerializable      *metric.Counter
+	RestartsPossibleReplay    *metric.Counter
+	RestartsAsyncWriteFailure *metric.Counter
+
+	// AbortsTTL counts transactions aborted for exceeding
+	// kv.transaction.max_duration.
+	AbortsTTL *metric.Counter
 }
 
 var (
@@ -304,22 +333,36 @@ var (
 		Measurement: "Restarted Transactions",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaRestartsAsyncWriteFailure = metric.Metadata{
+		Name:        "txn.restarts.asyncwritefailure",
+		Help:        "Number of restarts due to async consensus writes that failed to leave intents",
+		Measurement: "Restarted Transactions",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaAbortsTTL = metric.Metadata{
+		Name:        "txn.aborts.ttl",
+		Help:        "Number of KV transactions aborted for exceeding kv.transaction.max_duration",
+		Measurement: "KV Transactions",
+		Unit:        metric.Unit_COUNT,
+	}
 )
 
 // MakeTxnMetrics returns a TxnMetrics struct that contains metrics whose
 // windowed portions retain data for approximately histogramWindow.
 func MakeTxnMetrics(histogramWindow time.Duration) TxnMetrics {
 	return TxnMetrics{
-		Aborts:                 metric.NewCounterWithRates(metaAbortsRates),
-		Commits:                metric.NewCounterWithRates(metaCommitsRates),
-		Commits1PC:             metric.NewCounterWithRates(metaCommits1PCRates),
-		AutoRetries:            metric.NewCounterWithRates(metaAutoRetriesRates),
-		Durations:              metric.NewLatency(metaDurationsHistograms, histogramWindow),
-		Restarts:               metric.NewHistogram(metaRestartsHistogram, histogramWindow, 100, 3),
-		RestartsWriteTooOld:    metric.NewCounter(metaRestartsWriteTooOld),
-		RestartsDeleteRange:    metric.NewCounter(metaRestartsDeleteRange),
-		RestartsSerializable:   metric.NewCounter(metaRestartsSerializable),
-		RestartsPossibleReplay: metric.NewCounter(metaRestartsPossibleReplay),
+		Aborts:                    metric.NewCounterWithRates(metaAbortsRates),
+		Commits:                   metric.NewCounterWithRates(metaCommitsRates),
+		Commits1PC:                metric.NewCounterWithRates(metaCommits1PCRates),
+		AutoRetries:               metric.NewCounterWithRates(metaAutoRetriesRates),
+		Durations:                 metric.NewLatency(metaDurationsHistograms, histogramWindow),
+		Restarts:                  metric.NewHistogram(metaRestartsHistogram, histogramWindow, 100, 3),
+		RestartsWriteTooOld:       metric.NewCounter(metaRestartsWriteTooOld),
+		RestartsDeleteRange:       metric.NewCounter(metaRestartsDeleteRange),
+		RestartsSerializable:      metric.NewCounter(metaRestartsSerializable),
+		RestartsPossibleReplay:    metric.NewCounter(metaRestartsPossibleReplay),
+		RestartsAsyncWriteFailure: metric.NewCounter(metaRestartsAsyncWriteFailure),
+		AbortsTTL:                 metric.NewCounter(metaAbortsTTL),
 	}
 }
 
@@ -407,6 +450,9 @@ func (tcf *TxnCoordSenderFactory) TransactionalSender(
 		st: tcf.st,
 		ri: ri,
 	}
+	tcs.interceptorAlloc.txnPipeliner = txnPipeliner{
+		st: tcf.st,
+	}
 	tcs.interceptorAlloc.txnSpanRefresher = txnSpanRefresher{
 		st:           tcf.st,
 		knobs:        &tcf.testingKnobs,
@@ -418,13 +464,21 @@ func (tcf *TxnCoordSenderFactory) TransactionalSender(
 		canAutoRetry:     typ == client.RootTxn,
 		autoRetryCounter: tcs.metrics.AutoRetries,
 	}
+	tcs.interceptorAlloc.txnCommitter = txnCommitter{
+		AmbientContext: tcf.AmbientContext,
+		st:             tcf.st,
+		stopper:        tcf.stopper,
+		sender:         tcf.wrapped,
+	}
 	tcs.interceptorAlloc.txnLockGatekeeper = txnLockGatekeeper{
 		mu:      &tcs.mu,
 		wrapped: tcs.wrapped,
 	}
 	tcs.interceptorStack = [...]txnInterceptor{
 		&tcs.interceptorAlloc.txnIntentCollector,
+		&tcs.interceptorAlloc.txnPipeliner,
 		&tcs.interceptorAlloc.txnSpanRefresher,
+		&tcs.interceptorAlloc.txnCommitter,
 	}
 	for i, reqInt := range tcs.interceptorStack {
 		if i < len(tcs.interceptorStack)-1 {
@@ -688,6 +742,10 @@ func (tc *TxnCoordSender) validateTxnForBatchLocked(
 	var haveBeginTxn bool
 	for _, req := range ba.Requests {
 		args := req.GetInner()
+		if _, ok := args.(*roachpb.ScanAndLockRequest); ok &&
+			!tc.st.Version.IsActive(cluster.VersionScanAndLock) {
+			return errors.Errorf("cluster version does not support ScanAndLock requests")
+		}
 		if _, ok := args.(*roachpb.BeginTransactionRequest); ok {
 			if haveBeginTxn {
 				return errors.Errorf("begin transaction requested twice in the same batch: %s", ba.Txn)
@@ -721,6 +779,14 @@ func (tc *TxnCoordSender) cleanupTxnLocked(ctx context.Context, state txnCoordSt
 	if tc.mu.txnEnd == nil {
 		return
 	}
+	// If the heartbeat loop was never started, there's nobody else to record
+	// the transaction's stats.
+	if tc.mu.hbTimer != nil && tc.mu.hbTimer.Stop() {
+		tc.mu.hbTimer = nil
+		tc.mu.hbRunning = false
+		duration, restarts, status := tc.finalTxnStatsLocked()
+		tc.updateStats(duration, restarts, status)
+	}
 	// Trigger heartbeat shutdown.
 	log.VEvent(ctx, 2, "coordinator stops")
 	close(tc.mu.txnEnd)
@@ -745,8 +811,11 @@ func (tc *TxnCoordSender) finalTxnStatsLocked() (duration, restarts int64, statu
 // attempting to resolve the intents. When the heartbeat stops, the transaction
 // stats are updated based on its final disposition.
 //
+// If heartbeatNow is set, the first heartbeat is sent right away instead of
+// after one heartbeat interval.
+//
 // TODO(wiz): Update (*DBServer).Batch to not use context.TODO().
-func (tc *TxnCoordSender) heartbeatLoop(ctx context.Context) {
+func (tc *TxnCoordSender) heartbeatLoop(ctx context.Context, heartbeatNow bool) {
 	var tickChan <-chan time.Time
 	{
 		ticker := time.NewTicker(tc.heartbeatInterval)
@@ -780,11 +849,14 @@ func (tc *TxnCoordSender) heartbeatLoop(ctx context.Context) {
 			return
 		}
 	}
+	if heartbeatNow && !tc.heartbeat(ctx) {
+		return
+	}
 	// Loop with ticker for periodic heartbeats.
 	for {
 		select {
 		case <-tickChan:
-			if !tc.heartbeat(ctx) {
+			if tc.maybeAbortExpiredTxn(ctx) || !tc.heartbeat(ctx) {
 				return
 			}
 		case <-closer:
@@ -796,6 +868,25 @@ func (tc *TxnCoordSender) heartbeatLoop(ctx context.Context) {
 	}
 }
 
+// maybeAbortExpiredTxn aborts the transaction if it has been running for
+// longer than kv.transaction.max_duration. Returns true if it did.
+func (tc *TxnCoordSender) maybeAbortExpiredTxn(ctx context.Context) bool {
+	maxDuration := maxTxnDuration.Get(&tc.st.SV)
+	if maxDuration == 0 {
+		return false
+	}
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	age := time.Duration(tc.clock.PhysicalNow() - tc.mu.firstUpdateNanos)
+	if age <= maxDuration || tc.mu.txn.Status != roachpb.PENDING {
+		return false
+	}
+	log.Warningf(ctx, "aborting transaction %s after %s (exceeds %s)", tc.mu.txn.ID.Short(), age, maxDuration)
+	tc.metrics.AbortsTTL.Inc(1)
+	tc.abortTxnAsyncLocked()
+	return true
+}
+
 // abortTxnAsyncLocked sends an EndTransaction asynchronously to the wrapped
 // Sender.
 func (tc *TxnCoordSender) abortTxnAsyncLocked() {
@@ -921,16 +1012,44 @@ func (tc *TxnCoordSender) startHeartbeatLoopLocked(ctx context.Context) error {
 		return nil
 	}
 
-	log.VEventf(ctx, 2, "coordinator spawns heartbeat loop")
 	// Create a channel to stop the heartbeat with the lock held
 	// to avoid a race between the async task and a subsequent commit.
 	tc.mu.txnEnd = make(chan struct{})
+	if lazyHeartbeatEnabled.Get(&tc.st.SV) {
+		log.VEventf(ctx, 2, "coordinator schedules heartbeat loop")
+		// Create a new context so that the heartbeat loop doesn't inherit the
+		// caller's cancelation.
+		hbCtx := tc.AnnotateCtx(context.Background())
+		tc.mu.hbTimer = time.AfterFunc(tc.heartbeatInterval, func() {
+			tc.mu.Lock()
+			defer tc.mu.Unlock()
+			tc.mu.hbTimer = nil
+			if tc.mu.txnEnd == nil {
+				// The transaction finished while we were waiting for the lock.
+				tc.mu.hbRunning = false
+				duration, restarts, status := tc.finalTxnStatsLocked()
+				tc.updateStats(duration, restarts, status)
+				return
+			}
+			if err := tc.runHeartbeatLoopLocked(hbCtx, true /* heartbeatNow */); err != nil {
+				log.VEventf(hbCtx, 2, "unable to start heartbeat loop: %s", err)
+			}
+		})
+		return nil
+	}
+	return tc.runHeartbeatLoopLocked(ctx, false /* heartbeatNow */)
+}
+
+// runHeartbeatLoopLocked spawns the goroutine running the heartbeat loop. The
+// caller must have created tc.mu.txnEnd.
+func (tc *TxnCoordSender) runHeartbeatLoopLocked(ctx context.Context, heartbeatNow bool) error {
+	log.VEventf(ctx, 2, "coordinator spawns heartbeat loop")
 	// Create a new context so that the heartbeat loop doesn't inherit the
 	// caller's cancelation.
 	hbCtx := tc.AnnotateCtx(context.Background())
 	if err := tc.stopper.RunAsyncTask(
 		ctx, "kv.TxnCoordSender: heartbeat loop", func(ctx context.Context) {
-			tc.heartbeatLoop(hbCtx)
+			tc.heartbeatLoop(hbCtx, heartbeatNow)
 		}); err != nil {
 		// The system is already draining and we can't start the
 		// heartbeat. We refuse new transactions for now because
@@ -997,6 +1116,8 @@ func (tc *TxnCoordSender) updateStateLocked(
 					tc.metrics.RestartsSerializable.Inc(1)
 				case roachpb.RETRY_POSSIBLE_REPLAY:
 					tc.metrics.RestartsPossibleReplay.Inc(1)
+				case roachpb.RETRY_ASYNC_WRITE_FAILURE:
+					tc.metrics.RestartsAsyncWriteFailure.Inc(1)
 				}
 			}
 			nextTxn := roachpb.PrepareTransactionForRetry(ctx, pErr, ba.UserPriority, tc.clock)
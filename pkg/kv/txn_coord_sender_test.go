@@ -30,6 +30,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/rpc"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
@@ -463,6 +464,85 @@ func TestTxnCoordSenderHeartbeat(t *testing.T) {
 	assertTransactionAbortedError(t, err)
 }
 
+// TestTxnCoordSenderLazyHeartbeat verifies that with lazy heartbeats enabled,
+// a transaction which finishes before its first heartbeat is due never starts
+// a heartbeat loop, but still has its stats recorded.
+func TestTxnCoordSenderLazyHeartbeat(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s := createTestDB(t)
+	defer s.Stop()
+
+	txn := client.NewTxn(s.DB, 0 /* gatewayNodeID */, client.RootTxn)
+	tc := txn.Sender().(*TxnCoordSender)
+	defer teardownHeartbeat(tc)
+	lazyHeartbeatEnabled.Override(&tc.st.SV, true)
+	tc.TxnCoordSenderFactory.heartbeatInterval = time.Hour
+	commits := tc.metrics.Commits.Count()
+
+	if err := txn.Put(context.TODO(), roachpb.Key("a"), []byte("value")); err != nil {
+		t.Fatal(err)
+	}
+	tc.mu.Lock()
+	scheduled := tc.mu.hbTimer != nil
+	tc.mu.Unlock()
+	if !scheduled {
+		t.Fatal("expected heartbeat loop to be scheduled")
+	}
+	if !tc.IsTracking() {
+		t.Fatal("expected transaction to be tracked")
+	}
+
+	if err := txn.CommitOrCleanup(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if tc.IsTracking() {
+		t.Fatal("expected transaction to no longer be tracked")
+	}
+	if a, e := tc.metrics.Commits.Count(), commits+1; a != e {
+		t.Fatalf("expected %d commits, got %d", e, a)
+	}
+}
+
+// TestTxnCoordSenderMaxDuration verifies that the heartbeat loop aborts a
+// transaction which exceeds kv.transaction.max_duration.
+func TestTxnCoordSenderMaxDuration(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s := createTestDB(t)
+	defer s.Stop()
+
+	txn := client.NewTxn(s.DB, 0 /* gatewayNodeID */, client.RootTxn)
+	tc := txn.Sender().(*TxnCoordSender)
+	defer teardownHeartbeat(tc)
+	maxTxnDuration.Override(&tc.st.SV, time.Nanosecond)
+	tc.TxnCoordSenderFactory.heartbeatInterval = time.Millisecond
+
+	if err := txn.Put(context.TODO(), roachpb.Key("a"), []byte("value")); err != nil {
+		t.Fatal(err)
+	}
+	// The heartbeat loop notices that the transaction has expired on its first
+	// tick. Advance the clock to make sure it has.
+	tc.mu.Lock()
+	s.Manual.Increment(int64(time.Millisecond))
+	tc.mu.Unlock()
+
+	testutils.SucceedsSoon(t, func() error {
+		tc.mu.Lock()
+		done := tc.mu.txnEnd == nil
+		tc.mu.Unlock()
+		if !done {
+			return fmt.Errorf("transaction is not aborted")
+		}
+		return nil
+	})
+	if a := tc.metrics.AbortsTTL.Count(); a != 1 {
+		t.Fatalf("expected 1 expired transaction, got %d", a)
+	}
+
+	_, err := txn.Get(context.TODO(), "a")
+	assertTransactionAbortedError(t, err)
+	verifyCleanup(roachpb.Key("a"), s.Eng, t, tc)
+}
+
 // getTxn fetches the requested key and returns the transaction info.
 func getTxn(ctx context.Context, txn *client.Txn) (*roachpb.Transaction, *roachpb.Error) {
 	hb := &roachpb.HeartbeatTxnRequest{
@@ -1114,6 +1194,170 @@ func TestTxnCoordSenderNoDuplicateIntents(t *testing.T) {
 	}
 }
 
+// TestTxnCoordSenderPipelinesWrites verifies that transactional writes are
+// sent with asynchronous consensus, that they are proven by QueryIntent
+// requests before the transaction commits, and that a failed proof results in
+// a retryable error.
+func TestTxnCoordSenderPipelinesWrites(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	clock := hlc.NewClock(hlc.UnixNano, time.Nanosecond)
+
+	var failProofs, lastCommit, lastGet atomic.Value
+	failProofs.Store(false)
+	var senderFn client.SenderFunc = func(_ context.Context, ba roachpb.BatchRequest) (
+		*roachpb.BatchResponse, *roachpb.Error) {
+		if _, ok := ba.GetArg(roachpb.EndTransaction); ok {
+			lastCommit.Store(ba)
+			if ba.AsyncConsensus {
+				t.Errorf("unexpected async consensus for batch with EndTransaction: %s", ba)
+			}
+		} else if _, ok := ba.GetArg(roachpb.Put); ok && !ba.AsyncConsensus {
+			t.Errorf("expected async consensus for batch: %s", ba)
+		} else if _, ok := ba.GetArg(roachpb.Get); ok {
+			lastGet.Store(ba)
+		}
+		if _, ok := ba.GetArg(roachpb.QueryIntent); ok && failProofs.Load().(bool) {
+			return nil, roachpb.NewErrorWithTxn(&roachpb.IntentMissingError{}, ba.Txn)
+		}
+		br := ba.CreateReply()
+		txnClone := ba.Txn.Clone()
+		br.Txn = &txnClone
+		br.Txn.Writing = true
+		return br, nil
+	}
+	ambient := log.AmbientContext{Tracer: tracing.NewTracer()}
+	st := cluster.MakeTestingClusterSettings()
+	pipelinedWritesEnabled.Override(&st.SV, true)
+	factory := NewTxnCoordSenderFactory(
+		TxnCoordSenderFactoryConfig{
+			AmbientCtx: ambient,
+			Settings:   st,
+			Clock:      clock,
+			Stopper:    stopper,
+		},
+		senderFn,
+	)
+	db := client.NewDB(ambient, factory, clock)
+
+	t.Run("commit", func(t *testing.T) {
+		txn := client.NewTxn(db, 0 /* gatewayNodeID */, client.RootTxn)
+		for _, k := range []string{"b", "a"} {
+			if err := txn.Put(context.TODO(), k, "value"); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// The commit must prove both writes, in key order, ahead of the
+		// EndTransaction.
+		b := txn.NewBatch()
+		b.Put("c", "value")
+		if err := txn.CommitInBatch(context.TODO(), b); err != nil {
+			t.Fatal(err)
+		}
+		commitBatch := lastCommit.Load().(roachpb.BatchRequest)
+		var methods []roachpb.Method
+		var keys []string
+		for _, ru := range commitBatch.Requests {
+			methods = append(methods, ru.GetInner().Method())
+			keys = append(keys, string(ru.GetInner().Header().Key))
+		}
+		expMethods := []roachpb.Method{
+			roachpb.QueryIntent, roachpb.QueryIntent, roachpb.Put, roachpb.EndTransaction,
+		}
+		if !reflect.DeepEqual(methods, expMethods) {
+			t.Fatalf("expected methods %s, found %s", expMethods, methods)
+		}
+		if expKeys := []string{"a", "b", "c"}; !reflect.DeepEqual(keys[:3], expKeys) {
+			t.Fatalf("expected keys %s, found %s", expKeys, keys[:3])
+		}
+	})
+
+	t.Run("failed proof", func(t *testing.T) {
+		txn := client.NewTxn(db, 0 /* gatewayNodeID */, client.RootTxn)
+		if err := txn.Put(context.TODO(), "a", "value"); err != nil {
+			t.Fatal(err)
+		}
+		failProofs.Store(true)
+		defer failProofs.Store(false)
+		err := txn.Put(context.TODO(), "a", "value2")
+		retErr, ok := err.(*roachpb.HandledRetryableTxnError)
+		if !ok {
+			t.Fatalf("expected retryable error, found %v", err)
+		}
+		if !testutils.IsError(retErr, "RETRY_ASYNC_WRITE_FAILURE") {
+			t.Fatalf("expected async write failure, found %v", retErr)
+		}
+	})
+
+	t.Run("leaf", func(t *testing.T) {
+		txn := client.NewTxn(db, 0 /* gatewayNodeID */, client.RootTxn)
+		if err := txn.Put(context.TODO(), "a", "value"); err != nil {
+			t.Fatal(err)
+		}
+
+		// A leaf initialized with the root's metadata must prove the root's
+		// outstanding write before reading the key.
+		leaf := client.NewTxnWithProto(db, 0 /* gatewayNodeID */, client.LeafTxn, *txn.Proto())
+		leaf.AugmentTxnCoordMeta(context.TODO(), txn.GetStrippedTxnCoordMeta())
+		if _, err := leaf.Get(context.TODO(), "a"); err != nil {
+			t.Fatal(err)
+		}
+		getBatch := lastGet.Load().(roachpb.BatchRequest)
+		var methods []roachpb.Method
+		for _, ru := range getBatch.Requests {
+			methods = append(methods, ru.GetInner().Method())
+		}
+		if expMethods := []roachpb.Method{roachpb.QueryIntent, roachpb.Get}; !reflect.DeepEqual(methods, expMethods) {
+			t.Fatalf("expected methods %s, found %s", expMethods, methods)
+		}
+
+		txn.AugmentTxnCoordMeta(context.TODO(), leaf.GetTxnCoordMeta())
+		if err := txn.Commit(context.TODO()); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// TestTxnCoordSenderScanAndLockVersionGate verifies that ScanAndLock requests
+// are rejected until all nodes can evaluate them.
+func TestTxnCoordSenderScanAndLockVersionGate(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	clock := hlc.NewClock(hlc.UnixNano, time.Nanosecond)
+
+	var senderFn client.SenderFunc = func(_ context.Context, ba roachpb.BatchRequest) (
+		*roachpb.BatchResponse, *roachpb.Error) {
+		if _, ok := ba.GetArg(roachpb.ScanAndLock); ok {
+			t.Errorf("unexpected ScanAndLock request: %s", ba)
+		}
+		return ba.CreateReply(), nil
+	}
+	ambient := log.AmbientContext{Tracer: tracing.NewTracer()}
+	st := cluster.MakeTestingClusterSettingsWithVersion(
+		cluster.VersionByKey(cluster.VersionImportPgURL),
+		cluster.VersionByKey(cluster.VersionImportPgURL),
+	)
+	factory := NewTxnCoordSenderFactory(
+		TxnCoordSenderFactoryConfig{
+			AmbientCtx: ambient,
+			Settings:   st,
+			Clock:      clock,
+			Stopper:    stopper,
+		},
+		senderFn,
+	)
+	db := client.NewDB(ambient, factory, clock)
+
+	txn := client.NewTxn(db, 0 /* gatewayNodeID */, client.RootTxn)
+	_, err := txn.ScanAndLock(context.TODO(), "a", "c", 0 /* maxRows */)
+	if !testutils.IsError(err, "cluster version does not support ScanAndLock requests") {
+		t.Fatalf("expected version gate error, found %v", err)
+	}
+}
+
 // checkTxnMetrics verifies that the provided Sender's transaction metrics match the expected
 // values. This is done through a series of retries with increasing backoffs, to work around
 // the TxnCoordSender's asynchronous updating of metrics after a transaction ends.
@@ -1451,9 +1695,14 @@ func TestAbortTransactionOnCommitErrors(t *testing.T) {
 				return br, nil
 			}
 			ambient := log.AmbientContext{Tracer: tracing.NewTracer()}
+			// The sender above expects the commit to be sent on its own, so
+			// don't let it be chained to the pipelined Put.
+			st := cluster.MakeTestingClusterSettings()
+			pipelinedWritesEnabled.Override(&st.SV, false)
 			factory := NewTxnCoordSenderFactory(
 				TxnCoordSenderFactoryConfig{
 					AmbientCtx: ambient,
+					Settings:   st,
 					Clock:      clock,
 					Stopper:    stopper,
 				},
@@ -0,0 +1,180 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package kv
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+)
+
+// parallelCommitsEnabled controls whether transactions may write their
+// transaction record in parallel with their final batch of writes.
+var parallelCommitsEnabled = settings.RegisterBoolSetting(
+	"kv.transaction.parallel_commits.enabled",
+	"if enabled, transactional commits will be parallelized with their final batch of writes",
+	false,
+)
+
+// txnCommitter is a txnInterceptor that parallelizes the commit of a
+// transaction with its final batch of writes. When the final batch qualifies,
+// the EndTransaction request is sent along with the writes it depends on and
+// moves the transaction record into the STAGING state. A STAGING transaction
+// whose in-flight writes have all succeeded at the staged timestamp is
+// implicitly committed, so the committer reports the commit to the client and
+// makes it explicit asynchronously.
+type txnCommitter struct {
+	log.AmbientContext
+	st      *cluster.Settings
+	stopper *stop.Stopper
+	wrapped lockedSender
+	// sender is the TxnCoordSender's wrapped sender, used to send the
+	// explicit commit without holding the TxnCoordSender's lock.
+	sender client.Sender
+}
+
+// SendLocked implements the lockedSender interface.
+func (tc *txnCommitter) SendLocked(
+	ctx context.Context, ba roachpb.BatchRequest,
+) (*roachpb.BatchResponse, *roachpb.Error) {
+	if !tc.canCommitInParallel(ba) {
+		return tc.wrapped.SendLocked(ctx, ba)
+	}
+
+	// Replace the EndTransaction with a copy so that the caller's request
+	// isn't mutated, then attach the writes it must wait for.
+	etIdx := len(ba.Requests) - 1
+	etCopy := *ba.Requests[etIdx].GetEndTransaction()
+	etCopy.InFlightWrites = make([]roachpb.SequencedWrite, 0, etIdx)
+	for _, ru := range ba.Requests[:etIdx] {
+		w := roachpb.SequencedWrite{Key: ru.GetInner().Header().Key}
+		if qi, ok := ru.GetInner().(*roachpb.QueryIntentRequest); ok {
+			// A pipelined write which is being proven in the same batch.
+			w.Sequence = qi.Txn.Sequence
+		} else {
+			w.Sequence = ru.GetInner().Header().Sequence
+		}
+		etCopy.InFlightWrites = append(etCopy.InFlightWrites, w)
+	}
+	ba.Requests = append([]roachpb.RequestUnion(nil), ba.Requests...)
+	ba.Requests[etIdx].MustSetInner(&etCopy)
+
+	br, pErr := tc.wrapped.SendLocked(ctx, ba)
+	if pErr != nil || br.Txn == nil || br.Txn.Status != roachpb.STAGING {
+		return br, pErr
+	}
+
+	// If any of the in-flight writes had its timestamp pushed, the staged
+	// record doesn't describe a committed transaction. Let the transaction
+	// retry; a later EndTransaction overwrites the STAGING record.
+	if ba.Txn.Timestamp.Less(br.Txn.Timestamp) {
+		return nil, roachpb.NewErrorWithTxn(
+			roachpb.NewTransactionRetryError(roachpb.RETRY_SERIALIZABLE), br.Txn,
+		)
+	}
+
+	// The transaction is implicitly committed.
+	br.Txn.Status = roachpb.COMMITTED
+	br.Txn.InFlightWrites = nil
+	tc.makeCommitExplicitAsync(br.Txn, etCopy.IntentSpans)
+	return br, nil
+}
+
+// canCommitInParallel returns whether the batch can be committed in parallel.
+// This is the case for a committing SERIALIZABLE batch that doesn't create
+// its transaction record and whose other requests are all point writes or
+// QueryIntent requests proving pipelined writes.
+func (tc *txnCommitter) canCommitInParallel(ba roachpb.BatchRequest) bool {
+	if !parallelCommitsEnabled.Get(&tc.st.SV) ||
+		!tc.st.Version.IsActive(cluster.VersionParallelCommits) {
+		return false
+	}
+	if ba.Txn == nil || ba.Txn.Isolation != enginepb.SERIALIZABLE {
+		return false
+	}
+	etIdx := len(ba.Requests) - 1
+	if etIdx < 1 {
+		return false
+	}
+	et, ok := ba.Requests[etIdx].GetInner().(*roachpb.EndTransactionRequest)
+	if !ok || !et.Commit || et.InternalCommitTrigger != nil {
+		return false
+	}
+	for _, ru := range ba.Requests[:etIdx] {
+		req := ru.GetInner()
+		if req.Method() == roachpb.BeginTransaction {
+			return false
+		}
+		if req.Method() == roachpb.QueryIntent {
+			continue
+		}
+		if !roachpb.IsTransactionWrite(req) || roachpb.IsRange(req) {
+			return false
+		}
+	}
+	return true
+}
+
+// makeCommitExplicitAsync marks the implicitly committed transaction as
+// explicitly committed and resolves its intents. Failures are only logged;
+// the transaction is committed either way and will be recovered by the
+// first pusher to find it expired.
+func (tc *txnCommitter) makeCommitExplicitAsync(txn *roachpb.Transaction, intents []roachpb.Span) {
+	// NB: We use context.Background() here because we don't want a canceled
+	// context to interrupt the commit.
+	ctx := tc.AnnotateCtx(context.Background())
+
+	hTxn := txn.Clone()
+	hTxn.Status = roachpb.PENDING
+	var ba roachpb.BatchRequest
+	ba.Header = roachpb.Header{Txn: &hTxn}
+	ba.Add(&roachpb.EndTransactionRequest{
+		RequestHeader: roachpb.RequestHeader{Key: txn.Key},
+		Commit:        true,
+		IntentSpans:   intents,
+	})
+
+	log.VEventf(ctx, 2, "async explicit commit for txn: %s", &hTxn)
+	if err := tc.stopper.RunAsyncTask(
+		ctx, "kv.txnCommitter: making commit explicit", func(ctx context.Context) {
+			if _, pErr := tc.sender.Send(ctx, ba); pErr != nil {
+				log.VErrEventf(ctx, 1, "async explicit commit failed for %s: %s", &hTxn, pErr)
+			}
+		},
+	); err != nil {
+		log.Warning(ctx, err)
+	}
+}
+
+// setWrapped implements the txnInterceptor interface.
+func (tc *txnCommitter) setWrapped(wrapped lockedSender) { tc.wrapped = wrapped }
+
+// populateMetaLocked implements the txnInterceptor interface.
+func (*txnCommitter) populateMetaLocked(meta *roachpb.TxnCoordMeta) {}
+
+// augmentMetaLocked implements the txnInterceptor interface.
+func (*txnCommitter) augmentMetaLocked(meta roachpb.TxnCoordMeta) {}
+
+// epochBumpedLocked implements the txnInterceptor interface.
+func (*txnCommitter) epochBumpedLocked() {}
+
+// closeLocked implements the txnInterceptor interface.
+func (*txnCommitter) closeLocked() {}
@@ -0,0 +1,257 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package kv
+
+import (
+	"context"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+var pipelinedWritesEnabled = settings.RegisterBoolSetting(
+	"kv.transaction.write_pipelining_enabled",
+	"if enabled, transactional writes are pipelined through Raft consensus",
+	false,
+)
+
+var pipelinedWritesMaxBatchSize = settings.RegisterIntSetting(
+	"kv.transaction.write_pipelining_max_batch_size",
+	"if non-zero, defines the maximum size batch that will be pipelined through Raft consensus",
+	128,
+)
+
+// txnPipeliner is a txnInterceptor that pipelines transactional writes by
+// using asynchronous consensus. A batch of point writes is acknowledged by
+// its leaseholder once it has been evaluated and proposed, so a transaction
+// no longer waits for Raft to apply each of its writes in turn.
+//
+// The writes are tracked as outstanding until they are proven to have
+// succeeded. A later request which overlaps an outstanding write is preceded
+// by a QueryIntent request for it in the same batch, which both waits for the
+// write to apply and checks that it succeeded. Before a transaction commits,
+// all of its outstanding writes are proven the same way. If a write turns out
+// to have failed, the transaction is retried.
+type txnPipeliner struct {
+	st      *cluster.Settings
+	wrapped lockedSender
+
+	// outstandingWrites maps each key written asynchronously and not yet
+	// proven to the sequence number of the latest such write.
+	outstandingWrites map[string]int32
+}
+
+// SendLocked implements the lockedSender interface.
+func (tp *txnPipeliner) SendLocked(
+	ctx context.Context, ba roachpb.BatchRequest,
+) (*roachpb.BatchResponse, *roachpb.Error) {
+	// Decide whether the batch may use asynchronous consensus before chaining
+	// it onto any outstanding writes.
+	ba.AsyncConsensus = tp.canUseAsyncConsensus(ba)
+
+	ba, proven := tp.chainToOutstandingWrites(ba)
+
+	br, pErr := tp.wrapped.SendLocked(ctx, ba)
+	if pErr != nil {
+		return nil, tp.adjustError(ctx, ba, len(proven), pErr)
+	}
+
+	// The proven writes succeeded.
+	for _, w := range proven {
+		if seq, ok := tp.outstandingWrites[string(w.Key)]; ok && seq <= w.Sequence {
+			delete(tp.outstandingWrites, string(w.Key))
+		}
+	}
+	if ba.AsyncConsensus {
+		for _, ru := range ba.Requests[len(proven):] {
+			req := ru.GetInner()
+			if req.Method() == roachpb.BeginTransaction {
+				continue
+			}
+			tp.addOutstandingWrite(req.Header())
+		}
+	}
+
+	// Strip the responses of the QueryIntent requests we added.
+	br.Responses = br.Responses[len(proven):]
+	return br, nil
+}
+
+// canUseAsyncConsensus returns whether the batch can be proposed with
+// asynchronous consensus. Only batches of transactional point writes qualify;
+// in particular, a batch with an EndTransaction must wait for consensus.
+func (tp *txnPipeliner) canUseAsyncConsensus(ba roachpb.BatchRequest) bool {
+	if !pipelinedWritesEnabled.Get(&tp.st.SV) ||
+		!tp.st.Version.IsActive(cluster.VersionAsyncConsensus) {
+		return false
+	}
+	if ba.Txn == nil {
+		return false
+	}
+	if max := pipelinedWritesMaxBatchSize.Get(&tp.st.SV); max > 0 && int64(len(ba.Requests)) > max {
+		return false
+	}
+	sawWrite := false
+	for _, ru := range ba.Requests {
+		req := ru.GetInner()
+		if req.Method() == roachpb.BeginTransaction {
+			// BeginTransaction is always sent along with a write. If it fails
+			// to apply, the transaction's record is missing and the commit
+			// fails.
+			continue
+		}
+		if !roachpb.IsTransactionWrite(req) || roachpb.IsRange(req) {
+			return false
+		}
+		sawWrite = true
+	}
+	return sawWrite
+}
+
+// chainToOutstandingWrites prepends a QueryIntent request to the batch for
+// each outstanding write it depends on: those which overlap one of its
+// requests, or all of them if the batch commits the transaction. A batch which
+// rolls the transaction back is left untouched. It returns the updated batch
+// along with the writes being proven, in batch order.
+func (tp *txnPipeliner) chainToOutstandingWrites(
+	ba roachpb.BatchRequest,
+) (roachpb.BatchRequest, []roachpb.SequencedWrite) {
+	if len(tp.outstandingWrites) == 0 {
+		return ba, nil
+	}
+	isCommitting := false
+	if args, ok := ba.GetArg(roachpb.EndTransaction); ok {
+		if !args.(*roachpb.EndTransactionRequest).Commit {
+			// A rollback doesn't depend on the success of any write.
+			return ba, nil
+		}
+		isCommitting = true
+	}
+
+	var proven []roachpb.SequencedWrite
+	for k, seq := range tp.outstandingWrites {
+		key := roachpb.Key(k)
+		if !isCommitting && !batchOverlapsKey(ba, key) {
+			continue
+		}
+		proven = append(proven, roachpb.SequencedWrite{Key: key, Sequence: seq})
+	}
+	if len(proven) == 0 {
+		return ba, nil
+	}
+	sort.Slice(proven, func(i, j int) bool { return proven[i].Key.Compare(proven[j].Key) < 0 })
+
+	reqs := make([]roachpb.RequestUnion, len(proven), len(proven)+len(ba.Requests))
+	for i, w := range proven {
+		meta := ba.Txn.TxnMeta
+		meta.Sequence = w.Sequence
+		reqs[i].MustSetInner(&roachpb.QueryIntentRequest{
+			RequestHeader: roachpb.RequestHeader{Key: w.Key},
+			Txn:           meta,
+			IfMissing:     roachpb.QueryIntentRequest_RETURN_ERROR,
+		})
+	}
+	ba.Requests = append(reqs, ba.Requests...)
+	return ba, proven
+}
+
+// adjustError maps an error for the batch sent with numProven QueryIntent
+// requests prepended back to the client's batch. A missing intent means that
+// an asynchronous write failed, which forces the transaction to retry.
+func (tp *txnPipeliner) adjustError(
+	ctx context.Context, ba roachpb.BatchRequest, numProven int, pErr *roachpb.Error,
+) *roachpb.Error {
+	if _, ok := pErr.GetDetail().(*roachpb.IntentMissingError); ok {
+		log.VEventf(ctx, 2, "transaction failed to prove an asynchronous write: %s", pErr)
+		txn := pErr.GetTxn()
+		if txn == nil {
+			txn = ba.Txn
+		}
+		return roachpb.NewErrorWithTxn(
+			roachpb.NewTransactionRetryError(roachpb.RETRY_ASYNC_WRITE_FAILURE), txn,
+		)
+	}
+	if pErr.Index != nil && numProven > 0 {
+		if idx := pErr.Index.Index - int32(numProven); idx >= 0 {
+			pErr.SetErrorIndex(idx)
+		} else {
+			pErr.Index = nil
+		}
+	}
+	return pErr
+}
+
+func (tp *txnPipeliner) addOutstandingWrite(h roachpb.RequestHeader) {
+	if tp.outstandingWrites == nil {
+		tp.outstandingWrites = make(map[string]int32)
+	}
+	if seq, ok := tp.outstandingWrites[string(h.Key)]; !ok || seq < h.Sequence {
+		tp.outstandingWrites[string(h.Key)] = h.Sequence
+	}
+}
+
+// batchOverlapsKey returns whether any of the requests in the batch overlaps
+// the given key.
+func batchOverlapsKey(ba roachpb.BatchRequest, key roachpb.Key) bool {
+	keySpan := roachpb.Span{Key: key}
+	for _, ru := range ba.Requests {
+		h := ru.GetInner().Header()
+		if keySpan.Overlaps(roachpb.Span{Key: h.Key, EndKey: h.EndKey}) {
+			return true
+		}
+	}
+	return false
+}
+
+// setWrapped implements the txnInterceptor interface.
+func (tp *txnPipeliner) setWrapped(wrapped lockedSender) { tp.wrapped = wrapped }
+
+// populateMetaLocked implements the txnInterceptor interface.
+func (tp *txnPipeliner) populateMetaLocked(meta *roachpb.TxnCoordMeta) {
+	if len(tp.outstandingWrites) == 0 {
+		return
+	}
+	meta.OutstandingWrites = make([]roachpb.SequencedWrite, 0, len(tp.outstandingWrites))
+	for k, seq := range tp.outstandingWrites {
+		meta.OutstandingWrites = append(meta.OutstandingWrites,
+			roachpb.SequencedWrite{Key: roachpb.Key(k), Sequence: seq})
+	}
+	sort.Slice(meta.OutstandingWrites, func(i, j int) bool {
+		return meta.OutstandingWrites[i].Key.Compare(meta.OutstandingWrites[j].Key) < 0
+	})
+}
+
+// augmentMetaLocked implements the txnInterceptor interface. This is how a
+// leaf transaction learns about the root's outstanding writes, so that the
+// reads it performs are chained onto the writes they overlap.
+func (tp *txnPipeliner) augmentMetaLocked(meta roachpb.TxnCoordMeta) {
+	for _, w := range meta.OutstandingWrites {
+		tp.addOutstandingWrite(roachpb.RequestHeader{Key: w.Key, Sequence: w.Sequence})
+	}
+}
+
+// epochBumpedLocked implements the txnInterceptor interface.
+func (tp *txnPipeliner) epochBumpedLocked() {
+	// Writes from a previous epoch don't need to be proven.
+	tp.outstandingWrites = nil
+}
+
+// closeLocked implements the txnInterceptor interface.
+func (tp *txnPipeliner) closeLocked() {
+	tp.outstandingWrites = nil
+}
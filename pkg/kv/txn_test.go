@@ -694,3 +694,66 @@ func TestTxnResolveIntentsFromMultipleEpochs(t *testing.T) {
 		}
 	}
 }
+
+// TestTxnScanAndLock verifies that ScanAndLock returns the values in its span
+// unchanged and prevents concurrent writers from overwriting them until the
+// locking transaction finishes.
+func TestTxnScanAndLock(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s := createTestDB(t)
+	defer s.Stop()
+
+	ctx := context.Background()
+	for _, key := range []string{"a", "b", "c"} {
+		if err := s.DB.Put(ctx, key, "orig-"+key); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	txn := client.NewTxn(s.DB, 0 /* gatewayNodeID */, client.RootTxn)
+	rows, err := txn.ScanAndLock(ctx, "a", "c", 0 /* maxRows */)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(rows))
+	}
+	for i, key := range []string{"a", "b"} {
+		if k, v := string(rows[i].Key), string(rows[i].ValueBytes()); k != key || v != "orig-"+key {
+			t.Fatalf("%d: expected %s=orig-%s, got %s=%s", i, key, key, k, v)
+		}
+	}
+
+	// A write outside of the locked span is not blocked.
+	if err := s.DB.Put(ctx, "c", "new-c"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A write to a locked key waits for the locking transaction.
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- s.DB.Put(ctx, "b", "new-b")
+	}()
+	select {
+	case err := <-errChan:
+		t.Fatalf("write to locked key did not block: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if err := txn.CommitOrCleanup(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errChan; err != nil {
+		t.Fatal(err)
+	}
+
+	for key, expected := range map[string]string{"a": "orig-a", "b": "new-b", "c": "new-c"} {
+		kv, err := s.DB.Get(ctx, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := string(kv.ValueBytes()); v != expected {
+			t.Errorf("%s: expected %s, got %s", key, expected, v)
+		}
+	}
+}
@@ -239,6 +239,7 @@ func (rh *ResponseHeader) combine(otherRH ResponseHeader) error {
 	rh.ResumeSpan = otherRH.ResumeSpan
 	rh.ResumeReason = otherRH.ResumeReason
 	rh.NumKeys += otherRH.NumKeys
+	rh.NumBytes += otherRH.NumBytes
 	rh.RangeInfos = append(rh.RangeInfos, otherRH.RangeInfos...)
 	return nil
 }
@@ -273,6 +274,20 @@ func (sr *ReverseScanResponse) combine(c combinable) error {
 
 var _ combinable = &ReverseScanResponse{}
 
+// combine implements the combinable interface.
+func (sr *ScanAndLockResponse) combine(c combinable) error {
+	otherSR := c.(*ScanAndLockResponse)
+	if sr != nil {
+		sr.Rows = append(sr.Rows, otherSR.Rows...)
+		if err := sr.ResponseHeader.combine(otherSR.Header()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var _ combinable = &ScanAndLockResponse{}
+
 // combine implements the combinable interface.
 func (dr *DeleteRangeResponse) combine(c combinable) error {
 	otherDR := c.(*DeleteRangeResponse)
@@ -381,6 +396,11 @@ func (h *BatchResponse_Header) combine(o BatchResponse_Header) error {
 	}
 	h.Now.Forward(o.Now)
 	h.CollectedSpans = append(h.CollectedSpans, o.CollectedSpans...)
+	h.RangeDescriptors = append(h.RangeDescriptors, o.RangeDescriptors...)
+	h.KeysRead += o.KeysRead
+	h.BytesRead += o.BytesRead
+	h.BytesWritten += o.BytesWritten
+	h.RaftCommands += o.RaftCommands
 	return nil
 }
 
@@ -441,6 +461,16 @@ func (sr *ReverseScanResponse) Verify(req Request) error {
 	return nil
 }
 
+// Verify verifies the integrity of every value returned in the locking scan.
+func (sr *ScanAndLockResponse) Verify(req Request) error {
+	for _, kv := range sr.Rows {
+		if err := kv.Value.Verify(kv.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Verify implements the Response interface.
 func (*NoopResponse) Verify(_ Request) error {
 	return nil
@@ -586,6 +616,9 @@ func (*RefreshRangeRequest) Method() Method { return RefreshRange }
 // Method implements the Request interface.
 func (*GetSnapshotForMergeRequest) Method() Method { return GetSnapshotForMerge }
 
+// Method implements the Request interface.
+func (*ScanAndLockRequest) Method() Method { return ScanAndLock }
+
 // ShallowCopy implements the Request interface.
 func (gr *GetRequest) ShallowCopy() Request {
 	shallowCopy := *gr
@@ -826,6 +859,12 @@ func (r *GetSnapshotForMergeRequest) ShallowCopy() Request {
 	return &shallowCopy
 }
 
+// ShallowCopy implements the Request interface.
+func (r *ScanAndLockRequest) ShallowCopy() Request {
+	shallowCopy := *r
+	return &shallowCopy
+}
+
 // NewGet returns a Request initialized to get the value at key.
 func NewGet(key Key) Request {
 	return &GetRequest{
@@ -938,6 +977,17 @@ func NewScan(key, endKey Key) Request {
 	}
 }
 
+// NewScanAndLock returns a Request initialized to scan from start to end keys
+// and lock every key returned on behalf of the transaction.
+func NewScanAndLock(key, endKey Key) Request {
+	return &ScanAndLockRequest{
+		RequestHeader: RequestHeader{
+			Key:    key,
+			EndKey: endKey,
+		},
+	}
+}
+
 // NewCheckConsistency returns a Request initialized to scan from start to end keys.
 func NewCheckConsistency(key, endKey Key, withDiff bool) Request {
 	return &CheckConsistencyRequest{
@@ -1089,6 +1139,14 @@ func (*RefreshRangeRequest) flags() int { return isRead | isTxn | isRange | upda
 
 func (*GetSnapshotForMergeRequest) flags() int { return isRead | updatesReadTSCache }
 
+// ScanAndLock locks the keys it returns by laying down intents which carry
+// their existing values, so it is a transactional write to the whole span. It
+// also updates the read timestamp cache like a Scan, which prevents keys that
+// did not exist when it was evaluated from being written beneath it.
+func (*ScanAndLockRequest) flags() int {
+	return isWrite | isTxn | isTxnWrite | isRange | consultsTSCache | updatesReadTSCache | needsRefresh
+}
+
 // Keys returns credentials in an aws.Config.
 func (b *ExportStorage_S3) Keys() *aws.Config {
 	return &aws.Config{
@@ -45,3 +45,30 @@ func AddNumericStats(a, b NumericStat, countA, countB int64) NumericStat {
 			delta*delta*float64(countA)*float64(countB)/total,
 	}
 }
+
+// Add combines other into this StatementStatistics, as if this had recorded
+// the executions recorded by other too. The last error of other, if any, wins.
+func (s *StatementStatistics) Add(other *StatementStatistics) {
+	if other.Count == 0 {
+		return
+	}
+	s.FirstAttemptCount += other.FirstAttemptCount
+	if other.MaxRetries > s.MaxRetries {
+		s.MaxRetries = other.MaxRetries
+	}
+	if other.LastErr != "" {
+		s.LastErr = other.LastErr
+		s.LastErrRedacted = other.LastErrRedacted
+	}
+	s.NumRows.Add(other.NumRows, s.Count, other.Count)
+	s.ParseLat.Add(other.ParseLat, s.Count, other.Count)
+	s.PlanLat.Add(other.PlanLat, s.Count, other.Count)
+	s.RunLat.Add(other.RunLat, s.Count, other.Count)
+	s.ServiceLat.Add(other.ServiceLat, s.Count, other.Count)
+	s.OverheadLat.Add(other.OverheadLat, s.Count, other.Count)
+	s.KVRequests.Add(other.KVRequests, s.Count, other.Count)
+	s.KVBytesRead.Add(other.KVBytesRead, s.Count, other.Count)
+	s.KVBytesWritten.Add(other.KVBytesWritten, s.Count, other.Count)
+	s.KVLat.Add(other.KVLat, s.Count, other.Count)
+	s.Count += other.Count
+}
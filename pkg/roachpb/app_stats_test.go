@@ -85,3 +85,44 @@ func TestAddNumericStats(t *testing.T) {
 		t.Fatalf("a.Add(b) should match add(a, b): %+v vs %+v", a, combined)
 	}
 }
+
+func TestAddStatementStatistics(t *testing.T) {
+	var a, b, ab StatementStatistics
+	record := func(s *StatementStatistics, rows float64, retries int64) {
+		s.Count++
+		if retries == 0 {
+			s.FirstAttemptCount++
+		} else if retries > s.MaxRetries {
+			s.MaxRetries = retries
+		}
+		s.NumRows.Record(s.Count, rows)
+		s.ServiceLat.Record(s.Count, rows/10)
+	}
+	record(&a, 1, 0)
+	record(&a, 4, 2)
+	record(&b, 2, 0)
+	record(&b, 8, 0)
+	record(&b, 3, 1)
+	for _, r := range []struct {
+		rows    float64
+		retries int64
+	}{{1, 0}, {4, 2}, {2, 0}, {8, 0}, {3, 1}} {
+		record(&ab, r.rows, r.retries)
+	}
+
+	// Adding empty statistics is a no-op.
+	a.Add(&StatementStatistics{})
+	a.Add(&b)
+	if a.Count != ab.Count || a.FirstAttemptCount != ab.FirstAttemptCount ||
+		a.MaxRetries != ab.MaxRetries {
+		t.Fatalf("expected %+v, got %+v", ab, a)
+	}
+	const epsilon = 0.0000001
+	if e := math.Abs(a.NumRows.Mean - ab.NumRows.Mean); e > epsilon {
+		t.Fatalf("mean rows of combined %f does not match ab %f", a.NumRows.Mean, ab.NumRows.Mean)
+	}
+	if e := math.Abs(a.ServiceLat.SquaredDiffs - ab.ServiceLat.SquaredDiffs); e > epsilon {
+		t.Fatalf("service latency of combined %f does not match ab %f",
+			a.ServiceLat.SquaredDiffs, ab.ServiceLat.SquaredDiffs)
+	}
+}
@@ -163,6 +163,33 @@ func (ba *BatchRequest) IsSingleEndTransactionRequest() bool {
 	return false
 }
 
+// IsSingleBatchedIncrementRequest returns true iff the batch is not
+// transactional and contains a single IncrementRequest which allows being
+// combined with concurrent increments of the same key.
+func (ba *BatchRequest) IsSingleBatchedIncrementRequest() bool {
+	if ba.Txn == nil && ba.IsSingleRequest() {
+		inc, ok := ba.Requests[0].GetInner().(*IncrementRequest)
+		return ok && inc.Batched
+	}
+	return false
+}
+
+// RequiresReplayDetection returns true iff the batch is a write which, when
+// retried after an ambiguous RPC failure, has to be checked for replays by the
+// leaseholder. This is the case of non-transactional writes and of batches
+// containing an EndTransactionRequest. Replays of other transactional writes
+// are caught through the transaction's sequence numbers.
+func (ba *BatchRequest) RequiresReplayDetection() bool {
+	if !ba.IsWrite() {
+		return false
+	}
+	if ba.Txn == nil {
+		return true
+	}
+	_, hasET := ba.GetArg(EndTransaction)
+	return hasET
+}
+
 // GetPrevLeaseForLeaseRequest returns the previous lease, at the time
 // of proposal, for a request lease or transfer lease request. If the
 // batch does not contain a single lease request, this method will panic.
@@ -92,6 +92,8 @@ func (ru RequestUnion) GetInner() Request {
 		return t.RefreshRange
 	case *RequestUnion_GetSnapshotForMerge:
 		return t.GetSnapshotForMerge
+	case *RequestUnion_ScanAndLock:
+		return t.ScanAndLock
 	default:
 		return nil
 	}
@@ -178,6 +180,8 @@ func (ru ResponseUnion) GetInner() Response {
 		return t.RefreshRange
 	case *ResponseUnion_GetSnapshotForMerge:
 		return t.GetSnapshotForMerge
+	case *ResponseUnion_ScanAndLock:
+		return t.ScanAndLock
 	default:
 		return nil
 	}
@@ -267,6 +271,8 @@ func (ru *RequestUnion) SetInner(r Request) bool {
 		union = &RequestUnion_RefreshRange{t}
 	case *GetSnapshotForMergeRequest:
 		union = &RequestUnion_GetSnapshotForMerge{t}
+	case *ScanAndLockRequest:
+		union = &RequestUnion_ScanAndLock{t}
 	default:
 		return false
 	}
@@ -356,6 +362,8 @@ func (ru *ResponseUnion) SetInner(r Response) bool {
 		union = &ResponseUnion_RefreshRange{t}
 	case *GetSnapshotForMergeResponse:
 		union = &ResponseUnion_GetSnapshotForMerge{t}
+	case *ScanAndLockResponse:
+		union = &ResponseUnion_ScanAndLock{t}
 	default:
 		return false
 	}
@@ -363,7 +371,7 @@ func (ru *ResponseUnion) SetInner(r Response) bool {
 	return true
 }
 
-type reqCounts [40]int32
+type reqCounts [41]int32
 
 // getReqCounts returns the number of times each
 // request type appears in the batch.
@@ -451,6 +459,8 @@ func (ba *BatchRequest) getReqCounts() reqCounts {
 			counts[38]++
 		case *RequestUnion_GetSnapshotForMerge:
 			counts[39]++
+		case *RequestUnion_ScanAndLock:
+			counts[40]++
 		default:
 			panic(fmt.Sprintf("unsupported request: %+v", ru))
 		}
@@ -499,6 +509,7 @@ var requestNames = []string{
 	"Refresh",
 	"RefreshRng",
 	"GetSnapshotForMerge",
+	"ScanAndLock",
 }
 
 // Summary prints a short summary of the requests in a batch.
@@ -682,6 +693,10 @@ type getSnapshotForMergeResponseAlloc struct {
 	union ResponseUnion_GetSnapshotForMerge
 	resp  GetSnapshotForMergeResponse
 }
+type scanAndLockResponseAlloc struct {
+	union ResponseUnion_ScanAndLock
+	resp  ScanAndLockResponse
+}
 
 // CreateReply creates replies for each of the contained requests, wrapped in a
 // BatchResponse. The response objects are batch allocated to minimize
@@ -732,6 +747,7 @@ func (ba *BatchRequest) CreateReply() *BatchResponse {
 	var buf37 []refreshResponseAlloc
 	var buf38 []refreshRangeResponseAlloc
 	var buf39 []getSnapshotForMergeResponseAlloc
+	var buf40 []scanAndLockResponseAlloc
 
 	for i, r := range ba.Requests {
 		switch r.GetValue().(type) {
@@ -1015,6 +1031,13 @@ func (ba *BatchRequest) CreateReply() *BatchResponse {
 			buf39[0].union.GetSnapshotForMerge = &buf39[0].resp
 			br.Responses[i].Value = &buf39[0].union
 			buf39 = buf39[1:]
+		case *RequestUnion_ScanAndLock:
+			if buf40 == nil {
+				buf40 = make([]scanAndLockResponseAlloc, counts[40])
+			}
+			buf40[0].union.ScanAndLock = &buf40[0].resp
+			br.Responses[i].Value = &buf40[0].union
+			buf40 = buf40[1:]
 		default:
 			panic(fmt.Sprintf("unsupported request: %+v", r))
 		}
@@ -333,6 +333,10 @@ func TestBatchResponseCombine(t *testing.T) {
 			}},
 		})
 		return &BatchResponse{
+			BatchResponse_Header: BatchResponse_Header{
+				KeysRead:  1,
+				BytesRead: 3,
+			},
 			Responses: []ResponseUnion{union},
 		}
 	}
@@ -368,6 +372,10 @@ func TestBatchResponseCombine(t *testing.T) {
 	if len(scan.IntentRows) != expRows {
 		t.Fatalf("expected %d intent rows, got %s", expRows, pretty.Sprint(scan))
 	}
+	if br.KeysRead != 3 || br.BytesRead != 9 {
+		t.Fatalf("expected the read stats of the batches to be summed, got %d keys and %d bytes",
+			br.KeysRead, br.BytesRead)
+	}
 	if err := br.Combine(singleScanBR(), []int{0}); err.Error() !=
 		`can not combine *roachpb.PutResponse and *roachpb.ScanResponse` {
 		t.Fatal(err)
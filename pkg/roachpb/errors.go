@@ -706,3 +706,25 @@ func (e *IntentMissingError) message(_ *Error) string {
 }
 
 var _ ErrorDetailInterface = &IntentMissingError{}
+
+// NewEvalMemoryBudgetExceededError creates a new
+// EvalMemoryBudgetExceededError.
+func NewEvalMemoryBudgetExceededError(
+	requestedBytes, allocatedBytes int64,
+) *EvalMemoryBudgetExceededError {
+	return &EvalMemoryBudgetExceededError{
+		RequestedBytes: requestedBytes,
+		AllocatedBytes: allocatedBytes,
+	}
+}
+
+func (e *EvalMemoryBudgetExceededError) Error() string {
+	return e.message(nil)
+}
+
+func (e *EvalMemoryBudgetExceededError) message(_ *Error) string {
+	return fmt.Sprintf("command evaluation memory budget exceeded: %d bytes requested, "+
+		"%d bytes already allocated on store", e.RequestedBytes, e.AllocatedBytes)
+}
+
+var _ ErrorDetailInterface = &EvalMemoryBudgetExceededError{}
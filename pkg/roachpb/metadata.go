@@ -132,6 +132,40 @@ func (r RangeDescriptor) GetReplicaDescriptorByID(replicaID ReplicaID) (ReplicaD
 	return ReplicaDescriptor{}, false
 }
 
+// Voters returns the replicas of the range which vote in Raft elections. The
+// returned slice may alias r.Replicas and must not be modified.
+func (r RangeDescriptor) Voters() []ReplicaDescriptor {
+	return r.filterReplicas(ReplicaType_VOTER)
+}
+
+// Learners returns the non-voting replicas of the range.
+func (r RangeDescriptor) Learners() []ReplicaDescriptor {
+	return r.filterReplicas(ReplicaType_LEARNER)
+}
+
+func (r RangeDescriptor) filterReplicas(typ ReplicaType) []ReplicaDescriptor {
+	// Fast path for the common case of a range without learners.
+	match := 0
+	for _, repDesc := range r.Replicas {
+		if repDesc.GetType() == typ {
+			match++
+		}
+	}
+	if match == len(r.Replicas) {
+		return r.Replicas
+	}
+	if match == 0 {
+		return nil
+	}
+	replicas := make([]ReplicaDescriptor, 0, match)
+	for _, repDesc := range r.Replicas {
+		if repDesc.GetType() == typ {
+			replicas = append(replicas, repDesc)
+		}
+	}
+	return replicas
+}
+
 // IsInitialized returns false if this descriptor represents an
 // uninitialized range.
 // TODO(bdarnell): unify this with Validate().
@@ -199,9 +233,21 @@ func (r ReplicaDescriptor) String() string {
 	} else {
 		fmt.Fprintf(&buf, "%d", r.ReplicaID)
 	}
+	if r.GetType() == ReplicaType_LEARNER {
+		buf.WriteString("LEARNER")
+	}
 	return buf.String()
 }
 
+// GetType returns the type of the replica. Replicas without an explicit type
+// are voters.
+func (r ReplicaDescriptor) GetType() ReplicaType {
+	if r.Type == nil {
+		return ReplicaType_VOTER
+	}
+	return *r.Type
+}
+
 // Validate performs some basic validation of the contents of a replica descriptor.
 func (r ReplicaDescriptor) Validate() error {
 	if r.NodeID == 0 {
@@ -255,12 +301,12 @@ func (p Percentiles) String() string {
 // String returns a string representation of the StoreCapacity.
 func (sc StoreCapacity) String() string {
 	return fmt.Sprintf("disk (capacity=%s, available=%s, used=%s, logicalBytes=%s), "+
-		"ranges=%d, leases=%d, writes=%.2f, "+
+		"ranges=%d, leases=%d, queries=%.2f, writes=%.2f, readAmp=%d, "+
 		"bytesPerReplica={%s}, writesPerReplica={%s}",
 		humanizeutil.IBytes(sc.Capacity), humanizeutil.IBytes(sc.Available),
 		humanizeutil.IBytes(sc.Used), humanizeutil.IBytes(sc.LogicalBytes),
-		sc.RangeCount, sc.LeaseCount, sc.WritesPerSecond,
-		sc.BytesPerReplica, sc.WritesPerReplica)
+		sc.RangeCount, sc.LeaseCount, sc.QueriesPerSecond, sc.WritesPerSecond,
+		sc.ReadAmplification, sc.BytesPerReplica, sc.WritesPerReplica)
 }
 
 // FractionUsed computes the fraction of storage capacity that is in use.
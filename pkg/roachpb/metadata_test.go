@@ -92,6 +92,39 @@ func TestRangeDescriptorMissingReplica(t *testing.T) {
 	}
 }
 
+func TestRangeDescriptorVotersAndLearners(t *testing.T) {
+	voter1 := ReplicaDescriptor{NodeID: 1, StoreID: 1, ReplicaID: 1}
+	voter2 := ReplicaDescriptor{NodeID: 2, StoreID: 2, ReplicaID: 2}
+	learner := ReplicaDescriptor{NodeID: 3, StoreID: 3, ReplicaID: 3, Type: ReplicaType_LEARNER.Enum()}
+
+	testCases := []struct {
+		replicas []ReplicaDescriptor
+		voters   []ReplicaDescriptor
+		learners []ReplicaDescriptor
+	}{
+		{nil, nil, nil},
+		{[]ReplicaDescriptor{voter1, voter2}, []ReplicaDescriptor{voter1, voter2}, nil},
+		{[]ReplicaDescriptor{learner}, nil, []ReplicaDescriptor{learner}},
+		{[]ReplicaDescriptor{voter1, learner, voter2}, []ReplicaDescriptor{voter1, voter2}, []ReplicaDescriptor{learner}},
+	}
+	for i, c := range testCases {
+		desc := RangeDescriptor{Replicas: c.replicas}
+		if voters := desc.Voters(); !reflect.DeepEqual(c.voters, voters) && len(c.voters)+len(voters) > 0 {
+			t.Errorf("%d: expected voters %v, got %v", i, c.voters, voters)
+		}
+		if learners := desc.Learners(); !reflect.DeepEqual(c.learners, learners) && len(c.learners)+len(learners) > 0 {
+			t.Errorf("%d: expected learners %v, got %v", i, c.learners, learners)
+		}
+	}
+
+	if e, a := "(n3,s3):3LEARNER", learner.String(); e != a {
+		t.Errorf("expected %s, got %s", e, a)
+	}
+	if typ := voter1.GetType(); typ != ReplicaType_VOTER {
+		t.Errorf("expected replica without a type to be a voter, got %s", typ)
+	}
+}
+
 // TestLocalityConversions verifies that setting the value from the CLI short
 // hand format works correctly.
 func TestLocalityConversions(t *testing.T) {
@@ -148,4 +148,7 @@ const (
 	// GetSnapshotForMerge notifies a range that its left-hand neighbor has
 	// initiated a merge and needs a snapshot of its data.
 	GetSnapshotForMerge
+	// ScanAndLock fetches the values for a key range and acquires an
+	// exclusive lock on each returned key on behalf of the transaction.
+	ScanAndLock
 )
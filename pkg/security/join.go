@@ -0,0 +1,319 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package security
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"math"
+	"net"
+	"os"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/pkg/errors"
+)
+
+// Join tokens allow a node (or client) without any certificates to obtain
+// certificates signed by the cluster's CA from a node which has access to the
+// CA key, without the operator having to copy them around by hand.
+//
+// A join token is created by an operator with access to the CA key. It
+// contains a random ID, an expiration time, the principal a certificate may be
+// issued for, the fingerprint of the CA certificate and a MAC over these,
+// keyed by a secret derived from the CA key. The fingerprint lets the joining
+// party authenticate the node it's joining through, and the MAC lets that node
+// authenticate the joining party and trust the principal. The node issuing
+// certificates doesn't need to know about a token before it's used.
+//
+// The encoded token is:
+//   version (1 byte) | ID (16 bytes) | expiration (8 bytes, unix seconds) |
+//   principal length (1 byte) | principal | CA fingerprint (32 bytes) |
+//   MAC (32 bytes)
+// in unpadded URL-safe base64.
+
+const (
+	joinTokenVersion = 2
+	joinTokenIDLen   = 16
+	// joinTokenMinLen is the length of a token with an empty principal.
+	joinTokenMinLen   = 1 + joinTokenIDLen + 8 + 1 + sha256.Size + sha256.Size
+	joinTokenMACLabel = "cockroach join token"
+)
+
+// JoinToken is a decoded join token.
+type JoinToken struct {
+	ID         [joinTokenIDLen]byte
+	Expiration time.Time
+	// Principal is the only user a certificate may be issued for with this
+	// token: NodeUser for a node certificate, or the name of a SQL user for a
+	// client certificate.
+	Principal     string
+	CAFingerprint []byte
+	mac           []byte
+}
+
+// CAFingerprint returns the SHA-256 fingerprint of a DER-encoded certificate.
+func CAFingerprint(der []byte) []byte {
+	sum := sha256.Sum256(der)
+	return sum[:]
+}
+
+// joinTokenMACKey derives the key used to authenticate join tokens from the CA
+// private key.
+func joinTokenMACKey(caPrivateKey crypto.PrivateKey) ([]byte, error) {
+	block, err := PrivateKeyToPEM(caPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	h.Write([]byte(joinTokenMACLabel))
+	h.Write(block.Bytes)
+	return h.Sum(nil), nil
+}
+
+func (t *JoinToken) body() []byte {
+	buf := make([]byte, 0, joinTokenMinLen+len(t.Principal))
+	buf = append(buf, joinTokenVersion)
+	buf = append(buf, t.ID[:]...)
+	var expiration [8]byte
+	binary.BigEndian.PutUint64(expiration[:], uint64(t.Expiration.Unix()))
+	buf = append(buf, expiration[:]...)
+	buf = append(buf, byte(len(t.Principal)))
+	buf = append(buf, t.Principal...)
+	return append(buf, t.CAFingerprint...)
+}
+
+func (t *JoinToken) computeMAC(caPrivateKey crypto.PrivateKey) ([]byte, error) {
+	key, err := joinTokenMACKey(caPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(t.body())
+	return mac.Sum(nil), nil
+}
+
+// GenerateJoinToken returns a new encoded join token for the CA with the
+// provided certificate and key, valid for the provided lifetime, with which a
+// certificate can be obtained for the provided principal only.
+func GenerateJoinToken(
+	caCert *x509.Certificate,
+	caPrivateKey crypto.PrivateKey,
+	principal string,
+	lifetime time.Duration,
+) (string, error) {
+	if len(principal) == 0 {
+		return "", errors.New("a principal is required")
+	}
+	if len(principal) > math.MaxUint8 {
+		return "", errors.Errorf("principal %q is too long", principal)
+	}
+	t := JoinToken{
+		Expiration:    timeutil.Now().Add(lifetime),
+		Principal:     principal,
+		CAFingerprint: CAFingerprint(caCert.Raw),
+	}
+	if _, err := rand.Read(t.ID[:]); err != nil {
+		return "", err
+	}
+	var err error
+	if t.mac, err = t.computeMAC(caPrivateKey); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(append(t.body(), t.mac...)), nil
+}
+
+// ParseJoinToken decodes a join token. The token is not authenticated; see
+// Verify.
+func ParseJoinToken(s string) (JoinToken, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return JoinToken{}, errors.Wrap(err, "malformed join token")
+	}
+	if len(buf) < joinTokenMinLen || buf[0] != joinTokenVersion {
+		return JoinToken{}, errors.New("malformed join token")
+	}
+	var t JoinToken
+	buf = buf[1:]
+	copy(t.ID[:], buf)
+	buf = buf[joinTokenIDLen:]
+	t.Expiration = timeutil.Unix(int64(binary.BigEndian.Uint64(buf)), 0)
+	buf = buf[8:]
+	principalLen := int(buf[0])
+	buf = buf[1:]
+	if len(buf) != principalLen+2*sha256.Size {
+		return JoinToken{}, errors.New("malformed join token")
+	}
+	t.Principal = string(buf[:principalLen])
+	buf = buf[principalLen:]
+	t.CAFingerprint = buf[:sha256.Size]
+	t.mac = buf[sha256.Size:]
+	return t, nil
+}
+
+// Verify checks that the token was issued for the CA with the provided
+// certificate and key and that it hasn't expired.
+func (t *JoinToken) Verify(caCert *x509.Certificate, caPrivateKey crypto.PrivateKey) error {
+	if !bytes.Equal(t.CAFingerprint, CAFingerprint(caCert.Raw)) {
+		return errors.New("join token was issued for a different CA")
+	}
+	mac, err := t.computeMAC(caPrivateKey)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal(mac, t.mac) {
+		return errors.New("invalid join token")
+	}
+	if timeutil.Now().After(t.Expiration) {
+		return errors.Errorf("join token expired at %s", t.Expiration)
+	}
+	return nil
+}
+
+// CreateJoinToken loads the CA certificate from the certs directory and the CA
+// key and returns a new join token for them, valid for the provided lifetime
+// and for the provided principal only.
+func CreateJoinToken(
+	certsDir, caKeyPath, principal string, lifetime time.Duration,
+) (string, error) {
+	if len(caKeyPath) == 0 {
+		return "", errors.New("the path to the CA key is required")
+	}
+	if len(certsDir) == 0 {
+		return "", errors.New("the path to the certs directory is required")
+	}
+
+	caCert, caPrivateKey, err := LoadCACertAndKey(certsDir, caKeyPath)
+	if err != nil {
+		return "", err
+	}
+	return GenerateJoinToken(caCert, caPrivateKey, principal, lifetime)
+}
+
+// LoadCACertAndKey loads the CA certificate from the certs directory and the
+// CA key. If multiple certificates exist in the CA cert, the first one is
+// returned.
+func LoadCACertAndKey(certsDir, caKeyPath string) (*x509.Certificate, crypto.PrivateKey, error) {
+	cm, err := NewCertificateManager(certsDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The certificate manager expands the env for the certs directory.
+	// For consistency, we need to do this for the key as well.
+	return loadCACertAndKey(cm.CACertPath(), os.ExpandEnv(caKeyPath))
+}
+
+// SignCertificateRequest issues a certificate for a certificate request
+// received from a party joining the cluster with a join token. Requests for
+// the node user get a node certificate for the hosts and IP addresses in the
+// request, other requests get a client certificate for the user named by the
+// request's common name. The certificate's lifetime is capped to that of the
+// CA certificate. It returns the DER-encoded certificate.
+func SignCertificateRequest(
+	caCert *x509.Certificate,
+	caPrivateKey crypto.PrivateKey,
+	csr *x509.CertificateRequest,
+	lifetime time.Duration,
+) ([]byte, error) {
+	if err := csr.CheckSignature(); err != nil {
+		return nil, errors.Wrap(err, "invalid certificate request signature")
+	}
+	// Leave some slack so that the certificate doesn't end up outlasting the
+	// CA cert by the time it's generated.
+	if caLifetime := caCert.NotAfter.Sub(timeutil.Now()) - time.Minute; caLifetime < lifetime {
+		lifetime = caLifetime
+	}
+	if lifetime <= 0 {
+		return nil, errors.Errorf("CA certificate expires at %s", caCert.NotAfter)
+	}
+
+	user := csr.Subject.CommonName
+	if user != NodeUser {
+		return GenerateClientCert(caCert, caPrivateKey, csr.PublicKey, lifetime, user)
+	}
+	hosts := append([]string(nil), csr.DNSNames...)
+	for _, ip := range csr.IPAddresses {
+		hosts = append(hosts, ip.String())
+	}
+	if len(hosts) == 0 {
+		return nil, errors.New("node certificate requests require at least one host")
+	}
+	return GenerateServerCert(caCert, caPrivateKey, csr.PublicKey, lifetime, hosts)
+}
+
+// CreateCertificateRequest returns a PEM-encoded certificate request for the
+// provided user, signed with the provided key. Hosts are only used for the
+// node user.
+func CreateCertificateRequest(key crypto.Signer, user string, hosts []string) ([]byte, error) {
+	template := &x509.CertificateRequest{}
+	template.Subject.CommonName = user
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, h)
+		}
+	}
+	der, err := x509.CreateCertificateRequest(rand.Reader, template, key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}), nil
+}
+
+// WriteJoinedCertificates writes the CA certificates, and the certificate and
+// key obtained for the provided user by joining the cluster, to the certs
+// directory, which is created if it does not exist.
+func WriteJoinedCertificates(
+	certsDir string, caPEM []byte, cert []byte, key crypto.PrivateKey, user string, overwrite bool,
+) error {
+	cm, err := NewCertificateManagerFirstRun(certsDir)
+	if err != nil {
+		return err
+	}
+
+	caBlocks, err := PEMToCertificates(caPEM)
+	if err != nil {
+		return errors.Wrap(err, "could not parse CA certificates")
+	}
+	caPath := cm.CACertPath()
+	if err := WritePEMToFile(caPath, certFileMode, overwrite, caBlocks...); err != nil {
+		return errors.Errorf("error writing CA certificate to %s: %v", caPath, err)
+	}
+	log.Infof(context.Background(), "Wrote %d certificates to %s", len(caBlocks), caPath)
+
+	certPath, keyPath := cm.ClientCertPath(user), cm.ClientKeyPath(user)
+	if user == NodeUser {
+		certPath, keyPath = cm.NodeCertPath(), cm.NodeKeyPath()
+	}
+	if err := writeCertificateToFile(certPath, cert, overwrite); err != nil {
+		return errors.Errorf("error writing certificate to %s: %v", certPath, err)
+	}
+	log.Infof(context.Background(), "Generated certificate: %s", certPath)
+	if err := writeKeyToFile(keyPath, key, overwrite); err != nil {
+		return errors.Errorf("error writing key to %s: %v", keyPath, err)
+	}
+	log.Infof(context.Background(), "Generated key: %s", keyPath)
+	return nil
+}
@@ -0,0 +1,175 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package security_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func generateTestCA(t *testing.T, lifetime time.Duration) (*x509.Certificate, *rsa.PrivateKey) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caBytes, err := security.GenerateCA(key, lifetime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, err := x509.ParseCertificate(caBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return caCert, key
+}
+
+func TestJoinToken(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	caCert, caKey := generateTestCA(t, time.Hour*48)
+	otherCACert, otherCAKey := generateTestCA(t, time.Hour*48)
+
+	token, err := security.GenerateJoinToken(caCert, caKey, "foo", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := security.ParseJoinToken(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parsed.Verify(caCert, caKey); err != nil {
+		t.Fatal(err)
+	}
+	if a, e := parsed.Principal, "foo"; a != e {
+		t.Fatalf("expected principal %s, got %s", e, a)
+	}
+
+	// The token can't be used with another CA.
+	if err := parsed.Verify(otherCACert, otherCAKey); !testutils.IsError(
+		err, "join token was issued for a different CA",
+	) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Nor can it be verified by someone with only the CA cert.
+	if err := parsed.Verify(caCert, otherCAKey); !testutils.IsError(err, "invalid join token") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Tampering with the token invalidates it.
+	tampered := parsed
+	tampered.Expiration = tampered.Expiration.Add(time.Hour)
+	if err := tampered.Verify(caCert, caKey); !testutils.IsError(err, "invalid join token") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tampered = parsed
+	tampered.Principal = security.RootUser
+	if err := tampered.Verify(caCert, caKey); !testutils.IsError(err, "invalid join token") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Tokens must name a principal.
+	if _, err := security.GenerateJoinToken(caCert, caKey, "", time.Hour); !testutils.IsError(
+		err, "a principal is required",
+	) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Expired tokens are rejected.
+	token, err = security.GenerateJoinToken(caCert, caKey, security.NodeUser, -time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed, err = security.ParseJoinToken(token); err != nil {
+		t.Fatal(err)
+	}
+	if err := parsed.Verify(caCert, caKey); !testutils.IsError(err, "join token expired") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Malformed tokens are rejected.
+	for _, s := range []string{"", "not a token", token[:len(token)-2]} {
+		if _, err := security.ParseJoinToken(s); !testutils.IsError(err, "malformed join token") {
+			t.Fatalf("%q: unexpected error: %v", s, err)
+		}
+	}
+}
+
+func TestSignCertificateRequest(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	caCert, caKey := generateTestCA(t, time.Hour*48)
+	key, err := rsa.GenerateKey(rand.Reader, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sign := func(user string, hosts []string) (*x509.Certificate, error) {
+		csrPEM, err := security.CreateCertificateRequest(key, user, hosts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block, _ := pem.Decode(csrPEM)
+		csr, err := x509.ParseCertificateRequest(block.Bytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The requested lifetime is capped to the CA's.
+		der, err := security.SignCertificateRequest(caCert, caKey, csr, time.Hour*96)
+		if err != nil {
+			return nil, err
+		}
+		return x509.ParseCertificate(der)
+	}
+
+	nodeCert, err := sign(security.NodeUser, []string{"localhost", "127.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nodeCert.NotAfter.After(caCert.NotAfter) {
+		t.Fatalf("certificate outlasts its CA: %s vs %s", nodeCert.NotAfter, caCert.NotAfter)
+	}
+	if err := nodeCert.VerifyHostname("127.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := nodeCert.CheckSignatureFrom(caCert); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sign(security.NodeUser, nil); !testutils.IsError(
+		err, "node certificate requests require at least one host",
+	) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	clientCert, err := sign("foo", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, e := clientCert.Subject.CommonName, "foo"; a != e {
+		t.Fatalf("expected common name %s, got %s", e, a)
+	}
+	if a, e := clientCert.ExtKeyUsage, []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}; len(a) != 1 || a[0] != e[0] {
+		t.Fatalf("expected key usage %v, got %v", e, a)
+	}
+}
@@ -532,9 +532,26 @@ func (cfg *Config) CreateEngines(ctx context.Context) (Engines, error) {
 				UseFileRegistry:         spec.UseFileRegistry,
 				RocksDBOptions:          spec.RocksDBOptions,
 				ExtraOptions:            spec.ExtraOptions,
+				PersistentCachePath:     spec.SecondaryCachePath,
+				PersistentCacheSize:     spec.SecondaryCacheSize,
+			}
+			if spec.SecondaryCachePath != "" {
+				details = append(details, fmt.Sprintf("store %d: secondary cache in %s, size %s",
+					i, spec.SecondaryCachePath, humanizeutil.IBytes(spec.SecondaryCacheSize)))
 			}
 
-			eng, err := engine.NewRocksDB(rocksDBConfig, cache)
+			storeCache := cache
+			if spec.BlockCacheSize > 0 {
+				// The store has its own block cache rather than sharing the node's.
+				details = append(details, fmt.Sprintf("store %d: dedicated RocksDB cache size %s",
+					i, humanizeutil.IBytes(spec.BlockCacheSize)))
+				storeCache = engine.NewRocksDBCacheWithShardBits(spec.BlockCacheSize, spec.BlockCacheShardBits)
+			}
+			eng, err := engine.NewRocksDB(rocksDBConfig, storeCache)
+			if spec.BlockCacheSize > 0 {
+				// The engine holds its own reference to the cache.
+				storeCache.Release()
+			}
 			if err != nil {
 				return Engines{}, err
 			}
@@ -0,0 +1,208 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/util/httputil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+const (
+	// JoinCAPath is the HTTP path from which the CA certificates of a node
+	// serving as the cluster's built-in CA can be fetched.
+	JoinCAPath = "/_join/v1/ca"
+	// JoinCertPath is the HTTP path at which a node serving as the cluster's
+	// built-in CA signs certificate requests accompanied by a join token.
+	JoinCertPath = "/_join/v1/cert"
+
+	// joinCertLifetime is the lifetime of the certificates issued by the
+	// built-in CA, unless the CA certificate expires earlier. It matches the
+	// default of `cockroach cert create-{node,client}`.
+	joinCertLifetime = 5 * 366 * 24 * time.Hour
+
+	// maxJoinRequestBytes bounds the size of certificate requests.
+	maxJoinRequestBytes = 64 << 10
+
+	// joinTokenRetention is how long used join tokens are remembered past
+	// their expiration.
+	joinTokenRetention = time.Hour
+)
+
+// JoinCertRequest is the body of a request to JoinCertPath.
+type JoinCertRequest struct {
+	// Token is an encoded join token; see security.GenerateJoinToken.
+	Token string `json:"token"`
+	// CSR is a PEM-encoded certificate request.
+	CSR []byte `json:"csr"`
+}
+
+// JoinCertResponse is the body of a response from JoinCertPath.
+type JoinCertResponse struct {
+	// Certificate is the PEM-encoded certificate issued for the request.
+	Certificate []byte `json:"certificate"`
+}
+
+// joinCA issues certificates to nodes and clients joining the cluster with a
+// join token. It's only enabled on nodes started with access to the CA key.
+//
+// A join token only allows obtaining a certificate for the principal it was
+// created for, and only once: joinCA records the tokens it accepts in
+// system.join_tokens, so that a token can't be used again through this or any
+// other node serving as a CA.
+type joinCA struct {
+	caPEM        []byte
+	caCert       *x509.Certificate
+	caPrivateKey crypto.PrivateKey
+	ie           *sql.InternalExecutor
+}
+
+// newJoinCA loads the CA certificate from the certs directory and the CA key.
+func newJoinCA(certsDir, caKeyPath string, ie *sql.InternalExecutor) (*joinCA, error) {
+	cm, err := security.NewCertificateManager(certsDir)
+	if err != nil {
+		return nil, err
+	}
+	caPEM, err := ioutil.ReadFile(cm.CACertPath())
+	if err != nil {
+		return nil, err
+	}
+	caCert, caPrivateKey, err := security.LoadCACertAndKey(certsDir, caKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	return &joinCA{
+		caPEM:        caPEM,
+		caCert:       caCert,
+		caPrivateKey: caPrivateKey,
+		ie:           ie,
+	}, nil
+}
+
+// handleCA serves the PEM-encoded CA certificates. These are public; joining
+// parties check them against the fingerprint in their join token.
+func (ca *joinCA) handleCA(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(httputil.ContentTypeHeader, httputil.PlaintextContentType)
+	if _, err := w.Write(ca.caPEM); err != nil {
+		log.Warning(r.Context(), err)
+	}
+}
+
+// handleCert signs the certificate request in a JoinCertRequest.
+func (ca *joinCA) handleCert(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req JoinCertRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxJoinRequestBytes)).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	cert, err := ca.signRequest(ctx, req)
+	if err != nil {
+		log.Warningf(ctx, "rejected certificate request from %s: %s", r.RemoteAddr, err)
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	w.Header().Set(httputil.ContentTypeHeader, httputil.JSONContentType)
+	if err := json.NewEncoder(w).Encode(JoinCertResponse{Certificate: cert}); err != nil {
+		log.Warning(ctx, err)
+	}
+}
+
+func (ca *joinCA) signRequest(ctx context.Context, req JoinCertRequest) ([]byte, error) {
+	token, err := security.ParseJoinToken(req.Token)
+	if err != nil {
+		return nil, err
+	}
+	if err := token.Verify(ca.caCert, ca.caPrivateKey); err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(req.CSR)
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return nil, errors.New("no PEM-encoded certificate request found")
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	// The token names the only user it can be used for. In particular, a
+	// certificate for root can only be obtained with a token created for root.
+	user := csr.Subject.CommonName
+	if user != token.Principal {
+		return nil, errors.Errorf("join token does not allow a certificate for user %q", user)
+	}
+	if user != security.NodeUser {
+		if normalized, err := sql.NormalizeAndValidateUsername(user); err != nil {
+			return nil, err
+		} else if normalized != user {
+			return nil, errors.Errorf("username %q is not normalized", user)
+		}
+	}
+
+	if err := ca.useToken(ctx, token); err != nil {
+		return nil, err
+	}
+	der, err := security.SignCertificateRequest(ca.caCert, ca.caPrivateKey, csr, joinCertLifetime)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof(ctx, "issued certificate for user %q with join token %x",
+		csr.Subject.CommonName, token.ID)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), nil
+}
+
+// useToken records the use of the given token in system.join_tokens,
+// returning an error if it has already been used.
+func (ca *joinCA) useToken(ctx context.Context, token security.JoinToken) error {
+	// Tokens which expired a while ago are rejected anyway; forget them. The
+	// margin keeps a node with a lagging clock from accepting a token again
+	// after another node removed it.
+	if _, err := ca.ie.Exec(
+		ctx, "delete-expired-join-tokens", nil, /* txn */
+		`DELETE FROM system.join_tokens WHERE expiration < now() - $1::INTERVAL`,
+		joinTokenRetention,
+	); err != nil {
+		return err
+	}
+	n, err := ca.ie.Exec(
+		ctx, "use-join-token", nil, /* txn */
+		`INSERT INTO system.join_tokens (id, principal, expiration) VALUES ($1, $2, $3)
+ON CONFLICT (id) DO NOTHING`,
+		token.ID[:], token.Principal, token.Expiration,
+	)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errors.New("join token has already been used")
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestJoinCASignRequest(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+	ts := s.(*TestServer)
+	ctx := context.TODO()
+
+	caKey, err := rsa.GenerateKey(rand.Reader, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caDER, err := security.GenerateCA(caKey, 48*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newCA := func() *joinCA {
+		return &joinCA{caCert: caCert, caPrivateKey: caKey, ie: ts.internalExecutor}
+	}
+	ca := newCA()
+
+	key, err := rsa.GenerateKey(rand.Reader, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := func(principal, user string) JoinCertRequest {
+		token, err := security.GenerateJoinToken(caCert, caKey, principal, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		csr, err := security.CreateCertificateRequest(key, user, []string{"localhost"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return JoinCertRequest{Token: token, CSR: csr}
+	}
+
+	// A token only allows a certificate for its principal.
+	for _, user := range []string{security.RootUser, "foo"} {
+		if _, err := ca.signRequest(ctx, request(security.NodeUser, user)); !testutils.IsError(
+			err, "join token does not allow a certificate for user",
+		) {
+			t.Fatalf("%s: unexpected error: %v", user, err)
+		}
+	}
+
+	// A token can only be used once, even through another CA.
+	req := request(security.NodeUser, security.NodeUser)
+	if _, err := ca.signRequest(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []*joinCA{ca, newCA()} {
+		if _, err := c.signRequest(ctx, req); !testutils.IsError(
+			err, "join token has already been used",
+		) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// A certificate for root requires a token created for root.
+	if _, err := ca.signRequest(ctx, request(security.RootUser, security.RootUser)); err != nil {
+		t.Fatal(err)
+	}
+}
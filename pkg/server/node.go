@@ -44,6 +44,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/grpcutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
 	"github.com/cockroachdb/cockroach/pkg/util/retry"
@@ -965,6 +966,18 @@ func (n *Node) batchInternal(
 	}
 
 	isLocalRequest := grpcutil.IsLocalRequestContext(ctx)
+	if !isLocalRequest {
+		// Local requests never go over the wire, so there's no point in
+		// paying for computing their size.
+		if max := kv.MaxBatchSize.Get(&n.storeCfg.Settings.SV); max > 0 {
+			if size := int64(args.Size()); size > max {
+				return nil, errors.Errorf(
+					"batch of %d requests has size %s, which exceeds the maximum of %s "+
+						"(kv.rpc.max_batch_size); split the operation into smaller batches",
+					len(args.Requests), humanizeutil.IBytes(size), humanizeutil.IBytes(max))
+			}
+		}
+	}
 	// TODO(marc): grpc's authentication model (which gives credential access in
 	// the request handler) doesn't really fit with the current design of the
 	// security package (which assumes that TLS state is only given at connection
@@ -1045,6 +1058,24 @@ func (n *Node) Batch(
 	return br, nil
 }
 
+// RangeFeed implements the roachpb.InternalServer interface.
+func (n *Node) RangeFeed(
+	args *roachpb.RangeFeedRequest, stream roachpb.Internal_RangeFeedServer,
+) error {
+	growStack()
+
+	ctx := n.storeCfg.AmbientCtx.AnnotateCtx(stream.Context())
+	pErr := n.stores.RangeFeed(ctx, args, stream)
+	if pErr != nil {
+		var event roachpb.RangeFeedEvent
+		event.SetValue(&roachpb.RangeFeedError{
+			Error: *pErr,
+		})
+		return stream.Send(&event)
+	}
+	return nil
+}
+
 // setupSpanForIncomingRPC takes a context and returns a derived context with a
 // new span in it. Depending on the input context, that span might be a root
 // span or a child span. If it is a child span, it might be a child span of a
@@ -789,3 +789,26 @@ func TestNodeSendUnknownBatchRequest(t *testing.T) {
 		t.Fatalf("expected unsupported request, not %v", br.Error)
 	}
 }
+
+// TestNodeRejectsOversizedBatch verifies that a node rejects a batch received
+// over the network which exceeds kv.rpc.max_batch_size.
+func TestNodeRejectsOversizedBatch(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+
+	kv.MaxBatchSize.Override(&s.ClusterSettings().SV, 1<<10)
+
+	conn, err := s.RPCContext().GRPCDial(s.ServingAddr()).Connect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ba roachpb.BatchRequest
+	ba.RangeID = 1
+	ba.Add(roachpb.NewPut(roachpb.Key("a"), roachpb.MakeValueFromBytes(make([]byte, 2<<10))))
+	_, err = roachpb.NewInternalClient(conn).Batch(ctx, &ba)
+	if !testutils.IsError(err, `batch of 1 requests has size .*, which exceeds the maximum of 1\.0 KiB`) {
+		t.Fatalf("expected batch to be rejected, got %v", err)
+	}
+}
@@ -68,6 +68,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sqlmigrations"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
 	"github.com/cockroachdb/cockroach/pkg/ts"
 	"github.com/cockroachdb/cockroach/pkg/ui"
 	"github.com/cockroachdb/cockroach/pkg/util"
@@ -153,6 +154,7 @@ type Server struct {
 	initServer         *initServer
 	tsDB               *ts.DB
 	tsServer           ts.Server
+	protectedTSCache   *protectedts.Cache
 	raftTransport      *storage.RaftTransport
 	stopper            *stop.Stopper
 	execCfg            *sql.ExecutorConfig
@@ -387,6 +389,8 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 	}
 	s.tsServer = ts.MakeServer(s.cfg.AmbientCtx, s.tsDB, nodeCountFn, s.cfg.TimeSeriesServerConfig, s.stopper)
 
+	s.protectedTSCache = protectedts.NewCache(s.db, s.cfg.Settings)
+
 	// The InternalExecutor will be further initialized later, as we create more
 	// of the server's components. There's a circular dependency - many things
 	// need an InternalExecutor, but the InternalExecutor needs an ExecutorConfig,
@@ -416,6 +420,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		SQLExecutor:             internalExecutor,
 		LogRangeEvents:          s.cfg.EventLogEnabled,
 		TimeSeriesDataStore:     s.tsDB,
+		ProtectedTimestampCache: s.protectedTSCache,
 
 		EnableEpochRangeLeases: true,
 	}
@@ -629,6 +634,10 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 	s.internalExecutor = internalExecutor
 	execCfg.InternalExecutor = internalExecutor
 
+	execCfg.StatsRefresher = stats.MakeRefresher(
+		s.st, internalExecutor, s.db, execCfg.TableStatsCache,
+	)
+
 	s.execCfg = &execCfg
 
 	s.leaseMgr.SetExecCfg(&execCfg)
@@ -1181,6 +1190,20 @@ func (s *Server) Start(ctx context.Context) error {
 	// endpoints.
 	s.mux.Handle(debug.Endpoint, debug.NewServer(s.st))
 
+	// Serve as the cluster's built-in CA if we have access to the CA key.
+	if s.cfg.SSLCAKey != "" {
+		if s.cfg.Insecure {
+			return errors.New("the built-in CA cannot be enabled in insecure mode")
+		}
+		ca, err := newJoinCA(s.cfg.SSLCertsDir, s.cfg.SSLCAKey, s.internalExecutor)
+		if err != nil {
+			return errors.Wrap(err, "could not enable the built-in CA")
+		}
+		s.mux.Handle(JoinCAPath, http.HandlerFunc(ca.handleCA))
+		s.mux.Handle(JoinCertPath, http.HandlerFunc(ca.handleCert))
+		log.Infof(ctx, "serving as the cluster's CA")
+	}
+
 	fileServer := http.FileServer(&assetfs.AssetFS{
 		Asset:     ui.Asset,
 		AssetDir:  ui.AssetDir,
@@ -1457,6 +1480,10 @@ If problems persist, please see ` + base.DocsURL("cluster-setup-troubleshooting.
 	// Begin recording status summaries.
 	s.node.startWriteNodeStatus(DefaultMetricsSampleInterval)
 
+	// Begin reading the protected timestamp records, which the GC queue
+	// waits for.
+	s.protectedTSCache.Start(ctx, s.stopper)
+
 	var graphiteOnce sync.Once
 	graphiteEndpoint.SetOnChange(&s.st.SV, func() {
 		if graphiteEndpoint.Get(&s.st.SV) != "" {
@@ -1555,6 +1582,9 @@ If problems persist, please see ` + base.DocsURL("cluster-setup-troubleshooting.
 		}
 	}
 
+	// Start the automatic refresh of table statistics.
+	s.execCfg.StatsRefresher.Start(ctx, s.stopper, stats.DefaultRefreshInterval)
+
 	// Before serving SQL requests, we have to make sure the database is
 	// in an acceptable form for this version of the software.
 	// We have to do this after actually starting up the server to be able to
@@ -28,6 +28,7 @@ import (
 	"regexp"
 	"runtime"
 	"runtime/pprof"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -57,6 +58,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/httputil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
@@ -1042,7 +1044,49 @@ func (s *statusServer) handleVars(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// localityRates converts a map of per-locality rates into a slice sorted by
+// locality, so that the output is deterministic.
+func localityRates(rates map[string]float64) []serverpb.RangeStatistics_LocalityRate {
+	if len(rates) == 0 {
+		return nil
+	}
+	res := make([]serverpb.RangeStatistics_LocalityRate, 0, len(rates))
+	for locality, rate := range rates {
+		res = append(res, serverpb.RangeStatistics_LocalityRate{
+			Locality:       locality,
+			BytesPerSecond: rate,
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Locality < res[j].Locality
+	})
+	return res
+}
+
 // Ranges returns range info for the specified node.
+// rangeGCInfo returns the GC progress of a replica, which is only known by
+// the leaseholder.
+func rangeGCInfo(
+	ctx context.Context, rep *storage.Replica, cfg config.SystemConfig,
+) serverpb.RangeGCInfo {
+	p, err := rep.GCProgress(ctx, rep.Clock().Now(), cfg)
+	if err != nil {
+		log.Warningf(ctx, "failed to compute the GC progress of r%d: %v", rep.RangeID, err)
+		return serverpb.RangeGCInfo{}
+	}
+	info := serverpb.RangeGCInfo{
+		Score:              p.Score,
+		ShouldQueue:        p.ShouldQueue,
+		BytesAwaitingGC:    p.BytesAwaitingGC,
+		TtlSeconds:         int32(p.TTL.Seconds()),
+		NanosUntilEligible: p.UntilEligible.Nanoseconds(),
+	}
+	if p.LastRun != (hlc.Timestamp{}) {
+		info.LastRun = p.LastRun.GoTime()
+	}
+	return info
+}
+
 func (s *statusServer) Ranges(
 	ctx context.Context, req *serverpb.RangesRequest,
 ) (*serverpb.RangesResponse, error) {
@@ -1097,6 +1141,16 @@ func (s *statusServer) Ranges(
 
 	includeRawKeys := debug.GatewayRemoteAllowed(ctx, s.st)
 
+	cfg, ok := s.gossip.GetSystemConfig()
+	if !ok {
+		// Very little on the status pages requires the system config -- as of June
+		// 2017, only the underreplicated range metric does. Refusing to return a
+		// status page (that may help debug why the config isn't available) due to
+		// such a small piece of missing information is overly harsh.
+		log.Error(ctx, "system config not yet available, serving status page without it")
+		cfg = config.SystemConfig{}
+	}
+
 	constructRangeInfo := func(
 		desc roachpb.RangeDescriptor, rep *storage.Replica, storeID roachpb.StoreID, metrics storage.ReplicaMetrics,
 	) serverpb.RangeInfo {
@@ -1111,11 +1165,24 @@ func (s *statusServer) Ranges(
 			span.StartKey = omittedKeyStr
 			span.EndKey = omittedKeyStr
 		}
+		loadSplitQPS, splitKey := rep.LoadSplitStats()
+		var loadSplitKey string
+		if splitKey != nil {
+			if includeRawKeys {
+				loadSplitKey = splitKey.String()
+			} else {
+				loadSplitKey = omittedKeyStr
+			}
+		}
 		state := rep.State()
 		if !includeRawKeys {
 			state.ReplicaState.Desc.StartKey = nil
 			state.ReplicaState.Desc.EndKey = nil
 		}
+		var gcInfo serverpb.RangeGCInfo
+		if metrics.Leaseholder {
+			gcInfo = rangeGCInfo(ctx, rep, cfg)
+		}
 		return serverpb.RangeInfo{
 			Span:          span,
 			RaftState:     raftState,
@@ -1124,8 +1191,11 @@ func (s *statusServer) Ranges(
 			SourceStoreID: storeID,
 			LeaseHistory:  leaseHistory,
 			Stats: serverpb.RangeStatistics{
-				QueriesPerSecond: rep.QueriesPerSecond(),
-				WritesPerSecond:  rep.WritesPerSecond(),
+				QueriesPerSecond:          rep.QueriesPerSecond(),
+				WritesPerSecond:           rep.WritesPerSecond(),
+				LoadSplitQueriesPerSecond: loadSplitQPS,
+				LoadSplitKey:              loadSplitKey,
+				EgressBytesPerSecond:      localityRates(rep.EgressBytesPerSecond()),
 			},
 			Problems: serverpb.RangeProblems{
 				Unavailable:            metrics.Unavailable,
@@ -1140,18 +1210,10 @@ func (s *statusServer) Ranges(
 			LeaseStatus: metrics.LeaseStatus,
 			Quiescent:   metrics.Quiescent,
 			Ticking:     metrics.Ticking,
+			GC:          gcInfo,
 		}
 	}
 
-	cfg, ok := s.gossip.GetSystemConfig()
-	if !ok {
-		// Very little on the status pages requires the system config -- as of June
-		// 2017, only the underreplicated range metric does. Refusing to return a
-		// status page (that may help debug why the config isn't available) due to
-		// such a small piece of missing information is overly harsh.
-		log.Error(ctx, "system config not yet available, serving status page without it")
-		cfg = config.SystemConfig{}
-	}
 	isLiveMap := s.nodeLiveness.GetIsLiveMap()
 
 	err = s.stores.VisitStores(func(store *storage.Store) error {
@@ -369,6 +369,17 @@ func anonymizeZoneConfig(dst *config.ZoneConfig, src config.ZoneConfig, secret s
 			}
 		}
 	}
+	dst.NumLearners = src.NumLearners
+	dst.LearnerConstraints = make([]config.Constraint, len(src.LearnerConstraints))
+	for i := range src.LearnerConstraints {
+		dst.LearnerConstraints[i].Type = src.LearnerConstraints[i].Type
+		if key := src.LearnerConstraints[i].Key; key != "" {
+			dst.LearnerConstraints[i].Key = sql.HashForReporting(secret, key)
+		}
+		if val := src.LearnerConstraints[i].Value; val != "" {
+			dst.LearnerConstraints[i].Value = sql.HashForReporting(secret, val)
+		}
+	}
 	dst.Subzones = make([]config.Subzone, len(src.Subzones))
 	for i := range src.Subzones {
 		dst.Subzones[i].IndexID = src.Subzones[i].IndexID
@@ -64,6 +64,14 @@ const (
 	VersionSecondaryLookupJoins
 	VersionClientSideWritingFlag
 	VersionColumnarTimeSeries
+	VersionReplayDetection
+	VersionParallelCommits
+	VersionAsyncConsensus
+	VersionLoadBasedRebalancing
+	VersionRaftCompression
+	VersionLearnerReplicas
+	VersionImportPgURL
+	VersionScanAndLock
 
 	// Add new versions here (step one of two).
 
@@ -241,6 +249,54 @@ var versionsSingleton = keyedVersions([]keyedVersion{
 		Key:     VersionColumnarTimeSeries,
 		Version: roachpb.Version{Major: 2, Minor: 0, Unstable: 7},
 	},
+	{
+		// VersionReplayDetection allows the DistSender to retry write batches after
+		// an ambiguous RPC failure, relying on the leaseholder to detect replays.
+		Key:     VersionReplayDetection,
+		Version: roachpb.Version{Major: 2, Minor: 0, Unstable: 8},
+	},
+	{
+		// VersionParallelCommits allows EndTransaction requests to move a
+		// transaction record to STAGING while its final writes are in flight.
+		Key:     VersionParallelCommits,
+		Version: roachpb.Version{Major: 2, Minor: 0, Unstable: 9},
+	},
+	{
+		// VersionAsyncConsensus allows transactional write batches to set
+		// AsyncConsensus and be acknowledged before they are applied.
+		Key:     VersionAsyncConsensus,
+		Version: roachpb.Version{Major: 2, Minor: 0, Unstable: 10},
+	},
+	{
+		// VersionLoadBasedRebalancing means that all stores gossip the queries per
+		// second served by their leaseholders, which load-based rebalancing relies on.
+		Key:     VersionLoadBasedRebalancing,
+		Version: roachpb.Version{Major: 2, Minor: 0, Unstable: 11},
+	},
+	{
+		// VersionRaftCompression means that all nodes understand compressed Raft
+		// entries and snapshot data.
+		Key:     VersionRaftCompression,
+		Version: roachpb.Version{Major: 2, Minor: 0, Unstable: 12},
+	},
+	{
+		// VersionLearnerReplicas means that all nodes understand range
+		// descriptors containing non-voting learner replicas.
+		Key:     VersionLearnerReplicas,
+		Version: roachpb.Version{Major: 2, Minor: 0, Unstable: 13},
+	},
+	{
+		// VersionImportPgURL means that all nodes can read IMPORT data directly
+		// from a postgres-compatible server.
+		Key:     VersionImportPgURL,
+		Version: roachpb.Version{Major: 2, Minor: 0, Unstable: 14},
+	},
+	{
+		// VersionScanAndLock means that all nodes can evaluate ScanAndLock
+		// requests.
+		Key:     VersionScanAndLock,
+		Version: roachpb.Version{Major: 2, Minor: 0, Unstable: 15},
+	},
 
 	// Add new versions here (step two of two).
 
@@ -20,6 +20,7 @@ import (
 
 	"github.com/gogo/protobuf/proto"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -80,6 +81,15 @@ func (n *alterIndexNode) startExec(params runParams) error {
 				return err
 			}
 			n.indexDesc.Partitioning = partitioning
+		case *tree.AlterIndexVisible:
+			if t.NotVisible && n.indexDesc.ID == n.tableDesc.PrimaryIndex.ID {
+				return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+					"primary index %q cannot be made not visible", n.indexDesc.Name)
+			}
+			if n.indexDesc.NotVisible != t.NotVisible {
+				n.indexDesc.NotVisible = t.NotVisible
+				descriptorChanged = true
+			}
 		default:
 			return fmt.Errorf("unsupported alter command: %T", cmd)
 		}
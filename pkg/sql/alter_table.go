@@ -102,6 +102,8 @@ func (n *alterTableNode) startExec(params runParams) error {
 	origNumMutations := len(n.tableDesc.Mutations)
 	var droppedViews []string
 	tn := n.n.Table.TableName()
+	// ownedSeqIDs maps new SERIAL columns to the sequences created for them.
+	ownedSeqIDs := make(map[tree.Name]sqlbase.ID)
 
 	for i, cmd := range n.n.Cmds {
 		switch t := cmd.(type) {
@@ -115,6 +117,21 @@ func (n *alterTableNode) startExec(params runParams) error {
 				return pgerror.Unimplemented(
 					"alter add fk", "adding a REFERENCES constraint via ALTER not supported")
 			}
+			if _, _, err := n.tableDesc.FindColumnByName(d.Name); err != nil && isSerialColumnDef(d) {
+				// A new SERIAL column may need a sequence.
+				dbDesc, err := MustGetDatabaseDescByID(params.ctx, params.p.txn, n.tableDesc.ParentID)
+				if err != nil {
+					return err
+				}
+				var seqDesc *sqlbase.TableDescriptor
+				d, seqDesc, err = processSerialInColumnDef(params, d, dbDesc, tn, n.n.String())
+				if err != nil {
+					return err
+				}
+				if seqDesc != nil {
+					ownedSeqIDs[d.Name] = seqDesc.ID
+				}
+			}
 			col, idx, expr, err := sqlbase.MakeColumnDefDescs(d, &params.p.semaCtx, params.EvalContext())
 			if err != nil {
 				return err
@@ -291,6 +308,18 @@ func (n *alterTableNode) startExec(params runParams) error {
 				}
 			}
 
+			// If the dropped column owns sequences, drop them too.
+			if len(col.OwnsSequenceIds) > 0 {
+				if err := params.p.canDropSequencesOwnedByCol(
+					params.ctx, n.tableDesc.ID, &col, nil, /* dropping */
+				); err != nil {
+					return err
+				}
+				if err := params.p.dropSequencesOwnedByCol(params.ctx, &col); err != nil {
+					return err
+				}
+			}
+
 			// You can't drop a column depended on by a view unless CASCADE was
 			// specified.
 			for _, ref := range n.tableDesc.DependedOnBy {
@@ -376,6 +405,12 @@ func (n *alterTableNode) startExec(params runParams) error {
 						containsThisColumn = true
 					}
 				}
+				// The predicate of a partial index can also reference the column.
+				if used, err := idx.PredicateUsesColumn(col.Name); err != nil {
+					return err
+				} else if used {
+					containsThisColumn = true
+				}
 
 				// Perform the DROP.
 				if containsThisColumn {
@@ -538,6 +573,15 @@ func (n *alterTableNode) startExec(params runParams) error {
 			n.tableDesc.UpdateColumnDescriptor(col)
 			descriptorChanged = true
 
+		case *tree.AlterTableAlterPrimaryKey:
+			if len(n.n.Cmds) > 1 {
+				return pgerror.Unimplemented("alter primary key with other commands",
+					"ALTER PRIMARY KEY cannot be combined with other ALTER TABLE commands")
+			}
+			if err := alterPrimaryKey(n.tableDesc, t); err != nil {
+				return err
+			}
+
 		case *tree.AlterTablePartitionBy:
 			partitioning, err := CreatePartitioning(
 				params.ctx, params.p.ExecCfg().Settings,
@@ -596,6 +640,27 @@ func (n *alterTableNode) startExec(params runParams) error {
 		return err
 	}
 
+	// Make the new SERIAL columns own their sequences, now that the columns
+	// have IDs.
+	for i := range n.tableDesc.Mutations {
+		col := n.tableDesc.Mutations[i].GetColumn()
+		if col == nil || n.tableDesc.Mutations[i].Direction != sqlbase.DescriptorMutation_ADD {
+			continue
+		}
+		seqID, ok := ownedSeqIDs[tree.Name(col.Name)]
+		if !ok {
+			continue
+		}
+		seqDesc := sqlbase.TableDescriptor{}
+		if err := getDescriptorByID(params.ctx, params.p.txn, seqID, &seqDesc); err != nil {
+			return err
+		}
+		setSequenceOwner(&seqDesc, n.tableDesc, col)
+		if err := params.p.writeSchemaChange(params.ctx, &seqDesc, sqlbase.InvalidMutationID); err != nil {
+			return err
+		}
+	}
+
 	mutationID := sqlbase.InvalidMutationID
 	if addedMutations {
 		var err error
@@ -647,6 +712,105 @@ func (p *planner) setAuditMode(
 	return desc.SetAuditMode(auditMode)
 }
 
+// alterPrimaryKey adds the mutations of a primary key change to tableDesc: a
+// new primary index on the given columns, and a copy of every secondary index
+// keyed on the new primary key. Both are built alongside the existing
+// indexes and replace them once the schema change completes.
+func alterPrimaryKey(tableDesc *sqlbase.TableDescriptor, t *tree.AlterTableAlterPrimaryKey) error {
+	if len(tableDesc.Mutations) > 0 {
+		return fmt.Errorf("table %q has schema changes in progress, try again later", tableDesc.Name)
+	}
+	if len(tableDesc.Families) > 1 {
+		return pgerror.Unimplemented("alter primary key column families",
+			"cannot change the primary key of a table with multiple column families")
+	}
+	if tableDesc.IsInterleaved() {
+		return pgerror.Unimplemented("alter primary key interleaved",
+			"cannot change the primary key of an interleaved table")
+	}
+	for _, idx := range tableDesc.AllNonDropIndexes() {
+		if idx.ForeignKey.IsSet() || len(idx.ReferencedBy) > 0 {
+			return pgerror.Unimplemented("alter primary key foreign keys",
+				"cannot change the primary key of a table with foreign key references")
+		}
+		if idx.Partitioning.NumColumns > 0 {
+			return pgerror.Unimplemented("alter primary key partitioned",
+				"cannot change the primary key of a partitioned table")
+		}
+	}
+	for _, ref := range tableDesc.DependedOnBy {
+		if ref.IndexID != 0 {
+			return fmt.Errorf("cannot change the primary key of table %q: "+
+				"an index is in use by relation %d", tableDesc.Name, ref.ID)
+		}
+	}
+
+	newPrimaryIndex := sqlbase.IndexDescriptor{
+		Name:         sqlbase.PrimaryKeyIndexName + sqlbase.PrimaryKeyChangeIndexSuffix,
+		Unique:       true,
+		EncodingType: sqlbase.PrimaryIndexEncoding,
+	}
+	if err := newPrimaryIndex.FillColumns(t.Columns); err != nil {
+		return err
+	}
+	if len(newPrimaryIndex.ColumnNames) == len(tableDesc.PrimaryIndex.ColumnNames) {
+		same := true
+		for i := range newPrimaryIndex.ColumnNames {
+			if newPrimaryIndex.ColumnNames[i] != tableDesc.PrimaryIndex.ColumnNames[i] ||
+				newPrimaryIndex.ColumnDirections[i] != tableDesc.PrimaryIndex.ColumnDirections[i] {
+				same = false
+				break
+			}
+		}
+		if same {
+			// The primary key is unchanged.
+			return nil
+		}
+	}
+
+	keyColumns := make(map[string]struct{}, len(newPrimaryIndex.ColumnNames))
+	for _, name := range newPrimaryIndex.ColumnNames {
+		col, dropped, err := tableDesc.FindColumnByName(tree.Name(name))
+		if err != nil {
+			return err
+		}
+		if dropped {
+			return fmt.Errorf("column %q in the middle of being dropped", name)
+		}
+		if col.Nullable {
+			return pgerror.NewErrorf(pgerror.CodeInvalidTableDefinitionError,
+				"cannot use nullable column %q in primary key", name)
+		}
+		keyColumns[name] = struct{}{}
+	}
+	// Like the primary index, the new primary index stores all the other
+	// columns.
+	for _, col := range tableDesc.Columns {
+		if _, ok := keyColumns[col.Name]; !ok {
+			newPrimaryIndex.StoreColumnNames = append(newPrimaryIndex.StoreColumnNames, col.Name)
+			newPrimaryIndex.StoreColumnIDs = append(newPrimaryIndex.StoreColumnIDs, col.ID)
+		}
+	}
+	if err := tableDesc.AddIndexMutation(newPrimaryIndex, sqlbase.DescriptorMutation_ADD); err != nil {
+		return err
+	}
+
+	// The secondary indexes encode the primary key columns, so they are all
+	// rebuilt. Their extra columns are allocated against the new primary key.
+	for _, idx := range tableDesc.Indexes {
+		newIndex := idx
+		newIndex.ID = 0
+		newIndex.Name = idx.Name + sqlbase.PrimaryKeyChangeIndexSuffix
+		newIndex.ExtraColumnIDs = nil
+		newIndex.StoreColumnIDs = nil
+		newIndex.CompositeColumnIDs = nil
+		if err := tableDesc.AddIndexMutation(newIndex, sqlbase.DescriptorMutation_ADD); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (n *alterTableNode) Next(runParams) (bool, error) { return false, nil }
 func (n *alterTableNode) Values() tree.Datums          { return tree.Datums{} }
 func (n *alterTableNode) Close(context.Context)        {}
@@ -0,0 +1,167 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// appQuotas holds the per-node statement rate quotas of applications. The
+// quotas are soft: they're enforced independently by every node, and only
+// once a statement is about to run.
+var appQuotas = settings.RegisterValidatedStringSetting(
+	"sql.application_quotas.statements_per_second",
+	"per-node limits on the rate of statements of applications, "+
+		"as a comma-separated list of application_name=rate pairs",
+	"",
+	func(_ *settings.Values, s string) error {
+		_, err := parseAppQuotas(s)
+		return err
+	},
+)
+
+type appQuotaMode int64
+
+const (
+	appQuotaReject appQuotaMode = iota
+	appQuotaThrottle
+)
+
+// appQuotaModeSetting determines what happens to statements of applications
+// exceeding their quota.
+var appQuotaModeSetting = settings.RegisterEnumSetting(
+	"sql.application_quotas.mode",
+	"whether statements exceeding their application's quota are rejected or delayed",
+	"reject",
+	map[int64]string{
+		int64(appQuotaReject):   "reject",
+		int64(appQuotaThrottle): "throttle",
+	},
+)
+
+// parseAppQuotas parses the value of the appQuotas setting into a map from
+// application names to statement rates.
+func parseAppQuotas(s string) (map[string]float64, error) {
+	quotas := make(map[string]float64)
+	if strings.TrimSpace(s) == "" {
+		return quotas, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		i := strings.LastIndexByte(pair, '=')
+		if i < 0 {
+			return nil, errors.Errorf("invalid application quota %q: expected application_name=rate", pair)
+		}
+		appName := strings.TrimSpace(pair[:i])
+		qps, err := strconv.ParseFloat(strings.TrimSpace(pair[i+1:]), 64)
+		if err != nil || qps <= 0 {
+			return nil, errors.Errorf("invalid application quota %q: rate must be a positive number", pair)
+		}
+		if _, ok := quotas[appName]; ok {
+			return nil, errors.Errorf("duplicate application quota for %q", appName)
+		}
+		quotas[appName] = qps
+	}
+	return quotas, nil
+}
+
+// appResourceUsage tracks the resources consumed by the statements of an
+// application on this node and enforces its quota. Unlike the per-statement
+// statistics, it's never reset.
+type appResourceUsage struct {
+	// Accessed atomically.
+	statements     int64
+	rows           int64
+	kvBytesRead    int64
+	kvBytesWritten int64
+	throttled      int64
+	rejected       int64
+
+	mu struct {
+		syncutil.Mutex
+		// quotasSetting is the value of the appQuotas setting the limiter was
+		// last configured for.
+		quotasSetting string
+		// limiter is nil if the application has no quota.
+		limiter *rate.Limiter
+	}
+}
+
+// recordResources records the resources consumed by a statement.
+func (a *appStats) recordResources(numRows int, kvStats client.KVStats) {
+	if a == nil {
+		return
+	}
+	r := &a.resources
+	atomic.AddInt64(&r.statements, 1)
+	atomic.AddInt64(&r.rows, int64(numRows))
+	atomic.AddInt64(&r.kvBytesRead, kvStats.BytesRead)
+	atomic.AddInt64(&r.kvBytesWritten, kvStats.BytesWritten)
+}
+
+// limiter returns the rate limiter enforcing the application's quota, or nil
+// if it has none.
+func (a *appStats) limiter() *rate.Limiter {
+	quotasSetting := appQuotas.Get(&a.st.SV)
+	r := &a.resources
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if quotasSetting != r.mu.quotasSetting {
+		// The setting was validated, so this can't fail.
+		quotas, _ := parseAppQuotas(quotasSetting)
+		if qps, ok := quotas[a.name]; !ok {
+			r.mu.limiter = nil
+		} else if burst := int(qps); r.mu.limiter == nil {
+			r.mu.limiter = rate.NewLimiter(rate.Limit(qps), burst+1)
+		} else {
+			r.mu.limiter.SetLimit(rate.Limit(qps))
+		}
+		r.mu.quotasSetting = quotasSetting
+	}
+	return r.mu.limiter
+}
+
+// admit enforces the application's quota, if any, before a statement runs.
+// Depending on the sql.application_quotas.mode setting, it either returns an
+// error or blocks until the statement fits in the quota.
+func (a *appStats) admit(ctx context.Context, m *EngineMetrics) error {
+	if a == nil {
+		return nil
+	}
+	limiter := a.limiter()
+	if limiter == nil || limiter.Allow() {
+		return nil
+	}
+	if appQuotaMode(appQuotaModeSetting.Get(&a.st.SV)) == appQuotaThrottle {
+		atomic.AddInt64(&a.resources.throttled, 1)
+		m.AppQuotaThrottledCount.Inc(1)
+		return limiter.Wait(ctx)
+	}
+	atomic.AddInt64(&a.resources.rejected, 1)
+	m.AppQuotaRejectedCount.Inc(1)
+	return pgerror.NewErrorf(pgerror.CodeConfigurationLimitExceededError,
+		"application %q exceeded its quota of %g statements per second",
+		a.name, float64(limiter.Limit()))
+}
@@ -0,0 +1,55 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestParseAppQuotas(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		setting  string
+		expected map[string]float64
+		err      string
+	}{
+		{``, map[string]float64{}, ``},
+		{` `, map[string]float64{}, ``},
+		{`app=10`, map[string]float64{`app`: 10}, ``},
+		{` app = 0.5 , other=100`, map[string]float64{`app`: 0.5, `other`: 100}, ``},
+		{`a=b=2`, map[string]float64{`a=b`: 2}, ``},
+		{`=1`, map[string]float64{``: 1}, ``},
+		{`app`, nil, `expected application_name=rate`},
+		{`app=`, nil, `rate must be a positive number`},
+		{`app=0`, nil, `rate must be a positive number`},
+		{`app=-1`, nil, `rate must be a positive number`},
+		{`app=1,app=2`, nil, `duplicate application quota for "app"`},
+	}
+	for _, tc := range testCases {
+		quotas, err := parseAppQuotas(tc.setting)
+		if !testutils.IsError(err, tc.err) {
+			t.Errorf("%q: expected error %q, got %v", tc.setting, tc.err, err)
+			continue
+		}
+		if tc.err == `` && !reflect.DeepEqual(tc.expected, quotas) {
+			t.Errorf("%q: expected %v, got %v", tc.setting, tc.expected, quotas)
+		}
+	}
+}
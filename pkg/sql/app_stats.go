@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
@@ -43,7 +44,12 @@ type stmtKey struct {
 
 // appStats holds per-application statistics.
 type appStats struct {
-	st *cluster.Settings
+	st   *cluster.Settings
+	name string
+
+	// resources tracks the resources consumed by the application and enforces
+	// its quota.
+	resources appResourceUsage
 
 	syncutil.Mutex
 	stmts map[stmtKey]*stmtStats
@@ -54,6 +60,12 @@ type stmtStats struct {
 	syncutil.Mutex
 
 	data roachpb.StatementStatistics
+	// retries counts the retryable errors encountered by the statement,
+	// broken down by cause.
+	retries [numRetryCauses]int64
+	// indexRecommendations holds the indexes recommended for the last plan
+	// of the statement.
+	indexRecommendations []string
 }
 
 // stmtStatsEnable determines whether to collect per-statement
@@ -92,10 +104,13 @@ func (s stmtKey) flags() string {
 }
 
 func (a *appStats) recordStatement(
+	ctx context.Context,
 	stmt Statement,
+	plan *planTop,
 	distSQLUsed bool,
 	automaticRetryCount int,
 	numRows int,
+	kvStats client.KVStats,
 	err error,
 	parseLat, planLat, runLat, svcLat, ovhLat float64,
 ) {
@@ -125,6 +140,11 @@ func (a *appStats) recordStatement(
 		key.stmt = anonymizeStmt(stmt)
 	}
 
+	var indexRecs []string
+	if plan != nil {
+		indexRecs = indexRecommendationsForPlan(ctx, plan)
+	}
+
 	// Get the statistics object.
 	s := a.getStatsForStmt(key)
 
@@ -134,6 +154,9 @@ func (a *appStats) recordStatement(
 	if err != nil {
 		s.data.LastErr = err.Error()
 		s.data.LastErrRedacted = log.Redact(err)
+		if retryErr, ok := err.(*roachpb.HandledRetryableTxnError); ok {
+			s.retries[classifyRetryableErr(retryErr)]++
+		}
 	}
 	if automaticRetryCount == 0 {
 		s.data.FirstAttemptCount++
@@ -146,6 +169,11 @@ func (a *appStats) recordStatement(
 	s.data.RunLat.Record(s.data.Count, runLat)
 	s.data.ServiceLat.Record(s.data.Count, svcLat)
 	s.data.OverheadLat.Record(s.data.Count, ovhLat)
+	s.data.KVRequests.Record(s.data.Count, float64(kvStats.Requests))
+	s.data.KVBytesRead.Record(s.data.Count, float64(kvStats.BytesRead))
+	s.data.KVBytesWritten.Record(s.data.Count, float64(kvStats.BytesWritten))
+	s.data.KVLat.Record(s.data.Count, kvStats.Latency.Seconds())
+	s.indexRecommendations = indexRecs
 	s.Unlock()
 }
 
@@ -177,6 +205,11 @@ type sqlStats struct {
 	lastReset time.Time
 	// apps is the container for all the per-application statistics objects.
 	apps map[string]*appStats
+
+	// indexUsage tracks the index reads of all applications. Unlike the
+	// per-application statistics, it isn't reset but periodically flushed to
+	// system.index_usage_statistics.
+	indexUsage indexUsageStats
 }
 
 func (s *sqlStats) getStatsForApplication(appName string) *appStats {
@@ -185,7 +218,7 @@ func (s *sqlStats) getStatsForApplication(appName string) *appStats {
 	if a, ok := s.apps[appName]; ok {
 		return a
 	}
-	a := &appStats{st: s.st, stmts: make(map[stmtKey]*stmtStats)}
+	a := &appStats{st: s.st, name: appName, stmts: make(map[stmtKey]*stmtStats)}
 	s.apps[appName] = a
 	return a
 }
@@ -0,0 +1,184 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// applyJoinNode implements apply join: the execution component of correlated
+// subqueries and LATERAL joins that could not be decorrelated by the
+// optimizer. For each row produced by the left input, a new plan is built for
+// the right side, in which the references to the left columns are replaced by
+// the values of that row. The right plan is then executed and its results are
+// joined with the left row.
+//
+// Only inner, left outer, semi and anti joins are supported. The node is never
+// distributed.
+type applyJoinNode struct {
+	joinType sqlbase.JoinType
+
+	// The data source for the left side of the join.
+	input planDataSource
+
+	// pred represents the join predicate. The ON condition refers to the left
+	// columns followed by the right columns.
+	pred *joinPredicate
+
+	// columns contains the metadata for the results of this node.
+	columns sqlbase.ResultColumns
+
+	// rightCols contains the metadata for the results of the right plans.
+	rightCols sqlbase.ResultColumns
+
+	// planRightSideFn creates a plan for the right side of the join for a
+	// given left row.
+	planRightSideFn exec.ApplyJoinPlanRightSideFn
+
+	run applyJoinRun
+}
+
+// applyJoinRun contains the run-time state of applyJoinNode during local
+// execution.
+type applyJoinRun struct {
+	// leftRow is the current left row.
+	leftRow tree.Datums
+
+	// leftRowMatched is set once a right row matches the current left row.
+	leftRowMatched bool
+
+	// rightPlan is the running plan for the right side of the current left
+	// row, or nil if there is none.
+	rightPlan planNode
+
+	// emptyRight contains NULLs for the right columns; it is used by left outer
+	// joins when a left row has no match.
+	emptyRight tree.Datums
+
+	// out is the current output row.
+	out tree.Datums
+}
+
+func (p *planner) makeApplyJoinNode(
+	joinType sqlbase.JoinType,
+	left planDataSource,
+	rightCols sqlbase.ResultColumns,
+	pred *joinPredicate,
+	planRightSideFn exec.ApplyJoinPlanRightSideFn,
+) *applyJoinNode {
+	n := &applyJoinNode{
+		joinType:        joinType,
+		input:           left,
+		pred:            pred,
+		columns:         pred.info.SourceColumns,
+		rightCols:       rightCols,
+		planRightSideFn: planRightSideFn,
+	}
+	n.run.emptyRight = make(tree.Datums, len(rightCols))
+	for i := range n.run.emptyRight {
+		n.run.emptyRight[i] = tree.DNull
+	}
+	n.run.out = make(tree.Datums, len(n.columns))
+	return n
+}
+
+func (a *applyJoinNode) Next(params runParams) (bool, error) {
+	for {
+		if a.run.rightPlan == nil {
+			// Advance to the next left row and plan the right side for it.
+			ok, err := a.input.plan.Next(params)
+			if !ok || err != nil {
+				return ok, err
+			}
+			a.run.leftRow = append(a.run.leftRow[:0], a.input.plan.Values()...)
+			a.run.leftRowMatched = false
+
+			rightPlan, err := a.planRightSideFn(a.run.leftRow)
+			if err != nil {
+				return false, err
+			}
+			a.run.rightPlan = rightPlan.(planNode)
+			if err := startPlan(params, a.run.rightPlan); err != nil {
+				return false, err
+			}
+		}
+
+		ok, err := a.run.rightPlan.Next(params)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			// The right side is exhausted.
+			a.closeRightPlan(params.ctx)
+			if a.run.leftRowMatched {
+				continue
+			}
+			switch a.joinType {
+			case sqlbase.LeftOuterJoin:
+				a.pred.prepareRow(a.run.out, a.run.leftRow, a.run.emptyRight)
+				return true, nil
+			case sqlbase.LeftAntiJoin:
+				copy(a.run.out, a.run.leftRow)
+				return true, nil
+			}
+			continue
+		}
+
+		rightRow := a.run.rightPlan.Values()
+		pass, err := a.pred.eval(params.EvalContext(), a.run.leftRow, rightRow)
+		if err != nil {
+			return false, err
+		}
+		if !pass {
+			continue
+		}
+		a.run.leftRowMatched = true
+
+		switch a.joinType {
+		case sqlbase.LeftSemiJoin:
+			// The left row is emitted at most once; there is no need to look at
+			// the rest of the right side.
+			a.closeRightPlan(params.ctx)
+			copy(a.run.out, a.run.leftRow)
+			return true, nil
+		case sqlbase.LeftAntiJoin:
+			// The left row is filtered out.
+			a.closeRightPlan(params.ctx)
+			continue
+		}
+		a.pred.prepareRow(a.run.out, a.run.leftRow, rightRow)
+		return true, nil
+	}
+}
+
+func (a *applyJoinNode) Values() tree.Datums {
+	return a.run.out
+}
+
+func (a *applyJoinNode) closeRightPlan(ctx context.Context) {
+	if a.run.rightPlan != nil {
+		a.run.rightPlan.Close(ctx)
+		a.run.rightPlan = nil
+	}
+}
+
+func (a *applyJoinNode) Close(ctx context.Context) {
+	a.closeRightPlan(ctx)
+	a.input.plan.Close(ctx)
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/backfill"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlrun"
 	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
@@ -61,6 +62,20 @@ const (
 	checkpointInterval = 10 * time.Second
 )
 
+// columnBackfillChunkSize is the number of rows the distributed column
+// backfill rewrites per transaction.
+var columnBackfillChunkSize = settings.RegisterValidatedIntSetting(
+	"schemachanger.backfiller.column_chunk_size",
+	"the number of rows rewritten per transaction by column backfills",
+	columnTruncateAndBackfillChunkSize,
+	func(v int64) error {
+		if v <= 0 {
+			return errors.Errorf("cannot set schemachanger.backfiller.column_chunk_size to a non-positive value: %d", v)
+		}
+		return nil
+	},
+)
+
 var _ sort.Interface = columnsByID{}
 var _ sort.Interface = indexesByID{}
 
@@ -577,7 +592,7 @@ func (sc *SchemaChanger) truncateAndBackfillColumns(
 ) error {
 	return sc.distBackfill(
 		ctx, evalCtx,
-		lease, version, columnBackfill, columnTruncateAndBackfillChunkSize,
+		lease, version, columnBackfill, columnBackfillChunkSize.Get(&sc.settings.SV),
 		backfill.ColumnMutationFilter)
 }
 
@@ -633,7 +648,7 @@ func runSchemaChangesInTxn(
 				doneColumnBackfill = true
 
 			case *sqlbase.DescriptorMutation_Index:
-				if err := indexBackfillInTxn(ctx, txn, tableDesc); err != nil {
+				if err := indexBackfillInTxn(ctx, txn, evalCtx, tableDesc); err != nil {
 					return err
 				}
 
@@ -723,10 +738,13 @@ func columnBackfillInTxn(
 }
 
 func indexBackfillInTxn(
-	ctx context.Context, txn *client.Txn, tableDesc *sqlbase.TableDescriptor,
+	ctx context.Context,
+	txn *client.Txn,
+	evalCtx *tree.EvalContext,
+	tableDesc *sqlbase.TableDescriptor,
 ) error {
 	var backfiller backfill.IndexBackfiller
-	if err := backfiller.Init(*tableDesc); err != nil {
+	if err := backfiller.Init(evalCtx, *tableDesc); err != nil {
 		return err
 	}
 	sp := tableDesc.PrimaryIndexSpan()
@@ -285,13 +285,15 @@ type IndexBackfiller struct {
 	added []sqlbase.IndexDescriptor
 	// colIdxMap maps ColumnIDs to indices into desc.Columns and desc.Mutations.
 	colIdxMap map[sqlbase.ColumnID]int
+	// partialIndexPreds evaluates the predicates of the added partial indexes.
+	partialIndexPreds *sqlbase.PartialIndexPredicates
 
 	types   []sqlbase.ColumnType
 	rowVals tree.Datums
 }
 
 // Init initializes an IndexBackfiller.
-func (ib *IndexBackfiller) Init(desc sqlbase.TableDescriptor) error {
+func (ib *IndexBackfiller) Init(evalCtx *tree.EvalContext, desc sqlbase.TableDescriptor) error {
 	numCols := len(desc.Columns)
 	cols := desc.Columns
 	if len(desc.Mutations) > 0 {
@@ -318,9 +320,19 @@ func (ib *IndexBackfiller) Init(desc sqlbase.TableDescriptor) error {
 					valNeededForCol.Add(i)
 				}
 			}
+			if idx.IsPartial() {
+				// The predicate can reference any of the public columns.
+				valNeededForCol.AddRange(0, len(desc.Columns)-1)
+			}
 		}
 	}
 
+	var err error
+	ib.partialIndexPreds, err = sqlbase.MakePartialIndexPredicates(evalCtx, &desc, ib.added)
+	if err != nil {
+		return err
+	}
+
 	ib.types = make([]sqlbase.ColumnType, len(cols))
 	for i := range cols {
 		ib.types[i] = cols[i].Type
@@ -395,7 +407,20 @@ func (ib *IndexBackfiller) BuildIndexEntriesChunk(
 			ib.rowVals, buffer); err != nil {
 			return nil, nil, err
 		}
-		entries = append(entries, buffer...)
+		for j := range buffer {
+			// Partial indexes are never inverted, so they only have one
+			// entry at the beginning of the buffer.
+			if j < len(ib.added) && ib.added[j].IsPartial() {
+				ok, err := ib.partialIndexPreds.Includes(&ib.added[j], ib.colIdxMap, ib.rowVals)
+				if err != nil {
+					return nil, nil, err
+				}
+				if !ok {
+					continue
+				}
+			}
+			entries = append(entries, buffer[j])
+		}
 	}
 	return entries, ib.fetcher.Key(), nil
 }
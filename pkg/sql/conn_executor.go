@@ -239,6 +239,9 @@ type Server struct {
 
 	reCache *tree.RegexpCache
 
+	// queryCache holds the results of recent read-only statements.
+	queryCache *queryCache
+
 	// pool is the parent monitor for all session monitors except "internal" ones.
 	pool *mon.BytesMonitor
 
@@ -278,13 +281,18 @@ func NewServer(cfg *ExecutorConfig, pool *mon.BytesMonitor) *Server {
 				6*metricsSampleInterval),
 			SQLServiceLatency: metric.NewLatency(MetaSQLServiceLatency,
 				6*metricsSampleInterval),
+			AppQuotaThrottledCount: metric.NewCounter(MetaAppQuotaThrottled),
+			AppQuotaRejectedCount:  metric.NewCounter(MetaAppQuotaRejected),
+			QueryCacheHitCount:     metric.NewCounter(MetaQueryCacheHit),
+			QueryCacheMissCount:    metric.NewCounter(MetaQueryCacheMiss),
 		},
 		StatementCounters: makeStatementCounters(),
 		// dbCache will be updated on Start().
-		dbCache:  newDatabaseCacheHolder(newDatabaseCache(config.SystemConfig{})),
-		pool:     pool,
-		sqlStats: sqlStats{st: cfg.Settings, apps: make(map[string]*appStats)},
-		reCache:  tree.NewRegexpCache(512),
+		dbCache:    newDatabaseCacheHolder(newDatabaseCache(config.SystemConfig{})),
+		pool:       pool,
+		sqlStats:   sqlStats{st: cfg.Settings, apps: make(map[string]*appStats)},
+		reCache:    tree.NewRegexpCache(512),
+		queryCache: makeQueryCache(cfg.Settings),
 	}
 }
 
@@ -303,6 +311,9 @@ func (s *Server) Start(ctx context.Context, stopper *stop.Stopper) {
 		}
 	})
 	s.PeriodicallyClearStmtStats(ctx, stopper)
+	s.PeriodicallyFlushIndexUsageStats(ctx, stopper)
+	s.PeriodicallyFlushStmtStats(ctx, stopper)
+	s.PeriodicallyRunSchedules(ctx, stopper)
 }
 
 // recordError takes an error and increments the corresponding count for its
@@ -359,7 +370,14 @@ func (s *Server) ResetErrorCounts() {
 }
 
 // ResetStatementStats resets the executor's collected statement statistics.
+// Unless persisting them is disabled, they're first written to
+// system.statement_statistics.
 func (s *Server) ResetStatementStats(ctx context.Context) {
+	if stmtStatsFlushInterval.Get(&s.cfg.Settings.SV) > 0 {
+		if err := s.sqlStats.flushStmtStats(ctx, s.cfg); err != nil {
+			log.Warningf(ctx, "failed to flush statement statistics: %v", err)
+		}
+	}
 	s.sqlStats.resetStats(ctx)
 }
 
@@ -438,15 +456,17 @@ func (sp sessionParams) sessionData(
 		curDb = sessiondata.DefaultDatabaseName
 	}
 	sd := sessiondata.SessionData{
-		ApplicationName: sp.args.ApplicationName,
-		Database:        curDb,
-		DistSQLMode:     sessiondata.DistSQLExecMode(DistSQLClusterExecMode.Get(&settings.SV)),
-		OptimizerMode:   sessiondata.OptimizerMode(OptimizerClusterMode.Get(&settings.SV)),
-		SearchPath:      sqlbase.DefaultSearchPath,
-		Location:        time.UTC,
-		User:            sp.args.User,
-		RemoteAddr:      sp.args.RemoteAddr,
-		SequenceState:   sessiondata.NewSequenceState(),
+		ApplicationName:           sp.args.ApplicationName,
+		Database:                  curDb,
+		DistSQLMode:               sessiondata.DistSQLExecMode(DistSQLClusterExecMode.Get(&settings.SV)),
+		OptimizerMode:             sessiondata.OptimizerMode(OptimizerClusterMode.Get(&settings.SV)),
+		ForeignKeyCascadesLimit:   defaultForeignKeyCascadesLimit,
+		SearchPath:                sqlbase.DefaultSearchPath,
+		Location:                  time.UTC,
+		User:                      sp.args.User,
+		RemoteAddr:                sp.args.RemoteAddr,
+		SequenceState:             sessiondata.NewSequenceState(),
+		OptimizerUseMultiColStats: true,
 	}
 	return sd
 }
@@ -486,6 +506,19 @@ func (s *Server) newConnExecutor(
 		memMetrics.TxnCurBytesCount,
 		memMetrics.TxnMaxBytesHist,
 		-1 /* increment */, noteworthyMemoryUsageBytes, s.cfg.Settings)
+	// The session's disk monitor tracks the temporary storage used by the
+	// session's local flows, so that it can be reported in SHOW QUERIES. It
+	// doesn't report to the metrics itself: its parent already does.
+	var diskMon *mon.BytesMonitor
+	if s.cfg.DistSQLSrv != nil && s.cfg.DistSQLSrv.DiskMonitor != nil {
+		m := mon.MakeMonitor("session disk",
+			mon.DiskResource,
+			nil, /* curCount */
+			nil, /* maxHist */
+			-1 /* increment */, math.MaxInt64, s.cfg.Settings)
+		m.Start(ctx, s.cfg.DistSQLSrv.DiskMonitor, mon.BoundAccount{})
+		diskMon = &m
+	}
 
 	sd := sargs.sessionData(ctx, s.cfg.Settings)
 
@@ -495,6 +528,7 @@ func (s *Server) newConnExecutor(
 		clientComm:  clientComm,
 		mon:         &sessionRootMon,
 		sessionMon:  &sessionMon,
+		diskMon:     diskMon,
 		sessionData: sd,
 		prepStmtsNamespace: prepStmtNamespace{
 			prepStmts: make(map[string]prepStmtEntry),
@@ -727,10 +761,16 @@ func (ex *connExecutor) close(ctx context.Context, closeType closeType) {
 		ex.state.mon.Stop(ctx)
 		ex.sessionMon.Stop(ctx)
 		ex.mon.Stop(ctx)
+		if ex.diskMon != nil {
+			ex.diskMon.Stop(ctx)
+		}
 	} else {
 		ex.state.mon.EmergencyStop(ctx)
 		ex.sessionMon.EmergencyStop(ctx)
 		ex.mon.EmergencyStop(ctx)
+		if ex.diskMon != nil {
+			ex.diskMon.EmergencyStop(ctx)
+		}
 	}
 }
 
@@ -754,6 +794,9 @@ type connExecutor struct {
 	// statistics for result sets (which escape transactions).
 	mon        *mon.BytesMonitor
 	sessionMon *mon.BytesMonitor
+	// diskMon tracks the temporary disk storage used by the session's
+	// queries. It's nil if there's no temporary storage (e.g. in some tests).
+	diskMon *mon.BytesMonitor
 	// memMetrics contains the metrics that statements executed on this connection
 	// will contribute to.
 	memMetrics MemoryMetrics
@@ -991,6 +1034,20 @@ func (ex *connExecutor) resetExtraTxnState(
 	return nil
 }
 
+// idleTimeout returns the duration for which the session can wait for its
+// next command before being terminated, and whether it's in a transaction. The
+// idle_in_transaction_session_timeout applies while a transaction is open and
+// the idle_session_timeout applies otherwise. A zero duration means that the
+// session can stay idle forever.
+func (ex *connExecutor) idleTimeout() (timeout time.Duration, inTxn bool) {
+	switch ex.machine.CurState().(type) {
+	case stateNoTxn, stateInternalError:
+		return ex.sessionData.IdleSessionTimeout, false
+	default:
+		return ex.sessionData.IdleInTransactionSessionTimeout, true
+	}
+}
+
 // Ctx returns the transaction's ctx, if we're inside a transaction, or the
 // session's context otherwise.
 func (ex *connExecutor) Ctx() context.Context {
@@ -1040,7 +1097,26 @@ func (ex *connExecutor) run(ctx context.Context, cancel context.CancelFunc) erro
 			return err
 		}
 
+		// If the session stays idle for longer than its idle timeout, the
+		// stmtBuf is closed from under curCmd() and the session terminates.
+		// Closing the session rolls back its transaction, which releases the
+		// leases and the intents it holds.
+		var idleTimer *time.Timer
+		idleTimeout, inTxn := ex.idleTimeout()
+		if idleTimeout > 0 {
+			idleTimer = time.AfterFunc(idleTimeout, ex.stmtBuf.Close)
+		}
 		cmd, pos, err := ex.stmtBuf.curCmd()
+		if idleTimer != nil && !idleTimer.Stop() {
+			err := pgerror.NewError(pgerror.CodeIdleSessionTimeoutError,
+				"terminating connection due to idle session timeout")
+			if inTxn {
+				err = pgerror.NewError(pgerror.CodeIdleInTransactionSessionTimeoutError,
+					"terminating connection due to idle-in-transaction timeout")
+			}
+			log.Infof(ctx, "%v (%s)", err, idleTimeout)
+			return err
+		}
 		if err != nil {
 			if err == io.EOF {
 				return nil
@@ -1065,6 +1141,9 @@ func (ex *connExecutor) run(ctx context.Context, cancel context.CancelFunc) erro
 			stmtRes := ex.clientComm.CreateStatementResult(
 				tcmd.Stmt, NeedRowDesc, pos, nil, /* formatCodes */
 				ex.sessionData.Location, ex.sessionData.BytesEncodeFormat)
+			if tcmd.CoalescedCount > 1 {
+				stmtRes.SetCoalescedCount(tcmd.CoalescedCount)
+			}
 			res = stmtRes
 			curStmt := Statement{AST: tcmd.Stmt}
 
@@ -1746,6 +1825,7 @@ func (ex *connExecutor) evalCtx(
 		Tracing:         &ex.sessionTracing,
 		StatusServer:    ex.server.cfg.StatusServer,
 		MemMetrics:      &ex.memMetrics,
+		DiskMonitor:     ex.diskMon,
 		Tables:          &ex.extraTxnState.tables,
 		ExecCfg:         ex.server.cfg,
 		DistSQLPlanner:  ex.server.cfg.DistSQLPlanner,
@@ -1961,6 +2041,13 @@ func (ex *connExecutor) serialize() serverpb.Session {
 		return sql
 	}
 
+	// The memory used by the queries is accounted to their transaction, and
+	// the disk usage to the session, so the queries of a session report the
+	// same usage.
+	var diskBytes int64
+	if ex.diskMon != nil {
+		diskBytes = ex.diskMon.AllocBytes()
+	}
 	for id, query := range ex.mu.ActiveQueries {
 		if query.hidden {
 			continue
@@ -1972,6 +2059,8 @@ func (ex *connExecutor) serialize() serverpb.Session {
 			Sql:           sql,
 			IsDistributed: query.isDistributed,
 			Phase:         (serverpb.ActiveQuery_Phase)(query.phase),
+			AllocBytes:    ex.state.mon.AllocBytes(),
+			DiskBytes:     diskBytes,
 		})
 	}
 	lastActiveQuery := ""
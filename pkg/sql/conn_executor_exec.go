@@ -317,6 +317,14 @@ func (ex *connExecutor) execStmtInOpenState(
 	// For regular statements (the ones that get to this point), we don't return
 	// any event unless an an error happens.
 
+	// Enforce the application's quota. Transaction control statements aren't
+	// subject to it, so that transactions can always finish.
+	if !ex.stmtCounterDisabled {
+		if err := ex.appStats.admit(ctx, &ex.server.EngineMetrics); err != nil {
+			return makeErrEvent(err)
+		}
+	}
+
 	var p *planner
 	stmtTS := ex.server.cfg.Clock.PhysicalTime()
 	// Only run statements asynchronously through the parallelize queue if the
@@ -647,12 +655,14 @@ func (ex *connExecutor) execStmtInParallel(
 		}
 
 		planner.statsCollector.PhaseTimes()[plannerStartExecStmt] = timeutil.Now()
+		kvStatsStart := planner.txn.KVStats()
 		err := ex.execWithLocalEngine(ctx, planner, stmt.AST.StatementType(), res)
 
 		planner.statsCollector.PhaseTimes()[plannerEndExecStmt] = timeutil.Now()
 		ex.recordStatementSummary(
 			planner, stmt, false /* distSQLUsed*/, ex.extraTxnState.autoRetryCounter,
-			res.RowsAffected(), err, &ex.server.EngineMetrics,
+			res.RowsAffected(), planner.txn.KVStats().Sub(kvStatsStart), err,
+			&ex.server.EngineMetrics,
 		)
 		if ex.server.cfg.TestingKnobs.AfterExecute != nil {
 			ex.server.cfg.TestingKnobs.AfterExecute(ctx, stmt.String(), res.Err())
@@ -733,11 +743,40 @@ func (ex *connExecutor) dispatchToExecutionEngine(
 		}
 	}
 
+	// Serve the result of the statement from the query cache if possible.
+	// Otherwise, collect it so that it can be cached once the statement
+	// succeeds. Internal statements are never cached.
+	var cacheKey string
+	var tableVersions map[sqlbase.ID]sqlbase.DescriptorVersion
+	var cacheResult *queryCacheResultWriter
+	var cachedRows []tree.Datums
+	cacheable, cacheHit := false, false
+	if !ex.stmtCounterDisabled {
+		cacheKey, tableVersions, cacheable = ex.server.queryCache.key(
+			ctx, planner, stmt, ex.implicitTxn(),
+		)
+	}
+	execRes := res
+	if cacheable {
+		cachedRows, cacheHit = ex.server.queryCache.get(cacheKey, tableVersions)
+		if cacheHit {
+			ex.server.EngineMetrics.QueryCacheHitCount.Inc(1)
+		} else {
+			ex.server.EngineMetrics.QueryCacheMissCount.Inc(1)
+			cacheResult = &queryCacheResultWriter{
+				RestrictedCommandResult: res,
+				maxSize:                 queryCacheMaxResultSize.Get(&ex.server.cfg.Settings.SV),
+			}
+			execRes = cacheResult
+		}
+	}
+
 	if ex.server.cfg.TestingKnobs.BeforeExecute != nil {
 		ex.server.cfg.TestingKnobs.BeforeExecute(ctx, stmt.String(), false /* isParallel */)
 	}
 
 	planner.statsCollector.PhaseTimes()[plannerStartExecStmt] = timeutil.Now()
+	kvStatsStart := planner.txn.KVStats()
 
 	ex.mu.Lock()
 	queryMeta, ok := ex.mu.ActiveQueries[stmt.queryID]
@@ -749,19 +788,37 @@ func (ex *connExecutor) dispatchToExecutionEngine(
 	queryMeta.isDistributed = useDistSQL
 	ex.mu.Unlock()
 
-	if useDistSQL {
-		err = ex.execWithDistSQLEngine(ctx, planner, stmt.AST.StatementType(), res)
+	if cacheHit {
+		for _, row := range cachedRows {
+			if err = res.AddRow(ctx, row); err != nil {
+				res.SetError(err)
+				break
+			}
+		}
+	} else if useDistSQL {
+		err = ex.execWithDistSQLEngine(ctx, planner, stmt.AST.StatementType(), execRes)
 	} else {
-		err = ex.execWithLocalEngine(ctx, planner, stmt.AST.StatementType(), res)
+		err = ex.execWithLocalEngine(ctx, planner, stmt.AST.StatementType(), execRes)
 	}
 	planner.statsCollector.PhaseTimes()[plannerEndExecStmt] = timeutil.Now()
 	if err != nil {
 		return err
 	}
+	if cacheResult != nil && !cacheResult.overflow && res.Err() == nil {
+		ex.server.queryCache.add(cacheKey, &queryCacheEntry{
+			rows:          cacheResult.rows,
+			createdAt:     timeutil.Now(),
+			tableVersions: tableVersions,
+		})
+	}
 	ex.recordStatementSummary(
 		planner, stmt, useDistSQL, ex.extraTxnState.autoRetryCounter,
-		res.RowsAffected(), res.Err(), &ex.server.EngineMetrics,
+		res.RowsAffected(), planner.txn.KVStats().Sub(kvStatsStart), res.Err(),
+		&ex.server.EngineMetrics,
 	)
+	if !ex.stmtCounterDisabled && !cacheHit && res.Err() == nil {
+		ex.server.sqlStats.recordIndexUsage(ctx, &planner.curPlan)
+	}
 	if ex.server.cfg.TestingKnobs.AfterExecute != nil {
 		ex.server.cfg.TestingKnobs.AfterExecute(ctx, stmt.String(), res.Err())
 	}
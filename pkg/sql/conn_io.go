@@ -144,6 +144,11 @@ type ExecStmt struct {
 	// stats reporting.
 	ParseStart time.Time
 	ParseEnd   time.Time
+
+	// CoalescedCount, if greater than one, is the number of single-row INSERT
+	// statements sent by the client that were coalesced into Stmt. The client
+	// expects a completion message for each of them.
+	CoalescedCount int
 }
 
 // command implements the Command interface.
@@ -619,6 +624,29 @@ type CommandResult interface {
 	// the Postgres protocol; instead, we'll return an error if the number of rows
 	// produced is larger than this limit.
 	SetLimit(n int)
+
+	// SetCoalescedCount is used when the statement stands for n statements sent
+	// by the client (see ExecStmt.CoalescedCount). A completion is reported for
+	// each of them, with the rows affected split by CoalescedRowsAffected.
+	SetCoalescedCount(n int)
+}
+
+// CoalescedRowsAffected splits the rows affected by a statement that stands for
+// n statements sent by the client (see ExecStmt.CoalescedCount) into the
+// counts to report for each of them. The rows are split evenly, with any
+// remainder going to the first statements.
+func CoalescedRowsAffected(rowsAffected, n int) []int {
+	if n < 1 {
+		n = 1
+	}
+	counts := make([]int, n)
+	for i := range counts {
+		counts[i] = rowsAffected / n
+		if i < rowsAffected%n {
+			counts[i]++
+		}
+	}
+	return counts
 }
 
 // CommandResultErrBase is the subset of CommandResult dealing with setting a
@@ -842,6 +870,10 @@ type bufferedCommandResult struct {
 	rowsAffected int
 	cols         sqlbase.ResultColumns
 
+	// coalescedCount, if greater than one, is the number of statements sent by
+	// the client that this result stands for.
+	coalescedCount int
+
 	// errOnly, if set, makes AddRow() panic. This can be used when the execution
 	// of the query is not expected to produce any results.
 	errOnly bool
@@ -909,6 +941,18 @@ func (r *bufferedCommandResult) SetLimit(limit int) {
 	}
 }
 
+// SetCoalescedCount is part of the CommandResult interface.
+func (r *bufferedCommandResult) SetCoalescedCount(n int) {
+	r.coalescedCount = n
+}
+
+// CompletionCounts returns the rows affected to report for each of the
+// statements sent by the client that this result stands for. RowsAffected
+// returns their sum.
+func (r *bufferedCommandResult) CompletionCounts() []int {
+	return CoalescedRowsAffected(r.rowsAffected, r.coalescedCount)
+}
+
 // Close is part of the CommandResult interface.
 func (r *bufferedCommandResult) Close(TransactionStatusIndicator) {
 	if r.closeCallback != nil {
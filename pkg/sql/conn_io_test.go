@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"reflect"
 	"testing"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
@@ -341,3 +342,28 @@ func TestStmtBufBatching(t *testing.T) {
 		t.Fatalf("expected pos to be %d, got: %d", 9, pos)
 	}
 }
+
+func TestCoalescedRowsAffected(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		rowsAffected, n int
+		exp             []int
+	}{
+		{0, 0, []int{0}},
+		{5, 1, []int{5}},
+		{3, 3, []int{1, 1, 1}},
+		{0, 2, []int{0, 0}},
+		{5, 3, []int{2, 2, 1}},
+	}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%d/%d", tc.rowsAffected, tc.n), func(t *testing.T) {
+			res := &bufferedCommandResult{}
+			res.IncrementRowsAffected(tc.rowsAffected)
+			res.SetCoalescedCount(tc.n)
+			if counts := res.CompletionCounts(); !reflect.DeepEqual(counts, tc.exp) {
+				t.Fatalf("expected %v, got %v", tc.exp, counts)
+			}
+		})
+	}
+}
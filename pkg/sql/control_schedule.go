@@ -0,0 +1,106 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/cron"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/pkg/errors"
+)
+
+// controlScheduleNode represents a PAUSE, RESUME or DROP SCHEDULE statement.
+type controlScheduleNode struct {
+	name    string
+	command tree.ScheduleCommand
+
+	rowsAffected int
+}
+
+// ControlSchedule pauses, resumes or drops a schedule.
+// Privileges: superuser.
+func (p *planner) ControlSchedule(ctx context.Context, n *tree.ControlSchedule) (planNode, error) {
+	if err := p.RequireSuperUser(
+		ctx, tree.ScheduleCommandToStatement[n.Command]+" SCHEDULE",
+	); err != nil {
+		return nil, err
+	}
+	return &controlScheduleNode{name: string(n.Name), command: n.Command}, nil
+}
+
+func (n *controlScheduleNode) startExec(params runParams) error {
+	ie := params.extendedEvalCtx.ExecCfg.InternalExecutor
+	var err error
+	switch n.command {
+	case tree.PauseSchedule:
+		n.rowsAffected, err = ie.Exec(
+			params.ctx, "pause-schedule", params.p.txn,
+			`UPDATE system.scheduled_jobs SET paused = true WHERE name = $1`,
+			n.name,
+		)
+
+	case tree.ResumeSchedule:
+		// The runs missed while the schedule was paused are skipped.
+		var row tree.Datums
+		row, err = ie.QueryRow(
+			params.ctx, "resume-schedule", params.p.txn,
+			`SELECT recurrence FROM system.scheduled_jobs WHERE name = $1`,
+			n.name,
+		)
+		if err != nil || row == nil {
+			break
+		}
+		recurrence := string(tree.MustBeDString(row[0]))
+		sched, parseErr := cron.Parse(recurrence)
+		if parseErr != nil {
+			return parseErr
+		}
+		next := sched.Next(timeutil.Now().UTC())
+		if next.IsZero() {
+			return errors.Errorf("cron expression %q never fires", recurrence)
+		}
+		n.rowsAffected, err = ie.Exec(
+			params.ctx, "resume-schedule", params.p.txn,
+			`UPDATE system.scheduled_jobs SET paused = false, next_run = $2 WHERE name = $1`,
+			n.name, next,
+		)
+
+	case tree.DropSchedule:
+		n.rowsAffected, err = ie.Exec(
+			params.ctx, "drop-schedule", params.p.txn,
+			`DELETE FROM system.scheduled_jobs WHERE name = $1`,
+			n.name,
+		)
+	}
+	if err != nil {
+		return err
+	}
+	if n.rowsAffected == 0 {
+		return pgerror.NewErrorf(pgerror.CodeUndefinedObjectError,
+			"schedule %q does not exist", n.name)
+	}
+	return nil
+}
+
+func (*controlScheduleNode) Next(runParams) (bool, error) { return false, nil }
+func (*controlScheduleNode) Values() tree.Datums          { return tree.Datums{} }
+func (*controlScheduleNode) Close(context.Context)        {}
+
+func (n *controlScheduleNode) FastPathResults() (int, bool) {
+	return n.rowsAffected, true
+}
@@ -22,6 +22,7 @@ import (
 	"net/url"
 	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
@@ -43,6 +44,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
@@ -66,7 +69,9 @@ var crdbInternal = virtualSchema{
 		crdbInternalGossipAlertsTable,
 		crdbInternalGossipLivenessTable,
 		crdbInternalIndexColumnsTable,
+		crdbInternalIndexUsageStatsTable,
 		crdbInternalJobsTable,
+		crdbInternalKVGCProgressTable,
 		crdbInternalKVNodeStatusTable,
 		crdbInternalKVStoreStatusTable,
 		crdbInternalLeasesTable,
@@ -76,12 +81,16 @@ var crdbInternal = virtualSchema{
 		crdbInternalPartitionsTable,
 		crdbInternalRangesTable,
 		crdbInternalRuntimeInfoTable,
+		crdbInternalScheduledJobsTable,
 		crdbInternalSchemaChangesTable,
 		crdbInternalSessionTraceTable,
 		crdbInternalSessionVariablesTable,
 		crdbInternalStmtStatsTable,
+		crdbInternalPersistedStmtStatsTable,
+		crdbInternalAppResourceUsageTable,
 		crdbInternalTableColumnsTable,
 		crdbInternalTableIndexesTable,
+		crdbInternalTableEgressTable,
 		crdbInternalTablesTable,
 		crdbInternalZonesTable,
 	},
@@ -457,6 +466,41 @@ CREATE TABLE crdb_internal.jobs (
 	},
 }
 
+// crdbInternalScheduledJobsTable exposes the schedules in
+// system.scheduled_jobs.
+var crdbInternalScheduledJobsTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.scheduled_jobs (
+	name           STRING,
+	owner          STRING,
+	database_name  STRING,
+	created        TIMESTAMP,
+	recurrence     STRING,
+	command        STRING,
+	paused         BOOL,
+	next_run       TIMESTAMP,
+	last_run       TIMESTAMP,
+	last_error     STRING
+);
+`,
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		query := `SELECT name, owner, database_name, created, recurrence, command, paused, ` +
+			`next_run, last_run, last_error FROM system.scheduled_jobs`
+		rows, _ /* cols */, err :=
+			p.ExtendedEvalContext().ExecCfg.InternalExecutor.QueryWithSessionArgs(
+				ctx, "crdb-internal-scheduled-jobs-table", p.txn, SessionArgs{User: p.SessionData().User}, query)
+		if err != nil {
+			return err
+		}
+		for _, r := range rows {
+			if err := addRow(r...); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
 type stmtList []stmtKey
 
 func (s stmtList) Len() int {
@@ -472,27 +516,36 @@ func (s stmtList) Less(i, j int) bool {
 var crdbInternalStmtStatsTable = virtualSchemaTable{
 	schema: `
 CREATE TABLE crdb_internal.node_statement_statistics (
-  node_id             INT NOT NULL,
-  application_name    STRING NOT NULL,
-  flags               STRING NOT NULL,
-  key                 STRING NOT NULL,
-  anonymized          STRING,
-  count               INT NOT NULL,
-  first_attempt_count INT NOT NULL,
-  max_retries         INT NOT NULL,
-  last_error          STRING,
-  rows_avg            FLOAT NOT NULL,
-  rows_var            FLOAT NOT NULL,
-  parse_lat_avg       FLOAT NOT NULL,
-  parse_lat_var       FLOAT NOT NULL,
-  plan_lat_avg        FLOAT NOT NULL,
-  plan_lat_var        FLOAT NOT NULL,
-  run_lat_avg         FLOAT NOT NULL,
-  run_lat_var         FLOAT NOT NULL,
-  service_lat_avg     FLOAT NOT NULL,
-  service_lat_var     FLOAT NOT NULL,
-  overhead_lat_avg    FLOAT NOT NULL,
-  overhead_lat_var    FLOAT NOT NULL
+  node_id              INT NOT NULL,
+  application_name     STRING NOT NULL,
+  flags                STRING NOT NULL,
+  key                  STRING NOT NULL,
+  anonymized           STRING,
+  count                INT NOT NULL,
+  first_attempt_count  INT NOT NULL,
+  max_retries          INT NOT NULL,
+  last_error           STRING,
+  rows_avg             FLOAT NOT NULL,
+  rows_var             FLOAT NOT NULL,
+  parse_lat_avg        FLOAT NOT NULL,
+  parse_lat_var        FLOAT NOT NULL,
+  plan_lat_avg         FLOAT NOT NULL,
+  plan_lat_var         FLOAT NOT NULL,
+  run_lat_avg          FLOAT NOT NULL,
+  run_lat_var          FLOAT NOT NULL,
+  service_lat_avg      FLOAT NOT NULL,
+  service_lat_var      FLOAT NOT NULL,
+  overhead_lat_avg     FLOAT NOT NULL,
+  overhead_lat_var     FLOAT NOT NULL,
+  kv_requests_avg      FLOAT NOT NULL,
+  kv_requests_var      FLOAT NOT NULL,
+  kv_bytes_read_avg    FLOAT NOT NULL,
+  kv_bytes_read_var    FLOAT NOT NULL,
+  kv_bytes_written_avg FLOAT NOT NULL,
+  kv_bytes_written_var FLOAT NOT NULL,
+  kv_lat_avg           FLOAT NOT NULL,
+  kv_lat_var           FLOAT NOT NULL,
+  recommended_indexes  STRING[] NOT NULL
 );
 `,
 	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
@@ -546,6 +599,13 @@ CREATE TABLE crdb_internal.node_statement_statistics (
 				if s.data.LastErr != "" {
 					errString = tree.NewDString(s.data.LastErr)
 				}
+				indexRecs := tree.NewDArray(types.String)
+				for _, rec := range s.indexRecommendations {
+					if err := indexRecs.Append(tree.NewDString(rec)); err != nil {
+						s.Unlock()
+						return err
+					}
+				}
 				err := addRow(
 					nodeID,
 					tree.NewDString(appName),
@@ -568,6 +628,15 @@ CREATE TABLE crdb_internal.node_statement_statistics (
 					tree.NewDFloat(tree.DFloat(s.data.ServiceLat.GetVariance(s.data.Count))),
 					tree.NewDFloat(tree.DFloat(s.data.OverheadLat.Mean)),
 					tree.NewDFloat(tree.DFloat(s.data.OverheadLat.GetVariance(s.data.Count))),
+					tree.NewDFloat(tree.DFloat(s.data.KVRequests.Mean)),
+					tree.NewDFloat(tree.DFloat(s.data.KVRequests.GetVariance(s.data.Count))),
+					tree.NewDFloat(tree.DFloat(s.data.KVBytesRead.Mean)),
+					tree.NewDFloat(tree.DFloat(s.data.KVBytesRead.GetVariance(s.data.Count))),
+					tree.NewDFloat(tree.DFloat(s.data.KVBytesWritten.Mean)),
+					tree.NewDFloat(tree.DFloat(s.data.KVBytesWritten.GetVariance(s.data.Count))),
+					tree.NewDFloat(tree.DFloat(s.data.KVLat.Mean)),
+					tree.NewDFloat(tree.DFloat(s.data.KVLat.GetVariance(s.data.Count))),
+					indexRecs,
 				)
 				s.Unlock()
 				if err != nil {
@@ -579,6 +648,161 @@ CREATE TABLE crdb_internal.node_statement_statistics (
 	},
 }
 
+// crdbInternalPersistedStmtStatsTable exposes the statement statistics
+// persisted to system.statement_statistics by all the nodes, combined across
+// nodes and since the oldest statistics kept. The statistics of a node only
+// include the statements run until its last flush.
+var crdbInternalPersistedStmtStatsTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.statement_statistics (
+  application_name     STRING NOT NULL,
+  flags                STRING NOT NULL,
+  key                  STRING NOT NULL,
+  count                INT NOT NULL,
+  first_attempt_count  INT NOT NULL,
+  max_retries          INT NOT NULL,
+  last_error           STRING,
+  rows_avg             FLOAT NOT NULL,
+  rows_var             FLOAT NOT NULL,
+  parse_lat_avg        FLOAT NOT NULL,
+  parse_lat_var        FLOAT NOT NULL,
+  plan_lat_avg         FLOAT NOT NULL,
+  plan_lat_var         FLOAT NOT NULL,
+  run_lat_avg          FLOAT NOT NULL,
+  run_lat_var          FLOAT NOT NULL,
+  service_lat_avg      FLOAT NOT NULL,
+  service_lat_var      FLOAT NOT NULL,
+  overhead_lat_avg     FLOAT NOT NULL,
+  overhead_lat_var     FLOAT NOT NULL,
+  kv_requests_avg      FLOAT NOT NULL,
+  kv_requests_var      FLOAT NOT NULL,
+  kv_bytes_read_avg    FLOAT NOT NULL,
+  kv_bytes_read_var    FLOAT NOT NULL,
+  kv_bytes_written_avg FLOAT NOT NULL,
+  kv_bytes_written_var FLOAT NOT NULL,
+  kv_lat_avg           FLOAT NOT NULL,
+  kv_lat_var           FLOAT NOT NULL
+);
+`,
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireSuperUser(ctx, "access application statistics"); err != nil {
+			return err
+		}
+
+		stats, err := persistedStmtStats(ctx, p)
+		if err != nil {
+			return err
+		}
+		for i := range stats {
+			k, s := &stats[i].Key, &stats[i].Stats
+			errString := tree.DNull
+			if s.LastErr != "" {
+				errString = tree.NewDString(s.LastErr)
+			}
+			flags := stmtKey{failed: k.Failed, distSQLUsed: k.DistSQL}.flags()
+			if err := addRow(
+				tree.NewDString(k.App),
+				tree.NewDString(flags),
+				tree.NewDString(k.Query),
+				tree.NewDInt(tree.DInt(s.Count)),
+				tree.NewDInt(tree.DInt(s.FirstAttemptCount)),
+				tree.NewDInt(tree.DInt(s.MaxRetries)),
+				errString,
+				tree.NewDFloat(tree.DFloat(s.NumRows.Mean)),
+				tree.NewDFloat(tree.DFloat(s.NumRows.GetVariance(s.Count))),
+				tree.NewDFloat(tree.DFloat(s.ParseLat.Mean)),
+				tree.NewDFloat(tree.DFloat(s.ParseLat.GetVariance(s.Count))),
+				tree.NewDFloat(tree.DFloat(s.PlanLat.Mean)),
+				tree.NewDFloat(tree.DFloat(s.PlanLat.GetVariance(s.Count))),
+				tree.NewDFloat(tree.DFloat(s.RunLat.Mean)),
+				tree.NewDFloat(tree.DFloat(s.RunLat.GetVariance(s.Count))),
+				tree.NewDFloat(tree.DFloat(s.ServiceLat.Mean)),
+				tree.NewDFloat(tree.DFloat(s.ServiceLat.GetVariance(s.Count))),
+				tree.NewDFloat(tree.DFloat(s.OverheadLat.Mean)),
+				tree.NewDFloat(tree.DFloat(s.OverheadLat.GetVariance(s.Count))),
+				tree.NewDFloat(tree.DFloat(s.KVRequests.Mean)),
+				tree.NewDFloat(tree.DFloat(s.KVRequests.GetVariance(s.Count))),
+				tree.NewDFloat(tree.DFloat(s.KVBytesRead.Mean)),
+				tree.NewDFloat(tree.DFloat(s.KVBytesRead.GetVariance(s.Count))),
+				tree.NewDFloat(tree.DFloat(s.KVBytesWritten.Mean)),
+				tree.NewDFloat(tree.DFloat(s.KVBytesWritten.GetVariance(s.Count))),
+				tree.NewDFloat(tree.DFloat(s.KVLat.Mean)),
+				tree.NewDFloat(tree.DFloat(s.KVLat.GetVariance(s.Count))),
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// crdbInternalAppResourceUsageTable exposes the resources consumed by the
+// statements of each application on this node since it started, along with
+// the application's quota, if any.
+var crdbInternalAppResourceUsageTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.node_application_resource_usage (
+  node_id              INT NOT NULL,
+  application_name     STRING NOT NULL,
+  statements           INT NOT NULL,
+  rows                 INT NOT NULL,
+  kv_bytes_read        INT NOT NULL,
+  kv_bytes_written     INT NOT NULL,
+  throttled            INT NOT NULL,
+  rejected             INT NOT NULL,
+  quota                FLOAT
+);
+`,
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireSuperUser(ctx, "access application statistics"); err != nil {
+			return err
+		}
+
+		sqlStats := p.statsCollector.SQLStats()
+		if sqlStats == nil {
+			return errors.New("cannot access sql statistics from this context")
+		}
+
+		nodeID := tree.NewDInt(tree.DInt(int64(p.ExecCfg().NodeID.Get())))
+		quotas, err := parseAppQuotas(appQuotas.Get(&p.ExecCfg().Settings.SV))
+		if err != nil {
+			return err
+		}
+
+		// Retrieve the application names and sort them to ensure the
+		// output is deterministic.
+		var appNames []string
+		sqlStats.Lock()
+		for n := range sqlStats.apps {
+			appNames = append(appNames, n)
+		}
+		sqlStats.Unlock()
+		sort.Strings(appNames)
+
+		for _, appName := range appNames {
+			r := &sqlStats.getStatsForApplication(appName).resources
+			quota := tree.DNull
+			if qps, ok := quotas[appName]; ok {
+				quota = tree.NewDFloat(tree.DFloat(qps))
+			}
+			if err := addRow(
+				nodeID,
+				tree.NewDString(appName),
+				tree.NewDInt(tree.DInt(atomic.LoadInt64(&r.statements))),
+				tree.NewDInt(tree.DInt(atomic.LoadInt64(&r.rows))),
+				tree.NewDInt(tree.DInt(atomic.LoadInt64(&r.kvBytesRead))),
+				tree.NewDInt(tree.DInt(atomic.LoadInt64(&r.kvBytesWritten))),
+				tree.NewDInt(tree.DInt(atomic.LoadInt64(&r.throttled))),
+				tree.NewDInt(tree.DInt(atomic.LoadInt64(&r.rejected))),
+				quota,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
 // crdbInternalSessionTraceTable exposes the latest trace collected on this
 // session (via SET TRACING={ON/OFF})
 var crdbInternalSessionTraceTable = virtualSchemaTable{
@@ -675,7 +899,9 @@ CREATE TABLE crdb_internal.%s (
   client_address   STRING,         -- the address of the client that issued the query
   application_name STRING,         -- the name of the application as per SET application_name
   distributed      BOOL,           -- whether the query is running distributed
-  phase            STRING          -- the current execution phase
+  phase            STRING,         -- the current execution phase
+  alloc_bytes      INT,            -- the number of bytes allocated by the query's transaction
+  disk_bytes       INT             -- the number of bytes of temporary storage used by the session
 );
 `
 
@@ -730,6 +956,8 @@ func populateQueriesTable(
 				tree.NewDString(session.ApplicationName),
 				isDistributedDatum,
 				tree.NewDString(phase),
+				tree.NewDInt(tree.DInt(query.AllocBytes)),
+				tree.NewDInt(tree.DInt(query.DiskBytes)),
 			); err != nil {
 				return err
 			}
@@ -751,6 +979,8 @@ func populateQueriesTable(
 				tree.DNull,                             // application_name
 				tree.DNull,                             // distributed
 				tree.DNull,                             // phase
+				tree.DNull,                             // alloc_bytes
+				tree.DNull,                             // disk_bytes
 			); err != nil {
 				return err
 			}
@@ -1119,7 +1349,8 @@ CREATE TABLE crdb_internal.table_indexes (
   index_id         INT NOT NULL,
   index_name       STRING NOT NULL,
   index_type       STRING NOT NULL,
-  is_unique        BOOL NOT NULL
+  is_unique        BOOL NOT NULL,
+  is_visible       BOOL NOT NULL
 )
 `,
 	populate: func(ctx context.Context, p *planner, dbContext *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
@@ -1136,6 +1367,7 @@ CREATE TABLE crdb_internal.table_indexes (
 					tree.NewDString(table.PrimaryIndex.Name),
 					primary,
 					tree.MakeDBool(tree.DBool(table.PrimaryIndex.Unique)),
+					tree.MakeDBool(tree.DBool(!table.PrimaryIndex.NotVisible)),
 				); err != nil {
 					return err
 				}
@@ -1147,6 +1379,7 @@ CREATE TABLE crdb_internal.table_indexes (
 						tree.NewDString(idx.Name),
 						secondary,
 						tree.MakeDBool(tree.DBool(idx.Unique)),
+						tree.MakeDBool(tree.DBool(!idx.NotVisible)),
 					); err != nil {
 						return err
 					}
@@ -1156,6 +1389,61 @@ CREATE TABLE crdb_internal.table_indexes (
 	},
 }
 
+// crdbInternalIndexUsageStatsTable exposes the number of reads of every
+// index, to help find the indexes that aren't worth their write
+// amplification.
+var crdbInternalIndexUsageStatsTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.index_usage_statistics (
+  descriptor_id    INT,
+  descriptor_name  STRING NOT NULL,
+  index_id         INT NOT NULL,
+  index_name       STRING NOT NULL,
+  total_reads      INT NOT NULL,
+  last_read        TIMESTAMP
+)
+`,
+	populate: func(ctx context.Context, p *planner, dbContext *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		sqlStats := p.statsCollector.SQLStats()
+		if sqlStats == nil {
+			return errors.New("cannot access sql statistics from this context")
+		}
+		reads, err := sqlStats.indexUsageForTables(ctx, p)
+		if err != nil {
+			return err
+		}
+		return forEachTableDescAll(ctx, p, dbContext, hideVirtual,
+			func(db *DatabaseDescriptor, _ string, table *TableDescriptor) error {
+				tableID := tree.NewDInt(tree.DInt(table.ID))
+				tableName := tree.NewDString(table.Name)
+				addIndexRow := func(idx *sqlbase.IndexDescriptor) error {
+					u := reads[indexUsageKey{tableID: table.ID, indexID: idx.ID}]
+					lastRead := tree.DNull
+					if u.reads > 0 {
+						lastRead = tree.MakeDTimestamp(u.lastRead, time.Microsecond)
+					}
+					return addRow(
+						tableID,
+						tableName,
+						tree.NewDInt(tree.DInt(idx.ID)),
+						tree.NewDString(idx.Name),
+						tree.NewDInt(tree.DInt(u.reads)),
+						lastRead,
+					)
+				}
+				if err := addIndexRow(&table.PrimaryIndex); err != nil {
+					return err
+				}
+				for i := range table.Indexes {
+					if err := addIndexRow(&table.Indexes[i]); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+	},
+}
+
 // crdbInternalIndexColumnsTable exposes the index columns.
 var crdbInternalIndexColumnsTable = virtualSchemaTable{
 	schema: `
@@ -2176,3 +2464,154 @@ CREATE TABLE crdb_internal.kv_store_status (
 		return nil
 	},
 }
+
+// crdbInternalTableEgressTable exposes, for each table, the rate at which
+// response bytes are returned to clients, broken down by the locality of the
+// gateway nodes that issued the requests. Ranges are attributed to the table
+// their start key belongs to.
+var crdbInternalTableEgressTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.table_egress (
+  table_id         INT NOT NULL,
+  gateway_locality STRING NOT NULL,
+  bytes_per_second FLOAT NOT NULL
+)
+`,
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireSuperUser(ctx, "read crdb_internal.table_egress"); err != nil {
+			return err
+		}
+
+		nodes, err := p.ExecCfg().StatusServer.Nodes(ctx, &serverpb.NodesRequest{})
+		if err != nil {
+			return err
+		}
+
+		type egressKey struct {
+			tableID  uint64
+			locality string
+		}
+		rates := make(map[egressKey]float64)
+		for _, n := range nodes.Nodes {
+			ranges, err := p.ExecCfg().StatusServer.Ranges(ctx, &serverpb.RangesRequest{
+				NodeId: n.Desc.NodeID.String(),
+			})
+			if err != nil {
+				return err
+			}
+			for _, r := range ranges.Ranges {
+				_, tableID, err := keys.DecodeTablePrefix(roachpb.Key(r.State.Desc.StartKey))
+				if err != nil {
+					// The range doesn't start inside a table.
+					continue
+				}
+				for _, e := range r.Stats.EgressBytesPerSecond {
+					rates[egressKey{tableID: tableID, locality: e.Locality}] += e.BytesPerSecond
+				}
+			}
+		}
+
+		sorted := make([]egressKey, 0, len(rates))
+		for k := range rates {
+			sorted = append(sorted, k)
+		}
+		sort.Slice(sorted, func(i, j int) bool {
+			if sorted[i].tableID != sorted[j].tableID {
+				return sorted[i].tableID < sorted[j].tableID
+			}
+			return sorted[i].locality < sorted[j].locality
+		})
+		for _, k := range sorted {
+			if err := addRow(
+				tree.NewDInt(tree.DInt(k.tableID)),
+				tree.NewDString(k.locality),
+				tree.NewDFloat(tree.DFloat(rates[k])),
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// crdbInternalKVGCProgressTable exposes the progress of the garbage
+// collection of the old versions of the keys of every range, as seen by the
+// leaseholder of the range, which runs the GC queue.
+var crdbInternalKVGCProgressTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.kv_gc_progress (
+  range_id                  INT NOT NULL,
+  node_id                   INT NOT NULL,
+  store_id                  INT NOT NULL,
+  score                     FLOAT NOT NULL,
+  should_queue              BOOL NOT NULL,
+  last_run                  TIMESTAMP,
+  gc_threshold              TIMESTAMP,
+  bytes_awaiting_gc         INT NOT NULL,
+  ttl                       INTERVAL NOT NULL,
+  estimated_time_to_reclaim INTERVAL
+)
+`,
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireSuperUser(ctx, "read crdb_internal.kv_gc_progress"); err != nil {
+			return err
+		}
+
+		nodes, err := p.ExecCfg().StatusServer.Nodes(ctx, &serverpb.NodesRequest{})
+		if err != nil {
+			return err
+		}
+		var infos []serverpb.RangeInfo
+		for _, n := range nodes.Nodes {
+			ranges, err := p.ExecCfg().StatusServer.Ranges(ctx, &serverpb.RangesRequest{
+				NodeId: n.Desc.NodeID.String(),
+			})
+			if err != nil {
+				return err
+			}
+			for _, r := range ranges.Ranges {
+				lease := r.State.Lease
+				if r.LeaseStatus.State != storage.LeaseState_VALID || lease == nil ||
+					lease.Replica.StoreID != r.SourceStoreID {
+					// Only the leaseholder knows the GC progress of the range.
+					continue
+				}
+				infos = append(infos, r)
+			}
+		}
+		sort.Slice(infos, func(i, j int) bool {
+			return infos[i].State.Desc.RangeID < infos[j].State.Desc.RangeID
+		})
+
+		for _, r := range infos {
+			gc := &r.GC
+			lastRun := tree.DNull
+			if !gc.LastRun.IsZero() {
+				lastRun = tree.MakeDTimestamp(gc.LastRun, time.Microsecond)
+			}
+			gcThreshold := tree.DNull
+			if t := r.State.GCThreshold; t != nil && *t != (hlc.Timestamp{}) {
+				gcThreshold = tree.MakeDTimestamp(t.GoTime(), time.Microsecond)
+			}
+			toReclaim := tree.DNull
+			if gc.NanosUntilEligible >= 0 {
+				toReclaim = &tree.DInterval{Duration: duration.Duration{Nanos: gc.NanosUntilEligible}}
+			}
+			if err := addRow(
+				tree.NewDInt(tree.DInt(r.State.Desc.RangeID)),
+				tree.NewDInt(tree.DInt(r.SourceNodeID)),
+				tree.NewDInt(tree.DInt(r.SourceStoreID)),
+				tree.NewDFloat(tree.DFloat(gc.Score)),
+				tree.MakeDBool(tree.DBool(gc.ShouldQueue)),
+				lastRun,
+				gcThreshold,
+				tree.NewDInt(tree.DInt(gc.BytesAwaitingGC)),
+				&tree.DInterval{Duration: duration.Duration{Nanos: int64(gc.TtlSeconds) * time.Second.Nanoseconds()}},
+				toReclaim,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
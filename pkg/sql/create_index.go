@@ -80,9 +80,17 @@ func MakeIndexDescriptor(n *tree.CreateIndex) (*sqlbase.IndexDescriptor, error)
 		if n.Unique {
 			return nil, pgerror.NewError(pgerror.CodeInvalidSQLStatementNameError, "inverted indexes can't be unique")
 		}
+
+		if n.Predicate != nil {
+			return nil, pgerror.NewError(pgerror.CodeInvalidSQLStatementNameError, "inverted indexes can't be partial")
+		}
 		indexDesc.Type = sqlbase.IndexDescriptor_INVERTED
 	}
 
+	if n.Predicate != nil && n.Unique {
+		return nil, pgerror.Unimplemented("unique partial indexes", "unique partial indexes are not supported")
+	}
+
 	if err := indexDesc.FillColumns(n.Columns); err != nil {
 		return nil, err
 	}
@@ -114,6 +122,15 @@ func (n *createIndexNode) startExec(params runParams) error {
 		indexDesc.Partitioning = partitioning
 	}
 
+	if n.n.Predicate != nil {
+		pred, err := makePartialIndexPredicate(params.ctx, n.tableDesc, n.n.Predicate,
+			*n.n.Table.TableName(), &params.p.semaCtx, params.EvalContext())
+		if err != nil {
+			return err
+		}
+		indexDesc.Predicate = pred
+	}
+
 	mutationIdx := len(n.tableDesc.Mutations)
 	if err := n.tableDesc.AddIndexMutation(*indexDesc, sqlbase.DescriptorMutation_ADD); err != nil {
 		return err
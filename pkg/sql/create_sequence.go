@@ -67,25 +67,45 @@ func (n *createSequenceNode) startExec(params runParams) error {
 		return err
 	}
 
+	_, err := doCreateSequence(params, n.n.String(), n.dbDesc, n.n.Name.TableName(), n.n.Options)
+	return err
+}
+
+// doCreateSequence creates a sequence with the given name and options in the
+// given database and returns its descriptor. The caller must ensure that no
+// object with that name exists. statement is the statement that caused the
+// creation, for the event log.
+func doCreateSequence(
+	params runParams,
+	statement string,
+	dbDesc *DatabaseDescriptor,
+	name *tree.TableName,
+	opts tree.SequenceOptions,
+) (*sqlbase.TableDescriptor, error) {
 	id, err := GenerateUniqueDescID(params.ctx, params.p.ExecCfg().DB)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Inherit permissions from the database descriptor.
-	privs := n.dbDesc.GetPrivileges()
+	privs := dbDesc.GetPrivileges()
 
-	desc, err := n.makeSequenceTableDesc(params, seqName, n.dbDesc.ID, id, privs)
+	desc, err := makeSequenceTableDesc(params, name.Table(), opts, dbDesc.ID, id, privs)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if err := params.p.maybeSetSequenceOwner(params.ctx, &desc, opts); err != nil {
+		return nil, err
 	}
 
 	if err = desc.ValidateTable(params.EvalContext().Settings); err != nil {
-		return err
+		return nil, err
 	}
 
+	key := tableKey{parentID: dbDesc.ID, name: name.Table()}.Key()
 	if err = params.p.createDescriptorWithID(params.ctx, key, id, &desc); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Initialize the sequence value.
@@ -93,16 +113,16 @@ func (n *createSequenceNode) startExec(params runParams) error {
 	b := &client.Batch{}
 	b.Inc(seqValueKey, desc.SequenceOpts.Start-desc.SequenceOpts.Increment)
 	if err := params.p.txn.Run(params.ctx, b); err != nil {
-		return err
+		return nil, err
 	}
 
 	if err := desc.Validate(params.ctx, params.p.txn, params.extendedEvalCtx.Settings); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Log Create Sequence event. This is an auditable log event and is
 	// recorded in the same transaction as the table descriptor update.
-	return MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
+	if err := MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
 		params.ctx,
 		params.p.txn,
 		EventLogCreateSequence,
@@ -112,8 +132,11 @@ func (n *createSequenceNode) startExec(params runParams) error {
 			SequenceName string
 			Statement    string
 			User         string
-		}{n.n.Name.TableName().FQString(), n.n.String(), params.SessionData().User},
-	)
+		}{name.FQString(), statement, params.SessionData().User},
+	); err != nil {
+		return nil, err
+	}
+	return &desc, nil
 }
 
 func (*createSequenceNode) Next(runParams) (bool, error) { return false, nil }
@@ -125,9 +148,10 @@ const (
 	sequenceColumnName = "value"
 )
 
-func (n *createSequenceNode) makeSequenceTableDesc(
+func makeSequenceTableDesc(
 	params runParams,
 	sequenceName string,
+	sequenceOptions tree.SequenceOptions,
 	parentID sqlbase.ID,
 	id sqlbase.ID,
 	privileges *sqlbase.PrivilegeDescriptor,
@@ -166,7 +190,7 @@ func (n *createSequenceNode) makeSequenceTableDesc(
 	opts := &sqlbase.TableDescriptor_SequenceOpts{
 		Increment: 1,
 	}
-	err := assignSequenceOptions(opts, n.n.Options, true /* setDefaults */)
+	err := assignSequenceOptions(opts, sequenceOptions, true /* setDefaults */)
 	if err != nil {
 		return desc, err
 	}
@@ -135,19 +135,59 @@ func (n *createTableNode) startExec(params runParams) error {
 
 	var desc sqlbase.TableDescriptor
 	var affected map[sqlbase.ID]*sqlbase.TableDescriptor
+	// ownedSeqIDs maps SERIAL columns to the sequences created for them.
+	ownedSeqIDs := make(map[tree.Name]sqlbase.ID)
 	creationTime := params.p.txn.CommitTimestamp()
 	if n.n.As() {
 		desc, err = makeTableDescIfAs(
 			n.n, n.dbDesc.ID, id, creationTime, planColumns(n.sourcePlan),
 			privs, &params.p.semaCtx, params.EvalContext())
 	} else {
+		createTable := n.n
+		for i, def := range n.n.Defs {
+			d, ok := def.(*tree.ColumnTableDef)
+			if !ok {
+				continue
+			}
+			newDef, seqDesc, err := processSerialInColumnDef(
+				params, d, n.dbDesc, n.n.Table.TableName(), n.n.String(),
+			)
+			if err != nil {
+				return err
+			}
+			if seqDesc == nil {
+				continue
+			}
+			if createTable == n.n {
+				// Copy the statement so as to leave the AST unmodified.
+				cp := *n.n
+				cp.Defs = append(tree.TableDefs(nil), n.n.Defs...)
+				createTable = &cp
+			}
+			createTable.Defs[i] = newDef
+			ownedSeqIDs[d.Name] = seqDesc.ID
+		}
 		affected = make(map[sqlbase.ID]*sqlbase.TableDescriptor)
-		desc, err = params.p.makeTableDesc(params.ctx, n.n, n.dbDesc.ID, id, creationTime, privs, affected)
+		desc, err = params.p.makeTableDesc(params.ctx, createTable, n.dbDesc.ID, id, creationTime, privs, affected)
 	}
 	if err != nil {
 		return err
 	}
 
+	// Make the SERIAL columns own their sequences. The sequence descriptors
+	// are in affected, since the columns' DEFAULT expressions use them.
+	for colName, seqID := range ownedSeqIDs {
+		c, err := desc.FindActiveColumnByName(string(colName))
+		if err != nil {
+			return err
+		}
+		col, err := desc.FindColumnByID(c.ID)
+		if err != nil {
+			return err
+		}
+		setSequenceOwner(affected[seqID], &desc, col)
+	}
+
 	// We need to validate again after adding the FKs.
 	// Only validate the table because backreferences aren't created yet.
 	// Everything is validated below.
@@ -391,6 +431,11 @@ func matchesIndex(
 	if len(cols) > len(idx.ColumnIDs) || (exact && len(cols) != len(idx.ColumnIDs)) {
 		return false
 	}
+	// Partial indexes don't contain all the rows needed by the foreign key
+	// checks.
+	if idx.IsPartial() {
+		return false
+	}
 
 	for i := range cols {
 		if cols[i].ID != idx.ColumnIDs[i] {
@@ -1089,6 +1134,13 @@ func MakeTableDesc(
 			if err := idx.FillColumns(d.Columns); err != nil {
 				return desc, err
 			}
+			if d.Predicate != nil {
+				pred, err := makePartialIndexPredicate(ctx, &desc, d.Predicate, *tableName, semaCtx, evalCtx)
+				if err != nil {
+					return desc, err
+				}
+				idx.Predicate = pred
+			}
 			if d.PartitionBy != nil {
 				partitioning, err := CreatePartitioning(ctx, st, evalCtx, &desc, &idx, d.PartitionBy)
 				if err != nil {
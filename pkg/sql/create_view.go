@@ -180,6 +180,16 @@ func (n *createViewNode) startExec(params runParams) error {
 		return err
 	}
 
+	if desc.MaterializedView() {
+		// Populate the view in the same transaction that creates it.
+		if err := refreshMaterializedView(
+			params.ctx, params.extendedEvalCtx.ExecCfg.InternalExecutor,
+			params.extendedEvalCtx.NodeID, params.p.txn, &desc, false, /* concurrently */
+		); err != nil {
+			return err
+		}
+	}
+
 	// Log Create View event. This is an auditable log event and is
 	// recorded in the same transaction as the table descriptor update.
 	return MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
@@ -219,6 +229,9 @@ func (n *createViewNode) makeViewTableDesc(
 	desc := InitTableDescriptor(id, parentID, viewName,
 		params.p.txn.CommitTimestamp(), privileges)
 	desc.ViewQuery = tree.AsStringWithFlags(n.n.AsSource, tree.FmtParsable)
+	// A materialized view gets a hidden rowid primary key from AllocateIDs,
+	// since its rows are stored like a table's.
+	desc.IsMaterializedView = n.n.Materialized
 	for i, colRes := range resultColumns {
 		colType, err := coltypes.DatumTypeToColumnType(colRes.Typ)
 		if err != nil {
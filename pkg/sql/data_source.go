@@ -111,7 +111,7 @@ func (p *planner) getDataSource(
 		}
 
 		// If there's a CTE with this name, it takes priority over the normal flow.
-		ds, foundCTE, err := p.getCTEDataSource(tn)
+		ds, foundCTE, err := p.getCTEDataSource(ctx, tn)
 		if foundCTE || err != nil {
 			return ds, err
 		}
@@ -173,6 +173,12 @@ func (p *planner) getDataSource(
 			hints = t.Hints
 		}
 
+		if t.Lateral {
+			return planDataSource{}, pgerror.UnimplementedWithIssueError(
+				24560, "LATERAL is only supported by the cost-based optimizer",
+			)
+		}
+
 		src, err := p.getDataSource(ctx, t.Expr, hints, scanVisibility)
 		if err != nil {
 			return src, err
@@ -330,7 +336,7 @@ func (p *planner) getPlanForDesc(
 	hints *tree.IndexHints,
 	colCfg scanColumnsConfig,
 ) (planDataSource, error) {
-	if desc.IsView() {
+	if desc.IsView() && !desc.MaterializedView() {
 		if colCfg.wantedColumns != nil {
 			return planDataSource{},
 				errors.Errorf("cannot specify an explicit column list when accessing a view by reference")
@@ -340,12 +346,13 @@ func (p *planner) getPlanForDesc(
 	if desc.IsSequence() {
 		return p.getSequenceSource(ctx, *tn, desc)
 	}
-	if !desc.IsTable() {
+	if !desc.IsTable() && !desc.MaterializedView() {
 		return planDataSource{}, errors.Errorf(
 			"unexpected table descriptor of type %s for %q", desc.TypeName(), tree.ErrString(tn))
 	}
 
-	// This name designates a real table.
+	// This name designates a real table, or a materialized view whose rows
+	// are stored like a table's.
 	scan := p.Scan()
 	if err := scan.initTable(ctx, p, desc, hints, colCfg); err != nil {
 		return planDataSource{}, err
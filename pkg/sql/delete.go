@@ -298,6 +298,9 @@ func (d *deleteNode) BatchedNext(params runParams) (bool, error) {
 		d.run.done = true
 	}
 
+	// Possibly initiate a refresh of the table statistics.
+	params.extendedEvalCtx.ExecCfg.notifyMutation(d.run.td.tableDesc().ID, d.run.rowCount)
+
 	return d.run.rowCount > 0, nil
 }
 
@@ -404,7 +407,12 @@ func (d *deleteNode) fastDelete(params runParams, scan *scanNode) error {
 	var err error
 	d.run.rowCount, err = d.run.td.fastDelete(
 		params.ctx, scan, d.run.autoCommit, d.run.traceKV)
-	return err
+	if err != nil {
+		return err
+	}
+	// Possibly initiate a refresh of the table statistics.
+	params.extendedEvalCtx.ExecCfg.notifyMutation(d.run.td.tableDesc().ID, d.run.rowCount)
+	return nil
 }
 
 // enableAutoCommit is part of the autoCommitNode interface.
@@ -419,6 +419,30 @@ func (dsp *DistSQLPlanner) checkSupportForNode(node planNode) (distRecommendatio
 	case *projectSetNode:
 		return dsp.checkSupportForNode(n.source)
 
+	case *windowNode:
+		rec, err := dsp.checkSupportForNode(n.plan)
+		if err != nil {
+			return 0, err
+		}
+		for _, windowFn := range n.funcs {
+			if err := checkSupportForWindowFn(windowFn); err != nil {
+				return 0, err
+			}
+		}
+		for _, render := range n.windowRender {
+			if err := dsp.checkExpr(render); err != nil {
+				return 0, err
+			}
+		}
+		// If we have PARTITION BY clauses, we distribute the computation of the
+		// window functions by partition.
+		for _, windowFn := range n.funcs {
+			if len(windowFn.partitionIdxs) > 0 {
+				return rec.compose(shouldDistribute), nil
+			}
+		}
+		return rec, nil
+
 	default:
 		return 0, newQueryNotSupportedErrorf("unsupported node %T", node)
 	}
@@ -434,6 +458,11 @@ type planningCtx struct {
 	// physicalPlan we generate with this context.
 	// Nodes that fail a health check have empty addresses.
 	nodeAddresses map[roachpb.NodeID]string
+
+	// collectCPUProfile, if set, asks the remote nodes running parts of the
+	// plan to collect CPU profiles while they do so. The profiles are returned
+	// to the distSQLReceiver.
+	collectCPUProfile bool
 }
 
 func (p *planningCtx) EvalContext() *tree.EvalContext {
@@ -2132,6 +2161,9 @@ func (dsp *DistSQLPlanner) createPlanForNode(
 	case *projectSetNode:
 		plan, err = dsp.createPlanForProjectSet(planCtx, n)
 
+	case *windowNode:
+		plan, err = dsp.createPlanForWindow(planCtx, n)
+
 	default:
 		panic(fmt.Sprintf("unsupported node type %T", n))
 	}
@@ -2344,6 +2376,252 @@ func (dsp *DistSQLPlanner) createPlanForProjectSet(
 	return plan, nil
 }
 
+// windowFnSpecFunc returns a WindowFn spec with the AggregateFunc or WindowFunc
+// field set according to the function applied by the given windowFuncHolder.
+func windowFnSpecFunc(windowFn *windowFuncHolder) (distsqlrun.WindowerSpec_WindowFn, error) {
+	var spec distsqlrun.WindowerSpec_WindowFn
+	// Convert the function to the enum value with the same string
+	// representation.
+	funcStr := strings.ToUpper(windowFn.expr.Func.String())
+	if funcIdx, ok := distsqlrun.WindowerSpec_WindowFunc_value[funcStr]; ok {
+		fn := distsqlrun.WindowerSpec_WindowFunc(funcIdx)
+		spec.WindowFunc = &fn
+	} else if funcIdx, ok := distsqlrun.AggregatorSpec_Func_value[funcStr]; ok {
+		fn := distsqlrun.AggregatorSpec_Func(funcIdx)
+		spec.AggregateFunc = &fn
+	} else {
+		return spec, newQueryNotSupportedErrorf("window function %s is not supported by distsql", funcStr)
+	}
+	return spec, nil
+}
+
+// checkSupportForWindowFn returns an error if the window function applied by
+// the given windowFuncHolder cannot be computed by a windower.
+func checkSupportForWindowFn(windowFn *windowFuncHolder) error {
+	spec, err := windowFnSpecFunc(windowFn)
+	if err != nil {
+		return err
+	}
+	argTypes := make([]sqlbase.ColumnType, len(windowFn.args))
+	for i, arg := range windowFn.args {
+		argTypes[i], err = sqlbase.DatumTypeToColumnType(arg.(tree.TypedExpr).ResolvedType())
+		if err != nil {
+			return newQueryNotSupportedError(err.Error())
+		}
+	}
+	if _, _, _, err := distsqlrun.GetWindowFunctionInfo(spec, argTypes...); err != nil {
+		return newQueryNotSupportedError(err.Error())
+	}
+	return nil
+}
+
+// createWindowFnSpec creates the WindowFn spec for the given windowFuncHolder.
+// The column indices of the windowFuncHolder refer to the wrapped plan; they
+// are mapped to stream columns using planToStreamColMap.
+func createWindowFnSpec(
+	planCtx *planningCtx,
+	windowFn *windowFuncHolder,
+	frame *tree.WindowFrame,
+	planToStreamColMap []int,
+) (distsqlrun.WindowerSpec_WindowFn, error) {
+	spec, err := windowFnSpecFunc(windowFn)
+	if err != nil {
+		return spec, err
+	}
+	if windowFn.argCount > 0 {
+		spec.ArgIdxStart = uint32(planToStreamColMap[windowFn.argIdxStart])
+	}
+	spec.ArgCount = uint32(windowFn.argCount)
+	spec.Ordering = distsqlrun.ConvertToMappedSpecOrdering(windowFn.columnOrdering, planToStreamColMap)
+
+	if frame != nil {
+		startOffset, endOffset, err := evalWindowFrameOffsets(planCtx.EvalContext(), frame)
+		if err != nil {
+			return spec, err
+		}
+		// The frame enums of the spec mirror those of tree.WindowFrame.
+		spec.Frame = &distsqlrun.WindowerSpec_Frame{
+			Mode: distsqlrun.WindowerSpec_Frame_Mode(frame.Mode),
+			Start: distsqlrun.WindowerSpec_Frame_Bound{
+				BoundType: distsqlrun.WindowerSpec_Frame_BoundType(frame.Bounds.StartBound.BoundType),
+				Offset:    uint64(startOffset),
+			},
+		}
+		if frame.Bounds.EndBound != nil {
+			spec.Frame.End = &distsqlrun.WindowerSpec_Frame_Bound{
+				BoundType: distsqlrun.WindowerSpec_Frame_BoundType(frame.Bounds.EndBound.BoundType),
+				Offset:    uint64(endOffset),
+			}
+		}
+	}
+	return spec, nil
+}
+
+// createPlanForWindow creates a physical plan for a windowNode. Window
+// functions with the same PARTITION BY clause are computed by the same stage of
+// windowers; each stage appends a column per window function to its input
+// columns. The renders of the windowNode are then evaluated on top of the last
+// stage.
+func (dsp *DistSQLPlanner) createPlanForWindow(
+	planCtx *planningCtx, n *windowNode,
+) (physicalPlan, error) {
+	plan, err := dsp.createPlanForNode(planCtx, n.plan)
+	if err != nil {
+		return physicalPlan{}, err
+	}
+
+	// The windowers refer to the arguments of a window function as a range of
+	// columns, so we make sure the columns of the wrapped plan appear in the
+	// streams in order.
+	numSourceCols := len(plan.planToStreamColMap)
+	sourceCols := make([]uint32, numSourceCols)
+	for i, streamCol := range plan.planToStreamColMap {
+		if streamCol == -1 {
+			return physicalPlan{}, errors.Errorf("column %d of the wrapped plan not in stream", i)
+		}
+		sourceCols[i] = uint32(streamCol)
+	}
+	plan.AddProjection(sourceCols)
+	plan.planToStreamColMap = identityMap(plan.planToStreamColMap, numSourceCols)
+
+	// indexVarMap maps the columns of the wrapped plan followed by the results
+	// of the window functions (see windowNode.distSQLRenders) to stream columns.
+	indexVarMap := make([]int, numSourceCols+len(n.funcs))
+	identityMapInPlace(indexVarMap[:numSourceCols])
+
+	// Group the window functions by their PARTITION BY clause, in order of
+	// first appearance.
+	var partitionGroups [][]*windowFuncHolder
+	for _, windowFn := range n.funcs {
+		found := false
+		for i, group := range partitionGroups {
+			if reflect.DeepEqual(group[0].partitionIdxs, windowFn.partitionIdxs) {
+				partitionGroups[i] = append(group, windowFn)
+				found = true
+				break
+			}
+		}
+		if !found {
+			partitionGroups = append(partitionGroups, []*windowFuncHolder{windowFn})
+		}
+	}
+
+	for _, group := range partitionGroups {
+		windowerSpec := distsqlrun.WindowerSpec{
+			PartitionBy: make([]uint32, len(group[0].partitionIdxs)),
+			WindowFns:   make([]distsqlrun.WindowerSpec_WindowFn, len(group)),
+		}
+		for i, idx := range group[0].partitionIdxs {
+			windowerSpec.PartitionBy[i] = uint32(plan.planToStreamColMap[idx])
+		}
+
+		outputTypes := append([]sqlbase.ColumnType(nil), plan.ResultTypes...)
+		for i, windowFn := range group {
+			fnSpec, err := createWindowFnSpec(
+				planCtx, windowFn, n.run.windowFrames[windowFn.funcIdx], plan.planToStreamColMap,
+			)
+			if err != nil {
+				return physicalPlan{}, err
+			}
+			argTypes := plan.ResultTypes[fnSpec.ArgIdxStart : fnSpec.ArgIdxStart+fnSpec.ArgCount]
+			_, _, retType, err := distsqlrun.GetWindowFunctionInfo(fnSpec, argTypes...)
+			if err != nil {
+				return physicalPlan{}, err
+			}
+			windowerSpec.WindowFns[i] = fnSpec
+			indexVarMap[numSourceCols+windowFn.funcIdx] = len(outputTypes)
+			outputTypes = append(outputTypes, retType)
+		}
+
+		dsp.addWindowers(&plan, &windowerSpec, outputTypes)
+	}
+
+	renders, err := n.distSQLRenders()
+	if err != nil {
+		return physicalPlan{}, err
+	}
+	types, err := getTypesForPlanResult(n, nil /* planToStreamColMap */)
+	if err != nil {
+		return physicalPlan{}, err
+	}
+	plan.AddRendering(renders, planCtx.EvalContext(), indexVarMap, types)
+	plan.planToStreamColMap = identityMap(plan.planToStreamColMap, len(renders))
+
+	return plan, nil
+}
+
+// addWindowers adds a stage of windowers with the given spec to the plan.
+func (dsp *DistSQLPlanner) addWindowers(
+	p *physicalPlan, spec *distsqlrun.WindowerSpec, outputTypes []sqlbase.ColumnType,
+) {
+	if len(spec.PartitionBy) == 0 || len(p.ResultRouters) == 1 {
+		// No PARTITION BY, or we have a single stream. Use a single windower. If
+		// the previous stage was all on a single node, put the windower there.
+		// Otherwise, bring the results back on this node.
+		node := dsp.nodeDesc.NodeID
+		if len(p.ResultRouters) == 1 {
+			node = p.Processors[p.ResultRouters[0]].Node
+		}
+		p.AddSingleGroupStage(
+			node,
+			distsqlrun.ProcessorCoreUnion{Windower: spec},
+			distsqlrun.PostProcessSpec{},
+			outputTypes,
+		)
+		return
+	}
+
+	// We distribute (by the PARTITION BY columns) to multiple processors.
+
+	// Set up the output routers from the previous stage.
+	for _, resultProc := range p.ResultRouters {
+		p.Processors[resultProc].Spec.Output[0] = distsqlrun.OutputRouterSpec{
+			Type:        distsqlrun.OutputRouterSpec_BY_HASH,
+			HashColumns: spec.PartitionBy,
+		}
+	}
+
+	stageID := p.NewStageID()
+
+	// We have one windower for each result router. This is a somewhat arbitrary
+	// decision; we could have a different number of nodes working on this
+	// stage.
+	pIdxStart := distsqlplan.ProcessorIdx(len(p.Processors))
+	for _, resultProc := range p.ResultRouters {
+		proc := distsqlplan.Processor{
+			Node: p.Processors[resultProc].Node,
+			Spec: distsqlrun.ProcessorSpec{
+				Input: []distsqlrun.InputSyncSpec{{
+					// The other fields will be filled in by mergeResultStreams.
+					ColumnTypes: p.ResultTypes,
+				}},
+				Core: distsqlrun.ProcessorCoreUnion{Windower: spec},
+				Output: []distsqlrun.OutputRouterSpec{{
+					Type: distsqlrun.OutputRouterSpec_PASS_THROUGH,
+				}},
+				StageID: stageID,
+			},
+		}
+		p.AddProcessor(proc)
+	}
+
+	// Connect the streams.
+	for bucket := 0; bucket < len(p.ResultRouters); bucket++ {
+		pIdx := pIdxStart + distsqlplan.ProcessorIdx(bucket)
+		p.MergeResultStreams(p.ResultRouters, bucket, p.MergeOrdering, pIdx, 0)
+	}
+
+	// Set the new result routers.
+	for i := 0; i < len(p.ResultRouters); i++ {
+		p.ResultRouters[i] = pIdxStart + distsqlplan.ProcessorIdx(i)
+	}
+
+	p.ResultTypes = outputTypes
+	// The rows of a partition are spread out over the windowers, so the
+	// streams are no longer ordered.
+	p.SetMergeOrdering(distsqlrun.Ordering{})
+}
+
 // isOnlyOnGateway returns true if a physical plan is executed entirely on the
 // gateway node.
 func (dsp *DistSQLPlanner) isOnlyOnGateway(plan *physicalPlan) bool {
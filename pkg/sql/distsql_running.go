@@ -116,8 +116,11 @@ func (dsp *DistSQLPlanner) Run(
 	ctx := planCtx.ctx
 
 	var txnProto *roachpb.Transaction
+	var txnCoordMeta *roachpb.TxnCoordMeta
 	if txn != nil {
 		txnProto = txn.Proto()
+		meta := txn.GetStrippedTxnCoordMeta()
+		txnCoordMeta = &meta
 	}
 
 	if err := planCtx.sanityCheckAddresses(); err != nil {
@@ -161,10 +164,12 @@ func (dsp *DistSQLPlanner) Run(
 			continue
 		}
 		req := &distsqlrun.SetupFlowRequest{
-			Version:     distsqlrun.Version,
-			Txn:         txnProto,
-			Flow:        flowSpec,
-			EvalContext: evalCtxProto,
+			Version:           distsqlrun.Version,
+			Txn:               txnProto,
+			TxnCoordMeta:      txnCoordMeta,
+			Flow:              flowSpec,
+			EvalContext:       evalCtxProto,
+			CollectCPUProfile: planCtx.collectCPUProfile,
 		}
 		runReq := runnerRequest{
 			ctx:         ctx,
@@ -201,12 +206,13 @@ func (dsp *DistSQLPlanner) Run(
 
 	// Set up the flow on this node.
 	localReq := distsqlrun.SetupFlowRequest{
-		Version:     distsqlrun.Version,
-		Txn:         txnProto,
-		Flow:        flows[thisNodeID],
-		EvalContext: evalCtxProto,
+		Version:      distsqlrun.Version,
+		Txn:          txnProto,
+		TxnCoordMeta: txnCoordMeta,
+		Flow:         flows[thisNodeID],
+		EvalContext:  evalCtxProto,
 	}
-	ctx, flow, err := dsp.distSQLSrv.SetupSyncFlow(ctx, evalCtx.Mon, &localReq, recv)
+	ctx, flow, err := dsp.distSQLSrv.SetupSyncFlow(ctx, evalCtx.Mon, evalCtx.DiskMonitor, &localReq, recv)
 	if err != nil {
 		recv.SetError(err)
 		return
@@ -276,6 +282,10 @@ type distSQLReceiver struct {
 	// A handler for clock signals arriving from remote nodes. This should update
 	// this node's clock.
 	updateClock func(observedTs hlc.Timestamp)
+
+	// cpuProfiles accumulates the CPU profiles sent by remote nodes, if they
+	// were asked to collect them (see planningCtx.collectCPUProfile).
+	cpuProfiles []distsqlrun.RemoteProducerMetadata_CPUProfile
 }
 
 // errWrap is a container for an error, for use with atomic.Value, which
@@ -415,6 +425,9 @@ func (r *distSQLReceiver) Push(
 				r.resultWriter.SetError(errors.Errorf("error ingesting remote spans: %s", err))
 			}
 		}
+		if meta.CPUProfile != nil {
+			r.cpuProfiles = append(r.cpuProfiles, *meta.CPUProfile)
+		}
 		return r.status
 	}
 	if r.resultWriter.Err() == nil && r.txnAbortedErr.Load() != nil {
@@ -68,7 +68,9 @@ func runTestFlow(
 
 	var rowBuf distsqlrun.RowBuffer
 
-	ctx, flow, err := distSQLSrv.SetupSyncFlow(context.TODO(), distSQLSrv.ParentMemoryMonitor, &req, &rowBuf)
+	ctx, flow, err := distSQLSrv.SetupSyncFlow(
+		context.TODO(), distSQLSrv.ParentMemoryMonitor, nil /* diskMonitor */, &req, &rowBuf,
+	)
 	if err != nil {
 		t.Fatal(err)
 	}
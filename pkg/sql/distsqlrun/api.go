@@ -26,6 +26,7 @@ func MakeEvalContext(evalCtx tree.EvalContext) EvalContext {
 		Database:           evalCtx.SessionData.Database,
 		User:               evalCtx.SessionData.User,
 		ApplicationName:    evalCtx.SessionData.ApplicationName,
+		Vectorize:          int64(evalCtx.SessionData.Vectorize),
 	}
 
 	// Populate the search path.
@@ -375,6 +375,9 @@ type ProducerMetadata struct {
 	// inputs. It is used in tests to verify that all metadata is forwarded
 	// exactly once to the receiver on the gateway node.
 	RowNum *RemoteProducerMetadata_RowNum
+	// CPUProfile is a CPU profile collected by a node that was asked to profile
+	// its part of the flow (see SetupFlowRequest.CollectCPUProfile).
+	CPUProfile *RemoteProducerMetadata_CPUProfile
 }
 
 // RowChannel is a thin layer over a RowChannelMsg channel, which can be used to
@@ -0,0 +1,146 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlrun
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/exec"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/pkg/errors"
+)
+
+// columnarizer is an exec.Operator that turns the rows of a RowSource into
+// column batches. It is the leaf of every tree of vectorized operators.
+//
+// The columnarizer doesn't forward the metadata it receives from its input;
+// it accumulates it to be returned by the materializer at the root of the
+// operator tree. An error stops the columnarizer: it isn't returned through
+// the operator tree, but as metadata.
+type columnarizer struct {
+	input RowSource
+
+	typs      []sqlbase.ColumnType
+	execTypes []types.T
+
+	batch exec.ColBatch
+	alloc sqlbase.DatumAlloc
+
+	// meta accumulates the metadata received from the input.
+	meta []ProducerMetadata
+	// done is set once the input is exhausted or has returned an error.
+	done bool
+}
+
+var _ exec.Operator = &columnarizer{}
+
+// newColumnarizer creates a columnarizer. It returns an error if the vectorized
+// engine doesn't support the types of the input's columns.
+func newColumnarizer(input RowSource) (*columnarizer, error) {
+	c := &columnarizer{
+		input: input,
+		typs:  input.OutputTypes(),
+	}
+	c.execTypes = types.FromColumnTypes(c.typs)
+	for i, t := range c.execTypes {
+		if t == types.Unhandled {
+			return nil, errors.Errorf("unsupported column type %s", c.typs[i].SQLString())
+		}
+	}
+	return c, nil
+}
+
+// Init is part of the exec.Operator interface. The input needs to have been
+// started separately.
+func (c *columnarizer) Init() {
+	c.batch = exec.NewMemBatch(c.execTypes)
+}
+
+// Next is part of the exec.Operator interface.
+func (c *columnarizer) Next() exec.ColBatch {
+	c.batch.SetSelection(false)
+	vecs := c.batch.ColVecs()[:len(c.typs)]
+	for _, vec := range vecs {
+		vec.UnsetNulls()
+	}
+
+	n := 0
+	for !c.done && n < exec.ColBatchSize {
+		row, meta := c.input.Next()
+		if meta != nil {
+			c.meta = append(c.meta, *meta)
+			if meta.Err != nil {
+				c.done = true
+			}
+			continue
+		}
+		if row == nil {
+			c.done = true
+			break
+		}
+		for j, vec := range vecs {
+			if err := row[j].EnsureDecoded(&c.typs[j], &c.alloc); err != nil {
+				exec.PanicError(err)
+			}
+			setVecValue(vec, uint16(n), row[j].Datum)
+		}
+		n++
+	}
+	c.batch.SetLength(uint16(n))
+	return c.batch
+}
+
+// popMeta returns the oldest piece of metadata accumulated by the
+// columnarizer, or nil if there is none.
+func (c *columnarizer) popMeta() *ProducerMetadata {
+	if len(c.meta) == 0 {
+		return nil
+	}
+	meta := &c.meta[0]
+	c.meta = c.meta[1:]
+	return meta
+}
+
+// setVecValue sets the value at index idx of vec to the given datum.
+func setVecValue(vec exec.ColVec, idx uint16, d tree.Datum) {
+	if d == tree.DNull {
+		vec.SetNull(idx)
+		return
+	}
+	switch vec.Type() {
+	case types.Bool:
+		vec.Bool()[idx] = bool(*d.(*tree.DBool))
+	case types.Bytes:
+		col := vec.Bytes()
+		switch t := d.(type) {
+		case *tree.DString:
+			col[idx] = append(col[idx][:0], *t...)
+		case *tree.DBytes:
+			col[idx] = append(col[idx][:0], *t...)
+		default:
+			panic(fmt.Sprintf("unexpected datum %s for type %s", d, vec.Type()))
+		}
+	case types.Decimal:
+		vec.Decimal()[idx].Set(&d.(*tree.DDecimal).Decimal)
+	case types.Int64:
+		vec.Int64()[idx] = int64(*d.(*tree.DInt))
+	case types.Float64:
+		vec.Float64()[idx] = float64(*d.(*tree.DFloat))
+	default:
+		panic(fmt.Sprintf("unhandled type %s", vec.Type()))
+	}
+}
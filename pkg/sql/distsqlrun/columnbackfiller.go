@@ -17,18 +17,43 @@ package distsqlrun
 import (
 	"context"
 
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/backfill"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 )
 
+// columnBackfillMaxRowsPerSecond limits the rate at which every column
+// backfiller rewrites rows, to bound the impact of a backfill on foreground
+// traffic. Column backfills rewrite the live rows of the table in place, so
+// unlike bulk ingestion they can't go through AddSSTable without clobbering
+// concurrent writes; throttling them is the way to make them cheaper.
+var columnBackfillMaxRowsPerSecond = settings.RegisterValidatedIntSetting(
+	"schemachanger.backfiller.max_column_rows_per_second",
+	"the maximum number of rows per second each column backfill processor rewrites (0 disables the limit)",
+	0,
+	func(v int64) error {
+		if v < 0 {
+			return errors.Errorf("cannot set schemachanger.backfiller.max_column_rows_per_second to a negative value: %d", v)
+		}
+		return nil
+	},
+)
+
 // columnBackfiller is a processor for backfilling columns.
 type columnBackfiller struct {
 	backfiller
 
 	backfill.ColumnBackfiller
+
+	// limiter enforces columnBackfillMaxRowsPerSecond. It's nil until the
+	// setting is first found to be non-zero.
+	limiter *rate.Limiter
 }
 
 var _ Processor = &columnBackfiller{}
@@ -68,6 +93,9 @@ func (cb *columnBackfiller) runChunk(
 	chunkSize int64,
 	readAsOf hlc.Timestamp,
 ) (roachpb.Key, error) {
+	if err := cb.throttle(ctx, chunkSize); err != nil {
+		return nil, err
+	}
 	tableDesc := cb.backfiller.spec.Table
 	var key roachpb.Key
 	err := cb.flowCtx.clientDB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
@@ -94,3 +122,23 @@ func (cb *columnBackfiller) runChunk(
 	})
 	return key, err
 }
+
+// throttle blocks until a chunk of chunkSize rows fits in the rate allowed
+// by the schemachanger.backfiller.max_column_rows_per_second setting.
+func (cb *columnBackfiller) throttle(ctx context.Context, chunkSize int64) error {
+	maxRate := columnBackfillMaxRowsPerSecond.Get(&cb.flowCtx.Settings.SV)
+	if maxRate == 0 {
+		return nil
+	}
+	if cb.limiter == nil {
+		// The burst must fit a whole chunk for WaitN to succeed.
+		burst := maxRate
+		if burst < chunkSize {
+			burst = chunkSize
+		}
+		cb.limiter = rate.NewLimiter(rate.Limit(maxRate), int(burst))
+	} else {
+		cb.limiter.SetLimit(rate.Limit(maxRate))
+	}
+	return cb.limiter.WaitN(ctx, int(chunkSize))
+}
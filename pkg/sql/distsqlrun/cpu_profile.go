@@ -0,0 +1,63 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlrun
+
+import (
+	"bytes"
+	"runtime/pprof"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// CPUProfiler collects a CPU profile of the process while a statement (or
+// the part of it scheduled on this node) executes.
+//
+// The Go runtime only supports one CPU profile per process at a time, so the
+// profile covers everything the node did while it was running, not only the
+// work done on behalf of the statement, and profiling fails if another profile
+// is already being collected.
+type CPUProfiler struct {
+	mu struct {
+		syncutil.Mutex
+		// buf is nil once the profile has been stopped.
+		buf *bytes.Buffer
+	}
+}
+
+// StartCPUProfile starts collecting a CPU profile. The returned profiler must
+// be stopped.
+func StartCPUProfile() (*CPUProfiler, error) {
+	var buf bytes.Buffer
+	if err := pprof.StartCPUProfile(&buf); err != nil {
+		return nil, err
+	}
+	p := &CPUProfiler{}
+	p.mu.buf = &buf
+	return p, nil
+}
+
+// Stop stops the profile and returns it. Only the first call returns the
+// profile; subsequent calls return nil.
+func (p *CPUProfiler) Stop() []byte {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.mu.buf == nil {
+		return nil
+	}
+	pprof.StopCPUProfile()
+	res := p.mu.buf.Bytes()
+	p.mu.buf = nil
+	return res
+}
@@ -0,0 +1,46 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlrun
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestCPUProfiler(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	p, err := StartCPUProfile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only one profile can be collected at a time.
+	if _, err := StartCPUProfile(); err == nil {
+		t.Fatal("expected error starting a second CPU profile")
+	}
+	if profile := p.Stop(); len(profile) == 0 {
+		t.Fatal("expected a non-empty profile")
+	}
+	if profile := p.Stop(); profile != nil {
+		t.Fatalf("expected no profile from second Stop, got %d bytes", len(profile))
+	}
+	// Once stopped, a new profile can be started.
+	p, err = StartCPUProfile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = p.Stop()
+}
@@ -101,6 +101,10 @@ type FlowCtx struct {
 
 	// JobRegistry is used during backfill to load jobs which keep state.
 	JobRegistry *jobs.Registry
+
+	// cpuProfiler, if set, is collecting a CPU profile for the duration of the
+	// flow. The profile is sent to the gateway by the first outbox to finish.
+	cpuProfiler *CPUProfiler
 }
 
 // NewEvalCtx returns a modifiable copy of the FlowCtx's EvalContext.
@@ -528,6 +532,11 @@ func (f *Flow) Cleanup(ctx context.Context) {
 	if log.V(1) {
 		log.Infof(ctx, "cleaning up")
 	}
+	if f.cpuProfiler != nil {
+		// Make sure the profile is stopped if no outbox sent it (for example
+		// because the flow was canceled).
+		_ = f.cpuProfiler.Stop()
+	}
 	sp := opentracing.SpanFromContext(ctx)
 	sp.Finish()
 	if f.status != FlowNotStarted {
@@ -294,6 +294,35 @@ func (d *ProjectSetSpec) summary() (string, []string) {
 	return "ProjectSet", details
 }
 
+// summary implements the diagramCellType interface.
+func (w *WindowerSpec) summary() (string, []string) {
+	details := make([]string, 0, len(w.WindowFns)+1)
+	if len(w.PartitionBy) > 0 {
+		details = append(details, fmt.Sprintf("PARTITION BY: %s", colListStr(w.PartitionBy)))
+	}
+	for _, fn := range w.WindowFns {
+		var buf bytes.Buffer
+		if fn.AggregateFunc != nil {
+			buf.WriteString(fn.AggregateFunc.String())
+		} else if fn.WindowFunc != nil {
+			buf.WriteString(fn.WindowFunc.String())
+		}
+		buf.WriteByte('(')
+		for i := uint32(0); i < fn.ArgCount; i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			fmt.Fprintf(&buf, "@%d", fn.ArgIdxStart+i+1)
+		}
+		buf.WriteByte(')')
+		if len(fn.Ordering.Columns) > 0 {
+			fmt.Fprintf(&buf, " ORDER BY %s", fn.Ordering.diagramString())
+		}
+		details = append(details, buf.String())
+	}
+	return "Windower", details
+}
+
 // summary implements the diagramCellType interface.
 func (s *SamplerSpec) summary() (string, []string) {
 	details := []string{fmt.Sprintf("SampleSize: %d", s.SampleSize)}
@@ -610,6 +639,105 @@ func GeneratePlanDiagramURLWithSpans(
 	return encodeJSONToURL(json)
 }
 
+// PlanTreeEntry is a row of the tree rendering of a physical plan. Rows with a
+// Processor are the processors of the plan, at the given depth in the tree;
+// the rows that follow a processor describe it.
+type PlanTreeEntry struct {
+	Level       int
+	Processor   string
+	Field       string
+	Description string
+}
+
+// GeneratePlanTreeWithSpans renders the processors of a physical plan as a
+// tree rooted at the processor that returns the results, in which the children
+// of a processor are the processors that produce its inputs. Each processor is
+// annotated with the node it ran on and, if spans are provided, with the stats
+// extracted from the spans. There should be one FlowSpec per node, and the
+// function assumes that StreamIDs are unique across all flows.
+func GeneratePlanTreeWithSpans(
+	flows map[roachpb.NodeID]FlowSpec, spans []tracing.RecordedSpan,
+) ([]PlanTreeEntry, error) {
+	// We sort the flows by node because we want the tree to be deterministic.
+	nodeIDs := make([]int, 0, len(flows))
+	for n := range flows {
+		nodeIDs = append(nodeIDs, int(n))
+	}
+	sort.Ints(nodeIDs)
+
+	type treeProcessor struct {
+		nodeID roachpb.NodeID
+		spec   *ProcessorSpec
+	}
+	// producers maps streams to the processor that outputs them.
+	producers := make(map[StreamID]treeProcessor)
+	var root *treeProcessor
+	for _, nVal := range nodeIDs {
+		n := roachpb.NodeID(nVal)
+		procs := flows[n].Processors
+		for i := range procs {
+			p := treeProcessor{nodeID: n, spec: &procs[i]}
+			for _, r := range p.spec.Output {
+				for _, o := range r.Streams {
+					if o.Type == StreamEndpointSpec_SYNC_RESPONSE {
+						if root != nil {
+							return nil, errors.Errorf("multiple processors with SyncResponse")
+						}
+						root = &p
+					} else {
+						producers[o.StreamID] = p
+					}
+				}
+			}
+		}
+	}
+	if root == nil {
+		return nil, errors.Errorf("no processor with SyncResponse")
+	}
+
+	pidToStatDetails := extractStatsFromSpans(spans)
+	var res []PlanTreeEntry
+	addDetails := func(level int, details []string) {
+		for _, d := range details {
+			entry := PlanTreeEntry{Level: level, Description: d}
+			if i := strings.Index(d, ": "); i >= 0 {
+				entry.Field, entry.Description = d[:i], d[i+2:]
+			}
+			res = append(res, entry)
+		}
+	}
+	var visit func(p treeProcessor, level int) error
+	visit = func(p treeProcessor, level int) error {
+		title, details := p.spec.Core.GetValue().(diagramCellType).summary()
+		res = append(res, PlanTreeEntry{
+			Level:     level,
+			Processor: fmt.Sprintf("%s/%d", title, p.spec.ProcessorID),
+		})
+		res = append(res, PlanTreeEntry{
+			Level: level, Field: "node", Description: p.nodeID.String(),
+		})
+		addDetails(level, details)
+		addDetails(level, pidToStatDetails[int(p.spec.ProcessorID)])
+		addDetails(level, p.spec.Post.summary())
+		for _, input := range p.spec.Input {
+			for _, stream := range input.Streams {
+				child, ok := producers[stream.StreamID]
+				if !ok {
+					return errors.Errorf("stream %d has no source", stream.StreamID)
+				}
+				if err := visit(child, level+1); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if err := visit(*root, 0); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
 func encodeJSONToURL(json bytes.Buffer) (string, url.URL, error) {
 	var compressed bytes.Buffer
 	jsonStr := json.String()
@@ -25,6 +25,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/gogo/protobuf/types"
 )
 
 // compareDiagrams verifies that two JSON strings decode to equal diagramData
@@ -400,3 +402,73 @@ func TestPlanDiagramJoin(t *testing.T) {
 
 	compareDiagrams(t, buf.String(), expected)
 }
+
+func TestPlanTree(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	desc := &sqlbase.TableDescriptor{Name: "Table"}
+	tr := TableReaderSpec{Table: *desc}
+	reader := func(streamID StreamID, pid int32) ProcessorSpec {
+		return ProcessorSpec{
+			Core: ProcessorCoreUnion{TableReader: &tr},
+			Output: []OutputRouterSpec{{
+				Type:    OutputRouterSpec_PASS_THROUGH,
+				Streams: []StreamEndpointSpec{{StreamID: streamID}},
+			}},
+			ProcessorID: pid,
+		}
+	}
+
+	flows := make(map[roachpb.NodeID]FlowSpec)
+	flows[2] = FlowSpec{Processors: []ProcessorSpec{reader(1, 1)}}
+	flows[1] = FlowSpec{Processors: []ProcessorSpec{
+		reader(0, 0),
+		{
+			Input: []InputSyncSpec{{
+				Type:    InputSyncSpec_UNORDERED,
+				Streams: []StreamEndpointSpec{{StreamID: 0}, {StreamID: 1}},
+			}},
+			Core: ProcessorCoreUnion{JoinReader: &JoinReaderSpec{Table: *desc}},
+			Post: PostProcessSpec{
+				Projection:    true,
+				OutputColumns: []uint32{2},
+			},
+			Output: []OutputRouterSpec{{
+				Type:    OutputRouterSpec_PASS_THROUGH,
+				Streams: []StreamEndpointSpec{{Type: StreamEndpointSpec_SYNC_RESPONSE}},
+			}},
+			ProcessorID: 2,
+		},
+	}}
+
+	stats, err := types.MarshalAny(&TableReaderStats{InputStats: InputStats{NumRows: 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	spans := []tracing.RecordedSpan{{
+		Tags:  map[string]string{processorIDTagKey: "1"},
+		Stats: stats,
+	}}
+
+	entries, err := GeneratePlanTreeWithSpans(flows, spans)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []PlanTreeEntry{
+		{Level: 0, Processor: "JoinReader/2"},
+		{Level: 0, Field: "node", Description: "1"},
+		{Level: 0, Description: "primary@Table"},
+		{Level: 0, Field: "Out", Description: "@3"},
+		{Level: 1, Processor: "TableReader/0"},
+		{Level: 1, Field: "node", Description: "1"},
+		{Level: 1, Description: "primary@Table"},
+		{Level: 1, Processor: "TableReader/1"},
+		{Level: 1, Field: "node", Description: "2"},
+		{Level: 1, Description: "primary@Table"},
+		{Level: 1, Field: "rows read", Description: "3"},
+		{Level: 1, Field: "stall time", Description: "0s"},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("expected:\n%+v\ngot:\n%+v", expected, entries)
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlrun
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/exec"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// materializer is a processor that runs a tree of vectorized operators and
+// turns the column batches it produces back into rows, to which it applies
+// the post-processing that the operators didn't take care of.
+//
+// The leaf of the operator tree is a columnarizer, whose input is the
+// materializer's input for the purposes of draining and which accumulates the
+// metadata forwarded by the materializer.
+type materializer struct {
+	processorBase
+
+	source *columnarizer
+	input  exec.Operator
+
+	// typs are the types of the columns of the batches produced by input.
+	typs []sqlbase.ColumnType
+
+	batch exec.ColBatch
+	// curIdx is the index in the selection of the batch of the next tuple to
+	// turn into a row.
+	curIdx    uint16
+	inputDone bool
+
+	row sqlbase.EncDatumRow
+	da  sqlbase.DatumAlloc
+}
+
+var _ Processor = &materializer{}
+var _ RowSource = &materializer{}
+
+const materializerProcName = "materializer"
+
+func newMaterializer(
+	flowCtx *FlowCtx,
+	processorID int32,
+	source *columnarizer,
+	input exec.Operator,
+	typs []sqlbase.ColumnType,
+	post *PostProcessSpec,
+	output RowReceiver,
+) (*materializer, error) {
+	m := &materializer{
+		source: source,
+		input:  input,
+		typs:   typs,
+		row:    make(sqlbase.EncDatumRow, len(typs)),
+	}
+	if err := m.init(
+		m,
+		post,
+		typs,
+		flowCtx,
+		processorID,
+		output,
+		nil, /* memMonitor */
+		procStateOpts{
+			inputsToDrain: []RowSource{source.input},
+			trailingMetaCallback: func() []ProducerMetadata {
+				// Return any metadata that the columnarizer accumulated after the
+				// last batch.
+				meta := m.source.meta
+				m.source.meta = nil
+				m.internalClose()
+				return meta
+			},
+		},
+	); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Start is part of the RowSource interface.
+func (m *materializer) Start(ctx context.Context) context.Context {
+	m.source.input.Start(ctx)
+	ctx = m.startInternal(ctx, materializerProcName)
+	m.input.Init()
+	return ctx
+}
+
+// Next is part of the RowSource interface.
+func (m *materializer) Next() (sqlbase.EncDatumRow, *ProducerMetadata) {
+	for m.state == stateRunning {
+		if meta := m.source.popMeta(); meta != nil {
+			if meta.Err != nil {
+				m.moveToDraining(nil /* err */)
+			}
+			return nil, meta
+		}
+
+		if m.batch == nil || m.curIdx >= m.batch.Length() {
+			if m.inputDone {
+				m.moveToDraining(nil /* err */)
+				break
+			}
+			if err := exec.CatchRuntimeError(m.nextBatch); err != nil {
+				m.moveToDraining(err)
+				break
+			}
+			continue
+		}
+
+		rowIdx := m.curIdx
+		if sel := m.batch.Selection(); sel != nil {
+			rowIdx = sel[m.curIdx]
+		}
+		m.curIdx++
+		for i, vec := range m.batch.ColVecs() {
+			m.row[i] = sqlbase.DatumToEncDatum(m.typs[i], m.vecDatum(vec, i, rowIdx))
+		}
+		if outRow := m.processRowHelper(m.row); outRow != nil {
+			return outRow, nil
+		}
+	}
+	return nil, m.drainHelper()
+}
+
+// nextBatch gets the next batch from the input.
+func (m *materializer) nextBatch() {
+	m.batch = m.input.Next()
+	m.curIdx = 0
+	if m.batch.Length() == 0 {
+		m.inputDone = true
+	}
+}
+
+// vecDatum returns the value at index rowIdx of vec, the vector of the
+// column at index colIdx, as a datum.
+func (m *materializer) vecDatum(vec exec.ColVec, colIdx int, rowIdx uint16) tree.Datum {
+	if vec.HasNulls() && vec.NullAt(rowIdx) {
+		return tree.DNull
+	}
+	switch vec.Type() {
+	case types.Bool:
+		return tree.MakeDBool(tree.DBool(vec.Bool()[rowIdx]))
+	case types.Bytes:
+		if m.typs[colIdx].SemanticType == sqlbase.ColumnType_BYTES {
+			return m.da.NewDBytes(tree.DBytes(vec.Bytes()[rowIdx]))
+		}
+		return m.da.NewDString(tree.DString(vec.Bytes()[rowIdx]))
+	case types.Decimal:
+		d := m.da.NewDDecimal(tree.DDecimal{})
+		d.Set(&vec.Decimal()[rowIdx])
+		return d
+	case types.Int64:
+		return m.da.NewDInt(tree.DInt(vec.Int64()[rowIdx]))
+	case types.Float64:
+		return m.da.NewDFloat(tree.DFloat(vec.Float64()[rowIdx]))
+	}
+	panic(fmt.Sprintf("unhandled type %s", vec.Type()))
+}
+
+// ConsumerDone is part of the RowSource interface.
+func (m *materializer) ConsumerDone() {
+	m.moveToDraining(nil /* err */)
+}
+
+// ConsumerClosed is part of the RowSource interface.
+func (m *materializer) ConsumerClosed() {
+	// The consumer is done, Next() will not be called again.
+	m.internalClose()
+}
@@ -230,6 +230,18 @@ func (m *outbox) mainLoop(ctx context.Context) error {
 		case msg, ok := <-m.RowChannel.C:
 			if !ok {
 				// No more data.
+				if p := m.flowCtx.cpuProfiler; p != nil {
+					if profile := p.Stop(); profile != nil {
+						if err := m.addRow(ctx, nil /* row */, &ProducerMetadata{
+							CPUProfile: &RemoteProducerMetadata_CPUProfile{
+								NodeID:  m.flowCtx.nodeID,
+								Profile: profile,
+							},
+						}); err != nil {
+							return err
+						}
+					}
+				}
 				return m.flush(ctx)
 			}
 			if !draining || msg.Meta != nil {
@@ -23,6 +23,7 @@ import (
 	"fmt"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -739,6 +740,7 @@ var procNameToLogTag = map[string]string{
 	hashJoinerProcName:              "hashJoiner",
 	interleavedReaderJoinerProcName: "interleaveReaderJoiner",
 	joinReaderProcName:              "joinReader",
+	materializerProcName:            "materializer",
 	mergeJoinerProcName:             "mergeJoiner",
 	metadataTestReceiverProcName:    "metaReceiver",
 	metadataTestSenderProcName:      "metaSender",
@@ -910,6 +912,18 @@ func newProcessor(
 	inputs []RowSource,
 	outputs []RowReceiver,
 ) (Processor, error) {
+	if mode := flowCtx.EvalCtx.SessionData.Vectorize; mode != sessiondata.VectorizeOff &&
+		supportsVectorized(core) {
+		p, err := newVectorizedProcessor(flowCtx, processorID, core, post, inputs, outputs)
+		if err == nil {
+			return p, nil
+		}
+		if mode == sessiondata.VectorizeAlways {
+			return nil, err
+		}
+		// Fall back to the row-based processor.
+		log.VEventf(ctx, 2, "not vectorizing processor %d: %v", processorID, err)
+	}
 	if core.Noop != nil {
 		if err := checkNumInOut(inputs, outputs, 1, 1); err != nil {
 			return nil, err
@@ -1047,6 +1061,12 @@ func newProcessor(
 		}
 		return newProjectSetProcessor(flowCtx, processorID, core.ProjectSet, inputs[0], post, outputs[0])
 	}
+	if core.Windower != nil {
+		if err := checkNumInOut(inputs, outputs, 1, 1); err != nil {
+			return nil, err
+		}
+		return newWindower(flowCtx, processorID, core.Windower, inputs[0], post, outputs[0])
+	}
 	return nil, errors.Errorf("unsupported processor core %s", core)
 }
 
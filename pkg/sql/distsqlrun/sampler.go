@@ -36,6 +36,37 @@ type sketchInfo struct {
 	numRows  int64
 }
 
+// addRow adds a row to the sketch, using buf as scratch space for the key
+// encoding of the row's values. Rows that have a NULL in any of the sketch
+// columns are counted as NULLs instead. The (possibly reallocated) buf is
+// returned.
+func (s *sketchInfo) addRow(
+	row sqlbase.EncDatumRow, typs []sqlbase.ColumnType, da *sqlbase.DatumAlloc, buf []byte,
+) ([]byte, error) {
+	s.numRows++
+	for _, col := range s.spec.Columns {
+		if row[col].IsNull() {
+			s.numNulls++
+			return buf, nil
+		}
+	}
+	// We need to use a KEY encoding because equal values should have the same
+	// encoding. For multi-column sketches, the key encodings of the columns are
+	// concatenated; they are self-delimiting, so distinct tuples of values
+	// yield distinct encodings.
+	// TODO(radu): a fast path for simple columns (like integer)?
+	buf = buf[:0]
+	for _, col := range s.spec.Columns {
+		var err error
+		buf, err = row[col].Encode(&typs[col], da, sqlbase.DatumEncoding_ASCENDING_KEY, buf)
+		if err != nil {
+			return buf, err
+		}
+	}
+	s.sketch.Insert(buf)
+	return buf, nil
+}
+
 // A sampler processor returns a random sample of rows, as well as "global"
 // statistics (including cardinality estimation sketch data). See SamplerSpec
 // for more details.
@@ -77,8 +108,8 @@ func newSamplerProcessor(
 		if _, ok := supportedSketchTypes[s.SketchType]; !ok {
 			return nil, errors.Errorf("unsupported sketch type %s", s.SketchType)
 		}
-		if len(s.Columns) != 1 {
-			return nil, errors.Errorf("multi-column sketches not supported yet")
+		if len(s.Columns) == 0 {
+			return nil, errors.Errorf("no columns")
 		}
 	}
 
@@ -179,23 +210,11 @@ func (s *samplerProcessor) mainLoop(ctx context.Context) (earlyExit bool, _ erro
 		}
 
 		for i := range s.sketches {
-			// TODO(radu): for multi-column sketches, we will need to do this for all
-			// columns.
-			col := s.sketches[i].spec.Columns[0]
-			s.sketches[i].numRows++
-			if row[col].IsNull() {
-				s.sketches[i].numNulls++
-				continue
-			}
-			// We need to use a KEY encoding because equal values should have the same
-			// encoding.
-			// TODO(radu): a fast path for simple columns (like integer)?
 			var err error
-			buf, err = row[col].Encode(&s.outTypes[col], &da, sqlbase.DatumEncoding_ASCENDING_KEY, buf[:0])
+			buf, err = s.sketches[i].addRow(row, s.outTypes, &da, buf)
 			if err != nil {
 				return false, err
 			}
-			s.sketches[i].sketch.Insert(buf)
 		}
 
 		// Use Int63 so we don't have headaches converting to DInt.
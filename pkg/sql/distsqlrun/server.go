@@ -74,7 +74,7 @@ type DistSQLVersion uint32
 //
 // ATTENTION: When updating these fields, add to version_history.txt explaining
 // what changed.
-const Version DistSQLVersion = 15
+const Version DistSQLVersion = 17
 
 // MinAcceptedVersion is the oldest version that the server is
 // compatible with; see above.
@@ -278,6 +278,7 @@ func (ds *ServerImpl) setupFlow(
 	ctx context.Context,
 	parentSpan opentracing.Span,
 	parentMonitor *mon.BytesMonitor,
+	diskMonitor *mon.BytesMonitor,
 	req *SetupFlowRequest,
 	syncFlowConsumer RowReceiver,
 ) (context.Context, *Flow, error) {
@@ -322,6 +323,10 @@ func (ds *ServerImpl) setupFlow(
 		// The flow will run in a Txn that specifies child=true because we
 		// do not want each distributed Txn to heartbeat the transaction.
 		txn = client.NewTxnWithProto(ds.FlowDB, req.Flow.Gateway, client.LeafTxn, *req.Txn)
+		if req.TxnCoordMeta != nil {
+			// Pick up the root's outstanding pipelined writes, among others.
+			txn.AugmentTxnCoordMeta(ctx, *req.TxnCoordMeta)
+		}
 	}
 
 	location, err := timeutil.TimeZoneStringToLocation(req.EvalContext.Location)
@@ -337,6 +342,7 @@ func (ds *ServerImpl) setupFlow(
 		User:            req.EvalContext.User,
 		SearchPath:      sessiondata.MakeSearchPath(req.EvalContext.SearchPath),
 		SequenceState:   sessiondata.NewSequenceState(),
+		Vectorize:       sessiondata.VectorizeExecMode(req.EvalContext.Vectorize),
 	}
 	ie := ds.SessionBoundInternalExecutorFactory(ctx, sd)
 
@@ -382,9 +388,18 @@ func (ds *ServerImpl) setupFlow(
 		testingKnobs:   ds.TestingKnobs,
 		nodeID:         nodeID,
 		TempStorage:    ds.TempStorage,
-		diskMonitor:    ds.DiskMonitor,
+		diskMonitor:    diskMonitor,
 		JobRegistry:    ds.ServerConfig.JobRegistry,
 	}
+	if req.CollectCPUProfile {
+		p, err := StartCPUProfile()
+		if err != nil {
+			// The statement is still run, just without a profile from this node.
+			log.Warningf(ctx, "unable to collect CPU profile: %s", err)
+		} else {
+			flowCtx.cpuProfiler = p
+		}
+	}
 
 	ctx = flowCtx.AnnotateCtx(ctx)
 
@@ -392,6 +407,9 @@ func (ds *ServerImpl) setupFlow(
 	flowCtx.AddLogTagStr("f", f.id.Short())
 	if err := f.setup(ctx, &req.Flow); err != nil {
 		log.Errorf(ctx, "error setting up flow: %s", err)
+		if flowCtx.cpuProfiler != nil {
+			_ = flowCtx.cpuProfiler.Stop()
+		}
 		tracing.FinishSpan(sp)
 		ctx = opentracing.ContextWithSpan(ctx, nil)
 		return ctx, nil, err
@@ -402,12 +420,23 @@ func (ds *ServerImpl) setupFlow(
 // SetupSyncFlow sets up a synchronous flow, connecting the sync response
 // output stream to the given RowReceiver. The flow is not started. The flow
 // will be associated with the given context.
+// The temporary storage used by the flow is accounted for in diskMonitor if
+// it's set, or in the server's disk monitor otherwise.
 // Note: the returned context contains a span that must be finished through
 // Flow.Cleanup.
 func (ds *ServerImpl) SetupSyncFlow(
-	ctx context.Context, parentMonitor *mon.BytesMonitor, req *SetupFlowRequest, output RowReceiver,
+	ctx context.Context,
+	parentMonitor *mon.BytesMonitor,
+	diskMonitor *mon.BytesMonitor,
+	req *SetupFlowRequest,
+	output RowReceiver,
 ) (context.Context, *Flow, error) {
-	return ds.setupFlow(ds.AnnotateCtx(ctx), opentracing.SpanFromContext(ctx), parentMonitor, req, output)
+	if diskMonitor == nil {
+		diskMonitor = ds.DiskMonitor
+	}
+	return ds.setupFlow(
+		ds.AnnotateCtx(ctx), opentracing.SpanFromContext(ctx), parentMonitor, diskMonitor, req, output,
+	)
 }
 
 // RunSyncFlow is part of the DistSQLServer interface.
@@ -423,7 +452,7 @@ func (ds *ServerImpl) RunSyncFlow(stream DistSQL_RunSyncFlowServer) error {
 		return errors.Errorf("first message in RunSyncFlow doesn't contain SetupFlowRequest")
 	}
 	req := firstMsg.SetupFlowRequest
-	ctx, f, err := ds.SetupSyncFlow(stream.Context(), &ds.memMonitor, req, mbox)
+	ctx, f, err := ds.SetupSyncFlow(stream.Context(), &ds.memMonitor, nil /* diskMonitor */, req, mbox)
 	if err != nil {
 		return err
 	}
@@ -456,7 +485,9 @@ func (ds *ServerImpl) SetupFlow(
 	// Note: the passed context will be canceled when this RPC completes, so we
 	// can't associate it with the flow.
 	ctx = ds.AnnotateCtx(context.Background())
-	ctx, f, err := ds.setupFlow(ctx, parentSpan, &ds.memMonitor, req, nil /* syncFlowConsumer */)
+	ctx, f, err := ds.setupFlow(
+		ctx, parentSpan, &ds.memMonitor, ds.DiskMonitor, req, nil, /* syncFlowConsumer */
+	)
 	if err == nil {
 		err = ds.flowScheduler.ScheduleFlow(ctx, f)
 	}
@@ -598,6 +629,13 @@ func (ep *dummyEvalPlanner) EvalSubquery(expr *tree.Subquery) (tree.Datum, error
 	return nil, errEvalPlanner
 }
 
+// Implements the tree.EvalPlanner interface.
+func (ep *dummyEvalPlanner) TxnContentionAdvice(
+	ctx context.Context,
+) ([]tree.ContentionAdvice, error) {
+	return nil, errEvalPlanner
+}
+
 var errSequenceOperators = errors.New("cannot backfill such sequence operation")
 
 // Implements the tree.SequenceOperators interface by returning errors.
@@ -114,6 +114,9 @@ func (sd *StreamDecoder) AddMessage(msg *ProducerMessage) error {
 			case *RemoteProducerMetadata_RowNum_:
 				meta.RowNum = v.RowNum
 
+			case *RemoteProducerMetadata_CpuProfile:
+				meta.CPUProfile = v.CpuProfile
+
 			case *RemoteProducerMetadata_Error:
 				meta.Err = v.Error.ErrorDetail()
 
@@ -99,6 +99,10 @@ func (se *StreamEncoder) AddMetadata(meta ProducerMetadata) {
 		enc.Value = &RemoteProducerMetadata_RowNum_{
 			RowNum: meta.RowNum,
 		}
+	} else if meta.CPUProfile != nil {
+		enc.Value = &RemoteProducerMetadata_CpuProfile{
+			CpuProfile: meta.CPUProfile,
+		}
 	} else {
 		enc.Value = &RemoteProducerMetadata_Error{
 			Error: NewError(meta.Err),
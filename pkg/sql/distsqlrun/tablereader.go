@@ -16,6 +16,7 @@ package distsqlrun
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
@@ -24,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
@@ -259,7 +261,10 @@ const tableReaderTagPrefix = "tablereader."
 
 // Stats implements the SpanStats interface.
 func (trs *TableReaderStats) Stats() map[string]string {
-	return trs.InputStats.Stats(tableReaderTagPrefix)
+	statsMap := trs.InputStats.Stats(tableReaderTagPrefix)
+	statsMap[tableReaderTagPrefix+"kv.keys_read"] = fmt.Sprintf("%d", trs.KeysRead)
+	statsMap[tableReaderTagPrefix+"kv.bytes_read"] = humanizeutil.IBytes(trs.BytesRead)
+	return statsMap
 }
 
 // StatsForQueryPlan implements the DistSQLSpanStats interface.
@@ -275,6 +280,11 @@ func (tr *tableReader) outputStatsToTrace() {
 		return
 	}
 	if sp := opentracing.SpanFromContext(tr.ctx); sp != nil {
-		tracing.SetSpanStats(sp, &TableReaderStats{InputStats: is})
+		keysRead, bytesRead := tr.fetcher.GetKVStats()
+		tracing.SetSpanStats(sp, &TableReaderStats{
+			InputStats: is,
+			KeysRead:   keysRead,
+			BytesRead:  bytesRead,
+		})
 	}
 }
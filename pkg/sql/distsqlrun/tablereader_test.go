@@ -381,6 +381,10 @@ func TestLimitScans(t *testing.T) {
 			if trs.InputStats.NumRows != limit {
 				t.Fatalf("read %d rows, but stats only counted: %d", limit, trs.InputStats.NumRows)
 			}
+			if trs.KeysRead < trs.InputStats.NumRows || trs.BytesRead == 0 {
+				t.Fatalf("read %d rows, but KV stats only counted %d keys and %d bytes",
+					trs.InputStats.NumRows, trs.KeysRead, trs.BytesRead)
+			}
 		}
 		for _, l := range span.Logs {
 			for _, f := range l.Fields {
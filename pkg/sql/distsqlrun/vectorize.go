@@ -0,0 +1,478 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlrun
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/exec"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/pkg/errors"
+)
+
+// supportsVectorized returns whether the vectorized engine can potentially
+// run processors with the given core. Whether it can actually run a given
+// processor also depends on its spec, its post-processing and the types of its
+// columns; see newVectorizedProcessor.
+func supportsVectorized(core *ProcessorCoreUnion) bool {
+	return core.Noop != nil ||
+		(core.TableReader != nil && !core.TableReader.IsCheck) ||
+		core.Aggregator != nil
+}
+
+// newVectorizedProcessor creates a processor that runs the given core and
+// post-processing with the vectorized engine: the rows of the processor's
+// input are turned into column batches by a columnarizer, processed by a tree
+// of vectorized operators, and turned back into rows by a materializer, which
+// also applies the post-processing that couldn't be vectorized.
+//
+// An error is returned if the processor can't be vectorized.
+func newVectorizedProcessor(
+	flowCtx *FlowCtx,
+	processorID int32,
+	core *ProcessorCoreUnion,
+	post *PostProcessSpec,
+	inputs []RowSource,
+	outputs []RowReceiver,
+) (Processor, error) {
+	var input RowSource
+	switch {
+	case core.Noop != nil:
+		if err := checkNumInOut(inputs, outputs, 1, 1); err != nil {
+			return nil, err
+		}
+		input = inputs[0]
+	case core.TableReader != nil && !core.TableReader.IsCheck:
+		if err := checkNumInOut(inputs, outputs, 0, 1); err != nil {
+			return nil, err
+		}
+		var err error
+		input, post, err = newProjectingTableReader(flowCtx, processorID, core.TableReader, post)
+		if err != nil {
+			return nil, err
+		}
+	case core.Aggregator != nil:
+		if err := checkNumInOut(inputs, outputs, 1, 1); err != nil {
+			return nil, err
+		}
+		input = inputs[0]
+	default:
+		return nil, errors.Errorf("unsupported processor core %s", core)
+	}
+
+	source, err := newColumnarizer(input)
+	if err != nil {
+		return nil, err
+	}
+	var op exec.Operator = source
+	typs := input.OutputTypes()
+	if core.Aggregator != nil {
+		if op, typs, err = planVectorizedAggregator(op, typs, core.Aggregator); err != nil {
+			return nil, err
+		}
+	}
+	if op, typs, post, err = planVectorizedPostProcess(op, typs, post, &flowCtx.EvalCtx); err != nil {
+		return nil, err
+	}
+	return newMaterializer(flowCtx, processorID, source, op, typs, post, outputs[0])
+}
+
+// newProjectingTableReader creates a tableReader that only outputs the
+// columns needed by the given post-processing, so that the columnarizer
+// doesn't convert the others. It returns the tableReader and the
+// post-processing to apply to its output, which is remapped to refer to its
+// output columns.
+func newProjectingTableReader(
+	flowCtx *FlowCtx, processorID int32, spec *TableReaderSpec, post *PostProcessSpec,
+) (RowSource, *PostProcessSpec, error) {
+	typs := make([]sqlbase.ColumnType, len(spec.Table.Columns))
+	for i := range typs {
+		typs[i] = spec.Table.Columns[i].Type
+	}
+	var h ProcOutputHelper
+	if err := h.Init(post, typs, &flowCtx.EvalCtx, nil /* output */); err != nil {
+		return nil, nil, err
+	}
+	neededColumns := h.neededColumns()
+
+	// colMap maps the columns of the table to the output columns of the
+	// tableReader.
+	colMap := make([]int, len(typs))
+	outputCols := make([]uint32, 0, neededColumns.Len())
+	for i := range colMap {
+		colMap[i] = -1
+		if neededColumns.Contains(i) {
+			colMap[i] = len(outputCols)
+			outputCols = append(outputCols, uint32(i))
+		}
+	}
+
+	// The limit of the post-processing is applied by the materializer; pass it
+	// on as a hint so that the tableReader doesn't scan more than needed.
+	innerSpec := *spec
+	innerSpec.LimitHint = limitHint(spec.LimitHint, post)
+	tr, err := newTableReader(
+		flowCtx, processorID, &innerSpec,
+		&PostProcessSpec{Projection: true, OutputColumns: outputCols}, nil, /* output */
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	outerPost := &PostProcessSpec{
+		Projection: post.Projection,
+		Offset:     post.Offset,
+		Limit:      post.Limit,
+	}
+	if outerPost.Filter, err = remapExpression(
+		post.Filter, typs, &flowCtx.EvalCtx, colMap,
+	); err != nil {
+		return nil, nil, err
+	}
+	if post.Projection {
+		outerPost.OutputColumns = make([]uint32, len(post.OutputColumns))
+		for i, c := range post.OutputColumns {
+			outerPost.OutputColumns[i] = uint32(colMap[c])
+		}
+	}
+	if post.RenderExprs != nil {
+		outerPost.RenderExprs = make([]Expression, len(post.RenderExprs))
+		for i := range post.RenderExprs {
+			if outerPost.RenderExprs[i], err = remapExpression(
+				post.RenderExprs[i], typs, &flowCtx.EvalCtx, colMap,
+			); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	return tr, outerPost, nil
+}
+
+// aggregateFnFromSpec maps the aggregate functions supported by the
+// vectorized aggregator to their exec counterparts.
+var aggregateFnFromSpec = map[AggregatorSpec_Func]exec.AggregateFn{
+	AggregatorSpec_ANY_NOT_NULL: exec.AnyNotNull,
+	AggregatorSpec_AVG:          exec.Avg,
+	AggregatorSpec_BOOL_AND:     exec.BoolAnd,
+	AggregatorSpec_BOOL_OR:      exec.BoolOr,
+	AggregatorSpec_COUNT:        exec.Count,
+	AggregatorSpec_COUNT_ROWS:   exec.CountRows,
+	AggregatorSpec_MAX:          exec.Max,
+	AggregatorSpec_MIN:          exec.Min,
+	AggregatorSpec_SUM:          exec.Sum,
+	AggregatorSpec_SUM_INT:      exec.SumInt,
+}
+
+// planVectorizedAggregator creates a vectorized aggregator on top of input,
+// whose columns are of the given types. It returns the aggregator and the
+// types of its output columns.
+//
+// Only aggregations whose input is ordered on all the grouping columns, and
+// which don't use DISTINCT or FILTER, are supported.
+func planVectorizedAggregator(
+	input exec.Operator, typs []sqlbase.ColumnType, spec *AggregatorSpec,
+) (exec.Operator, []sqlbase.ColumnType, error) {
+	if len(spec.OrderedGroupCols) != len(spec.GroupCols) {
+		return nil, nil, errors.Errorf("unordered aggregation is not supported")
+	}
+	groupTypes := make([]types.T, len(spec.GroupCols))
+	for i, c := range spec.GroupCols {
+		if int(c) >= len(typs) {
+			return nil, nil, errors.Errorf("group column %d out of range", c)
+		}
+		groupTypes[i] = types.FromColumnType(typs[c])
+	}
+
+	aggFns := make([]exec.AggregateFn, len(spec.Aggregations))
+	aggCols := make([][]uint32, len(spec.Aggregations))
+	aggTypes := make([][]types.T, len(spec.Aggregations))
+	outputTypes := make([]sqlbase.ColumnType, len(spec.Aggregations))
+	for i, agg := range spec.Aggregations {
+		if agg.Distinct || agg.FilterColIdx != nil {
+			return nil, nil, errors.Errorf("aggregation with DISTINCT or FILTER is not supported")
+		}
+		fn, ok := aggregateFnFromSpec[agg.Func]
+		if !ok {
+			return nil, nil, errors.Errorf("unsupported aggregate function %s", agg.Func)
+		}
+		argTypes := make([]sqlbase.ColumnType, len(agg.ColIdx))
+		aggTypes[i] = make([]types.T, len(agg.ColIdx))
+		for j, c := range agg.ColIdx {
+			if int(c) >= len(typs) {
+				return nil, nil, errors.Errorf("ColIdx out of range (%d)", c)
+			}
+			argTypes[j] = typs[c]
+			aggTypes[i][j] = types.FromColumnType(typs[c])
+		}
+		_, retType, err := GetAggregateInfo(agg.Func, argTypes...)
+		if err != nil {
+			return nil, nil, err
+		}
+		// Check that the vectorized function produces values that can represent
+		// the result of the builtin.
+		execRetType, err := exec.AggregateOutputType(fn, aggTypes[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		if types.FromColumnType(retType) != execRetType {
+			return nil, nil, errors.Errorf(
+				"unsupported aggregate function %s on %v", agg.Func, argTypes,
+			)
+		}
+		aggFns[i] = fn
+		aggCols[i] = agg.ColIdx
+		outputTypes[i] = retType
+	}
+
+	op, err := exec.NewOrderedAggregator(
+		input, spec.GroupCols, groupTypes, aggFns, aggCols, aggTypes,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	return op, outputTypes, nil
+}
+
+// planVectorizedPostProcess plans vectorized operators on top of input, whose
+// columns are of the given types, for the parts of the post-processing spec
+// that the vectorized engine supports. The conjuncts of the filter that compare
+// a column with a constant or with another column of the same type are turned
+// into selection operators. If the whole filter was vectorized and there is no
+// offset or limit, the render expressions that apply an arithmetic operator to
+// columns and constants are turned into projection operators, which append
+// their result as a new column to the batches.
+//
+// It returns the resulting operator, the types of the columns of its batches,
+// and the post-processing that remains to be applied to the rows.
+func planVectorizedPostProcess(
+	input exec.Operator, typs []sqlbase.ColumnType, post *PostProcessSpec, evalCtx *tree.EvalContext,
+) (exec.Operator, []sqlbase.ColumnType, *PostProcessSpec, error) {
+	op := input
+	residual := *post
+
+	if post.Filter.Expr != "" {
+		h := &exprHelper{}
+		if err := h.init(post.Filter, typs, evalCtx); err != nil {
+			return nil, nil, nil, err
+		}
+		var rest tree.TypedExpr
+		for _, conj := range splitConjunction(h.expr, nil /* res */) {
+			selOp, err := planSelection(op, typs, conj)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			if selOp != nil {
+				op = selOp
+				continue
+			}
+			if rest == nil {
+				rest = conj
+			} else {
+				rest = tree.NewTypedAndExpr(rest, conj)
+			}
+		}
+		residual.Filter = serializeExpression(rest, nil /* indexVarMap */)
+	}
+
+	// Projections are computed for all the tuples that pass the vectorized
+	// filters; don't compute them for tuples that wouldn't be output, as
+	// computing them may fail.
+	if residual.Filter.Expr != "" || post.Offset != 0 || post.Limit != 0 {
+		return op, typs, &residual, nil
+	}
+	if post.RenderExprs != nil {
+		residual.RenderExprs = make([]Expression, len(post.RenderExprs))
+		// The renders refer to the input columns; the projections only append
+		// columns to the batches.
+		inputTypes := typs
+		for i := range post.RenderExprs {
+			residual.RenderExprs[i] = post.RenderExprs[i]
+			h := &exprHelper{}
+			if err := h.init(post.RenderExprs[i], inputTypes, evalCtx); err != nil {
+				return nil, nil, nil, err
+			}
+			outputIdx := len(typs)
+			projOp, outputType, err := planProjection(op, inputTypes, h.expr, outputIdx)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			if projOp == nil {
+				continue
+			}
+			op = projOp
+			// Don't modify the slice of types of the input.
+			typs = append(typs[:len(typs):len(typs)], outputType)
+			residual.RenderExprs[i] = Expression{Expr: fmt.Sprintf("@%d", outputIdx+1)}
+		}
+	}
+	return op, typs, &residual, nil
+}
+
+// planSelection returns a selection operator on top of input that implements
+// the given filter conjunct, or nil if the conjunct can't be vectorized.
+func planSelection(
+	input exec.Operator, typs []sqlbase.ColumnType, expr tree.TypedExpr,
+) (exec.Operator, error) {
+	cmpExpr, ok := expr.(*tree.ComparisonExpr)
+	if !ok || !exec.IsSupportedComparison(cmpExpr.Operator) {
+		return nil, nil
+	}
+	cmp := cmpExpr.Operator
+	left, right := cmpExpr.TypedLeft(), cmpExpr.TypedRight()
+	if _, ok := left.(*tree.IndexedVar); !ok {
+		// Put the column on the left: `1 < @1` is `@1 > 1`.
+		left, right = right, left
+		cmp = flipComparison(cmp)
+	}
+	leftVar, ok := left.(*tree.IndexedVar)
+	if !ok {
+		return nil, nil
+	}
+	leftType := typs[leftVar.Idx]
+	t := types.FromColumnType(leftType)
+	switch r := right.(type) {
+	case *tree.IndexedVar:
+		if typs[r.Idx].SemanticType != leftType.SemanticType {
+			return nil, nil
+		}
+		return exec.NewSelOp(input, t, cmp, leftVar.Idx, r.Idx)
+	case tree.Datum:
+		if r == tree.DNull || !r.ResolvedType().Equivalent(leftType.ToDatumType()) {
+			return nil, nil
+		}
+		return exec.NewSelConstOp(input, t, cmp, leftVar.Idx, r)
+	}
+	return nil, nil
+}
+
+// planProjection returns a projection operator on top of input that computes
+// the given render expression into the column at index outputIdx, along with
+// the type of that column, or nil if the render can't be vectorized.
+func planProjection(
+	input exec.Operator, typs []sqlbase.ColumnType, expr tree.TypedExpr, outputIdx int,
+) (exec.Operator, sqlbase.ColumnType, error) {
+	binExpr, ok := expr.(*tree.BinaryExpr)
+	if !ok {
+		return nil, sqlbase.ColumnType{}, nil
+	}
+	outputType, err := sqlbase.DatumTypeToColumnType(binExpr.ResolvedType())
+	if err != nil {
+		return nil, sqlbase.ColumnType{}, nil
+	}
+	t := types.FromColumnType(outputType)
+	if !exec.IsSupportedBinaryOperator(binExpr.Operator, t) {
+		return nil, sqlbase.ColumnType{}, nil
+	}
+	left, right := binExpr.TypedLeft(), binExpr.TypedRight()
+	if _, ok := left.(*tree.IndexedVar); !ok && isCommutative(binExpr.Operator) {
+		left, right = right, left
+	}
+	leftVar, ok := left.(*tree.IndexedVar)
+	if !ok || typs[leftVar.Idx].SemanticType != outputType.SemanticType {
+		return nil, sqlbase.ColumnType{}, nil
+	}
+	var op exec.Operator
+	switch r := right.(type) {
+	case *tree.IndexedVar:
+		if typs[r.Idx].SemanticType != outputType.SemanticType {
+			return nil, sqlbase.ColumnType{}, nil
+		}
+		op, err = exec.NewProjOp(input, t, binExpr.Operator, leftVar.Idx, r.Idx, outputIdx)
+	case tree.Datum:
+		if r == tree.DNull || !r.ResolvedType().Equivalent(outputType.ToDatumType()) {
+			return nil, sqlbase.ColumnType{}, nil
+		}
+		op, err = exec.NewProjConstOp(input, t, binExpr.Operator, leftVar.Idx, r, outputIdx)
+	default:
+		return nil, sqlbase.ColumnType{}, nil
+	}
+	if err != nil {
+		return nil, sqlbase.ColumnType{}, err
+	}
+	return op, outputType, nil
+}
+
+// splitConjunction appends the conjuncts of expr to res.
+func splitConjunction(expr tree.TypedExpr, res []tree.TypedExpr) []tree.TypedExpr {
+	switch t := expr.(type) {
+	case *tree.AndExpr:
+		return splitConjunction(t.TypedRight(), splitConjunction(t.TypedLeft(), res))
+	case *tree.ParenExpr:
+		return splitConjunction(t.TypedInnerExpr(), res)
+	}
+	return append(res, expr)
+}
+
+// flipComparison returns the operator cmp' such that `a cmp b` is equivalent
+// to `b cmp' a`, for the comparison operators supported by the vectorized
+// engine.
+func flipComparison(cmp tree.ComparisonOperator) tree.ComparisonOperator {
+	switch cmp {
+	case tree.LT:
+		return tree.GT
+	case tree.LE:
+		return tree.GE
+	case tree.GT:
+		return tree.LT
+	case tree.GE:
+		return tree.LE
+	}
+	return cmp
+}
+
+func isCommutative(op tree.BinaryOperator) bool {
+	return op == tree.Plus || op == tree.Mult
+}
+
+// serializeExpression serializes expr into an Expression, optionally
+// remapping the columns it refers to: an IndexedVar with index i becomes
+// column indexVarMap[i].
+func serializeExpression(expr tree.TypedExpr, indexVarMap []int) Expression {
+	if expr == nil {
+		return Expression{}
+	}
+	var buf bytes.Buffer
+	fmtCtx := tree.MakeFmtCtx(&buf, tree.FmtCheckEquivalence)
+	fmtCtx.WithIndexedVarFormat(func(ctx *tree.FmtCtx, idx int) {
+		remappedIdx := idx
+		if indexVarMap != nil {
+			remappedIdx = indexVarMap[idx]
+			if remappedIdx < 0 {
+				panic(fmt.Sprintf("unmapped index %d", idx))
+			}
+		}
+		ctx.Printf("@%d", remappedIdx+1)
+	})
+	fmtCtx.FormatNode(expr)
+	return Expression{Expr: buf.String()}
+}
+
+// remapExpression remaps the columns referred to by an expression on columns
+// of the given types; see serializeExpression.
+func remapExpression(
+	expr Expression, typs []sqlbase.ColumnType, evalCtx *tree.EvalContext, indexVarMap []int,
+) (Expression, error) {
+	if expr.Expr == "" {
+		return expr, nil
+	}
+	h := &exprHelper{}
+	if err := h.init(expr, typs, evalCtx); err != nil {
+		return Expression{}, err
+	}
+	return serializeExpression(h.expr, indexVarMap), nil
+}
@@ -0,0 +1,276 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlrun
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// runProcessorWithVectorizeMode runs a processor with the given spec on the
+// given input, with the given vectorize session setting, and returns its
+// output and whether it was vectorized.
+func runProcessorWithVectorizeMode(
+	core ProcessorCoreUnion,
+	post PostProcessSpec,
+	inputTypes []sqlbase.ColumnType,
+	inputRows sqlbase.EncDatumRows,
+	mode sessiondata.VectorizeExecMode,
+) (_ sqlbase.EncDatumRows, _ []sqlbase.ColumnType, vectorized bool, _ error) {
+	in := NewRowBuffer(inputTypes, inputRows, RowBufferArgs{})
+	out := &RowBuffer{}
+
+	st := cluster.MakeTestingClusterSettings()
+	evalCtx := tree.MakeTestingEvalContext(st)
+	defer evalCtx.Stop(context.Background())
+	evalCtx.SessionData.Vectorize = mode
+	flowCtx := FlowCtx{
+		Settings: st,
+		EvalCtx:  evalCtx,
+	}
+
+	p, err := newProcessor(
+		context.Background(), &flowCtx, 0 /* processorID */, &core, &post,
+		[]RowSource{in}, []RowReceiver{out})
+	if err != nil {
+		return nil, nil, false, err
+	}
+	_, vectorized = p.(*materializer)
+
+	p.Run(context.Background(), nil /* wg */)
+	if !out.ProducerClosed {
+		return nil, nil, false, fmt.Errorf("output RowReceiver not closed")
+	}
+	var res sqlbase.EncDatumRows
+	for {
+		row, meta := out.Next()
+		if meta != nil {
+			if meta.Err != nil {
+				return nil, nil, false, meta.Err
+			}
+			continue
+		}
+		if row == nil {
+			break
+		}
+		res = append(res, row.Copy())
+	}
+	return res, p.OutputTypes(), vectorized, nil
+}
+
+func TestVectorizedProcessors(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	floatType := sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_FLOAT}
+
+	// The input is ordered on the first column, and has more rows than fit in a
+	// batch. All the other columns have NULLs.
+	const numRows = 3000
+	rng := rand.New(rand.NewSource(0))
+	inputTypes := []sqlbase.ColumnType{intType, intType, floatType, strType, boolType, decType}
+	input := make(sqlbase.EncDatumRows, numRows)
+	for i := range input {
+		input[i] = sqlbase.EncDatumRow{
+			sqlbase.DatumToEncDatum(intType, tree.NewDInt(tree.DInt(i/7))),
+			sqlbase.DatumToEncDatum(intType, tree.NewDInt(tree.DInt(rng.Intn(200)-100))),
+			sqlbase.DatumToEncDatum(floatType, tree.NewDFloat(tree.DFloat(rng.Float64()*10))),
+			sqlbase.DatumToEncDatum(strType, tree.NewDString(fmt.Sprintf("s%d", rng.Intn(50)))),
+			sqlbase.DatumToEncDatum(boolType, tree.MakeDBool(rng.Intn(2) == 0)),
+			sqlbase.DatumToEncDatum(decType, &tree.DDecimal{}),
+		}
+		if err := input[i][5].Datum.(*tree.DDecimal).SetString(
+			fmt.Sprintf("%d.%d", rng.Intn(100), rng.Intn(100)),
+		); err != nil {
+			t.Fatal(err)
+		}
+		for j := 1; j < len(input[i]); j++ {
+			if rng.Intn(10) == 0 {
+				input[i][j] = sqlbase.EncDatum{Datum: tree.DNull}
+			}
+		}
+	}
+
+	noop := ProcessorCoreUnion{Noop: &NoopCoreSpec{}}
+	testCases := []struct {
+		description string
+		core        ProcessorCoreUnion
+		post        PostProcessSpec
+	}{
+		{
+			description: "noop",
+			core:        noop,
+		},
+		{
+			description: "filter",
+			core:        noop,
+			post: PostProcessSpec{
+				Filter: Expression{Expr: "@2 < 50 AND 1.5 <= @3 AND @4 != 's7' AND @2 >= @1 AND @5"},
+			},
+		},
+		{
+			description: "filter-projection",
+			core:        noop,
+			post: PostProcessSpec{
+				Filter:        Expression{Expr: "@6 > 50.5 AND @2 < @1"},
+				Projection:    true,
+				OutputColumns: []uint32{5, 3, 0},
+			},
+		},
+		{
+			description: "renders",
+			core:        noop,
+			post: PostProcessSpec{
+				Filter: Expression{Expr: "@2 > 0"},
+				RenderExprs: []Expression{
+					{Expr: "@1 + @2"}, {Expr: "10 - @2"}, {Expr: "@3 / 2.5"}, {Expr: "@6 * @6"},
+					{Expr: "@4"}, {Expr: "@1 * 2 + 1"},
+				},
+			},
+		},
+		{
+			description: "limit",
+			core:        noop,
+			post: PostProcessSpec{
+				Filter:      Expression{Expr: "@2 > 0"},
+				RenderExprs: []Expression{{Expr: "@1 + @2"}},
+				Offset:      500,
+				Limit:       10,
+			},
+		},
+		{
+			description: "scalar-aggregation",
+			core: ProcessorCoreUnion{Aggregator: &AggregatorSpec{
+				Aggregations: []AggregatorSpec_Aggregation{
+					{Func: AggregatorSpec_COUNT_ROWS},
+					{Func: AggregatorSpec_COUNT, ColIdx: []uint32{3}},
+					{Func: AggregatorSpec_SUM, ColIdx: []uint32{1}},
+					{Func: AggregatorSpec_AVG, ColIdx: []uint32{5}},
+					{Func: AggregatorSpec_MIN, ColIdx: []uint32{3}},
+					{Func: AggregatorSpec_MAX, ColIdx: []uint32{2}},
+					{Func: AggregatorSpec_BOOL_AND, ColIdx: []uint32{4}},
+				},
+			}},
+		},
+		{
+			description: "ordered-aggregation",
+			core: ProcessorCoreUnion{Aggregator: &AggregatorSpec{
+				GroupCols:        []uint32{0},
+				OrderedGroupCols: []uint32{0},
+				Aggregations: []AggregatorSpec_Aggregation{
+					{Func: AggregatorSpec_ANY_NOT_NULL, ColIdx: []uint32{0}},
+					{Func: AggregatorSpec_SUM_INT, ColIdx: []uint32{1}},
+					{Func: AggregatorSpec_AVG, ColIdx: []uint32{1}},
+					{Func: AggregatorSpec_SUM, ColIdx: []uint32{2}},
+					{Func: AggregatorSpec_MAX, ColIdx: []uint32{5}},
+					{Func: AggregatorSpec_BOOL_OR, ColIdx: []uint32{4}},
+				},
+			}},
+			post: PostProcessSpec{
+				Filter: Expression{Expr: "@2 > 0"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			expected, expectedTypes, _, err := runProcessorWithVectorizeMode(
+				tc.core, tc.post, inputTypes, input, sessiondata.VectorizeOff,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, resTypes, vectorized, err := runProcessorWithVectorizeMode(
+				tc.core, tc.post, inputTypes, input, sessiondata.VectorizeAlways,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !vectorized {
+				t.Fatal("processor not vectorized")
+			}
+			if len(expected) == 0 {
+				t.Fatal("no rows returned")
+			}
+			if e, r := expected.String(expectedTypes), res.String(resTypes); e != r {
+				t.Fatalf("expected:\n%s\ngot:\n%s", e, r)
+			}
+		})
+	}
+}
+
+func TestVectorizedProcessorsUnsupported(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		core        ProcessorCoreUnion
+		inputTypes  []sqlbase.ColumnType
+		expectedErr string
+	}{
+		{
+			core:        ProcessorCoreUnion{Noop: &NoopCoreSpec{}},
+			inputTypes:  []sqlbase.ColumnType{{SemanticType: sqlbase.ColumnType_TIMESTAMP}},
+			expectedErr: "unsupported column type",
+		},
+		{
+			core: ProcessorCoreUnion{Aggregator: &AggregatorSpec{
+				GroupCols: []uint32{0},
+				Aggregations: []AggregatorSpec_Aggregation{
+					{Func: AggregatorSpec_ANY_NOT_NULL, ColIdx: []uint32{0}},
+				},
+			}},
+			inputTypes:  oneIntCol,
+			expectedErr: "unordered aggregation is not supported",
+		},
+		{
+			core: ProcessorCoreUnion{Aggregator: &AggregatorSpec{
+				Aggregations: []AggregatorSpec_Aggregation{
+					{Func: AggregatorSpec_VARIANCE, ColIdx: []uint32{0}},
+				},
+			}},
+			inputTypes:  oneIntCol,
+			expectedErr: "unsupported aggregate function VARIANCE",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.expectedErr, func(t *testing.T) {
+			// The processor fails to be set up in the always mode, and falls back
+			// to the row-based engine in the on mode.
+			_, _, _, err := runProcessorWithVectorizeMode(
+				tc.core, PostProcessSpec{}, tc.inputTypes, nil /* inputRows */, sessiondata.VectorizeAlways,
+			)
+			if !testutils.IsError(err, tc.expectedErr) {
+				t.Fatalf("expected error %q, got %v", tc.expectedErr, err)
+			}
+			_, _, vectorized, err := runProcessorWithVectorizeMode(
+				tc.core, PostProcessSpec{}, tc.inputTypes, nil /* inputRows */, sessiondata.VectorizeOn,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if vectorized {
+				t.Fatal("unexpectedly vectorized processor")
+			}
+		})
+	}
+}
@@ -0,0 +1,573 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlrun
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unsafe"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/pkg/errors"
+)
+
+// GetWindowFunctionInfo returns the window function constructor and the return
+// type for the given window function when applied on the given types. If the
+// window function is an aggregate, the aggregate constructor is returned as
+// well; it is used to reset the aggregate when computing over a window frame.
+func GetWindowFunctionInfo(
+	fn WindowerSpec_WindowFn, inputTypes ...sqlbase.ColumnType,
+) (
+	windowConstructor func(*tree.EvalContext) tree.WindowFunc,
+	aggregateConstructor func(*tree.EvalContext) tree.AggregateFunc,
+	returnType sqlbase.ColumnType,
+	err error,
+) {
+	var funcStr string
+	switch {
+	case fn.AggregateFunc != nil:
+		funcStr = fn.AggregateFunc.String()
+	case fn.WindowFunc != nil:
+		funcStr = fn.WindowFunc.String()
+	default:
+		return nil, nil, sqlbase.ColumnType{}, errors.Errorf(
+			"function is neither an aggregate nor a window function",
+		)
+	}
+	datumTypes := make([]types.T, len(inputTypes))
+	for i := range inputTypes {
+		datumTypes[i] = inputTypes[i].ToDatumType()
+	}
+
+	_, builtins := builtins.GetBuiltinProperties(strings.ToLower(funcStr))
+	for _, b := range builtins {
+		types := b.Types.Types()
+		if len(types) != len(inputTypes) {
+			continue
+		}
+		match := true
+		for i, t := range types {
+			if !datumTypes[i].Equivalent(t) {
+				match = false
+				break
+			}
+		}
+		if match && b.WindowFunc != nil {
+			// Found!
+			constructWindow := func(evalCtx *tree.EvalContext) tree.WindowFunc {
+				return b.WindowFunc(datumTypes, evalCtx)
+			}
+			var constructAgg func(*tree.EvalContext) tree.AggregateFunc
+			if b.AggregateFunc != nil {
+				constructAgg = func(evalCtx *tree.EvalContext) tree.AggregateFunc {
+					return b.AggregateFunc(datumTypes, evalCtx)
+				}
+			}
+
+			colTyp, err := sqlbase.DatumTypeToColumnType(b.FixedReturnType())
+			if err != nil {
+				return nil, nil, sqlbase.ColumnType{}, err
+			}
+			return constructWindow, constructAgg, colTyp, nil
+		}
+	}
+	return nil, nil, sqlbase.ColumnType{}, errors.Errorf(
+		"no builtin window function for %s on %v", funcStr, inputTypes,
+	)
+}
+
+// windowFunc contains the static state of a single window function computed
+// by the windower.
+type windowFunc struct {
+	create         func(*tree.EvalContext) tree.WindowFunc
+	aggConstructor func(*tree.EvalContext) tree.AggregateFunc
+	ordering       sqlbase.ColumnOrdering
+	argIdxStart    int
+	argCount       int
+
+	// frame is nil if the default frame (RANGE UNBOUNDED PRECEDING) is used.
+	frame            *tree.WindowFrame
+	startBoundOffset int
+	endBoundOffset   int
+}
+
+// windowerState represents the state of the processor.
+type windowerState int
+
+const (
+	windowerStateUnknown windowerState = iota
+	// windowerAccumulating means that rows are being read from the input and
+	// buffered.
+	windowerAccumulating
+	// windowerEmittingRows means that the window functions have been computed
+	// over all buffered rows and rows are being sent to the output.
+	windowerEmittingRows
+)
+
+// windower is the processor that computes window functions. All input rows
+// are buffered and grouped into partitions according to the PARTITION BY
+// columns; once the input is exhausted, every window function is computed over
+// each partition and the input rows are emitted, in the order they were
+// received, with one additional column per window function.
+//
+// Since all rows of a partition must be seen by a single windower, multiple
+// windowers need their input to be hash-routed on the PARTITION BY columns.
+type windower struct {
+	processorBase
+
+	runningState windowerState
+	input        RowSource
+	inputTypes   []sqlbase.ColumnType
+	outputTypes  []sqlbase.ColumnType
+	datumAlloc   sqlbase.DatumAlloc
+	acc          mon.BoundAccount
+
+	partitionBy []uint32
+	windowFns   []windowFunc
+
+	// rows buffers all the input rows.
+	rows memRowContainer
+	// partitions maps the encoded PARTITION BY columns to the rows in the
+	// partition, in input order. partitionKeys contains the keys of partitions
+	// in order of first appearance.
+	partitions    map[string][]tree.IndexedRow
+	partitionKeys []string
+	scratch       []byte
+
+	// windowValues contains, for each buffered row, the results of the window
+	// functions.
+	windowValues [][]tree.Datum
+	// nextRowIdx is the index of the next buffered row to be emitted.
+	nextRowIdx int
+	outputRow  sqlbase.EncDatumRow
+
+	cancelChecker *sqlbase.CancelChecker
+}
+
+var _ Processor = &windower{}
+var _ RowSource = &windower{}
+
+const windowerProcName = "windower"
+
+func newWindower(
+	flowCtx *FlowCtx,
+	processorID int32,
+	spec *WindowerSpec,
+	input RowSource,
+	post *PostProcessSpec,
+	output RowReceiver,
+) (*windower, error) {
+	w := &windower{
+		input:       input,
+		inputTypes:  input.OutputTypes(),
+		partitionBy: spec.PartitionBy,
+		windowFns:   make([]windowFunc, len(spec.WindowFns)),
+		partitions:  make(map[string][]tree.IndexedRow),
+	}
+	ctx := flowCtx.EvalCtx.Ctx()
+	memMonitor := newMonitor(ctx, flowCtx.EvalCtx.Mon, "windower-mem")
+
+	for _, c := range w.partitionBy {
+		if c >= uint32(len(w.inputTypes)) {
+			return nil, errors.Errorf("PartitionBy column out of range (%d)", c)
+		}
+	}
+
+	w.outputTypes = make([]sqlbase.ColumnType, len(w.inputTypes), len(w.inputTypes)+len(spec.WindowFns))
+	copy(w.outputTypes, w.inputTypes)
+	for i, fn := range spec.WindowFns {
+		if fn.ArgIdxStart+fn.ArgCount > uint32(len(w.inputTypes)) {
+			return nil, errors.Errorf(
+				"arguments out of range (start %d, count %d)", fn.ArgIdxStart, fn.ArgCount,
+			)
+		}
+		argTypes := w.inputTypes[fn.ArgIdxStart : fn.ArgIdxStart+fn.ArgCount]
+		windowConstructor, aggConstructor, retType, err := GetWindowFunctionInfo(fn, argTypes...)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range fn.Ordering.Columns {
+			if c.ColIdx >= uint32(len(w.inputTypes)) {
+				return nil, errors.Errorf("Ordering column out of range (%d)", c.ColIdx)
+			}
+		}
+		w.windowFns[i] = windowFunc{
+			create:         windowConstructor,
+			aggConstructor: aggConstructor,
+			ordering:       convertToColumnOrdering(fn.Ordering),
+			argIdxStart:    int(fn.ArgIdxStart),
+			argCount:       int(fn.ArgCount),
+		}
+		if fn.Frame != nil {
+			w.windowFns[i].frame, w.windowFns[i].startBoundOffset, w.windowFns[i].endBoundOffset =
+				fn.Frame.convertToWindowFrame()
+		}
+		w.outputTypes = append(w.outputTypes, retType)
+	}
+	w.outputRow = make(sqlbase.EncDatumRow, len(w.outputTypes))
+
+	w.acc = memMonitor.MakeBoundAccount()
+	w.rows.initWithMon(nil /* ordering */, w.inputTypes, &flowCtx.EvalCtx, memMonitor)
+
+	if err := w.init(
+		w,
+		post,
+		w.outputTypes,
+		flowCtx,
+		processorID,
+		output,
+		memMonitor,
+		procStateOpts{
+			inputsToDrain: []RowSource{w.input},
+			trailingMetaCallback: func() []ProducerMetadata {
+				w.close()
+				return nil
+			},
+		},
+	); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// convertToWindowFrame converts the frame specification to a tree.WindowFrame
+// along with its (already evaluated) bound offsets.
+func (f *WindowerSpec_Frame) convertToWindowFrame() (
+	frame *tree.WindowFrame,
+	startBoundOffset int,
+	endBoundOffset int,
+) {
+	frame = &tree.WindowFrame{
+		Mode: tree.WindowFrameMode(f.Mode),
+		Bounds: tree.WindowFrameBounds{
+			StartBound: &tree.WindowFrameBound{
+				BoundType: tree.WindowFrameBoundType(f.Start.BoundType),
+			},
+		},
+	}
+	startBoundOffset = int(f.Start.Offset)
+	if f.End != nil {
+		frame.Bounds.EndBound = &tree.WindowFrameBound{
+			BoundType: tree.WindowFrameBoundType(f.End.BoundType),
+		}
+		endBoundOffset = int(f.End.Offset)
+	}
+	return frame, startBoundOffset, endBoundOffset
+}
+
+// Start is part of the RowSource interface.
+func (w *windower) Start(ctx context.Context) context.Context {
+	w.input.Start(ctx)
+	ctx = w.startInternal(ctx, windowerProcName)
+	w.cancelChecker = sqlbase.NewCancelChecker(ctx)
+	w.runningState = windowerAccumulating
+	return ctx
+}
+
+// Next is part of the RowSource interface.
+func (w *windower) Next() (sqlbase.EncDatumRow, *ProducerMetadata) {
+	for w.state == stateRunning {
+		var row sqlbase.EncDatumRow
+		var meta *ProducerMetadata
+		switch w.runningState {
+		case windowerAccumulating:
+			w.runningState, row, meta = w.accumulateRows()
+		case windowerEmittingRows:
+			w.runningState, row, meta = w.emitRow()
+		default:
+			log.Fatalf(w.ctx, "unsupported state: %d", w.runningState)
+		}
+
+		if row == nil && meta == nil {
+			continue
+		}
+		return row, meta
+	}
+	return nil, w.drainHelper()
+}
+
+// ConsumerDone is part of the RowSource interface.
+func (w *windower) ConsumerDone() {
+	w.moveToDraining(nil /* err */)
+}
+
+// ConsumerClosed is part of the RowSource interface.
+func (w *windower) ConsumerClosed() {
+	// The consumer is done, Next() will not be called again.
+	w.close()
+}
+
+func (w *windower) close() {
+	if w.internalClose() {
+		log.VEventf(w.ctx, 2, "exiting windower")
+		w.rows.Close(w.ctx)
+		w.acc.Close(w.ctx)
+		w.memMonitor.Stop(w.ctx)
+	}
+}
+
+// accumulateRows continually reads rows from the input and buffers them. If it
+// encounters metadata, the metadata is immediately returned. Subsequent calls
+// of this function will resume row accumulation.
+func (w *windower) accumulateRows() (windowerState, sqlbase.EncDatumRow, *ProducerMetadata) {
+	for {
+		row, meta := w.input.Next()
+		if meta != nil {
+			if meta.Err != nil {
+				w.moveToDraining(nil /* err */)
+				return windowerStateUnknown, nil, meta
+			}
+			return windowerAccumulating, nil, meta
+		}
+		if row == nil {
+			log.VEvent(w.ctx, 1, "accumulation complete")
+			break
+		}
+
+		if err := w.accumulateRow(row); err != nil {
+			w.moveToDraining(err)
+			return windowerStateUnknown, nil, nil
+		}
+	}
+
+	if err := w.computeWindowFunctions(); err != nil {
+		w.moveToDraining(err)
+		return windowerStateUnknown, nil, nil
+	}
+
+	// Transition to windowerEmittingRows, and let it generate the next row/meta.
+	return windowerEmittingRows, nil, nil
+}
+
+// accumulateRow buffers a single row and adds it to its partition.
+func (w *windower) accumulateRow(row sqlbase.EncDatumRow) error {
+	if err := w.cancelChecker.Check(); err != nil {
+		return err
+	}
+
+	encoded := w.scratch[:0]
+	for _, colIdx := range w.partitionBy {
+		var err error
+		encoded, err = row[colIdx].Encode(
+			&w.inputTypes[colIdx], &w.datumAlloc, sqlbase.DatumEncoding_ASCENDING_KEY, encoded,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	w.scratch = encoded
+
+	if err := w.rows.AddRow(w.ctx, row); err != nil {
+		return err
+	}
+	rowIdx := w.rows.Len() - 1
+	entry := tree.IndexedRow{Idx: rowIdx, Row: w.rows.At(rowIdx)}
+
+	partition, ok := w.partitions[string(encoded)]
+	sz := int64(unsafe.Sizeof(entry))
+	if !ok {
+		sz += int64(len(encoded))
+	}
+	if err := w.acc.Grow(w.ctx, sz); err != nil {
+		return err
+	}
+	if !ok {
+		w.partitionKeys = append(w.partitionKeys, string(encoded))
+	}
+	w.partitions[string(encoded)] = append(partition, entry)
+	return nil
+}
+
+// windowerPartitionSorter sorts the rows of a partition according to the
+// ordering of a window function. It also determines peer groups.
+type windowerPartitionSorter struct {
+	evalCtx  *tree.EvalContext
+	rows     []tree.IndexedRow
+	ordering sqlbase.ColumnOrdering
+}
+
+// windowerPartitionSorter implements the sort.Interface interface.
+func (s *windowerPartitionSorter) Len() int      { return len(s.rows) }
+func (s *windowerPartitionSorter) Swap(i, j int) { s.rows[i], s.rows[j] = s.rows[j], s.rows[i] }
+func (s *windowerPartitionSorter) Less(i, j int) bool {
+	return sqlbase.CompareDatums(s.ordering, s.evalCtx, s.rows[i].Row, s.rows[j].Row) < 0
+}
+
+// inSameGroup returns whether the i-th and j-th rows are peers.
+func (s *windowerPartitionSorter) inSameGroup(i, j int) bool {
+	return sqlbase.CompareDatums(s.ordering, s.evalCtx, s.rows[i].Row, s.rows[j].Row) == 0
+}
+
+// computeWindowFunctions computes the results of all window functions over all
+// buffered rows and stores them in w.windowValues.
+func (w *windower) computeWindowFunctions() error {
+	rowCount := w.rows.Len()
+	if rowCount == 0 {
+		return nil
+	}
+
+	windowCount := len(w.windowFns)
+	winValSz := uintptr(rowCount) * unsafe.Sizeof([]tree.Datum{})
+	winAllocSz := uintptr(rowCount*windowCount) * unsafe.Sizeof(tree.Datum(nil))
+	if err := w.acc.Grow(w.ctx, int64(winValSz+winAllocSz)); err != nil {
+		return err
+	}
+	w.windowValues = make([][]tree.Datum, rowCount)
+	windowAlloc := make([]tree.Datum, rowCount*windowCount)
+	for i := range w.windowValues {
+		w.windowValues[i] = windowAlloc[i*windowCount : (i+1)*windowCount]
+	}
+
+	// Every window function sorts its own copy of each partition, so that it
+	// always starts from the input order. This makes the sort deterministic
+	// across window functions with equivalent ORDER BY clauses.
+	var partition []tree.IndexedRow
+	evalCtx := w.evalCtx
+	for windowIdx, fn := range w.windowFns {
+		frameRun := &tree.WindowFrameRun{
+			ArgIdxStart:      fn.argIdxStart,
+			ArgCount:         fn.argCount,
+			Frame:            fn.frame,
+			StartBoundOffset: fn.startBoundOffset,
+			EndBoundOffset:   fn.endBoundOffset,
+		}
+
+		for _, key := range w.partitionKeys {
+			if err := w.cancelChecker.Check(); err != nil {
+				return err
+			}
+			partition = append(partition[:0], w.partitions[key]...)
+
+			var inSameGroup func(i, j int) bool
+			if len(fn.ordering) > 0 {
+				// If an ORDER BY clause is provided, order the partition and use the
+				// sorter to determine peer groups.
+				sorter := &windowerPartitionSorter{
+					evalCtx:  evalCtx,
+					rows:     partition,
+					ordering: fn.ordering,
+				}
+				sort.Sort(sorter)
+				inSameGroup = sorter.inSameGroup
+			} else if fn.frame != nil && fn.frame.Mode == tree.ROWS {
+				// If ORDER BY clause is not provided and the frame is specified with
+				// ROWS mode, any row has no peers.
+				inSameGroup = func(i, j int) bool { return false }
+			} else {
+				// If ORDER BY clause is not provided and either no frame is provided
+				// or the frame is specified with RANGE mode, all rows are peers.
+				inSameGroup = func(i, j int) bool { return true }
+			}
+
+			if err := w.computeWindowFunction(
+				windowIdx, fn, frameRun, partition, inSameGroup,
+			); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// computeWindowFunction computes the window function fn over a single sorted
+// partition.
+func (w *windower) computeWindowFunction(
+	windowIdx int,
+	fn windowFunc,
+	frameRun *tree.WindowFrameRun,
+	partition []tree.IndexedRow,
+	inSameGroup func(i, j int) bool,
+) error {
+	evalCtx := w.evalCtx
+	builtin := fn.create(evalCtx)
+	defer builtin.Close(w.ctx, evalCtx)
+	if fn.frame != nil {
+		// In order to calculate aggregates over a particular window frame, we
+		// need a way to 'reset' the aggregate.
+		builtins.AddAggregateConstructorToFramableAggregate(builtin, fn.aggConstructor)
+	}
+
+	frameRun.Rows = partition
+	frameRun.RowIdx = 0
+	for frameRun.RowIdx < len(partition) {
+		// Compute the size of the current peer group.
+		frameRun.FirstPeerIdx = frameRun.RowIdx
+		frameRun.PeerRowCount = 1
+		for ; frameRun.FirstPeerIdx+frameRun.PeerRowCount < frameRun.PartitionSize(); frameRun.PeerRowCount++ {
+			cur := frameRun.FirstPeerIdx + frameRun.PeerRowCount
+			if !inSameGroup(cur, cur-1) {
+				break
+			}
+		}
+
+		// Perform calculations on each row in the current peer group.
+		for ; frameRun.RowIdx < frameRun.FirstPeerIdx+frameRun.PeerRowCount; frameRun.RowIdx++ {
+			res, err := builtin.Compute(w.ctx, evalCtx, frameRun)
+			if err != nil {
+				return err
+			}
+
+			// This may overestimate, because WindowFuncs may perform internal caching.
+			if err := w.acc.Grow(w.ctx, int64(res.Size())); err != nil {
+				return err
+			}
+
+			// Save the result, indexed by original row index.
+			w.windowValues[partition[frameRun.RowIdx].Idx][windowIdx] = res
+		}
+	}
+	return nil
+}
+
+// emitRow returns the next buffered row along with the results of the window
+// functions for it.
+//
+// emitRow() might move to stateDraining. It might also not return a row if the
+// ProcOutputHelper filtered the current row out.
+func (w *windower) emitRow() (windowerState, sqlbase.EncDatumRow, *ProducerMetadata) {
+	if w.nextRowIdx >= w.rows.Len() {
+		// All rows have been emitted. Transition to draining so that we emit any
+		// metadata that we've produced.
+		w.moveToDraining(nil /* err */)
+		return windowerStateUnknown, nil, nil
+	}
+
+	row := w.rows.At(w.nextRowIdx)
+	for i, d := range row {
+		w.outputRow[i] = sqlbase.DatumToEncDatum(w.inputTypes[i], d)
+	}
+	for i, res := range w.windowValues[w.nextRowIdx] {
+		if res == nil {
+			// We can't encode nil into an EncDatum, so we represent it with DNull.
+			res = tree.DNull
+		}
+		w.outputRow[len(row)+i] = sqlbase.DatumToEncDatum(w.outputTypes[len(row)+i], res)
+	}
+	w.nextRowIdx++
+
+	if outRow := w.processRowHelper(w.outputRow); outRow != nil {
+		return windowerEmittingRows, outRow, nil
+	}
+	// We might have switched to draining, we might not have. In case we
+	// haven't, windowerEmittingRows is accurate. If we have, it will be ignored
+	// by the caller.
+	return windowerEmittingRows, nil, nil
+}
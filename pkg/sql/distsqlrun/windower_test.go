@@ -0,0 +1,112 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlrun
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestWindower(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	v := [10]sqlbase.EncDatum{}
+	for i := range v {
+		v[i] = intEncDatum(i)
+	}
+
+	input := sqlbase.EncDatumRows{
+		{v[1], v[1]},
+		{v[1], v[3]},
+		{v[2], v[2]},
+		{v[1], v[4]},
+		{v[2], v[5]},
+	}
+	orderBySecondCol := Ordering{Columns: []Ordering_Column{{ColIdx: 1, Direction: Ordering_Column_ASC}}}
+
+	testCases := []struct {
+		description string
+		spec        WindowerSpec
+		expected    sqlbase.EncDatumRows
+	}{
+		{
+			description: "partitions",
+			spec: WindowerSpec{
+				PartitionBy: []uint32{0},
+				WindowFns: []WindowerSpec_WindowFn{
+					{
+						WindowFunc: WindowerSpec_ROW_NUMBER.Enum(),
+						Ordering:   orderBySecondCol,
+					},
+					{
+						AggregateFunc: AggregatorSpec_COUNT.Enum(),
+						ArgIdxStart:   1,
+						ArgCount:      1,
+					},
+				},
+			},
+			expected: sqlbase.EncDatumRows{
+				{v[1], v[1], v[1], v[3]},
+				{v[1], v[3], v[2], v[3]},
+				{v[2], v[2], v[1], v[2]},
+				{v[1], v[4], v[3], v[3]},
+				{v[2], v[5], v[2], v[2]},
+			},
+		},
+		{
+			description: "frame",
+			spec: WindowerSpec{
+				WindowFns: []WindowerSpec_WindowFn{
+					{
+						AggregateFunc: AggregatorSpec_MIN.Enum(),
+						ArgIdxStart:   1,
+						ArgCount:      1,
+						Ordering:      orderBySecondCol,
+						Frame: &WindowerSpec_Frame{
+							Mode: WindowerSpec_Frame_ROWS,
+							Start: WindowerSpec_Frame_Bound{
+								BoundType: WindowerSpec_Frame_VALUE_PRECEDING,
+								Offset:    1,
+							},
+						},
+					},
+				},
+			},
+			expected: sqlbase.EncDatumRows{
+				{v[1], v[1], v[1]},
+				{v[1], v[3], v[2]},
+				{v[2], v[2], v[1]},
+				{v[1], v[4], v[3]},
+				{v[2], v[5], v[4]},
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.description, func(t *testing.T) {
+			runProcessorTest(
+				t,
+				ProcessorCoreUnion{Windower: &c.spec},
+				PostProcessSpec{},
+				twoIntCols,
+				input,
+				intCols(2+len(c.spec.WindowFns)), /* outputTypes */
+				c.expected,
+			)
+		})
+	}
+}
@@ -133,6 +133,18 @@ func (n *dropDatabaseNode) startExec(params runParams) error {
 			// TODO(knz): dependent dropped views should be qualified here.
 			tbNameStrings = append(tbNameStrings, cascadedViews...)
 		} else {
+			if tbDesc.IsSequence() && tbDesc.SequenceOpts.OwnerTableID != sqlbase.InvalidID {
+				// An owned sequence is dropped along with the table that owns
+				// it, which may have happened already.
+				seqDesc, err := sqlbase.GetTableDescFromID(ctx, p.txn, tbDesc.ID)
+				if err != nil {
+					return err
+				}
+				if seqDesc.Dropped() {
+					tbNameStrings = append(tbNameStrings, toDel.tn.FQString())
+					continue
+				}
+			}
 			cascadedViews, err := p.dropTableImpl(params, tbDesc)
 			if err != nil {
 				return err
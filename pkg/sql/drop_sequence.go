@@ -96,6 +96,9 @@ func (*dropSequenceNode) Close(context.Context)        {}
 func (p *planner) dropSequenceImpl(
 	ctx context.Context, seqDesc *sqlbase.TableDescriptor, behavior tree.DropBehavior,
 ) error {
+	if err := p.removeSequenceOwnerIfExists(ctx, seqDesc); err != nil {
+		return err
+	}
 	return p.initiateDropTable(ctx, seqDesc, true /* drainName */)
 }
 
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -49,6 +50,9 @@ func (p *planner) DropView(ctx context.Context, n *tree.DropView) (planNode, err
 			// IfExists specified and the view did not exist.
 			continue
 		}
+		if err := checkViewMatchesMaterialized(tn, droppedDesc, n.IsMaterialized); err != nil {
+			return nil, err
+		}
 
 		td = append(td, toDelete{tn, droppedDesc})
 	}
@@ -113,6 +117,22 @@ func (*dropViewNode) Next(runParams) (bool, error) { return false, nil }
 func (*dropViewNode) Values() tree.Datums          { return tree.Datums{} }
 func (*dropViewNode) Close(context.Context)        {}
 
+// checkViewMatchesMaterialized returns an error if desc is a materialized
+// view and materialized is false, or the other way around.
+func checkViewMatchesMaterialized(
+	tn *tree.TableName, desc *sqlbase.TableDescriptor, materialized bool,
+) error {
+	if desc.MaterializedView() && !materialized {
+		return pgerror.NewErrorf(pgerror.CodeWrongObjectTypeError,
+			"%q is not a view", tree.ErrString(tn)).SetHintf(
+			"use DROP MATERIALIZED VIEW to drop a materialized view")
+	}
+	if !desc.MaterializedView() && materialized {
+		return sqlbase.NewWrongObjectTypeError(tn, "materialized view")
+	}
+	return nil
+}
+
 func descInSlice(descID sqlbase.ID, td []toDelete) bool {
 	for _, toDel := range td {
 		if descID == toDel.desc.ID {
@@ -93,6 +93,10 @@ const (
 	EventLogSetZoneConfig EventLogType = "set_zone_config"
 	// EventLogRemoveZoneConfig is recorded when a zone config is removed.
 	EventLogRemoveZoneConfig EventLogType = "remove_zone_config"
+
+	// EventLogClusterProvisioned is recorded when a cluster is provisioned
+	// from files at initialization.
+	EventLogClusterProvisioned EventLogType = "cluster_provisioned"
 )
 
 // EventLogSetClusterSettingDetail is the json details for a settings change.
@@ -102,6 +106,16 @@ type EventLogSetClusterSettingDetail struct {
 	User        string
 }
 
+// EventLogClusterProvisionedDetail is the json details for the provisioning of
+// a cluster at initialization.
+type EventLogClusterProvisionedDetail struct {
+	// Settings are the names of the cluster settings applied.
+	Settings []string
+	// BootstrapSQL is the name of the file of SQL statements executed, if any.
+	BootstrapSQL string
+	User         string
+}
+
 // An EventLogger exposes methods used to record events to the event table.
 type EventLogger struct {
 	*InternalExecutor
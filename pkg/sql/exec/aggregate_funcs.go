@@ -0,0 +1,856 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/cockroachdb/apd"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/arith"
+	"github.com/pkg/errors"
+)
+
+// AggregateFn is an aggregate function supported by the vectorized
+// aggregator. The functions have the same semantics as the corresponding SQL
+// builtins.
+type AggregateFn int
+
+const (
+	// AnyNotNull returns an arbitrary non-NULL value of the group.
+	AnyNotNull AggregateFn = iota
+	// Avg is the avg builtin.
+	Avg
+	// BoolAnd is the bool_and builtin.
+	BoolAnd
+	// BoolOr is the bool_or builtin.
+	BoolOr
+	// Count is the count builtin.
+	Count
+	// CountRows is count(*).
+	CountRows
+	// Max is the max builtin.
+	Max
+	// Min is the min builtin.
+	Min
+	// Sum is the sum builtin.
+	Sum
+	// SumInt is the sum_int builtin.
+	SumInt
+)
+
+// AggregateOutputType returns the type of the result of the given aggregate
+// function when applied to arguments of the given types, or an error if the
+// aggregate function is not supported on those types.
+func AggregateOutputType(fn AggregateFn, argTypes []types.T) (types.T, error) {
+	if fn == CountRows {
+		if len(argTypes) != 0 {
+			return types.Unhandled, errors.Errorf("count_rows takes no arguments")
+		}
+		return types.Int64, nil
+	}
+	if len(argTypes) != 1 {
+		return types.Unhandled, errors.Errorf("expected one argument, got %d", len(argTypes))
+	}
+	t := argTypes[0]
+	switch fn {
+	case AnyNotNull, Min, Max:
+		switch t {
+		case types.Bool, types.Bytes, types.Decimal, types.Int64, types.Float64:
+			return t, nil
+		}
+	case Avg, Sum:
+		switch t {
+		case types.Decimal, types.Int64:
+			return types.Decimal, nil
+		case types.Float64:
+			return types.Float64, nil
+		}
+	case BoolAnd, BoolOr:
+		if t == types.Bool {
+			return types.Bool, nil
+		}
+	case Count:
+		if t != types.Unhandled {
+			return types.Int64, nil
+		}
+	case SumInt:
+		if t == types.Int64 {
+			return types.Int64, nil
+		}
+	}
+	return types.Unhandled, errors.Errorf("unsupported aggregate function %d on type %s", fn, t)
+}
+
+// aggregateFunc computes an aggregate function over groups of consecutive
+// tuples, writing the result of each group to an output vector.
+type aggregateFunc interface {
+	// Init sets the groups for the aggregation and the output vector. Each
+	// index in groups corresponds to a tuple of the input batches; true means
+	// that the tuple is the first one of a new group.
+	Init(groups []bool, vec ColVec)
+
+	// CurrentOutputIndex returns the index in the output vector of the group
+	// that is currently being aggregated; the results of all the previous
+	// groups have been written at lower indexes. It is -1 before any tuple has
+	// been aggregated.
+	CurrentOutputIndex() int
+	// SetOutputIndex sets the index in the output vector of the group that is
+	// currently being aggregated.
+	SetOutputIndex(idx int)
+
+	// Compute aggregates the selected tuples of the batch, whose arguments are
+	// in the columns at indexes inputIdxs. A zero-length batch signals the end
+	// of the input: the result of the last group is written and the output
+	// index is advanced past it.
+	Compute(batch ColBatch, inputIdxs []uint32)
+
+	// HandleEmptyInputScalar writes the result of a scalar aggregation (i.e.
+	// without grouping columns) over an empty input at index 0 of the output
+	// vector.
+	HandleEmptyInputScalar()
+}
+
+func newAggregateFunc(fn AggregateFn, argTypes []types.T) (aggregateFunc, error) {
+	if _, err := AggregateOutputType(fn, argTypes); err != nil {
+		return nil, err
+	}
+	switch fn {
+	case AnyNotNull:
+		return &anyNotNullAgg{t: argTypes[0]}, nil
+	case Avg:
+		return &sumAgg{t: argTypes[0], avg: true}, nil
+	case BoolAnd:
+		return &boolAgg{and: true}, nil
+	case BoolOr:
+		return &boolAgg{}, nil
+	case Count:
+		return &countAgg{}, nil
+	case CountRows:
+		return &countAgg{countRows: true}, nil
+	case Max:
+		return &minMaxAgg{t: argTypes[0]}, nil
+	case Min:
+		return &minMaxAgg{t: argTypes[0], min: true}, nil
+	case Sum:
+		return &sumAgg{t: argTypes[0]}, nil
+	case SumInt:
+		return &sumIntAgg{}, nil
+	}
+	return nil, errors.Errorf("unsupported aggregate function %d", fn)
+}
+
+// aggBase contains the state common to all aggregateFuncs.
+type aggBase struct {
+	groups []bool
+	vec    ColVec
+	curIdx int
+}
+
+func (a *aggBase) Init(groups []bool, vec ColVec) {
+	a.groups = groups
+	a.vec = vec
+	a.curIdx = -1
+}
+
+func (a *aggBase) CurrentOutputIndex() int {
+	return a.curIdx
+}
+
+func (a *aggBase) SetOutputIndex(idx int) {
+	a.curIdx = idx
+}
+
+// HandleEmptyInputScalar is part of the aggregateFunc interface. Most
+// aggregate functions return NULL on an empty input.
+func (a *aggBase) HandleEmptyInputScalar() {
+	a.vec.SetNull(0)
+}
+
+// aggValue holds a single value of any type, the type being known by the
+// user.
+type aggValue struct {
+	curBool    bool
+	curBytes   []byte
+	curDecimal apd.Decimal
+	curInt64   int64
+	curFloat64 float64
+}
+
+// minMaxAgg implements the MIN and MAX aggregate functions.
+type minMaxAgg struct {
+	aggBase
+	aggValue
+
+	t            types.T
+	min          bool
+	foundNonNull bool
+}
+
+// better returns whether a value that compares to the current value with the
+// given result should replace it.
+func (a *minMaxAgg) better(cmp int) bool {
+	if a.min {
+		return cmp < 0
+	}
+	return cmp > 0
+}
+
+// Compute is part of the aggregateFunc interface.
+func (a *minMaxAgg) Compute(batch ColBatch, inputIdxs []uint32) {
+	n := batch.Length()
+	if n == 0 {
+		if a.curIdx >= 0 {
+			a.flush()
+			a.curIdx++
+		}
+		return
+	}
+
+	vec := batch.ColVec(int(inputIdxs[0]))
+	hasNulls := vec.HasNulls()
+	sel := batch.Selection()
+	switch a.t {
+	case types.Bool:
+		col := vec.Bool()
+		for i := uint16(0); i < n; i++ {
+			rowIdx := i
+			if sel != nil {
+				rowIdx = sel[i]
+			}
+			if a.groups[rowIdx] {
+				if a.curIdx >= 0 {
+					a.flush()
+				}
+				a.curIdx++
+				a.foundNonNull = false
+			}
+			if hasNulls && vec.NullAt(rowIdx) {
+				continue
+			}
+			if !a.foundNonNull || a.better(compareBools(col[rowIdx], a.curBool)) {
+				a.curBool = col[rowIdx]
+				a.foundNonNull = true
+			}
+		}
+	case types.Bytes:
+		col := vec.Bytes()
+		for i := uint16(0); i < n; i++ {
+			rowIdx := i
+			if sel != nil {
+				rowIdx = sel[i]
+			}
+			if a.groups[rowIdx] {
+				if a.curIdx >= 0 {
+					a.flush()
+				}
+				a.curIdx++
+				a.foundNonNull = false
+			}
+			if hasNulls && vec.NullAt(rowIdx) {
+				continue
+			}
+			if !a.foundNonNull || a.better(bytes.Compare(col[rowIdx], a.curBytes)) {
+				a.curBytes = append(a.curBytes[:0], col[rowIdx]...)
+				a.foundNonNull = true
+			}
+		}
+	case types.Decimal:
+		col := vec.Decimal()
+		for i := uint16(0); i < n; i++ {
+			rowIdx := i
+			if sel != nil {
+				rowIdx = sel[i]
+			}
+			if a.groups[rowIdx] {
+				if a.curIdx >= 0 {
+					a.flush()
+				}
+				a.curIdx++
+				a.foundNonNull = false
+			}
+			if hasNulls && vec.NullAt(rowIdx) {
+				continue
+			}
+			if !a.foundNonNull || a.better(compareDecimals(&col[rowIdx], &a.curDecimal)) {
+				a.curDecimal.Set(&col[rowIdx])
+				a.foundNonNull = true
+			}
+		}
+	case types.Int64:
+		col := vec.Int64()
+		for i := uint16(0); i < n; i++ {
+			rowIdx := i
+			if sel != nil {
+				rowIdx = sel[i]
+			}
+			if a.groups[rowIdx] {
+				if a.curIdx >= 0 {
+					a.flush()
+				}
+				a.curIdx++
+				a.foundNonNull = false
+			}
+			if hasNulls && vec.NullAt(rowIdx) {
+				continue
+			}
+			if !a.foundNonNull || a.better(compareInt64s(col[rowIdx], a.curInt64)) {
+				a.curInt64 = col[rowIdx]
+				a.foundNonNull = true
+			}
+		}
+	case types.Float64:
+		col := vec.Float64()
+		for i := uint16(0); i < n; i++ {
+			rowIdx := i
+			if sel != nil {
+				rowIdx = sel[i]
+			}
+			if a.groups[rowIdx] {
+				if a.curIdx >= 0 {
+					a.flush()
+				}
+				a.curIdx++
+				a.foundNonNull = false
+			}
+			if hasNulls && vec.NullAt(rowIdx) {
+				continue
+			}
+			if !a.foundNonNull || a.better(compareFloat64s(col[rowIdx], a.curFloat64)) {
+				a.curFloat64 = col[rowIdx]
+				a.foundNonNull = true
+			}
+		}
+	default:
+		panic(fmt.Sprintf("unhandled type %s", a.t))
+	}
+}
+
+// flush writes the result of the current group.
+func (a *minMaxAgg) flush() {
+	if !a.foundNonNull {
+		a.vec.SetNull(uint16(a.curIdx))
+		return
+	}
+	a.vec.UnsetNull(uint16(a.curIdx))
+	switch a.t {
+	case types.Bool:
+		out := a.vec.Bool()
+		out[a.curIdx] = a.curBool
+	case types.Bytes:
+		out := a.vec.Bytes()
+		out[a.curIdx] = a.curBytes
+		// The buffer now belongs to the output vector.
+		a.curBytes = nil
+	case types.Decimal:
+		out := a.vec.Decimal()
+		out[a.curIdx].Set(&a.curDecimal)
+	case types.Int64:
+		out := a.vec.Int64()
+		out[a.curIdx] = a.curInt64
+	case types.Float64:
+		out := a.vec.Float64()
+		out[a.curIdx] = a.curFloat64
+	}
+}
+
+// anyNotNullAgg implements the ANY_NOT_NULL aggregate function: it returns the
+// first non-NULL value of each group.
+type anyNotNullAgg struct {
+	aggBase
+	aggValue
+
+	t            types.T
+	foundNonNull bool
+}
+
+// Compute is part of the aggregateFunc interface.
+func (a *anyNotNullAgg) Compute(batch ColBatch, inputIdxs []uint32) {
+	n := batch.Length()
+	if n == 0 {
+		if a.curIdx >= 0 {
+			a.flush()
+			a.curIdx++
+		}
+		return
+	}
+
+	vec := batch.ColVec(int(inputIdxs[0]))
+	hasNulls := vec.HasNulls()
+	sel := batch.Selection()
+	switch a.t {
+	case types.Bool:
+		col := vec.Bool()
+		for i := uint16(0); i < n; i++ {
+			rowIdx := i
+			if sel != nil {
+				rowIdx = sel[i]
+			}
+			if a.groups[rowIdx] {
+				if a.curIdx >= 0 {
+					a.flush()
+				}
+				a.curIdx++
+				a.foundNonNull = false
+			}
+			if !a.foundNonNull && !(hasNulls && vec.NullAt(rowIdx)) {
+				a.curBool = col[rowIdx]
+				a.foundNonNull = true
+			}
+		}
+	case types.Bytes:
+		col := vec.Bytes()
+		for i := uint16(0); i < n; i++ {
+			rowIdx := i
+			if sel != nil {
+				rowIdx = sel[i]
+			}
+			if a.groups[rowIdx] {
+				if a.curIdx >= 0 {
+					a.flush()
+				}
+				a.curIdx++
+				a.foundNonNull = false
+			}
+			if !a.foundNonNull && !(hasNulls && vec.NullAt(rowIdx)) {
+				a.curBytes = append(a.curBytes[:0], col[rowIdx]...)
+				a.foundNonNull = true
+			}
+		}
+	case types.Decimal:
+		col := vec.Decimal()
+		for i := uint16(0); i < n; i++ {
+			rowIdx := i
+			if sel != nil {
+				rowIdx = sel[i]
+			}
+			if a.groups[rowIdx] {
+				if a.curIdx >= 0 {
+					a.flush()
+				}
+				a.curIdx++
+				a.foundNonNull = false
+			}
+			if !a.foundNonNull && !(hasNulls && vec.NullAt(rowIdx)) {
+				a.curDecimal.Set(&col[rowIdx])
+				a.foundNonNull = true
+			}
+		}
+	case types.Int64:
+		col := vec.Int64()
+		for i := uint16(0); i < n; i++ {
+			rowIdx := i
+			if sel != nil {
+				rowIdx = sel[i]
+			}
+			if a.groups[rowIdx] {
+				if a.curIdx >= 0 {
+					a.flush()
+				}
+				a.curIdx++
+				a.foundNonNull = false
+			}
+			if !a.foundNonNull && !(hasNulls && vec.NullAt(rowIdx)) {
+				a.curInt64 = col[rowIdx]
+				a.foundNonNull = true
+			}
+		}
+	case types.Float64:
+		col := vec.Float64()
+		for i := uint16(0); i < n; i++ {
+			rowIdx := i
+			if sel != nil {
+				rowIdx = sel[i]
+			}
+			if a.groups[rowIdx] {
+				if a.curIdx >= 0 {
+					a.flush()
+				}
+				a.curIdx++
+				a.foundNonNull = false
+			}
+			if !a.foundNonNull && !(hasNulls && vec.NullAt(rowIdx)) {
+				a.curFloat64 = col[rowIdx]
+				a.foundNonNull = true
+			}
+		}
+	default:
+		panic(fmt.Sprintf("unhandled type %s", a.t))
+	}
+}
+
+// flush writes the result of the current group.
+func (a *anyNotNullAgg) flush() {
+	if !a.foundNonNull {
+		a.vec.SetNull(uint16(a.curIdx))
+		return
+	}
+	a.vec.UnsetNull(uint16(a.curIdx))
+	switch a.t {
+	case types.Bool:
+		out := a.vec.Bool()
+		out[a.curIdx] = a.curBool
+	case types.Bytes:
+		out := a.vec.Bytes()
+		out[a.curIdx] = a.curBytes
+		// The buffer now belongs to the output vector.
+		a.curBytes = nil
+	case types.Decimal:
+		out := a.vec.Decimal()
+		out[a.curIdx].Set(&a.curDecimal)
+	case types.Int64:
+		out := a.vec.Int64()
+		out[a.curIdx] = a.curInt64
+	case types.Float64:
+		out := a.vec.Float64()
+		out[a.curIdx] = a.curFloat64
+	}
+}
+
+// countAgg implements the COUNT and COUNT_ROWS aggregate functions.
+type countAgg struct {
+	aggBase
+
+	// countRows is set for COUNT_ROWS, which also counts the tuples that are
+	// NULL in the argument column.
+	countRows bool
+	count     int64
+}
+
+// Compute is part of the aggregateFunc interface.
+func (a *countAgg) Compute(batch ColBatch, inputIdxs []uint32) {
+	n := batch.Length()
+	if n == 0 {
+		if a.curIdx >= 0 {
+			a.vec.Int64()[a.curIdx] = a.count
+			a.curIdx++
+		}
+		return
+	}
+
+	var vec ColVec
+	if !a.countRows && batch.ColVec(int(inputIdxs[0])).HasNulls() {
+		vec = batch.ColVec(int(inputIdxs[0]))
+	}
+	out := a.vec.Int64()
+	sel := batch.Selection()
+	for i := uint16(0); i < n; i++ {
+		rowIdx := i
+		if sel != nil {
+			rowIdx = sel[i]
+		}
+		if a.groups[rowIdx] {
+			if a.curIdx >= 0 {
+				out[a.curIdx] = a.count
+			}
+			a.curIdx++
+			a.count = 0
+		}
+		if vec == nil || !vec.NullAt(rowIdx) {
+			a.count++
+		}
+	}
+}
+
+// HandleEmptyInputScalar is part of the aggregateFunc interface.
+func (a *countAgg) HandleEmptyInputScalar() {
+	a.vec.Int64()[0] = 0
+}
+
+// boolAgg implements the BOOL_AND and BOOL_OR aggregate functions.
+type boolAgg struct {
+	aggBase
+
+	and          bool
+	cur          bool
+	foundNonNull bool
+}
+
+// Compute is part of the aggregateFunc interface.
+func (a *boolAgg) Compute(batch ColBatch, inputIdxs []uint32) {
+	n := batch.Length()
+	if n == 0 {
+		if a.curIdx >= 0 {
+			a.flush()
+			a.curIdx++
+		}
+		return
+	}
+
+	vec := batch.ColVec(int(inputIdxs[0]))
+	col := vec.Bool()
+	hasNulls := vec.HasNulls()
+	sel := batch.Selection()
+	for i := uint16(0); i < n; i++ {
+		rowIdx := i
+		if sel != nil {
+			rowIdx = sel[i]
+		}
+		if a.groups[rowIdx] {
+			if a.curIdx >= 0 {
+				a.flush()
+			}
+			a.curIdx++
+			a.cur = a.and
+			a.foundNonNull = false
+		}
+		if hasNulls && vec.NullAt(rowIdx) {
+			continue
+		}
+		if a.and {
+			a.cur = a.cur && col[rowIdx]
+		} else {
+			a.cur = a.cur || col[rowIdx]
+		}
+		a.foundNonNull = true
+	}
+}
+
+// flush writes the result of the current group.
+func (a *boolAgg) flush() {
+	if !a.foundNonNull {
+		a.vec.SetNull(uint16(a.curIdx))
+		return
+	}
+	a.vec.UnsetNull(uint16(a.curIdx))
+	a.vec.Bool()[a.curIdx] = a.cur
+}
+
+// sumIntAgg implements the SUM_INT aggregate function. Like the builtin, it
+// doesn't check for overflow.
+type sumIntAgg struct {
+	aggBase
+
+	sum          int64
+	foundNonNull bool
+}
+
+// Compute is part of the aggregateFunc interface.
+func (a *sumIntAgg) Compute(batch ColBatch, inputIdxs []uint32) {
+	n := batch.Length()
+	if n == 0 {
+		if a.curIdx >= 0 {
+			a.flush()
+			a.curIdx++
+		}
+		return
+	}
+
+	vec := batch.ColVec(int(inputIdxs[0]))
+	col := vec.Int64()
+	hasNulls := vec.HasNulls()
+	sel := batch.Selection()
+	for i := uint16(0); i < n; i++ {
+		rowIdx := i
+		if sel != nil {
+			rowIdx = sel[i]
+		}
+		if a.groups[rowIdx] {
+			if a.curIdx >= 0 {
+				a.flush()
+			}
+			a.curIdx++
+			a.sum = 0
+			a.foundNonNull = false
+		}
+		if hasNulls && vec.NullAt(rowIdx) {
+			continue
+		}
+		a.sum += col[rowIdx]
+		a.foundNonNull = true
+	}
+}
+
+// flush writes the result of the current group.
+func (a *sumIntAgg) flush() {
+	if !a.foundNonNull {
+		a.vec.SetNull(uint16(a.curIdx))
+		return
+	}
+	a.vec.UnsetNull(uint16(a.curIdx))
+	a.vec.Int64()[a.curIdx] = a.sum
+}
+
+// sumAgg implements the SUM and AVG aggregate functions. The sum of integers
+// and the average of integers and decimals are decimals.
+type sumAgg struct {
+	aggBase
+
+	// t is the type of the argument.
+	t   types.T
+	avg bool
+
+	foundNonNull bool
+	count        int64
+
+	// The sum of integers is accumulated in intSum as long as it doesn't
+	// overflow; after that, it is accumulated in decSum (large is set). The sum
+	// of decimals is always accumulated in decSum.
+	intSum   int64
+	large    bool
+	decSum   apd.Decimal
+	tmpDec   apd.Decimal
+	floatSum float64
+}
+
+// Compute is part of the aggregateFunc interface.
+func (a *sumAgg) Compute(batch ColBatch, inputIdxs []uint32) {
+	n := batch.Length()
+	if n == 0 {
+		if a.curIdx >= 0 {
+			a.flush()
+			a.curIdx++
+		}
+		return
+	}
+
+	vec := batch.ColVec(int(inputIdxs[0]))
+	hasNulls := vec.HasNulls()
+	sel := batch.Selection()
+	switch a.t {
+	case types.Decimal:
+		col := vec.Decimal()
+		for i := uint16(0); i < n; i++ {
+			rowIdx := i
+			if sel != nil {
+				rowIdx = sel[i]
+			}
+			if a.groups[rowIdx] {
+				if a.curIdx >= 0 {
+					a.flush()
+				}
+				a.curIdx++
+				a.reset()
+			}
+			if hasNulls && vec.NullAt(rowIdx) {
+				continue
+			}
+			if _, err := tree.ExactCtx.Add(&a.decSum, &a.decSum, &col[rowIdx]); err != nil {
+				panicError(err)
+			}
+			a.count++
+			a.foundNonNull = true
+		}
+	case types.Int64:
+		col := vec.Int64()
+		for i := uint16(0); i < n; i++ {
+			rowIdx := i
+			if sel != nil {
+				rowIdx = sel[i]
+			}
+			if a.groups[rowIdx] {
+				if a.curIdx >= 0 {
+					a.flush()
+				}
+				a.curIdx++
+				a.reset()
+			}
+			if hasNulls && vec.NullAt(rowIdx) {
+				continue
+			}
+			a.addInt64(col[rowIdx])
+			a.count++
+			a.foundNonNull = true
+		}
+	case types.Float64:
+		col := vec.Float64()
+		for i := uint16(0); i < n; i++ {
+			rowIdx := i
+			if sel != nil {
+				rowIdx = sel[i]
+			}
+			if a.groups[rowIdx] {
+				if a.curIdx >= 0 {
+					a.flush()
+				}
+				a.curIdx++
+				a.reset()
+			}
+			if hasNulls && vec.NullAt(rowIdx) {
+				continue
+			}
+			a.floatSum += col[rowIdx]
+			a.count++
+			a.foundNonNull = true
+		}
+	default:
+		panic(fmt.Sprintf("unhandled type %s", a.t))
+	}
+}
+
+// addInt64 adds an integer to the sum, switching to a decimal sum on
+// overflow.
+func (a *sumAgg) addInt64(v int64) {
+	if v == 0 {
+		return
+	}
+	if !a.large {
+		r, ok := arith.AddWithOverflow(a.intSum, v)
+		if ok {
+			a.intSum = r
+			return
+		}
+		// An overflow was detected; go to large integers, but keep the sum
+		// computed so far.
+		a.large = true
+		a.decSum.SetCoefficient(a.intSum).SetExponent(0)
+	}
+	a.tmpDec.SetCoefficient(v).SetExponent(0)
+	if _, err := tree.ExactCtx.Add(&a.decSum, &a.decSum, &a.tmpDec); err != nil {
+		panicError(err)
+	}
+}
+
+// reset prepares the aggregation of a new group.
+func (a *sumAgg) reset() {
+	a.foundNonNull = false
+	a.count = 0
+	a.intSum = 0
+	a.large = false
+	a.decSum.SetCoefficient(0).SetExponent(0)
+	a.floatSum = 0
+}
+
+// flush writes the result of the current group.
+func (a *sumAgg) flush() {
+	if !a.foundNonNull {
+		a.vec.SetNull(uint16(a.curIdx))
+		return
+	}
+	a.vec.UnsetNull(uint16(a.curIdx))
+	if a.t == types.Float64 {
+		res := a.floatSum
+		if a.avg {
+			res /= float64(a.count)
+		}
+		a.vec.Float64()[a.curIdx] = res
+		return
+	}
+
+	res := &a.vec.Decimal()[a.curIdx]
+	if a.t == types.Int64 && !a.large {
+		res.SetCoefficient(a.intSum).SetExponent(0)
+	} else {
+		res.Set(&a.decSum)
+	}
+	if a.avg {
+		a.tmpDec.SetCoefficient(a.count).SetExponent(0)
+		if _, err := tree.DecimalCtx.Quo(res, res, &a.tmpDec); err != nil {
+			panicError(err)
+		}
+	}
+}
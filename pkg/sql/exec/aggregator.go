@@ -0,0 +1,341 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/pkg/errors"
+)
+
+// orderedAggregator is an aggregator that performs arbitrary aggregations on
+// input ordered by a set of grouping columns. For each input batch, a
+// groupMarker first marks the tuples that start a new group; the aggregate
+// functions then process the batch, writing the result of each group that
+// ends directly to the output batch.
+//
+// Since a single input batch can finish up to ColBatchSize groups, the output
+// batch has room for twice as many values as are returned at once, so that the
+// aggregate functions never need to check for overflow. Once at least
+// ColBatchSize results have been written, the first ColBatchSize of them are
+// returned; the remaining ones are copied to the start of the output batch on
+// the next call to Next.
+type orderedAggregator struct {
+	input Operator
+
+	// isScalar is set if there are no grouping columns, in which case exactly
+	// one row is output, even if the input is empty.
+	isScalar bool
+	// inputDone is set once the input has been exhausted.
+	inputDone bool
+	// done is set once all the output has been returned.
+	done bool
+
+	aggCols [][]uint32
+
+	// scratch is the ColBatch to output and variables related to it. Aggregate
+	// functions write directly to this output batch.
+	scratch struct {
+		ColBatch
+		// resumeIdx is the index at which the aggregation functions should
+		// start writing to on the next iteration of Next().
+		resumeIdx int
+		// outputSize is the number of values returned in each output batch.
+		outputSize int
+	}
+
+	// groups is the vector of booleans that marks the first tuple of each
+	// group in the current input batch.
+	groups []bool
+	marker groupMarker
+
+	aggregateFuncs []aggregateFunc
+}
+
+var _ Operator = &orderedAggregator{}
+
+// NewOrderedAggregator creates an ordered aggregator on the given grouping
+// columns, of types groupTypes. The input must be ordered on the grouping
+// columns, so that all the tuples of a group are consecutive.
+//
+// The i-th aggregation is aggFns[i], computed on the columns aggCols[i], of
+// types aggTypes[i]. Each aggregation produces one output column; the types of
+// the output columns can be determined with AggregateOutputType.
+func NewOrderedAggregator(
+	input Operator,
+	groupCols []uint32,
+	groupTypes []types.T,
+	aggFns []AggregateFn,
+	aggCols [][]uint32,
+	aggTypes [][]types.T,
+) (Operator, error) {
+	if len(aggFns) != len(aggCols) || len(aggFns) != len(aggTypes) {
+		return nil, errors.Errorf(
+			"mismatched aggregation lengths: aggFns: %d, aggCols: %d, aggTypes: %d",
+			len(aggFns), len(aggCols), len(aggTypes),
+		)
+	}
+	if len(aggFns) == 0 {
+		return nil, errors.Errorf("no aggregations")
+	}
+	if len(groupCols) != len(groupTypes) {
+		return nil, errors.Errorf(
+			"mismatched grouping lengths: groupCols: %d, groupTypes: %d", len(groupCols), len(groupTypes),
+		)
+	}
+
+	a := &orderedAggregator{
+		input:          input,
+		isScalar:       len(groupCols) == 0,
+		aggCols:        aggCols,
+		groups:         make([]bool, ColBatchSize),
+		aggregateFuncs: make([]aggregateFunc, len(aggFns)),
+	}
+	outputTypes := make([]types.T, len(aggFns))
+	for i := range aggFns {
+		var err error
+		if outputTypes[i], err = AggregateOutputType(aggFns[i], aggTypes[i]); err != nil {
+			return nil, err
+		}
+		if a.aggregateFuncs[i], err = newAggregateFunc(aggFns[i], aggTypes[i]); err != nil {
+			return nil, err
+		}
+	}
+	if err := a.marker.init(groupCols, groupTypes); err != nil {
+		return nil, err
+	}
+
+	// The functions may write up to a whole input batch of groups past the
+	// output size; see the comment on orderedAggregator.
+	a.scratch.outputSize = ColBatchSize
+	a.scratch.ColBatch = NewMemBatchWithSize(outputTypes, 2*ColBatchSize)
+	for i, fn := range a.aggregateFuncs {
+		fn.Init(a.groups, a.scratch.ColVec(i))
+	}
+	return a, nil
+}
+
+// Init is part of the Operator interface.
+func (a *orderedAggregator) Init() {
+	a.input.Init()
+}
+
+// Next is part of the Operator interface.
+func (a *orderedAggregator) Next() ColBatch {
+	if a.done {
+		a.scratch.SetLength(0)
+		return a.scratch
+	}
+	if a.scratch.resumeIdx >= a.scratch.outputSize {
+		// Copy the overflow of the last output batch to the start of the output
+		// batch.
+		for i := 0; i < a.scratch.Width(); i++ {
+			vec := a.scratch.ColVec(i)
+			vec.Copy(vec, 0 /* destIdx */, a.scratch.outputSize, a.scratch.resumeIdx)
+		}
+		a.scratch.resumeIdx -= a.scratch.outputSize
+		if !a.inputDone {
+			// The functions are still aggregating a group, whose output index
+			// moves with the copy.
+			for _, fn := range a.aggregateFuncs {
+				fn.SetOutputIndex(a.scratch.resumeIdx)
+			}
+		}
+	}
+
+	for !a.inputDone && a.scratch.resumeIdx < a.scratch.outputSize {
+		batch := a.input.Next()
+		a.marker.mark(batch, a.groups)
+		for i, fn := range a.aggregateFuncs {
+			fn.Compute(batch, a.aggCols[i])
+		}
+		a.scratch.resumeIdx = a.aggregateFuncs[0].CurrentOutputIndex()
+		if batch.Length() == 0 {
+			a.inputDone = true
+		}
+	}
+
+	if a.scratch.resumeIdx < 0 {
+		// The input was empty.
+		a.scratch.resumeIdx = 0
+		if a.isScalar {
+			for _, fn := range a.aggregateFuncs {
+				fn.HandleEmptyInputScalar()
+			}
+			a.scratch.resumeIdx = 1
+		}
+	}
+
+	n := a.scratch.resumeIdx
+	if n > a.scratch.outputSize {
+		n = a.scratch.outputSize
+	}
+	if a.inputDone && a.scratch.resumeIdx <= a.scratch.outputSize {
+		a.done = true
+	}
+	a.scratch.SetLength(uint16(n))
+	return a.scratch
+}
+
+// groupMarker marks the tuples of the batches of a stream ordered on a set of
+// grouping columns which are the first of a group: the first tuple of the
+// stream, and the tuples whose grouping columns differ from the ones of the
+// previous tuple. NULLs are considered equal to each other.
+type groupMarker struct {
+	groupCols  []uint32
+	groupTypes []types.T
+
+	// last holds, for each grouping column, the value of the last tuple of
+	// the previous batch.
+	last []ColVec
+	// seenTuple is set once a tuple has been marked.
+	seenTuple bool
+}
+
+func (m *groupMarker) init(groupCols []uint32, groupTypes []types.T) error {
+	m.groupCols = groupCols
+	m.groupTypes = groupTypes
+	m.last = make([]ColVec, len(groupCols))
+	for i, t := range groupTypes {
+		switch t {
+		case types.Bool, types.Bytes, types.Decimal, types.Int64, types.Float64:
+		default:
+			return errors.Errorf("unhandled type %s", t)
+		}
+		m.last[i] = newMemColumn(t, 1)
+	}
+	return nil
+}
+
+// mark sets groups[i] for each selected tuple i of the batch to whether the
+// tuple is the first of a group.
+func (m *groupMarker) mark(batch ColBatch, groups []bool) {
+	n := batch.Length()
+	if n == 0 {
+		return
+	}
+	sel := batch.Selection()
+	for i := uint16(0); i < n; i++ {
+		rowIdx := i
+		if sel != nil {
+			rowIdx = sel[i]
+		}
+		groups[rowIdx] = false
+	}
+	firstIdx, lastIdx := uint16(0), n-1
+	if sel != nil {
+		firstIdx, lastIdx = sel[0], sel[n-1]
+	}
+	if !m.seenTuple {
+		groups[firstIdx] = true
+		m.seenTuple = true
+	}
+
+	for j, colIdx := range m.groupCols {
+		vec := batch.ColVec(int(colIdx))
+		hasNulls := vec.HasNulls()
+		last := m.last[j]
+		prevNull := last.NullAt(0)
+		switch m.groupTypes[j] {
+		case types.Bool:
+			col := vec.Bool()
+			prev := last.Bool()[0]
+			for i := uint16(0); i < n; i++ {
+				rowIdx := i
+				if sel != nil {
+					rowIdx = sel[i]
+				}
+				null := hasNulls && vec.NullAt(rowIdx)
+				if null != prevNull || (!null && compareBools(col[rowIdx], prev) != 0) {
+					groups[rowIdx] = true
+				}
+				prevNull = null
+				prev = col[rowIdx]
+			}
+		case types.Bytes:
+			col := vec.Bytes()
+			prev := last.Bytes()[0]
+			for i := uint16(0); i < n; i++ {
+				rowIdx := i
+				if sel != nil {
+					rowIdx = sel[i]
+				}
+				null := hasNulls && vec.NullAt(rowIdx)
+				if null != prevNull || (!null && !bytes.Equal(col[rowIdx], prev)) {
+					groups[rowIdx] = true
+				}
+				prevNull = null
+				prev = col[rowIdx]
+			}
+		case types.Decimal:
+			col := vec.Decimal()
+			prev := &last.Decimal()[0]
+			for i := uint16(0); i < n; i++ {
+				rowIdx := i
+				if sel != nil {
+					rowIdx = sel[i]
+				}
+				null := hasNulls && vec.NullAt(rowIdx)
+				if null != prevNull || (!null && compareDecimals(&col[rowIdx], prev) != 0) {
+					groups[rowIdx] = true
+				}
+				prevNull = null
+				prev = &col[rowIdx]
+			}
+		case types.Int64:
+			col := vec.Int64()
+			prev := last.Int64()[0]
+			for i := uint16(0); i < n; i++ {
+				rowIdx := i
+				if sel != nil {
+					rowIdx = sel[i]
+				}
+				null := hasNulls && vec.NullAt(rowIdx)
+				if null != prevNull || (!null && col[rowIdx] != prev) {
+					groups[rowIdx] = true
+				}
+				prevNull = null
+				prev = col[rowIdx]
+			}
+		case types.Float64:
+			col := vec.Float64()
+			prev := last.Float64()[0]
+			for i := uint16(0); i < n; i++ {
+				rowIdx := i
+				if sel != nil {
+					rowIdx = sel[i]
+				}
+				null := hasNulls && vec.NullAt(rowIdx)
+				if null != prevNull || (!null && compareFloat64s(col[rowIdx], prev) != 0) {
+					groups[rowIdx] = true
+				}
+				prevNull = null
+				prev = col[rowIdx]
+			}
+		default:
+			panic(fmt.Sprintf("unhandled type %s", m.groupTypes[j]))
+		}
+
+		// Remember the value of the last tuple for the next batch.
+		last.Copy(vec, 0 /* destIdx */, int(lastIdx), int(lastIdx)+1)
+		if m.groupTypes[j] == types.Bytes {
+			// The batch may be reused by the input; make a copy of the bytes.
+			lastBytes := last.Bytes()
+			lastBytes[0] = append([]byte(nil), lastBytes[0]...)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestOrderedAggregator(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	// The input is ordered on the first two columns.
+	input := tuples{
+		{1, "a", 10, 1.5, "x", true},
+		{1, "a", nil, 2.5, "y", false},
+		{1, "b", 7, nil, nil, nil},
+		{2, "b", -3, 4.0, "c", true},
+		{2, "b", 5, 1.0, "b", true},
+		{2, "b", 1, 1.0, "d", nil},
+		{nil, "c", nil, nil, nil, nil},
+		{nil, "c", 4, 2.0, "e", false},
+	}
+	typs := []types.T{types.Int64, types.Bytes, types.Int64, types.Float64, types.Bytes, types.Bool}
+
+	testCases := []struct {
+		description string
+		groupCols   []uint32
+		aggFns      []AggregateFn
+		aggCols     [][]uint32
+		expected    tuples
+	}{
+		{
+			description: "count",
+			groupCols:   []uint32{0, 1},
+			aggFns:      []AggregateFn{AnyNotNull, AnyNotNull, CountRows, Count},
+			aggCols:     [][]uint32{{0}, {1}, {}, {2}},
+			expected: tuples{
+				{1, "a", 2, 1},
+				{1, "b", 1, 1},
+				{2, "b", 3, 3},
+				{nil, "c", 2, 1},
+			},
+		},
+		{
+			description: "sum",
+			groupCols:   []uint32{0},
+			aggFns:      []AggregateFn{Sum, SumInt, Sum, Avg, Avg},
+			aggCols:     [][]uint32{{2}, {2}, {3}, {2}, {3}},
+			expected: tuples{
+				{"17", 17, 4.0, "8.5", 2.0},
+				{"3", 3, 6.0, "1", 2.0},
+				{"4", 4, 2.0, "4", 2.0},
+			},
+		},
+		{
+			description: "min-max",
+			groupCols:   []uint32{0},
+			aggFns:      []AggregateFn{Min, Max, Min, Max, BoolAnd, BoolOr},
+			aggCols:     [][]uint32{{2}, {3}, {4}, {4}, {5}, {5}},
+			expected: tuples{
+				{7, 2.5, "x", "y", false, true},
+				{-3, 4.0, "b", "d", true, true},
+				{4, 2.0, "e", "e", false, false},
+			},
+		},
+		{
+			description: "scalar",
+			aggFns:      []AggregateFn{CountRows, Sum, Max},
+			aggCols:     [][]uint32{{}, {2}, {4}},
+			expected: tuples{
+				{8, "24", "y"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			runTests(t, func(t *testing.T, batchSize uint16) {
+				groupTypes := make([]types.T, len(tc.groupCols))
+				for i, c := range tc.groupCols {
+					groupTypes[i] = typs[c]
+				}
+				aggTypes := make([][]types.T, len(tc.aggCols))
+				for i, cols := range tc.aggCols {
+					aggTypes[i] = make([]types.T, len(cols))
+					for j, c := range cols {
+						aggTypes[i][j] = typs[c]
+					}
+				}
+				op, err := NewOrderedAggregator(
+					newOpTestInput(batchSize, input, typs...),
+					tc.groupCols, groupTypes, tc.aggFns, tc.aggCols, aggTypes,
+				)
+				if err != nil {
+					t.Fatal(err)
+				}
+				assertTuplesEqual(t, tc.expected, collectTuples(op))
+			})
+		})
+	}
+}
+
+func TestOrderedAggregatorEmptyInput(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	aggFns := []AggregateFn{CountRows, Count, Sum}
+	aggCols := [][]uint32{{}, {0}, {0}}
+	aggTypes := [][]types.T{{}, {types.Int64}, {types.Int64}}
+
+	// A scalar aggregation produces a single row.
+	op, err := NewOrderedAggregator(
+		newOpTestInput(ColBatchSize, nil /* tuples */, types.Int64),
+		nil /* groupCols */, nil /* groupTypes */, aggFns, aggCols, aggTypes,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertTuplesEqual(t, tuples{{0, 0, nil}}, collectTuples(op))
+
+	// An aggregation with grouping columns produces no rows.
+	op, err = NewOrderedAggregator(
+		newOpTestInput(ColBatchSize, nil /* tuples */, types.Int64),
+		[]uint32{0}, []types.T{types.Int64}, aggFns, aggCols, aggTypes,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertTuplesEqual(t, nil, collectTuples(op))
+}
+
+func TestOrderedAggregatorManyGroups(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	// Use enough groups that the results span several output batches, and
+	// batches of groups of one or two tuples, so that the number of results
+	// per input batch varies.
+	const numGroups = 3*ColBatchSize + 5
+	var input, expected tuples
+	for i := 0; i < numGroups; i++ {
+		input = append(input, tuple{i, i})
+		if i%2 == 0 {
+			input = append(input, tuple{i, 1})
+			expected = append(expected, tuple{i, 2, fmt.Sprint(i + 1)})
+		} else {
+			expected = append(expected, tuple{i, 1, fmt.Sprint(i)})
+		}
+	}
+	for _, batchSize := range []uint16{7, ColBatchSize} {
+		t.Run(fmt.Sprintf("batchSize=%d", batchSize), func(t *testing.T) {
+			op, err := NewOrderedAggregator(
+				newOpTestInput(batchSize, input, types.Int64, types.Int64),
+				[]uint32{0}, []types.T{types.Int64},
+				[]AggregateFn{AnyNotNull, CountRows, Sum},
+				[][]uint32{{0}, {}, {1}},
+				[][]types.T{{types.Int64}, {}, {types.Int64}},
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertTuplesEqual(t, expected, collectTuples(op))
+		})
+	}
+}
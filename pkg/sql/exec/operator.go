@@ -0,0 +1,74 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package exec contains the vectorized execution engine: operators that
+// process data in batches of typed column vectors (ColBatch) rather than one
+// row at a time. Operators are arranged in trees, and each operator pulls
+// batches from its inputs by calling their Next method.
+//
+// The engine is plugged into DistSQL by distsqlrun, which converts rows into
+// batches (columnarizer) and batches back into rows (materializer).
+package exec
+
+// Operator is a column vector operator that produces a ColBatch as output.
+type Operator interface {
+	// Init initializes this operator. Will be called once at operator setup
+	// time. If an operator has an input operator, it's responsible for calling
+	// Init on that input operator as well.
+	Init()
+
+	// Next returns the next ColBatch from this operator. Once the operator is
+	// finished, it will return a ColBatch with length 0. Subsequent calls to
+	// Next at the end of the input stream return a ColBatch with length 0.
+	//
+	// Calling Next may invalidate the contents of the last ColBatch returned by
+	// Next.
+	//
+	// Operators don't return errors: an operator that encounters an error
+	// during execution panics with it by calling panicError. The panic is
+	// recovered at the root of the operator tree by CatchRuntimeError.
+	Next() ColBatch
+}
+
+// runtimeError wraps an error encountered by an operator during execution.
+type runtimeError struct {
+	err error
+}
+
+// panicError propagates the given error up the operator tree. See Operator.
+func panicError(err error) {
+	panic(runtimeError{err: err})
+}
+
+// PanicError propagates the given error up the operator tree. It is meant to
+// be used by Operators implemented outside this package.
+func PanicError(err error) {
+	panicError(err)
+}
+
+// CatchRuntimeError executes f and returns the error raised by any operator it
+// calls, if any. Panics that weren't raised by panicError are not recovered.
+func CatchRuntimeError(f func()) (retErr error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(runtimeError); ok {
+				retErr = e.err
+				return
+			}
+			panic(r)
+		}
+	}()
+	f()
+	return nil
+}
@@ -0,0 +1,284 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/cockroachdb/apd"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/arith"
+	"github.com/pkg/errors"
+)
+
+var errIntOutOfRange = pgerror.NewError(pgerror.CodeNumericValueOutOfRangeError, "integer out of range")
+
+// IsSupportedBinaryOperator returns whether projection operators can be built
+// for the given binary operator on operands of type t. The result of the
+// operation is always of type t.
+func IsSupportedBinaryOperator(op tree.BinaryOperator, t types.T) bool {
+	switch t {
+	case types.Decimal, types.Int64:
+		return op == tree.Plus || op == tree.Minus || op == tree.Mult
+	case types.Float64:
+		return op == tree.Plus || op == tree.Minus || op == tree.Mult || op == tree.Div
+	}
+	return false
+}
+
+// projOp is an Operator that evaluates an arithmetic operator on a column and
+// either another column or a constant, appending the results as a new column
+// to the batch. The result is NULL if any operand is NULL.
+type projOp struct {
+	input Operator
+
+	t         types.T
+	op        tree.BinaryOperator
+	col1Idx   int
+	outputIdx int
+
+	// The right operand: either the column at index col2Idx, or, if useConst is
+	// set, a constant. Only the constant field that corresponds to t is set.
+	col2Idx      int
+	useConst     bool
+	constDecimal apd.Decimal
+	constInt64   int64
+	constFloat64 float64
+}
+
+var _ Operator = &projOp{}
+
+// NewProjOp returns an Operator that computes `col1 <op> col2` for the columns
+// at indexes col1Idx and col2Idx, both of type t, and writes the result to the
+// column at index outputIdx. If the batches produced by the input don't have
+// a column at outputIdx yet, one is appended.
+func NewProjOp(
+	input Operator, t types.T, op tree.BinaryOperator, col1Idx int, col2Idx int, outputIdx int,
+) (Operator, error) {
+	if !IsSupportedBinaryOperator(op, t) {
+		return nil, errors.Errorf("unsupported binary operator %s on type %s", op, t)
+	}
+	return &projOp{
+		input:     input,
+		t:         t,
+		op:        op,
+		col1Idx:   col1Idx,
+		col2Idx:   col2Idx,
+		outputIdx: outputIdx,
+	}, nil
+}
+
+// NewProjConstOp returns an Operator that computes `col <op> constArg` for the
+// column at index colIdx, of type t, and writes the result to the column at
+// index outputIdx. The constant must be a non-NULL datum of a type that is
+// represented by t.
+func NewProjConstOp(
+	input Operator, t types.T, op tree.BinaryOperator, colIdx int, constArg tree.Datum, outputIdx int,
+) (Operator, error) {
+	if !IsSupportedBinaryOperator(op, t) {
+		return nil, errors.Errorf("unsupported binary operator %s on type %s", op, t)
+	}
+	p := &projOp{
+		input:     input,
+		t:         t,
+		op:        op,
+		col1Idx:   colIdx,
+		outputIdx: outputIdx,
+		useConst:  true,
+	}
+	ok := false
+	switch t {
+	case types.Decimal:
+		var d *tree.DDecimal
+		if d, ok = constArg.(*tree.DDecimal); ok {
+			p.constDecimal.Set(&d.Decimal)
+		}
+	case types.Int64:
+		var d *tree.DInt
+		if d, ok = constArg.(*tree.DInt); ok {
+			p.constInt64 = int64(*d)
+		}
+	case types.Float64:
+		var d *tree.DFloat
+		if d, ok = constArg.(*tree.DFloat); ok {
+			p.constFloat64 = float64(*d)
+		}
+	}
+	if !ok {
+		return nil, errors.Errorf("unexpected constant %s for type %s", constArg, t)
+	}
+	return p, nil
+}
+
+// Init is part of the Operator interface.
+func (p *projOp) Init() {
+	p.input.Init()
+}
+
+// Next is part of the Operator interface.
+func (p *projOp) Next() ColBatch {
+	batch := p.input.Next()
+	if p.outputIdx == batch.Width() {
+		batch.AppendCol(p.t)
+	}
+	n := batch.Length()
+	if n == 0 {
+		return batch
+	}
+
+	vec1 := batch.ColVec(p.col1Idx)
+	var vec2 ColVec
+	if !p.useConst {
+		vec2 = batch.ColVec(p.col2Idx)
+	}
+	outVec := batch.ColVec(p.outputIdx)
+	outVec.UnsetNulls()
+	hasNulls := vec1.HasNulls() || (vec2 != nil && vec2.HasNulls())
+	sel := batch.Selection()
+
+	switch p.t {
+	case types.Decimal:
+		col1, out := vec1.Decimal(), outVec.Decimal()
+		var col2 []apd.Decimal
+		if vec2 != nil {
+			col2 = vec2.Decimal()
+		}
+		for i := uint16(0); i < n; i++ {
+			rowIdx := i
+			if sel != nil {
+				rowIdx = sel[i]
+			}
+			if hasNulls && (vec1.NullAt(rowIdx) || (vec2 != nil && vec2.NullAt(rowIdx))) {
+				outVec.SetNull(rowIdx)
+				continue
+			}
+			right := &p.constDecimal
+			if col2 != nil {
+				right = &col2[rowIdx]
+			}
+			evalDecimalBinOp(p.op, &out[rowIdx], &col1[rowIdx], right)
+		}
+	case types.Int64:
+		col1, out := vec1.Int64(), outVec.Int64()
+		var col2 []int64
+		if vec2 != nil {
+			col2 = vec2.Int64()
+		}
+		for i := uint16(0); i < n; i++ {
+			rowIdx := i
+			if sel != nil {
+				rowIdx = sel[i]
+			}
+			if hasNulls && (vec1.NullAt(rowIdx) || (vec2 != nil && vec2.NullAt(rowIdx))) {
+				outVec.SetNull(rowIdx)
+				continue
+			}
+			right := p.constInt64
+			if col2 != nil {
+				right = col2[rowIdx]
+			}
+			out[rowIdx] = evalInt64BinOp(p.op, col1[rowIdx], right)
+		}
+	case types.Float64:
+		col1, out := vec1.Float64(), outVec.Float64()
+		var col2 []float64
+		if vec2 != nil {
+			col2 = vec2.Float64()
+		}
+		for i := uint16(0); i < n; i++ {
+			rowIdx := i
+			if sel != nil {
+				rowIdx = sel[i]
+			}
+			if hasNulls && (vec1.NullAt(rowIdx) || (vec2 != nil && vec2.NullAt(rowIdx))) {
+				outVec.SetNull(rowIdx)
+				continue
+			}
+			right := p.constFloat64
+			if col2 != nil {
+				right = col2[rowIdx]
+			}
+			out[rowIdx] = evalFloat64BinOp(p.op, col1[rowIdx], right)
+		}
+	default:
+		panic(fmt.Sprintf("unhandled type %s", p.t))
+	}
+	return batch
+}
+
+// The eval functions below implement the arithmetic operators with the same
+// semantics as the corresponding tree.BinOps.
+
+func evalInt64BinOp(op tree.BinaryOperator, a, b int64) int64 {
+	switch op {
+	case tree.Plus:
+		r, ok := arith.AddWithOverflow(a, b)
+		if !ok {
+			panicError(errIntOutOfRange)
+		}
+		return r
+	case tree.Minus:
+		if (b < 0 && a > math.MaxInt64+b) || (b > 0 && a < math.MinInt64+b) {
+			panicError(errIntOutOfRange)
+		}
+		return a - b
+	case tree.Mult:
+		c := a * b
+		if a == 0 || b == 0 || a == 1 || b == 1 {
+			// ignore
+		} else if a == math.MinInt64 || b == math.MinInt64 {
+			// This test is required to detect math.MinInt64 * -1.
+			panicError(errIntOutOfRange)
+		} else if c/b != a {
+			panicError(errIntOutOfRange)
+		}
+		return c
+	}
+	panic(fmt.Sprintf("unhandled operator %s", op))
+}
+
+func evalFloat64BinOp(op tree.BinaryOperator, a, b float64) float64 {
+	switch op {
+	case tree.Plus:
+		return a + b
+	case tree.Minus:
+		return a - b
+	case tree.Mult:
+		return a * b
+	case tree.Div:
+		return a / b
+	}
+	panic(fmt.Sprintf("unhandled operator %s", op))
+}
+
+func evalDecimalBinOp(op tree.BinaryOperator, res, a, b *apd.Decimal) {
+	var err error
+	switch op {
+	case tree.Plus:
+		_, err = tree.ExactCtx.Add(res, a, b)
+	case tree.Minus:
+		_, err = tree.ExactCtx.Sub(res, a, b)
+	case tree.Mult:
+		_, err = tree.ExactCtx.Mul(res, a, b)
+	default:
+		panic(fmt.Sprintf("unhandled operator %s", op))
+	}
+	if err != nil {
+		panicError(err)
+	}
+}
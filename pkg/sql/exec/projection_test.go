@@ -0,0 +1,98 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestProjOp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	runTests(t, func(t *testing.T, batchSize uint16) {
+		// @1 * @2, on floats.
+		input := tuples{{1.5, 2.0}, {nil, 1.0}, {3.0, -1.0}}
+		op, err := NewProjOp(
+			newOpTestInput(batchSize, input, types.Float64, types.Float64),
+			types.Float64, tree.Mult, 0, 1, 2, /* outputIdx */
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertTuplesEqual(t, tuples{
+			{1.5, 2.0, 3.0}, {nil, 1.0, nil}, {3.0, -1.0, -3.0},
+		}, collectTuples(op))
+
+		// @1 - 1, on decimals.
+		input = tuples{{"1.5"}, {nil}, {"-2"}}
+		op, err = NewProjConstOp(
+			newOpTestInput(batchSize, input, types.Decimal),
+			types.Decimal, tree.Minus, 0, &tree.DecimalOne, 1, /* outputIdx */
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertTuplesEqual(t, tuples{
+			{"1.5", "0.5"}, {nil, nil}, {"-2", "-3"},
+		}, collectTuples(op))
+
+		// (@1 + 1) - @2, on integers, with a filter on @1 in between. The
+		// projections only need to be computed for the selected tuples.
+		input = tuples{{1, 1}, {2, 5}, {3, 4}, {4, nil}}
+		op, err = NewProjConstOp(
+			newOpTestInput(batchSize, input, types.Int64, types.Int64),
+			types.Int64, tree.Plus, 0, tree.NewDInt(1), 2, /* outputIdx */
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		op, err = NewSelConstOp(op, types.Int64, tree.GE, 0 /* colIdx */, tree.NewDInt(2))
+		if err != nil {
+			t.Fatal(err)
+		}
+		op, err = NewProjOp(op, types.Int64, tree.Minus, 2, 1, 3 /* outputIdx */)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertTuplesEqual(t, tuples{
+			{2, 5, 3, -2}, {3, 4, 4, 0}, {4, nil, 5, nil},
+		}, collectTuples(op))
+	})
+}
+
+func TestProjOpOverflow(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	input := tuples{{1}, {math.MaxInt64}}
+	op, err := NewProjConstOp(
+		newOpTestInput(ColBatchSize, input, types.Int64),
+		types.Int64, tree.Plus, 0, tree.NewDInt(1), 1, /* outputIdx */
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = CatchRuntimeError(func() {
+		collectTuples(op)
+	})
+	if !testutils.IsError(err, "integer out of range") {
+		t.Fatalf("expected overflow error, got %v", err)
+	}
+}
@@ -0,0 +1,444 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+
+	"github.com/cockroachdb/apd"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/pkg/errors"
+)
+
+// cmpPredicate describes a comparison operator in terms of the results of a
+// three-way comparison: a comparison passes if the result of comparing its
+// operands is negative and lt is set, zero and eq is set, or positive and gt
+// is set.
+type cmpPredicate struct {
+	lt, eq, gt bool
+}
+
+func makeCmpPredicate(cmp tree.ComparisonOperator) (cmpPredicate, error) {
+	switch cmp {
+	case tree.EQ:
+		return cmpPredicate{eq: true}, nil
+	case tree.NE:
+		return cmpPredicate{lt: true, gt: true}, nil
+	case tree.LT:
+		return cmpPredicate{lt: true}, nil
+	case tree.LE:
+		return cmpPredicate{lt: true, eq: true}, nil
+	case tree.GT:
+		return cmpPredicate{gt: true}, nil
+	case tree.GE:
+		return cmpPredicate{gt: true, eq: true}, nil
+	}
+	return cmpPredicate{}, errors.Errorf("unsupported comparison operator %s", cmp)
+}
+
+func (p cmpPredicate) matches(c int) bool {
+	return (c < 0 && p.lt) || (c == 0 && p.eq) || (c > 0 && p.gt)
+}
+
+// IsSupportedComparison returns whether selection operators can be built for
+// the given comparison operator.
+func IsSupportedComparison(cmp tree.ComparisonOperator) bool {
+	_, err := makeCmpPredicate(cmp)
+	return err == nil
+}
+
+// The compare functions below implement three-way comparisons with the same
+// semantics as the Compare methods of the corresponding datums.
+
+func compareBools(a, b bool) int {
+	if a == b {
+		return 0
+	}
+	if !a {
+		return -1
+	}
+	return 1
+}
+
+func compareInt64s(a, b int64) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
+
+func compareFloat64s(a, b float64) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	if a == b {
+		return 0
+	}
+	// NaN sorts before non-NaN.
+	if math.IsNaN(a) {
+		if math.IsNaN(b) {
+			return 0
+		}
+		return -1
+	}
+	return 1
+}
+
+func compareDecimals(a, b *apd.Decimal) int {
+	// NaNs sort first.
+	if an, bn := a.Form == apd.NaN, b.Form == apd.NaN; an && !bn {
+		return -1
+	} else if !an && bn {
+		return 1
+	} else if an && bn {
+		return 0
+	}
+	return a.Cmp(b)
+}
+
+// selConstOp is an Operator that filters out the tuples of its input for
+// which the comparison between a column and a constant is not true. Tuples
+// with a NULL in the column are always filtered out.
+type selConstOp struct {
+	input Operator
+
+	t      types.T
+	colIdx int
+	pred   cmpPredicate
+
+	// The constant; only the field that corresponds to t is set.
+	constBool    bool
+	constBytes   []byte
+	constDecimal apd.Decimal
+	constInt64   int64
+	constFloat64 float64
+}
+
+var _ Operator = &selConstOp{}
+
+// NewSelConstOp returns an Operator that selects the tuples of its input for
+// which `col <cmp> constArg` is true, where col is the column at index colIdx,
+// of type t. The constant must be a non-NULL datum of a type that is
+// represented by t.
+func NewSelConstOp(
+	input Operator, t types.T, cmp tree.ComparisonOperator, colIdx int, constArg tree.Datum,
+) (Operator, error) {
+	pred, err := makeCmpPredicate(cmp)
+	if err != nil {
+		return nil, err
+	}
+	p := &selConstOp{input: input, t: t, colIdx: colIdx, pred: pred}
+	switch t {
+	case types.Bool:
+		d, ok := constArg.(*tree.DBool)
+		if !ok {
+			return nil, errors.Errorf("unexpected constant %s for type %s", constArg, t)
+		}
+		p.constBool = bool(*d)
+	case types.Bytes:
+		switch d := constArg.(type) {
+		case *tree.DString:
+			p.constBytes = []byte(*d)
+		case *tree.DBytes:
+			p.constBytes = []byte(*d)
+		default:
+			return nil, errors.Errorf("unexpected constant %s for type %s", constArg, t)
+		}
+	case types.Decimal:
+		d, ok := constArg.(*tree.DDecimal)
+		if !ok {
+			return nil, errors.Errorf("unexpected constant %s for type %s", constArg, t)
+		}
+		p.constDecimal.Set(&d.Decimal)
+	case types.Int64:
+		d, ok := constArg.(*tree.DInt)
+		if !ok {
+			return nil, errors.Errorf("unexpected constant %s for type %s", constArg, t)
+		}
+		p.constInt64 = int64(*d)
+	case types.Float64:
+		d, ok := constArg.(*tree.DFloat)
+		if !ok {
+			return nil, errors.Errorf("unexpected constant %s for type %s", constArg, t)
+		}
+		p.constFloat64 = float64(*d)
+	default:
+		return nil, errors.Errorf("unhandled type %s", t)
+	}
+	return p, nil
+}
+
+// Init is part of the Operator interface.
+func (p *selConstOp) Init() {
+	p.input.Init()
+}
+
+// Next is part of the Operator interface.
+func (p *selConstOp) Next() ColBatch {
+	for {
+		batch := p.input.Next()
+		n := batch.Length()
+		if n == 0 {
+			return batch
+		}
+
+		vec := batch.ColVec(p.colIdx)
+		hasNulls := vec.HasNulls()
+		sel := batch.Selection()
+		usesSel := sel != nil
+		if !usesSel {
+			batch.SetSelection(true)
+			sel = batch.Selection()
+		}
+
+		var idx uint16
+		switch p.t {
+		case types.Bool:
+			col := vec.Bool()
+			for i := uint16(0); i < n; i++ {
+				rowIdx := i
+				if usesSel {
+					rowIdx = sel[i]
+				}
+				if hasNulls && vec.NullAt(rowIdx) {
+					continue
+				}
+				if p.pred.matches(compareBools(col[rowIdx], p.constBool)) {
+					sel[idx] = rowIdx
+					idx++
+				}
+			}
+		case types.Bytes:
+			col := vec.Bytes()
+			for i := uint16(0); i < n; i++ {
+				rowIdx := i
+				if usesSel {
+					rowIdx = sel[i]
+				}
+				if hasNulls && vec.NullAt(rowIdx) {
+					continue
+				}
+				if p.pred.matches(bytes.Compare(col[rowIdx], p.constBytes)) {
+					sel[idx] = rowIdx
+					idx++
+				}
+			}
+		case types.Decimal:
+			col := vec.Decimal()
+			for i := uint16(0); i < n; i++ {
+				rowIdx := i
+				if usesSel {
+					rowIdx = sel[i]
+				}
+				if hasNulls && vec.NullAt(rowIdx) {
+					continue
+				}
+				if p.pred.matches(compareDecimals(&col[rowIdx], &p.constDecimal)) {
+					sel[idx] = rowIdx
+					idx++
+				}
+			}
+		case types.Int64:
+			col := vec.Int64()
+			for i := uint16(0); i < n; i++ {
+				rowIdx := i
+				if usesSel {
+					rowIdx = sel[i]
+				}
+				if hasNulls && vec.NullAt(rowIdx) {
+					continue
+				}
+				if p.pred.matches(compareInt64s(col[rowIdx], p.constInt64)) {
+					sel[idx] = rowIdx
+					idx++
+				}
+			}
+		case types.Float64:
+			col := vec.Float64()
+			for i := uint16(0); i < n; i++ {
+				rowIdx := i
+				if usesSel {
+					rowIdx = sel[i]
+				}
+				if hasNulls && vec.NullAt(rowIdx) {
+					continue
+				}
+				if p.pred.matches(compareFloat64s(col[rowIdx], p.constFloat64)) {
+					sel[idx] = rowIdx
+					idx++
+				}
+			}
+		default:
+			panic(fmt.Sprintf("unhandled type %s", p.t))
+		}
+
+		if idx > 0 {
+			batch.SetLength(idx)
+			return batch
+		}
+	}
+}
+
+// selOp is an Operator that filters out the tuples of its input for which the
+// comparison between two columns of the same type is not true. Tuples with a
+// NULL in either column are always filtered out.
+type selOp struct {
+	input Operator
+
+	t       types.T
+	col1Idx int
+	col2Idx int
+	pred    cmpPredicate
+}
+
+var _ Operator = &selOp{}
+
+// NewSelOp returns an Operator that selects the tuples of its input for which
+// `col1 <cmp> col2` is true, where col1 and col2 are the columns at indexes
+// col1Idx and col2Idx, both of type t.
+func NewSelOp(
+	input Operator, t types.T, cmp tree.ComparisonOperator, col1Idx int, col2Idx int,
+) (Operator, error) {
+	pred, err := makeCmpPredicate(cmp)
+	if err != nil {
+		return nil, err
+	}
+	switch t {
+	case types.Bool, types.Bytes, types.Decimal, types.Int64, types.Float64:
+	default:
+		return nil, errors.Errorf("unhandled type %s", t)
+	}
+	return &selOp{input: input, t: t, col1Idx: col1Idx, col2Idx: col2Idx, pred: pred}, nil
+}
+
+// Init is part of the Operator interface.
+func (p *selOp) Init() {
+	p.input.Init()
+}
+
+// Next is part of the Operator interface.
+func (p *selOp) Next() ColBatch {
+	for {
+		batch := p.input.Next()
+		n := batch.Length()
+		if n == 0 {
+			return batch
+		}
+
+		vec1, vec2 := batch.ColVec(p.col1Idx), batch.ColVec(p.col2Idx)
+		hasNulls := vec1.HasNulls() || vec2.HasNulls()
+		sel := batch.Selection()
+		usesSel := sel != nil
+		if !usesSel {
+			batch.SetSelection(true)
+			sel = batch.Selection()
+		}
+
+		var idx uint16
+		switch p.t {
+		case types.Bool:
+			col1, col2 := vec1.Bool(), vec2.Bool()
+			for i := uint16(0); i < n; i++ {
+				rowIdx := i
+				if usesSel {
+					rowIdx = sel[i]
+				}
+				if hasNulls && (vec1.NullAt(rowIdx) || vec2.NullAt(rowIdx)) {
+					continue
+				}
+				if p.pred.matches(compareBools(col1[rowIdx], col2[rowIdx])) {
+					sel[idx] = rowIdx
+					idx++
+				}
+			}
+		case types.Bytes:
+			col1, col2 := vec1.Bytes(), vec2.Bytes()
+			for i := uint16(0); i < n; i++ {
+				rowIdx := i
+				if usesSel {
+					rowIdx = sel[i]
+				}
+				if hasNulls && (vec1.NullAt(rowIdx) || vec2.NullAt(rowIdx)) {
+					continue
+				}
+				if p.pred.matches(bytes.Compare(col1[rowIdx], col2[rowIdx])) {
+					sel[idx] = rowIdx
+					idx++
+				}
+			}
+		case types.Decimal:
+			col1, col2 := vec1.Decimal(), vec2.Decimal()
+			for i := uint16(0); i < n; i++ {
+				rowIdx := i
+				if usesSel {
+					rowIdx = sel[i]
+				}
+				if hasNulls && (vec1.NullAt(rowIdx) || vec2.NullAt(rowIdx)) {
+					continue
+				}
+				if p.pred.matches(compareDecimals(&col1[rowIdx], &col2[rowIdx])) {
+					sel[idx] = rowIdx
+					idx++
+				}
+			}
+		case types.Int64:
+			col1, col2 := vec1.Int64(), vec2.Int64()
+			for i := uint16(0); i < n; i++ {
+				rowIdx := i
+				if usesSel {
+					rowIdx = sel[i]
+				}
+				if hasNulls && (vec1.NullAt(rowIdx) || vec2.NullAt(rowIdx)) {
+					continue
+				}
+				if p.pred.matches(compareInt64s(col1[rowIdx], col2[rowIdx])) {
+					sel[idx] = rowIdx
+					idx++
+				}
+			}
+		case types.Float64:
+			col1, col2 := vec1.Float64(), vec2.Float64()
+			for i := uint16(0); i < n; i++ {
+				rowIdx := i
+				if usesSel {
+					rowIdx = sel[i]
+				}
+				if hasNulls && (vec1.NullAt(rowIdx) || vec2.NullAt(rowIdx)) {
+					continue
+				}
+				if p.pred.matches(compareFloat64s(col1[rowIdx], col2[rowIdx])) {
+					sel[idx] = rowIdx
+					idx++
+				}
+			}
+		default:
+			panic(fmt.Sprintf("unhandled type %s", p.t))
+		}
+
+		if idx > 0 {
+			batch.SetLength(idx)
+			return batch
+		}
+	}
+}
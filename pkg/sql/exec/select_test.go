@@ -0,0 +1,166 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestSelConstOp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	input := tuples{
+		{0, "a"},
+		{1, "b"},
+		{nil, "c"},
+		{2, nil},
+		{3, "b"},
+		{4, "d"},
+	}
+	testCases := []struct {
+		description string
+		cmp         tree.ComparisonOperator
+		colIdx      int
+		t           types.T
+		constArg    tree.Datum
+		expected    tuples
+	}{
+		{
+			description: "lt",
+			cmp:         tree.LT,
+			colIdx:      0,
+			t:           types.Int64,
+			constArg:    tree.NewDInt(2),
+			expected:    tuples{{0, "a"}, {1, "b"}},
+		},
+		{
+			description: "ne",
+			cmp:         tree.NE,
+			colIdx:      0,
+			t:           types.Int64,
+			constArg:    tree.NewDInt(2),
+			expected:    tuples{{0, "a"}, {1, "b"}, {3, "b"}, {4, "d"}},
+		},
+		{
+			description: "ge",
+			cmp:         tree.GE,
+			colIdx:      0,
+			t:           types.Int64,
+			constArg:    tree.NewDInt(3),
+			expected:    tuples{{3, "b"}, {4, "d"}},
+		},
+		{
+			description: "bytes",
+			cmp:         tree.EQ,
+			colIdx:      1,
+			t:           types.Bytes,
+			constArg:    tree.NewDString("b"),
+			expected:    tuples{{1, "b"}, {3, "b"}},
+		},
+		{
+			description: "none",
+			cmp:         tree.GT,
+			colIdx:      1,
+			t:           types.Bytes,
+			constArg:    tree.NewDString("z"),
+			expected:    nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			runTests(t, func(t *testing.T, batchSize uint16) {
+				op, err := NewSelConstOp(
+					newOpTestInput(batchSize, input, types.Int64, types.Bytes),
+					tc.t, tc.cmp, tc.colIdx, tc.constArg,
+				)
+				if err != nil {
+					t.Fatal(err)
+				}
+				assertTuplesEqual(t, tc.expected, collectTuples(op))
+			})
+		})
+	}
+}
+
+func TestSelOp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	input := tuples{
+		{0, 1},
+		{1, 1},
+		{nil, 2},
+		{3, 2},
+		{2, nil},
+	}
+	testCases := []struct {
+		cmp      tree.ComparisonOperator
+		expected tuples
+	}{
+		{cmp: tree.LE, expected: tuples{{0, 1}, {1, 1}}},
+		{cmp: tree.GT, expected: tuples{{3, 2}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.cmp.String(), func(t *testing.T) {
+			runTests(t, func(t *testing.T, batchSize uint16) {
+				op, err := NewSelOp(
+					newOpTestInput(batchSize, input, types.Int64, types.Int64),
+					types.Int64, tc.cmp, 0, 1,
+				)
+				if err != nil {
+					t.Fatal(err)
+				}
+				assertTuplesEqual(t, tc.expected, collectTuples(op))
+			})
+		})
+	}
+}
+
+func TestSelNaN(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	// NaN sorts before all other values, and is equal to itself.
+	input := tuples{{math.NaN()}, {0.0}, {1.0}}
+	runTests(t, func(t *testing.T, batchSize uint16) {
+		op, err := NewSelConstOp(
+			newOpTestInput(batchSize, input, types.Float64),
+			types.Float64, tree.LT, 0 /* colIdx */, tree.NewDFloat(0.5),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res := collectTuples(op)
+		if len(res) != 2 || !math.IsNaN(res[0][0].(float64)) || res[1][0].(float64) != 0 {
+			t.Fatalf("unexpected result %v", res)
+		}
+
+		op, err = NewSelConstOp(
+			newOpTestInput(batchSize, input, types.Float64),
+			types.Float64, tree.EQ, 0 /* colIdx */, tree.NewDFloat(tree.DFloat(math.NaN())),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res = collectTuples(op)
+		if len(res) != 1 || !math.IsNaN(res[0][0].(float64)) {
+			t.Fatalf("unexpected result %v", res)
+		}
+	})
+}
@@ -0,0 +1,153 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+)
+
+// tuple is a row of values used in tests. A nil value represents NULL.
+// Values are given as ints for Int64 columns, float64s for Float64 columns,
+// bools for Bool columns and strings for Bytes and Decimal columns.
+type tuple []interface{}
+
+// tuples represents a table of values.
+type tuples []tuple
+
+// opTestInput is an Operator that produces the given tuples in batches of at
+// most batchSize tuples.
+type opTestInput struct {
+	typs      []types.T
+	batchSize uint16
+	tuples    tuples
+
+	batch ColBatch
+}
+
+var _ Operator = &opTestInput{}
+
+func newOpTestInput(batchSize uint16, tuples tuples, typs ...types.T) *opTestInput {
+	return &opTestInput{typs: typs, batchSize: batchSize, tuples: tuples}
+}
+
+func (s *opTestInput) Init() {
+	s.batch = NewMemBatch(s.typs)
+}
+
+func (s *opTestInput) Next() ColBatch {
+	n := len(s.tuples)
+	if n > int(s.batchSize) {
+		n = int(s.batchSize)
+	}
+	tups := s.tuples[:n]
+	s.tuples = s.tuples[n:]
+
+	s.batch.SetSelection(false)
+	for j, vec := range s.batch.ColVecs()[:len(s.typs)] {
+		vec.UnsetNulls()
+		for i := range tups {
+			v := tups[i][j]
+			if v == nil {
+				vec.SetNull(uint16(i))
+				continue
+			}
+			switch s.typs[j] {
+			case types.Bool:
+				vec.Bool()[i] = v.(bool)
+			case types.Bytes:
+				vec.Bytes()[i] = []byte(v.(string))
+			case types.Decimal:
+				if _, _, err := vec.Decimal()[i].SetString(v.(string)); err != nil {
+					panic(err)
+				}
+			case types.Int64:
+				vec.Int64()[i] = int64(v.(int))
+			case types.Float64:
+				vec.Float64()[i] = v.(float64)
+			default:
+				panic(fmt.Sprintf("unhandled type %s", s.typs[j]))
+			}
+		}
+	}
+	s.batch.SetLength(uint16(n))
+	return s.batch
+}
+
+// collectTuples runs the operator until it is exhausted and returns the
+// selected tuples of all the batches it produced, with values of the same Go
+// types as the ones in a tuple.
+func collectTuples(op Operator) tuples {
+	var res tuples
+	op.Init()
+	for {
+		batch := op.Next()
+		n := batch.Length()
+		if n == 0 {
+			return res
+		}
+		sel := batch.Selection()
+		for i := uint16(0); i < n; i++ {
+			rowIdx := i
+			if sel != nil {
+				rowIdx = sel[i]
+			}
+			tup := make(tuple, batch.Width())
+			for j, vec := range batch.ColVecs() {
+				if vec.HasNulls() && vec.NullAt(rowIdx) {
+					continue
+				}
+				switch vec.Type() {
+				case types.Bool:
+					tup[j] = vec.Bool()[rowIdx]
+				case types.Bytes:
+					tup[j] = string(vec.Bytes()[rowIdx])
+				case types.Decimal:
+					d := &vec.Decimal()[rowIdx]
+					tup[j] = d.String()
+				case types.Int64:
+					tup[j] = int(vec.Int64()[rowIdx])
+				case types.Float64:
+					tup[j] = vec.Float64()[rowIdx]
+				default:
+					panic(fmt.Sprintf("unhandled type %s", vec.Type()))
+				}
+			}
+			res = append(res, tup)
+		}
+	}
+}
+
+// runTests runs the given test function for each of a set of batch sizes.
+func runTests(t *testing.T, f func(t *testing.T, batchSize uint16)) {
+	for _, batchSize := range []uint16{1, 2, 3, ColBatchSize} {
+		t.Run(fmt.Sprintf("batchSize=%d", batchSize), func(t *testing.T) {
+			f(t, batchSize)
+		})
+	}
+}
+
+func assertTuplesEqual(t *testing.T, expected, actual tuples) {
+	t.Helper()
+	if len(expected) == 0 && len(actual) == 0 {
+		return
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected:\n%v\ngot:\n%v", expected, actual)
+	}
+}
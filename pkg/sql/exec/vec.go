@@ -0,0 +1,189 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/apd"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+)
+
+// ColVec is an interface that represents a column vector that's accessible by
+// Go native types.
+type ColVec interface {
+	Nulls
+
+	// Type returns the type of the data stored in this ColVec.
+	Type() types.T
+
+	// Bool returns a bool list.
+	Bool() []bool
+	// Int64 returns an int64 slice.
+	Int64() []int64
+	// Float64 returns a float64 slice.
+	Float64() []float64
+	// Bytes returns a []byte slice.
+	Bytes() [][]byte
+	// Decimal returns an apd.Decimal slice.
+	Decimal() []apd.Decimal
+
+	// Col returns the raw, typeless backing storage for this ColVec.
+	Col() interface{}
+
+	// Copy copies src[srcStartIdx:srcEndIdx] into this ColVec, starting at
+	// destIdx. Both ColVecs must be of the same type. Bytes values are shared,
+	// not deep-copied.
+	Copy(src ColVec, destIdx, srcStartIdx, srcEndIdx int)
+}
+
+// Nulls represents a list of potentially nullable values.
+type Nulls interface {
+	// HasNulls returns true if the column has any null values.
+	HasNulls() bool
+
+	// NullAt takes in a uint16 and returns true if the ith value of the column is
+	// null.
+	NullAt(i uint16) bool
+	// SetNull takes in a uint16 and sets the ith value of the column to null.
+	SetNull(i uint16)
+	// UnsetNull takes in a uint16 and sets the ith value of the column to
+	// non-null.
+	UnsetNull(i uint16)
+
+	// UnsetNulls sets the column to have 0 null values.
+	UnsetNulls()
+}
+
+// memColumn is a simple pass-through implementation of ColVec that just casts
+// a generic interface{} to the proper type when requested.
+type memColumn struct {
+	t   types.T
+	col interface{}
+
+	// nulls is a bitmap of the null values of the column; the value at index i
+	// is null if bit i%64 of nulls[i/64] is set.
+	nulls []uint64
+	// hasNulls is true if any bit of nulls is set.
+	hasNulls bool
+}
+
+var _ ColVec = &memColumn{}
+
+// newMemColumn returns a new memColumn, initialized with a length.
+func newMemColumn(t types.T, n int) *memColumn {
+	nulls := make([]uint64, (n-1)/64+1)
+
+	switch t {
+	case types.Bool:
+		return &memColumn{t: t, col: make([]bool, n), nulls: nulls}
+	case types.Bytes:
+		return &memColumn{t: t, col: make([][]byte, n), nulls: nulls}
+	case types.Decimal:
+		return &memColumn{t: t, col: make([]apd.Decimal, n), nulls: nulls}
+	case types.Int64:
+		return &memColumn{t: t, col: make([]int64, n), nulls: nulls}
+	case types.Float64:
+		return &memColumn{t: t, col: make([]float64, n), nulls: nulls}
+	default:
+		panic(fmt.Sprintf("unhandled type %s", t))
+	}
+}
+
+func (m *memColumn) HasNulls() bool {
+	return m.hasNulls
+}
+
+func (m *memColumn) NullAt(i uint16) bool {
+	return m.nulls[i/64]&(1<<(i%64)) != 0
+}
+
+func (m *memColumn) SetNull(i uint16) {
+	m.hasNulls = true
+	m.nulls[i/64] |= 1 << (i % 64)
+}
+
+func (m *memColumn) UnsetNull(i uint16) {
+	m.nulls[i/64] &^= 1 << (i % 64)
+}
+
+func (m *memColumn) UnsetNulls() {
+	if !m.hasNulls {
+		return
+	}
+	m.hasNulls = false
+	for i := range m.nulls {
+		m.nulls[i] = 0
+	}
+}
+
+func (m *memColumn) Type() types.T {
+	return m.t
+}
+
+func (m *memColumn) Bool() []bool {
+	return m.col.([]bool)
+}
+
+func (m *memColumn) Int64() []int64 {
+	return m.col.([]int64)
+}
+
+func (m *memColumn) Float64() []float64 {
+	return m.col.([]float64)
+}
+
+func (m *memColumn) Bytes() [][]byte {
+	return m.col.([][]byte)
+}
+
+func (m *memColumn) Decimal() []apd.Decimal {
+	return m.col.([]apd.Decimal)
+}
+
+func (m *memColumn) Col() interface{} {
+	return m.col
+}
+
+func (m *memColumn) Copy(src ColVec, destIdx, srcStartIdx, srcEndIdx int) {
+	switch m.t {
+	case types.Bool:
+		copy(m.Bool()[destIdx:], src.Bool()[srcStartIdx:srcEndIdx])
+	case types.Bytes:
+		copy(m.Bytes()[destIdx:], src.Bytes()[srcStartIdx:srcEndIdx])
+	case types.Decimal:
+		dst, srcCol := m.Decimal()[destIdx:], src.Decimal()[srcStartIdx:srcEndIdx]
+		for i := range srcCol {
+			dst[i].Set(&srcCol[i])
+		}
+	case types.Int64:
+		copy(m.Int64()[destIdx:], src.Int64()[srcStartIdx:srcEndIdx])
+	case types.Float64:
+		copy(m.Float64()[destIdx:], src.Float64()[srcStartIdx:srcEndIdx])
+	default:
+		panic(fmt.Sprintf("unhandled type %s", m.t))
+	}
+
+	// Copy the nulls one by one; the source and destination ranges are
+	// generally not aligned on the bitmap words.
+	for i := 0; i < srcEndIdx-srcStartIdx; i++ {
+		dst := uint16(destIdx + i)
+		if src.HasNulls() && src.NullAt(uint16(srcStartIdx+i)) {
+			m.SetNull(dst)
+		} else {
+			m.UnsetNull(dst)
+		}
+	}
+}
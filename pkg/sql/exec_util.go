@@ -235,6 +235,30 @@ var (
 		Measurement: "SQL Statements",
 		Unit:        metric.Unit_COUNT,
 	}
+	MetaAppQuotaThrottled = metric.Metadata{
+		Name:        "sql.app_quota.throttled.count",
+		Help:        "Number of statements delayed because their application exceeded its quota",
+		Measurement: "SQL Statements",
+		Unit:        metric.Unit_COUNT,
+	}
+	MetaAppQuotaRejected = metric.Metadata{
+		Name:        "sql.app_quota.rejected.count",
+		Help:        "Number of statements rejected because their application exceeded its quota",
+		Measurement: "SQL Statements",
+		Unit:        metric.Unit_COUNT,
+	}
+	MetaQueryCacheHit = metric.Metadata{
+		Name:        "sql.query_cache.hit.count",
+		Help:        "Number of statements served from the query cache",
+		Measurement: "SQL Statements",
+		Unit:        metric.Unit_COUNT,
+	}
+	MetaQueryCacheMiss = metric.Metadata{
+		Name:        "sql.query_cache.miss.count",
+		Help:        "Number of cacheable statements whose result wasn't in the query cache",
+		Measurement: "SQL Statements",
+		Unit:        metric.Unit_COUNT,
+	}
 )
 
 // NodeInfo contains metadata about the executing node and cluster.
@@ -267,6 +291,7 @@ type ExecutorConfig struct {
 	VirtualSchemas   *VirtualSchemaHolder
 	DistSQLPlanner   *DistSQLPlanner
 	TableStatsCache  *stats.TableStatisticsCache
+	StatsRefresher   *stats.Refresher
 	ExecLogger       *log.SecondaryLogger
 	AuditLogger      *log.SecondaryLogger
 	InternalExecutor *InternalExecutor
@@ -292,6 +317,14 @@ func (ec *ExecutorConfig) Organization() string {
 	return ClusterOrganization.Get(&ec.Settings.SV)
 }
 
+// notifyMutation informs the automatic statistics refresher, if there is one,
+// that rowsAffected rows of the given table were modified.
+func (ec *ExecutorConfig) notifyMutation(tableID sqlbase.ID, rowsAffected int) {
+	if ec.StatsRefresher != nil {
+		ec.StatsRefresher.NotifyMutation(tableID, rowsAffected)
+	}
+}
+
 var _ base.ModuleTestingKnobs = &ExecutorTestingKnobs{}
 
 // ModuleTestingKnobs is part of the base.ModuleTestingKnobs interface.
@@ -1489,6 +1522,14 @@ func (m *sessionDataMutator) SetBytesEncodeFormat(val sessiondata.BytesEncodeFor
 	m.data.BytesEncodeFormat = val
 }
 
+func (m *sessionDataMutator) SetSerialNormalizationMode(val sessiondata.SerialNormalizationMode) {
+	m.data.SerialNormalizationMode = val
+}
+
+func (m *sessionDataMutator) SetForeignKeyCascadesLimit(val int64) {
+	m.data.ForeignKeyCascadesLimit = val
+}
+
 func (m *sessionDataMutator) SetDatabase(dbName string) {
 	m.data.Database = dbName
 }
@@ -1513,10 +1554,22 @@ func (m *sessionDataMutator) SetZigzagJoinEnabled(val bool) {
 	m.data.ZigzagJoinEnabled = val
 }
 
+func (m *sessionDataMutator) SetOptimizerUseNotVisibleIndexes(val bool) {
+	m.data.OptimizerUseNotVisibleIndexes = val
+}
+
+func (m *sessionDataMutator) SetOptimizerUseMultiColStats(val bool) {
+	m.data.OptimizerUseMultiColStats = val
+}
+
 func (m *sessionDataMutator) SetOptimizerMode(val sessiondata.OptimizerMode) {
 	m.data.OptimizerMode = val
 }
 
+func (m *sessionDataMutator) SetVectorize(val sessiondata.VectorizeExecMode) {
+	m.data.Vectorize = val
+}
+
 func (m *sessionDataMutator) SetSafeUpdates(val bool) {
 	m.data.SafeUpdates = val
 }
@@ -1537,6 +1590,14 @@ func (m *sessionDataMutator) SetStmtTimeout(timeout time.Duration) {
 	m.data.StmtTimeout = timeout
 }
 
+func (m *sessionDataMutator) SetIdleInTransactionSessionTimeout(timeout time.Duration) {
+	m.data.IdleInTransactionSessionTimeout = timeout
+}
+
+func (m *sessionDataMutator) SetIdleSessionTimeout(timeout time.Duration) {
+	m.data.IdleSessionTimeout = timeout
+}
+
 // RecordLatestSequenceValue records that value to which the session incremented
 // a sequence.
 func (m *sessionDataMutator) RecordLatestSequenceVal(seqID uint32, val int64) {
@@ -1578,15 +1639,19 @@ func (s *sqlStatsCollectorImpl) PhaseTimes() *phaseTimes {
 
 // RecordStatement is part of the sqlStatsCollector interface.
 func (s *sqlStatsCollectorImpl) RecordStatement(
+	ctx context.Context,
 	stmt Statement,
+	plan *planTop,
 	distSQLUsed bool,
 	automaticRetryCount int,
 	numRows int,
+	kvStats client.KVStats,
 	err error,
 	parseLat, planLat, runLat, svcLat, ovhLat float64,
 ) {
+	s.appStats.recordResources(numRows, kvStats)
 	s.appStats.recordStatement(
-		stmt, distSQLUsed, automaticRetryCount, numRows, err,
+		ctx, stmt, plan, distSQLUsed, automaticRetryCount, numRows, kvStats, err,
 		parseLat, planLat, runLat, svcLat, ovhLat)
 }
 
@@ -17,6 +17,7 @@ package sql
 import (
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
@@ -69,6 +70,14 @@ type EngineMetrics struct {
 	SQLExecLatency        *metric.Histogram
 	DistSQLServiceLatency *metric.Histogram
 	SQLServiceLatency     *metric.Histogram
+
+	// Statements exceeding their application's quota.
+	AppQuotaThrottledCount *metric.Counter
+	AppQuotaRejectedCount  *metric.Counter
+
+	// Cacheable statements served from and missing the query cache.
+	QueryCacheHitCount  *metric.Counter
+	QueryCacheMissCount *metric.Counter
 }
 
 // EngineMetrics implements the metric.Struct interface
@@ -84,6 +93,7 @@ func (EngineMetrics) MetricStruct() {}
 // - automaticRetryCount is the count of implicit txn retries
 //   so far.
 // - result is the result set computed by the query/statement.
+// - kvStats describes the KV requests sent while running the statement.
 // - err is the error encountered, if any.
 func (ex *connExecutor) recordStatementSummary(
 	planner *planner,
@@ -91,6 +101,7 @@ func (ex *connExecutor) recordStatementSummary(
 	distSQLUsed bool,
 	automaticRetryCount int,
 	rowsAffected int,
+	kvStats client.KVStats,
 	err error,
 	m *EngineMetrics,
 ) {
@@ -135,7 +146,7 @@ func (ex *connExecutor) recordStatementSummary(
 	}
 
 	planner.statsCollector.RecordStatement(
-		stmt, distSQLUsed, automaticRetryCount, rowsAffected, err,
+		planner.EvalContext().Ctx(), stmt, &planner.curPlan, distSQLUsed, automaticRetryCount, rowsAffected, kvStats, err,
 		parseLat, planLat, runLat, svcLat, execOverhead,
 	)
 
@@ -351,10 +351,12 @@ func doExpandPlan(
 		n.source, err = doExpandPlan(ctx, p, noParams, n.source)
 
 	case *valuesNode:
+	case *cteScanNode:
 	case *alterIndexNode:
 	case *alterTableNode:
 	case *alterSequenceNode:
 	case *alterUserSetPasswordNode:
+	case *controlScheduleNode:
 	case *scrubNode:
 	case *createDatabaseNode:
 	case *createIndexNode:
@@ -367,6 +369,7 @@ func doExpandPlan(
 	case *dropTableNode:
 	case *dropViewNode:
 	case *dropSequenceNode:
+	case *refreshMaterializedViewNode:
 	case *DropUserNode:
 	case *zeroNode:
 	case *unaryNode:
@@ -857,10 +860,12 @@ func (p *planner) simplifyOrderings(plan planNode, usefulOrdering sqlbase.Column
 		n.rows = p.simplifyOrderings(n.rows, nil)
 
 	case *valuesNode:
+	case *cteScanNode:
 	case *alterIndexNode:
 	case *alterTableNode:
 	case *alterSequenceNode:
 	case *alterUserSetPasswordNode:
+	case *controlScheduleNode:
 	case *scrubNode:
 	case *createDatabaseNode:
 	case *createIndexNode:
@@ -873,6 +878,7 @@ func (p *planner) simplifyOrderings(plan planNode, usefulOrdering sqlbase.Column
 	case *dropTableNode:
 	case *dropViewNode:
 	case *dropSequenceNode:
+	case *refreshMaterializedViewNode:
 	case *DropUserNode:
 	case *zeroNode:
 	case *unaryNode:
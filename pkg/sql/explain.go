@@ -46,9 +46,31 @@ func (p *planner) Explain(ctx context.Context, n *tree.Explain) (planNode, error
 			analyze: opts.Flags.Contains(tree.ExplainFlagAnalyze),
 		}, nil
 
+	case tree.ExplainDebug:
+		if !opts.Flags.Contains(tree.ExplainFlagAnalyze) {
+			return nil, errors.New("EXPLAIN (DEBUG) only supported with ANALYZE")
+		}
+		plan, err := p.newPlan(ctx, n.Statement, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &explainDistSQLNode{
+			plan:    plan,
+			analyze: true,
+			debug:   true,
+		}, nil
+
 	case tree.ExplainPlan:
 		if opts.Flags.Contains(tree.ExplainFlagAnalyze) {
-			return nil, errors.New("EXPLAIN ANALYZE only supported with (DISTSQL) option")
+			plan, err := p.newPlan(ctx, n.Statement, nil)
+			if err != nil {
+				return nil, err
+			}
+			return &explainDistSQLNode{
+				plan:     plan,
+				analyze:  true,
+				planTree: true,
+			}, nil
 		}
 		// We may want to show placeholder types, so allow missing values.
 		p.semaCtx.Placeholders.PermitUnassigned()
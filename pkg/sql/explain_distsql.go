@@ -16,11 +16,15 @@ package sql
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlrun"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/cockroach/pkg/util/treeprinter"
 )
 
 // explainDistSQLNode is a planNode that wraps a plan and returns
@@ -35,16 +39,26 @@ type explainDistSQLNode struct {
 	// returned by the node.
 	analyze bool
 
+	// If debug is set (which implies analyze), CPU profiles are collected on
+	// all the nodes executing the plan, and the node returns a bundle of
+	// debugging information, one row per file, instead of a single row.
+	debug bool
+
+	// If planTree is set (which implies analyze), the node returns the
+	// processors of the plan as a tree annotated with their statistics, one row
+	// per line like EXPLAIN (PLAN), instead of a url.
+	planTree bool
+
 	run explainDistSQLRun
 }
 
 // explainDistSQLRun contains the run-time state of explainDistSQLNode during local execution.
 type explainDistSQLRun struct {
-	// The single row returned by the node.
-	values tree.Datums
+	// The rows returned by the node.
+	rows []tree.Datums
 
-	// done is set if Next() was called.
-	done bool
+	// next is the index of the next row to be returned.
+	next int
 }
 
 func (n *explainDistSQLNode) startExec(params runParams) error {
@@ -69,6 +83,7 @@ func (n *explainDistSQLNode) startExec(params runParams) error {
 	distSQLPlanner.FinalizePlan(&planCtx, &plan)
 
 	var spans []tracing.RecordedSpan
+	var cpuProfiles []distsqlrun.RemoteProducerMetadata_CPUProfile
 	if n.analyze {
 		// If tracing is already enabled, don't call StopTracing at the end.
 		shouldStopTracing := !params.extendedEvalCtx.Tracing.Enabled()
@@ -101,7 +116,23 @@ func (n *explainDistSQLNode) startExec(params runParams) error {
 				_ = execCfg.Clock.Update(ts)
 			},
 		)
+		var gatewayProfiler *distsqlrun.CPUProfiler
+		if n.debug {
+			// Remote nodes profile their own flows. The gateway's part of the plan
+			// runs in this process, so we profile it here.
+			planCtx.collectCPUProfile = true
+			if gatewayProfiler, err = distsqlrun.StartCPUProfile(); err != nil {
+				log.Warningf(params.ctx, "unable to collect CPU profile: %s", err)
+			}
+		}
 		distSQLPlanner.Run(&planCtx, params.p.txn, &plan, recv, params.p.ExtendedEvalContext())
+		if gatewayProfiler != nil {
+			cpuProfiles = append(cpuProfiles, distsqlrun.RemoteProducerMetadata_CPUProfile{
+				NodeID:  params.extendedEvalCtx.NodeID,
+				Profile: gatewayProfiler.Stop(),
+			})
+		}
+		cpuProfiles = append(cpuProfiles, recv.cpuProfiles...)
 
 		spans = params.extendedEvalCtx.Tracing.getRecording()
 		if shouldStopTracing {
@@ -112,28 +143,71 @@ func (n *explainDistSQLNode) startExec(params runParams) error {
 	}
 
 	flows := plan.GenerateFlowSpecs(params.extendedEvalCtx.NodeID)
+	if n.planTree {
+		entries, err := distsqlrun.GeneratePlanTreeWithSpans(flows, spans)
+		if err != nil {
+			return err
+		}
+		tp := treeprinter.New()
+		// nodes keeps track of the current processor on each level.
+		nodes := []treeprinter.Node{tp}
+		for _, entry := range entries {
+			if entry.Processor != "" {
+				nodes = append(nodes[:entry.Level+1], nodes[entry.Level].Child(entry.Processor))
+			} else {
+				tp.AddEmptyLine()
+			}
+		}
+		for i, treeRow := range tp.FormattedRows() {
+			n.run.rows = append(n.run.rows, tree.Datums{
+				tree.NewDString(treeRow),
+				tree.NewDString(entries[i].Field),
+				tree.NewDString(entries[i].Description),
+			})
+		}
+		return nil
+	}
+
 	planJSON, planURL, err := distsqlrun.GeneratePlanDiagramURLWithSpans(flows, spans)
 	if err != nil {
 		return err
 	}
 
-	n.run.values = tree.Datums{
-		tree.MakeDBool(tree.DBool(auto)),
-		tree.NewDString(planURL.String()),
-		tree.NewDString(planJSON),
+	if !n.debug {
+		n.run.rows = []tree.Datums{{
+			tree.MakeDBool(tree.DBool(auto)),
+			tree.NewDString(planURL.String()),
+			tree.NewDString(planJSON),
+		}}
+		return nil
+	}
+
+	gateway := params.extendedEvalCtx.NodeID
+	addFile := func(nodeID roachpb.NodeID, name string, contents string) {
+		n.run.rows = append(n.run.rows, tree.Datums{
+			tree.NewDInt(tree.DInt(nodeID)),
+			tree.NewDString(name),
+			tree.NewDBytes(tree.DBytes(contents)),
+		})
+	}
+	addFile(gateway, "distsql.json", planJSON)
+	addFile(gateway, "distsql.url", planURL.String())
+	addFile(gateway, "trace.txt", tracing.FormatRecordedSpans(spans))
+	for _, p := range cpuProfiles {
+		addFile(p.NodeID, fmt.Sprintf("cpu.n%d.pprof", p.NodeID), string(p.Profile))
 	}
 	return nil
 }
 
 func (n *explainDistSQLNode) Next(runParams) (bool, error) {
-	if n.run.done {
+	if n.run.next >= len(n.run.rows) {
 		return false, nil
 	}
-	n.run.done = true
+	n.run.next++
 	return true, nil
 }
 
-func (n *explainDistSQLNode) Values() tree.Datums { return n.run.values }
+func (n *explainDistSQLNode) Values() tree.Datums { return n.run.rows[n.run.next-1] }
 func (n *explainDistSQLNode) Close(ctx context.Context) {
 	// If we analyzed the statement, we relinquished ownership of the plan to a
 	// distSQLWrapper.
@@ -76,7 +76,8 @@ func (p *planner) makeExplainPlanNodeWithPlan(
 	subqueryPlans []subquery,
 ) (planNode, error) {
 	flags := explainFlags{
-		symbolicVars: opts.Flags.Contains(tree.ExplainFlagSymVars),
+		symbolicVars:     opts.Flags.Contains(tree.ExplainFlagSymVars),
+		recommendIndexes: opts.Flags.Contains(tree.ExplainFlagRecommend),
 	}
 	if opts.Flags.Contains(tree.ExplainFlagVerbose) {
 		flags.showMetadata = true
@@ -193,6 +194,10 @@ type explainFlags struct {
 	// showTypes indicates whether to print the type of embedded
 	// expressions and result columns.
 	showTypes bool
+
+	// recommendIndexes indicates whether to annotate the scans reading a
+	// whole table with an index that would let them read less.
+	recommendIndexes bool
 }
 
 // explainFlags represents the run-time state of the EXPLAIN logic.
@@ -213,6 +218,10 @@ type explainer struct {
 
 	// explainEntry accumulates entries (nodes or attributes).
 	entries []explainEntry
+
+	// recommendations holds the indexes recommended for the scans of the
+	// plan, if recommendIndexes is set.
+	recommendations map[*scanNode]string
 }
 
 var emptyString = tree.NewDString("")
@@ -275,6 +284,10 @@ func (p *planner) populateExplain(
 
 func (e *explainer) populateEntries(ctx context.Context, plan planNode, subqueryPlans []subquery) {
 	e.entries = nil
+	e.recommendations = nil
+	if e.recommendIndexes {
+		e.recommendations = recommendIndexes(ctx, plan, subqueryPlans).recommendations
+	}
 	observer := e.observer()
 
 	// If there are any subqueries in the plan, we enclose both the main
@@ -299,11 +312,11 @@ func (e *explainer) populateEntries(ctx context.Context, plan planNode, subquery
 		} else if subqueryPlans[i].started {
 			e.expr("subquery", "result", -1, subqueryPlans[i].result)
 		}
-		_ = e.leaveNode("subquery", subqueryPlans[i].plan)
+		_ = e.leaveNode("subquery", nil /* plan */)
 	}
 
 	if len(subqueryPlans) > 0 {
-		_ = e.leaveNode("root", plan)
+		_ = e.leaveNode("root", nil /* plan */)
 	}
 }
 
@@ -387,7 +400,12 @@ func (e *explainer) attr(nodeName, fieldName, attr string) {
 }
 
 // leaveNode implements the planObserver interface.
-func (e *explainer) leaveNode(name string, _ planNode) error {
+func (e *explainer) leaveNode(name string, plan planNode) error {
+	if n, ok := plan.(*scanNode); ok {
+		if rec, ok := e.recommendations[n]; ok {
+			e.attr(name, "index recommendation", rec)
+		}
+	}
 	e.level--
 	return nil
 }
@@ -0,0 +1,287 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+)
+
+// indexRecommender finds the scans of a plan that read a whole table because
+// no index matches the columns their filter constrains or the ordering
+// required of them, and suggests an index for each of them.
+//
+// This is intentionally simple: it only considers conjunctions of comparisons
+// of columns with constants, and sorts placed directly on top of a scan. It
+// never recommends an index if an existing index could already serve the
+// scan, even if the planner decided not to use it.
+type indexRecommender struct {
+	// recommendations maps the scans needing an index to the CREATE INDEX
+	// statement that would create it.
+	recommendations map[*scanNode]string
+	// scans lists the scans needing an index in the order they were found.
+	scans []*scanNode
+	// orderings holds the orderings required of the scans placed directly
+	// under a sort.
+	orderings map[*scanNode]sqlbase.ColumnOrdering
+}
+
+// recommendIndexes finds the indexes recommended for the scans of the given
+// plan and subqueries.
+func recommendIndexes(
+	ctx context.Context, plan planNode, subqueryPlans []subquery,
+) *indexRecommender {
+	r := &indexRecommender{orderings: make(map[*scanNode]sqlbase.ColumnOrdering)}
+	observer := planObserver{enterNode: r.enterNode}
+	_ = walkPlan(ctx, plan, observer)
+	for i := range subqueryPlans {
+		if subqueryPlans[i].plan != nil {
+			_ = walkPlan(ctx, subqueryPlans[i].plan, observer)
+		}
+	}
+	return r
+}
+
+// indexRecommendationsForPlan returns the distinct CREATE INDEX statements
+// recommended for the given plan.
+func indexRecommendationsForPlan(ctx context.Context, plan *planTop) []string {
+	if plan.plan == nil {
+		return nil
+	}
+	r := recommendIndexes(ctx, plan.plan, plan.subqueryPlans)
+	var res []string
+	for _, n := range r.scans {
+		rec := r.recommendations[n]
+		found := false
+		for _, s := range res {
+			if s == rec {
+				found = true
+				break
+			}
+		}
+		if !found {
+			res = append(res, rec)
+		}
+	}
+	return res
+}
+
+func (r *indexRecommender) enterNode(_ context.Context, _ string, p planNode) (bool, error) {
+	switch n := p.(type) {
+	case *explainPlanNode, *explainDistSQLNode:
+		// EXPLAIN makes its own recommendations for the plans it describes.
+		return false, nil
+	case *sortNode:
+		if s, ok := n.plan.(*scanNode); ok && n.needSort {
+			r.orderings[s] = n.ordering
+		}
+	case *scanNode:
+		if rec := r.recommendForScan(n); rec != "" {
+			if r.recommendations == nil {
+				r.recommendations = make(map[*scanNode]string)
+			}
+			r.recommendations[n] = rec
+			r.scans = append(r.scans, n)
+		}
+	}
+	return true, nil
+}
+
+// recommendForScan returns the CREATE INDEX statement recommended for the
+// given scan, or an empty string if none is.
+func (r *indexRecommender) recommendForScan(n *scanNode) string {
+	if n.desc.IsVirtualTable() || n.specifiedIndex != nil {
+		return ""
+	}
+	if len(n.spans) != 1 || !n.spans[0].EqualValue(n.desc.IndexSpan(n.index.ID)) {
+		// The scan is already constrained.
+		return ""
+	}
+
+	eqCols, rangeCol := constrainedColumns(n)
+	indexes := n.desc.AllNonDropIndexes()
+
+	var cols []sqlbase.ColumnID
+	var dirs []encoding.Direction
+	if len(eqCols) > 0 || rangeCol != 0 {
+		for i := range indexes {
+			if len(indexes[i].ColumnIDs) == 0 {
+				continue
+			}
+			first := indexes[i].ColumnIDs[0]
+			if first == rangeCol {
+				return ""
+			}
+			for _, id := range eqCols {
+				if first == id {
+					return ""
+				}
+			}
+		}
+		cols = append(cols, eqCols...)
+		for range eqCols {
+			dirs = append(dirs, encoding.Ascending)
+		}
+		if rangeCol != 0 {
+			cols = append(cols, rangeCol)
+			dirs = append(dirs, encoding.Ascending)
+		}
+	}
+
+	// Append the ordering required of the scan, unless a range constraint
+	// comes first and would prevent the index from providing it.
+	if ordering := r.orderings[n]; len(ordering) > 0 && rangeCol == 0 {
+		for _, o := range ordering {
+			id := n.cols[o.ColIdx].ID
+			if !containsColumnID(cols, id) {
+				cols = append(cols, id)
+				dirs = append(dirs, o.Direction)
+			}
+		}
+		if len(eqCols) == 0 {
+			for i := range indexes {
+				if indexProvidesOrdering(&indexes[i], cols, dirs) {
+					return ""
+				}
+			}
+		}
+	}
+
+	if len(cols) == 0 {
+		return ""
+	}
+	return formatIndexRecommendation(n.desc, cols, dirs)
+}
+
+// constrainedColumns returns the columns of the given scan compared for
+// equality with constants by its filter, and a column compared by inequality
+// with a constant, if any.
+func constrainedColumns(n *scanNode) (eqCols []sqlbase.ColumnID, rangeCol sqlbase.ColumnID) {
+	var visit func(e tree.Expr)
+	visit = func(e tree.Expr) {
+		switch t := e.(type) {
+		case *tree.AndExpr:
+			visit(t.Left)
+			visit(t.Right)
+		case *tree.ParenExpr:
+			visit(t.Expr)
+		case *tree.ComparisonExpr:
+			v, ok := t.Left.(*tree.IndexedVar)
+			if !ok || !isConstantOperand(t.Right) {
+				return
+			}
+			id := n.cols[v.Idx].ID
+			switch t.Operator {
+			case tree.EQ, tree.In, tree.IsNotDistinctFrom:
+				if !containsColumnID(eqCols, id) {
+					eqCols = append(eqCols, id)
+				}
+			case tree.LT, tree.GT, tree.LE, tree.GE:
+				if rangeCol == 0 {
+					rangeCol = id
+				}
+			}
+		}
+	}
+	if n.filter != nil {
+		visit(n.filter)
+	}
+	if containsColumnID(eqCols, rangeCol) {
+		rangeCol = 0
+	}
+	return eqCols, rangeCol
+}
+
+// isConstantOperand returns whether the given operand of a comparison has the
+// same value for every row.
+func isConstantOperand(e tree.Expr) bool {
+	switch t := e.(type) {
+	case tree.Datum, *tree.Placeholder:
+		return true
+	case *tree.Tuple:
+		for _, expr := range t.Exprs {
+			if !isConstantOperand(expr) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// indexProvidesOrdering returns whether scanning the given index, forward or
+// in reverse, yields rows in the given ordering.
+func indexProvidesOrdering(
+	index *sqlbase.IndexDescriptor, cols []sqlbase.ColumnID, dirs []encoding.Direction,
+) bool {
+	if len(index.ColumnIDs) < len(cols) {
+		return false
+	}
+	forward, reverse := true, true
+	for i, id := range cols {
+		if index.ColumnIDs[i] != id {
+			return false
+		}
+		dir, err := index.ColumnDirections[i].ToEncodingDirection()
+		if err != nil {
+			return false
+		}
+		if dir == dirs[i] {
+			reverse = false
+		} else {
+			forward = false
+		}
+	}
+	return forward || reverse
+}
+
+func containsColumnID(ids []sqlbase.ColumnID, id sqlbase.ColumnID) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+// formatIndexRecommendation returns the CREATE INDEX statement for the given
+// columns of a table.
+func formatIndexRecommendation(
+	desc *sqlbase.TableDescriptor, cols []sqlbase.ColumnID, dirs []encoding.Direction,
+) string {
+	var buf bytes.Buffer
+	buf.WriteString("CREATE INDEX ON ")
+	buf.WriteString(tree.NameString(desc.Name))
+	buf.WriteString(" (")
+	for i, id := range cols {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		col, err := desc.FindColumnByID(id)
+		if err != nil {
+			return ""
+		}
+		buf.WriteString(tree.NameString(col.Name))
+		if dirs[i] == encoding.Descending {
+			buf.WriteString(" DESC")
+		}
+	}
+	buf.WriteString(")")
+	return buf.String()
+}
@@ -0,0 +1,224 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/pkg/errors"
+)
+
+var indexUsageStatsEnabled = settings.RegisterBoolSetting(
+	"sql.metrics.index_usage_stats.enabled",
+	"collect per-index read statistics",
+	true,
+)
+
+var indexUsageStatsFlushInterval = settings.RegisterValidatedDurationSetting(
+	"sql.metrics.index_usage_stats.flush_interval",
+	"interval at which each node adds the index reads it collected to system.index_usage_statistics",
+	time.Minute,
+	func(v time.Duration) error {
+		if v < time.Second {
+			return errors.Errorf("cannot set sql.metrics.index_usage_stats.flush_interval to less than 1s: %s", v)
+		}
+		return nil
+	},
+)
+
+type indexUsageKey struct {
+	tableID sqlbase.ID
+	indexID sqlbase.IndexID
+}
+
+type indexUsage struct {
+	reads    int64
+	lastRead time.Time
+}
+
+func (u *indexUsage) add(o indexUsage) {
+	u.reads += o.reads
+	if o.lastRead.After(u.lastRead) {
+		u.lastRead = o.lastRead
+	}
+}
+
+// indexUsageStats accumulates the reads of indexes by the statements run on
+// this node until they're flushed to system.index_usage_statistics.
+type indexUsageStats struct {
+	syncutil.Mutex
+	// unflushed holds the reads recorded since the last flush.
+	unflushed map[indexUsageKey]indexUsage
+}
+
+// merge adds the given reads to the unflushed ones.
+func (s *indexUsageStats) merge(reads map[indexUsageKey]indexUsage) {
+	s.Lock()
+	defer s.Unlock()
+	if s.unflushed == nil {
+		s.unflushed = make(map[indexUsageKey]indexUsage, len(reads))
+	}
+	for k, r := range reads {
+		u := s.unflushed[k]
+		u.add(r)
+		s.unflushed[k] = u
+	}
+}
+
+// snapshot returns a copy of the unflushed reads.
+func (s *indexUsageStats) snapshot() map[indexUsageKey]indexUsage {
+	s.Lock()
+	defer s.Unlock()
+	reads := make(map[indexUsageKey]indexUsage, len(s.unflushed))
+	for k, r := range s.unflushed {
+		reads[k] = r
+	}
+	return reads
+}
+
+// recordIndexUsage records a read of every index scanned by the given plan
+// and its subqueries.
+func (s *sqlStats) recordIndexUsage(ctx context.Context, plan *planTop) {
+	if !indexUsageStatsEnabled.Get(&s.st.SV) {
+		return
+	}
+	now := timeutil.Now()
+	reads := make(map[indexUsageKey]indexUsage)
+	observer := planObserver{
+		enterNode: func(_ context.Context, _ string, p planNode) (bool, error) {
+			switch n := p.(type) {
+			case *explainPlanNode:
+				// EXPLAIN doesn't run the plan it describes.
+				return false, nil
+			case *explainDistSQLNode:
+				return n.analyze, nil
+			case *scanNode:
+				if n.desc.IsVirtualTable() {
+					return true, nil
+				}
+				k := indexUsageKey{tableID: n.desc.ID, indexID: n.index.ID}
+				u := reads[k]
+				u.add(indexUsage{reads: 1, lastRead: now})
+				reads[k] = u
+			}
+			return true, nil
+		},
+	}
+	_ = walkPlan(ctx, plan.plan, observer)
+	for i := range plan.subqueryPlans {
+		if plan.subqueryPlans[i].plan != nil {
+			_ = walkPlan(ctx, plan.subqueryPlans[i].plan, observer)
+		}
+	}
+	if len(reads) > 0 {
+		s.indexUsage.merge(reads)
+	}
+}
+
+// flushIndexUsage adds the unflushed index reads to the rows of this node in
+// system.index_usage_statistics. If this fails, the reads are kept for the
+// next flush.
+func (s *sqlStats) flushIndexUsage(ctx context.Context, cfg *ExecutorConfig) error {
+	s.indexUsage.Lock()
+	reads := s.indexUsage.unflushed
+	s.indexUsage.unflushed = nil
+	s.indexUsage.Unlock()
+	if len(reads) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`INSERT INTO system.index_usage_statistics ` +
+		`("tableID", "indexID", "nodeID", "totalReads", "lastRead") VALUES `)
+	args := make([]interface{}, 0, len(reads)*5)
+	for k, r := range reads {
+		if len(args) > 0 {
+			buf.WriteString(", ")
+		}
+		n := len(args)
+		fmt.Fprintf(&buf, "($%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5)
+		args = append(args, int64(k.tableID), int64(k.indexID), int64(cfg.NodeID.Get()), r.reads, r.lastRead)
+	}
+	buf.WriteString(` ON CONFLICT ("tableID", "indexID", "nodeID") DO UPDATE SET ` +
+		`"totalReads" = index_usage_statistics."totalReads" + excluded."totalReads", ` +
+		`"lastRead" = greatest(index_usage_statistics."lastRead", excluded."lastRead")`)
+
+	if _, err := cfg.InternalExecutor.Exec(
+		ctx, "flush-index-usage-stats", nil /* txn */, buf.String(), args...,
+	); err != nil {
+		s.indexUsage.merge(reads)
+		return err
+	}
+	return nil
+}
+
+// PeriodicallyFlushIndexUsageStats runs a loop writing the index reads
+// collected on this node to system.index_usage_statistics.
+func (s *Server) PeriodicallyFlushIndexUsageStats(ctx context.Context, stopper *stop.Stopper) {
+	stopper.RunWorker(ctx, func(ctx context.Context) {
+		var timer timeutil.Timer
+		defer timer.Stop()
+		for {
+			timer.Reset(indexUsageStatsFlushInterval.Get(&s.cfg.Settings.SV))
+			select {
+			case <-stopper.ShouldQuiesce():
+				return
+			case <-timer.C:
+				timer.Read = true
+			}
+			if err := s.sqlStats.flushIndexUsage(ctx, s.cfg); err != nil {
+				log.Warningf(ctx, "failed to flush index usage statistics: %v", err)
+			}
+		}
+	})
+}
+
+// indexUsageForTables returns the reads of every index recorded cluster-wide,
+// including those not yet flushed by this node.
+func (s *sqlStats) indexUsageForTables(
+	ctx context.Context, p *planner,
+) (map[indexUsageKey]indexUsage, error) {
+	rows, _ /* cols */, err := p.ExtendedEvalContext().ExecCfg.InternalExecutor.Query(
+		ctx, "crdb-internal-index-usage-statistics", p.txn,
+		`SELECT "tableID", "indexID", sum("totalReads")::INT, max("lastRead") `+
+			`FROM system.index_usage_statistics GROUP BY "tableID", "indexID"`)
+	if err != nil {
+		return nil, err
+	}
+	reads := s.indexUsage.snapshot()
+	for _, r := range rows {
+		k := indexUsageKey{
+			tableID: sqlbase.ID(tree.MustBeDInt(r[0])),
+			indexID: sqlbase.IndexID(tree.MustBeDInt(r[1])),
+		}
+		u := reads[k]
+		u.add(indexUsage{
+			reads:    int64(tree.MustBeDInt(r[2])),
+			lastRead: r[3].(*tree.DTimestamp).Time,
+		})
+		reads[k] = u
+	}
+	return reads, nil
+}
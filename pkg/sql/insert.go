@@ -462,6 +462,9 @@ func (n *insertNode) BatchedNext(params runParams) (bool, error) {
 		n.run.done = true
 	}
 
+	// Possibly initiate a refresh of the table statistics.
+	params.extendedEvalCtx.ExecCfg.notifyMutation(n.run.ti.tableDesc().ID, n.run.rowCount)
+
 	return n.run.rowCount > 0, nil
 }
 
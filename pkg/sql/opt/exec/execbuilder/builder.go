@@ -15,6 +15,7 @@
 package execbuilder
 
 import (
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -31,6 +32,12 @@ type Builder struct {
 	// expressions we built. Each entry is associated with a tree.Subquery
 	// expression node.
 	subqueries []exec.Subquery
+
+	// outerValues contains the values of outer columns that are bound by an
+	// enclosing apply join. It is only set when building the right side of an
+	// apply join for a given left row (see buildApplyJoin); variables that refer
+	// to these columns are replaced by the corresponding values.
+	outerValues map[opt.ColumnID]tree.Datum
 }
 
 // New constructs an instance of the execution node builder using the
@@ -125,13 +125,17 @@ func (b *Builder) buildRelational(ev memo.ExprView) (execPlan, error) {
 	case opt.MergeJoinOp:
 		ep, err = b.buildMergeJoin(ev)
 
+	case opt.Max1RowOp:
+		ep, err = b.buildMax1Row(ev)
+
 	default:
 		if ev.IsJoinNonApply() {
 			ep, err = b.buildHashJoin(ev)
 			break
 		}
 		if ev.IsJoinApply() {
-			return execPlan{}, errors.Errorf("could not decorrelate subquery")
+			ep, err = b.buildApplyJoin(ev)
+			break
 		}
 		return execPlan{}, errors.Errorf("unsupported relational op %s", ev.Operator())
 	}
@@ -365,6 +369,97 @@ func (b *Builder) buildMergeJoin(ev memo.ExprView) (execPlan, error) {
 	return ep, nil
 }
 
+// buildApplyJoin builds a join whose right side refers to columns of the left
+// side. The right side cannot be planned ahead of time; instead, it is planned
+// again for each left row, with the outer column references replaced by the
+// values in that row.
+func (b *Builder) buildApplyJoin(ev memo.ExprView) (execPlan, error) {
+	var joinType sqlbase.JoinType
+	switch ev.Operator() {
+	case opt.InnerJoinApplyOp:
+		joinType = sqlbase.InnerJoin
+	case opt.LeftJoinApplyOp:
+		joinType = sqlbase.LeftOuterJoin
+	case opt.SemiJoinApplyOp:
+		joinType = sqlbase.LeftSemiJoin
+	case opt.AntiJoinApplyOp:
+		joinType = sqlbase.LeftAntiJoin
+	default:
+		// Right and full apply joins would need to produce the right rows that
+		// don't match any left row, which requires an uncorrelated right side.
+		return execPlan{}, errors.Errorf("could not decorrelate subquery")
+	}
+
+	leftPlan, err := b.buildRelational(ev.Child(0))
+	if err != nil {
+		return execPlan{}, err
+	}
+
+	// The plans for the right side always produce the right output columns in
+	// increasing ColumnID order.
+	md := ev.Metadata()
+	right := ev.Child(1)
+	var rightCols opt.ColList
+	var rightColumns sqlbase.ResultColumns
+	right.Logical().Relational.OutputCols.ForEach(func(i int) {
+		col := opt.ColumnID(i)
+		rightCols = append(rightCols, col)
+		rightColumns = append(rightColumns, sqlbase.ResultColumn{
+			Name: md.ColumnLabel(col),
+			Typ:  md.ColumnType(col),
+		})
+	})
+
+	numLeftCols := leftPlan.outputCols.Len()
+	allCols := leftPlan.outputCols.Copy()
+	for i, col := range rightCols {
+		allCols.Set(int(col), numLeftCols+i)
+	}
+	ctx := buildScalarCtx{
+		ivh:     tree.MakeIndexedVarHelper(nil /* container */, allCols.Len()),
+		ivarMap: allCols,
+	}
+	onExpr, err := b.buildScalar(&ctx, ev.Child(2))
+	if err != nil {
+		return execPlan{}, err
+	}
+
+	planRightSide := func(leftRow tree.Datums) (exec.Node, error) {
+		rb := Builder{
+			factory:     b.factory,
+			ev:          right,
+			outerValues: make(map[opt.ColumnID]tree.Datum, len(b.outerValues)+numLeftCols),
+		}
+		for col, d := range b.outerValues {
+			rb.outerValues[col] = d
+		}
+		leftPlan.outputCols.ForEach(func(col, ord int) {
+			rb.outerValues[opt.ColumnID(col)] = leftRow[ord]
+		})
+		rightPlan, err := rb.buildRelational(right)
+		if err != nil {
+			return nil, err
+		}
+		if len(rb.subqueries) > 0 {
+			return nil, errors.Errorf("subqueries in the input of an apply join are not supported")
+		}
+		return rb.ensureColumns(rightPlan, rightCols)
+	}
+
+	ep := execPlan{outputCols: allCols}
+	if joinType == sqlbase.LeftSemiJoin || joinType == sqlbase.LeftAntiJoin {
+		// For semi and anti join, only the left columns are output.
+		ep.outputCols = leftPlan.outputCols
+	}
+	ep.root, err = b.factory.ConstructApplyJoin(
+		joinType, leftPlan.root, rightColumns, onExpr, planRightSide,
+	)
+	if err != nil {
+		return execPlan{}, err
+	}
+	return ep, nil
+}
+
 // initJoinBuild builds the inputs to the join as well as the ON expression.
 func (b *Builder) initJoinBuild(
 	leftChild memo.ExprView,
@@ -603,6 +698,18 @@ func (b *Builder) buildRowNumber(ev memo.ExprView) (execPlan, error) {
 	return execPlan{root: node, outputCols: outputCols}, nil
 }
 
+func (b *Builder) buildMax1Row(ev memo.ExprView) (execPlan, error) {
+	input, err := b.buildRelational(ev.Child(0))
+	if err != nil {
+		return execPlan{}, err
+	}
+	node, err := b.factory.ConstructMax1Row(input.root)
+	if err != nil {
+		return execPlan{}, err
+	}
+	return execPlan{root: node, outputCols: input.outputCols}, nil
+}
+
 func (b *Builder) buildIndexJoin(ev memo.ExprView) (execPlan, error) {
 	var err error
 	// If the index join child is a limit and/or sort operator then flip the order
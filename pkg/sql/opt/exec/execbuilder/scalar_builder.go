@@ -96,7 +96,15 @@ func (b *Builder) buildNull(ctx *buildScalarCtx, ev memo.ExprView) (tree.TypedEx
 }
 
 func (b *Builder) buildVariable(ctx *buildScalarCtx, ev memo.ExprView) (tree.TypedExpr, error) {
-	return b.indexedVar(ctx, ev.Metadata(), ev.Private().(opt.ColumnID)), nil
+	md := ev.Metadata()
+	colID := ev.Private().(opt.ColumnID)
+	if _, ok := ctx.ivarMap.Get(int(colID)); !ok {
+		if d, ok := b.outerValues[colID]; ok {
+			// The variable refers to a column bound by an enclosing apply join.
+			return tree.ReType(d, md.ColumnType(colID))
+		}
+	}
+	return b.indexedVar(ctx, md, colID), nil
 }
 
 func (b *Builder) indexedVar(
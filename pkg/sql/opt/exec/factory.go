@@ -100,6 +100,20 @@ type Factory interface {
 		leftOrdering, rightOrdering sqlbase.ColumnOrdering,
 	) (Node, error)
 
+	// ConstructApplyJoin returns a node that runs an apply join between the
+	// results of the left input node and a right side that depends on the
+	// values of each left row. For each left row, the right side is planned by
+	// calling planRightSide; the resulting node must produce the given
+	// rightColumns. The ON expression can refer to columns from both sides using
+	// IndexedVars (first the left columns, then the right columns).
+	ConstructApplyJoin(
+		joinType sqlbase.JoinType,
+		left Node,
+		rightColumns sqlbase.ResultColumns,
+		onCond tree.TypedExpr,
+		planRightSide ApplyJoinPlanRightSideFn,
+	) (Node, error)
+
 	// ConstructGroupBy returns a node that runs an aggregation. If group columns
 	// are specified, a set of aggregations is performed for each group of values
 	// on those columns (otherwise there is a single group).
@@ -130,6 +144,11 @@ type Factory interface {
 	// set to nil.
 	ConstructLimit(input Node, limit, offset tree.TypedExpr) (Node, error)
 
+	// ConstructMax1Row returns a node that permits at most one row from the
+	// given input node, returning an error at runtime if the node tries to
+	// return more than one row.
+	ConstructMax1Row(input Node) (Node, error)
+
 	// RenameColumns modifies the column names of a node.
 	RenameColumns(input Node, colNames []string) (Node, error)
 
@@ -146,6 +165,10 @@ type Factory interface {
 	ConstructShowTrace(typ tree.ShowTraceType, compact bool, input Node) (Node, error)
 }
 
+// ApplyJoinPlanRightSideFn plans the right side of an apply join for the given
+// left row (see ConstructApplyJoin).
+type ApplyJoinPlanRightSideFn func(leftRow tree.Datums) (Node, error)
+
 // Subquery encapsulates information about a subquery that is part of a plan.
 type Subquery struct {
 	// ExprNode is a reference to a tree.Subquery node that has been created for
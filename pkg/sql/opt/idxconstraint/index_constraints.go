@@ -766,6 +766,10 @@ func (c *indexConstraintCtx) makeInvertedIndexSpansForExpr(
 			return true
 		}
 
+		if ra, ok := rightDatum.(*tree.DArray); ok {
+			return c.makeInvertedIndexSpansForArray(ra, out)
+		}
+
 		rd := rightDatum.(*tree.DJSON).JSON
 
 		switch rd.Type() {
@@ -849,6 +853,36 @@ func (c *indexConstraintCtx) makeInvertedIndexSpansForExpr(
 	return false
 }
 
+// makeInvertedIndexSpansForArray generates the spans of an inverted index on an
+// array column for the condition that the column contains the given array.
+// The rows of the index are keyed by the elements of the arrays, so the spans
+// are those of a single-element array containing the first element of the
+// given array. They are tight if it has a single element.
+func (c *indexConstraintCtx) makeInvertedIndexSpansForArray(
+	ra *tree.DArray, out *constraint.Constraint,
+) (tight bool) {
+	for _, d := range ra.Array {
+		if d == tree.DNull {
+			// NULL elements aren't contained in any array.
+			c.contradiction(0 /* offset */, out)
+			return true
+		}
+	}
+	if ra.Len() == 0 {
+		// All the arrays contain the empty array.
+		c.unconstrained(0 /* offset */, out)
+		return false
+	}
+	elem := tree.NewDArray(ra.ParamTyp)
+	if err := elem.Append(ra.Array[0]); err != nil {
+		log.Errorf(context.TODO(), "unexpected array error: %v", err)
+		c.unconstrained(0 /* offset */, out)
+		return false
+	}
+	c.eqSpan(0 /* offset */, elem, out)
+	return ra.Len() == 1
+}
+
 // getMaxSimplifyPrefix finds the longest prefix (maxSimplifyPrefix) such that
 // every span has the same first maxSimplifyPrefix values for the start and end
 // key. For example, for:
@@ -17,6 +17,7 @@ package memo
 import (
 	"fmt"
 	"math"
+	"sort"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/constraint"
@@ -1193,23 +1194,119 @@ func (sb *statisticsBuilder) updateDistinctCountsFromConstraint(
 // This selectivity will be used later to update the row count and the
 // distinct count for the unconstrained columns in applySelectivityToColStat.
 //
-// TODO(rytaft): This formula assumes that the columns are completely
-// independent. Improve this estimate to take functional dependencies and/or
-// column correlations into account.
+// This formula assumes that the columns are completely independent. If the
+// optimizer_use_multicol_stats session setting is on, the estimate is
+// corrected with the multi-column statistics collected for the constrained
+// columns; see multiColSelectivityCorrection.
+//
+// TODO(rytaft): Take functional dependencies into account as well.
 func (sb *statisticsBuilder) selectivityFromDistinctCounts(
 	inputStatsBuilder *statisticsBuilder,
 ) (selectivity float64) {
 	selectivity = 1.0
+	var constrainedCols opt.ColSet
 	for col, colStat := range sb.s.ColStats {
 		inputStat := inputStatsBuilder.colStat(util.MakeFastIntSet(int(col)))
 		if inputStat.DistinctCount != 0 && colStat.DistinctCount < inputStat.DistinctCount {
 			selectivity *= colStat.DistinctCount / inputStat.DistinctCount
+			constrainedCols.Add(int(col))
 		}
 	}
 
+	if constrainedCols.Len() > 1 && sb.evalCtx.SessionData.OptimizerUseMultiColStats {
+		selectivity *= sb.multiColSelectivityCorrection(constrainedCols, inputStatsBuilder)
+	}
 	return selectivity
 }
 
+// multiColSelectivityCorrection returns the factor by which the selectivity
+// calculated by selectivityFromDistinctCounts must be multiplied to account
+// for the correlations between the given constrained columns, as measured by
+// the multi-column statistics collected on their tables.
+//
+// For a set of columns S with a multi-column statistic, the number of
+// distinct combinations of values in the input is at most:
+//
+//   old distinct(S) = min(multi-column distinct(S), ┬-┬ old distinct(i))
+//                                                   ┴ ┴
+//                                                  i in S
+//
+// and the filter keeps at most min(┬-┬ new distinct(i), old distinct(S)) of
+// them, so the selectivity of the constraints on S is estimated as their
+// ratio instead of the product of the ratios of the individual columns. The
+// two estimates are the same when the columns of S are independent; they
+// diverge as the columns become more correlated. For example, if a city
+// column determines a country column, constraining both is as selective as
+// constraining only the city.
+//
+// The statistics on the most columns are used first, and each constrained
+// column is accounted for by at most one multi-column statistic.
+func (sb *statisticsBuilder) multiColSelectivityCorrection(
+	constrainedCols opt.ColSet, inputStatsBuilder *statisticsBuilder,
+) (correction float64) {
+	type multiColStat struct {
+		cols          opt.ColSet
+		distinctCount float64
+	}
+
+	// Gather the multi-column statistics on subsets of the constrained columns.
+	// Stats are ordered with most recent first, so only the first statistic on
+	// each column set is kept.
+	md := sb.ev.Metadata()
+	var stats []multiColStat
+	var seenTables util.FastIntSet
+	seenCols := make(map[string]bool)
+	constrainedCols.ForEach(func(i int) {
+		tabID := md.ColumnTableID(opt.ColumnID(i))
+		if tabID == 0 || seenTables.Contains(int(tabID)) {
+			return
+		}
+		seenTables.Add(int(tabID))
+		tab := md.Table(tabID)
+		for j := 0; j < tab.StatisticCount(); j++ {
+			stat := tab.Statistic(j)
+			if stat.ColumnCount() < 2 {
+				continue
+			}
+			cols := sb.colSetFromTableStatistic(stat, tabID)
+			if !cols.SubsetOf(constrainedCols) || seenCols[cols.String()] {
+				continue
+			}
+			seenCols[cols.String()] = true
+			stats = append(stats, multiColStat{
+				cols:          cols,
+				distinctCount: float64(stat.DistinctCount()),
+			})
+		}
+	})
+	sort.SliceStable(stats, func(i, j int) bool {
+		return stats[i].cols.Len() > stats[j].cols.Len()
+	})
+
+	correction = 1.0
+	remainingCols := constrainedCols.Copy()
+	for _, stat := range stats {
+		if !stat.cols.SubsetOf(remainingCols) {
+			continue
+		}
+		oldDistinct, newDistinct := 1.0, 1.0
+		stat.cols.ForEach(func(i int) {
+			oldDistinct *= inputStatsBuilder.colStat(util.MakeFastIntSet(i)).DistinctCount
+			newDistinct *= sb.s.ColStats[opt.ColumnID(i)].DistinctCount
+		})
+		multiColDistinct := min(stat.distinctCount, oldDistinct)
+		if multiColDistinct == 0 || newDistinct == 0 || multiColDistinct == oldDistinct {
+			// Either the filter is a contradiction, or the columns are independent.
+			continue
+		}
+		independent := newDistinct / oldDistinct
+		correlated := min(newDistinct, multiColDistinct) / multiColDistinct
+		correction *= correlated / independent
+		remainingCols.DifferenceWith(stat.cols)
+	}
+	return correction
+}
+
 // applySelectivityToColStat updates the given column statistics according to
 // the filter selectivity.
 func (sb *statisticsBuilder) applySelectivityToColStat(
@@ -215,6 +215,17 @@ func (md *Metadata) TableColumn(tabID TableID, ord int) ColumnID {
 	return ColumnID(int(tabID) + ord)
 }
 
+// ColumnTableID returns the metadata id of the table that the given column
+// belongs to, or zero if it is not a table column (e.g. a projection).
+func (md *Metadata) ColumnTableID(id ColumnID) TableID {
+	for tabID, mdTab := range md.tables {
+		if id >= ColumnID(tabID) && int(id) < int(tabID)+mdTab.tab.ColumnCount() {
+			return tabID
+		}
+	}
+	return 0
+}
+
 // TableAnnotation returns the given annotation that is associated with the
 // given table. If the table has no such annotation, TableAnnotation returns
 // nil.
@@ -39,7 +39,11 @@ func (b *Builder) buildExplain(explain *tree.Explain, inScope *scope) (outScope
 	var cols sqlbase.ResultColumns
 	switch opts.Mode {
 	case tree.ExplainPlan:
-		if opts.Flags.Contains(tree.ExplainFlagVerbose) || opts.Flags.Contains(tree.ExplainFlagTypes) {
+		// EXPLAIN ANALYZE ignores the flags that add metadata columns, as it
+		// describes the physical plan.
+		if opts.Flags.Contains(tree.ExplainFlagAnalyze) {
+			cols = sqlbase.ExplainPlanColumns
+		} else if opts.Flags.Contains(tree.ExplainFlagVerbose) || opts.Flags.Contains(tree.ExplainFlagTypes) {
 			cols = sqlbase.ExplainPlanVerboseColumns
 		} else {
 			cols = sqlbase.ExplainPlanColumns
@@ -51,6 +55,9 @@ func (b *Builder) buildExplain(explain *tree.Explain, inScope *scope) (outScope
 	case tree.ExplainOpt:
 		cols = sqlbase.ExplainOptColumns
 
+	case tree.ExplainDebug:
+		cols = sqlbase.ExplainDebugColumns
+
 	default:
 		panic(fmt.Errorf("unsupported EXPLAIN mode: %d", opts.Mode))
 	}
@@ -32,7 +32,14 @@ import (
 // return values.
 func (b *Builder) buildJoin(join *tree.JoinTableExpr, inScope *scope) (outScope *scope) {
 	leftScope := b.buildTable(join.Left, inScope)
-	rightScope := b.buildTable(join.Right, inScope)
+
+	// A LATERAL source on the right side of the join can refer to the columns
+	// of the left side.
+	rightInScope := inScope
+	if isLateral(join.Right) {
+		rightInScope = lateralScope(leftScope, inScope)
+	}
+	rightScope := b.buildTable(join.Right, rightInScope)
 
 	// Check that the same table name is not used on both sides.
 	leftTables := make(map[string]struct{})
@@ -341,6 +348,18 @@ func (b *Builder) constructJoin(
 ) memo.GroupID {
 	// Wrap the ON condition in a FiltersOp.
 	filter = b.factory.ConstructFilters(b.factory.InternList([]memo.GroupID{filter}))
+	if b.factory.CustomFuncs().IsCorrelated(right, left) {
+		// The right side is a LATERAL source that refers to the left side.
+		switch joinType {
+		case sqlbase.InnerJoin:
+			return b.factory.ConstructInnerJoinApply(left, right, filter)
+		case sqlbase.LeftOuterJoin:
+			return b.factory.ConstructLeftJoinApply(left, right, filter)
+		default:
+			panic(builderError{pgerror.NewErrorf(pgerror.CodeInvalidColumnReferenceError,
+				"the combining JOIN type must be INNER or LEFT for a LATERAL reference")})
+		}
+	}
 	switch joinType {
 	case sqlbase.InnerJoin:
 		return b.factory.ConstructInnerJoin(left, right, filter)
@@ -355,6 +374,21 @@ func (b *Builder) constructJoin(
 	}
 }
 
+// isLateral returns true if the given table expression is a LATERAL source,
+// which can refer to the columns of the sources that precede it.
+func isLateral(texpr tree.TableExpr) bool {
+	source, ok := texpr.(*tree.AliasedTableExpr)
+	return ok && source.Lateral
+}
+
+// lateralScope returns the scope used to build a LATERAL source. The columns of
+// leftScope are visible as outer columns in the new scope.
+func lateralScope(leftScope, inScope *scope) *scope {
+	s := inScope.push()
+	s.appendColumns(leftScope)
+	return s
+}
+
 // findUsingColumn finds the column in cols that has the given name. If the
 // column exists it is returned. Otherwise, an error is thrown.
 //
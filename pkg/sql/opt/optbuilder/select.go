@@ -307,7 +307,12 @@ func (b *Builder) buildFrom(from *tree.From, where *tree.Where, inScope *scope)
 	colsAdded := 0
 
 	for _, table := range from.Tables {
-		tableScope := b.buildTable(table, inScope)
+		// A LATERAL source can refer to the columns of the preceding sources.
+		tableInScope := inScope
+		if outScope != nil && isLateral(table) {
+			tableInScope = lateralScope(outScope, inScope)
+		}
+		tableScope := b.buildTable(table, tableInScope)
 
 		if outScope == nil {
 			outScope = tableScope
@@ -327,9 +332,15 @@ func (b *Builder) buildFrom(from *tree.From, where *tree.Where, inScope *scope)
 		b.validateJoinTableNames(joinTables, tableScope)
 
 		outScope.appendColumns(tableScope)
-		outScope.group = b.factory.ConstructInnerJoin(
-			outScope.group, tableScope.group, b.factory.ConstructTrue(),
-		)
+		if b.factory.CustomFuncs().IsCorrelated(tableScope.group, outScope.group) {
+			outScope.group = b.factory.ConstructInnerJoinApply(
+				outScope.group, tableScope.group, b.factory.ConstructTrue(),
+			)
+		} else {
+			outScope.group = b.factory.ConstructInnerJoin(
+				outScope.group, tableScope.group, b.factory.ConstructTrue(),
+			)
+		}
 	}
 
 	if outScope == nil {
@@ -84,6 +84,11 @@ type OptTesterFlags struct {
 	// output to stdout when commands run. Only certain commands support this.
 	Verbose bool
 
+	// UseMultiColStats if set: the statistics builder uses the multi-column
+	// statistics of tables to estimate the selectivity of filters on correlated
+	// columns, as with the optimizer_use_multicol_stats session setting.
+	UseMultiColStats bool
+
 	// ExploreTraceRule restricts the ExploreTrace output to only show the effects
 	// of a specific rule.
 	ExploreTraceRule string
@@ -148,6 +153,9 @@ func NewOptTester(catalog opt.Catalog, sql string) *OptTester {
 //
 //  - fully-qualify-names: fully qualify all column names in the test output.
 //
+//  - use-multicol-stats: use multi-column statistics to estimate the
+//    selectivity of filters.
+//
 //  - rule: used with exploretrace; the value is the name of a rule. When
 //    specified, the exploretrace output is filtered to only show expression
 //    changes due to that specific rule.
@@ -161,6 +169,7 @@ func (ot *OptTester) RunCommand(tb testing.TB, d *datadriven.TestData) string {
 	}
 
 	ot.Flags.Verbose = testing.Verbose()
+	ot.evalCtx.SessionData.OptimizerUseMultiColStats = ot.Flags.UseMultiColStats
 
 	switch d.Cmd {
 	case "exec-ddl":
@@ -270,6 +279,9 @@ func (f *OptTesterFlags) Set(arg datadriven.CmdArg) error {
 		// Hiding qualifications defeats the purpose.
 		f.ExprFormat &= ^opt.ExprFmtHideQualifications
 
+	case "use-multicol-stats":
+		f.UseMultiColStats = true
+
 	case "rule":
 		if len(arg.Vals) != 1 {
 			return fmt.Errorf("rule requires one argument")
@@ -19,6 +19,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/stats"
@@ -35,6 +36,10 @@ type optCatalog struct {
 
 	statsCache *stats.TableStatisticsCache
 
+	// useNotVisibleIndexes indicates whether the optimizer can use the indexes
+	// that were made NOT VISIBLE.
+	useNotVisibleIndexes bool
+
 	// wrappers is a cache of table wrappers that's used to satisfy repeated
 	// calls to the FindTable method for the same table.
 	wrappers map[*sqlbase.TableDescriptor]*optTable
@@ -43,9 +48,12 @@ type optCatalog struct {
 var _ opt.Catalog = &optCatalog{}
 
 // init allows the optCatalog wrapper to be inlined.
-func (oc *optCatalog) init(statsCache *stats.TableStatisticsCache, resolver SchemaResolver) {
+func (oc *optCatalog) init(
+	statsCache *stats.TableStatisticsCache, resolver SchemaResolver, useNotVisibleIndexes bool,
+) {
 	oc.resolver = resolver
 	oc.statsCache = statsCache
+	oc.useNotVisibleIndexes = useNotVisibleIndexes
 }
 
 // FindTable is part of the opt.Catalog interface.
@@ -54,6 +62,14 @@ func (oc *optCatalog) FindTable(ctx context.Context, name *tree.TableName) (opt.
 	if err != nil {
 		return nil, err
 	}
+	for i := range desc.Indexes {
+		if desc.Indexes[i].IsPartial() && (!desc.Indexes[i].NotVisible || oc.useNotVisibleIndexes) {
+			// Fall back to the heuristic planner, which knows when partial
+			// indexes can be used.
+			return nil, pgerror.Unimplemented("partial indexes",
+				"the optimizer does not support tables with partial indexes")
+		}
+	}
 
 	// Check to see if there's already a wrapper for this table descriptor.
 	if oc.wrappers == nil {
@@ -61,7 +77,7 @@ func (oc *optCatalog) FindTable(ctx context.Context, name *tree.TableName) (opt.
 	}
 	wrapper, ok := oc.wrappers[desc]
 	if !ok {
-		wrapper = newOptTable(oc.statsCache, desc)
+		wrapper = newOptTable(oc.statsCache, desc, oc.useNotVisibleIndexes)
 		oc.wrappers[desc] = wrapper
 	}
 	return wrapper, nil
@@ -75,6 +91,9 @@ type optTable struct {
 	// primary is the inlined wrapper for the table's primary index.
 	primary optIndex
 
+	// indexes holds the secondary indexes the optimizer can use.
+	indexes []*sqlbase.IndexDescriptor
+
 	statsCache *stats.TableStatisticsCache
 
 	// stats is nil until StatisticCount is called. After that it will not be nil,
@@ -92,16 +111,31 @@ type optTable struct {
 
 var _ opt.Table = &optTable{}
 
-func newOptTable(statsCache *stats.TableStatisticsCache, desc *sqlbase.TableDescriptor) *optTable {
+func newOptTable(
+	statsCache *stats.TableStatisticsCache,
+	desc *sqlbase.TableDescriptor,
+	useNotVisibleIndexes bool,
+) *optTable {
 	ot := &optTable{}
-	ot.init(statsCache, desc)
+	ot.init(statsCache, desc, useNotVisibleIndexes)
 	return ot
 }
 
 // init allows the optTable wrapper to be inlined.
-func (ot *optTable) init(statsCache *stats.TableStatisticsCache, desc *sqlbase.TableDescriptor) {
+func (ot *optTable) init(
+	statsCache *stats.TableStatisticsCache,
+	desc *sqlbase.TableDescriptor,
+	useNotVisibleIndexes bool,
+) {
 	ot.desc = desc
 	ot.primary.init(ot, &desc.PrimaryIndex)
+	ot.indexes = make([]*sqlbase.IndexDescriptor, 0, len(desc.Indexes))
+	for i := range desc.Indexes {
+		if desc.Indexes[i].NotVisible && !useNotVisibleIndexes {
+			continue
+		}
+		ot.indexes = append(ot.indexes, &desc.Indexes[i])
+	}
 	ot.statsCache = statsCache
 }
 
@@ -128,7 +162,7 @@ func (ot *optTable) Column(i int) opt.Column {
 // IndexCount is part of the opt.Table interface.
 func (ot *optTable) IndexCount() int {
 	// Primary index is always present, so count is always >= 1.
-	return 1 + len(ot.desc.Indexes)
+	return 1 + len(ot.indexes)
 }
 
 // Index is part of the opt.Table interface.
@@ -139,11 +173,11 @@ func (ot *optTable) Index(i int) opt.Index {
 	}
 
 	// Bias i to account for lack of primary index in Indexes slice.
-	desc := &ot.desc.Indexes[i-1]
+	desc := ot.indexes[i-1]
 
 	// Check to see if there's already a wrapper for this index descriptor.
 	if ot.wrappers == nil {
-		ot.wrappers = make(map[*sqlbase.IndexDescriptor]*optIndex, len(ot.desc.Indexes))
+		ot.wrappers = make(map[*sqlbase.IndexDescriptor]*optIndex, len(ot.indexes))
 	}
 	wrapper, ok := ot.wrappers[desc]
 	if !ok {
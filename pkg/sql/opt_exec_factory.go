@@ -287,6 +287,31 @@ func (ef *execFactory) ConstructMergeJoin(
 	return node, nil
 }
 
+// ConstructApplyJoin is part of the exec.Factory interface.
+func (ef *execFactory) ConstructApplyJoin(
+	joinType sqlbase.JoinType,
+	left exec.Node,
+	rightColumns sqlbase.ResultColumns,
+	onCond tree.TypedExpr,
+	planRightSide exec.ApplyJoinPlanRightSideFn,
+) (exec.Node, error) {
+	p := ef.planner
+	leftSrc := asDataSource(left)
+	rightInfo := &sqlbase.DataSourceInfo{SourceColumns: rightColumns}
+	pred, _, err := p.makeJoinPredicate(
+		context.TODO(), leftSrc.info, rightInfo, joinType, nil, /* cond */
+	)
+	if err != nil {
+		return nil, err
+	}
+	if onCond != tree.DBoolTrue {
+		pred.onCond = pred.iVarHelper.Rebind(
+			onCond, false /* alsoReset */, false, /* normalizeToNonNil */
+		)
+	}
+	return p.makeApplyJoinNode(joinType, leftSrc, rightColumns, pred, planRightSide), nil
+}
+
 // ConstructGroupBy is part of the exec.Factory interface.
 func (ef *execFactory) ConstructGroupBy(
 	input exec.Node, groupCols []exec.ColumnOrdinal, aggregations []exec.AggInfo,
@@ -477,6 +502,11 @@ func (ef *execFactory) ConstructLimit(
 	}, nil
 }
 
+// ConstructMax1Row is part of the exec.Factory interface.
+func (ef *execFactory) ConstructMax1Row(input exec.Node) (exec.Node, error) {
+	return &max1RowNode{plan: input.(planNode)}, nil
+}
+
 // ConstructPlan is part of the exec.Factory interface.
 func (ef *execFactory) ConstructPlan(
 	root exec.Node, subqueries []exec.Subquery,
@@ -526,9 +556,29 @@ func (ef *execFactory) ConstructExplain(
 			analyze: analyzeSet,
 		}, nil
 
+	case tree.ExplainDebug:
+		if !analyzeSet {
+			return nil, errors.New("EXPLAIN (DEBUG) only supported with ANALYZE")
+		}
+		if len(p.subqueryPlans) > 0 {
+			return nil, fmt.Errorf("subqueries not supported yet")
+		}
+		return &explainDistSQLNode{
+			plan:    p.plan,
+			analyze: true,
+			debug:   true,
+		}, nil
+
 	case tree.ExplainPlan:
 		if analyzeSet {
-			return nil, errors.New("EXPLAIN ANALYZE only supported with (DISTSQL) option")
+			if len(p.subqueryPlans) > 0 {
+				return nil, fmt.Errorf("subqueries not supported yet")
+			}
+			return &explainDistSQLNode{
+				plan:     p.plan,
+				analyze:  true,
+				planTree: true,
+			}, nil
 		}
 		// NOEXPAND and NOOPTIMIZE must always be set when using the optimizer to
 		// prevent the plans from being modified.
@@ -372,6 +372,7 @@ func (p *planner) propagateFilters(
 	case *alterTableNode:
 	case *alterSequenceNode:
 	case *alterUserSetPasswordNode:
+	case *controlScheduleNode:
 	case *scrubNode:
 	case *createDatabaseNode:
 	case *createIndexNode:
@@ -384,9 +385,11 @@ func (p *planner) propagateFilters(
 	case *dropTableNode:
 	case *dropViewNode:
 	case *dropSequenceNode:
+	case *refreshMaterializedViewNode:
 	case *DropUserNode:
 	case *hookFnNode:
 	case *valuesNode:
+	case *cteScanNode:
 	case *sequenceSelectNode:
 	case *setVarNode:
 	case *setClusterSettingNode:
@@ -108,6 +108,16 @@ func (p *planner) selectIndex(
 
 	candidates := make([]*indexInfo, 0, len(s.desc.Indexes)+1)
 	if s.specifiedIndex != nil {
+		if s.specifiedIndex.IsPartial() {
+			ok, err := p.partialIndexPredicateImplied(ctx, s, s.specifiedIndex)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return nil, fmt.Errorf("index \"%s\" is a partial index that cannot be used for this query",
+					s.specifiedIndex.Name)
+			}
+		}
 		// An explicit secondary index was requested. Only add it to the candidate
 		// indexes list.
 		candidates = append(candidates, &indexInfo{
@@ -120,6 +130,21 @@ func (p *planner) selectIndex(
 			index: &s.desc.PrimaryIndex,
 		})
 		for i := range s.desc.Indexes {
+			if s.desc.Indexes[i].NotVisible && !p.SessionData().OptimizerUseNotVisibleIndexes {
+				// Indexes that aren't visible are only used when requested explicitly.
+				continue
+			}
+			if s.desc.Indexes[i].IsPartial() {
+				// Partial indexes can only be used when the filter guarantees that
+				// all the rows needed are in the index.
+				ok, err := p.partialIndexPredicateImplied(ctx, s, &s.desc.Indexes[i])
+				if err != nil {
+					return nil, err
+				}
+				if !ok {
+					continue
+				}
+			}
 			candidates = append(candidates, &indexInfo{
 				desc:  s.desc,
 				index: &s.desc.Indexes[i],
@@ -128,6 +128,12 @@ func (p *planner) applyLimit(plan planNode, numRows int64, soft bool) {
 		p.setUnlimited(n.left.plan)
 		p.setUnlimited(n.right.plan)
 
+	case *applyJoinNode:
+		p.setUnlimited(n.input.plan)
+
+	case *max1RowNode:
+		p.setUnlimited(n.plan)
+
 	case *ordinalityNode:
 		p.applyLimit(n.source, numRows, soft)
 
@@ -204,10 +210,12 @@ func (p *planner) applyLimit(plan planNode, numRows int64, soft bool) {
 		p.setUnlimited(n.rows)
 
 	case *valuesNode:
+	case *cteScanNode:
 	case *alterIndexNode:
 	case *alterTableNode:
 	case *alterSequenceNode:
 	case *alterUserSetPasswordNode:
+	case *controlScheduleNode:
 	case *scrubNode:
 	case *createDatabaseNode:
 	case *createIndexNode:
@@ -220,6 +228,7 @@ func (p *planner) applyLimit(plan planNode, numRows int64, soft bool) {
 	case *dropTableNode:
 	case *dropViewNode:
 	case *dropSequenceNode:
+	case *refreshMaterializedViewNode:
 	case *DropUserNode:
 	case *zeroNode:
 	case *unaryNode:
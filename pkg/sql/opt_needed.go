@@ -260,6 +260,7 @@ func setNeededColumns(plan planNode, needed []bool) {
 	case *alterTableNode:
 	case *alterSequenceNode:
 	case *alterUserSetPasswordNode:
+	case *controlScheduleNode:
 	case *scrubNode:
 	case *createDatabaseNode:
 	case *createIndexNode:
@@ -272,9 +273,11 @@ func setNeededColumns(plan planNode, needed []bool) {
 	case *dropTableNode:
 	case *dropViewNode:
 	case *dropSequenceNode:
+	case *refreshMaterializedViewNode:
 	case *DropUserNode:
 	case *zeroNode:
 	case *unaryNode:
+	case *cteScanNode:
 	case *hookFnNode:
 	case *sequenceSelectNode:
 	case *setVarNode:
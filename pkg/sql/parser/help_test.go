@@ -57,6 +57,8 @@ func TestContextualHelp(t *testing.T) {
 		{`ALTER DATABASE foo RENAME ??`, `ALTER DATABASE`},
 		{`ALTER DATABASE foo RENAME TO bar ??`, `ALTER DATABASE`},
 
+		{`ALTER RANGE foo ??`, `ALTER RANGE`},
+
 		{`ALTER VIEW IF ??`, `ALTER VIEW`},
 		{`ALTER VIEW blah ??`, `ALTER VIEW`},
 		{`ALTER VIEW blah RENAME ??`, `ALTER VIEW`},
@@ -107,10 +109,13 @@ func TestContextualHelp(t *testing.T) {
 		{`CREATE VIEW blah AS (SELECT c FROM x) ??`, `CREATE VIEW`},
 		{`CREATE VIEW blah AS SELECT c FROM x ??`, `SELECT`},
 		{`CREATE VIEW blah AS (??`, `<SELECTCLAUSE>`},
+		{`CREATE MATERIALIZED VIEW blah (??`, `CREATE VIEW`},
 
 		{`CREATE SEQUENCE ??`, `CREATE SEQUENCE`},
 
 		{`CREATE STATISTICS ??`, `CREATE STATISTICS`},
+		{`CREATE SCHEDULE ??`, `CREATE SCHEDULE`},
+		{`CREATE SCHEDULE foo FOR ??`, `CREATE SCHEDULE`},
 
 		{`CREATE TABLE blah (??`, `CREATE TABLE`},
 		{`CREATE TABLE IF NOT ??`, `CREATE TABLE`},
@@ -140,6 +145,8 @@ func TestContextualHelp(t *testing.T) {
 		{`DROP ROLE IF ??`, `DROP ROLE`},
 		{`DROP ROLE IF EXISTS bluh ??`, `DROP ROLE`},
 
+		{`DROP SCHEDULE ??`, `DROP SCHEDULE`},
+
 		{`DROP SEQUENCE blah ??`, `DROP SEQUENCE`},
 		{`DROP SEQUENCE IF ??`, `DROP SEQUENCE`},
 		{`DROP SEQUENCE IF EXISTS blih, bloh ??`, `DROP SEQUENCE`},
@@ -151,6 +158,7 @@ func TestContextualHelp(t *testing.T) {
 		{`DROP VIEW blah ??`, `DROP VIEW`},
 		{`DROP VIEW IF ??`, `DROP VIEW`},
 		{`DROP VIEW IF EXISTS blih, bloh ??`, `DROP VIEW`},
+		{`DROP MATERIALIZED VIEW blah ??`, `DROP VIEW`},
 
 		{`DROP USER ??`, `DROP USER`},
 		{`DROP USER IF ??`, `DROP USER`},
@@ -235,6 +243,9 @@ func TestContextualHelp(t *testing.T) {
 
 		{`SHOW HISTOGRAM ??`, `SHOW HISTOGRAM`},
 
+		{`SHOW RANGE ??`, `SHOW RANGE LEASE HISTORY`},
+		{`SHOW RANGE 1 ??`, `SHOW RANGE LEASE HISTORY`},
+
 		{`SHOW QUERIES ??`, `SHOW QUERIES`},
 		{`SHOW LOCAL QUERIES ??`, `SHOW QUERIES`},
 
@@ -249,6 +260,8 @@ func TestContextualHelp(t *testing.T) {
 		{`SHOW CLUSTER SETTING all ??`, `SHOW CLUSTER SETTING`},
 		{`SHOW ALL CLUSTER ??`, `SHOW CLUSTER SETTING`},
 
+		{`SHOW CLUSTER CONFIGURATION ??`, `SHOW CLUSTER CONFIGURATION`},
+
 		{`SHOW COLUMNS FROM ??`, `SHOW COLUMNS`},
 		{`SHOW COLUMNS FROM foo ??`, `SHOW COLUMNS`},
 
@@ -295,6 +308,8 @@ func TestContextualHelp(t *testing.T) {
 
 		{`SHOW USERS ??`, `SHOW USERS`},
 
+		{`SHOW ZONE ??`, `SHOW ZONE`},
+
 		{`TRUNCATE foo ??`, `TRUNCATE`},
 		{`TRUNCATE foo, ??`, `TRUNCATE`},
 
@@ -350,6 +365,9 @@ func TestContextualHelp(t *testing.T) {
 
 		{`SAVEPOINT blah ??`, `SAVEPOINT`},
 
+		{`REFRESH ??`, `REFRESH`},
+		{`REFRESH MATERIALIZED VIEW blah ??`, `REFRESH`},
+
 		{`RELEASE blah ??`, `RELEASE`},
 		{`RELEASE SAVEPOINT blah ??`, `RELEASE`},
 
@@ -80,6 +80,8 @@ func TestParse(t *testing.T) {
 		{`CREATE INDEX ON a (b) INTERLEAVE IN PARENT c (d)`},
 		{`CREATE INDEX ON a (b) INTERLEAVE IN PARENT c.d (e)`},
 		{`CREATE INDEX ON a (b ASC, c DESC)`},
+		{`CREATE INDEX ON a (b) WHERE c > 3`},
+		{`CREATE INDEX IF NOT EXISTS a ON b (c) STORING (d) WHERE (e = 1) AND (f IS NOT NULL)`},
 		{`CREATE UNIQUE INDEX a ON b (c)`},
 		{`CREATE UNIQUE INDEX a ON b (c) STORING (d)`},
 		{`CREATE UNIQUE INDEX a ON b (c) INTERLEAVE IN PARENT d (e, f)`},
@@ -234,6 +236,8 @@ func TestParse(t *testing.T) {
 		{`CREATE VIEW a AS VALUES (1, 'one'), (2, 'two')`},
 		{`CREATE VIEW a (x, y) AS VALUES (1, 'one'), (2, 'two')`},
 		{`CREATE VIEW a AS TABLE b`},
+		{`CREATE MATERIALIZED VIEW a AS SELECT * FROM b`},
+		{`CREATE MATERIALIZED VIEW a (x, y) AS SELECT c, d FROM b`},
 
 		{`CREATE SEQUENCE a`},
 		{`CREATE SEQUENCE IF NOT EXISTS a`},
@@ -250,6 +254,8 @@ func TestParse(t *testing.T) {
 		{`CREATE SEQUENCE a MINVALUE 1000`},
 		{`CREATE SEQUENCE a START 1000`},
 		{`CREATE SEQUENCE a START WITH 1000`},
+		{`CREATE SEQUENCE a OWNED BY b.c`},
+		{`CREATE SEQUENCE a OWNED BY NONE`},
 		{`CREATE SEQUENCE a INCREMENT 5 NO MAXVALUE MINVALUE 1 START 3`},
 		{`CREATE SEQUENCE a INCREMENT 5 NO CYCLE NO MAXVALUE MINVALUE 1 START 3 CACHE 1`},
 
@@ -303,6 +309,8 @@ func TestParse(t *testing.T) {
 		{`DROP VIEW IF EXISTS a, b RESTRICT`},
 		{`DROP VIEW a.b CASCADE`},
 		{`DROP VIEW a, b CASCADE`},
+		{`DROP MATERIALIZED VIEW a`},
+		{`DROP MATERIALIZED VIEW IF EXISTS a, b CASCADE`},
 		{`DROP SEQUENCE a`},
 		{`DROP SEQUENCE a.b`},
 		{`DROP SEQUENCE a, b`},
@@ -319,11 +327,17 @@ func TestParse(t *testing.T) {
 		{`CANCEL SESSIONS IF EXISTS SELECT a`},
 		{`RESUME JOBS SELECT a`},
 		{`PAUSE JOBS SELECT a`},
+		{`PAUSE SCHEDULE foo`},
+		{`RESUME SCHEDULE foo`},
+		{`DROP SCHEDULE foo`},
 
 		{`EXPLAIN SELECT 1`},
 		{`EXPLAIN EXPLAIN SELECT 1`},
 		{`EXPLAIN (A, B, C) SELECT 1`},
 		{`EXPLAIN ANALYZE (A, B, C) SELECT 1`},
+		{`EXPLAIN ANALYZE (DEBUG) SELECT 1`},
+		{`EXPLAIN ANALYZE SELECT 1`},
+		{`EXPLAIN ANALYZE (PLAN) SELECT 1`},
 		{`SELECT * FROM [EXPLAIN SELECT 1]`},
 		{`SELECT * FROM [SHOW TRANSACTION STATUS]`},
 
@@ -334,6 +348,7 @@ func TestParse(t *testing.T) {
 
 		{`SHOW CLUSTER SETTING a`},
 		{`SHOW CLUSTER SETTING all`},
+		{`SHOW CLUSTER CONFIGURATION`},
 
 		{`SHOW DATABASES`},
 		{`SHOW SCHEMAS`},
@@ -369,21 +384,22 @@ func TestParse(t *testing.T) {
 		{`SHOW EXPERIMENTAL_RANGES FROM TABLE d.t`},
 		{`SHOW EXPERIMENTAL_RANGES FROM TABLE t`},
 		{`SHOW EXPERIMENTAL_RANGES FROM INDEX d.t@i`},
+		{`SHOW RANGE 123 LEASE HISTORY`},
 		{`SHOW EXPERIMENTAL_RANGES FROM INDEX t@i`},
 		{`SHOW EXPERIMENTAL_RANGES FROM INDEX d.i`},
 		{`SHOW EXPERIMENTAL_RANGES FROM INDEX i`},
 		{`SHOW EXPERIMENTAL_FINGERPRINTS FROM TABLE d.t`},
-		{`EXPERIMENTAL SHOW ZONE CONFIGURATIONS`},
-		{`EXPERIMENTAL SHOW ZONE CONFIGURATION FOR RANGE default`},
-		{`EXPERIMENTAL SHOW ZONE CONFIGURATION FOR RANGE meta`},
-		{`EXPERIMENTAL SHOW ZONE CONFIGURATION FOR DATABASE db`},
-		{`EXPERIMENTAL SHOW ZONE CONFIGURATION FOR TABLE db.t`},
-		{`EXPERIMENTAL SHOW ZONE CONFIGURATION FOR PARTITION p OF TABLE db.t`},
-		{`EXPERIMENTAL SHOW ZONE CONFIGURATION FOR TABLE t`},
-		{`EXPERIMENTAL SHOW ZONE CONFIGURATION FOR PARTITION p OF TABLE t`},
-		{`EXPERIMENTAL SHOW ZONE CONFIGURATION FOR INDEX db.t@i`},
-		{`EXPERIMENTAL SHOW ZONE CONFIGURATION FOR INDEX t@i`},
-		{`EXPERIMENTAL SHOW ZONE CONFIGURATION FOR INDEX i`},
+		{`SHOW ZONE CONFIGURATIONS`},
+		{`SHOW ZONE CONFIGURATION FOR RANGE default`},
+		{`SHOW ZONE CONFIGURATION FOR RANGE meta`},
+		{`SHOW ZONE CONFIGURATION FOR DATABASE db`},
+		{`SHOW ZONE CONFIGURATION FOR TABLE db.t`},
+		{`SHOW ZONE CONFIGURATION FOR PARTITION p OF TABLE db.t`},
+		{`SHOW ZONE CONFIGURATION FOR TABLE t`},
+		{`SHOW ZONE CONFIGURATION FOR PARTITION p OF TABLE t`},
+		{`SHOW ZONE CONFIGURATION FOR INDEX db.t@i`},
+		{`SHOW ZONE CONFIGURATION FOR INDEX t@i`},
+		{`SHOW ZONE CONFIGURATION FOR INDEX i`},
 
 		// Tables are the default, but can also be specified with
 		// GRANT x ON TABLE y. However, the stringer does not output TABLE.
@@ -650,6 +666,9 @@ func TestParse(t *testing.T) {
 		{`SELECT a FROM (SELECT 1 FROM t) AS bar (bar1, bar2, bar3)`},
 		{`SELECT a FROM (SELECT 1 FROM t) WITH ORDINALITY`},
 		{`SELECT a FROM (SELECT 1 FROM t) WITH ORDINALITY AS bar`},
+		{`SELECT a FROM t1, LATERAL (SELECT 1 FROM t2 WHERE t2.x = t1.x)`},
+		{`SELECT a FROM t1, LATERAL (SELECT 1 FROM t2 WHERE t2.x = t1.x) AS bar (bar1)`},
+		{`SELECT a FROM t1 LEFT JOIN LATERAL (SELECT 1 FROM t2) WITH ORDINALITY AS bar ON true`},
 		{`SELECT a FROM ROWS FROM (a(x), b(y), c(z))`},
 		{`SELECT a FROM t1, t2`},
 		{`SELECT a FROM t AS t1`},
@@ -888,6 +907,8 @@ func TestParse(t *testing.T) {
 		{`ALTER INDEX a@primary RENAME TO like`},
 		{`ALTER INDEX IF EXISTS a@b RENAME TO b`},
 		{`ALTER INDEX IF EXISTS a@primary RENAME TO like`},
+		{`ALTER INDEX a@b NOT VISIBLE`},
+		{`ALTER INDEX IF EXISTS b VISIBLE`},
 		{`ALTER TABLE a RENAME COLUMN c1 TO c2`},
 		{`ALTER TABLE IF EXISTS a RENAME COLUMN c1 TO c2`},
 
@@ -924,6 +945,8 @@ func TestParse(t *testing.T) {
 		{`ALTER TABLE a ALTER COLUMN b DROP NOT NULL`},
 		{`ALTER TABLE a ALTER b DROP NOT NULL`},
 		{`ALTER TABLE a ALTER COLUMN b DROP STORED`},
+		{`ALTER TABLE a ALTER PRIMARY KEY USING COLUMNS (b)`},
+		{`ALTER TABLE a ALTER PRIMARY KEY USING COLUMNS (b, c DESC)`},
 
 		{`ALTER TABLE a ALTER b TYPE INT`},
 		{`ALTER TABLE a ALTER COLUMN b SET DATA TYPE INT`},
@@ -945,24 +968,32 @@ func TestParse(t *testing.T) {
 		{`ALTER TABLE a EXPERIMENTAL_RELOCATE SELECT * FROM t`},
 		{`ALTER TABLE d.a EXPERIMENTAL_RELOCATE VALUES (ARRAY[1, 2, 3], 'b', 2)`},
 		{`ALTER INDEX d.i EXPERIMENTAL_RELOCATE VALUES (ARRAY[1], 2)`},
+		{`ALTER TABLE a EXPERIMENTAL_RELOCATE LEASE VALUES (1, 1)`},
+		{`ALTER TABLE a EXPERIMENTAL_RELOCATE LEASE SELECT * FROM t`},
+		{`ALTER INDEX d.i EXPERIMENTAL_RELOCATE LEASE VALUES (1, 2)`},
 
 		{`ALTER TABLE a SCATTER`},
 		{`ALTER TABLE a SCATTER FROM (1, 2, 3) TO (4, 5, 6)`},
 		{`ALTER TABLE d.a SCATTER`},
 		{`ALTER INDEX d.i SCATTER FROM (1) TO (2)`},
 
-		{`ALTER RANGE default EXPERIMENTAL CONFIGURE ZONE 'foo'`},
-		{`ALTER RANGE meta EXPERIMENTAL CONFIGURE ZONE 'foo'`},
-		{`ALTER DATABASE db EXPERIMENTAL CONFIGURE ZONE 'foo'`},
-		{`ALTER TABLE db.t EXPERIMENTAL CONFIGURE ZONE 'foo'`},
-		{`ALTER PARTITION p OF TABLE db.t EXPERIMENTAL CONFIGURE ZONE 'foo'`},
-		{`ALTER TABLE t EXPERIMENTAL CONFIGURE ZONE 'foo'`},
-		{`ALTER PARTITION p OF TABLE t EXPERIMENTAL CONFIGURE ZONE 'foo'`},
-		{`ALTER INDEX db.t@i EXPERIMENTAL CONFIGURE ZONE 'foo'`},
-		{`ALTER INDEX t@i EXPERIMENTAL CONFIGURE ZONE 'foo'`},
-		{`ALTER INDEX i EXPERIMENTAL CONFIGURE ZONE 'foo'`},
-		{`ALTER TABLE t EXPERIMENTAL CONFIGURE ZONE b'foo'`},
-		{`ALTER TABLE t EXPERIMENTAL CONFIGURE ZONE NULL`},
+		{`ALTER RANGE default CONFIGURE ZONE = 'foo'`},
+		{`ALTER RANGE meta CONFIGURE ZONE = 'foo'`},
+		{`ALTER DATABASE db CONFIGURE ZONE = 'foo'`},
+		{`ALTER TABLE db.t CONFIGURE ZONE = 'foo'`},
+		{`ALTER PARTITION p OF TABLE db.t CONFIGURE ZONE = 'foo'`},
+		{`ALTER TABLE t CONFIGURE ZONE = 'foo'`},
+		{`ALTER PARTITION p OF TABLE t CONFIGURE ZONE = 'foo'`},
+		{`ALTER INDEX db.t@i CONFIGURE ZONE = 'foo'`},
+		{`ALTER INDEX t@i CONFIGURE ZONE = 'foo'`},
+		{`ALTER INDEX i CONFIGURE ZONE = 'foo'`},
+		{`ALTER TABLE t CONFIGURE ZONE = b'foo'`},
+		{`ALTER TABLE t CONFIGURE ZONE DISCARD`},
+		{`ALTER RANGE default CONFIGURE ZONE USING num_replicas = 1`},
+		{`ALTER DATABASE db CONFIGURE ZONE USING num_replicas = 3, gc.ttlseconds = 600`},
+		{`ALTER PARTITION p OF TABLE t CONFIGURE ZONE USING constraints = '[+region=us]'`},
+		{`ALTER INDEX t@i CONFIGURE ZONE USING lease_preferences = '[[+region=us]]', range_max_bytes = 1024`},
+		{`ALTER TABLE t CONFIGURE ZONE USING range_min_bytes = $1`},
 		{`ALTER TABLE t EXPERIMENTAL_AUDIT SET READ WRITE`},
 		{`ALTER TABLE t EXPERIMENTAL_AUDIT SET OFF`},
 
@@ -971,6 +1002,12 @@ func TestParse(t *testing.T) {
 		{`ALTER SEQUENCE a INCREMENT BY 5 START WITH 1000`},
 		{`ALTER SEQUENCE IF EXISTS a INCREMENT BY 5 START WITH 1000`},
 		{`ALTER SEQUENCE IF EXISTS a NO CYCLE CACHE 1`},
+		{`ALTER SEQUENCE a OWNED BY b.c`},
+		{`ALTER SEQUENCE a OWNED BY db.b.c`},
+		{`ALTER SEQUENCE a OWNED BY NONE`},
+
+		{`REFRESH MATERIALIZED VIEW a`},
+		{`REFRESH MATERIALIZED VIEW CONCURRENTLY a.b`},
 
 		{`EXPERIMENTAL SCRUB DATABASE x`},
 		{`EXPERIMENTAL SCRUB DATABASE x AS OF SYSTEM TIME 1`},
@@ -1022,6 +1059,13 @@ func TestParse(t *testing.T) {
 		{`CREATE CHANGEFEED FOR DATABASE foo INTO 'sink'`},
 		{`CREATE CHANGEFEED FOR TABLE foo INTO 'sink' WITH bar = 'baz'`},
 
+		{`CREATE SCHEDULE foo FOR BACKUP DATABASE bar TO 'baz' RECURRING '@daily'`},
+		{`CREATE SCHEDULE foo FOR BACKUP TABLE bar.* TO $1 INCREMENTAL FROM 'baz' WITH key1, key2 = 'value' RECURRING $2`},
+		{`CREATE REPLICATION STREAM FOR TABLE foo`},
+		{`CREATE REPLICATION STREAM FOR TABLE foo WITH cursor = '1.0'`},
+		{`CREATE REPLICATION STREAM FOR TABLE foo FROM 'postgres://source'`},
+		{`CREATE REPLICATION STREAM FOR TABLE foo FROM 'postgres://source' WITH source_table = 'bar'`},
+
 		// Regression for #15926
 		{`SELECT * FROM ((t1 NATURAL JOIN t2 WITH ORDINALITY AS o1)) WITH ORDINALITY AS o2`},
 	}
@@ -1339,7 +1383,14 @@ func TestParse2(t *testing.T) {
 		{`SHOW SESSION database`, `SHOW database`},
 		{`SHOW SESSION TIME ZONE`, `SHOW timezone`},
 		{`SHOW SESSION TIMEZONE`, `SHOW timezone`},
-		{`EXPERIMENTAL SHOW ALL ZONE CONFIGURATIONS`, `EXPERIMENTAL SHOW ZONE CONFIGURATIONS`},
+		{`EXPERIMENTAL SHOW ALL ZONE CONFIGURATIONS`, `SHOW ZONE CONFIGURATIONS`},
+		{`SHOW ALL ZONE CONFIGURATIONS`, `SHOW ZONE CONFIGURATIONS`},
+		{`EXPERIMENTAL SHOW ZONE CONFIGURATIONS`, `SHOW ZONE CONFIGURATIONS`},
+		{`EXPERIMENTAL SHOW ZONE CONFIGURATION FOR TABLE db.t`, `SHOW ZONE CONFIGURATION FOR TABLE db.t`},
+		{`SHOW ZONE CONFIGURATION FOR TABLE t PARTITION p`, `SHOW ZONE CONFIGURATION FOR PARTITION p OF TABLE t`},
+		{`ALTER TABLE t EXPERIMENTAL CONFIGURE ZONE 'foo'`, `ALTER TABLE t CONFIGURE ZONE = 'foo'`},
+		{`ALTER TABLE t EXPERIMENTAL CONFIGURE ZONE NULL`, `ALTER TABLE t CONFIGURE ZONE DISCARD`},
+		{`ALTER TABLE t CONFIGURE ZONE = NULL`, `ALTER TABLE t CONFIGURE ZONE DISCARD`},
 		{`BEGIN`,
 			`BEGIN TRANSACTION`},
 		{`START TRANSACTION`,
@@ -1969,13 +2020,6 @@ HINT: See: https://github.com/cockroachdb/cockroach/issues/8318`,
 UPDATE foo SET a.b = 1
                  ^
 HINT: See: https://github.com/cockroachdb/cockroach/issues/8318`,
-		},
-		{
-			`SELECT * FROM ab, LATERAL (SELECT * FROM kv)`,
-			`unimplemented at or near "EOF"
-SELECT * FROM ab, LATERAL (SELECT * FROM kv)
-                                            ^
-HINT: See: https://github.com/cockroachdb/cockroach/issues/24560`,
 		},
 		{
 			`SELECT * FROM ab, LATERAL foo(a)`,
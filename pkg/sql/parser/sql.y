@@ -439,6 +439,12 @@ func (u *sqlSymUnion) resolvableFuncRefFromName() tree.ResolvableFunctionReferen
 func (u *sqlSymUnion) rowsFromExpr() *tree.RowsFromExpr {
     return u.val.(*tree.RowsFromExpr)
 }
+func (u *sqlSymUnion) zoneSpecifier() tree.ZoneSpecifier {
+    return u.val.(tree.ZoneSpecifier)
+}
+func (u *sqlSymUnion) setZoneConfig() *tree.SetZoneConfig {
+    return u.val.(*tree.SetZoneConfig)
+}
 func newNameFromStr(s string) *tree.Name {
     return (*tree.Name)(&s)
 }
@@ -471,7 +477,7 @@ func newNameFromStr(s string) *tree.Name {
 %token <str> CACHE CANCEL CASCADE CASE CAST CHANGEFEED CHAR
 %token <str> CHARACTER CHARACTERISTICS CHECK
 %token <str> CLUSTER COALESCE COLLATE COLLATION COLUMN COLUMNS COMMENT COMMIT
-%token <str> COMMITTED COMPACT CONCAT CONFIGURATION CONFIGURATIONS CONFIGURE
+%token <str> COMMITTED COMPACT CONCAT CONCURRENTLY CONFIGURATION CONFIGURATIONS CONFIGURE
 %token <str> CONFLICT CONSTRAINT CONSTRAINTS CONTAINS COPY COVERING CREATE
 %token <str> CROSS CUBE CURRENT CURRENT_CATALOG CURRENT_DATE CURRENT_SCHEMA
 %token <str> CURRENT_ROLE CURRENT_TIME CURRENT_TIMESTAMP
@@ -493,7 +499,7 @@ func newNameFromStr(s string) *tree.Name {
 
 %token <str> GIN GRANT GRANTS GREATEST GROUP GROUPING
 
-%token <str> HAVING HIGH HISTOGRAM HOUR
+%token <str> HAVING HIGH HISTOGRAM HISTORY HOUR
 
 
 %token <str> IMPORT INCREMENT INCREMENTAL IF IFERROR IFNULL ILIKE IN ISERROR
@@ -507,10 +513,10 @@ func newNameFromStr(s string) *tree.Name {
 %token <str> KEY KEYS KV
 
 %token <str> LATERAL LC_CTYPE LC_COLLATE
-%token <str> LEADING LEAST LEFT LESS LEVEL LIKE LIMIT LIST LOCAL
+%token <str> LEADING LEASE LEAST LEFT LESS LEVEL LIKE LIMIT LIST LOCAL
 %token <str> LOCALTIME LOCALTIMESTAMP LOW LSHIFT
 
-%token <str> MATCH MINVALUE MAXVALUE MINUTE MONTH
+%token <str> MATCH MATERIALIZED MINVALUE MAXVALUE MINUTE MONTH
 
 %token <str> NAN NAME NAMES NATURAL NEXT NO NO_INDEX_JOIN NORMAL
 %token <str> NOT NOTHING NOTNULL NULL NULLIF
@@ -524,18 +530,18 @@ func newNameFromStr(s string) *tree.Name {
 
 %token <str> QUERIES QUERY
 
-%token <str> RANGE RANGES READ REAL RECURSIVE REF REFERENCES
+%token <str> RANGE RANGES READ REAL RECURRING RECURSIVE REF REFERENCES REFRESH
 %token <str> REGCLASS REGPROC REGPROCEDURE REGNAMESPACE REGTYPE
-%token <str> REMOVE_PATH RENAME REPEATABLE
+%token <str> REMOVE_PATH RENAME REPEATABLE REPLICATION
 %token <str> RELEASE RESET RESTORE RESTRICT RESUME RETURNING REVOKE RIGHT
 %token <str> ROLE ROLES ROLLBACK ROLLUP ROW ROWS RSHIFT
 
-%token <str> SAVEPOINT SCATTER SCHEMA SCHEMAS SCRUB SEARCH SECOND SELECT SEQUENCE SEQUENCES
+%token <str> SAVEPOINT SCATTER SCHEDULE SCHEMA SCHEMAS SCRUB SEARCH SECOND SELECT SEQUENCE SEQUENCES
 %token <str> SERIAL SERIAL2 SERIAL4 SERIAL8
 %token <str> SERIALIZABLE SESSION SESSIONS SESSION_USER SET SETTING SETTINGS
 %token <str> SHOW SIMILAR SIMPLE SMALLINT SMALLSERIAL SNAPSHOT SOME SPLIT SQL
 
-%token <str> START STATISTICS STATUS STDIN STRICT STRING STORE STORED STORING SUBSTRING
+%token <str> START STATISTICS STATUS STDIN STREAM STRICT STRING STORE STORED STORING SUBSTRING
 %token <str> SYMMETRIC SYNTAX SYSTEM
 
 %token <str> TABLE TABLES TEMP TEMPLATE TEMPORARY TESTING_RANGES EXPERIMENTAL_RANGES TESTING_RELOCATE EXPERIMENTAL_RELOCATE TEXT THAN THEN
@@ -546,7 +552,7 @@ func newNameFromStr(s string) *tree.Name {
 %token <str> UNBOUNDED UNCOMMITTED UNION UNIQUE UNKNOWN
 %token <str> UPDATE UPSERT USE USER USERS USING UUID
 
-%token <str> VALID VALIDATE VALUE VALUES VARCHAR VARIADIC VIEW VARYING VIRTUAL
+%token <str> VALID VALIDATE VALUE VALUES VARCHAR VARIADIC VIEW VARYING VIRTUAL VISIBLE
 
 %token <str> WHEN WHERE WINDOW WITH WITHIN WITHOUT WORK WRITE
 
@@ -648,6 +654,8 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.Statement> create_user_stmt
 %type <tree.Statement> create_view_stmt
 %type <tree.Statement> create_changefeed_stmt
+%type <tree.Statement> create_replication_stream_stmt
+%type <tree.Statement> create_schedule_stmt
 %type <tree.Statement> create_sequence_stmt
 %type <tree.Statement> create_stats_stmt
 %type <tree.Statement> delete_stmt
@@ -658,6 +666,7 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.Statement> drop_database_stmt
 %type <tree.Statement> drop_index_stmt
 %type <tree.Statement> drop_role_stmt
+%type <tree.Statement> drop_schedule_stmt
 %type <tree.Statement> drop_table_stmt
 %type <tree.Statement> drop_user_stmt
 %type <tree.Statement> drop_view_stmt
@@ -665,6 +674,7 @@ func newNameFromStr(s string) *tree.Name {
 
 %type <tree.Statement> explain_stmt
 %type <tree.Statement> prepare_stmt
+%type <tree.Statement> refresh_stmt
 %type <tree.Statement> preparable_stmt
 %type <tree.Statement> explainable_stmt
 %type <tree.Statement> export_stmt
@@ -700,6 +710,7 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.Statement> show_create_table_stmt
 %type <tree.Statement> show_create_view_stmt
 %type <tree.Statement> show_create_sequence_stmt
+%type <tree.Statement> show_cluster_config_stmt
 %type <tree.Statement> show_csettings_stmt
 %type <tree.Statement> show_databases_stmt
 %type <tree.Statement> show_fingerprints_stmt
@@ -708,6 +719,7 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.Statement> show_indexes_stmt
 %type <tree.Statement> show_jobs_stmt
 %type <tree.Statement> show_queries_stmt
+%type <tree.Statement> show_range_lease_history_stmt
 %type <tree.Statement> show_ranges_stmt
 %type <tree.Statement> show_roles_stmt
 %type <tree.Statement> show_schemas_stmt
@@ -732,7 +744,9 @@ func newNameFromStr(s string) *tree.Name {
 
 %type <[]string> opt_incremental
 %type <tree.KVOption> kv_option
-%type <[]tree.KVOption> kv_option_list opt_with_options
+%type <[]tree.KVOption> kv_option_list opt_with_options var_set_list
+%type <*tree.SetZoneConfig> set_zone_config
+%type <tree.ZoneSpecifier> zone_specifier
 %type <str> import_format
 
 %type <*tree.Select> select_no_parens
@@ -886,6 +900,7 @@ func newNameFromStr(s string) *tree.Name {
 %type <*tree.UpdateExpr> single_set_clause
 %type <tree.AsOfClause> as_of_clause opt_as_of_clause
 %type <tree.Expr> opt_changefeed_sink
+%type <tree.Expr> opt_replication_stream_source
 
 %type <str> explain_option_name
 %type <[]string> explain_option_list
@@ -1065,6 +1080,7 @@ stmt:
 | import_stmt     // EXTEND WITH HELP: IMPORT
 | pause_stmt      // EXTEND WITH HELP: PAUSE JOBS
 | prepare_stmt    // EXTEND WITH HELP: PREPARE
+| refresh_stmt    // EXTEND WITH HELP: REFRESH
 | restore_stmt    // EXTEND WITH HELP: RESTORE
 | resume_stmt     // EXTEND WITH HELP: RESUME JOBS
 | revoke_stmt     // EXTEND WITH HELP: REVOKE
@@ -1089,7 +1105,7 @@ stmt:
 
 // %Help: ALTER
 // %Category: Group
-// %Text: ALTER TABLE, ALTER INDEX, ALTER VIEW, ALTER SEQUENCE, ALTER DATABASE, ALTER USER
+// %Text: ALTER TABLE, ALTER INDEX, ALTER VIEW, ALTER SEQUENCE, ALTER DATABASE, ALTER USER, ALTER RANGE
 alter_stmt:
   alter_ddl_stmt      // help texts in sub-rule
 | alter_user_stmt     // EXTEND WITH HELP: ALTER USER
@@ -1101,7 +1117,7 @@ alter_ddl_stmt:
 | alter_view_stmt     // EXTEND WITH HELP: ALTER VIEW
 | alter_sequence_stmt // EXTEND WITH HELP: ALTER SEQUENCE
 | alter_database_stmt // EXTEND WITH HELP: ALTER DATABASE
-| alter_range_stmt
+| alter_range_stmt    // EXTEND WITH HELP: ALTER RANGE
 
 // %Help: ALTER TABLE - change the definition of a table
 // %Category: DDL
@@ -1117,11 +1133,14 @@ alter_ddl_stmt:
 //   ALTER TABLE ... ALTER [COLUMN] <colname> DROP NOT NULL
 //   ALTER TABLE ... ALTER [COLUMN] <colname> DROP STORED
 //   ALTER TABLE ... ALTER [COLUMN] <colname> [SET DATA] TYPE <type> [COLLATE <collation>]
+//   ALTER TABLE ... ALTER PRIMARY KEY USING COLUMNS ( <colnames...> )
 //   ALTER TABLE ... RENAME TO <newname>
 //   ALTER TABLE ... RENAME [COLUMN] <colname> TO <newname>
 //   ALTER TABLE ... VALIDATE CONSTRAINT <constraintname>
 //   ALTER TABLE ... SPLIT AT <selectclause>
 //   ALTER TABLE ... SCATTER [ FROM ( <exprs...> ) TO ( <exprs...> ) ]
+//   ALTER TABLE ... CONFIGURE ZONE <zoneconfig>
+//   ALTER PARTITION ... OF TABLE ... CONFIGURE ZONE <zoneconfig>
 //
 // Column qualifiers:
 //   [CONSTRAINT <constraintname>] {NULL | NOT NULL | UNIQUE | PRIMARY KEY | CHECK (<expr>) | DEFAULT <expr>}
@@ -1129,6 +1148,11 @@ alter_ddl_stmt:
 //   REFERENCES <tablename> [( <colnames...> )]
 //   COLLATE <collationname>
 //
+// Zone configurations:
+//   DISCARD
+//   USING <var> = <expr> [, ...]
+//   = <yaml>
+//
 // %SeeAlso: WEBDOCS/alter-table.html
 alter_table_stmt:
   alter_onetable_stmt
@@ -1161,6 +1185,7 @@ alter_view_stmt:
 //   [MAXVALUE <maxvalue> | NO MAXVALUE]
 //   [START <start>]
 //   [[NO] CYCLE]
+//   [OWNED BY <tablename>.<colname> | OWNED BY NONE]
 // ALTER SEQUENCE [IF EXISTS] <name> RENAME TO <newname>
 alter_sequence_stmt:
   alter_rename_sequence_stmt
@@ -1190,6 +1215,13 @@ alter_user_stmt:
 // %Category: DDL
 // %Text:
 // ALTER DATABASE <name> RENAME TO <newname>
+// ALTER DATABASE <name> CONFIGURE ZONE <zoneconfig>
+//
+// Zone configurations:
+//   DISCARD
+//   USING <var> = <expr> [, ...]
+//   = <yaml>
+//
 // %SeeAlso: WEBDOCS/alter-database.html
 alter_database_stmt:
   alter_rename_database_stmt
@@ -1198,8 +1230,20 @@ alter_database_stmt:
 // prefix is spread over multiple non-terminals.
 | ALTER DATABASE error // SHOW HELP: ALTER DATABASE
 
+// %Help: ALTER RANGE - change the parameters of a range
+// %Category: DDL
+// %Text:
+// ALTER RANGE <zonename> CONFIGURE ZONE <zoneconfig>
+//
+// Zone configurations:
+//   DISCARD
+//   USING <var> = <expr> [, ...]
+//   = <yaml>
+//
+// %SeeAlso: ALTER TABLE
 alter_range_stmt:
   alter_zone_range_stmt
+| ALTER RANGE error // SHOW HELP: ALTER RANGE
 
 // %Help: ALTER INDEX - change the definition of an index
 // %Category: DDL
@@ -1210,6 +1254,13 @@ alter_range_stmt:
 //   ALTER INDEX ... RENAME TO <newname>
 //   ALTER INDEX ... SPLIT AT <selectclause>
 //   ALTER INDEX ... SCATTER [ FROM ( <exprs...> ) TO ( <exprs...> ) ]
+//   ALTER INDEX ... [NOT] VISIBLE
+//   ALTER INDEX ... CONFIGURE ZONE <zoneconfig>
+//
+// Zone configurations:
+//   DISCARD
+//   USING <var> = <expr> [, ...]
+//   = <yaml>
 //
 // %SeeAlso: WEBDOCS/alter-index.html
 alter_index_stmt:
@@ -1261,6 +1312,11 @@ alter_relocate_stmt:
     /* SKIP DOC */
     $$.val = &tree.Relocate{Table: $3.newNormalizableTableNameFromUnresolvedName(), Rows: $5.slct()}
   }
+| ALTER TABLE table_name relocate_kw LEASE select_stmt
+  {
+    /* SKIP DOC */
+    $$.val = &tree.Relocate{Table: $3.newNormalizableTableNameFromUnresolvedName(), Rows: $6.slct(), RelocateLease: true}
+  }
 
 relocate_kw:
   TESTING_RELOCATE
@@ -1272,60 +1328,86 @@ alter_relocate_index_stmt:
     /* SKIP DOC */
     $$.val = &tree.Relocate{Index: $3.newTableWithIdx(), Rows: $5.slct()}
   }
+| ALTER INDEX table_name_with_index relocate_kw LEASE select_stmt
+  {
+    /* SKIP DOC */
+    $$.val = &tree.Relocate{Index: $3.newTableWithIdx(), Rows: $6.slct(), RelocateLease: true}
+  }
 
 alter_zone_range_stmt:
-  ALTER RANGE zone_name EXPERIMENTAL CONFIGURE ZONE a_expr_const
+  ALTER RANGE zone_name set_zone_config
   {
-    /* SKIP DOC */
-    $$.val = &tree.SetZoneConfig{
-      ZoneSpecifier: tree.ZoneSpecifier{NamedZone: tree.UnrestrictedName($3)},
-      YAMLConfig: $7.expr(),
-    }
+    s := $4.setZoneConfig()
+    s.ZoneSpecifier = tree.ZoneSpecifier{NamedZone: tree.UnrestrictedName($3)}
+    $$.val = s
   }
 
 alter_zone_database_stmt:
-  ALTER DATABASE database_name EXPERIMENTAL CONFIGURE ZONE a_expr_const
+  ALTER DATABASE database_name set_zone_config
   {
-    /* SKIP DOC */
-    $$.val = &tree.SetZoneConfig{
-      ZoneSpecifier: tree.ZoneSpecifier{Database: tree.Name($3)},
-      YAMLConfig: $7.expr(),
-    }
+    s := $4.setZoneConfig()
+    s.ZoneSpecifier = tree.ZoneSpecifier{Database: tree.Name($3)}
+    $$.val = s
   }
 
 alter_zone_table_stmt:
-  ALTER TABLE table_name EXPERIMENTAL CONFIGURE ZONE a_expr_const
+  ALTER TABLE table_name set_zone_config
   {
-    /* SKIP DOC */
-    $$.val = &tree.SetZoneConfig{
-      ZoneSpecifier: tree.ZoneSpecifier{
-        TableOrIndex: tree.TableNameWithIndex{Table: $3.normalizableTableNameFromUnresolvedName()},
-      },
-      YAMLConfig: $7.expr(),
+    s := $4.setZoneConfig()
+    s.ZoneSpecifier = tree.ZoneSpecifier{
+      TableOrIndex: tree.TableNameWithIndex{Table: $3.normalizableTableNameFromUnresolvedName()},
     }
+    $$.val = s
   }
-| ALTER PARTITION partition_name OF TABLE table_name EXPERIMENTAL CONFIGURE ZONE a_expr_const
+| ALTER PARTITION partition_name OF TABLE table_name set_zone_config
   {
-    /* SKIP DOC */
-    $$.val = &tree.SetZoneConfig{
-      ZoneSpecifier: tree.ZoneSpecifier{
-        TableOrIndex: tree.TableNameWithIndex{Table: $6.normalizableTableNameFromUnresolvedName()},
-        Partition: tree.Name($3),
-      },
-      YAMLConfig: $10.expr(),
+    s := $7.setZoneConfig()
+    s.ZoneSpecifier = tree.ZoneSpecifier{
+      TableOrIndex: tree.TableNameWithIndex{Table: $6.normalizableTableNameFromUnresolvedName()},
+      Partition: tree.Name($3),
     }
+    $$.val = s
   }
 
 alter_zone_index_stmt:
-  ALTER INDEX table_name_with_index EXPERIMENTAL CONFIGURE ZONE a_expr_const
+  ALTER INDEX table_name_with_index set_zone_config
   {
-    /* SKIP DOC */
-    $$.val = &tree.SetZoneConfig{
-      ZoneSpecifier: tree.ZoneSpecifier{
-        TableOrIndex: $3.tableWithIdx(),
-      },
-      YAMLConfig: $7.expr(),
+    s := $4.setZoneConfig()
+    s.ZoneSpecifier = tree.ZoneSpecifier{
+      TableOrIndex: $3.tableWithIdx(),
     }
+    $$.val = s
+  }
+
+// set_zone_config is the common suffix of the ALTER ... CONFIGURE ZONE
+// statements. The YAML form is kept for backward compatibility.
+set_zone_config:
+  CONFIGURE ZONE USING var_set_list
+  {
+    $$.val = &tree.SetZoneConfig{Options: $4.kvOptions()}
+  }
+| CONFIGURE ZONE DISCARD
+  {
+    $$.val = &tree.SetZoneConfig{YAMLConfig: tree.DNull}
+  }
+| CONFIGURE ZONE '=' a_expr_const
+  {
+    $$.val = &tree.SetZoneConfig{YAMLConfig: $4.expr()}
+  }
+| EXPERIMENTAL CONFIGURE ZONE a_expr_const
+  {
+    /* SKIP DOC */
+    $$.val = &tree.SetZoneConfig{YAMLConfig: $4.expr()}
+  }
+
+var_set_list:
+  var_name '=' var_value
+  {
+    $$.val = []tree.KVOption{{Key: tree.Name(strings.Join($1.strs(), ".")), Value: $3.expr()}}
+  }
+| var_set_list ',' var_name '=' var_value
+  {
+    $$.val = append($1.kvOptions(), tree.KVOption{Key: tree.Name(strings.Join($3.strs(), ".")), Value: $5.expr()})
   }
 
 alter_scatter_stmt:
@@ -1396,6 +1478,11 @@ alter_table_cmd:
   }
   // ALTER TABLE <name> ALTER [COLUMN] <colname> SET NOT NULL
 | ALTER opt_column column_name SET NOT NULL { return unimplemented(sqllex, "alter set non null") }
+  // ALTER TABLE <name> ALTER PRIMARY KEY USING COLUMNS ( <colnames...> )
+| ALTER PRIMARY KEY USING COLUMNS '(' index_params ')'
+  {
+    $$.val = &tree.AlterTableAlterPrimaryKey{Columns: $7.idxElems()}
+  }
   // ALTER TABLE <name> DROP [COLUMN] IF EXISTS <colname> [RESTRICT|CASCADE]
 | DROP opt_column IF EXISTS column_name opt_drop_behavior
   {
@@ -1507,6 +1594,14 @@ alter_index_cmd:
       PartitionBy: $1.partitionBy(),
     }
   }
+| VISIBLE
+  {
+    $$.val = &tree.AlterIndexVisible{NotVisible: false}
+  }
+| NOT VISIBLE
+  {
+    $$.val = &tree.AlterIndexVisible{NotVisible: true}
+  }
 
 alter_column_default:
   SET DEFAULT a_expr
@@ -1624,6 +1719,7 @@ import_format:
 //        <format>
 //        DATA ( <datafile> [, ...] )
 //        [ WITH <option> [= <value>] [, ...] ]
+// IMPORT TABLE <tablename> FROM PGURL ( <pgurl> )
 //
 // Formats:
 //    CSV
@@ -1631,6 +1727,7 @@ import_format:
 //    MYSQLDUMP (mysqldump's SQL output)
 //    PGCOPY
 //    PGDUMP
+//    PGURL (a table read from a postgres-compatible server)
 //
 // Options:
 //    distributed = '...'
@@ -1639,6 +1736,7 @@ import_format:
 //    delimiter = '...'      [CSV, PGCOPY-specific]
 //    nullif = '...'         [CSV, PGCOPY-specific]
 //    comment = '...'        [CSV-specific]
+//    source_table = '...'   [PGURL-specific]
 //
 // %SeeAlso: CREATE TABLE
 import_stmt:
@@ -1890,6 +1988,7 @@ create_stmt:
 | create_role_stmt     // EXTEND WITH HELP: CREATE ROLE
 | create_ddl_stmt      // help texts in sub-rule
 | create_stats_stmt    // EXTEND WITH HELP: CREATE STATISTICS
+| create_schedule_stmt // EXTEND WITH HELP: CREATE SCHEDULE
 | CREATE error         // SHOW HELP: CREATE
 
 create_ddl_stmt:
@@ -1902,6 +2001,7 @@ create_ddl_stmt:
 | create_view_stmt     // EXTEND WITH HELP: CREATE VIEW
 | create_sequence_stmt // EXTEND WITH HELP: CREATE SEQUENCE
 | create_changefeed_stmt
+| create_replication_stream_stmt
 
 // %Help: CREATE STATISTICS - create a new table statistic
 // %Category: Misc
@@ -1920,6 +2020,34 @@ create_stats_stmt:
   }
 | CREATE STATISTICS error // SHOW HELP: CREATE STATISTICS
 
+// %Help: CREATE SCHEDULE - run a backup on a recurring schedule
+// %Category: CCL
+// %Text:
+// CREATE SCHEDULE <name> FOR BACKUP <targets...> TO <location...>
+//        [ INCREMENTAL FROM <location...> ]
+//        [ WITH <option> [= <value>] [, ...] ]
+//        RECURRING <cronexpr>
+//
+// Targets:
+//    TABLE <pattern> [, ...]
+//    DATABASE <databasename> [, ...]
+//
+// Cron expression:
+//    '<minute> <hour> <day of month> <month> <day of week>'
+//    '@hourly' | '@daily' | '@weekly' | '@monthly' | '@yearly'
+//
+// %SeeAlso: BACKUP, DROP SCHEDULE, PAUSE JOBS, RESUME JOBS
+create_schedule_stmt:
+  CREATE SCHEDULE name FOR BACKUP targets TO string_or_placeholder opt_incremental opt_with_options RECURRING string_or_placeholder
+  {
+    $$.val = &tree.CreateSchedule{
+      Name: tree.Name($3),
+      Backup: &tree.Backup{Targets: $6.targetList(), To: $8.expr(), IncrementalFrom: $9.exprs(), Options: $10.kvOptions()},
+      Recurrence: $12.expr(),
+    }
+  }
+| CREATE SCHEDULE error // SHOW HELP: CREATE SCHEDULE
+
 create_changefeed_stmt:
   CREATE CHANGEFEED FOR targets opt_changefeed_sink opt_with_options
   {
@@ -1940,6 +2068,26 @@ opt_changefeed_sink:
     $$.val = nil
   }
 
+create_replication_stream_stmt:
+  CREATE REPLICATION STREAM FOR targets opt_replication_stream_source opt_with_options
+  {
+    $$.val = &tree.CreateReplicationStream{
+      Targets: $5.targetList(),
+      SourceURI: $6.expr(),
+      Options: $7.kvOptions(),
+    }
+  }
+
+opt_replication_stream_source:
+  FROM string_or_placeholder
+  {
+    $$.val = $2.expr()
+  }
+| /* EMPTY */
+  {
+    $$.val = nil
+  }
+
 // %Help: DELETE - delete rows from a table
 // %Category: DML
 // %Text: DELETE FROM <tablename> [WHERE <expr>]
@@ -1984,6 +2132,7 @@ drop_stmt:
   drop_ddl_stmt      // help texts in sub-rule
 | drop_role_stmt     // EXTEND WITH HELP: DROP ROLE
 | drop_user_stmt     // EXTEND WITH HELP: DROP USER
+| drop_schedule_stmt // EXTEND WITH HELP: DROP SCHEDULE
 | DROP error         // SHOW HELP: DROP
 
 drop_ddl_stmt:
@@ -1993,9 +2142,20 @@ drop_ddl_stmt:
 | drop_view_stmt     // EXTEND WITH HELP: DROP VIEW
 | drop_sequence_stmt // EXTEND WITH HELP: DROP SEQUENCE
 
+// %Help: DROP SCHEDULE - remove a backup schedule
+// %Category: CCL
+// %Text: DROP SCHEDULE <name>
+// %SeeAlso: CREATE SCHEDULE
+drop_schedule_stmt:
+  DROP SCHEDULE name
+  {
+    $$.val = &tree.ControlSchedule{Name: tree.Name($3), Command: tree.DropSchedule}
+  }
+| DROP SCHEDULE error // SHOW HELP: DROP SCHEDULE
+
 // %Help: DROP VIEW - remove a view
 // %Category: DDL
-// %Text: DROP VIEW [IF EXISTS] <tablename> [, ...] [CASCADE | RESTRICT]
+// %Text: DROP [MATERIALIZED] VIEW [IF EXISTS] <tablename> [, ...] [CASCADE | RESTRICT]
 // %SeeAlso: WEBDOCS/drop-index.html
 drop_view_stmt:
   DROP VIEW table_name_list opt_drop_behavior
@@ -2006,7 +2166,26 @@ drop_view_stmt:
   {
     $$.val = &tree.DropView{Names: $5.normalizableTableNames(), IfExists: true, DropBehavior: $6.dropBehavior()}
   }
+| DROP MATERIALIZED VIEW table_name_list opt_drop_behavior
+  {
+    $$.val = &tree.DropView{
+      Names: $4.normalizableTableNames(),
+      IfExists: false,
+      DropBehavior: $5.dropBehavior(),
+      IsMaterialized: true,
+    }
+  }
+| DROP MATERIALIZED VIEW IF EXISTS table_name_list opt_drop_behavior
+  {
+    $$.val = &tree.DropView{
+      Names: $6.normalizableTableNames(),
+      IfExists: true,
+      DropBehavior: $7.dropBehavior(),
+      IsMaterialized: true,
+    }
+  }
 | DROP VIEW error // SHOW HELP: DROP VIEW
+| DROP MATERIALIZED VIEW error // SHOW HELP: DROP VIEW
 
 // %Help: DROP SEQUENCE - remove a sequence
 // %Category: DDL
@@ -2129,7 +2308,9 @@ table_name_list:
 // %Text:
 // EXPLAIN <statement>
 // EXPLAIN ([PLAN ,] <planoptions...> ) <statement>
+// EXPLAIN ANALYZE [(PLAN)] <statement>
 // EXPLAIN [ANALYZE] (DISTSQL) <statement>
+// EXPLAIN ANALYZE (DEBUG) <statement>
 //
 // Explainable statements:
 //     SELECT, CREATE, DROP, ALTER, INSERT, UPSERT, UPDATE, DELETE,
@@ -2149,6 +2330,10 @@ explain_stmt:
   {
     $$.val = &tree.Explain{Options: $3.strs(), Statement: $5.stmt()}
   }
+| EXPLAIN ANALYZE explainable_stmt
+  {
+    $$.val = &tree.Explain{Options: []string{$2}, Statement: $3.stmt()}
+  }
 | EXPLAIN ANALYZE '(' explain_option_list ')' explainable_stmt
   {
     $$.val = &tree.Explain{Options: append($4.strs(), $2), Statement: $6.stmt()}
@@ -2768,7 +2953,7 @@ zone_value:
 // SHOW SESSION, SHOW CLUSTER SETTING, SHOW DATABASES, SHOW TABLES, SHOW COLUMNS, SHOW INDEXES,
 // SHOW CONSTRAINTS, SHOW CREATE TABLE, SHOW CREATE VIEW, SHOW CREATE SEQUENCE, SHOW USERS,
 // SHOW TRANSACTION, SHOW BACKUP, SHOW JOBS, SHOW QUERIES, SHOW ROLES, SHOW SESSIONS, SHOW SYNTAX,
-// SHOW TRACE
+// SHOW TRACE, SHOW ZONE
 show_stmt:
   show_backup_stmt          // EXTEND WITH HELP: SHOW BACKUP
 | show_columns_stmt         // EXTEND WITH HELP: SHOW COLUMNS
@@ -2776,6 +2961,7 @@ show_stmt:
 | show_create_table_stmt    // EXTEND WITH HELP: SHOW CREATE TABLE
 | show_create_view_stmt     // EXTEND WITH HELP: SHOW CREATE VIEW
 | show_create_sequence_stmt // EXTEND WITH HELP: SHOW CREATE SEQUENCE
+| show_cluster_config_stmt  // EXTEND WITH HELP: SHOW CLUSTER CONFIGURATION
 | show_csettings_stmt       // EXTEND WITH HELP: SHOW CLUSTER SETTING
 | show_databases_stmt       // EXTEND WITH HELP: SHOW DATABASES
 | show_fingerprints_stmt
@@ -2784,6 +2970,7 @@ show_stmt:
 | show_indexes_stmt         // EXTEND WITH HELP: SHOW INDEXES
 | show_jobs_stmt            // EXTEND WITH HELP: SHOW JOBS
 | show_queries_stmt         // EXTEND WITH HELP: SHOW QUERIES
+| show_range_lease_history_stmt // EXTEND WITH HELP: SHOW RANGE LEASE HISTORY
 | show_ranges_stmt          // EXTEND WITH HELP: SHOW RANGES
 | show_roles_stmt           // EXTEND WITH HELP: SHOW ROLES
 | show_schemas_stmt         // EXTEND WITH HELP: SHOW SCHEMAS
@@ -2795,7 +2982,7 @@ show_stmt:
 | show_trace_stmt           // EXTEND WITH HELP: SHOW TRACE
 | show_transaction_stmt     // EXTEND WITH HELP: SHOW TRANSACTION
 | show_users_stmt           // EXTEND WITH HELP: SHOW USERS
-| show_zone_stmt
+| show_zone_stmt            // EXTEND WITH HELP: SHOW ZONE
 | SHOW error                // SHOW HELP: SHOW
 
 // %Help: SHOW SESSION - display session variables
@@ -2912,6 +3099,22 @@ show_csettings_stmt:
   }
 | SHOW ALL CLUSTER error // SHOW HELP: SHOW CLUSTER SETTING
 
+// %Help: SHOW CLUSTER CONFIGURATION - export the cluster configuration as SQL
+// %Category: Cfg
+// %Text: SHOW CLUSTER CONFIGURATION
+//
+// Returns the statements that recreate the changed cluster settings,
+// the users and roles, the privileges and the zone configurations.
+// The statements are idempotent and can be replayed on a rebuilt
+// cluster. Passwords are not included.
+// %SeeAlso: SHOW CLUSTER SETTING, SHOW GRANTS
+show_cluster_config_stmt:
+  SHOW CLUSTER CONFIGURATION
+  {
+    $$.val = &tree.ShowClusterConfiguration{}
+  }
+| SHOW CLUSTER CONFIGURATION error // SHOW HELP: SHOW CLUSTER CONFIGURATION
+
 // %Help: SHOW COLUMNS - list columns in relation
 // %Category: DDL
 // %Text: SHOW COLUMNS FROM <tablename>
@@ -3206,38 +3409,36 @@ show_roles_stmt:
   }
 | SHOW ROLES error // SHOW HELP: SHOW ROLES
 
+// %Help: SHOW ZONE - display current zone configuration
+// %Category: Cfg
+// %Text:
+// SHOW ZONE CONFIGURATION FOR RANGE <zone_name>
+// SHOW ZONE CONFIGURATION FOR DATABASE <database_name>
+// SHOW ZONE CONFIGURATION FOR TABLE <table_name> [PARTITION <partition_name>]
+// SHOW ZONE CONFIGURATION FOR PARTITION <partition_name> OF TABLE <table_name>
+// SHOW ZONE CONFIGURATION FOR INDEX <table_name>@<index_name>
+// SHOW [ALL] ZONE CONFIGURATIONS
+//
+// The configuration shown is the one that applies to the object, which may be
+// inherited from a parent zone: cli_specifier names the zone it comes from.
+// %SeeAlso: ALTER TABLE, ALTER INDEX, ALTER DATABASE, ALTER RANGE
 show_zone_stmt:
-  EXPERIMENTAL SHOW ZONE CONFIGURATION FOR RANGE zone_name
+  SHOW ZONE CONFIGURATION FOR zone_specifier
   {
-    /* SKIP DOC */
-    $$.val = &tree.ShowZoneConfig{ZoneSpecifier: tree.ZoneSpecifier{NamedZone: tree.UnrestrictedName($7)}}
+    $$.val = &tree.ShowZoneConfig{ZoneSpecifier: $5.zoneSpecifier()}
   }
-| EXPERIMENTAL SHOW ZONE CONFIGURATION FOR DATABASE database_name
+| SHOW ZONE CONFIGURATIONS
   {
-    /* SKIP DOC */
-    $$.val = &tree.ShowZoneConfig{ZoneSpecifier: tree.ZoneSpecifier{Database: tree.Name($7)}}
-  }
-| EXPERIMENTAL SHOW ZONE CONFIGURATION FOR TABLE table_name opt_partition
-  {
-    /* SKIP DOC */
-    $$.val = &tree.ShowZoneConfig{ZoneSpecifier: tree.ZoneSpecifier{
-        TableOrIndex: tree.TableNameWithIndex{Table: $7.normalizableTableNameFromUnresolvedName() },
-    }}
+    $$.val = &tree.ShowZoneConfig{}
   }
-| EXPERIMENTAL SHOW ZONE CONFIGURATION FOR PARTITION partition_name OF TABLE table_name
+| SHOW ALL ZONE CONFIGURATIONS
   {
-    /* SKIP DOC */
-    $$.val = &tree.ShowZoneConfig{ZoneSpecifier: tree.ZoneSpecifier{
-        TableOrIndex: tree.TableNameWithIndex{Table: $10.normalizableTableNameFromUnresolvedName() },
-      Partition: tree.Name($7),
-    }}
+    $$.val = &tree.ShowZoneConfig{}
   }
-| EXPERIMENTAL SHOW ZONE CONFIGURATION FOR INDEX table_name_with_index
+| EXPERIMENTAL SHOW ZONE CONFIGURATION FOR zone_specifier
   {
     /* SKIP DOC */
-    $$.val = &tree.ShowZoneConfig{ZoneSpecifier: tree.ZoneSpecifier{
-      TableOrIndex: $7.tableWithIdx(),
-    }}
+    $$.val = &tree.ShowZoneConfig{ZoneSpecifier: $6.zoneSpecifier()}
   }
 | EXPERIMENTAL SHOW ZONE CONFIGURATIONS
   {
@@ -3249,6 +3450,35 @@ show_zone_stmt:
     /* SKIP DOC */
     $$.val = &tree.ShowZoneConfig{}
   }
+| SHOW ZONE error // SHOW HELP: SHOW ZONE
+
+zone_specifier:
+  RANGE zone_name
+  {
+    $$.val = tree.ZoneSpecifier{NamedZone: tree.UnrestrictedName($2)}
+  }
+| DATABASE database_name
+  {
+    $$.val = tree.ZoneSpecifier{Database: tree.Name($2)}
+  }
+| TABLE table_name opt_partition
+  {
+    $$.val = tree.ZoneSpecifier{
+      TableOrIndex: tree.TableNameWithIndex{Table: $2.normalizableTableNameFromUnresolvedName()},
+      Partition: tree.Name($3),
+    }
+  }
+| PARTITION partition_name OF TABLE table_name
+  {
+    $$.val = tree.ZoneSpecifier{
+      TableOrIndex: tree.TableNameWithIndex{Table: $5.normalizableTableNameFromUnresolvedName()},
+      Partition: tree.Name($2),
+    }
+  }
+| INDEX table_name_with_index
+  {
+    $$.val = tree.ZoneSpecifier{TableOrIndex: $2.tableWithIdx()}
+  }
 
 // %Help: SHOW RANGES - list ranges
 // %Category: Misc
@@ -3270,6 +3500,26 @@ ranges_kw:
   TESTING_RANGES
 | EXPERIMENTAL_RANGES
 
+// %Help: SHOW RANGE LEASE HISTORY - show the recent lease holders of a range
+// %Category: Misc
+// %Text: SHOW RANGE <range_id> LEASE HISTORY
+//
+// Returns the most recent leases of the range with the given ID
+// (as returned by SHOW EXPERIMENTAL_RANGES), as remembered by its
+// replicas.
+// %SeeAlso: SHOW RANGES
+show_range_lease_history_stmt:
+  SHOW RANGE ICONST LEASE HISTORY
+  {
+    id, err := $3.numVal().AsInt64()
+    if err != nil {
+      sqllex.Error(err.Error())
+      return 1
+    }
+    $$.val = &tree.ShowRangeLeaseHistory{RangeID: id}
+  }
+| SHOW RANGE error // SHOW HELP: SHOW RANGE LEASE HISTORY
+
 show_fingerprints_stmt:
   SHOW EXPERIMENTAL_FINGERPRINTS FROM TABLE table_name
   {
@@ -3493,7 +3743,8 @@ for_grantee_clause:
 // %Text:
 // PAUSE JOBS <selectclause>
 // PAUSE JOB <jobid>
-// %SeeAlso: SHOW JOBS, CANCEL JOBS, RESUME JOBS
+// PAUSE SCHEDULE <name>
+// %SeeAlso: SHOW JOBS, CANCEL JOBS, RESUME JOBS, CREATE SCHEDULE
 pause_stmt:
   PAUSE JOB a_expr
   {
@@ -3508,6 +3759,10 @@ pause_stmt:
   {
     $$.val = &tree.ControlJobs{Jobs: $3.slct(), Command: tree.PauseJob}
   }
+| PAUSE SCHEDULE name
+  {
+    $$.val = &tree.ControlSchedule{Name: tree.Name($3), Command: tree.PauseSchedule}
+  }
 | PAUSE error // SHOW HELP: PAUSE JOBS
 
 // %Help: CREATE TABLE - create a new table
@@ -3844,7 +4099,7 @@ col_qualification_elem:
  }
 
 index_def:
-  INDEX opt_index_name '(' index_params ')' opt_storing opt_interleave opt_partition_by
+  INDEX opt_index_name '(' index_params ')' opt_storing opt_interleave opt_partition_by where_clause
   {
     $$.val = &tree.IndexTableDef{
       Name:    tree.Name($2),
@@ -3852,6 +4107,7 @@ index_def:
       Storing: $6.nameList(),
       Interleave: $7.interleave(),
       PartitionBy: $8.partitionBy(),
+      Predicate: $9.expr(),
     }
   }
 | UNIQUE INDEX opt_index_name '(' index_params ')' opt_storing opt_interleave opt_partition_by
@@ -4060,6 +4316,7 @@ numeric_only:
 //   [START [WITH] <start>]
 //   [CACHE <cache>]
 //   [NO CYCLE]
+//   [OWNED BY <tablename>.<colname> | OWNED BY NONE]
 //
 // %SeeAlso: CREATE TABLE
 create_sequence_stmt:
@@ -4095,7 +4352,24 @@ sequence_option_elem:
 | CYCLE                        { /* SKIP DOC */
                                  $$.val = tree.SequenceOption{Name: tree.SeqOptCycle} }
 | NO CYCLE                     { $$.val = tree.SequenceOption{Name: tree.SeqOptNoCycle} }
-| OWNED BY column_path         { return unimplemented(sqllex, "create sequence OWNED BY option") }
+| OWNED BY column_path         { name := $3.unresolvedName()
+                                 // As in Postgres, NONE is not a keyword: an unqualified name
+                                 // "none" means the sequence is not owned by any column.
+                                 if name.NumParts == 1 && name.Parts[0] == "none" {
+                                   $$.val = tree.SequenceOption{Name: tree.SeqOptOwnedBy}
+                                 } else {
+                                   varName, err := name.NormalizeVarName()
+                                   if err != nil {
+                                     sqllex.Error(err.Error())
+                                     return 1
+                                   }
+                                   columnItem, ok := varName.(*tree.ColumnItem)
+                                   if !ok {
+                                     sqllex.Error(fmt.Sprintf("invalid column name: %q", tree.ErrString(name)))
+                                     return 1
+                                   }
+                                   $$.val = tree.SequenceOption{Name: tree.SeqOptOwnedBy, ColumnItemVal: columnItem}
+                                 } }
 | CACHE signed_iconst64        { /* SKIP DOC */
                                  x := $2.int64()
                                  $$.val = tree.SequenceOption{Name: tree.SeqOptCache, IntVal: &x} }
@@ -4167,8 +4441,8 @@ create_role_stmt:
 
 // %Help: CREATE VIEW - create a new view
 // %Category: DDL
-// %Text: CREATE VIEW <viewname> [( <colnames...> )] AS <source>
-// %SeeAlso: CREATE TABLE, SHOW CREATE VIEW, WEBDOCS/create-view.html
+// %Text: CREATE [MATERIALIZED] VIEW <viewname> [( <colnames...> )] AS <source>
+// %SeeAlso: CREATE TABLE, SHOW CREATE VIEW, REFRESH, WEBDOCS/create-view.html
 create_view_stmt:
   CREATE VIEW view_name opt_column_list AS select_stmt
   {
@@ -4178,7 +4452,17 @@ create_view_stmt:
       AsSource: $6.slct(),
     }
   }
+| CREATE MATERIALIZED VIEW view_name opt_column_list AS select_stmt
+  {
+    $$.val = &tree.CreateView{
+      Name: $4.normalizableTableNameFromUnresolvedName(),
+      ColumnNames: $5.nameList(),
+      AsSource: $7.slct(),
+      Materialized: true,
+    }
+  }
 | CREATE VIEW error // SHOW HELP: CREATE VIEW
+| CREATE MATERIALIZED VIEW error // SHOW HELP: CREATE VIEW
 
 // TODO(a-robinson): CREATE OR REPLACE VIEW support (#2971).
 
@@ -4188,6 +4472,7 @@ create_view_stmt:
 // CREATE [UNIQUE | INVERTED] INDEX [IF NOT EXISTS] [<idxname>]
 //        ON <tablename> ( <colname> [ASC | DESC] [, ...] )
 //        [STORING ( <colnames...> )] [<interleave>]
+//        [WHERE <predicate>]
 //
 // Interleave clause:
 //    INTERLEAVE IN PARENT <tablename> ( <colnames...> ) [CASCADE | RESTRICT]
@@ -4195,7 +4480,7 @@ create_view_stmt:
 // %SeeAlso: CREATE TABLE, SHOW INDEXES, SHOW CREATE INDEX,
 // WEBDOCS/create-index.html
 create_index_stmt:
-  CREATE opt_unique INDEX opt_index_name ON table_name opt_using_gin_btree '(' index_params ')' opt_storing opt_interleave opt_partition_by
+  CREATE opt_unique INDEX opt_index_name ON table_name opt_using_gin_btree '(' index_params ')' opt_storing opt_interleave opt_partition_by where_clause
   {
     $$.val = &tree.CreateIndex{
       Name:    tree.Name($4),
@@ -4206,9 +4491,10 @@ create_index_stmt:
       Interleave: $12.interleave(),
       PartitionBy: $13.partitionBy(),
       Inverted: $7.bool(),
+      Predicate: $14.expr(),
     }
   }
-| CREATE opt_unique INDEX IF NOT EXISTS index_name ON table_name opt_using_gin_btree '(' index_params ')' opt_storing opt_interleave opt_partition_by
+| CREATE opt_unique INDEX IF NOT EXISTS index_name ON table_name opt_using_gin_btree '(' index_params ')' opt_storing opt_interleave opt_partition_by where_clause
   {
     $$.val = &tree.CreateIndex{
       Name:        tree.Name($7),
@@ -4220,6 +4506,7 @@ create_index_stmt:
       Interleave: $15.interleave(),
       PartitionBy: $16.partitionBy(),
       Inverted: $10.bool(),
+      Predicate: $17.expr(),
     }
   }
 | CREATE INVERTED INDEX opt_index_name ON table_name '(' index_params ')'
@@ -4394,6 +4681,26 @@ opt_set_data:
   SET DATA { $$.val = true }
 | /* EMPTY */ { $$.val = false }
 
+// %Help: REFRESH - recompute the rows of a materialized view
+// %Category: DDL
+// %Text: REFRESH MATERIALIZED VIEW [CONCURRENTLY] <viewname>
+// %SeeAlso: CREATE VIEW
+refresh_stmt:
+  REFRESH MATERIALIZED VIEW view_name
+  {
+    $$.val = &tree.RefreshMaterializedView{
+      Name: $4.normalizableTableNameFromUnresolvedName(),
+    }
+  }
+| REFRESH MATERIALIZED VIEW CONCURRENTLY view_name
+  {
+    $$.val = &tree.RefreshMaterializedView{
+      Name: $5.normalizableTableNameFromUnresolvedName(),
+      Concurrently: true,
+    }
+  }
+| REFRESH error // SHOW HELP: REFRESH
+
 // %Help: RELEASE - complete a retryable block
 // %Category: Txn
 // %Text: RELEASE [SAVEPOINT] cockroach_restart
@@ -4410,7 +4717,8 @@ release_stmt:
 // %Text:
 // RESUME JOBS <selectclause>
 // RESUME JOB <jobid>
-// %SeeAlso: SHOW JOBS, CANCEL JOBS, PAUSE JOBS
+// RESUME SCHEDULE <name>
+// %SeeAlso: SHOW JOBS, CANCEL JOBS, PAUSE JOBS, CREATE SCHEDULE
 resume_stmt:
   RESUME JOB a_expr
   {
@@ -4425,6 +4733,10 @@ resume_stmt:
   {
     $$.val = &tree.ControlJobs{Jobs: $3.slct(), Command: tree.ResumeJob}
   }
+| RESUME SCHEDULE name
+  {
+    $$.val = &tree.ControlSchedule{Name: tree.Name($3), Command: tree.ResumeSchedule}
+  }
 | RESUME error // SHOW HELP: RESUME JOBS
 
 // %Help: SAVEPOINT - start a retryable block
@@ -5520,7 +5832,10 @@ table_ref:
   {
     $$.val = &tree.AliasedTableExpr{Expr: &tree.Subquery{Select: $1.selectStmt()}, Ordinality: $2.bool(), As: $3.aliasClause() }
   }
-| LATERAL select_with_parens opt_ordinality opt_alias_clause { return unimplementedWithIssue(sqllex, 24560) }
+| LATERAL select_with_parens opt_ordinality opt_alias_clause
+  {
+    $$.val = &tree.AliasedTableExpr{Expr: &tree.Subquery{Select: $2.selectStmt()}, Ordinality: $3.bool(), Lateral: true, As: $4.aliasClause() }
+  }
 | joined_table
   {
     $$.val = $1.tblExpr()
@@ -8074,6 +8389,7 @@ unreserved_keyword:
 | COMMITTED
 | COMPACT
 | CONFLICT
+| CONCURRENTLY
 | CONFIGURATION
 | CONFIGURATIONS
 | CONFIGURE
@@ -8116,6 +8432,7 @@ unreserved_keyword:
 | GRANTS
 | HIGH
 | HISTOGRAM
+| HISTORY
 | HOUR
 | IMPORT
 | INCREMENT
@@ -8141,12 +8458,14 @@ unreserved_keyword:
 | KV
 | LC_COLLATE
 | LC_CTYPE
+| LEASE
 | LESS
 | LEVEL
 | LIST
 | LOCAL
 | LOW
 | MATCH
+| MATERIALIZED
 | MINUTE
 | MONTH
 | NAMES
@@ -8181,8 +8500,10 @@ unreserved_keyword:
 | RANGE
 | RANGES
 | READ
+| RECURRING
 | RECURSIVE
 | REF
+| REFRESH
 | REGCLASS
 | REGPROC
 | REGPROCEDURE
@@ -8191,6 +8512,7 @@ unreserved_keyword:
 | RELEASE
 | RENAME
 | REPEATABLE
+| REPLICATION
 | RESET
 | RESTORE
 | RESTRICT
@@ -8206,6 +8528,7 @@ unreserved_keyword:
 | STATUS
 | SAVEPOINT
 | SCATTER
+| SCHEDULE
 | SCHEMA
 | SCHEMAS
 | SCRUB
@@ -8229,6 +8552,7 @@ unreserved_keyword:
 | START
 | STATISTICS
 | STDIN
+| STREAM
 | STORE
 | STORED
 | STORING
@@ -8262,6 +8586,7 @@ unreserved_keyword:
 | VALIDATE
 | VALUE
 | VARYING
+| VISIBLE
 | WITHIN
 | WITHOUT
 | WRITE
@@ -0,0 +1,98 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// makePartialIndexPredicate checks that the predicate of a partial index on
+// the given table is a boolean expression over its columns, without
+// subqueries or impure functions, and returns its serialized form.
+func makePartialIndexPredicate(
+	ctx context.Context,
+	desc *sqlbase.TableDescriptor,
+	pred tree.Expr,
+	tableName tree.TableName,
+	semaCtx *tree.SemaContext,
+	evalCtx *tree.EvalContext,
+) (string, error) {
+	// Replace column references with typed dummies to allow typechecking.
+	replacedExpr, _, err := replaceVars(*desc, pred)
+	if err != nil {
+		return "", err
+	}
+	if _, err := sqlbase.SanitizeVarFreeExpr(
+		replacedExpr, types.Bool, "index predicate", semaCtx, evalCtx, false, /* allowImpure */
+	); err != nil {
+		return "", err
+	}
+
+	sourceInfo := sqlbase.NewSourceInfoForSingleTable(
+		tableName, sqlbase.ResultColumnsFromColDescs(desc.Columns),
+	)
+	expr, err := dequalifyColumnRefs(ctx, sqlbase.MultiSourceInfo{sourceInfo}, pred)
+	if err != nil {
+		return "", err
+	}
+	return tree.Serialize(expr), nil
+}
+
+// partialIndexPredicateImplied returns whether the filter of the scan
+// implies the predicate of the given partial index, in which case all the
+// rows needed by the scan are in the index. The implication is only detected
+// when each conjunct of the predicate is also a conjunct of the filter, after
+// normalization.
+func (p *planner) partialIndexPredicateImplied(
+	ctx context.Context, s *scanNode, index *sqlbase.IndexDescriptor,
+) (bool, error) {
+	if s.filter == nil {
+		return false, nil
+	}
+	raw, err := parser.ParseExpr(index.Predicate)
+	if err != nil {
+		return false, err
+	}
+	// The predicate is analyzed with its own IndexedVarHelper so that the
+	// columns it references aren't marked as needed by the scan.
+	sourceInfo := sqlbase.NewSourceInfoForSingleTable(
+		tree.MakeUnqualifiedTableName(tree.Name(s.desc.Name)), s.resultColumns,
+	)
+	pred, err := p.analyzeExpr(
+		ctx, raw, sqlbase.MultiSourceInfo{sourceInfo}, tree.MakeIndexedVarHelper(s, len(s.cols)),
+		types.Bool, true /* requireType */, "index predicate",
+	)
+	if err != nil {
+		// The predicate references a column that the scan doesn't produce,
+		// which the filter can't constrain either.
+		return false, nil
+	}
+
+	filterConjuncts := make(map[string]struct{})
+	for _, e := range splitAndExpr(p.EvalContext(), s.filter, nil) {
+		filterConjuncts[tree.AsString(tree.StripParens(e))] = struct{}{}
+	}
+	for _, e := range splitAndExpr(p.EvalContext(), pred, nil) {
+		if _, ok := filterConjuncts[tree.AsString(tree.StripParens(e))]; !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
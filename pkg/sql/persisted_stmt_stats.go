@@ -0,0 +1,206 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/pkg/errors"
+)
+
+var stmtStatsFlushInterval = settings.RegisterValidatedDurationSetting(
+	"sql.metrics.statement_details.flush_interval",
+	"interval at which each node writes the statement statistics it collected to "+
+		"system.statement_statistics (0 disables)",
+	time.Minute,
+	func(v time.Duration) error {
+		if v != 0 && v < time.Second {
+			return errors.Errorf("cannot set sql.metrics.statement_details.flush_interval to less than 1s: %s", v)
+		}
+		return nil
+	},
+)
+
+var stmtStatsRetention = settings.RegisterNonNegativeDurationSetting(
+	"sql.metrics.statement_details.retention",
+	"duration for which the statement statistics written to system.statement_statistics "+
+		"are kept (0 keeps them forever)",
+	7*24*time.Hour,
+)
+
+// stmtStatsFlushBatchSize is the maximum number of statements whose
+// statistics are written by a single UPSERT.
+const stmtStatsFlushBatchSize = 100
+
+type collectedStmtStats struct {
+	app  string
+	key  stmtKey
+	data roachpb.StatementStatistics
+}
+
+// flushStmtStats writes the statement statistics collected on this node
+// since the last reset to system.statement_statistics. As the in-memory
+// statistics are cumulative, the rows written by the previous flush since the
+// last reset are overwritten. It also deletes the rows older than
+// sql.metrics.statement_details.retention.
+func (s *sqlStats) flushStmtStats(ctx context.Context, cfg *ExecutorConfig) error {
+	s.Lock()
+	aggregatedTs := s.lastReset
+	var stats []collectedStmtStats
+	for appName, a := range s.apps {
+		a.Lock()
+		for k, ss := range a.stmts {
+			ss.Lock()
+			stats = append(stats, collectedStmtStats{app: appName, key: k, data: ss.data})
+			ss.Unlock()
+		}
+		a.Unlock()
+	}
+	s.Unlock()
+
+	nodeID := int64(cfg.NodeID.Get())
+	for len(stats) > 0 {
+		batch := stats
+		if len(batch) > stmtStatsFlushBatchSize {
+			batch = batch[:stmtStatsFlushBatchSize]
+		}
+		stats = stats[len(batch):]
+
+		var buf bytes.Buffer
+		buf.WriteString(`UPSERT INTO system.statement_statistics ` +
+			`(aggregated_ts, fingerprint, app_name, failed, distsql, node_id, statistics) VALUES `)
+		args := make([]interface{}, 0, len(batch)*7)
+		for i := range batch {
+			data, err := protoutil.Marshal(&batch[i].data)
+			if err != nil {
+				return err
+			}
+			if len(args) > 0 {
+				buf.WriteString(", ")
+			}
+			n := len(args)
+			fmt.Fprintf(&buf, "($%d, $%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6, n+7)
+			args = append(args, aggregatedTs, batch[i].key.stmt, batch[i].app,
+				batch[i].key.failed, batch[i].key.distSQLUsed, nodeID, data)
+		}
+		if _, err := cfg.InternalExecutor.Exec(
+			ctx, "flush-stmt-stats", nil /* txn */, buf.String(), args...,
+		); err != nil {
+			return err
+		}
+	}
+
+	if retention := stmtStatsRetention.Get(&cfg.Settings.SV); retention > 0 {
+		if _, err := cfg.InternalExecutor.Exec(
+			ctx, "delete-old-stmt-stats", nil, /* txn */
+			`DELETE FROM system.statement_statistics WHERE aggregated_ts < $1`,
+			timeutil.Now().Add(-retention),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PeriodicallyFlushStmtStats runs a loop writing the statement statistics
+// collected on this node to system.statement_statistics.
+func (s *Server) PeriodicallyFlushStmtStats(ctx context.Context, stopper *stop.Stopper) {
+	stopper.RunWorker(ctx, func(ctx context.Context) {
+		var timer timeutil.Timer
+		defer timer.Stop()
+		for {
+			interval := stmtStatsFlushInterval.Get(&s.cfg.Settings.SV)
+			if interval == 0 {
+				// Check again later whether the flushes were enabled.
+				interval = time.Minute
+			}
+			timer.Reset(interval)
+			select {
+			case <-stopper.ShouldQuiesce():
+				return
+			case <-timer.C:
+				timer.Read = true
+			}
+			if stmtStatsFlushInterval.Get(&s.cfg.Settings.SV) == 0 {
+				continue
+			}
+			if err := s.sqlStats.flushStmtStats(ctx, s.cfg); err != nil {
+				log.Warningf(ctx, "failed to flush statement statistics: %v", err)
+			}
+		}
+	})
+}
+
+// persistedStmtStats returns the statement statistics written to
+// system.statement_statistics by all the nodes, combined across nodes and
+// reset periods, sorted by application and statement.
+func persistedStmtStats(
+	ctx context.Context, p *planner,
+) ([]roachpb.CollectedStatementStatistics, error) {
+	rows, _ /* cols */, err := p.ExtendedEvalContext().ExecCfg.InternalExecutor.Query(
+		ctx, "crdb-internal-statement-statistics", p.txn,
+		`SELECT app_name, fingerprint, failed, distsql, statistics FROM system.statement_statistics`)
+	if err != nil {
+		return nil, err
+	}
+	combined := make(map[roachpb.StatementStatisticsKey]*roachpb.StatementStatistics)
+	for _, r := range rows {
+		k := roachpb.StatementStatisticsKey{
+			App:     string(tree.MustBeDString(r[0])),
+			Query:   string(tree.MustBeDString(r[1])),
+			Failed:  bool(tree.MustBeDBool(r[2])),
+			DistSQL: bool(tree.MustBeDBool(r[3])),
+		}
+		var data roachpb.StatementStatistics
+		if err := protoutil.Unmarshal([]byte(tree.MustBeDBytes(r[4])), &data); err != nil {
+			return nil, err
+		}
+		if s, ok := combined[k]; ok {
+			s.Add(&data)
+		} else {
+			combined[k] = &data
+		}
+	}
+
+	ret := make([]roachpb.CollectedStatementStatistics, 0, len(combined))
+	for k, s := range combined {
+		ret = append(ret, roachpb.CollectedStatementStatistics{Key: k, Stats: *s})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		a, b := ret[i].Key, ret[j].Key
+		if a.App != b.App {
+			return a.App < b.App
+		}
+		if a.Query != b.Query {
+			return a.Query < b.Query
+		}
+		if a.Failed != b.Failed {
+			return !a.Failed
+		}
+		return !a.DistSQL && b.DistSQL
+	})
+	return ret, nil
+}
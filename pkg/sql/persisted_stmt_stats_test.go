@@ -0,0 +1,66 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/server"
+	"github.com/cockroachdb/cockroach/pkg/sql/tests"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestPersistedStmtStats(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	params, _ := tests.CreateTestServerParams()
+	s, db, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(ctx)
+
+	sqlServer := s.(*server.TestServer).PGServer().SQLServer
+	// Use a single connection, so that the application name is set for all
+	// the statements.
+	db.SetMaxOpenConns(1)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `CREATE DATABASE t; CREATE TABLE t.kv (k INT PRIMARY KEY, v INT)`)
+	sqlDB.Exec(t, `SET application_name = 'persisted_stats'`)
+
+	// The statistics survive the resets of the in-memory statistics, which
+	// flush them first.
+	for i := 0; i < 3; i++ {
+		sqlDB.Exec(t, fmt.Sprintf(`INSERT INTO t.kv VALUES (%d, %d)`, i, i))
+	}
+	sqlServer.ResetStatementStats(ctx)
+	for i := 3; i < 5; i++ {
+		sqlDB.Exec(t, fmt.Sprintf(`INSERT INTO t.kv VALUES (%d, %d)`, i, i))
+	}
+	sqlServer.ResetStatementStats(ctx)
+
+	sqlDB.CheckQueryResults(t, `
+SELECT key, count, rows_avg FROM crdb_internal.statement_statistics
+WHERE application_name = 'persisted_stats' AND key LIKE 'INSERT%'`,
+		[][]string{{"INSERT INTO t.kv VALUES (_, _)", "5", "1"}},
+	)
+	sqlDB.CheckQueryResults(t, `
+SELECT count(DISTINCT aggregated_ts) FROM system.statement_statistics
+WHERE app_name = 'persisted_stats' AND fingerprint LIKE 'INSERT%'`,
+		[][]string{{"2"}},
+	)
+}
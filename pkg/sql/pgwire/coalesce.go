@@ -0,0 +1,149 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package pgwire
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// coalesceInsertsEnabled controls whether runs of single-row INSERT statements
+// sent in a single simple-protocol query are executed as one statement.
+var coalesceInsertsEnabled = settings.RegisterBoolSetting(
+	"sql.pgwire.coalesce_inserts.enabled",
+	"if set, consecutive single-row INSERT statements into the same table sent together "+
+		"in a simple query are executed as a single multi-row INSERT",
+	false,
+)
+
+// coalescedStmt is a statement to be executed on behalf of count statements
+// from a simple-protocol query.
+type coalescedStmt struct {
+	stmt  tree.Statement
+	count int
+}
+
+// coalesceInserts groups the runs of consecutive single-row INSERT statements
+// into the same table and columns found in stmts into multi-row INSERT
+// statements. This allows naive clients that insert one row per statement to
+// write all of the rows in a single KV batch, in a single transaction.
+//
+// Like Postgres, which runs all the statements of a simple-protocol query in a
+// single implicit transaction, either all the rows of a run are inserted or
+// none are. Only statements whose values are literals are coalesced, so that
+// the outcome doesn't otherwise depend on whether the rows were written by
+// separate statements.
+func coalesceInserts(stmts []tree.Statement) []coalescedStmt {
+	res := make([]coalescedStmt, 0, len(stmts))
+	for i := 0; i < len(stmts); {
+		first, ok := coalescableInsert(stmts[i])
+		if !ok {
+			res = append(res, coalescedStmt{stmt: stmts[i], count: 1})
+			i++
+			continue
+		}
+		j := i + 1
+		for ; j < len(stmts); j++ {
+			next, ok := coalescableInsert(stmts[j])
+			if !ok || !sameInsertTarget(first, next) {
+				break
+			}
+		}
+		if j-i == 1 {
+			res = append(res, coalescedStmt{stmt: first, count: 1})
+			i++
+			continue
+		}
+		tuples := make([]*tree.Tuple, 0, j-i)
+		for _, stmt := range stmts[i:j] {
+			tuples = append(tuples, singleInsertRow(stmt.(*tree.Insert)))
+		}
+		combined := *first
+		combined.Rows = &tree.Select{Select: &tree.ValuesClause{Tuples: tuples}}
+		res = append(res, coalescedStmt{stmt: &combined, count: j - i})
+		i = j
+	}
+	return res
+}
+
+// coalescableInsert returns the statement as an INSERT if it inserts a single
+// row of literals, without any clause that could make the rows it writes
+// depend on other statements.
+func coalescableInsert(stmt tree.Statement) (*tree.Insert, bool) {
+	ins, ok := stmt.(*tree.Insert)
+	if !ok || ins.With != nil || ins.OnConflict != nil || tree.HasReturningClause(ins.Returning) {
+		return nil, false
+	}
+	row := singleInsertRow(ins)
+	if row == nil {
+		return nil, false
+	}
+	for _, e := range row.Exprs {
+		if !isLiteral(e) {
+			return nil, false
+		}
+	}
+	return ins, true
+}
+
+// singleInsertRow returns the row inserted by ins, or nil if ins does not
+// insert exactly one row from a VALUES clause.
+func singleInsertRow(ins *tree.Insert) *tree.Tuple {
+	if ins.DefaultValues() || ins.Rows.With != nil || ins.Rows.OrderBy != nil ||
+		ins.Rows.Limit != nil {
+		return nil
+	}
+	values, ok := ins.Rows.Select.(*tree.ValuesClause)
+	if !ok || len(values.Tuples) != 1 {
+		return nil
+	}
+	return values.Tuples[0]
+}
+
+// isLiteral returns whether e is a literal value. Casts are not considered to
+// be literals: the rows of a VALUES clause need to have matching types, which
+// separate statements don't.
+func isLiteral(e tree.Expr) bool {
+	switch t := e.(type) {
+	case tree.Constant, tree.DefaultVal:
+		return true
+	case *tree.DBool:
+		return true
+	case tree.Datum:
+		return t == tree.DNull
+	case *tree.ParenExpr:
+		return isLiteral(t.Expr)
+	case *tree.UnaryExpr:
+		_, ok := t.Expr.(*tree.NumVal)
+		return ok && t.Operator == tree.UnaryMinus
+	default:
+		return false
+	}
+}
+
+// sameInsertTarget returns whether two INSERT statements write to the same
+// table and columns.
+func sameInsertTarget(a, b *tree.Insert) bool {
+	if len(a.Columns) != len(b.Columns) ||
+		len(singleInsertRow(a).Exprs) != len(singleInsertRow(b).Exprs) {
+		return false
+	}
+	for i := range a.Columns {
+		if a.Columns[i] != b.Columns[i] {
+			return false
+		}
+	}
+	return tree.AsString(a.Table) == tree.AsString(b.Table)
+}
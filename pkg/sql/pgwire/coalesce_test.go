@@ -0,0 +1,104 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package pgwire
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestCoalesceInserts(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		sql      string
+		expected []string
+		counts   []int
+	}{
+		{
+			sql:      `INSERT INTO t VALUES (1, 'a')`,
+			expected: []string{`INSERT INTO t VALUES (1, 'a')`},
+			counts:   []int{1},
+		},
+		{
+			sql:      `INSERT INTO t VALUES (1, 'a'); INSERT INTO t VALUES (-2, NULL); INSERT INTO t VALUES (3, DEFAULT)`,
+			expected: []string{`INSERT INTO t VALUES (1, 'a'), (-2, NULL), (3, DEFAULT)`},
+			counts:   []int{3},
+		},
+		{
+			sql: `INSERT INTO t(a, b) VALUES (1, 'a'); INSERT INTO t(a, b) VALUES (2, 'b'); ` +
+				`INSERT INTO t(b, a) VALUES ('c', 3); INSERT INTO u(a, b) VALUES (4, 'd')`,
+			expected: []string{
+				`INSERT INTO t(a, b) VALUES (1, 'a'), (2, 'b')`,
+				`INSERT INTO t(b, a) VALUES ('c', 3)`,
+				`INSERT INTO u(a, b) VALUES (4, 'd')`,
+			},
+			counts: []int{2, 1, 1},
+		},
+		{
+			// Statements that aren't single-row INSERTs of literals break runs.
+			sql: `INSERT INTO t VALUES (1); CREATE INDEX ON t(a); INSERT INTO t VALUES (2); ` +
+				`INSERT INTO t VALUES (now()); INSERT INTO t VALUES (3); INSERT INTO t VALUES (4::INT); ` +
+				`INSERT INTO t VALUES (5), (6); INSERT INTO t VALUES (7) RETURNING a; ` +
+				`INSERT INTO t VALUES (8) ON CONFLICT DO NOTHING; UPSERT INTO t VALUES (9)`,
+			expected: []string{
+				`INSERT INTO t VALUES (1)`,
+				`CREATE INDEX ON t (a)`,
+				`INSERT INTO t VALUES (2)`,
+				`INSERT INTO t VALUES (now())`,
+				`INSERT INTO t VALUES (3)`,
+				`INSERT INTO t VALUES (4::INT)`,
+				`INSERT INTO t VALUES (5), (6)`,
+				`INSERT INTO t VALUES (7) RETURNING a`,
+				`INSERT INTO t VALUES (8) ON CONFLICT DO NOTHING`,
+				`UPSERT INTO t VALUES (9)`,
+			},
+			counts: []int{1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
+		},
+		{
+			sql: `BEGIN; INSERT INTO t VALUES (1); INSERT INTO t VALUES (2); COMMIT`,
+			expected: []string{
+				`BEGIN TRANSACTION`,
+				`INSERT INTO t VALUES (1), (2)`,
+				`COMMIT TRANSACTION`,
+			},
+			counts: []int{1, 2, 1},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.sql, func(t *testing.T) {
+			stmts, err := parser.Parse(tc.sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var actual []string
+			var counts []int
+			for _, cs := range coalesceInserts(stmts) {
+				actual = append(actual, tree.AsString(cs.stmt))
+				counts = append(counts, cs.count)
+			}
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("expected:\n%v\ngot:\n%v", tc.expected, actual)
+			}
+			if !reflect.DeepEqual(tc.counts, counts) {
+				t.Errorf("expected counts %v, got %v", tc.counts, counts)
+			}
+		})
+	}
+}
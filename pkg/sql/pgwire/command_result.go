@@ -67,6 +67,10 @@ type commandResult struct {
 	// If set, an error will be sent to the client if more rows are produced than
 	// this limit.
 	limit int
+	// coalescedCount, if greater than one, is the number of statements sent by
+	// the client that this result stands for. A CommandComplete message is
+	// written for each of them.
+	coalescedCount int
 
 	stmtType     tree.StatementType
 	descOpt      sql.RowDescOpt
@@ -137,6 +141,13 @@ func (r *commandResult) Close(t sql.TransactionStatusIndicator) {
 	// Send a completion message, specific to the type of result.
 	switch r.typ {
 	case commandComplete:
+		if r.coalescedCount > 1 {
+			for _, n := range sql.CoalescedRowsAffected(r.rowsAffected, r.coalescedCount) {
+				tag := cookTag(r.cmdCompleteTag, r.conn.writerState.tagBuf[:0], r.stmtType, n)
+				r.conn.bufferCommandComplete(tag)
+			}
+			break
+		}
 		tag := cookTag(
 			r.cmdCompleteTag, r.conn.writerState.tagBuf[:0], r.stmtType, r.rowsAffected,
 		)
@@ -269,6 +280,11 @@ func (r *commandResult) SetLimit(n int) {
 	r.limit = n
 }
 
+// SetCoalescedCount is part of the CommandResult interface.
+func (r *commandResult) SetCoalescedCount(n int) {
+	r.coalescedCount = n
+}
+
 // ResetStmtType is part of the CommandResult interface.
 func (r *commandResult) ResetStmtType(stmt tree.Statement) {
 	r.stmtType = stmt.StatementType()
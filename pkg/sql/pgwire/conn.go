@@ -23,6 +23,7 @@ import (
 	"io"
 	"net"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -418,7 +419,18 @@ func (c *conn) handleSimpleQuery(
 			})
 	}
 
-	for _, stmt := range stmts {
+	var toExec []coalescedStmt
+	if coalesceInsertsEnabled.Get(&c.execCfg.Settings.SV) {
+		toExec = coalesceInserts(stmts)
+	} else {
+		toExec = make([]coalescedStmt, len(stmts))
+		for i, stmt := range stmts {
+			toExec[i] = coalescedStmt{stmt: stmt, count: 1}
+		}
+	}
+
+	for _, cs := range toExec {
+		stmt := cs.stmt
 		// The CopyFrom statement is special. We need to detect it so we can hand
 		// control of the connection, through the stmtBuf, to a copyMachine, and
 		// block this network routine until control is passed back.
@@ -447,10 +459,11 @@ func (c *conn) handleSimpleQuery(
 		if err := c.stmtBuf.Push(
 			ctx,
 			sql.ExecStmt{
-				Stmt:         stmt,
-				TimeReceived: timeReceived,
-				ParseStart:   startParse,
-				ParseEnd:     endParse,
+				Stmt:           stmt,
+				TimeReceived:   timeReceived,
+				ParseStart:     startParse,
+				ParseEnd:       endParse,
+				CoalescedCount: cs.count,
 			}); err != nil {
 			return err
 		}
@@ -789,7 +802,7 @@ func convertToErrWithPGCode(err error) error {
 		// nodes.
 		return sqlbase.NewStatementCompletionUnknownError(tErr)
 	default:
-		return err
+		return sqlbase.ConvertKVError(err)
 	}
 }
 
@@ -1317,12 +1330,30 @@ var statusReportParams = map[string]string{
 	"server_version": sql.PgServerVersion,
 	// The current CockroachDB version string.
 	"crdb_version": build.GetInfo().Short(),
+	// The features that drivers and ORMs commonly need to tell apart, so
+	// they don't have to infer them from the version.
+	"crdb_features": strings.Join(serverFeatures, ","),
 	// If this parameter is not present, some drivers (including Python's psycopg2)
 	// will add redundant backslash escapes for compatibility with non-standard
 	// backslash handling in older versions of postgres.
 	"standard_conforming_strings": "on",
 }
 
+// serverFeatures lists the features reported in the crdb_features parameter.
+// Features are only ever added to this list; a driver can assume that a
+// feature that isn't listed isn't supported.
+var serverFeatures = []string{
+	// COPY ... FROM STDIN.
+	"copy_from_stdin",
+	// SAVEPOINT cockroach_restart and the client-side retry protocol. Other
+	// savepoints aren't supported.
+	"restart_savepoint",
+	// RETURNING NOTHING.
+	"returning_nothing",
+	// UPSERT and INSERT ... ON CONFLICT.
+	"upsert",
+}
+
 // readTimeoutConn overloads net.Conn.Read by periodically calling
 // checkExitConds() and aborting the read if an error is returned.
 type readTimeoutConn struct {
@@ -31,6 +31,9 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/build"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirebase"
@@ -323,7 +326,9 @@ func waitForClientConn(ln net.Listener) (*conn, error) {
 	}
 
 	metrics := makeServerMetrics(sql.MemoryMetrics{} /* sqlMemMetrics */, metric.TestSampleInterval)
-	pgwireConn := newConn(conn, sql.SessionArgs{}, &metrics, &sql.ExecutorConfig{})
+	pgwireConn := newConn(conn, sql.SessionArgs{}, &metrics, &sql.ExecutorConfig{
+		Settings: cluster.MakeTestingClusterSettings(),
+	})
 	return pgwireConn, nil
 }
 
@@ -691,6 +696,47 @@ func TestConnClose(t *testing.T) {
 	})
 }
 
+// Test that the server reports its version and its features when a
+// connection is established.
+func TestConnParameterStatus(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{Insecure: true})
+	defer s.Stopper().Stop(context.TODO())
+
+	host, ports, err := net.SplitHostPort(s.ServingAddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, err := strconv.Atoi(ports)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := pgx.Connect(pgx.ConnConfig{
+		Host:     host,
+		Port:     uint16(port),
+		User:     "root",
+		Database: "system",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	if v := conn.RuntimeParams["crdb_version"]; v != build.GetInfo().Short() {
+		t.Errorf("expected crdb_version %q, got %q", build.GetInfo().Short(), v)
+	}
+	features := strings.Split(conn.RuntimeParams["crdb_features"], ",")
+	for _, f := range []string{"copy_from_stdin", "restart_savepoint"} {
+		found := false
+		for _, g := range features {
+			found = found || g == f
+		}
+		if !found {
+			t.Errorf("expected feature %s to be reported, got %s", f, features)
+		}
+	}
+}
+
 // TestMaliciousInputs verifies that known malicious inputs sent to
 // a v3Conn don't crash the server.
 func TestMaliciousInputs(t *testing.T) {
@@ -833,3 +879,46 @@ func TestReadTimeoutConnExits(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+// Test that the KV errors surfaced to the clients carry their stable error
+// codes.
+func TestConvertToErrWithPGCode(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		err  error
+		code string
+	}{
+		{roachpb.NewRangeNotFoundError(1), sqlbase.CodeRangeUnavailable},
+		{roachpb.NewSendError("boom"), sqlbase.CodeRangeUnavailable},
+		{&roachpb.NotLeaseHolderError{}, sqlbase.CodeLeaseUnavailable},
+		{&roachpb.LeaseRejectedError{}, sqlbase.CodeLeaseUnavailable},
+		{
+			errors.Wrap(roachpb.NewEvalMemoryBudgetExceededError(10, 20), "scan failed"),
+			sqlbase.CodeKVMemoryBudgetExceeded,
+		},
+		{&roachpb.WriteIntentError{}, sqlbase.CodeWriteIntentConflict},
+		{&roachpb.AmbiguousResultError{}, pgerror.CodeStatementCompletionUnknownError},
+		{
+			pgerror.NewError(pgerror.CodeDataExceptionError, "already coded"),
+			pgerror.CodeDataExceptionError,
+		},
+		{errors.New("boom"), ""},
+	}
+	for _, tc := range testCases {
+		err := convertToErrWithPGCode(tc.err)
+		if err.Error() != tc.err.Error() {
+			t.Errorf("%v: expected the message to be kept, got %q", tc.err, err)
+		}
+		pgErr, ok := pgerror.GetPGCause(err)
+		if tc.code == "" {
+			if ok {
+				t.Errorf("%v: expected no code, got %s", tc.err, pgErr.Code)
+			}
+			continue
+		}
+		if !ok || pgErr.Code != tc.code {
+			t.Errorf("%v: expected code %s, got %v", tc.err, tc.code, err)
+		}
+	}
+}
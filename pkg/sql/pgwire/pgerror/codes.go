@@ -136,6 +136,7 @@ const (
 	CodeSchemaAndDataStatementMixingNotSupportedError        = "25007"
 	CodeNoActiveSQLTransactionError                          = "25P01"
 	CodeInFailedSQLTransactionError                          = "25P02"
+	CodeIdleInTransactionSessionTimeoutError                 = "25P03"
 	// Class 26 - Invalid SQL Statement Name
 	CodeInvalidSQLStatementNameError = "26000"
 	// Class 27 - Triggered Data Change Violation
@@ -250,6 +251,7 @@ const (
 	CodeCrashShutdownError        = "57P02"
 	CodeCannotConnectNowError     = "57P03"
 	CodeDatabaseDroppedError      = "57P04"
+	CodeIdleSessionTimeoutError   = "57P05"
 	// Class 58 - System Error (errors external to PostgreSQL itself)
 	CodeSystemError        = "58000"
 	CodeIoError            = "58030"
@@ -161,12 +161,14 @@ type planNodeFastPath interface {
 var _ planNode = &alterIndexNode{}
 var _ planNode = &alterTableNode{}
 var _ planNode = &alterSequenceNode{}
+var _ planNode = &applyJoinNode{}
 var _ planNode = &createDatabaseNode{}
 var _ planNode = &createIndexNode{}
 var _ planNode = &createTableNode{}
 var _ planNode = &createViewNode{}
 var _ planNode = &createSequenceNode{}
 var _ planNode = &createStatsNode{}
+var _ planNode = &cteScanNode{}
 var _ planNode = &delayedNode{}
 var _ planNode = &deleteNode{}
 var _ planNode = &distinctNode{}
@@ -188,8 +190,10 @@ var _ planNode = &indexJoinNode{}
 var _ planNode = &insertNode{}
 var _ planNode = &joinNode{}
 var _ planNode = &limitNode{}
+var _ planNode = &max1RowNode{}
 var _ planNode = &ordinalityNode{}
 var _ planNode = &projectSetNode{}
+var _ planNode = &refreshMaterializedViewNode{}
 var _ planNode = &relocateNode{}
 var _ planNode = &renderNode{}
 var _ planNode = &rowCountNode{}
@@ -217,6 +221,7 @@ var _ planNodeFastPath = &rowCountNode{}
 var _ planNodeFastPath = &serializeNode{}
 var _ planNodeFastPath = &setZoneConfigNode{}
 var _ planNodeFastPath = &controlJobsNode{}
+var _ planNodeFastPath = &controlScheduleNode{}
 
 // planNodeRequireSpool serves as marker for nodes whose parent must
 // ensure that the node is fully run to completion (and the results
@@ -267,6 +272,10 @@ type planTop struct {
 	// to the planNodes that represent their source.
 	cteNameEnvironment cteNameEnvironment
 
+	// numBufferedCTEs counts the common table expressions that were planned
+	// as buffered subqueries. See with.go.
+	numBufferedCTEs int
+
 	// hasStar collects whether any star expansion has occurred during
 	// logical plan construction. This is used by CREATE VIEW until
 	// #10028 is addressed.
@@ -358,7 +367,7 @@ func (p *planner) makeOptimizerPlan(ctx context.Context, stmt Statement) error {
 	}
 
 	var catalog optCatalog
-	catalog.init(p.execCfg.TableStatsCache, p)
+	catalog.init(p.execCfg.TableStatsCache, p, p.SessionData().OptimizerUseNotVisibleIndexes)
 
 	o := xform.NewOptimizer(p.EvalContext())
 	bld := optbuilder.New(ctx, &p.semaCtx, p.EvalContext(), &catalog, o.Factory(), stmt.AST)
@@ -667,6 +676,8 @@ func (p *planner) newPlan(
 		return p.CancelSessions(ctx, n)
 	case *tree.ControlJobs:
 		return p.ControlJobs(ctx, n)
+	case *tree.ControlSchedule:
+		return p.ControlSchedule(ctx, n)
 	case *tree.Scrub:
 		return p.Scrub(ctx, n)
 	case *tree.CreateDatabase:
@@ -711,6 +722,8 @@ func (p *planner) newPlan(
 		return p.Insert(ctx, n, desiredTypes)
 	case *tree.ParenSelect:
 		return p.newPlan(ctx, n.Select, desiredTypes)
+	case *tree.RefreshMaterializedView:
+		return p.RefreshMaterializedView(ctx, n)
 	case *tree.Relocate:
 		return p.Relocate(ctx, n)
 	case *tree.RenameColumn:
@@ -788,6 +801,10 @@ func (p *planner) newPlan(
 		return p.ShowUsers(ctx, n)
 	case *tree.ShowZoneConfig:
 		return p.ShowZoneConfig(ctx, n)
+	case *tree.ShowClusterConfiguration:
+		return p.ShowClusterConfiguration(ctx, n)
+	case *tree.ShowRangeLeaseHistory:
+		return p.ShowRangeLeaseHistory(ctx, n)
 	case *tree.ShowRanges:
 		return p.ShowRanges(ctx, n)
 	case *tree.ShowFingerprints:
@@ -846,6 +863,8 @@ func (p *planner) doPrepare(ctx context.Context, stmt tree.Statement) (planNode,
 		return p.CancelSessions(ctx, n)
 	case *tree.ControlJobs:
 		return p.ControlJobs(ctx, n)
+	case *tree.ControlSchedule:
+		return p.ControlSchedule(ctx, n)
 	case *tree.CreateUser:
 		return p.CreateUser(ctx, n)
 	case *tree.CreateTable:
@@ -49,6 +49,8 @@ func getPlanColumns(plan planNode, mut bool) sqlbase.ResultColumns {
 	switch n := plan.(type) {
 
 	// Nodes that define their own schema.
+	case *applyJoinNode:
+		return n.columns
 	case *delayedNode:
 		return n.columns
 	case *groupNode:
@@ -69,6 +71,8 @@ func getPlanColumns(plan planNode, mut bool) sqlbase.ResultColumns {
 		return n.columns
 	case *valuesNode:
 		return n.columns
+	case *cteScanNode:
+		return n.columns
 	case *explainPlanNode:
 		return n.run.results.columns
 	case *windowNode:
@@ -94,6 +98,12 @@ func getPlanColumns(plan planNode, mut bool) sqlbase.ResultColumns {
 	case *scrubNode:
 		return n.getColumns(mut, scrubColumns)
 	case *explainDistSQLNode:
+		if n.debug {
+			return n.getColumns(mut, sqlbase.ExplainDebugColumns)
+		}
+		if n.planTree {
+			return n.getColumns(mut, sqlbase.ExplainPlanColumns)
+		}
 		return n.getColumns(mut, sqlbase.ExplainDistSQLColumns)
 	case *relocateNode:
 		return n.getColumns(mut, relocateNodeColumns)
@@ -120,6 +130,8 @@ func getPlanColumns(plan planNode, mut bool) sqlbase.ResultColumns {
 		return getPlanColumns(n.source.plan, mut)
 	case *limitNode:
 		return getPlanColumns(n.plan, mut)
+	case *max1RowNode:
+		return getPlanColumns(n.plan, mut)
 	case *spoolNode:
 		return getPlanColumns(n.source, mut)
 	case *serializeNode:
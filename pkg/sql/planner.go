@@ -57,6 +57,10 @@ type extendedEvalContext struct {
 	// contribute.
 	MemMetrics *MemoryMetrics
 
+	// DiskMonitor tracks the temporary disk storage used by the session. It
+	// can be nil, in which case the flows use the DistSQL server's monitor.
+	DiskMonitor *mon.BytesMonitor
+
 	// Tables points to the Session's table collection (& cache).
 	Tables *TableCollection
 
@@ -577,10 +581,13 @@ type sqlStatsCollector interface {
 
 	// RecordStatement record stats for one statement.
 	RecordStatement(
+		ctx context.Context,
 		stmt Statement,
+		plan *planTop,
 		distSQLUsed bool,
 		automaticRetryCount int,
 		numRows int,
+		kvStats client.KVStats,
 		err error,
 		parseLat, planLat, runLat, svcLat, ovhLat float64,
 	)
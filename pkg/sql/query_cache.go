@@ -0,0 +1,250 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/cache"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+var queryCacheEnabled = settings.RegisterBoolSetting(
+	"sql.query_cache.enabled",
+	"serve the results of repeated identical read-only statements from a "+
+		"per-node cache; cached results can be stale by up to sql.query_cache.staleness",
+	false,
+)
+
+var queryCacheStaleness = settings.RegisterNonNegativeDurationSetting(
+	"sql.query_cache.staleness",
+	"maximum age of the cached results served to read-only statements",
+	5*time.Second,
+)
+
+var queryCacheMaxResultSize = settings.RegisterByteSizeSetting(
+	"sql.query_cache.max_result_size",
+	"maximum size of a result kept in the query cache",
+	64<<10, /* 64 KiB */
+)
+
+var queryCacheMaxEntries = settings.RegisterIntSetting(
+	"sql.query_cache.max_entries",
+	"maximum number of results kept in the query cache of each node",
+	1000,
+)
+
+// queryCacheEntry is the cached result of a statement.
+type queryCacheEntry struct {
+	rows      []tree.Datums
+	createdAt time.Time
+	// tableVersions holds the versions of the descriptors of the tables read
+	// by the statement when its result was computed. The entry is stale once
+	// any of them changes.
+	tableVersions map[sqlbase.ID]sqlbase.DescriptorVersion
+}
+
+// queryCache holds the results of recently run read-only statements. Entries
+// are keyed by the text of the statement, the values of its placeholders, its
+// AS OF SYSTEM TIME timestamp and the session settings that can change its
+// result. Since the statement is planned before its key is looked up, the
+// privileges of the user are always checked, and a change to the descriptor
+// of a table it reads is noticed.
+type queryCache struct {
+	st *cluster.Settings
+	mu struct {
+		syncutil.Mutex
+		cache *cache.UnorderedCache
+	}
+}
+
+func makeQueryCache(st *cluster.Settings) *queryCache {
+	c := &queryCache{st: st}
+	c.mu.cache = cache.NewUnorderedCache(cache.Config{
+		Policy: cache.CacheLRU,
+		ShouldEvict: func(size int, _, _ interface{}) bool {
+			return int64(size) > queryCacheMaxEntries.Get(&st.SV)
+		},
+	})
+	return c
+}
+
+// get returns the cached result for the given key, if there is one that's
+// fresh enough and was computed against the given table versions.
+func (c *queryCache) get(
+	key string, tableVersions map[sqlbase.ID]sqlbase.DescriptorVersion,
+) ([]tree.Datums, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := c.mu.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	e := v.(*queryCacheEntry)
+	if timeutil.Since(e.createdAt) > queryCacheStaleness.Get(&c.st.SV) ||
+		!sameTableVersions(e.tableVersions, tableVersions) {
+		c.mu.cache.Del(key)
+		return nil, false
+	}
+	return e.rows, true
+}
+
+// add caches the given result.
+func (c *queryCache) add(key string, e *queryCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mu.cache.Add(key, e)
+}
+
+func sameTableVersions(a, b map[sqlbase.ID]sqlbase.DescriptorVersion) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for id, v := range a {
+		if w, ok := b[id]; !ok || v != w {
+			return false
+		}
+	}
+	return true
+}
+
+// key returns the key of the result of the current plan of the given planner
+// in the query cache, along with the versions of the tables it reads. It
+// returns false if the result of the statement can't be cached: only SELECT
+// statements run in implicit transactions and that don't read virtual tables
+// or call impure functions are.
+func (c *queryCache) key(
+	ctx context.Context, p *planner, stmt Statement, implicitTxn bool,
+) (string, map[sqlbase.ID]sqlbase.DescriptorVersion, bool) {
+	if !queryCacheEnabled.Get(&c.st.SV) || !implicitTxn {
+		return "", nil, false
+	}
+	if _, ok := stmt.AST.(*tree.Select); !ok {
+		return "", nil, false
+	}
+
+	tableVersions := make(map[sqlbase.ID]sqlbase.DescriptorVersion)
+	cacheable := true
+	v := impureFuncVisitor{}
+	observer := planObserver{
+		enterNode: func(_ context.Context, _ string, plan planNode) (bool, error) {
+			switch n := plan.(type) {
+			case *scanNode:
+				if n.desc.IsVirtualTable() {
+					cacheable = false
+				}
+				tableVersions[n.desc.ID] = n.desc.Version
+			case *delayedNode, *insertNode, *upsertNode, *updateNode, *deleteNode:
+				// Virtual tables and SHOW statements are planned as delayed nodes.
+				cacheable = false
+			}
+			return cacheable, nil
+		},
+		expr: func(_, _ string, _ int, expr tree.Expr) {
+			if expr != nil {
+				tree.WalkExprConst(&v, expr)
+			}
+		},
+	}
+	_ = walkPlan(ctx, p.curPlan.plan, observer)
+	for i := range p.curPlan.subqueryPlans {
+		if cacheable && p.curPlan.subqueryPlans[i].plan != nil {
+			_ = walkPlan(ctx, p.curPlan.subqueryPlans[i].plan, observer)
+		}
+	}
+	if !cacheable || v.impure {
+		return "", nil, false
+	}
+
+	sd := p.SessionData()
+	var buf strings.Builder
+	buf.WriteString(stmt.String())
+	for _, s := range []string{
+		sd.User, sd.Database, sd.SearchPath.String(), sd.Location.String(), sd.BytesEncodeFormat.String(),
+	} {
+		buf.WriteByte(0)
+		buf.WriteString(s)
+	}
+	if p.asOfSystemTime {
+		buf.WriteByte(0)
+		buf.WriteString(p.txn.OrigTimestamp().String())
+	}
+	placeholders := p.semaCtx.Placeholders.Values
+	names := make([]string, 0, len(placeholders))
+	for name := range placeholders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		buf.WriteByte(0)
+		buf.WriteString(name)
+		buf.WriteByte('=')
+		buf.WriteString(tree.AsStringWithFlags(placeholders[name], tree.FmtParsable))
+	}
+	return buf.String(), tableVersions, true
+}
+
+// impureFuncVisitor records whether an expression calls an impure function,
+// like random() or now().
+type impureFuncVisitor struct {
+	impure bool
+}
+
+var _ tree.Visitor = &impureFuncVisitor{}
+
+func (v *impureFuncVisitor) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
+	if f, ok := expr.(*tree.FuncExpr); ok && f.IsImpure() {
+		v.impure = true
+	}
+	return !v.impure, expr
+}
+
+func (v *impureFuncVisitor) VisitPost(expr tree.Expr) tree.Expr { return expr }
+
+// queryCacheResultWriter is a RestrictedCommandResult that forwards the rows
+// of a statement to the client while collecting them for the query cache.
+type queryCacheResultWriter struct {
+	RestrictedCommandResult
+
+	maxSize int64
+	size    int64
+	// rows is reset to nil once the result exceeds maxSize.
+	rows     []tree.Datums
+	overflow bool
+}
+
+// AddRow is part of the RestrictedCommandResult interface.
+func (w *queryCacheResultWriter) AddRow(ctx context.Context, row tree.Datums) error {
+	if !w.overflow {
+		for _, d := range row {
+			w.size += int64(d.Size())
+		}
+		if w.size > w.maxSize {
+			w.overflow = true
+			w.rows = nil
+		} else {
+			w.rows = append(w.rows, append(tree.Datums(nil), row...))
+		}
+	}
+	return w.RestrictedCommandResult.AddRow(ctx, row)
+}
@@ -0,0 +1,257 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
+	"github.com/cockroachdb/cockroach/pkg/sql/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	jobs.AddResumeHook(refreshMaterializedViewResumeHook)
+}
+
+// refreshMaterializedViewNode represents a REFRESH MATERIALIZED VIEW
+// statement.
+type refreshMaterializedViewNode struct {
+	n    *tree.RefreshMaterializedView
+	desc *sqlbase.TableDescriptor
+}
+
+// RefreshMaterializedView recomputes the rows stored for a materialized
+// view.
+// Privileges: CREATE on view.
+//   Notes: postgres requires ownership of the view.
+func (p *planner) RefreshMaterializedView(
+	ctx context.Context, n *tree.RefreshMaterializedView,
+) (planNode, error) {
+	tn, err := n.Name.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	var desc *TableDescriptor
+	// DDL statements avoid the cache to avoid leases, and can view non-public descriptors.
+	p.runWithOptions(resolveFlags{skipCache: true}, func() {
+		desc, err = ResolveExistingObject(ctx, p, tn, true /*required*/, requireViewDesc)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !desc.MaterializedView() {
+		return nil, sqlbase.NewWrongObjectTypeError(tn, "materialized view")
+	}
+
+	if err := p.CheckPrivilege(ctx, desc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+
+	return &refreshMaterializedViewNode{n: n, desc: desc}, nil
+}
+
+func (n *refreshMaterializedViewNode) startExec(params runParams) error {
+	// The refresh runs as a job in its own transaction, so it can't be
+	// part of the session's transaction.
+	if !params.extendedEvalCtx.TxnImplicit {
+		return errors.Errorf("%s cannot be used inside a transaction", n.n.StatementTag())
+	}
+
+	_, errCh, err := params.extendedEvalCtx.ExecCfg.JobRegistry.StartJob(
+		params.ctx, nil /* resultsCh */, jobs.Record{
+			Description:   tree.AsStringWithFlags(n.n, tree.FmtAlwaysQualifyTableNames),
+			Username:      params.SessionData().User,
+			DescriptorIDs: sqlbase.IDs{n.desc.ID},
+			Details: jobspb.RefreshMaterializedViewDetails{
+				TableID:      n.desc.ID,
+				Concurrently: n.n.Concurrently,
+			},
+			Progress: jobspb.RefreshMaterializedViewProgress{},
+		})
+	if err != nil {
+		return err
+	}
+	select {
+	case <-params.ctx.Done():
+		return params.ctx.Err()
+	case err := <-errCh:
+		return err
+	}
+}
+
+func (*refreshMaterializedViewNode) Next(runParams) (bool, error) { return false, nil }
+func (*refreshMaterializedViewNode) Values() tree.Datums          { return tree.Datums{} }
+func (*refreshMaterializedViewNode) Close(context.Context)        {}
+
+// refreshMaterializedView replaces the rows stored for the materialized view
+// desc with the current results of its query. It's used both to populate a
+// new materialized view and by REFRESH MATERIALIZED VIEW jobs.
+//
+// All the writes are made in txn. Without concurrently, every stored row is
+// deleted and the query results are inserted, so readers block on the whole
+// view until txn commits. With concurrently, the stored rows are compared
+// with the query results, and only the rows that were added or removed are
+// written, so readers only block on those.
+//
+// The query results are buffered in memory, as are the stored rows with
+// concurrently.
+func refreshMaterializedView(
+	ctx context.Context,
+	ie *InternalExecutor,
+	nodeID roachpb.NodeID,
+	txn *client.Txn,
+	desc *sqlbase.TableDescriptor,
+	concurrently bool,
+) error {
+	rows, _ /* cols */, err := ie.Query(ctx, "refresh-materialized-view", txn, desc.ViewQuery)
+	if err != nil {
+		return err
+	}
+
+	// The hidden rowid column used as the primary key comes after the
+	// view's columns.
+	if len(desc.PrimaryIndex.ColumnIDs) != 1 {
+		return errors.Errorf("materialized view %q has an unexpected primary key", desc.Name)
+	}
+	pkColIdx := len(desc.Columns) - 1
+	if desc.Columns[pkColIdx].ID != desc.PrimaryIndex.ColumnIDs[0] {
+		return errors.Errorf("materialized view %q has an unexpected primary key", desc.Name)
+	}
+
+	var alloc sqlbase.DatumAlloc
+	ri, err := sqlbase.MakeRowInserter(txn, desc, nil /* fkTables */, desc.Columns, sqlbase.SkipFKs, &alloc)
+	if err != nil {
+		return err
+	}
+	b := txn.NewBatch()
+	insert := func(row tree.Datums) error {
+		values := make(tree.Datums, len(desc.Columns))
+		copy(values, row)
+		values[pkColIdx] = tree.NewDInt(builtins.GenerateUniqueInt(nodeID))
+		return ri.InsertRow(ctx, b, values, false /* overwrite */, sqlbase.SkipFKs, false /* traceKV */)
+	}
+
+	if !concurrently {
+		span := desc.TableSpan()
+		if err := txn.DelRange(ctx, span.Key, span.EndKey); err != nil {
+			return err
+		}
+		for _, row := range rows {
+			if err := insert(row); err != nil {
+				return err
+			}
+		}
+		return txn.Run(ctx, b)
+	}
+
+	// Match up the stored rows with the query results. Rows are compared by
+	// their formatted values; a stored row that formats differently from
+	// every result row is replaced, even if it compares equal.
+	query := fmt.Sprintf("SELECT *, %s FROM [%d AS mv]",
+		tree.NameString(desc.Columns[pkColIdx].Name), desc.ID)
+	stored, _ /* cols */, err := ie.Query(ctx, "refresh-materialized-view", txn, query)
+	if err != nil {
+		return err
+	}
+	key := func(row tree.Datums) string {
+		return tree.AsStringWithFlags(&row, tree.FmtParsable)
+	}
+	stale := make(map[string][]tree.Datums, len(stored))
+	for _, row := range stored {
+		k := key(row[:pkColIdx])
+		stale[k] = append(stale[k], row)
+	}
+	for _, row := range rows {
+		k := key(row)
+		if old := stale[k]; len(old) > 0 {
+			// An identical row is already stored; leave it alone.
+			stale[k] = old[1:]
+			continue
+		}
+		if err := insert(row); err != nil {
+			return err
+		}
+	}
+
+	rd, err := sqlbase.MakeRowDeleter(
+		txn, desc, nil /* fkTables */, desc.Columns, sqlbase.SkipFKs, nil /* evalCtx */, &alloc,
+	)
+	if err != nil {
+		return err
+	}
+	for _, old := range stale {
+		for _, row := range old {
+			if err := rd.DeleteRow(ctx, b, row, sqlbase.SkipFKs, false /* traceKV */); err != nil {
+				return err
+			}
+		}
+	}
+	return txn.Run(ctx, b)
+}
+
+type refreshMaterializedViewResumer struct{}
+
+func (r *refreshMaterializedViewResumer) Resume(
+	ctx context.Context, job *jobs.Job, planHookState interface{}, _ chan<- tree.Datums,
+) error {
+	execCfg := planHookState.(PlanHookState).ExecCfg()
+	details := job.Details().(jobspb.RefreshMaterializedViewDetails)
+	// The whole refresh is done in one transaction, so a job that is resumed
+	// after a failure starts over.
+	return execCfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		desc, err := sqlbase.GetTableDescFromID(ctx, txn, details.TableID)
+		if err != nil {
+			return err
+		}
+		if desc.Dropped() {
+			return errors.Errorf("materialized view %q is being dropped", desc.Name)
+		}
+		return refreshMaterializedView(
+			ctx, execCfg.InternalExecutor, execCfg.NodeID.Get(), txn, desc, details.Concurrently,
+		)
+	})
+}
+
+func (r *refreshMaterializedViewResumer) OnFailOrCancel(
+	context.Context, *client.Txn, *jobs.Job,
+) error {
+	return nil
+}
+
+func (r *refreshMaterializedViewResumer) OnSuccess(context.Context, *client.Txn, *jobs.Job) error {
+	return nil
+}
+
+func (r *refreshMaterializedViewResumer) OnTerminal(
+	context.Context, *jobs.Job, jobs.Status, chan<- tree.Datums,
+) {
+}
+
+func refreshMaterializedViewResumeHook(typ jobspb.Type, _ *cluster.Settings) jobs.Resumer {
+	if typ != jobspb.TypeRefreshMaterializedView {
+		return nil
+	}
+	return &refreshMaterializedViewResumer{}
+}
@@ -136,6 +136,18 @@ func (p *planner) RenameColumn(ctx context.Context, n *tree.RenameColumn) (planN
 		}
 	}
 
+	// Rename the column in the predicates of partial indexes.
+	if err := tableDesc.ForeachNonDropIndex(func(idx *sqlbase.IndexDescriptor) error {
+		if !idx.IsPartial() {
+			return nil
+		}
+		var err error
+		idx.Predicate, err = renameIn(idx.Predicate)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
 	// Rename the column in the indexes.
 	tableDesc.RenameColumnDescriptor(col, string(n.NewName))
 
@@ -447,6 +447,48 @@ func TestCancelIfExists(t *testing.T) {
 	}
 }
 
+func TestIdleInTransactionSessionTimeout(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.TODO()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `CREATE DATABASE t; CREATE TABLE t.kv (k INT PRIMARY KEY, v INT)`)
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.ExecContext(
+		ctx, "SET idle_in_transaction_session_timeout = '100ms'",
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	// Leave a transaction open with an intent on a row.
+	if _, err := conn.ExecContext(ctx, "BEGIN; INSERT INTO t.kv VALUES (1, 1)"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Once the session is terminated, its intent is released and the row can be
+	// written by another transaction.
+	testutils.SucceedsSoon(t, func() error {
+		var count int
+		sqlDB.QueryRow(t, "SELECT count(*) FROM [SHOW LOCAL SESSIONS]").Scan(&count)
+		if count != 1 {
+			return fmt.Errorf("expected 1 session but found %d", count)
+		}
+		return nil
+	})
+	sqlDB.Exec(t, "UPSERT INTO t.kv VALUES (1, 2)")
+
+	if _, err := conn.ExecContext(ctx, "SELECT 1"); err != gosqldriver.ErrBadConn {
+		t.Fatalf("session not terminated; actual error: %v", err)
+	}
+}
+
 func isClientsideQueryCanceledErr(err error) bool {
 	pqErr, ok := err.(*pq.Error)
 	if !ok {
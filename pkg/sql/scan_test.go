@@ -123,8 +123,8 @@ func testScanBatchQuery(t *testing.T, db *gosql.DB, numSpans, numAs, numBs int,
 	}
 }
 
-// TestScanBatches tests the scan-in-batches code by artificially setting the batch size to
-// particular values and performing queries.
+// TestScanBatches tests the scan-in-batches code by artificially setting the batch size
+// and the batch target bytes to particular values and performing queries.
 func TestScanBatches(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -182,6 +182,18 @@ func TestScanBatches(t *testing.T) {
 		}
 	}
 
+	// Batches are also limited by their size in bytes. The batch size is now
+	// large enough not to interfere.
+	restoreTargetBytes := sqlbase.SetKVBatchTargetBytes(1)
+	defer restoreTargetBytes()
+	for _, targetBytes := range []int64{1, 10, 50, 100, 1000} {
+		sqlbase.SetKVBatchTargetBytes(targetBytes)
+		for _, numSpans := range numSpanValues {
+			testScanBatchQuery(t, db, numSpans, numAs, numBs, false)
+			testScanBatchQuery(t, db, numSpans, numAs, numBs, true)
+		}
+	}
+
 	if _, err := db.Exec(`DROP TABLE test.scan`); err != nil {
 		t.Fatal(err)
 	}
@@ -16,6 +16,7 @@ package sql
 
 import (
 	"context"
+	"sort"
 
 	"github.com/pkg/errors"
 
@@ -161,13 +162,49 @@ var scatterNodeColumns = sqlbase.ResultColumns{
 		Name: "pretty",
 		Typ:  types.String,
 	},
+	{
+		Name: "range_id",
+		Typ:  types.Int,
+	},
+	{
+		Name: "replicas",
+		// The INTs in the array are Store IDs.
+		Typ: types.TArray{Typ: types.Int},
+	},
+	{
+		Name: "lease_holder",
+		// The store ID for the lease holder.
+		Typ: types.Int,
+	},
 }
 
 func (n *scatterNode) Values() tree.Datums {
 	r := n.run.ranges[n.run.rangeIdx]
+
+	replicas := make([]int, len(r.Replicas))
+	for i, rd := range r.Replicas {
+		replicas[i] = int(rd.StoreID)
+	}
+	sort.Ints(replicas)
+	replicaArr := tree.NewDArray(types.Int)
+	replicaArr.Array = make(tree.Datums, len(replicas))
+	for i, storeID := range replicas {
+		replicaArr.Array[i] = tree.NewDInt(tree.DInt(storeID))
+	}
+
+	// The lease holder is unknown if the range had no valid lease when the
+	// scatter finished.
+	leaseHolder := tree.DNull
+	if r.LeaseHolder.StoreID != 0 {
+		leaseHolder = tree.NewDInt(tree.DInt(r.LeaseHolder.StoreID))
+	}
+
 	return tree.Datums{
 		tree.NewDBytes(tree.DBytes(r.Span.Key)),
 		tree.NewDString(keys.PrettyPrint(nil /* valDirs */, r.Span.Key)),
+		tree.NewDInt(tree.DInt(r.RangeID)),
+		replicaArr,
+		leaseHolder,
 	}
 }
 
@@ -16,6 +16,7 @@ package sql_test
 
 import (
 	"context"
+	gosql "database/sql"
 	"fmt"
 	"reflect"
 	"testing"
@@ -123,9 +124,22 @@ func TestScatterResponse(t *testing.T) {
 	for ; rows.Next(); i++ {
 		var actualKey []byte
 		var pretty string
-		if err := rows.Scan(&actualKey, &pretty); err != nil {
+		var rangeID int
+		var replicas string
+		var leaseHolder gosql.NullInt64
+		if err := rows.Scan(&actualKey, &pretty, &rangeID, &replicas, &leaseHolder); err != nil {
 			t.Fatal(err)
 		}
+		if rangeID < 1 {
+			t.Errorf("%d: invalid range ID %d", i, rangeID)
+		}
+		// The test server has a single store.
+		if e, a := "{1}", replicas; e != a {
+			t.Errorf("%d: expected replicas %s, but got %s", i, e, a)
+		}
+		if !leaseHolder.Valid || leaseHolder.Int64 != 1 {
+			t.Errorf("%d: expected lease holder 1, but got %v", i, leaseHolder)
+		}
 		var expectedKey roachpb.Key
 		if i == 0 {
 			expectedKey = keys.MakeTablePrefix(uint32(tableDesc.ID))
@@ -0,0 +1,220 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/cron"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/pkg/errors"
+)
+
+var schedulerEnabled = settings.RegisterBoolSetting(
+	"jobs.scheduler.enabled",
+	"run the statements of the schedules in system.scheduled_jobs when they're due",
+	true,
+)
+
+var schedulerPollInterval = settings.RegisterValidatedDurationSetting(
+	"jobs.scheduler.poll_interval",
+	"interval at which each node looks for due schedules in system.scheduled_jobs",
+	time.Minute,
+	func(v time.Duration) error {
+		if v <= 0 {
+			return errors.Errorf("cannot set jobs.scheduler.poll_interval to a non-positive duration: %s", v)
+		}
+		return nil
+	},
+)
+
+// ScheduleHookFn is called on the statement of a schedule every time it's
+// run, and can return the statement to run instead, like a BACKUP to a
+// destination specific to this run. It returns nil if it doesn't handle the
+// statement.
+type ScheduleHookFn func(stmt tree.Statement, runAt time.Time) (tree.Statement, error)
+
+var scheduleHooks []ScheduleHookFn
+
+// AddScheduleHook adds a schedule hook.
+func AddScheduleHook(fn ScheduleHookFn) {
+	scheduleHooks = append(scheduleHooks, fn)
+}
+
+// CreateSchedule adds a schedule on which the given statement is run as the
+// current user in the current database. The statement is run every time the
+// cron expression recurrence fires. It's up to the caller, usually the plan
+// hook of a CREATE SCHEDULE statement, to validate the statement.
+func CreateSchedule(
+	ctx context.Context, p PlanHookState, name, recurrence string, stmt tree.Statement,
+) error {
+	sched, err := cron.Parse(recurrence)
+	if err != nil {
+		return err
+	}
+	next := sched.Next(timeutil.Now().UTC())
+	if next.IsZero() {
+		return errors.Errorf("cron expression %q never fires", recurrence)
+	}
+
+	n, err := p.ExecCfg().InternalExecutor.Exec(
+		ctx, "create-schedule", p.ExtendedEvalContext().Txn,
+		`INSERT INTO system.scheduled_jobs `+
+			`(name, owner, database_name, recurrence, command, next_run) `+
+			`VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (name) DO NOTHING`,
+		name, p.User(), p.SessionData().Database, recurrence, stmt.String(), next,
+	)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return pgerror.NewErrorf(pgerror.CodeDuplicateObjectError,
+			"schedule %q already exists", name)
+	}
+	return nil
+}
+
+// PeriodicallyRunSchedules runs a loop looking for the schedules in
+// system.scheduled_jobs that are due and running their statements. Every node
+// runs this loop; a schedule is claimed by the node that first advances its
+// next_run, so each run happens on a single node.
+func (s *Server) PeriodicallyRunSchedules(ctx context.Context, stopper *stop.Stopper) {
+	stopper.RunWorker(ctx, func(ctx context.Context) {
+		var timer timeutil.Timer
+		defer timer.Stop()
+		for {
+			timer.Reset(schedulerPollInterval.Get(&s.cfg.Settings.SV))
+			select {
+			case <-stopper.ShouldQuiesce():
+				return
+			case <-timer.C:
+				timer.Read = true
+			}
+			if !schedulerEnabled.Get(&s.cfg.Settings.SV) {
+				continue
+			}
+			if err := runDueSchedules(ctx, s.cfg, stopper); err != nil {
+				log.Warningf(ctx, "failed to run schedules: %v", err)
+			}
+		}
+	})
+}
+
+// runDueSchedules claims the schedules that are due and runs their statements
+// asynchronously.
+func runDueSchedules(ctx context.Context, cfg *ExecutorConfig, stopper *stop.Stopper) error {
+	now := timeutil.Now()
+	rows, _ /* cols */, err := cfg.InternalExecutor.Query(
+		ctx, "find-due-schedules", nil, /* txn */
+		`SELECT name, owner, database_name, recurrence, command, next_run `+
+			`FROM system.scheduled_jobs WHERE NOT paused AND next_run <= $1`,
+		now,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range rows {
+		name := string(tree.MustBeDString(r[0]))
+		owner := string(tree.MustBeDString(r[1]))
+		database := string(tree.MustBeDString(r[2]))
+		recurrence := string(tree.MustBeDString(r[3]))
+		command := string(tree.MustBeDString(r[4]))
+
+		sched, err := cron.Parse(recurrence)
+		if err != nil {
+			log.Warningf(ctx, "schedule %q has an invalid recurrence: %v", name, err)
+			continue
+		}
+		// The schedule is paused once its cron expression stops firing, which
+		// can happen since Next only looks a few years ahead.
+		next, paused := sched.Next(now.UTC()), false
+		if next.IsZero() {
+			next, paused = r[5].(*tree.DTimestamp).Time, true
+		}
+		claimed, err := cfg.InternalExecutor.Exec(
+			ctx, "claim-schedule", nil, /* txn */
+			`UPDATE system.scheduled_jobs SET next_run = $3, last_run = $4, paused = $5 `+
+				`WHERE name = $1 AND next_run = $2 AND NOT paused`,
+			name, r[5], next, now, paused,
+		)
+		if err != nil {
+			return err
+		}
+		if claimed == 0 {
+			// Another node got to it first, or it was paused or dropped since.
+			continue
+		}
+
+		if err := stopper.RunAsyncTask(ctx, "run-schedule", func(ctx context.Context) {
+			runSchedule(ctx, cfg, name, owner, database, command, now)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runSchedule runs the statement of a schedule and records its error, if any,
+// in system.scheduled_jobs.
+func runSchedule(
+	ctx context.Context,
+	cfg *ExecutorConfig,
+	name, owner, database, command string,
+	runAt time.Time,
+) {
+	runErr := func() error {
+		stmt, err := parser.ParseOne(command)
+		if err != nil {
+			return err
+		}
+		for _, hook := range scheduleHooks {
+			rewritten, err := hook(stmt, runAt)
+			if err != nil {
+				return err
+			}
+			if rewritten != nil {
+				stmt = rewritten
+				break
+			}
+		}
+		_, err = cfg.InternalExecutor.ExecWithSessionArgs(
+			ctx, "run-schedule", nil, /* txn */
+			SessionArgs{User: owner, Database: database},
+			stmt.String(),
+		)
+		return err
+	}()
+
+	var lastError interface{}
+	if runErr != nil {
+		log.Warningf(ctx, "schedule %q failed: %v", name, runErr)
+		lastError = runErr.Error()
+	}
+	if _, err := cfg.InternalExecutor.Exec(
+		ctx, "record-schedule-run", nil, /* txn */
+		`UPDATE system.scheduled_jobs SET last_error = $2 WHERE name = $1`,
+		name, lastError,
+	); err != nil {
+		log.Warningf(ctx, "failed to record the run of schedule %q: %v", name, err)
+	}
+}
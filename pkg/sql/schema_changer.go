@@ -776,6 +776,7 @@ func (sc *SchemaChanger) done(ctx context.Context) (*sqlbase.Descriptor, error)
 	isRollback := false
 	return sc.leaseMgr.Publish(ctx, sc.tableID, func(desc *sqlbase.TableDescriptor) error {
 		i := 0
+		var newPrimaryIndex *sqlbase.IndexDescriptor
 		for _, mutation := range desc.Mutations {
 			if mutation.MutationID != sc.mutationID {
 				// Mutations are applied in a FIFO order. Only apply the first set of
@@ -783,7 +784,15 @@ func (sc *SchemaChanger) done(ctx context.Context) (*sqlbase.Descriptor, error)
 				break
 			}
 			isRollback = mutation.Rollback
-			desc.MakeMutationComplete(mutation)
+			if idx := mutation.GetIndex(); idx != nil &&
+				mutation.Direction == sqlbase.DescriptorMutation_ADD &&
+				idx.EncodingType == sqlbase.PrimaryIndexEncoding {
+				// The index built by a primary key change replaces the primary
+				// index below, once the other new indexes are public.
+				newPrimaryIndex = idx
+			} else {
+				desc.MakeMutationComplete(mutation)
+			}
 			i++
 		}
 		if i == 0 {
@@ -801,6 +810,42 @@ func (sc *SchemaChanger) done(ctx context.Context) (*sqlbase.Descriptor, error)
 				break
 			}
 		}
+
+		if newPrimaryIndex != nil {
+			// Swap in the new primary index and drop the replaced indexes in a
+			// new schema change.
+			if err := desc.MakePrimaryKeyChangeComplete(*newPrimaryIndex); err != nil {
+				return err
+			}
+			mutationID, err := desc.FinalizeMutation()
+			if err != nil {
+				return err
+			}
+			span := desc.PrimaryIndexSpan()
+			var spanList []jobspb.ResumeSpanList
+			for _, m := range desc.Mutations {
+				if m.MutationID == mutationID {
+					spanList = append(spanList,
+						jobspb.ResumeSpanList{
+							ResumeSpans: []roachpb.Span{span},
+						},
+					)
+				}
+			}
+			oldJobPayload := sc.job.Payload()
+			job := sc.jobRegistry.NewJob(jobs.Record{
+				Description:   "CLEAN UP " + oldJobPayload.Description,
+				Username:      oldJobPayload.Username,
+				DescriptorIDs: oldJobPayload.DescriptorIDs,
+				Details:       jobspb.SchemaChangeDetails{ResumeSpanList: spanList},
+				Progress:      jobspb.SchemaChangeProgress{},
+			})
+			if err := job.Created(ctx); err != nil {
+				return err
+			}
+			desc.MutationJobs = append(desc.MutationJobs, sqlbase.TableDescriptor_MutationJob{
+				MutationID: mutationID, JobID: *job.ID()})
+		}
 		return nil
 	}, func(txn *client.Txn) error {
 		if err := sc.job.WithTxn(txn).Succeeded(ctx, jobs.NoopFn); err != nil {
@@ -142,6 +142,16 @@ var generators = map[string]builtinDefinition{
 		),
 	),
 
+	"crdb_internal.txn_contention_advice": makeBuiltin(genProps(contentionAdviceGeneratorType.Labels),
+		makeGeneratorOverload(
+			tree.ArgTypes{},
+			contentionAdviceGeneratorType,
+			makeContentionAdviceGenerator,
+			"Produces a virtual table containing advice for reducing the transaction "+
+				"retries recently caused by the statements of the current application on this node.",
+		),
+	),
+
 	"generate_subscripts": makeBuiltin(genProps(subscriptsValueGeneratorLabels),
 		// See https://www.postgresql.org/docs/current/static/functions-srf.html#FUNCTIONS-SRF-SUBSCRIPTS
 		makeGeneratorOverload(
@@ -239,6 +249,61 @@ func (k *keywordsValueGenerator) Values() tree.Datums {
 	return tree.Datums{tree.NewDString(kw), tree.NewDString(cat), tree.NewDString(desc)}
 }
 
+// contentionAdviceGenerator supports the execution of
+// crdb_internal.txn_contention_advice().
+type contentionAdviceGenerator struct {
+	evalCtx *tree.EvalContext
+	advice  []tree.ContentionAdvice
+	cur     int
+}
+
+var contentionAdviceGeneratorType = types.TTuple{
+	Types:  []types.T{types.String, types.String, types.Int, types.String},
+	Labels: []string{"statement", "cause", "retries", "advice"},
+}
+
+func makeContentionAdviceGenerator(
+	ctx *tree.EvalContext, _ tree.Datums,
+) (tree.ValueGenerator, error) {
+	return &contentionAdviceGenerator{evalCtx: ctx}, nil
+}
+
+// ResolvedType implements the tree.ValueGenerator interface.
+func (*contentionAdviceGenerator) ResolvedType() types.TTuple {
+	return contentionAdviceGeneratorType
+}
+
+// Close implements the tree.ValueGenerator interface.
+func (*contentionAdviceGenerator) Close() {}
+
+// Start implements the tree.ValueGenerator interface.
+func (g *contentionAdviceGenerator) Start() error {
+	advice, err := g.evalCtx.Planner.TxnContentionAdvice(g.evalCtx.Ctx())
+	if err != nil {
+		return err
+	}
+	g.advice = advice
+	g.cur = -1
+	return nil
+}
+
+// Next implements the tree.ValueGenerator interface.
+func (g *contentionAdviceGenerator) Next() (bool, error) {
+	g.cur++
+	return g.cur < len(g.advice), nil
+}
+
+// Values implements the tree.ValueGenerator interface.
+func (g *contentionAdviceGenerator) Values() tree.Datums {
+	a := g.advice[g.cur]
+	return tree.Datums{
+		tree.NewDString(a.Statement),
+		tree.NewDString(a.Cause),
+		tree.NewDInt(tree.DInt(a.Retries)),
+		tree.NewDString(a.Advice),
+	}
+}
+
 var keywordCategoryDescriptions = map[string]string{
 	"R": "reserved",
 	"C": "unreserved (cannot be function or type name)",
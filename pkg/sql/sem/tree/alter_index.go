@@ -55,8 +55,10 @@ type AlterIndexCmd interface {
 }
 
 func (*AlterIndexPartitionBy) alterIndexCmd() {}
+func (*AlterIndexVisible) alterIndexCmd()     {}
 
 var _ AlterIndexCmd = &AlterIndexPartitionBy{}
+var _ AlterIndexCmd = &AlterIndexVisible{}
 
 // AlterIndexPartitionBy represents an ALTER INDEX PARTITION BY
 // command.
@@ -68,3 +70,17 @@ type AlterIndexPartitionBy struct {
 func (node *AlterIndexPartitionBy) Format(ctx *FmtCtx) {
 	ctx.FormatNode(node.PartitionBy)
 }
+
+// AlterIndexVisible represents an ALTER INDEX [NOT] VISIBLE command.
+type AlterIndexVisible struct {
+	NotVisible bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterIndexVisible) Format(ctx *FmtCtx) {
+	if node.NotVisible {
+		ctx.WriteString(" NOT VISIBLE")
+	} else {
+		ctx.WriteString(" VISIBLE")
+	}
+}
@@ -59,6 +59,7 @@ type AlterTableCmd interface {
 func (*AlterTableAddColumn) alterTableCmd()          {}
 func (*AlterTableAddConstraint) alterTableCmd()      {}
 func (*AlterTableAlterColumnType) alterTableCmd()    {}
+func (*AlterTableAlterPrimaryKey) alterTableCmd()    {}
 func (*AlterTableDropColumn) alterTableCmd()         {}
 func (*AlterTableDropConstraint) alterTableCmd()     {}
 func (*AlterTableDropNotNull) alterTableCmd()        {}
@@ -72,6 +73,7 @@ func (*AlterTableInjectStats) alterTableCmd()        {}
 var _ AlterTableCmd = &AlterTableAddColumn{}
 var _ AlterTableCmd = &AlterTableAddConstraint{}
 var _ AlterTableCmd = &AlterTableAlterColumnType{}
+var _ AlterTableCmd = &AlterTableAlterPrimaryKey{}
 var _ AlterTableCmd = &AlterTableDropColumn{}
 var _ AlterTableCmd = &AlterTableDropConstraint{}
 var _ AlterTableCmd = &AlterTableDropNotNull{}
@@ -174,6 +176,19 @@ func (node *AlterTableAlterColumnType) GetColumn() Name {
 	return node.Column
 }
 
+// AlterTableAlterPrimaryKey represents an ALTER TABLE ALTER PRIMARY KEY
+// command.
+type AlterTableAlterPrimaryKey struct {
+	Columns IndexElemList
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTableAlterPrimaryKey) Format(ctx *FmtCtx) {
+	ctx.WriteString(" ALTER PRIMARY KEY USING COLUMNS (")
+	ctx.FormatNode(&node.Columns)
+	ctx.WriteString(")")
+}
+
 // AlterTableDropColumn represents a DROP COLUMN command.
 type AlterTableDropColumn struct {
 	ColumnKeyword bool
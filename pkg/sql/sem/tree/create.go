@@ -112,6 +112,9 @@ type CreateIndex struct {
 	Storing     NameList
 	Interleave  *InterleaveDef
 	PartitionBy *PartitionBy
+	// Predicate, if non-nil, makes the index a partial index that only
+	// contains the rows for which it is true.
+	Predicate Expr
 }
 
 // Format implements the NodeFormatter interface.
@@ -147,6 +150,10 @@ func (node *CreateIndex) Format(ctx *FmtCtx) {
 	if node.PartitionBy != nil {
 		ctx.FormatNode(node.PartitionBy)
 	}
+	if node.Predicate != nil {
+		ctx.WriteString(" WHERE ")
+		ctx.FormatNode(node.Predicate)
+	}
 }
 
 // TableDef represents a column, index or constraint definition within a CREATE
@@ -502,6 +509,9 @@ type IndexTableDef struct {
 	Interleave  *InterleaveDef
 	Inverted    bool
 	PartitionBy *PartitionBy
+	// Predicate, if non-nil, makes the index a partial index that only
+	// contains the rows for which it is true.
+	Predicate Expr
 }
 
 // SetName implements the TableDef interface.
@@ -533,6 +543,10 @@ func (node *IndexTableDef) Format(ctx *FmtCtx) {
 	if node.PartitionBy != nil {
 		ctx.FormatNode(node.PartitionBy)
 	}
+	if node.Predicate != nil {
+		ctx.WriteString(" WHERE ")
+		ctx.FormatNode(node.Predicate)
+	}
 }
 
 // ConstraintTableDef represents a constraint definition within a CREATE TABLE
@@ -901,6 +915,14 @@ func (node *SequenceOptions) Format(ctx *FmtCtx) {
 		switch option.Name {
 		case SeqOptCycle, SeqOptNoCycle:
 			ctx.WriteString(option.Name)
+		case SeqOptOwnedBy:
+			ctx.WriteString(option.Name)
+			ctx.WriteByte(' ')
+			if option.ColumnItemVal == nil {
+				ctx.WriteString("NONE")
+			} else {
+				ctx.FormatNode(option.ColumnItemVal)
+			}
 		case SeqOptCache:
 			ctx.WriteString(option.Name)
 			ctx.WriteByte(' ')
@@ -941,6 +963,10 @@ type SequenceOption struct {
 	IntVal *int64
 
 	OptionalWord bool
+
+	// ColumnItemVal is the owning column of an OWNED BY option; nil
+	// represents OWNED BY NONE.
+	ColumnItemVal *ColumnItem
 }
 
 // Names of options on CREATE SEQUENCE.
@@ -957,7 +983,6 @@ const (
 
 	// Avoid unused warning for constants.
 	_ = SeqOptAs
-	_ = SeqOptOwnedBy
 )
 
 // CreateUser represents a CREATE USER statement.
@@ -1026,16 +1051,22 @@ func (node *CreateRole) Format(ctx *FmtCtx) {
 	ctx.FormatNode(node.Name)
 }
 
-// CreateView represents a CREATE VIEW statement.
+// CreateView represents a CREATE VIEW or a CREATE MATERIALIZED VIEW
+// statement.
 type CreateView struct {
-	Name        NormalizableTableName
-	ColumnNames NameList
-	AsSource    *Select
+	Name         NormalizableTableName
+	ColumnNames  NameList
+	AsSource     *Select
+	Materialized bool
 }
 
 // Format implements the NodeFormatter interface.
 func (node *CreateView) Format(ctx *FmtCtx) {
-	ctx.WriteString("CREATE VIEW ")
+	ctx.WriteString("CREATE ")
+	if node.Materialized {
+		ctx.WriteString("MATERIALIZED ")
+	}
+	ctx.WriteString("VIEW ")
 	ctx.FormatNode(&node.Name)
 
 	if len(node.ColumnNames) > 0 {
@@ -1049,6 +1080,21 @@ func (node *CreateView) Format(ctx *FmtCtx) {
 	ctx.FormatNode(node.AsSource)
 }
 
+// RefreshMaterializedView represents a REFRESH MATERIALIZED VIEW statement.
+type RefreshMaterializedView struct {
+	Name         NormalizableTableName
+	Concurrently bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *RefreshMaterializedView) Format(ctx *FmtCtx) {
+	ctx.WriteString("REFRESH MATERIALIZED VIEW ")
+	if node.Concurrently {
+		ctx.WriteString("CONCURRENTLY ")
+	}
+	ctx.FormatNode(&node.Name)
+}
+
 // CreateStats represents a CREATE STATISTICS statement.
 type CreateStats struct {
 	Name        Name
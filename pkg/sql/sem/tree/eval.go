@@ -1619,6 +1619,48 @@ func init() {
 			NullableArgs: true,
 		})
 	}
+
+	// Array containment comparisons.
+	for _, t := range types.AnyNonArray {
+		CmpOps[Contains] = append(CmpOps[Contains], CmpOp{
+			LeftType:  types.TArray{Typ: t},
+			RightType: types.TArray{Typ: t},
+			fn: func(ctx *EvalContext, left Datum, right Datum) (Datum, error) {
+				return MakeDBool(arrayContains(ctx, MustBeDArray(left), MustBeDArray(right))), nil
+			},
+		})
+
+		CmpOps[ContainedBy] = append(CmpOps[ContainedBy], CmpOp{
+			LeftType:  types.TArray{Typ: t},
+			RightType: types.TArray{Typ: t},
+			fn: func(ctx *EvalContext, left Datum, right Datum) (Datum, error) {
+				return MakeDBool(arrayContains(ctx, MustBeDArray(right), MustBeDArray(left))), nil
+			},
+		})
+	}
+}
+
+// arrayContains returns whether each element of needles is equal to an
+// element of haystack, regardless of their positions and multiplicities. As
+// NULL elements aren't equal to anything, an array with NULL elements is never
+// contained in another one.
+func arrayContains(ctx *EvalContext, haystack, needles *DArray) DBool {
+	for _, n := range needles.Array {
+		if n == DNull {
+			return false
+		}
+		found := false
+		for _, h := range haystack.Array {
+			if h != DNull && h.Compare(ctx, n) == 0 {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
 }
 
 func init() {
@@ -2346,6 +2388,23 @@ type EvalPlanner interface {
 
 	// EvalSubquery returns the Datum for the given subquery node.
 	EvalSubquery(expr *Subquery) (Datum, error)
+
+	// TxnContentionAdvice returns advice for reducing the retryable errors
+	// recently encountered by the statements of the session's application.
+	TxnContentionAdvice(ctx context.Context) ([]ContentionAdvice, error)
+}
+
+// ContentionAdvice is a suggestion for reducing the retryable errors with a
+// given cause encountered by a statement.
+type ContentionAdvice struct {
+	// Statement is the statement, with its constants hidden.
+	Statement string
+	// Cause is the cause of the retryable errors.
+	Cause string
+	// Retries is the number of retryable errors with this cause.
+	Retries int64
+	// Advice is the suggested change.
+	Advice string
 }
 
 // SessionBoundInternalExecutor is a subset of sqlutil.InternalExecutor used by
@@ -54,10 +54,12 @@ func (node *Explain) Format(ctx *FmtCtx) {
 				optsBuffer.WriteString(upperCaseOpt)
 			}
 		}
-		// Write the options.
-		ctx.WriteByte('(')
-		ctx.Write(optsBuffer.Bytes())
-		ctx.WriteString(") ")
+		// Write the options, if there are any besides ANALYZE.
+		if optsBuffer.Len() > 0 {
+			ctx.WriteByte('(')
+			ctx.Write(optsBuffer.Bytes())
+			ctx.WriteString(") ")
+		}
 	}
 	ctx.FormatNode(node.Statement)
 }
@@ -69,8 +71,8 @@ type ExplainOptions struct {
 	Flags util.FastIntSet
 }
 
-// ExplainMode indicates the mode of the explain. Currently there are two modes:
-// PLAN (the default) and DISTSQL.
+// ExplainMode indicates the mode of the explain: PLAN (the default), DISTSQL,
+// OPT or DEBUG.
 type ExplainMode uint8
 
 const (
@@ -85,12 +87,18 @@ const (
 	// ExplainOpt shows the optimized relational expression (from the cost-based
 	// optimizer).
 	ExplainOpt
+
+	// ExplainDebug runs the statement like EXPLAIN ANALYZE (DISTSQL) and
+	// returns a bundle of debugging information, including CPU profiles of the
+	// nodes that participated in the execution. Only valid with ANALYZE.
+	ExplainDebug
 )
 
 var explainModeStrings = map[string]ExplainMode{
 	"plan":    ExplainPlan,
 	"distsql": ExplainDistSQL,
 	"opt":     ExplainOpt,
+	"debug":   ExplainDebug,
 }
 
 // Explain flags.
@@ -102,6 +110,7 @@ const (
 	ExplainFlagNoNormalize
 	ExplainFlagNoOptimize
 	ExplainFlagAnalyze
+	ExplainFlagRecommend
 )
 
 var explainFlagStrings = map[string]int{
@@ -112,6 +121,7 @@ var explainFlagStrings = map[string]int{
 	"nonormalize": ExplainFlagNoNormalize,
 	"nooptimize":  ExplainFlagNoOptimize,
 	"analyze":     ExplainFlagAnalyze,
+	"recommend":   ExplainFlagRecommend,
 }
 
 // ParseOptions parses the options for an EXPLAIN statement.
@@ -464,6 +464,12 @@ func (node *Subquery) doc(p PrettyCfg) pretty.Doc {
 
 func (node *AliasedTableExpr) doc(p PrettyCfg) pretty.Doc {
 	d := p.Doc(node.Expr)
+	if node.Lateral {
+		d = pretty.Concat(
+			pretty.Text("LATERAL "),
+			d,
+		)
+	}
 	if node.Hints != nil {
 		d = pretty.Concat(
 			d,
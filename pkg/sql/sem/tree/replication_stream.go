@@ -0,0 +1,41 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tree
+
+// CreateReplicationStream represents a CREATE REPLICATION STREAM statement.
+// Without a SourceURI, it streams changes to the targets to the client. With
+// one, it starts a job ingesting the stream from the source cluster into the
+// targets.
+type CreateReplicationStream struct {
+	Targets   TargetList
+	SourceURI Expr
+	Options   KVOptions
+}
+
+var _ Statement = &CreateReplicationStream{}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateReplicationStream) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE REPLICATION STREAM FOR ")
+	ctx.FormatNode(&node.Targets)
+	if node.SourceURI != nil {
+		ctx.WriteString(" FROM ")
+		ctx.FormatNode(node.SourceURI)
+	}
+	if node.Options != nil {
+		ctx.WriteString(" WITH ")
+		ctx.FormatNode(&node.Options)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tree
+
+// CreateSchedule represents a CREATE SCHEDULE statement, which runs a BACKUP
+// every time the cron expression in Recurrence fires.
+type CreateSchedule struct {
+	Name       Name
+	Backup     *Backup
+	Recurrence Expr
+}
+
+var _ Statement = &CreateSchedule{}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateSchedule) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE SCHEDULE ")
+	ctx.FormatNode(&node.Name)
+	ctx.WriteString(" FOR ")
+	ctx.FormatNode(node.Backup)
+	ctx.WriteString(" RECURRING ")
+	ctx.FormatNode(node.Recurrence)
+}
+
+// ControlSchedule represents a PAUSE/RESUME/DROP SCHEDULE statement.
+type ControlSchedule struct {
+	Name    Name
+	Command ScheduleCommand
+}
+
+// ScheduleCommand determines which type of action to effect on the selected
+// schedule.
+type ScheduleCommand int
+
+// ScheduleCommand values
+const (
+	PauseSchedule ScheduleCommand = iota
+	ResumeSchedule
+	DropSchedule
+)
+
+// ScheduleCommandToStatement translates a schedule command integer to a
+// statement prefix.
+var ScheduleCommandToStatement = map[ScheduleCommand]string{
+	PauseSchedule:  "PAUSE",
+	ResumeSchedule: "RESUME",
+	DropSchedule:   "DROP",
+}
+
+var _ Statement = &ControlSchedule{}
+
+// Format implements the NodeFormatter interface.
+func (node *ControlSchedule) Format(ctx *FmtCtx) {
+	ctx.WriteString(ScheduleCommandToStatement[node.Command])
+	ctx.WriteString(" SCHEDULE ")
+	ctx.FormatNode(&node.Name)
+}
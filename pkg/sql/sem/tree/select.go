@@ -281,11 +281,15 @@ type AliasedTableExpr struct {
 	Expr       TableExpr
 	Hints      *IndexHints
 	Ordinality bool
+	Lateral    bool
 	As         AliasClause
 }
 
 // Format implements the NodeFormatter interface.
 func (node *AliasedTableExpr) Format(ctx *FmtCtx) {
+	if node.Lateral {
+		ctx.WriteString("LATERAL ")
+	}
 	ctx.FormatNode(node.Expr)
 	if node.Hints != nil {
 		ctx.FormatNode(node.Hints)
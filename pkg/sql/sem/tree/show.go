@@ -49,6 +49,14 @@ func (node *ShowClusterSetting) Format(ctx *FmtCtx) {
 	ctx.WriteString(node.Name)
 }
 
+// ShowClusterConfiguration represents a SHOW CLUSTER CONFIGURATION statement.
+type ShowClusterConfiguration struct{}
+
+// Format implements the NodeFormatter interface.
+func (node *ShowClusterConfiguration) Format(ctx *FmtCtx) {
+	ctx.WriteString("SHOW CLUSTER CONFIGURATION")
+}
+
 // BackupDetails represents the type of details to display for a SHOW BACKUP
 // statement.
 type BackupDetails int
@@ -356,6 +364,16 @@ func (node *ShowRanges) Format(ctx *FmtCtx) {
 	}
 }
 
+// ShowRangeLeaseHistory represents a SHOW RANGE ... LEASE HISTORY statement.
+type ShowRangeLeaseHistory struct {
+	RangeID int64
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ShowRangeLeaseHistory) Format(ctx *FmtCtx) {
+	ctx.Printf("SHOW RANGE %d LEASE HISTORY", node.RangeID)
+}
+
 // ShowFingerprints represents a SHOW EXPERIMENTAL_FINGERPRINTS statement.
 type ShowFingerprints struct {
 	Table *NormalizableTableName
@@ -48,6 +48,10 @@ type Relocate struct {
 	// PK or index (or a prefix of the columns).
 	// See docs/RFCS/sql_split_syntax.md.
 	Rows *Select
+	// If RelocateLease is set, the first column of each row is a single store
+	// id, which becomes the leaseholder of the range; the replicas are not
+	// moved.
+	RelocateLease bool
 }
 
 // Format implements the NodeFormatter interface.
@@ -61,6 +65,9 @@ func (node *Relocate) Format(ctx *FmtCtx) {
 		ctx.FormatNode(node.Table)
 	}
 	ctx.WriteString(" EXPERIMENTAL_RELOCATE ")
+	if node.RelocateLease {
+		ctx.WriteString("LEASE ")
+	}
 	ctx.FormatNode(node.Rows)
 }
 
@@ -225,6 +225,14 @@ func (n *ControlJobs) StatementTag() string {
 
 func (*ControlJobs) independentFromParallelizedPriors() {}
 
+// StatementType implements the Statement interface.
+func (*ControlSchedule) StatementType() StatementType { return RowsAffected }
+
+// StatementTag returns a short string identifying the type of statement.
+func (n *ControlSchedule) StatementTag() string {
+	return fmt.Sprintf("%s SCHEDULE", ScheduleCommandToStatement[n.Command])
+}
+
 // StatementType implements the Statement interface.
 func (*CancelQueries) StatementType() StatementType { return RowsAffected }
 
@@ -300,6 +308,12 @@ func (*CreateUser) StatementTag() string { return "CREATE USER" }
 
 func (*CreateUser) hiddenFromShowQueries() {}
 
+// StatementType implements the Statement interface.
+func (*CreateReplicationStream) StatementType() StatementType { return Rows }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*CreateReplicationStream) StatementTag() string { return "CREATE REPLICATION STREAM" }
+
 // StatementType implements the Statement interface.
 func (*CreateRole) StatementType() StatementType { return RowsAffected }
 
@@ -308,11 +322,24 @@ func (*CreateRole) StatementTag() string { return "CREATE ROLE" }
 
 func (*CreateRole) hiddenFromShowQueries() {}
 
+// StatementType implements the Statement interface.
+func (*CreateSchedule) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*CreateSchedule) StatementTag() string { return "CREATE SCHEDULE" }
+
+func (*CreateSchedule) hiddenFromShowQueries() {}
+
 // StatementType implements the Statement interface.
 func (*CreateView) StatementType() StatementType { return DDL }
 
 // StatementTag returns a short string identifying the type of statement.
-func (*CreateView) StatementTag() string { return "CREATE VIEW" }
+func (n *CreateView) StatementTag() string {
+	if n.Materialized {
+		return "CREATE MATERIALIZED VIEW"
+	}
+	return "CREATE VIEW"
+}
 
 // StatementType implements the Statement interface.
 func (*CreateSequence) StatementType() StatementType { return DDL }
@@ -374,7 +401,12 @@ func (*DropTable) StatementTag() string { return "DROP TABLE" }
 func (*DropView) StatementType() StatementType { return DDL }
 
 // StatementTag returns a short string identifying the type of statement.
-func (*DropView) StatementTag() string { return "DROP VIEW" }
+func (n *DropView) StatementTag() string {
+	if n.IsMaterialized {
+		return "DROP MATERIALIZED VIEW"
+	}
+	return "DROP VIEW"
+}
 
 // StatementType implements the Statement interface.
 func (*DropSequence) StatementType() StatementType { return DDL }
@@ -458,6 +490,12 @@ func (*Prepare) StatementTag() string { return "PREPARE" }
 
 func (*Prepare) hiddenFromStats() {}
 
+// StatementType implements the Statement interface.
+func (*RefreshMaterializedView) StatementType() StatementType { return Ack }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*RefreshMaterializedView) StatementTag() string { return "REFRESH MATERIALIZED VIEW" }
+
 // StatementType implements the Statement interface.
 func (*ReleaseSavepoint) StatementType() StatementType { return Ack }
 
@@ -638,6 +676,14 @@ func (*ShowClusterSetting) StatementTag() string { return "SHOW" }
 func (*ShowClusterSetting) hiddenFromStats()                   {}
 func (*ShowClusterSetting) independentFromParallelizedPriors() {}
 
+// StatementType implements the Statement interface.
+func (*ShowClusterConfiguration) StatementType() StatementType { return Rows }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*ShowClusterConfiguration) StatementTag() string { return "SHOW CLUSTER CONFIGURATION" }
+
+func (*ShowClusterConfiguration) hiddenFromStats() {}
+
 // StatementType implements the Statement interface.
 func (*ShowColumns) StatementType() StatementType { return Rows }
 
@@ -811,6 +857,14 @@ func (*ShowRanges) StatementTag() string { return "SHOW EXPERIMENTAL_RANGES" }
 
 func (*ShowRanges) hiddenFromStats() {}
 
+// StatementType implements the Statement interface.
+func (*ShowRangeLeaseHistory) StatementType() StatementType { return Rows }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*ShowRangeLeaseHistory) StatementTag() string { return "SHOW RANGE LEASE HISTORY" }
+
+func (*ShowRangeLeaseHistory) hiddenFromStats() {}
+
 // StatementType implements the Statement interface.
 func (*ShowFingerprints) StatementType() StatementType { return Rows }
 
@@ -887,6 +941,7 @@ func (n *AlterSequence) String() string             { return AsString(n) }
 func (n *Backup) String() string                    { return AsString(n) }
 func (n *BeginTransaction) String() string          { return AsString(n) }
 func (n *ControlJobs) String() string               { return AsString(n) }
+func (n *ControlSchedule) String() string           { return AsString(n) }
 func (n *CancelQueries) String() string             { return AsString(n) }
 func (n *CancelSessions) String() string            { return AsString(n) }
 func (n *CommitTransaction) String() string         { return AsString(n) }
@@ -894,7 +949,9 @@ func (n *CopyFrom) String() string                  { return AsString(n) }
 func (n *CreateChangefeed) String() string          { return AsString(n) }
 func (n *CreateDatabase) String() string            { return AsString(n) }
 func (n *CreateIndex) String() string               { return AsString(n) }
+func (n *CreateReplicationStream) String() string   { return AsString(n) }
 func (n *CreateRole) String() string                { return AsString(n) }
+func (n *CreateSchedule) String() string            { return AsString(n) }
 func (n *CreateTable) String() string               { return AsString(n) }
 func (n *CreateSequence) String() string            { return AsString(n) }
 func (n *CreateStats) String() string               { return AsString(n) }
@@ -918,6 +975,7 @@ func (n *Insert) String() string                    { return AsString(n) }
 func (n *Import) String() string                    { return AsString(n) }
 func (n *ParenSelect) String() string               { return AsString(n) }
 func (n *Prepare) String() string                   { return AsString(n) }
+func (n *RefreshMaterializedView) String() string   { return AsString(n) }
 func (n *ReleaseSavepoint) String() string          { return AsString(n) }
 func (n *Relocate) String() string                  { return AsString(n) }
 func (n *RenameColumn) String() string              { return AsString(n) }
@@ -941,6 +999,7 @@ func (n *SetTransaction) String() string            { return AsString(n) }
 func (n *SetTracing) String() string                { return AsString(n) }
 func (n *SetVar) String() string                    { return AsString(n) }
 func (n *ShowBackup) String() string                { return AsString(n) }
+func (n *ShowClusterConfiguration) String() string  { return AsString(n) }
 func (n *ShowClusterSetting) String() string        { return AsString(n) }
 func (n *ShowColumns) String() string               { return AsString(n) }
 func (n *ShowConstraints) String() string           { return AsString(n) }
@@ -953,6 +1012,7 @@ func (n *ShowHistogram) String() string             { return AsString(n) }
 func (n *ShowIndex) String() string                 { return AsString(n) }
 func (n *ShowJobs) String() string                  { return AsString(n) }
 func (n *ShowQueries) String() string               { return AsString(n) }
+func (n *ShowRangeLeaseHistory) String() string     { return AsString(n) }
 func (n *ShowRanges) String() string                { return AsString(n) }
 func (n *ShowRoleGrants) String() string            { return AsString(n) }
 func (n *ShowRoles) String() string                 { return AsString(n) }
@@ -745,6 +745,28 @@ func (stmt *Backup) WalkStmt(v Visitor) Statement {
 	return ret
 }
 
+// CopyNode makes a copy of this Statement without recursing in any child Statements.
+func (stmt *CreateSchedule) CopyNode() *CreateSchedule {
+	stmtCopy := *stmt
+	return &stmtCopy
+}
+
+// WalkStmt is part of the WalkableStmt interface.
+func (stmt *CreateSchedule) WalkStmt(v Visitor) Statement {
+	ret := stmt
+	if backup := stmt.Backup.WalkStmt(v).(*Backup); backup != stmt.Backup {
+		ret = stmt.CopyNode()
+		ret.Backup = backup
+	}
+	if e, changed := WalkExpr(v, stmt.Recurrence); changed {
+		if ret == stmt {
+			ret = stmt.CopyNode()
+		}
+		ret.Recurrence = e
+	}
+	return ret
+}
+
 // CopyNode makes a copy of this Statement without recursing in any child Statements.
 func (stmt *Delete) CopyNode() *Delete {
 	stmtCopy := *stmt
@@ -1275,6 +1297,7 @@ func (stmt *ValuesClause) WalkStmt(v Visitor) Statement {
 
 var _ WalkableStmt = &CreateTable{}
 var _ WalkableStmt = &Backup{}
+var _ WalkableStmt = &CreateSchedule{}
 var _ WalkableStmt = &Delete{}
 var _ WalkableStmt = &Explain{}
 var _ WalkableStmt = &Insert{}
@@ -61,7 +61,7 @@ func (node *ZoneSpecifier) Format(ctx *FmtCtx) {
 
 func (node *ZoneSpecifier) String() string { return AsString(node) }
 
-// ShowZoneConfig represents an EXPERIMENTAL SHOW ZONE CONFIGURATION...
+// ShowZoneConfig represents a SHOW ZONE CONFIGURATION...
 // statement.
 type ShowZoneConfig struct {
 	ZoneSpecifier
@@ -70,24 +70,45 @@ type ShowZoneConfig struct {
 // Format implements the NodeFormatter interface.
 func (node *ShowZoneConfig) Format(ctx *FmtCtx) {
 	if node.ZoneSpecifier == (ZoneSpecifier{}) {
-		ctx.WriteString("EXPERIMENTAL SHOW ZONE CONFIGURATIONS")
+		ctx.WriteString("SHOW ZONE CONFIGURATIONS")
 	} else {
-		ctx.WriteString("EXPERIMENTAL SHOW ZONE CONFIGURATION FOR ")
+		ctx.WriteString("SHOW ZONE CONFIGURATION FOR ")
 		ctx.FormatNode(&node.ZoneSpecifier)
 	}
 }
 
-// SetZoneConfig represents an ALTER DATABASE/TABLE... EXPERIMENTAL CONFIGURE
-// ZONE statement.
+// SetZoneConfig represents an ALTER DATABASE/TABLE... CONFIGURE ZONE
+// statement.
 type SetZoneConfig struct {
 	ZoneSpecifier
+	// YAMLConfig is set for the CONFIGURE ZONE = <yaml> form. It is DNull
+	// for CONFIGURE ZONE DISCARD.
 	YAMLConfig Expr
+	// Options is set for the CONFIGURE ZONE USING <key> = <value>, ... form.
+	Options KVOptions
 }
 
 // Format implements the NodeFormatter interface.
 func (node *SetZoneConfig) Format(ctx *FmtCtx) {
 	ctx.WriteString("ALTER ")
 	ctx.FormatNode(&node.ZoneSpecifier)
-	ctx.WriteString(" EXPERIMENTAL CONFIGURE ZONE ")
-	ctx.FormatNode(node.YAMLConfig)
+	ctx.WriteString(" CONFIGURE ZONE ")
+	if node.YAMLConfig == DNull {
+		ctx.WriteString("DISCARD")
+	} else if node.YAMLConfig != nil {
+		ctx.WriteString("= ")
+		ctx.FormatNode(node.YAMLConfig)
+	} else {
+		ctx.WriteString("USING ")
+		for i, opt := range node.Options {
+			if i > 0 {
+				ctx.WriteString(", ")
+			}
+			// The key is a dotted parameter name such as gc.ttlseconds, which
+			// would be quoted as a whole if formatted as a Name.
+			ctx.WriteString(string(opt.Key))
+			ctx.WriteString(" = ")
+			ctx.FormatNode(opt.Value)
+		}
+	}
 }
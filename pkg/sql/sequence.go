@@ -50,7 +50,7 @@ func (p *planner) IncrementSequence(ctx context.Context, seqName *tree.TableName
 	}
 
 	seqValueKey := keys.MakeSequenceKey(uint32(descriptor.ID))
-	val, err := client.IncrementValRetryable(
+	val, err := client.IncrementValBatched(
 		ctx, p.txn.DB(), seqValueKey, descriptor.SequenceOpts.Increment)
 	if err != nil {
 		switch err.(type) {
@@ -247,6 +247,9 @@ func assignSequenceOptions(
 			}
 		case tree.SeqOptStart:
 			opts.Start = *option.IntVal
+		case tree.SeqOptOwnedBy:
+			// Do nothing; this requires name resolution and is handled by
+			// maybeSetSequenceOwner.
 		}
 	}
 
@@ -325,11 +328,19 @@ func removeSequenceDependencies(
 		if err := getDescriptorByID(params.ctx, params.p.txn, sequenceID, &seqDesc); err != nil {
 			return err
 		}
+		if seqDesc.Dropped() {
+			// The sequence is being dropped. No need to modify it further.
+			continue
+		}
 		// Find an item in seqDesc.DependedOnBy which references tableDesc.
 		refIdx := -1
 		for i, reference := range seqDesc.DependedOnBy {
 			if reference.ID == tableDesc.ID {
 				refIdx = i
+				if len(reference.ColumnIDs) == 1 && reference.ColumnIDs[0] == col.ID {
+					// This is the reference from this very column.
+					break
+				}
 			}
 		}
 		if refIdx == -1 {
@@ -346,6 +357,170 @@ func removeSequenceDependencies(
 	return nil
 }
 
+// maybeSetSequenceOwner applies the OWNED BY option, if any, to the sequence
+// descriptor. The previous owning column, if any, stops owning the sequence,
+// and the new owning column (unless OWNED BY NONE is specified) starts owning
+// it. The owning table descriptors are written; the sequence descriptor is
+// mutated but not saved, the caller must save it.
+func (p *planner) maybeSetSequenceOwner(
+	ctx context.Context, seqDesc *sqlbase.TableDescriptor, optsNode tree.SequenceOptions,
+) error {
+	var ownedBy *tree.SequenceOption
+	for i := range optsNode {
+		if optsNode[i].Name == tree.SeqOptOwnedBy {
+			ownedBy = &optsNode[i]
+		}
+	}
+	if ownedBy == nil {
+		return nil
+	}
+	if err := p.removeSequenceOwnerIfExists(ctx, seqDesc); err != nil {
+		return err
+	}
+	colItem := ownedBy.ColumnItemVal
+	if colItem == nil {
+		// OWNED BY NONE.
+		return nil
+	}
+	if colItem.TableName.NumParts == 0 {
+		return pgerror.NewErrorf(pgerror.CodeSyntaxError,
+			"invalid OWNED BY option: %s", colItem).SetHintf(
+			"Specify OWNED BY table.column or OWNED BY NONE.")
+	}
+	tn, err := tree.NormalizeTableName(&colItem.TableName)
+	if err != nil {
+		return err
+	}
+	var tableDesc *TableDescriptor
+	// DDL statements avoid the cache to avoid leases, and can view non-public descriptors.
+	p.runWithOptions(resolveFlags{skipCache: true}, func() {
+		tableDesc, err = ResolveExistingObject(ctx, p, &tn, true /*required*/, requireTableDesc)
+	})
+	if err != nil {
+		return err
+	}
+	if tableDesc.ParentID != seqDesc.ParentID {
+		return pgerror.NewError(pgerror.CodeObjectNotInPrerequisiteStateError,
+			"sequence must be in same database as table it is linked to")
+	}
+	if err := p.CheckPrivilege(ctx, tableDesc, privilege.CREATE); err != nil {
+		return err
+	}
+	c, err := tableDesc.FindActiveColumnByName(string(colItem.ColumnName))
+	if err != nil {
+		return err
+	}
+	col, err := tableDesc.FindColumnByID(c.ID)
+	if err != nil {
+		return err
+	}
+	setSequenceOwner(seqDesc, tableDesc, col)
+	return p.saveNonmutationAndNotify(ctx, tableDesc)
+}
+
+// setSequenceOwner adds references between the sequence descriptor and the
+// descriptor of the column that owns it. Neither descriptor is saved.
+func setSequenceOwner(
+	seqDesc *sqlbase.TableDescriptor,
+	tableDesc *sqlbase.TableDescriptor,
+	col *sqlbase.ColumnDescriptor,
+) {
+	seqDesc.SequenceOpts.OwnerTableID = tableDesc.ID
+	seqDesc.SequenceOpts.OwnerColumnID = col.ID
+	col.OwnsSequenceIds = append(col.OwnsSequenceIds, seqDesc.ID)
+}
+
+// removeSequenceOwnerIfExists removes the references between the sequence
+// descriptor and the descriptor of the column that owns it, if any. The
+// owning table descriptor is written; the sequence descriptor is mutated but
+// not saved, the caller must save it.
+func (p *planner) removeSequenceOwnerIfExists(
+	ctx context.Context, seqDesc *sqlbase.TableDescriptor,
+) error {
+	opts := seqDesc.SequenceOpts
+	if opts.OwnerTableID == sqlbase.InvalidID {
+		return nil
+	}
+	tableID, colID := opts.OwnerTableID, opts.OwnerColumnID
+	opts.OwnerTableID = sqlbase.InvalidID
+	opts.OwnerColumnID = 0
+
+	tableDesc, err := sqlbase.GetTableDescFromID(ctx, p.txn, tableID)
+	if err != nil {
+		return err
+	}
+	if tableDesc.Dropped() {
+		// The owning table is being dropped. No need to modify it further.
+		return nil
+	}
+	col, err := tableDesc.FindColumnByID(colID)
+	if err != nil {
+		return err
+	}
+	for i, id := range col.OwnsSequenceIds {
+		if id == seqDesc.ID {
+			col.OwnsSequenceIds = append(col.OwnsSequenceIds[:i], col.OwnsSequenceIds[i+1:]...)
+			break
+		}
+	}
+	return p.saveNonmutationAndNotify(ctx, tableDesc)
+}
+
+// canDropSequencesOwnedByCol returns an error if a sequence owned by the
+// given column is used in the DEFAULT expression of another column, since
+// dropping the owning column also drops the sequence. Uses by the column
+// itself and by the tables marked in dropping are allowed.
+func (p *planner) canDropSequencesOwnedByCol(
+	ctx context.Context,
+	tableID sqlbase.ID,
+	col *sqlbase.ColumnDescriptor,
+	dropping map[sqlbase.ID]bool,
+) error {
+	for _, sequenceID := range col.OwnsSequenceIds {
+		seqDesc, err := sqlbase.GetTableDescFromID(ctx, p.txn, sequenceID)
+		if err != nil {
+			return err
+		}
+		for _, ref := range seqDesc.DependedOnBy {
+			if dropping[ref.ID] {
+				continue
+			}
+			if ref.ID == tableID && len(ref.ColumnIDs) == 1 && ref.ColumnIDs[0] == col.ID {
+				continue
+			}
+			return pgerror.NewErrorf(
+				pgerror.CodeDependentObjectsStillExistError,
+				"cannot drop sequence %s because other objects depend on it",
+				seqDesc.Name,
+			).SetHintf("sequence %s is owned by column %q", seqDesc.Name, col.Name)
+		}
+	}
+	return nil
+}
+
+// dropSequencesOwnedByCol drops the sequences owned by the given column,
+// which is being dropped. canDropSequencesOwnedByCol must have been checked
+// beforehand.
+func (p *planner) dropSequencesOwnedByCol(
+	ctx context.Context, col *sqlbase.ColumnDescriptor,
+) error {
+	for _, sequenceID := range col.OwnsSequenceIds {
+		seqDesc, err := sqlbase.GetTableDescFromID(ctx, p.txn, sequenceID)
+		if err != nil {
+			return err
+		}
+		if seqDesc.Dropped() {
+			// The sequence is already being dropped, e.g. by DROP DATABASE.
+			continue
+		}
+		if err := p.initiateDropTable(ctx, seqDesc, true /* drainName */); err != nil {
+			return err
+		}
+	}
+	col.OwnsSequenceIds = nil
+	return nil
+}
+
 // getUsedSequenceNames returns the name of the sequence passed to
 // a call to nextval in the given expression, or nil if there is
 // no call to nextval.
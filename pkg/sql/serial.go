@@ -0,0 +1,90 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// isSerialColumnDef returns true if the column definition uses one of the
+// SERIAL pseudo-types.
+func isSerialColumnDef(d *tree.ColumnTableDef) bool {
+	t, ok := d.Type.(*coltypes.TInt)
+	return ok && t.IsSerial()
+}
+
+// processSerialInColumnDef analyzes a column definition and, if the column
+// is SERIAL and the session's serial_normalization mode is sql_sequence,
+// creates a new sequence for it. In that case it returns a copy of the
+// column definition that uses INT with a DEFAULT of nextval() on the new
+// sequence, along with the new sequence's descriptor. Otherwise the column
+// definition is returned unchanged and the sequence descriptor is nil, and
+// SERIAL is handled by sqlbase.MakeColumnDefDescs.
+//
+// The caller is responsible for making the column own the sequence once the
+// column descriptor exists, with setSequenceOwner.
+func processSerialInColumnDef(
+	params runParams,
+	d *tree.ColumnTableDef,
+	dbDesc *DatabaseDescriptor,
+	tableName *tree.TableName,
+	statement string,
+) (*tree.ColumnTableDef, *sqlbase.TableDescriptor, error) {
+	if !isSerialColumnDef(d) ||
+		params.SessionData().SerialNormalizationMode != sessiondata.SerialUsesSQLSequences {
+		return d, nil, nil
+	}
+	if d.HasDefaultExpr() {
+		// Checked here too so that no sequence gets created.
+		return nil, nil, fmt.Errorf("SERIAL column %q cannot have a default value", d.Name)
+	}
+
+	// Pick a sequence name that is not used yet, the same way as
+	// PostgreSQL: <table>_<column>_seq, followed by a number if needed.
+	baseName := fmt.Sprintf("%s_%s_seq", tableName.Table(), string(d.Name))
+	seqName := tree.MakeTableName(tableName.CatalogName, tree.Name(baseName))
+	seqName.ExplicitSchema = false
+	for i := 1; ; i++ {
+		key := tableKey{parentID: dbDesc.ID, name: seqName.Table()}.Key()
+		exists, err := descExists(params.ctx, params.p.txn, key)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !exists {
+			break
+		}
+		seqName.TableName = tree.Name(fmt.Sprintf("%s%d", baseName, i))
+	}
+
+	seqDesc, err := doCreateSequence(
+		params, statement, dbDesc, &seqName, nil, /* opts */
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	newDef := *d
+	newDef.Type = coltypes.Int
+	newDef.DefaultExpr.Expr = &tree.FuncExpr{
+		Func:  tree.WrapFunction("nextval"),
+		Exprs: tree.Exprs{tree.NewStrVal(seqName.String())},
+	}
+	return &newDef, seqDesc, nil
+}
@@ -42,6 +42,10 @@ type SessionData struct {
 	// DistSQLMode indicates whether to run queries using the distributed
 	// execution engine.
 	DistSQLMode DistSQLExecMode
+	// ForeignKeyCascadesLimit is the maximum number of cascading operations
+	// that a single statement may perform through foreign key actions. If set
+	// to 0, there is no limit.
+	ForeignKeyCascadesLimit int64
 	// LookupJoinEnabled indicates whether the planner should try and plan a
 	// lookup join where the left side is scanned and index lookups are done on
 	// the right side. Will emit a warning if a lookup join can't be planned.
@@ -58,6 +62,14 @@ type SessionData struct {
 	// StmtTimeout is the duration a query is permitted to run before it is
 	// canceled by the session. If set to 0, there is no timeout.
 	StmtTimeout time.Duration
+	// IdleInTransactionSessionTimeout is the duration a session is permitted to
+	// stay idle in an open transaction before it is terminated. If set to 0,
+	// there is no timeout.
+	IdleInTransactionSessionTimeout time.Duration
+	// IdleSessionTimeout is the duration a session is permitted to stay idle
+	// outside of a transaction before it is terminated. If set to 0, there is
+	// no timeout.
+	IdleSessionTimeout time.Duration
 	// User is the name of the user logged into the session.
 	User string
 	// SafeUpdates causes errors when the client
@@ -67,10 +79,22 @@ type SessionData struct {
 	// ZigzagJoinEnabled indicates whether the planner should try and plan a
 	// zigzag join. Will emit a warning if a zigzag join can't be planned.
 	ZigzagJoinEnabled bool
+	// OptimizerUseNotVisibleIndexes indicates whether the planner may use
+	// indexes that were made NOT VISIBLE.
+	OptimizerUseNotVisibleIndexes bool
+	// OptimizerUseMultiColStats indicates whether the optimizer uses the
+	// multi-column statistics of tables to estimate the selectivity of filters
+	// on correlated columns.
+	OptimizerUseMultiColStats bool
+	// Vectorize indicates whether to run the supported parts of DistSQL flows
+	// with the vectorized execution engine.
+	Vectorize VectorizeExecMode
 
 	// BytesEncodeFormat indicates how to encode byte arrays when converting
 	// to string.
 	BytesEncodeFormat BytesEncodeFormat
+	// SerialNormalizationMode indicates how to handle the SERIAL pseudo-type.
+	SerialNormalizationMode SerialNormalizationMode
 
 	// SequenceState gives access to the SQL sequences that have been manipulated
 	// by the session.
@@ -125,6 +149,44 @@ func BytesEncodeFormatFromString(val string) (_ BytesEncodeFormat, ok bool) {
 	}
 }
 
+// SerialNormalizationMode controls how the SERIAL type is interpreted in table
+// definitions.
+type SerialNormalizationMode int64
+
+const (
+	// SerialUsesRowID means use INT with a DEFAULT of unique_rowid(). This is
+	// the default, as it scales best.
+	SerialUsesRowID SerialNormalizationMode = iota
+	// SerialUsesSQLSequences means create a SQL sequence owned by the column
+	// and use INT with a DEFAULT of nextval() on that sequence, as PostgreSQL
+	// does.
+	SerialUsesSQLSequences
+)
+
+func (m SerialNormalizationMode) String() string {
+	switch m {
+	case SerialUsesRowID:
+		return "rowid"
+	case SerialUsesSQLSequences:
+		return "sql_sequence"
+	default:
+		return fmt.Sprintf("invalid (%d)", m)
+	}
+}
+
+// SerialNormalizationModeFromString converts a string into a
+// SerialNormalizationMode.
+func SerialNormalizationModeFromString(val string) (_ SerialNormalizationMode, ok bool) {
+	switch strings.ToUpper(val) {
+	case "ROWID":
+		return SerialUsesRowID, true
+	case "SQL_SEQUENCE":
+		return SerialUsesSQLSequences, true
+	default:
+		return 0, false
+	}
+}
+
 // DistSQLExecMode controls if and when the Executor uses DistSQL.
 type DistSQLExecMode int64
 
@@ -171,6 +233,49 @@ func DistSQLExecModeFromString(val string) (_ DistSQLExecMode, ok bool) {
 	}
 }
 
+// VectorizeExecMode controls if and when DistSQL flows use the vectorized
+// execution engine.
+type VectorizeExecMode int64
+
+const (
+	// VectorizeOff means that we never use the vectorized engine.
+	VectorizeOff VectorizeExecMode = iota
+	// VectorizeOn means that we use the vectorized engine for the processors
+	// that it supports, and the row-based engine for the others.
+	VectorizeOn
+	// VectorizeAlways means that the processors whose core is supported by the
+	// vectorized engine fail to be set up if they can't be vectorized, e.g.
+	// because of the types of their columns. This mode is useful for testing.
+	VectorizeAlways
+)
+
+func (m VectorizeExecMode) String() string {
+	switch m {
+	case VectorizeOff:
+		return "off"
+	case VectorizeOn:
+		return "on"
+	case VectorizeAlways:
+		return "always"
+	default:
+		return fmt.Sprintf("invalid (%d)", m)
+	}
+}
+
+// VectorizeExecModeFromString converts a string into a VectorizeExecMode
+func VectorizeExecModeFromString(val string) (_ VectorizeExecMode, ok bool) {
+	switch strings.ToUpper(val) {
+	case "OFF":
+		return VectorizeOff, true
+	case "ON":
+		return VectorizeOn, true
+	case "ALWAYS":
+		return VectorizeAlways, true
+	default:
+		return 0, false
+	}
+}
+
 // OptimizerMode controls if and when the Executor uses the optimizer.
 type OptimizerMode int64
 
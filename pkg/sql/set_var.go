@@ -203,43 +203,50 @@ func setTimeZone(
 	return nil
 }
 
-func setStmtTimeout(
-	_ context.Context, m *sessionDataMutator, evalCtx *extendedEvalContext, values []tree.TypedExpr,
-) error {
-	if len(values) != 1 {
-		return errors.New("set statement_timeout requires a single argument")
-	}
-	d, err := values[0].Eval(&evalCtx.EvalContext)
-	if err != nil {
-		return err
-	}
-
-	var timeout time.Duration
-	switch v := tree.UnwrapDatum(&evalCtx.EvalContext, d).(type) {
-	case *tree.DString:
-		interval, err := tree.ParseDInterval(string(*v))
-		if err != nil {
-			return err
+// makeTimeoutSetter returns the Set function of a session variable holding a
+// timeout. The timeout can be given as an interval, or as an integer number of
+// milliseconds.
+func makeTimeoutSetter(
+	varName string, setFunc func(*sessionDataMutator, time.Duration),
+) func(context.Context, *sessionDataMutator, *extendedEvalContext, []tree.TypedExpr) error {
+	return func(
+		_ context.Context, m *sessionDataMutator, evalCtx *extendedEvalContext, values []tree.TypedExpr,
+	) error {
+		if len(values) != 1 {
+			return errors.Errorf("set %s requires a single argument", varName)
 		}
-		timeout, err = intervalToDuration(interval)
+		d, err := values[0].Eval(&evalCtx.EvalContext)
 		if err != nil {
 			return err
 		}
-	case *tree.DInterval:
-		timeout, err = intervalToDuration(v)
-		if err != nil {
-			return err
+
+		var timeout time.Duration
+		switch v := tree.UnwrapDatum(&evalCtx.EvalContext, d).(type) {
+		case *tree.DString:
+			interval, err := tree.ParseDInterval(string(*v))
+			if err != nil {
+				return err
+			}
+			timeout, err = intervalToDuration(interval)
+			if err != nil {
+				return err
+			}
+		case *tree.DInterval:
+			timeout, err = intervalToDuration(v)
+			if err != nil {
+				return err
+			}
+		case *tree.DInt:
+			timeout = time.Duration(*v) * time.Millisecond
 		}
-	case *tree.DInt:
-		timeout = time.Duration(*v) * time.Millisecond
-	}
 
-	if timeout < 0 {
-		return errors.New("statement_timeout cannot have a negative duration")
-	}
-	m.SetStmtTimeout(timeout)
+		if timeout < 0 {
+			return errors.Errorf("%s cannot have a negative duration", varName)
+		}
+		setFunc(m, timeout)
 
-	return nil
+		return nil
+	}
 }
 
 func intervalToDuration(interval *tree.DInterval) (time.Duration, error) {
@@ -36,19 +36,119 @@ import (
 type setZoneConfigNode struct {
 	zoneSpecifier tree.ZoneSpecifier
 	yamlConfig    tree.TypedExpr
+	options       []setZoneConfigOption
 
 	run setZoneConfigRun
 }
 
+// setZoneConfigOption is a parameter of ALTER ... CONFIGURE ZONE USING.
+type setZoneConfigOption struct {
+	key   tree.Name
+	value tree.TypedExpr
+}
+
+// zoneConfigOption describes a parameter accepted by ALTER ... CONFIGURE ZONE
+// USING.
+type zoneConfigOption struct {
+	requiredType types.T
+	// yamlKey is the path of the parameter in a YAML zone config.
+	yamlKey []string
+	// parse parses the value of a string parameter, which is written in the
+	// parameter's YAML short form, into a value of the parameter's type. It
+	// is nil for integer parameters.
+	parse func(string) (interface{}, error)
+	// null returns the value a parameter set to NULL is reset to, taken from
+	// the provided default zone config.
+	null func(config.ZoneConfig) interface{}
+}
+
+// supportedZoneConfigOptions lists the parameters accepted by ALTER ...
+// CONFIGURE ZONE USING.
+var supportedZoneConfigOptions = map[tree.Name]zoneConfigOption{
+	"range_min_bytes": {types.Int, []string{"range_min_bytes"}, nil,
+		func(z config.ZoneConfig) interface{} { return z.RangeMinBytes }},
+	"range_max_bytes": {types.Int, []string{"range_max_bytes"}, nil,
+		func(z config.ZoneConfig) interface{} { return z.RangeMaxBytes }},
+	"gc.ttlseconds": {types.Int, []string{"gc", "ttlseconds"}, nil,
+		func(z config.ZoneConfig) interface{} { return z.GC.TTLSeconds }},
+	"num_replicas": {types.Int, []string{"num_replicas"}, nil,
+		func(z config.ZoneConfig) interface{} { return z.NumReplicas }},
+	"constraints": {types.String, []string{"constraints"},
+		func(s string) (interface{}, error) {
+			var c config.ConstraintsList
+			err := yaml.UnmarshalStrict([]byte(s), &c)
+			return c, err
+		},
+		func(z config.ZoneConfig) interface{} { return config.ConstraintsList(z.Constraints) }},
+	"lease_preferences": {types.String, []string{"experimental_lease_preferences"},
+		func(s string) (interface{}, error) {
+			var l []config.LeasePreference
+			err := yaml.UnmarshalStrict([]byte(s), &l)
+			return l, err
+		},
+		func(z config.ZoneConfig) interface{} {
+			return append([]config.LeasePreference{}, z.LeasePreferences...)
+		}},
+	"num_learners": {types.Int, []string{"num_learners"}, nil,
+		func(z config.ZoneConfig) interface{} { return z.NumLearners }},
+	"learner_constraints": {types.String, []string{"learner_constraints"},
+		func(s string) (interface{}, error) {
+			var short []string
+			if err := yaml.UnmarshalStrict([]byte(s), &short); err != nil {
+				return nil, err
+			}
+			for _, c := range short {
+				if err := new(config.Constraint).FromString(c); err != nil {
+					return nil, err
+				}
+			}
+			return short, nil
+		},
+		func(z config.ZoneConfig) interface{} {
+			short := []string{}
+			for _, c := range z.LearnerConstraints {
+				short = append(short, c.String())
+			}
+			return short
+		}},
+}
+
 func (p *planner) SetZoneConfig(ctx context.Context, n *tree.SetZoneConfig) (planNode, error) {
-	yamlConfig, err := p.analyzeExpr(
-		ctx, n.YAMLConfig, nil, tree.IndexedVarHelper{}, types.String, false, "configure zone")
-	if err != nil {
-		return nil, err
+	var yamlConfig tree.TypedExpr
+	if n.YAMLConfig != nil {
+		var err error
+		yamlConfig, err = p.analyzeExpr(
+			ctx, n.YAMLConfig, nil, tree.IndexedVarHelper{}, types.String, false, "configure zone")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var options []setZoneConfigOption
+	seen := make(map[tree.Name]struct{}, len(n.Options))
+	for _, opt := range n.Options {
+		if _, ok := seen[opt.Key]; ok {
+			return nil, pgerror.NewErrorf(pgerror.CodeSyntaxError,
+				"duplicate zone config parameter: %q", string(opt.Key))
+		}
+		seen[opt.Key] = struct{}{}
+		req, ok := supportedZoneConfigOptions[opt.Key]
+		if !ok {
+			return nil, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+				"unsupported zone config parameter: %q", string(opt.Key))
+		}
+		value, err := p.analyzeExpr(
+			ctx, opt.Value, nil, tree.IndexedVarHelper{}, req.requiredType, true, string(opt.Key))
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, setZoneConfigOption{key: opt.Key, value: value})
 	}
+
 	return &setZoneConfigNode{
 		zoneSpecifier: n.ZoneSpecifier,
 		yamlConfig:    yamlConfig,
+		options:       options,
 	}, nil
 }
 
@@ -59,22 +159,33 @@ type setZoneConfigRun struct {
 
 func (n *setZoneConfigNode) startExec(params runParams) error {
 	var yamlConfig *string
-	datum, err := n.yamlConfig.Eval(params.EvalContext())
-	if err != nil {
-		return err
-	}
-	switch val := datum.(type) {
-	case *tree.DString:
-		yamlConfig = (*string)(val)
-	case *tree.DBytes:
-		yamlConfig = (*string)(val)
-	default:
-		if datum != tree.DNull {
-			return fmt.Errorf("zone config must be of type string or bytes, not %T", val)
+	if n.yamlConfig != nil {
+		datum, err := n.yamlConfig.Eval(params.EvalContext())
+		if err != nil {
+			return err
+		}
+		switch val := datum.(type) {
+		case *tree.DString:
+			yamlConfig = (*string)(val)
+		case *tree.DBytes:
+			yamlConfig = (*string)(val)
+		default:
+			if datum != tree.DNull {
+				return fmt.Errorf("zone config must be of type string or bytes, not %T", val)
+			}
 		}
+	} else {
+		// The parameters are applied by way of the equivalent YAML, so that both
+		// forms of the statement go through the same validation.
+		optionsYAML, err := n.optionsToYAML(params.EvalContext())
+		if err != nil {
+			return err
+		}
+		yamlConfig = &optionsYAML
 	}
 
 	var table *TableDescriptor
+	var err error
 	// DDL statements avoid the cache to avoid leases, and can view non-public descriptors.
 	// TODO(vivek): check if the cache can be used.
 	params.p.runWithOptions(resolveFlags{skipCache: true}, func() {
@@ -153,6 +264,9 @@ func (n *setZoneConfigNode) startExec(params runParams) error {
 		if err := zone.Validate(); err != nil {
 			return fmt.Errorf("could not validate zone config: %s", err)
 		}
+		if err := validateSystemRangesZone(&n.zoneSpecifier, targetID, zone); err != nil {
+			return err
+		}
 		if err := validateZoneAttrsAndLocalities(
 			params.ctx,
 			params.extendedEvalCtx.StatusServer.Nodes,
@@ -194,12 +308,100 @@ func (n *setZoneConfigNode) startExec(params runParams) error {
 	)
 }
 
+// optionsToYAML translates the parameters of ALTER ... CONFIGURE ZONE USING
+// into the equivalent YAML zone config. Each value is parsed into the type of
+// its parameter and marshaled on its own, so that it can't set anything else.
+// A parameter set to NULL is reset to its value in the default zone config.
+func (n *setZoneConfigNode) optionsToYAML(evalCtx *tree.EvalContext) (string, error) {
+	defaults := config.DefaultZoneConfig()
+	var options yaml.MapSlice
+	for _, opt := range n.options {
+		datum, err := opt.value.Eval(evalCtx)
+		if err != nil {
+			return "", err
+		}
+		option := supportedZoneConfigOptions[opt.key]
+		var value interface{}
+		switch d := datum.(type) {
+		case *tree.DInt:
+			value = int64(*d)
+		case *tree.DString:
+			if value, err = option.parse(string(*d)); err != nil {
+				return "", fmt.Errorf("could not parse zone config: %s: %s", string(opt.key), err)
+			}
+		default:
+			if datum != tree.DNull {
+				return "", pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+					"unsupported value for zone config parameter %q: %s", string(opt.key), datum)
+			}
+			value = option.null(defaults)
+		}
+		// Nest the value under its path, e.g. gc: {ttlseconds: value}.
+		for i := len(option.yamlKey) - 1; i > 0; i-- {
+			value = yaml.MapSlice{{Key: option.yamlKey[i], Value: value}}
+		}
+		options = append(options, yaml.MapItem{Key: option.yamlKey[0], Value: value})
+	}
+	out, err := yaml.Marshal(options)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
 func (n *setZoneConfigNode) Next(runParams) (bool, error) { return false, nil }
 func (n *setZoneConfigNode) Values() tree.Datums          { return nil }
 func (*setZoneConfigNode) Close(context.Context)          {}
 
 func (n *setZoneConfigNode) FastPathResults() (int, bool) { return n.run.numAffected, true }
 
+// validateSystemRangesZone performs the additional validation of zone configs
+// for the ranges the whole cluster depends on (see
+// config.IsSystemRangesZoneID).
+//
+// These are typically given a higher replication factor than the default in
+// large clusters, which must be odd: an even number of replicas survives no
+// more failures than one fewer replica would, while making every write wait
+// for one more replica. ZoneConfig.Validate already rejects 2 replicas for all
+// zones; 4, 6 and so on are only rejected here.
+//
+// Their leaseholders are pinned with lease preferences; in particular, the
+// zone of the system database pins the leaseholder of the system config range,
+// which gossips the system config. A lease preference which contradicts a
+// constraint applying to all of the replicas can never be satisfied, which
+// would silently leave the lease unpinned, so it is rejected.
+func validateSystemRangesZone(
+	zs *tree.ZoneSpecifier, targetID sqlbase.ID, zone config.ZoneConfig,
+) error {
+	if !config.IsSystemRangesZoneID(uint32(targetID)) {
+		return nil
+	}
+	if zone.NumReplicas > 1 && zone.NumReplicas%2 == 0 {
+		return pgerror.NewErrorf(pgerror.CodeCheckViolationError,
+			"num_replicas for %s must be odd, got %d", config.CLIZoneSpecifier(zs), zone.NumReplicas)
+	}
+	for _, leasePref := range zone.LeasePreferences {
+		for _, prefConstraint := range leasePref.Constraints {
+			for _, constraints := range zone.Constraints {
+				if constraints.NumReplicas != 0 && constraints.NumReplicas != zone.NumReplicas {
+					// Constraints on a subset of the replicas leave the other
+					// replicas free to hold the lease.
+					continue
+				}
+				for _, constraint := range constraints.Constraints {
+					if constraint.Key == prefConstraint.Key && constraint.Value == prefConstraint.Value &&
+						constraint.Type != prefConstraint.Type {
+						return pgerror.NewErrorf(pgerror.CodeCheckViolationError,
+							"lease preference %s for %s contradicts constraint %s",
+							prefConstraint, config.CLIZoneSpecifier(zs), constraint)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
 type nodeGetter func(context.Context, *serverpb.NodesRequest) (*serverpb.NodesResponse, error)
 
 // validateZoneAttrsAndLocalities ensures that all constraints/lease preferences
@@ -0,0 +1,240 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/lex"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+var showClusterConfigurationColumns = sqlbase.ResultColumns{
+	{Name: "statement", Typ: types.String},
+}
+
+// ShowClusterConfiguration returns a SHOW CLUSTER CONFIGURATION statement,
+// which returns the SQL statements that recreate the configuration of the
+// cluster: the cluster settings that have been changed, the users and roles,
+// the role memberships, the privileges on databases and tables, and the zone
+// configs. The statements are idempotent, so the whole script can be replayed
+// on a rebuilt cluster, once its databases and tables have been restored.
+// Passwords are not exported.
+// Privileges: superuser.
+func (p *planner) ShowClusterConfiguration(
+	ctx context.Context, n *tree.ShowClusterConfiguration,
+) (planNode, error) {
+	if err := p.RequireSuperUser(ctx, "SHOW CLUSTER CONFIGURATION"); err != nil {
+		return nil, err
+	}
+	return &delayedNode{
+		name:    n.String(),
+		columns: showClusterConfigurationColumns,
+
+		constructor: func(ctx context.Context, p *planner) (planNode, error) {
+			var stmts []string
+			for _, gen := range []func(context.Context) ([]string, error){
+				p.clusterSettingsSQL,
+				p.usersSQL,
+				p.grantsSQL,
+				p.zoneConfigsSQL,
+			} {
+				s, err := gen(ctx)
+				if err != nil {
+					return nil, err
+				}
+				stmts = append(stmts, s...)
+			}
+
+			v := p.newContainerValuesNode(showClusterConfigurationColumns, len(stmts))
+			for _, stmt := range stmts {
+				if _, err := v.rows.AddRow(ctx, tree.Datums{tree.NewDString(stmt)}); err != nil {
+					v.Close(ctx)
+					return nil, err
+				}
+			}
+			return v, nil
+		},
+	}, nil
+}
+
+// clusterSettingsSQL returns a SET CLUSTER SETTING statement for every
+// setting that has been changed from its default. The values are read from
+// system.settings rather than from the local copy of the settings, which may
+// lag behind. The cluster version is skipped, as it's managed by the upgrade
+// process.
+func (p *planner) clusterSettingsSQL(ctx context.Context) ([]string, error) {
+	rows, _ /* cols */, err := p.ExtendedEvalContext().ExecCfg.InternalExecutor.Query(
+		ctx, "show-cluster-configuration-settings", p.txn,
+		`SELECT name, value, "valueType" FROM system.settings ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	var stmts []string
+	for _, r := range rows {
+		name := string(tree.MustBeDString(r[0]))
+		if name == "version" {
+			continue
+		}
+		if _, ok := settings.Lookup(name); !ok {
+			// The setting has been retired.
+			continue
+		}
+		// The encoded values of booleans, numbers and enums are valid SQL
+		// literals. The others (strings, durations and byte sizes) need to be
+		// quoted.
+		value := string(tree.MustBeDString(r[1]))
+		var typ string
+		if r[2] != tree.DNull {
+			typ = string(tree.MustBeDString(r[2]))
+		}
+		switch typ {
+		case "b", "i", "f", "e":
+		default:
+			value = lex.EscapeSQLString(value)
+		}
+		stmts = append(stmts, fmt.Sprintf("SET CLUSTER SETTING %s = %s", name, value))
+	}
+	return stmts, nil
+}
+
+// usersSQL returns the statements that create the users and roles, other
+// than root and admin, and that grant the role memberships.
+func (p *planner) usersSQL(ctx context.Context) ([]string, error) {
+	ie := p.ExtendedEvalContext().ExecCfg.InternalExecutor
+	rows, _ /* cols */, err := ie.Query(
+		ctx, "show-cluster-configuration-users", p.txn,
+		`SELECT username, "isRole" FROM system.users ORDER BY username`)
+	if err != nil {
+		return nil, err
+	}
+	var stmts []string
+	for _, r := range rows {
+		name := string(tree.MustBeDString(r[0]))
+		if name == security.RootUser || name == sqlbase.AdminRole {
+			continue
+		}
+		kind := "USER"
+		if tree.MustBeDBool(r[1]) {
+			kind = "ROLE"
+		}
+		stmts = append(stmts, fmt.Sprintf("CREATE %s IF NOT EXISTS %s", kind, lex.EscapeSQLString(name)))
+	}
+
+	rows, _ /* cols */, err = ie.Query(
+		ctx, "show-cluster-configuration-role-members", p.txn,
+		`SELECT "role", member, "isAdmin" FROM system.role_members ORDER BY "role", member`)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		role, member := tree.Name(tree.MustBeDString(r[0])), tree.Name(tree.MustBeDString(r[1]))
+		if string(role) == sqlbase.AdminRole && string(member) == security.RootUser {
+			continue
+		}
+		stmt := fmt.Sprintf("GRANT %s TO %s", tree.AsString(&role), tree.AsString(&member))
+		if tree.MustBeDBool(r[2]) {
+			stmt += " WITH ADMIN OPTION"
+		}
+		stmts = append(stmts, stmt)
+	}
+	return stmts, nil
+}
+
+// grantsSQL returns the GRANT statements for the privileges of the users and
+// roles on the databases and tables, other than those of root and admin,
+// which are always granted. The system database is skipped, as its
+// privileges are fixed.
+func (p *planner) grantsSQL(ctx context.Context) ([]string, error) {
+	descs, err := p.Tables().getAllDescriptors(ctx, p.txn)
+	if err != nil {
+		return nil, err
+	}
+	dbNames := make(map[sqlbase.ID]string)
+	for _, desc := range descs {
+		if db, ok := desc.(*sqlbase.DatabaseDescriptor); ok {
+			dbNames[db.ID] = db.Name
+		}
+	}
+
+	grants := func(target string, privs *sqlbase.PrivilegeDescriptor) []string {
+		var stmts []string
+		for _, u := range privs.Show() {
+			if u.User == security.RootUser || u.User == sqlbase.AdminRole || len(u.Privileges) == 0 {
+				continue
+			}
+			user := tree.Name(u.User)
+			stmts = append(stmts, fmt.Sprintf("GRANT %s ON %s TO %s",
+				strings.Join(u.Privileges, ", "), target, tree.AsString(&user)))
+		}
+		return stmts
+	}
+
+	var dbStmts, tableStmts []string
+	for _, desc := range descs {
+		switch desc := desc.(type) {
+		case *sqlbase.DatabaseDescriptor:
+			if desc.ID == keys.SystemDatabaseID {
+				continue
+			}
+			name := tree.Name(desc.Name)
+			dbStmts = append(dbStmts, grants("DATABASE "+tree.AsString(&name), desc.Privileges)...)
+		case *sqlbase.TableDescriptor:
+			if desc.ParentID == keys.SystemDatabaseID || desc.Dropped() || desc.Adding() {
+				continue
+			}
+			dbName, ok := dbNames[desc.ParentID]
+			if !ok {
+				continue
+			}
+			tn := tree.MakeTableName(tree.Name(dbName), tree.Name(desc.Name))
+			tableStmts = append(tableStmts, grants("TABLE "+tn.String(), desc.GetPrivileges())...)
+		}
+	}
+	sort.Strings(dbStmts)
+	sort.Strings(tableStmts)
+	return append(dbStmts, tableStmts...), nil
+}
+
+// zoneConfigsSQL returns the statements that set the zone configs, including
+// those of indexes and partitions.
+func (p *planner) zoneConfigsSQL(ctx context.Context) ([]string, error) {
+	rows, _ /* cols */, err := p.ExtendedEvalContext().ExecCfg.InternalExecutor.Query(
+		ctx, "show-cluster-configuration-zones", p.txn,
+		`SELECT cli_specifier, config_yaml FROM crdb_internal.zones
+		 WHERE cli_specifier IS NOT NULL ORDER BY id, cli_specifier`)
+	if err != nil {
+		return nil, err
+	}
+	var stmts []string
+	for _, r := range rows {
+		zs, err := config.ParseCLIZoneSpecifier(string(tree.MustBeDString(r[0])))
+		if err != nil {
+			return nil, err
+		}
+		stmts = append(stmts, fmt.Sprintf("ALTER %s CONFIGURE ZONE = %s",
+			&zs, lex.EscapeSQLString(string(tree.MustBeDBytes(r[1])))))
+	}
+	return stmts, nil
+}
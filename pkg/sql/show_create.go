@@ -96,13 +96,24 @@ func (p *planner) showCreateView(
 	ctx context.Context, tn *tree.Name, desc *sqlbase.TableDescriptor,
 ) (string, error) {
 	f := tree.NewFmtCtxWithBuf(tree.FmtSimple)
-	f.WriteString("CREATE VIEW ")
+	if desc.MaterializedView() {
+		f.WriteString("CREATE MATERIALIZED VIEW ")
+	} else {
+		f.WriteString("CREATE VIEW ")
+	}
 	f.FormatNode(tn)
 	f.WriteString(" (")
+	first := true
 	for i := range desc.Columns {
-		if i > 0 {
+		// Materialized views have a hidden rowid column for their primary
+		// key, which isn't part of the view definition.
+		if desc.Columns[i].Hidden {
+			continue
+		}
+		if !first {
 			f.WriteString(", ")
 		}
+		first = false
 		f.FormatNameP(&desc.Columns[i].Name)
 	}
 	f.WriteString(") AS ")
@@ -237,6 +248,10 @@ func (p *planner) showCreateTable(
 			); err != nil {
 				return "", err
 			}
+			if idx.IsPartial() {
+				f.WriteString(" WHERE ")
+				f.WriteString(idx.Predicate)
+			}
 		}
 	}
 
@@ -0,0 +1,130 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+var showRangeLeaseHistoryColumns = sqlbase.ResultColumns{
+	{Name: "sequence", Typ: types.Int},
+	{Name: "node_id", Typ: types.Int},
+	{Name: "store_id", Typ: types.Int},
+	{Name: "start", Typ: types.Timestamp},
+	{Name: "expiration", Typ: types.Timestamp},
+	{Name: "epoch", Typ: types.Int},
+	{Name: "proposed", Typ: types.Timestamp},
+}
+
+// ShowRangeLeaseHistory returns a SHOW RANGE ... LEASE HISTORY statement.
+// The lease history is kept in memory by every replica of the range, so it's
+// collected from all the nodes and merged.
+// Privileges: superuser.
+func (p *planner) ShowRangeLeaseHistory(
+	ctx context.Context, n *tree.ShowRangeLeaseHistory,
+) (planNode, error) {
+	if err := p.RequireSuperUser(ctx, "SHOW RANGE LEASE HISTORY"); err != nil {
+		return nil, err
+	}
+	return &delayedNode{
+		name:    fmt.Sprintf("SHOW RANGE %d LEASE HISTORY", n.RangeID),
+		columns: showRangeLeaseHistoryColumns,
+
+		constructor: func(ctx context.Context, p *planner) (planNode, error) {
+			resp, err := p.ExecCfg().StatusServer.Range(ctx, &serverpb.RangeRequest{RangeId: n.RangeID})
+			if err != nil {
+				return nil, err
+			}
+
+			// The replicas usually remember the same leases, and an
+			// expiration-based lease shows up again every time it's extended,
+			// so the leases are deduplicated by holder, start and sequence.
+			type leaseKey struct {
+				storeID  roachpb.StoreID
+				start    hlc.Timestamp
+				sequence roachpb.LeaseSequence
+			}
+			var found bool
+			leases := make(map[leaseKey]roachpb.Lease)
+			for _, nodeResp := range resp.ResponsesByNodeID {
+				for _, info := range nodeResp.Infos {
+					found = true
+					for _, l := range info.LeaseHistory {
+						key := leaseKey{storeID: l.Replica.StoreID, start: l.Start, sequence: l.Sequence}
+						if prev, ok := leases[key]; ok && prev.Expiration != nil &&
+							(l.Expiration == nil || !prev.Expiration.Less(*l.Expiration)) {
+							continue
+						}
+						leases[key] = l
+					}
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("range %d not found", n.RangeID)
+			}
+
+			sorted := make([]roachpb.Lease, 0, len(leases))
+			for _, l := range leases {
+				sorted = append(sorted, l)
+			}
+			sort.Slice(sorted, func(i, j int) bool {
+				if sorted[i].Sequence != sorted[j].Sequence {
+					return sorted[i].Sequence < sorted[j].Sequence
+				}
+				return sorted[i].Start.Less(sorted[j].Start)
+			})
+
+			timestamp := func(ts *hlc.Timestamp) tree.Datum {
+				if ts == nil {
+					return tree.DNull
+				}
+				return tree.MakeDTimestamp(timeutil.Unix(0, ts.WallTime), time.Microsecond)
+			}
+			v := p.newContainerValuesNode(showRangeLeaseHistoryColumns, len(sorted))
+			for i := range sorted {
+				l := &sorted[i]
+				epoch := tree.DNull
+				if l.Type() == roachpb.LeaseEpoch {
+					epoch = tree.NewDInt(tree.DInt(l.Epoch))
+				}
+				row := tree.Datums{
+					tree.NewDInt(tree.DInt(l.Sequence)),
+					tree.NewDInt(tree.DInt(l.Replica.NodeID)),
+					tree.NewDInt(tree.DInt(l.Replica.StoreID)),
+					timestamp(&l.Start),
+					timestamp(l.Expiration),
+					epoch,
+					timestamp(l.ProposedTS),
+				}
+				if _, err := v.rows.AddRow(ctx, row); err != nil {
+					v.Close(ctx)
+					return nil, err
+				}
+			}
+			return v, nil
+		},
+	}, nil
+}
@@ -428,6 +428,9 @@ func (c *cascader) addRowUpdater(table *TableDescriptor) (RowUpdater, RowFetcher
 	if err != nil {
 		return RowUpdater{}, RowFetcher{}, err
 	}
+	if err := rowUpdater.setPartialIndexPredicates(c.evalCtx); err != nil {
+		return RowUpdater{}, RowFetcher{}, err
+	}
 
 	// Create the row fetcher that will retrive the rows and columns needed for
 	// deletion.
@@ -953,6 +956,10 @@ func (c *cascader) cascadeAll(
 	); err != nil {
 		return err
 	}
+	// The first element of the queue holds the rows modified by the statement
+	// itself; every subsequent element is the result of a cascade.
+	cascades := int64(-1)
+	limit := c.evalCtx.SessionData.ForeignKeyCascadesLimit
 	for {
 		select {
 		case <-ctx.Done():
@@ -963,6 +970,12 @@ func (c *cascader) cascadeAll(
 		if !exists {
 			break
 		}
+		cascades++
+		if limit > 0 && cascades > limit {
+			return pgerror.NewErrorf(pgerror.CodeTriggeredActionExceptionError,
+				"foreign key cascade limit (%d) exceeded", limit,
+			).SetHintf("the limit can be changed with SET foreign_key_cascades_limit")
+		}
 		for _, referencedIndex := range elem.table.AllNonDropIndexes() {
 			for _, ref := range referencedIndex.ReferencedBy {
 				referencingTable, ok := c.tablesByID[ref.Table]
@@ -24,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/pkg/errors"
 )
 
 // Cockroach error extensions:
@@ -36,6 +37,24 @@ const (
 	// CodeCCLRequired signals that a CCL binary is required to complete this
 	// task.
 	CodeCCLRequired = "XXC01"
+
+	// CodeLeaseUnavailable signals that a request could not be served because
+	// the lease of a range could not be found or acquired (e.g. because of an
+	// ongoing lease transfer or a partitioned leaseholder). Retrying the
+	// statement later may succeed.
+	CodeLeaseUnavailable = "XXC02"
+
+	// CodeKVMemoryBudgetExceeded signals that the evaluation of a request was
+	// aborted because it would have exceeded the memory budget of the store
+	// evaluating it. Retrying with smaller statements (e.g. with a LIMIT) or
+	// with less concurrency may succeed.
+	CodeKVMemoryBudgetExceeded = "XXC03"
+
+	// CodeWriteIntentConflict signals that a request ran into the write
+	// intents of another transaction and could not push it out of the way.
+	// Retrying the statement after the other transaction finishes may
+	// succeed.
+	CodeWriteIntentConflict = "XXC04"
 )
 
 const (
@@ -192,6 +211,33 @@ func NewRangeUnavailableError(
 		rangeID, nodeIDs, origErr)
 }
 
+// ConvertKVError converts the KV errors that have a stable error code to a
+// pgerror with that code, keeping the original message, so that clients can
+// tell them apart without parsing the message. Other errors are returned
+// unchanged.
+//
+// The codes are part of the interface with the clients and must not be
+// reassigned.
+func ConvertKVError(err error) error {
+	if _, ok := pgerror.GetPGCause(err); ok {
+		return err
+	}
+	var code string
+	switch errors.Cause(err).(type) {
+	case *roachpb.RangeNotFoundError, *roachpb.NodeUnavailableError, *roachpb.SendError:
+		code = CodeRangeUnavailable
+	case *roachpb.NotLeaseHolderError, *roachpb.LeaseRejectedError:
+		code = CodeLeaseUnavailable
+	case *roachpb.EvalMemoryBudgetExceededError:
+		code = CodeKVMemoryBudgetExceeded
+	case *roachpb.WriteIntentError:
+		code = CodeWriteIntentConflict
+	default:
+		return err
+	}
+	return pgerror.NewError(code, err.Error())
+}
+
 // NewWindowInAggError creates an error for the case when a window function is
 // nested within an aggregate function.
 func NewWindowInAggError() error {
@@ -268,6 +314,11 @@ func (f *singleKVFetcher) getRangesInfo() []roachpb.RangeInfo {
 	panic("getRangesInfo() called on singleKVFetcher")
 }
 
+// getKVStats implements the kvFetcher interface.
+func (f *singleKVFetcher) getKVStats() (keysRead, bytesRead int64) {
+	return 0, 0
+}
+
 // ConvertBatchError returns a user friendly constraint violation error.
 func ConvertBatchError(ctx context.Context, tableDesc *TableDescriptor, b *client.Batch) error {
 	origPErr := b.MustPErr()
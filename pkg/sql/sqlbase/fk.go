@@ -288,6 +288,11 @@ func (f *SpanKVFetcher) getRangesInfo() []roachpb.RangeInfo {
 	panic("getRangesInfo() called on SpanKVFetcher")
 }
 
+// getKVStats implements the kvFetcher interface.
+func (f *SpanKVFetcher) getKVStats() (keysRead, bytesRead int64) {
+	return 0, 0
+}
+
 // fkBatchChecker accumulates foreign key checks and sends them out as a single
 // kv batch on demand. Checks are accumulated in order - the first failing check
 // will be the one that produces an error report.
@@ -26,6 +26,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 )
 
 // IndexKeyValDirs returns the corresponding encoding.Directions for all the
@@ -139,14 +141,28 @@ func SetKVBatchSize(val int64) func() {
 	return func() { kvBatchSize = oldVal }
 }
 
+// kvBatchTargetBytes is the target size of the rows we request at a time. It
+// bounds the memory used by each batch when rows are large, regardless of
+// kvBatchSize. Like kvBatchSize, it only applies to limited batches.
+var kvBatchTargetBytes int64 = 10 << 20 // 10 MB
+
+// SetKVBatchTargetBytes changes the kvFetcher batch target size, and returns a
+// function that restores it.
+func SetKVBatchTargetBytes(val int64) func() {
+	oldVal := kvBatchTargetBytes
+	kvBatchTargetBytes = val
+	return func() { kvBatchTargetBytes = oldVal }
+}
+
 // txnKVFetcher handles retrieval of key/values.
 type txnKVFetcher struct {
 	// "Constant" fields, provided by the caller.
 	txn   *client.Txn
 	spans roachpb.Spans
-	// If useBatchLimit is true, batches are limited to kvBatchSize. If
-	// firstBatchLimit is also set, the first batch is limited to that value.
-	// Subsequent batches are larger, up to kvBatchSize.
+	// If useBatchLimit is true, batches are limited to kvBatchSize keys and
+	// kvBatchTargetBytes bytes. If firstBatchLimit is also set, the first batch
+	// is limited to that many keys. Subsequent batches are larger, up to
+	// kvBatchSize.
 	firstBatchLimit int64
 	useBatchLimit   bool
 	reverse         bool
@@ -154,12 +170,20 @@ type txnKVFetcher struct {
 	// See also rowFetcher.returnRangeInfo.
 	returnRangeInfo bool
 
+	// If streamPartialResponses is set, the rows of the ranges that a batch
+	// spans are returned as they arrive, before the whole batch is done. See
+	// partialBatch.
+	streamPartialResponses bool
+
 	fetchEnd  bool
 	batchIdx  int
 	responses []roachpb.ResponseUnion
+	// partial is set while a batch whose partial responses are streamed is in
+	// flight.
+	partial *partialBatch
 
-	// Keep track if the previous batch was limited. Used to calculate if
-	// the currentSpan is a new span.
+	// Keep track of whether the last span we returned rows for needs to be
+	// resumed. Used to calculate if the currentSpan is a new span.
 	lastBatchLimited bool
 
 	// As the kvFetcher fetches batches of kvs, it accumulates information on the
@@ -168,6 +192,11 @@ type txnKVFetcher struct {
 	// rangeInfos are deduped, so they're not ordered in any particular way and
 	// they don't map to kvFetcher.spans in any particular way.
 	rangeInfos []roachpb.RangeInfo
+
+	// keysRead and bytesRead accumulate the work that the KV layer reported
+	// doing for the batches sent by the kvFetcher.
+	keysRead  int64
+	bytesRead int64
 }
 
 func (f *txnKVFetcher) getRangesInfo() []roachpb.RangeInfo {
@@ -177,6 +206,10 @@ func (f *txnKVFetcher) getRangesInfo() []roachpb.RangeInfo {
 	return f.rangeInfos
 }
 
+func (f *txnKVFetcher) getKVStats() (keysRead, bytesRead int64) {
+	return f.keysRead, f.bytesRead
+}
+
 // getBatchSize returns the max size of the next batch.
 func (f *txnKVFetcher) getBatchSize() int64 {
 	return f.getBatchSizeForIdx(f.batchIdx)
@@ -225,9 +258,10 @@ func (f *txnKVFetcher) getBatchSizeForIdx(batchIdx int) int64 {
 
 // makeKVFetcher initializes a kvFetcher for the given spans.
 //
-// If useBatchLimit is true, batches are limited to kvBatchSize. If
-// firstBatchLimit is also set, the first batch is limited to that value.
-// Subsequent batches are larger, up to kvBatchSize.
+// If useBatchLimit is true, batches are limited to kvBatchSize keys and
+// kvBatchTargetBytes bytes. If firstBatchLimit is also set, the first batch is
+// limited to that many keys. Subsequent batches are larger, up to
+// kvBatchSize.
 //
 // Batch limits can only be used if the spans are ordered.
 func makeKVFetcher(
@@ -252,6 +286,14 @@ func makeKVFetcher(
 		}
 	}
 
+	// Batches without limits are sent to all of their ranges in parallel, so
+	// their rows are streamed to the caller as the ranges respond. That's
+	// only done if the spans are ordered: the partial responses arrive in the
+	// order of the ranges, which only matches the order of the spans if the
+	// latter are ordered too. Limited batches are sent one range at a time,
+	// so there would be nothing to gain.
+	streamPartialResponses := !useBatchLimit && spansOrdered(spans)
+
 	// Make a copy of the spans because we update them.
 	copySpans := make(roachpb.Spans, len(spans))
 	for i := range spans {
@@ -270,13 +312,137 @@ func makeKVFetcher(
 		useBatchLimit:   useBatchLimit,
 		firstBatchLimit: firstBatchLimit,
 		returnRangeInfo: returnRangeInfo,
+
+		streamPartialResponses: streamPartialResponses,
 	}, nil
 }
 
+// spansOrdered returns whether the given spans are ordered and don't overlap.
+func spansOrdered(spans roachpb.Spans) bool {
+	for i := 1; i < len(spans); i++ {
+		prev := spans[i-1]
+		if spans[i].Key.Compare(prev.Key) <= 0 || spans[i].Key.Compare(prev.EndKey) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// partialBatch collects the partial responses of a batch that is sent in the
+// background by the txnKVFetcher, so that the rows of the first ranges the
+// batch spans can be returned before the rows of the last ones arrive.
+type partialBatch struct {
+	// notify is signaled whenever rows are added or the batch is restarted.
+	notify chan struct{}
+	// done receives the result of the batch once it is done.
+	done chan partialBatchResult
+	// res is the result of the batch, once it was received from done.
+	res *partialBatchResult
+	// returnedRows is set once rows of the batch were returned by the
+	// txnKVFetcher.
+	returnedRows bool
+
+	mu struct {
+		syncutil.Mutex
+		// rows holds the rows of the partial responses that haven't been
+		// returned yet, one slice per scan.
+		rows [][]roachpb.KeyValue
+		// consumed is set once rows were taken from rows.
+		consumed bool
+		// restarted is set if the batch was sent anew after rows were
+		// consumed.
+		restarted bool
+	}
+}
+
+type partialBatchResult struct {
+	br   *roachpb.BatchResponse
+	pErr *roachpb.Error
+}
+
+// handle is the client.PartialResponseHandler of the batch.
+func (p *partialBatch) handle(br *roachpb.BatchResponse, _ []int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if br == nil {
+		// The batch is being sent anew, so the rows of the previous attempt
+		// must be discarded. Rows which were already returned can't be taken
+		// back, though.
+		p.mu.rows = nil
+		p.mu.restarted = p.mu.consumed
+	} else {
+		for _, resp := range br.Responses {
+			var rows []roachpb.KeyValue
+			switch t := resp.GetInner().(type) {
+			case *roachpb.ScanResponse:
+				rows = t.Rows
+			case *roachpb.ReverseScanResponse:
+				rows = t.Rows
+			}
+			if len(rows) > 0 {
+				p.mu.rows = append(p.mu.rows, rows)
+			}
+		}
+	}
+	select {
+	case p.notify <- struct{}{}:
+	default:
+	}
+}
+
+// next returns the next rows of the batch, if any have arrived, or whether
+// the batch was restarted after rows were returned.
+func (p *partialBatch) next() (rows []roachpb.KeyValue, restarted bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.mu.restarted {
+		return nil, true
+	}
+	if len(p.mu.rows) == 0 {
+		return nil, false
+	}
+	rows = p.mu.rows[0]
+	p.mu.rows = p.mu.rows[1:]
+	p.mu.consumed = true
+	return rows, false
+}
+
+// wait blocks until more rows arrive, the batch is restarted or the batch is
+// done.
+func (p *partialBatch) wait() {
+	if p.res != nil {
+		return
+	}
+	select {
+	case <-p.notify:
+	case res := <-p.done:
+		p.res = &res
+	}
+}
+
+// result blocks until the batch is done and returns its result.
+func (p *partialBatch) result() partialBatchResult {
+	if p.res == nil {
+		res := <-p.done
+		p.res = &res
+	}
+	return *p.res
+}
+
 // fetch retrieves spans from the kv
 func (f *txnKVFetcher) fetch(ctx context.Context) error {
 	var ba roachpb.BatchRequest
 	ba.Header.MaxSpanRequestKeys = f.getBatchSize()
+	if f.useBatchLimit {
+		ba.Header.TargetBytes = kvBatchTargetBytes
+		// Let the KV layer know how long the scans are expected to be, so
+		// that it can read ahead for long ones. When the planner provided a
+		// limit hint the first batch is expected to be short; otherwise (and
+		// once the hint turned out to be too small) the scans are expected to
+		// fill up the batch. Without batch limits, which are disabled for
+		// index and lookup joins, the scans are usually point lookups.
+		ba.Header.ExpectedScanKeys = ba.Header.MaxSpanRequestKeys
+	}
 	ba.Header.ReturnRangeInfo = f.returnRangeInfo
 	ba.Requests = make([]roachpb.RequestUnion, len(f.spans))
 	if f.reverse {
@@ -307,14 +473,40 @@ func (f *txnKVFetcher) fetch(ctx context.Context) error {
 	// Reset spans in preparation for adding resume-spans below.
 	f.spans = f.spans[:0]
 
+	if f.streamPartialResponses {
+		p := &partialBatch{
+			notify: make(chan struct{}, 1),
+			done:   make(chan partialBatchResult, 1),
+		}
+		f.partial = p
+		// The batch is sent in its own span since the caller might stop
+		// reading before the batch is done.
+		sendCtx, sp := tracing.ForkCtxSpan(ctx, "kv fetch")
+		sendCtx = client.WithPartialResponseHandler(sendCtx, p.handle)
+		go func() {
+			defer tracing.FinishSpan(sp)
+			br, pErr := f.txn.Send(sendCtx, ba)
+			p.done <- partialBatchResult{br: br, pErr: pErr}
+		}()
+		return nil
+	}
+
 	br, err := f.txn.Send(ctx, ba)
 	if err != nil {
 		return err.GoError()
 	}
+	return f.processResponse(br, false /* streamed */)
+}
+
+// processResponse processes the response of the batch sent by fetch. If
+// streamed is set, the rows of the response were already returned as partial
+// responses, and only its headers are processed.
+func (f *txnKVFetcher) processResponse(br *roachpb.BatchResponse, streamed bool) error {
+	f.responses = nil
 	if br != nil {
 		f.responses = br.Responses
-	} else {
-		f.responses = nil
+		f.keysRead += br.KeysRead
+		f.bytesRead += br.BytesRead
 	}
 
 	// Set end to true until disproved.
@@ -345,6 +537,13 @@ func (f *txnKVFetcher) fetch(ctx context.Context) error {
 				f.rangeInfos = roachpb.InsertRangeInfo(f.rangeInfos, ri)
 			}
 		}
+
+		if streamed {
+			f.lastBatchLimited = f.batchIsLimited(int(header.NumKeys), header.ResumeSpan)
+		}
+	}
+	if streamed {
+		f.responses = nil
 	}
 
 	f.batchIdx++
@@ -355,12 +554,15 @@ func (f *txnKVFetcher) fetch(ctx context.Context) error {
 	return nil
 }
 
-func (f *txnKVFetcher) batchIsLimited(batchSize int) bool {
-	if f.useBatchLimit {
-		// f.batchIdx - 1 refers to the most recent fetch.
-		return int64(batchSize) == f.getBatchSizeForIdx(f.batchIdx-1)
+// batchIsLimited returns whether the span of the most recent rows returned
+// needs to be resumed, whether because of the key limit or the byte target.
+// A response without rows and with a resume span belongs to a span which
+// wasn't scanned at all, so it doesn't change the answer.
+func (f *txnKVFetcher) batchIsLimited(numRows int, resumeSpan *roachpb.Span) bool {
+	if numRows == 0 && resumeSpan != nil {
+		return f.lastBatchLimited
 	}
-	return false
+	return resumeSpan != nil
 }
 
 // nextBatch returns the next batch of key/value pairs. If there are none
@@ -371,6 +573,9 @@ func (f *txnKVFetcher) batchIsLimited(batchSize int) bool {
 func (f *txnKVFetcher) nextBatch(
 	ctx context.Context,
 ) (ok bool, kvs []roachpb.KeyValue, maybeNewSpan bool, err error) {
+	if f.partial != nil {
+		return f.nextPartialBatch(ctx)
+	}
 	if len(f.responses) > 0 {
 		reply := f.responses[0].GetInner()
 		f.responses = f.responses[1:]
@@ -378,10 +583,10 @@ func (f *txnKVFetcher) nextBatch(
 		maybeNewSpan = !f.lastBatchLimited
 		switch t := reply.(type) {
 		case *roachpb.ScanResponse:
-			f.lastBatchLimited = f.batchIsLimited(len(t.Rows))
+			f.lastBatchLimited = f.batchIsLimited(len(t.Rows), t.ResumeSpan)
 			return true, t.Rows, maybeNewSpan, nil
 		case *roachpb.ReverseScanResponse:
-			f.lastBatchLimited = f.batchIsLimited(len(t.Rows))
+			f.lastBatchLimited = f.batchIsLimited(len(t.Rows), t.ResumeSpan)
 			return true, t.Rows, maybeNewSpan, nil
 		}
 	}
@@ -393,3 +598,63 @@ func (f *txnKVFetcher) nextBatch(
 	}
 	return f.nextBatch(ctx)
 }
+
+// nextPartialBatch is like nextBatch, for when a batch whose partial responses
+// are streamed is in flight. It returns the rows of the partial responses as
+// they arrive, and processes the response of the batch once it is done.
+func (f *txnKVFetcher) nextPartialBatch(
+	ctx context.Context,
+) (ok bool, kvs []roachpb.KeyValue, maybeNewSpan bool, err error) {
+	p := f.partial
+	for {
+		rows, restarted := p.next()
+		if restarted {
+			f.partial = nil
+			return false, nil, false, f.restartedBatchError(ctx, p.result())
+		}
+		if rows != nil {
+			// Like in nextBatch, assume a new span is started as long as the
+			// last one wasn't limited. Rows of different scans are never
+			// returned together, so that only matters for the first rows.
+			maybeNewSpan = p.returnedRows || !f.lastBatchLimited
+			p.returnedRows = true
+			return true, rows, maybeNewSpan, nil
+		}
+		if p.res != nil {
+			// The batch is done and all of its partial responses were
+			// returned.
+			f.partial = nil
+			if p.res.pErr != nil {
+				return false, nil, false, p.res.pErr.GoError()
+			}
+			if err := f.processResponse(p.res.br, p.returnedRows); err != nil {
+				return false, nil, false, err
+			}
+			return f.nextBatch(ctx)
+		}
+		p.wait()
+	}
+}
+
+// restartedBatchError returns the error for a batch whose partial responses
+// were returned before it was sent anew, for example because the transaction
+// refreshed its reads at a higher timestamp. The rows which were returned
+// might not be valid at the timestamp at which the batch was retried, so the
+// transaction has to restart.
+func (f *txnKVFetcher) restartedBatchError(ctx context.Context, res partialBatchResult) error {
+	if res.pErr != nil {
+		return res.pErr.GoError()
+	}
+	txn := f.txn.Proto()
+	if res.br != nil && res.br.Txn != nil {
+		txn = res.br.Txn
+	}
+	pErr := roachpb.NewErrorWithTxn(
+		roachpb.NewTransactionRetryError(roachpb.RETRY_REASON_UNKNOWN), txn)
+	if f.txn.Type() == client.RootTxn {
+		return f.txn.UpdateStateOnRemoteRetryableErr(ctx, pErr)
+	}
+	// Leaf transactions can't restart; the error is forwarded to the root
+	// like any other retryable error.
+	return pErr.GoError()
+}
@@ -0,0 +1,99 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sqlbase
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// TestPartialBatch verifies that the rows of the partial responses of a batch
+// are discarded when the batch is sent anew, and that a restart is reported
+// once rows were returned.
+func TestPartialBatch(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	scanResponse := func(keys ...string) *roachpb.BatchResponse {
+		var scan roachpb.ScanResponse
+		for _, k := range keys {
+			scan.Rows = append(scan.Rows, roachpb.KeyValue{Key: roachpb.Key(k)})
+		}
+		br := &roachpb.BatchResponse{}
+		br.Add(&scan)
+		return br
+	}
+	nextKey := func(p *partialBatch) string {
+		rows, restarted := p.next()
+		if restarted {
+			return "restarted"
+		}
+		if rows == nil {
+			return "none"
+		}
+		return string(rows[0].Key)
+	}
+
+	p := &partialBatch{notify: make(chan struct{}, 1)}
+	p.handle(nil, nil)
+	p.handle(scanResponse("a"), []int{0})
+	p.handle(scanResponse(), []int{0})
+	// The batch is retried before any rows were returned: the rows of the
+	// first attempt are discarded.
+	p.handle(nil, nil)
+	if k := nextKey(p); k != "none" {
+		t.Fatalf("expected no rows after the batch was sent anew, got %s", k)
+	}
+	p.handle(scanResponse("b"), []int{0})
+	p.handle(scanResponse("c"), []int{0})
+	if k := nextKey(p); k != "b" {
+		t.Fatalf("expected b, got %s", k)
+	}
+	// The batch is retried after rows were returned, which can't be undone.
+	p.handle(nil, nil)
+	p.handle(scanResponse("c"), []int{0})
+	if k := nextKey(p); k != "restarted" {
+		t.Fatalf("expected the batch to be restarted, got %s", k)
+	}
+}
+
+func TestSpansOrdered(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	span := func(key, endKey string) roachpb.Span {
+		s := roachpb.Span{Key: roachpb.Key(key)}
+		if endKey != "" {
+			s.EndKey = roachpb.Key(endKey)
+		}
+		return s
+	}
+	testCases := []struct {
+		spans   roachpb.Spans
+		ordered bool
+	}{
+		{roachpb.Spans{}, true},
+		{roachpb.Spans{span("a", "c"), span("c", "d")}, true},
+		{roachpb.Spans{span("a", ""), span("b", ""), span("c", "e")}, true},
+		{roachpb.Spans{span("a", "c"), span("b", "d")}, false},
+		{roachpb.Spans{span("c", ""), span("b", "")}, false},
+		{roachpb.Spans{span("a", ""), span("a", "")}, false},
+	}
+	for _, tc := range testCases {
+		if ordered := spansOrdered(tc.spans); ordered != tc.ordered {
+			t.Errorf("%s: expected ordered=%t, got %t", tc.spans, tc.ordered, ordered)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sqlbase
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+)
+
+// IsPartial returns whether the index is a partial index, which only contains
+// the rows that satisfy its predicate.
+func (desc *IndexDescriptor) IsPartial() bool {
+	return desc.Predicate != ""
+}
+
+// PredicateUsesColumn returns whether the predicate of the partial index
+// references the column with the given name.
+func (desc *IndexDescriptor) PredicateUsesColumn(colName string) (bool, error) {
+	if !desc.IsPartial() {
+		return false, nil
+	}
+	parsed, err := parser.ParseExpr(desc.Predicate)
+	if err != nil {
+		return false, errors.Wrapf(err, "could not parse index predicate %s", desc.Predicate)
+	}
+	used := false
+	visitFn := func(expr tree.Expr) (err error, recurse bool, newExpr tree.Expr) {
+		if vBase, ok := expr.(tree.VarName); ok {
+			v, err := vBase.NormalizeVarName()
+			if err != nil {
+				return err, false, nil
+			}
+			if c, ok := v.(*tree.ColumnItem); ok && string(c.ColumnName) == colName {
+				used = true
+			}
+			return nil, false, v
+		}
+		return nil, true, expr
+	}
+	if _, err := tree.SimpleVisit(parsed, visitFn); err != nil {
+		return false, err
+	}
+	return used, nil
+}
+
+// PartialIndexPredicates evaluates the predicates of partial indexes, to
+// determine which rows of the table are part of them.
+type PartialIndexPredicates struct {
+	evalCtx *tree.EvalContext
+	exprs   map[IndexID]tree.TypedExpr
+	iv      RowIndexedVarContainer
+}
+
+// MakePartialIndexPredicates parses and type checks the predicates of the
+// partial indexes among the given indexes of the table. It returns nil if none
+// of them is partial.
+func MakePartialIndexPredicates(
+	evalCtx *tree.EvalContext, tableDesc *TableDescriptor, indexes []IndexDescriptor,
+) (*PartialIndexPredicates, error) {
+	var ids []IndexID
+	var exprStrings []string
+	for i := range indexes {
+		if indexes[i].IsPartial() {
+			ids = append(ids, indexes[i].ID)
+			exprStrings = append(exprStrings, indexes[i].Predicate)
+		}
+	}
+	if len(exprStrings) == 0 {
+		return nil, nil
+	}
+	exprs, err := parser.ParseExprs(exprStrings)
+	if err != nil {
+		return nil, err
+	}
+
+	// As for computed columns, the names in the predicates only need to be
+	// resolved to typecheck them; they are evaluated over the rows of the
+	// RowIndexedVarContainer.
+	iv := &descContainer{tableDesc.Columns}
+	ivarHelper := tree.MakeIndexedVarHelper(iv, len(tableDesc.Columns))
+	tn := tree.MakeUnqualifiedTableName(tree.Name(tableDesc.Name))
+	sourceInfo := NewSourceInfoForSingleTable(
+		tn, ResultColumnsFromColDescs(tableDesc.Columns),
+	)
+	semaCtx := tree.MakeSemaContext(false)
+	semaCtx.IVarContainer = iv
+
+	p := &PartialIndexPredicates{
+		evalCtx: evalCtx,
+		exprs:   make(map[IndexID]tree.TypedExpr, len(exprs)),
+		iv:      RowIndexedVarContainer{Cols: tableDesc.Columns},
+	}
+	for i, expr := range exprs {
+		expr, _, _, err := ResolveNames(expr,
+			MakeMultiSourceInfo(sourceInfo),
+			ivarHelper, evalCtx.SessionData.SearchPath)
+		if err != nil {
+			return nil, err
+		}
+		typedExpr, err := tree.TypeCheck(expr, &semaCtx, types.Bool)
+		if err != nil {
+			return nil, err
+		}
+		p.exprs[ids[i]] = typedExpr
+	}
+	return p, nil
+}
+
+// Includes returns whether the row with the given values, whose positions
+// are given by colMap, is part of the index. Rows are part of all the indexes
+// that aren't partial. A nil PartialIndexPredicates includes all the rows in
+// all the indexes.
+func (p *PartialIndexPredicates) Includes(
+	index *IndexDescriptor, colMap map[ColumnID]int, values tree.Datums,
+) (bool, error) {
+	if p == nil {
+		return true, nil
+	}
+	expr, ok := p.exprs[index.ID]
+	if !ok {
+		return true, nil
+	}
+	p.iv.Mapping = colMap
+	p.iv.CurSourceRow = values
+	p.evalCtx.PushIVarContainer(&p.iv)
+	d, err := expr.Eval(p.evalCtx)
+	p.evalCtx.PopIVarContainer()
+	if err != nil {
+		return false, err
+	}
+	if d == tree.DNull {
+		return false, nil
+	}
+	b, err := tree.GetBool(d)
+	return bool(b), err
+}
@@ -113,6 +113,14 @@ var ExplainDistSQLColumns = ResultColumns{
 	{Name: "JSON", Typ: types.String, Hidden: true},
 }
 
+// ExplainDebugColumns are the result columns of an
+// EXPLAIN ANALYZE (DEBUG) statement. Each row is a file of the bundle.
+var ExplainDebugColumns = ResultColumns{
+	{Name: "node_id", Typ: types.Int},
+	{Name: "file", Typ: types.String},
+	{Name: "contents", Typ: types.Bytes},
+}
+
 // ExplainOptColumns are the result columns of an
 // EXPLAIN (OPT) statement.
 var ExplainOptColumns = ResultColumns{
@@ -40,6 +40,7 @@ const debugRowFetch = false
 type kvFetcher interface {
 	nextBatch(ctx context.Context) (bool, []roachpb.KeyValue, bool, error)
 	getRangesInfo() []roachpb.RangeInfo
+	getKVStats() (keysRead, bytesRead int64)
 }
 
 type tableInfo struct {
@@ -1268,6 +1269,15 @@ func (rf *RowFetcher) GetRangeInfo() []roachpb.RangeInfo {
 	return rf.kvFetcher.getRangesInfo()
 }
 
+// GetKVStats returns the number of keys and bytes the KV layer reported
+// reading on behalf of the scans so far.
+func (rf *RowFetcher) GetKVStats() (keysRead, bytesRead int64) {
+	if rf.kvFetcher == nil {
+		return 0, 0
+	}
+	return rf.kvFetcher.getKVStats()
+}
+
 // Only unique secondary indexes have extra columns to decode (namely the
 // primary index columns).
 func hasExtraCols(table *tableInfo) bool {
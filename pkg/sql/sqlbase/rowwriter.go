@@ -47,6 +47,12 @@ type rowHelper struct {
 	Indexes      []IndexDescriptor
 	indexEntries []IndexEntry
 
+	// partialIndexPreds evaluates the predicates of the partial indexes among
+	// Indexes. If it's nil, the rows are written to the partial indexes
+	// regardless of their predicates, which is inefficient but correct as
+	// the rows are always removed from them.
+	partialIndexPreds *PartialIndexPredicates
+
 	// Computed during initialization for pretty-printing.
 	primIndexValDirs []encoding.Direction
 	secIndexValDirs  [][]encoding.Direction
@@ -72,6 +78,25 @@ func newRowHelper(desc *TableDescriptor, indexes []IndexDescriptor) rowHelper {
 	return rh
 }
 
+// setPartialIndexPredicates prepares the evaluation of the predicates of the
+// partial indexes among rh.Indexes.
+func (rh *rowHelper) setPartialIndexPredicates(evalCtx *tree.EvalContext) error {
+	var err error
+	rh.partialIndexPreds, err = MakePartialIndexPredicates(evalCtx, rh.TableDesc, rh.Indexes)
+	return err
+}
+
+// includesRow returns whether the row with the given values is part of the
+// i-th secondary index, which is always the case unless it's a partial index.
+func (rh *rowHelper) includesRow(
+	i int, colIDtoRowIndex map[ColumnID]int, values []tree.Datum,
+) (bool, error) {
+	if !rh.Indexes[i].IsPartial() {
+		return true, nil
+	}
+	return rh.partialIndexPreds.Includes(&rh.Indexes[i], colIDtoRowIndex, values)
+}
+
 // encodeIndexes encodes the primary and secondary index keys. The
 // secondaryIndexEntries are only valid until the next call to encodeIndexes or
 // encodeSecondaryIndexes.
@@ -213,6 +238,12 @@ func MakeRowInserter(
 	return ri, nil
 }
 
+// SetPartialIndexPredicates makes the RowInserter only write the rows that
+// satisfy the predicates of the partial indexes to them.
+func (ri *RowInserter) SetPartialIndexPredicates(evalCtx *tree.EvalContext) error {
+	return ri.Helper.setPartialIndexPredicates(evalCtx)
+}
+
 // insertCPutFn is used by insertRow when conflicts (i.e. the key already exists)
 // should generate errors.
 func insertCPutFn(
@@ -314,6 +345,15 @@ func (ri *RowInserter) InsertRow(
 
 	putFn = insertInvertedPutFn
 	for i := range secondaryIndexEntries {
+		// Partial indexes are never inverted, so they don't have any of the
+		// extra entries at the end.
+		if i < len(ri.Helper.Indexes) {
+			if ok, err := ri.Helper.includesRow(i, ri.InsertColIDtoRowIndex, values); err != nil {
+				return err
+			} else if !ok {
+				continue
+			}
+		}
 		e := &secondaryIndexEntries[i]
 		putFn(ctx, b, &e.Key, &e.Value, traceKV)
 	}
@@ -530,6 +570,9 @@ func MakeRowUpdater(
 	if err != nil {
 		return RowUpdater{}, err
 	}
+	if err := rowUpdater.setPartialIndexPredicates(evalCtx); err != nil {
+		return RowUpdater{}, err
+	}
 	rowUpdater.cascader, err = makeUpdateCascader(
 		txn, tableDesc, fkTables, updateCols, evalCtx, alloc,
 	)
@@ -575,6 +618,10 @@ func makeRowUpdaterWithoutCascader(
 		if primaryKeyColChange {
 			return true
 		}
+		// Any column can be referenced by the predicate of a partial index.
+		if index.IsPartial() {
+			return true
+		}
 		return index.RunOverAllColumns(func(id ColumnID) error {
 			if _, ok := updateColIDtoRowIndex[id]; ok {
 				return returnTruePseudoError
@@ -689,6 +736,14 @@ func makeRowUpdaterWithoutCascader(
 			if err := index.RunOverAllColumns(maybeAddCol); err != nil {
 				return RowUpdater{}, err
 			}
+			if index.IsPartial() {
+				// Evaluating the predicate may require any of the columns.
+				for _, col := range tableDesc.Columns {
+					if err := maybeAddCol(col.ID); err != nil {
+						return RowUpdater{}, err
+					}
+				}
+			}
 		}
 		for _, index := range deleteOnlyIndexes {
 			if err := index.RunOverAllColumns(maybeAddCol); err != nil {
@@ -705,6 +760,18 @@ func makeRowUpdaterWithoutCascader(
 	return ru, nil
 }
 
+// setPartialIndexPredicates makes the RowUpdater only write the rows that
+// satisfy the predicates of the partial indexes to them.
+func (ru *RowUpdater) setPartialIndexPredicates(evalCtx *tree.EvalContext) error {
+	if err := ru.Helper.setPartialIndexPredicates(evalCtx); err != nil {
+		return err
+	}
+	if ru.primaryKeyColChange {
+		return ru.ri.SetPartialIndexPredicates(evalCtx)
+	}
+	return nil
+}
+
 // UpdateRow adds to the batch the kv operations necessary to update a table row
 // with the given values.
 //
@@ -851,6 +918,37 @@ func (ru *RowUpdater) UpdateRow(
 			continue
 		}
 
+		if index.IsPartial() {
+			oldIncluded, err := ru.Helper.includesRow(i, ru.FetchColIDtoRowIndex, oldValues)
+			if err != nil {
+				return nil, err
+			}
+			newIncluded, err := ru.Helper.includesRow(i, ru.FetchColIDtoRowIndex, ru.newValues)
+			if err != nil {
+				return nil, err
+			}
+			sameKey := bytes.Equal(newSecondaryIndexEntry.Key, oldSecondaryIndexEntry.Key)
+			if oldIncluded && newIncluded && sameKey &&
+				newSecondaryIndexEntry.Value.EqualData(oldSecondaryIndexEntry.Value) {
+				continue
+			}
+			// The old entry is removed even if the old row doesn't satisfy the
+			// predicate, in case it was written without evaluating it.
+			if !newIncluded || !sameKey {
+				if traceKV {
+					log.VEventf(ctx, 2, "Del %s", keys.PrettyPrint(ru.Helper.secIndexValDirs[i], oldSecondaryIndexEntry.Key))
+				}
+				batch.Del(oldSecondaryIndexEntry.Key)
+			}
+			if newIncluded {
+				if traceKV {
+					log.VEventf(ctx, 2, "Put %s -> %v", keys.PrettyPrint(ru.Helper.secIndexValDirs[i], newSecondaryIndexEntry.Key), newSecondaryIndexEntry.Value.PrettyPrint())
+				}
+				batch.Put(newSecondaryIndexEntry.Key, &newSecondaryIndexEntry.Value)
+			}
+			continue
+		}
+
 		var expValue interface{}
 		if !bytes.Equal(newSecondaryIndexEntry.Key, oldSecondaryIndexEntry.Key) {
 			ru.Fks.addCheckForIndex(ru.Helper.Indexes[i].ID, ru.Helper.Indexes[i].Type)
@@ -80,6 +80,22 @@ type FamilyID uint32
 // IndexID is a custom type for IndexDescriptor IDs.
 type IndexID tree.IndexID
 
+// IndexDescriptorEncodingType is a custom type for the encoding of an
+// IndexDescriptor's entries.
+type IndexDescriptorEncodingType uint32
+
+const (
+	// SecondaryIndexEncoding is the encoding of secondary indexes: the index
+	// columns followed by the extra columns in the key, and the stored columns
+	// in the value.
+	SecondaryIndexEncoding IndexDescriptorEncodingType = iota
+	// PrimaryIndexEncoding is the encoding of the primary index: the index
+	// columns in the key, and all other columns in the value. It is used for
+	// the index built to replace the primary index during a primary key
+	// change, and for the replaced primary index while it is being dropped.
+	PrimaryIndexEncoding
+)
+
 // DescriptorVersion is a custom type for TableDescriptor Versions.
 type DescriptorVersion uint32
 
@@ -110,6 +126,10 @@ const InvalidMutationID MutationID = 0
 const (
 	// PrimaryKeyIndexName is the name of the index for the primary key.
 	PrimaryKeyIndexName = "primary"
+
+	// PrimaryKeyChangeIndexSuffix is appended to the names of the indexes
+	// built by a primary key change until they replace the existing indexes.
+	PrimaryKeyChangeIndexSuffix = "_rewrite_for_primary_key_change"
 )
 
 // ErrMissingColumns indicates a table with no columns.
@@ -377,6 +397,12 @@ func (desc *TableDescriptor) IsView() bool {
 	return desc.ViewQuery != ""
 }
 
+// MaterializedView returns true if the TableDescriptor describes a
+// materialized view, whose query results are stored like a table's rows.
+func (desc *TableDescriptor) MaterializedView() bool {
+	return desc.IsView() && desc.IsMaterializedView
+}
+
 // IsSequence returns true if the TableDescriptor actually describes a
 // Sequence resource rather than a Table.
 func (desc *TableDescriptor) IsSequence() bool {
@@ -394,10 +420,11 @@ func (desc *TableDescriptor) IsVirtualTable() bool {
 // physical Table that needs to be stored in the kv layer, as opposed to a
 // different resource like a view or a virtual table. Physical tables have
 // primary keys, column families, and indexes (unlike virtual tables).
-// Sequences count as physical tables because their values are stored in
-// the KV layer.
+// Sequences and materialized views count as physical tables because their
+// values are stored in the KV layer.
 func (desc *TableDescriptor) IsPhysicalTable() bool {
-	return desc.IsSequence() || (desc.IsTable() && !desc.IsVirtualTable())
+	return desc.IsSequence() || desc.MaterializedView() ||
+		(desc.IsTable() && !desc.IsVirtualTable())
 }
 
 // KeysPerRow returns the maximum number of keys used to encode a row for the
@@ -684,9 +711,17 @@ func (desc *TableDescriptor) allocateIndexIDs(columnNames map[string]ColumnID) e
 	for i := range desc.Indexes {
 		collectIndexes(&desc.Indexes[i])
 	}
+	// During a primary key change, the indexes added along with or after the
+	// index replacing the primary index are keyed on its columns.
+	newPrimaryIndex, primaryKeyChangeMutationID := desc.PendingPrimaryIndex()
+	keyedOnNewPrimaryIndex := make(map[*IndexDescriptor]struct{})
 	for _, m := range desc.Mutations {
 		if index := m.GetIndex(); index != nil {
 			collectIndexes(index)
+			if newPrimaryIndex != nil && m.Direction == DescriptorMutation_ADD &&
+				m.MutationID >= primaryKeyChangeMutationID {
+				keyedOnNewPrimaryIndex[index] = struct{}{}
+			}
 		}
 	}
 
@@ -716,14 +751,20 @@ func (desc *TableDescriptor) allocateIndexIDs(columnNames map[string]ColumnID) e
 			}
 		}
 
-		if index != &desc.PrimaryIndex {
+		// Indexes with the primary index encoding store all the other columns
+		// and have no extra columns; these are set when the index is created.
+		if index != &desc.PrimaryIndex && index.EncodingType != PrimaryIndexEncoding {
+			primaryIndex := &desc.PrimaryIndex
+			if _, ok := keyedOnNewPrimaryIndex[index]; ok {
+				primaryIndex = newPrimaryIndex
+			}
 			indexHasOldStoredColumns := index.HasOldStoredColumns()
 			// Need to clear ExtraColumnIDs and StoreColumnIDs because they are used
 			// by ContainsColumnID.
 			index.ExtraColumnIDs = nil
 			index.StoreColumnIDs = nil
 			var extraColumnIDs []ColumnID
-			for _, primaryColID := range desc.PrimaryIndex.ColumnIDs {
+			for _, primaryColID := range primaryIndex.ColumnIDs {
 				if !index.ContainsColumnID(primaryColID) {
 					extraColumnIDs = append(extraColumnIDs, primaryColID)
 				}
@@ -735,7 +776,7 @@ func (desc *TableDescriptor) allocateIndexIDs(columnNames map[string]ColumnID) e
 				if err != nil {
 					return err
 				}
-				if desc.PrimaryIndex.ContainsColumnID(col.ID) {
+				if primaryIndex.ContainsColumnID(col.ID) {
 					continue
 				}
 				if index.ContainsColumnID(col.ID) {
@@ -1573,13 +1614,30 @@ func fitColumnToFamily(desc TableDescriptor, col ColumnDescriptor) (int, bool) {
 
 // columnTypeIsIndexable returns whether the type t is valid as an indexed column.
 func columnTypeIsIndexable(t ColumnType) bool {
+	if t.SemanticType == ColumnType_ARRAY {
+		// Arrays are key encoded element by element. The key encoding of the
+		// elements with a composite encoding would lose the parts of their
+		// values that are otherwise stored in the value of the index entry.
+		elem := t.elementColumnType()
+		return elem.SemanticType != ColumnType_ARRAY && columnTypeIsIndexable(*elem) &&
+			!HasCompositeKeyEncoding(elem.SemanticType)
+	}
 	return !MustBeValueEncoded(t.SemanticType)
 }
 
 // columnTypeIsInvertedIndexable returns whether the type t is valid to be indexed
 // using an inverted index.
 func columnTypeIsInvertedIndexable(t ColumnType) bool {
-	return t.SemanticType == ColumnType_JSON
+	switch t.SemanticType {
+	case ColumnType_JSON:
+		return true
+	case ColumnType_ARRAY:
+		// The inverted index of an array contains the key encodings of its
+		// elements.
+		elem := t.elementColumnType()
+		return elem.SemanticType != ColumnType_ARRAY && !MustBeValueEncoded(elem.SemanticType)
+	}
+	return false
 }
 
 func notIndexableError(cols []ColumnDescriptor, inverted bool) error {
@@ -2000,6 +2058,19 @@ func (desc *TableDescriptor) IsInterleaved() bool {
 	return false
 }
 
+// PendingPrimaryIndex returns the index being built to replace the primary
+// index and the ID of the mutation adding it, or nil if no primary key change
+// is in progress.
+func (desc *TableDescriptor) PendingPrimaryIndex() (*IndexDescriptor, MutationID) {
+	for _, m := range desc.Mutations {
+		if idx := m.GetIndex(); idx != nil && m.Direction == DescriptorMutation_ADD &&
+			idx.EncodingType == PrimaryIndexEncoding {
+			return idx, m.MutationID
+		}
+	}
+	return nil, InvalidMutationID
+}
+
 // MakeMutationComplete updates the descriptor upon completion of a mutation.
 func (desc *TableDescriptor) MakeMutationComplete(m DescriptorMutation) {
 	switch m.Direction {
@@ -2024,6 +2095,43 @@ func (desc *TableDescriptor) MakeMutationComplete(m DescriptorMutation) {
 	}
 }
 
+// MakePrimaryKeyChangeComplete makes newPrimaryIndex, built by a primary key
+// change, the primary index of the table. The secondary indexes built along
+// with it, which must already be public, take over the names of the indexes
+// they replace, and the replaced primary and secondary indexes are queued to
+// be dropped. The caller is responsible for finalizing the new mutations.
+func (desc *TableDescriptor) MakePrimaryKeyChangeComplete(newPrimaryIndex IndexDescriptor) error {
+	oldPrimaryIndex := desc.PrimaryIndex
+	// The replaced primary index is dropped through the same code paths as
+	// secondary indexes; it keeps its own encoding until then.
+	oldPrimaryIndex.EncodingType = PrimaryIndexEncoding
+	dropped := []IndexDescriptor{oldPrimaryIndex}
+
+	newPrimaryIndex.Name = strings.TrimSuffix(newPrimaryIndex.Name, PrimaryKeyChangeIndexSuffix)
+	// The primary index stores all the columns implicitly.
+	newPrimaryIndex.StoreColumnNames = nil
+	newPrimaryIndex.StoreColumnIDs = nil
+	desc.PrimaryIndex = newPrimaryIndex
+
+	indexes := desc.Indexes[:0]
+	for _, idx := range desc.Indexes {
+		if strings.HasSuffix(idx.Name, PrimaryKeyChangeIndexSuffix) {
+			idx.Name = strings.TrimSuffix(idx.Name, PrimaryKeyChangeIndexSuffix)
+			indexes = append(indexes, idx)
+		} else {
+			dropped = append(dropped, idx)
+		}
+	}
+	desc.Indexes = indexes
+
+	for _, idx := range dropped {
+		if err := desc.AddIndexMutation(idx, DescriptorMutation_DROP); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // AddColumnMutation adds a column mutation to desc.Mutations.
 func (desc *TableDescriptor) AddColumnMutation(
 	c ColumnDescriptor, direction DescriptorMutation_Direction,
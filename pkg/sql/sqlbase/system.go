@@ -184,6 +184,87 @@ CREATE TABLE system.role_members (
   INDEX ("role"),
   INDEX ("member")
 );`
+
+	// index_usage_statistics accumulates the reads of every index by the
+	// statements run on each node. Nodes periodically add the reads they
+	// recorded in memory to their own rows.
+	IndexUsageStatsTableSchema = `
+CREATE TABLE system.index_usage_statistics (
+	"tableID"    INT       NOT NULL,
+	"indexID"    INT       NOT NULL,
+	"nodeID"     INT       NOT NULL,
+	"totalReads" INT       NOT NULL,
+	"lastRead"   TIMESTAMP NOT NULL,
+	PRIMARY KEY ("tableID", "indexID", "nodeID"),
+	FAMILY ("tableID", "indexID", "nodeID", "totalReads", "lastRead")
+);`
+
+	// scheduled_jobs holds the schedules on which statements, like BACKUP,
+	// are run. Each statement is run as its owner in the given database.
+	// next_run is the next time the statement is due, recomputed from the
+	// cron expression in recurrence every time it's run.
+	ScheduledJobsTableSchema = `
+CREATE TABLE system.scheduled_jobs (
+	name           STRING    NOT NULL PRIMARY KEY,
+	owner          STRING    NOT NULL,
+	database_name  STRING    NOT NULL,
+	created        TIMESTAMP NOT NULL DEFAULT now(),
+	recurrence     STRING    NOT NULL,
+	command        STRING    NOT NULL,
+	paused         BOOL      NOT NULL DEFAULT false,
+	next_run       TIMESTAMP NOT NULL,
+	last_run       TIMESTAMP,
+	last_error     STRING,
+	FAMILY (name, owner, database_name, created, recurrence, command, paused, next_run, last_run, last_error)
+);`
+
+	// replication_reports holds the latest replication report of the
+	// cluster: for every table, database or system range (object_id), the
+	// number of ranges that are unavailable, under- or over-replicated, that
+	// violate a constraint of their zone config, or that would lose quorum
+	// if a locality went down. The report is regenerated periodically by the
+	// leaseholder of the first range.
+	ReplicationReportsTableSchema = `
+CREATE TABLE system.replication_reports (
+	object_id INT       NOT NULL,
+	report    STRING    NOT NULL,
+	subject   STRING    NOT NULL,
+	ranges    INT       NOT NULL,
+	generated TIMESTAMP NOT NULL,
+	PRIMARY KEY (object_id, report, subject),
+	FAMILY (object_id, report, subject, ranges, generated)
+);`
+
+	// statement_statistics persists the statement statistics collected in
+	// memory by each node. The statistics of a node are cumulative since the
+	// last reset of its in-memory statistics (aggregated_ts), so nodes
+	// periodically overwrite their rows for the current period with the
+	// marshaled roachpb.StatementStatistics.
+	StatementStatsTableSchema = `
+CREATE TABLE system.statement_statistics (
+	aggregated_ts TIMESTAMP NOT NULL,
+	fingerprint   STRING    NOT NULL,
+	app_name      STRING    NOT NULL,
+	failed        BOOL      NOT NULL,
+	distsql       BOOL      NOT NULL,
+	node_id       INT       NOT NULL,
+	statistics    BYTES     NOT NULL,
+	PRIMARY KEY (aggregated_ts, fingerprint, app_name, failed, distsql, node_id),
+	FAMILY (aggregated_ts, fingerprint, app_name, failed, distsql, node_id, statistics)
+);`
+
+	// join_tokens records the join tokens which have been used to obtain a
+	// certificate from the built-in CA, so that each can only be used once
+	// across all the nodes serving as a CA. Rows are deleted once the token
+	// expires.
+	JoinTokensTableSchema = `
+CREATE TABLE system.join_tokens (
+	id         BYTES     NOT NULL PRIMARY KEY,
+	principal  STRING    NOT NULL,
+	expiration TIMESTAMP NOT NULL,
+	consumed   TIMESTAMP NOT NULL DEFAULT now(),
+	FAMILY (id, principal, expiration, consumed)
+);`
 )
 
 func pk(name string) IndexDescriptor {
@@ -218,11 +299,16 @@ var SystemAllowedPrivileges = map[ID]privilege.List{
 	// users will be able to modify system tables' schemas at will. CREATE and
 	// DROP privileges are allowed on the above system tables for backwards
 	// compatibility reasons only!
-	keys.JobsTableID:            privilege.ReadWriteData,
-	keys.WebSessionsTableID:     privilege.ReadWriteData,
-	keys.TableStatisticsTableID: privilege.ReadWriteData,
-	keys.LocationsTableID:       privilege.ReadWriteData,
-	keys.RoleMembersTableID:     privilege.ReadWriteData,
+	keys.JobsTableID:               privilege.ReadWriteData,
+	keys.WebSessionsTableID:        privilege.ReadWriteData,
+	keys.TableStatisticsTableID:    privilege.ReadWriteData,
+	keys.LocationsTableID:          privilege.ReadWriteData,
+	keys.RoleMembersTableID:        privilege.ReadWriteData,
+	keys.IndexUsageStatsTableID:    privilege.ReadWriteData,
+	keys.ScheduledJobsTableID:      privilege.ReadWriteData,
+	keys.ReplicationReportsTableID: privilege.ReadWriteData,
+	keys.StatementStatsTableID:     privilege.ReadWriteData,
+	keys.JoinTokensTableID:         privilege.ReadWriteData,
 }
 
 // Helpers used to make some of the TableDescriptor literals below more concise.
@@ -792,6 +878,194 @@ var (
 		FormatVersion:  InterleavedFormatVersion,
 		NextMutationID: 1,
 	}
+
+	// IndexUsageStatsTable is the descriptor for the index_usage_statistics
+	// table.
+	IndexUsageStatsTable = TableDescriptor{
+		Name:     "index_usage_statistics",
+		ID:       keys.IndexUsageStatsTableID,
+		ParentID: keys.SystemDatabaseID,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "tableID", ID: 1, Type: colTypeInt},
+			{Name: "indexID", ID: 2, Type: colTypeInt},
+			{Name: "nodeID", ID: 3, Type: colTypeInt},
+			{Name: "totalReads", ID: 4, Type: colTypeInt},
+			{Name: "lastRead", ID: 5, Type: colTypeTimestamp},
+		},
+		NextColumnID: 6,
+		Families: []ColumnFamilyDescriptor{
+			{
+				Name:        "fam_0_tableID_indexID_nodeID_totalReads_lastRead",
+				ID:          0,
+				ColumnNames: []string{"tableID", "indexID", "nodeID", "totalReads", "lastRead"},
+				ColumnIDs:   []ColumnID{1, 2, 3, 4, 5},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: IndexDescriptor{
+			Name:             "primary",
+			ID:               1,
+			Unique:           true,
+			ColumnNames:      []string{"tableID", "indexID", "nodeID"},
+			ColumnDirections: []IndexDescriptor_Direction{IndexDescriptor_ASC, IndexDescriptor_ASC, IndexDescriptor_ASC},
+			ColumnIDs:        []ColumnID{1, 2, 3},
+		},
+		NextIndexID:    2,
+		Privileges:     NewCustomSuperuserPrivilegeDescriptor(SystemAllowedPrivileges[keys.IndexUsageStatsTableID]),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
+
+	// ScheduledJobsTable is the descriptor for the scheduled_jobs table.
+	ScheduledJobsTable = TableDescriptor{
+		Name:     "scheduled_jobs",
+		ID:       keys.ScheduledJobsTableID,
+		ParentID: keys.SystemDatabaseID,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "name", ID: 1, Type: colTypeString},
+			{Name: "owner", ID: 2, Type: colTypeString},
+			{Name: "database_name", ID: 3, Type: colTypeString},
+			{Name: "created", ID: 4, Type: colTypeTimestamp, DefaultExpr: &nowString},
+			{Name: "recurrence", ID: 5, Type: colTypeString},
+			{Name: "command", ID: 6, Type: colTypeString},
+			{Name: "paused", ID: 7, Type: colTypeBool, DefaultExpr: &falseBoolString},
+			{Name: "next_run", ID: 8, Type: colTypeTimestamp},
+			{Name: "last_run", ID: 9, Type: colTypeTimestamp, Nullable: true},
+			{Name: "last_error", ID: 10, Type: colTypeString, Nullable: true},
+		},
+		NextColumnID: 11,
+		Families: []ColumnFamilyDescriptor{
+			{
+				Name: "fam_0_name_owner_database_name_created_recurrence_command_paused_next_run_last_run_last_error",
+				ID:   0,
+				ColumnNames: []string{
+					"name", "owner", "database_name", "created", "recurrence", "command", "paused",
+					"next_run", "last_run", "last_error",
+				},
+				ColumnIDs: []ColumnID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+			},
+		},
+		NextFamilyID:   1,
+		PrimaryIndex:   pk("name"),
+		NextIndexID:    2,
+		Privileges:     NewCustomSuperuserPrivilegeDescriptor(SystemAllowedPrivileges[keys.ScheduledJobsTableID]),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
+
+	// ReplicationReportsTable is the descriptor for the replication_reports
+	// table.
+	ReplicationReportsTable = TableDescriptor{
+		Name:     "replication_reports",
+		ID:       keys.ReplicationReportsTableID,
+		ParentID: keys.SystemDatabaseID,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "object_id", ID: 1, Type: colTypeInt},
+			{Name: "report", ID: 2, Type: colTypeString},
+			{Name: "subject", ID: 3, Type: colTypeString},
+			{Name: "ranges", ID: 4, Type: colTypeInt},
+			{Name: "generated", ID: 5, Type: colTypeTimestamp},
+		},
+		NextColumnID: 6,
+		Families: []ColumnFamilyDescriptor{
+			{
+				Name:        "fam_0_object_id_report_subject_ranges_generated",
+				ID:          0,
+				ColumnNames: []string{"object_id", "report", "subject", "ranges", "generated"},
+				ColumnIDs:   []ColumnID{1, 2, 3, 4, 5},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: IndexDescriptor{
+			Name:             "primary",
+			ID:               1,
+			Unique:           true,
+			ColumnNames:      []string{"object_id", "report", "subject"},
+			ColumnDirections: []IndexDescriptor_Direction{IndexDescriptor_ASC, IndexDescriptor_ASC, IndexDescriptor_ASC},
+			ColumnIDs:        []ColumnID{1, 2, 3},
+		},
+		NextIndexID:    2,
+		Privileges:     NewCustomSuperuserPrivilegeDescriptor(SystemAllowedPrivileges[keys.ReplicationReportsTableID]),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
+
+	// StatementStatsTable is the descriptor for the statement_statistics
+	// table.
+	StatementStatsTable = TableDescriptor{
+		Name:     "statement_statistics",
+		ID:       keys.StatementStatsTableID,
+		ParentID: keys.SystemDatabaseID,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "aggregated_ts", ID: 1, Type: colTypeTimestamp},
+			{Name: "fingerprint", ID: 2, Type: colTypeString},
+			{Name: "app_name", ID: 3, Type: colTypeString},
+			{Name: "failed", ID: 4, Type: colTypeBool},
+			{Name: "distsql", ID: 5, Type: colTypeBool},
+			{Name: "node_id", ID: 6, Type: colTypeInt},
+			{Name: "statistics", ID: 7, Type: colTypeBytes},
+		},
+		NextColumnID: 8,
+		Families: []ColumnFamilyDescriptor{
+			{
+				Name: "fam_0_aggregated_ts_fingerprint_app_name_failed_distsql_node_id_statistics",
+				ID:   0,
+				ColumnNames: []string{
+					"aggregated_ts", "fingerprint", "app_name", "failed", "distsql", "node_id", "statistics",
+				},
+				ColumnIDs: []ColumnID{1, 2, 3, 4, 5, 6, 7},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: IndexDescriptor{
+			Name:        "primary",
+			ID:          1,
+			Unique:      true,
+			ColumnNames: []string{"aggregated_ts", "fingerprint", "app_name", "failed", "distsql", "node_id"},
+			ColumnDirections: []IndexDescriptor_Direction{
+				IndexDescriptor_ASC, IndexDescriptor_ASC, IndexDescriptor_ASC,
+				IndexDescriptor_ASC, IndexDescriptor_ASC, IndexDescriptor_ASC,
+			},
+			ColumnIDs: []ColumnID{1, 2, 3, 4, 5, 6},
+		},
+		NextIndexID:    2,
+		Privileges:     NewCustomSuperuserPrivilegeDescriptor(SystemAllowedPrivileges[keys.StatementStatsTableID]),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
+
+	// JoinTokensTable is the descriptor for the join_tokens table.
+	JoinTokensTable = TableDescriptor{
+		Name:     "join_tokens",
+		ID:       keys.JoinTokensTableID,
+		ParentID: keys.SystemDatabaseID,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "id", ID: 1, Type: colTypeBytes},
+			{Name: "principal", ID: 2, Type: colTypeString},
+			{Name: "expiration", ID: 3, Type: colTypeTimestamp},
+			{Name: "consumed", ID: 4, Type: colTypeTimestamp, DefaultExpr: &nowString},
+		},
+		NextColumnID: 5,
+		Families: []ColumnFamilyDescriptor{
+			{
+				Name:        "fam_0_id_principal_expiration_consumed",
+				ID:          0,
+				ColumnNames: []string{"id", "principal", "expiration", "consumed"},
+				ColumnIDs:   []ColumnID{1, 2, 3, 4},
+			},
+		},
+		NextFamilyID:   1,
+		PrimaryIndex:   pk("id"),
+		NextIndexID:    2,
+		Privileges:     NewCustomSuperuserPrivilegeDescriptor(SystemAllowedPrivileges[keys.JoinTokensTableID]),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
 )
 
 // Create a kv pair for the zone config for the given key and config value.
@@ -15,6 +15,7 @@
 package sqlbase
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"sort"
@@ -660,14 +661,7 @@ func EncodeTableKey(b []byte, val tree.Datum, dir encoding.Direction) ([]byte, e
 		}
 		return encoding.EncodeBytesDescending(b, t.Key), nil
 	case *tree.DArray:
-		for _, datum := range t.Array {
-			var err error
-			b, err = EncodeTableKey(b, datum, dir)
-			if err != nil {
-				return nil, err
-			}
-		}
-		return b, nil
+		return encodeArrayKey(b, t, dir)
 	case *tree.DOid:
 		if dir == encoding.Ascending {
 			return encoding.EncodeVarintAscending(b, int64(t.DInt)), nil
@@ -677,6 +671,62 @@ func EncodeTableKey(b []byte, val tree.Datum, dir encoding.Direction) ([]byte, e
 	return nil, errors.Errorf("unable to encode table key: %T", val)
 }
 
+// encodeArrayKey encodes the array `val` into `b` and returns the new buffer.
+// The elements are key encoded between a marker and a terminator, so that
+// arrays sort element by element, and an array sorts before the arrays it is a
+// prefix of.
+func encodeArrayKey(b []byte, val *tree.DArray, dir encoding.Direction) ([]byte, error) {
+	if dir == encoding.Ascending {
+		b = encoding.EncodeArrayKeyAscending(b)
+	} else {
+		b = encoding.EncodeArrayKeyDescending(b)
+	}
+	for _, datum := range val.Array {
+		if datum == tree.DNull {
+			b = encoding.EncodeNullWithinArrayKey(b, dir)
+			continue
+		}
+		var err error
+		b, err = EncodeTableKey(b, datum, dir)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return encoding.EncodeArrayKeyTerminator(b, dir), nil
+}
+
+// decodeArrayKey decodes an array encoded by encodeArrayKey.
+func decodeArrayKey(
+	a *DatumAlloc, elementType types.T, key []byte, dir encoding.Direction,
+) (tree.Datum, []byte, error) {
+	key, err := encoding.ValidateAndConsumeArrayKeyMarker(key, dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	result := tree.NewDArray(elementType)
+	for {
+		if len(key) == 0 {
+			return nil, nil, errors.Errorf("invalid array key: missing terminator")
+		}
+		if encoding.IsArrayKeyDone(key, dir) {
+			return result, key[1:], nil
+		}
+		var d tree.Datum
+		if encoding.IsNextByteArrayEncodedNull(key, dir) {
+			d = tree.DNull
+			key = key[1:]
+		} else {
+			d, key, err = DecodeTableKey(a, elementType, key, dir)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		if err := result.Append(d); err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
 // EncodeTableValue encodes `val` into `appendTo` using DatumEncoding_VALUE
 // and returns the new buffer. The encoded value is guaranteed to round
 // trip and decode exactly to its input, but is not guaranteed to be
@@ -1469,6 +1519,8 @@ func DecodeTableKey(
 				return nil, nil, err
 			}
 			return tree.NewDCollatedString(r, t.Locale, &a.env), rkey, err
+		case types.TArray:
+			return decodeArrayKey(a, t.Typ, key, dir)
 		}
 		return nil, nil, errors.Errorf("TODO(pmattis): decoded index key: %s", valType)
 	}
@@ -1749,9 +1801,9 @@ func EncodeInvertedIndexKeys(
 	return EncodeInvertedIndexTableKeys(val, keyPrefix)
 }
 
-// EncodeInvertedIndexTableKeys encodes the paths in a JSON `val` and concatenates it with `inKey`and returns
-// a list of buffers per path. The encoded values is guaranteed to be lexicographically sortable, but not
-// guaranteed to be round-trippable during decoding.
+// EncodeInvertedIndexTableKeys encodes the paths in a JSON `val` (or the elements of an array `val`)
+// and concatenates it with `inKey`and returns a list of buffers per path. The encoded values is
+// guaranteed to be lexicographically sortable, but not guaranteed to be round-trippable during decoding.
 func EncodeInvertedIndexTableKeys(val tree.Datum, inKey []byte) (key [][]byte, err error) {
 	if val == tree.DNull {
 		return [][]byte{encoding.EncodeNullAscending(inKey)}, nil
@@ -1759,8 +1811,52 @@ func EncodeInvertedIndexTableKeys(val tree.Datum, inKey []byte) (key [][]byte, e
 	switch t := tree.UnwrapDatum(nil, val).(type) {
 	case *tree.DJSON:
 		return json.EncodeInvertedIndexKeys(inKey, (t.JSON))
+	case *tree.DArray:
+		return encodeArrayInvertedIndexTableKeys(t, inKey)
+	}
+	return nil, pgerror.NewError(pgerror.CodeInternalError, "trying to apply inverted index to non JSON or array type")
+}
+
+// encodeArrayInvertedIndexTableKeys returns a key for each distinct non-NULL
+// element of the array, made of `inKey` followed by the ascending key encoding
+// of the element. NULL elements are never contained in other arrays, so they
+// aren't indexed. An array without non-NULL elements gets a single key made of
+// the key encoding of an empty array, which can't be the encoding of an
+// element, as each row needs at least one index entry.
+func encodeArrayInvertedIndexTableKeys(val *tree.DArray, inKey []byte) (key [][]byte, err error) {
+	outKeys := make([][]byte, 0, len(val.Array))
+	for _, d := range val.Array {
+		if d == tree.DNull {
+			continue
+		}
+		outKey := make([]byte, len(inKey))
+		copy(outKey, inKey)
+		outKey, err = EncodeTableKey(outKey, d, encoding.Ascending)
+		if err != nil {
+			return nil, err
+		}
+		outKeys = append(outKeys, outKey)
+	}
+	if len(outKeys) == 0 {
+		outKey := make([]byte, len(inKey))
+		copy(outKey, inKey)
+		outKey, err = encodeArrayKey(outKey, tree.NewDArray(val.ParamTyp), encoding.Ascending)
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{outKey}, nil
+	}
+	// Elements with the same value have the same key.
+	sort.Slice(outKeys, func(i, j int) bool {
+		return bytes.Compare(outKeys[i], outKeys[j]) < 0
+	})
+	unique := outKeys[:0]
+	for i := range outKeys {
+		if i == 0 || !bytes.Equal(outKeys[i], outKeys[i-1]) {
+			unique = append(unique, outKeys[i])
+		}
 	}
-	return nil, pgerror.NewError(pgerror.CodeInternalError, "trying to apply inverted index to non JSON type")
+	return unique, nil
 }
 
 // EncodeSecondaryIndex encodes key/values for a secondary index. colMap maps
@@ -1852,7 +1948,14 @@ func EncodeSecondaryIndex(
 				return []IndexEntry{}, err
 			}
 		}
-		entry.Value.SetBytes(entryValue)
+		if secondaryIndex.EncodingType == PrimaryIndexEncoding {
+			// Entries of an index with the primary index encoding are laid out
+			// like the family 0 entries of the primary index, whose value is a
+			// tuple of the value-encoded columns.
+			entry.Value.SetTuple(entryValue)
+		} else {
+			entry.Value.SetBytes(entryValue)
+		}
 		entries[i] = entry
 	}
 
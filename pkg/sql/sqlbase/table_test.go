@@ -384,6 +384,85 @@ func BenchmarkArrayEncoding(b *testing.B) {
 	}
 }
 
+func TestArrayKeyEncoding(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	makeArray := func(typ types.T, elems ...tree.Datum) *tree.DArray {
+		a := tree.NewDArray(typ)
+		for _, e := range elems {
+			if err := a.Append(e); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return a
+	}
+	i := func(v int) tree.Datum { return tree.NewDInt(tree.DInt(v)) }
+	s := func(v string) tree.Datum { return tree.NewDString(v) }
+
+	// Each group of arrays is sorted in ascending order.
+	testCases := [][]tree.Datum{
+		{
+			tree.DNull,
+			makeArray(types.Int),
+			makeArray(types.Int, tree.DNull),
+			makeArray(types.Int, tree.DNull, i(1)),
+			makeArray(types.Int, i(-1)),
+			makeArray(types.Int, i(1)),
+			makeArray(types.Int, i(1), tree.DNull),
+			makeArray(types.Int, i(1), i(2)),
+			makeArray(types.Int, i(2)),
+		},
+		{
+			makeArray(types.String),
+			makeArray(types.String, s("")),
+			makeArray(types.String, s(""), s("")),
+			makeArray(types.String, s("\x00")),
+			makeArray(types.String, s("a")),
+			makeArray(types.String, s("a"), s("b")),
+			makeArray(types.String, s("ab")),
+		},
+	}
+
+	evalCtx := tree.NewTestingEvalContext(cluster.MakeTestingClusterSettings())
+	for _, dir := range []encoding.Direction{encoding.Ascending, encoding.Descending} {
+		for _, datums := range testCases {
+			var prev []byte
+			for j, d := range datums {
+				enc, err := EncodeTableKey(nil, d, dir)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if j > 0 {
+					c := bytes.Compare(prev, enc)
+					if (dir == encoding.Ascending && c >= 0) || (dir == encoding.Descending && c <= 0) {
+						t.Errorf("direction %d: expected %s to sort before %s", dir, datums[j-1], d)
+					}
+				}
+				prev = enc
+
+				typ := types.TArray{Typ: datums[len(datums)-1].(*tree.DArray).ParamTyp}
+				// Trailing bytes are left alone, and skipped by PeekLength.
+				withSuffix := append(append([]byte(nil), enc...), 0xff)
+				decoded, rest, err := DecodeTableKey(&DatumAlloc{}, typ, withSuffix, dir)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(rest, []byte{0xff}) {
+					t.Errorf("direction %d: unexpected remaining bytes %v decoding %s", dir, rest, d)
+				}
+				if decoded.Compare(evalCtx, d) != 0 {
+					t.Errorf("direction %d: expected %s to decode to itself, got %s", dir, d, decoded)
+				}
+				if l, err := encoding.PeekLength(enc); err != nil {
+					t.Fatal(err)
+				} else if l != len(enc) {
+					t.Errorf("direction %d: expected length %d for %s, got %d", dir, len(enc), d, l)
+				}
+			}
+		}
+	}
+}
+
 func TestMarshalColumnValue(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
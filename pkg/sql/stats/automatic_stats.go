@@ -0,0 +1,252 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/pkg/errors"
+)
+
+// AutomaticStatisticsClusterMode controls the cluster setting for enabling
+// automatic table statistics collection.
+var AutomaticStatisticsClusterMode = settings.RegisterBoolSetting(
+	"sql.stats.experimental_automatic_collection.enabled",
+	"experimental automatic statistics collection mode",
+	false,
+)
+
+// AutomaticStatisticsFractionStaleRows is the fraction of the rows of a table
+// that must be modified before its statistics are refreshed.
+var AutomaticStatisticsFractionStaleRows = settings.RegisterNonNegativeFloatSetting(
+	"sql.stats.automatic_collection.fraction_stale_rows",
+	"target fraction of stale rows per table that will trigger a statistics refresh",
+	0.2,
+)
+
+// AutomaticStatisticsMinStaleRows is the number of rows of a table that must be
+// modified, on top of the fraction above, before its statistics are refreshed.
+// It keeps small tables from being refreshed all the time.
+var AutomaticStatisticsMinStaleRows = settings.RegisterValidatedIntSetting(
+	"sql.stats.automatic_collection.min_stale_rows",
+	"target minimum number of stale rows per table that will trigger a statistics refresh",
+	500,
+	func(v int64) error {
+		if v < 0 {
+			return errors.Errorf("cannot set sql.stats.automatic_collection.min_stale_rows to a negative value: %d", v)
+		}
+		return nil
+	},
+)
+
+// AutoStatsName is the name used for the statistics created automatically.
+const AutoStatsName = "__auto__"
+
+// DefaultRefreshInterval is the interval at which the Refresher checks
+// whether the tables it has seen modified need new statistics.
+const DefaultRefreshInterval = time.Minute
+
+// mutation contains metadata about a SQL mutation of a table.
+type mutation struct {
+	tableID      sqlbase.ID
+	rowsAffected int
+}
+
+// mutationsChanSize is the size of the buffered channel through which
+// NotifyMutation hands the mutations to the Refresher. Mutations are dropped
+// when it's full, which only makes the refresh of statistics a bit later.
+const mutationsChanSize = 256
+
+// Refresher is responsible for automatically refreshing the table statistics
+// that are used by the cost-based optimizer. It keeps track of the number of
+// rows of each table that were modified through this node, and refreshes the
+// statistics of a table once they're likely to be stale: when the number of
+// modified rows exceeds the fraction of the rows of the table given by
+// sql.stats.automatic_collection.fraction_stale_rows, plus the minimum given by
+// sql.stats.automatic_collection.min_stale_rows.
+//
+// The statistics are refreshed with CREATE STATISTICS, on the first column of
+// each index of the table.
+type Refresher struct {
+	st    *cluster.Settings
+	ex    sqlutil.InternalExecutor
+	db    *client.DB
+	cache *TableStatisticsCache
+
+	// mutations is the buffered channel through which NotifyMutation passes
+	// mutations to the goroutine started by Start.
+	mutations chan mutation
+}
+
+// MakeRefresher creates a new Refresher.
+func MakeRefresher(
+	st *cluster.Settings, ex sqlutil.InternalExecutor, db *client.DB, cache *TableStatisticsCache,
+) *Refresher {
+	return &Refresher{
+		st:        st,
+		ex:        ex,
+		db:        db,
+		cache:     cache,
+		mutations: make(chan mutation, mutationsChanSize),
+	}
+}
+
+// Start starts a goroutine that accumulates the mutations passed to
+// NotifyMutation and, every refreshInterval, refreshes the statistics of the
+// tables that need it.
+func (r *Refresher) Start(
+	ctx context.Context, stopper *stop.Stopper, refreshInterval time.Duration,
+) {
+	stopper.RunWorker(ctx, func(ctx context.Context) {
+		// staleRows maps the tables modified since their last refresh to the
+		// number of rows that were modified.
+		staleRows := make(map[sqlbase.ID]int64)
+		var timer timeutil.Timer
+		defer timer.Stop()
+		timer.Reset(refreshInterval)
+		for {
+			select {
+			case m := <-r.mutations:
+				staleRows[m.tableID] += int64(m.rowsAffected)
+
+			case <-timer.C:
+				timer.Read = true
+				if AutomaticStatisticsClusterMode.Get(&r.st.SV) {
+					for tableID, rows := range staleRows {
+						if r.maybeRefreshStats(ctx, stopper, tableID, rows) {
+							delete(staleRows, tableID)
+						}
+					}
+				} else {
+					// Don't let the counts grow while the refreshes are disabled.
+					staleRows = make(map[sqlbase.ID]int64)
+				}
+				timer.Reset(refreshInterval)
+
+			case <-stopper.ShouldQuiesce():
+				return
+			}
+		}
+	})
+}
+
+// NotifyMutation is called by SQL mutation operations to signal to the
+// Refresher that a table has been mutated. It never blocks.
+func (r *Refresher) NotifyMutation(tableID sqlbase.ID, rowsAffected int) {
+	if rowsAffected == 0 || tableID < keys.MinUserDescID {
+		// The system tables are never refreshed; in particular, the writes of
+		// CREATE STATISTICS to system.table_statistics must not trigger more
+		// refreshes.
+		return
+	}
+	select {
+	case r.mutations <- mutation{tableID: tableID, rowsAffected: rowsAffected}:
+	default:
+	}
+}
+
+// maybeRefreshStats refreshes the statistics of the given table if enough of
+// its rows were modified, and returns whether it tried to.
+func (r *Refresher) maybeRefreshStats(
+	ctx context.Context, stopper *stop.Stopper, tableID sqlbase.ID, staleRows int64,
+) bool {
+	tableStats, err := r.cache.GetTableStats(ctx, tableID)
+	if err != nil {
+		log.Errorf(ctx, "failed to get statistics for table %d: %v", tableID, err)
+		return false
+	}
+	var rowCount float64
+	if len(tableStats) > 0 {
+		// The statistics are ordered from the newest to the oldest.
+		rowCount = float64(tableStats[0].RowCount)
+	}
+	threshold := float64(AutomaticStatisticsMinStaleRows.Get(&r.st.SV)) +
+		AutomaticStatisticsFractionStaleRows.Get(&r.st.SV)*rowCount
+	if float64(staleRows) < threshold {
+		return false
+	}
+
+	if err := r.refreshStats(ctx, stopper, tableID); err != nil {
+		log.Warningf(ctx, "failed to refresh statistics for table %d: %v", tableID, err)
+	}
+	return true
+}
+
+// refreshStats creates new statistics on the first column of each index of the
+// given table.
+func (r *Refresher) refreshStats(
+	ctx context.Context, stopper *stop.Stopper, tableID sqlbase.ID,
+) error {
+	var tn tree.TableName
+	var columns []string
+	if err := r.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		desc, err := sqlbase.GetTableDescFromID(ctx, txn, tableID)
+		if err != nil {
+			return err
+		}
+		if !desc.IsTable() || desc.Dropped() {
+			return nil
+		}
+		dbDesc, err := sqlbase.GetDatabaseDescFromID(ctx, txn, desc.ParentID)
+		if err != nil {
+			return err
+		}
+		tn = tree.MakeTableName(tree.Name(dbDesc.Name), tree.Name(desc.Name))
+		columns = columns[:0]
+		seen := make(map[string]struct{})
+		for _, idx := range desc.AllNonDropIndexes() {
+			if len(idx.ColumnNames) == 0 {
+				continue
+			}
+			if _, ok := seen[idx.ColumnNames[0]]; !ok {
+				seen[idx.ColumnNames[0]] = struct{}{}
+				columns = append(columns, idx.ColumnNames[0])
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for _, col := range columns {
+		select {
+		case <-stopper.ShouldQuiesce():
+			return nil
+		default:
+		}
+		stmt := tree.CreateStats{
+			Name:        AutoStatsName,
+			ColumnNames: tree.NameList{tree.Name(col)},
+			Table:       tree.NormalizableTableName{TableNameReference: &tn},
+		}
+		if _, err := r.ex.Exec(
+			ctx, "create-stats", nil /* txn */, tree.AsString(&stmt),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestMaybeRefreshStats(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	s, sqlDB, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+
+	sqlRun := sqlutils.MakeSQLRunner(sqlDB)
+	sqlRun.Exec(t,
+		`CREATE DATABASE t;
+		CREATE TABLE t.a (k INT PRIMARY KEY, v INT, INDEX (v));
+		INSERT INTO t.a SELECT x, x % 10 FROM generate_series(1, 100) AS g(x);`)
+	tableID := sqlbase.GetTableDescriptor(kvDB, "t", "a").ID
+
+	st := cluster.MakeTestingClusterSettings()
+	AutomaticStatisticsMinStaleRows.Override(&st.SV, 50)
+	ex := s.InternalExecutor().(sqlutil.InternalExecutor)
+	cache := NewTableStatisticsCache(10 /* cacheSize */, s.Gossip(), kvDB, ex)
+	r := MakeRefresher(st, ex, kvDB, cache)
+
+	countAutoStats := func() int {
+		var count int
+		sqlRun.QueryRow(t,
+			`SELECT count(*) FROM system.table_statistics WHERE "tableID" = $1 AND name = $2`,
+			tableID, AutoStatsName,
+		).Scan(&count)
+		return count
+	}
+
+	// There are too few stale rows to refresh the statistics.
+	if r.maybeRefreshStats(ctx, s.Stopper(), tableID, 10 /* staleRows */) {
+		t.Fatal("expected the statistics not to be refreshed")
+	}
+	if count := countAutoStats(); count != 0 {
+		t.Fatalf("expected no automatic statistics, found %d", count)
+	}
+
+	// Once there are enough stale rows, statistics are created on the first
+	// column of each index.
+	if !r.maybeRefreshStats(ctx, s.Stopper(), tableID, 100 /* staleRows */) {
+		t.Fatal("expected the statistics to be refreshed")
+	}
+	if count := countAutoStats(); count != 2 {
+		t.Fatalf("expected 2 automatic statistics, found %d", count)
+	}
+
+	// Now that the table has statistics with 100 rows, the fraction of stale
+	// rows also counts: 50 + 0.2 * 100 = 70 rows are needed.
+	cache.InvalidateTableStats(ctx, tableID)
+	if r.maybeRefreshStats(ctx, s.Stopper(), tableID, 60 /* staleRows */) {
+		t.Fatal("expected the statistics not to be refreshed")
+	}
+	if !r.maybeRefreshStats(ctx, s.Stopper(), tableID, 70 /* staleRows */) {
+		t.Fatal("expected the statistics to be refreshed")
+	}
+}
+
+func TestNotifyMutation(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	r := MakeRefresher(cluster.MakeTestingClusterSettings(), nil, nil, nil)
+
+	// Mutations of the system tables and empty mutations are ignored.
+	r.NotifyMutation(sqlbase.ID(3), 10)
+	r.NotifyMutation(sqlbase.ID(60), 0)
+	r.NotifyMutation(sqlbase.ID(60), 10)
+	if m := <-r.mutations; m.tableID != 60 || m.rowsAffected != 10 {
+		t.Fatalf("unexpected mutation %+v", m)
+	}
+	if len(r.mutations) != 0 {
+		t.Fatalf("expected no more mutations, found %d", len(r.mutations))
+	}
+
+	// Mutations are dropped rather than blocking when the channel is full.
+	for i := 0; i < mutationsChanSize+1; i++ {
+		r.NotifyMutation(sqlbase.ID(60), 1)
+	}
+	if len(r.mutations) != mutationsChanSize {
+		t.Fatalf("expected %d mutations, found %d", mutationsChanSize, len(r.mutations))
+	}
+}
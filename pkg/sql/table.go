@@ -623,6 +623,13 @@ func (tc *TableCollection) releaseAllDescriptors() {
 func (p *planner) createSchemaChangeJob(
 	ctx context.Context, tableDesc *sqlbase.TableDescriptor, stmt string,
 ) (sqlbase.MutationID, error) {
+	if _, id := tableDesc.PendingPrimaryIndex(); id != sqlbase.InvalidMutationID &&
+		id != tableDesc.NextMutationID {
+		// The indexes built by a primary key change must not miss changes
+		// made after they were created.
+		return sqlbase.InvalidMutationID, fmt.Errorf(
+			"table %q is undergoing a primary key change, try again later", tableDesc.Name)
+	}
 	span := tableDesc.PrimaryIndexSpan()
 	mutationID, err := tableDesc.FinalizeMutation()
 	if err != nil {
@@ -29,9 +29,9 @@ type tableInserter struct {
 }
 
 // init is part of the tableWriter interface.
-func (ti *tableInserter) init(txn *client.Txn, _ *tree.EvalContext) error {
+func (ti *tableInserter) init(txn *client.Txn, evalCtx *tree.EvalContext) error {
 	ti.tableWriterBase.init(txn)
-	return nil
+	return ti.ri.SetPartialIndexPredicates(evalCtx)
 }
 
 // row is part of the tableWriter interface.
@@ -56,6 +56,10 @@ func (tu *tableUpserterBase) init(txn *client.Txn, evalCtx *tree.EvalContext) er
 	tu.tableWriterBase.init(txn)
 	tableDesc := tu.tableDesc()
 
+	if err := tu.ri.SetPartialIndexPredicates(evalCtx); err != nil {
+		return err
+	}
+
 	tu.insertRows.Init(
 		evalCtx.Mon.MakeBoundAccount(), sqlbase.ColTypeInfoFromColDescs(tu.ri.InsertCols), 0,
 	)
@@ -120,6 +120,11 @@ func TestSystemTableLiterals(t *testing.T) {
 		{keys.TableStatisticsTableID, sqlbase.TableStatisticsTableSchema, sqlbase.TableStatisticsTable},
 		{keys.LocationsTableID, sqlbase.LocationsTableSchema, sqlbase.LocationsTable},
 		{keys.RoleMembersTableID, sqlbase.RoleMembersTableSchema, sqlbase.RoleMembersTable},
+		{keys.IndexUsageStatsTableID, sqlbase.IndexUsageStatsTableSchema, sqlbase.IndexUsageStatsTable},
+		{keys.ScheduledJobsTableID, sqlbase.ScheduledJobsTableSchema, sqlbase.ScheduledJobsTable},
+		{keys.ReplicationReportsTableID, sqlbase.ReplicationReportsTableSchema, sqlbase.ReplicationReportsTable},
+		{keys.StatementStatsTableID, sqlbase.StatementStatsTableSchema, sqlbase.StatementStatsTable},
+		{keys.JoinTokensTableID, sqlbase.JoinTokensTableSchema, sqlbase.JoinTokensTable},
 	} {
 		// Always create tables with "admin" privileges included, or CreateTestTableDescriptor fails.
 		privs := sqlbase.NewCustomSuperuserPrivilegeDescriptor(sqlbase.SystemAllowedPrivileges[test.id])
@@ -218,6 +218,20 @@ func (p *planner) truncateTable(ctx context.Context, id sqlbase.ID, traceKV bool
 		}
 	}
 
+	// Update the owner of the sequences owned by the table's columns.
+	for _, col := range tableDesc.Columns {
+		for _, sequenceID := range col.OwnsSequenceIds {
+			seqDesc, err := sqlbase.GetTableDescFromID(ctx, p.txn, sequenceID)
+			if err != nil {
+				return err
+			}
+			seqDesc.SequenceOpts.OwnerTableID = newID
+			if err := p.writeSchemaChange(ctx, seqDesc, sqlbase.InvalidMutationID); err != nil {
+				return err
+			}
+		}
+	}
+
 	// Reassign all self references.
 	if changed, err := reassignReferencedTables(
 		[]*sqlbase.TableDescriptor{&newTableDesc}, tableDesc.ID, newID,
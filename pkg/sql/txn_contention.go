@@ -0,0 +1,201 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/pkg/errors"
+)
+
+// retryCause classifies the retryable errors encountered by statements.
+type retryCause int
+
+const (
+	retryCauseOther retryCause = iota
+	// retryCauseSerializable is a transaction whose timestamp was pushed by
+	// conflicting transactions and could not be committed at its original
+	// timestamp.
+	retryCauseSerializable
+	// retryCauseWriteTooOld is a write which found a committed value with a
+	// newer timestamp.
+	retryCauseWriteTooOld
+	// retryCauseUncertainty is a read which found a value within its
+	// uncertainty interval.
+	retryCauseUncertainty
+	// retryCauseAborted is a transaction aborted by a conflicting transaction.
+	retryCauseAborted
+
+	numRetryCauses
+)
+
+func (c retryCause) String() string {
+	switch c {
+	case retryCauseSerializable:
+		return "serializable"
+	case retryCauseWriteTooOld:
+		return "write too old"
+	case retryCauseUncertainty:
+		return "uncertainty"
+	case retryCauseAborted:
+		return "aborted"
+	default:
+		return "other"
+	}
+}
+
+// classifyRetryableErr returns the cause of a retryable error. The original
+// error is no longer available once it has been handled by the
+// TxnCoordSender, so this relies on the message it left behind.
+func classifyRetryableErr(err *roachpb.HandledRetryableTxnError) retryCause {
+	switch msg := err.Msg; {
+	case strings.Contains(msg, roachpb.RETRY_SERIALIZABLE.String()):
+		return retryCauseSerializable
+	case strings.HasPrefix(msg, "WriteTooOldError"),
+		strings.Contains(msg, roachpb.RETRY_WRITE_TOO_OLD.String()):
+		return retryCauseWriteTooOld
+	case strings.HasPrefix(msg, "ReadWithinUncertaintyIntervalError"):
+		return retryCauseUncertainty
+	case strings.HasPrefix(msg, "TransactionAbortedError"):
+		return retryCauseAborted
+	default:
+		return retryCauseOther
+	}
+}
+
+const (
+	// contentionAdviceMinRetries is the number of retryable errors of a given
+	// cause a statement must have encountered for advice to be given.
+	contentionAdviceMinRetries = 3
+	// contentionAdviceScanBytesPerRow is the number of bytes read per row
+	// affected above which a mutation is assumed to be scanning for the rows it
+	// modifies instead of using an index.
+	contentionAdviceScanBytesPerRow = 16 << 10
+)
+
+// contentionAdvice analyzes the retryable errors encountered by the
+// application's statements and returns advice for reducing them, sorted by
+// decreasing number of retries.
+func (a *appStats) contentionAdvice() []tree.ContentionAdvice {
+	type stmtContention struct {
+		retries         [numRetryCauses]int64
+		bytesReadPerRow float64
+	}
+	byStmt := make(map[string]*stmtContention)
+	a.Lock()
+	for key, s := range a.stmts {
+		s.Lock()
+		retries := s.retries
+		bytesRead, rows := s.data.KVBytesRead.Mean, s.data.NumRows.Mean
+		s.Unlock()
+		c, ok := byStmt[key.stmt]
+		if !ok {
+			c = &stmtContention{}
+			byStmt[key.stmt] = c
+		}
+		for i := range retries {
+			c.retries[i] += retries[i]
+		}
+		if rows < 1 {
+			rows = 1
+		}
+		if perRow := bytesRead / rows; perRow > c.bytesReadPerRow {
+			c.bytesReadPerRow = perRow
+		}
+	}
+	a.Unlock()
+
+	var ret []tree.ContentionAdvice
+	for stmt, c := range byStmt {
+		for cause := retryCause(0); cause < numRetryCauses; cause++ {
+			if c.retries[cause] < contentionAdviceMinRetries {
+				continue
+			}
+			ret = append(ret, tree.ContentionAdvice{
+				Statement: stmt,
+				Cause:     cause.String(),
+				Retries:   c.retries[cause],
+				Advice:    adviseOnRetries(stmt, cause, c.bytesReadPerRow),
+			})
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Retries != ret[j].Retries {
+			return ret[i].Retries > ret[j].Retries
+		}
+		if ret[i].Statement != ret[j].Statement {
+			return ret[i].Statement < ret[j].Statement
+		}
+		return ret[i].Cause < ret[j].Cause
+	})
+	return ret
+}
+
+// adviseOnRetries suggests a change to reduce the retryable errors with the
+// given cause encountered by a statement.
+func adviseOnRetries(stmt string, cause retryCause, bytesReadPerRow float64) string {
+	var isMutation bool
+	if parsed, err := parser.ParseOne(stmt); err == nil {
+		switch parsed.(type) {
+		case *tree.Insert, *tree.Update, *tree.Delete:
+			isMutation = true
+		}
+	}
+
+	switch cause {
+	case retryCauseSerializable, retryCauseWriteTooOld:
+		if isMutation && strings.Contains(stmt, "nextval(") {
+			return "the statement writes keys generated from a sequence, so concurrent " +
+				"transactions all write to the end of the same index; consider UUID keys " +
+				"generated with gen_random_uuid() instead"
+		}
+		if isMutation && bytesReadPerRow > contentionAdviceScanBytesPerRow {
+			return fmt.Sprintf("the statement reads %.0f bytes per row it modifies, which "+
+				"suggests it scans for the rows it modifies and conflicts with unrelated writes; "+
+				"consider adding an index on the columns it filters on", bytesReadPerRow)
+		}
+		return "concurrent transactions read and write the same rows; keep the transactions " +
+			"short, perform contended writes as late as possible, and avoid mixing reads " +
+			"and writes of the same rows across separate statements"
+	case retryCauseUncertainty:
+		return "the statement reads recently written values whose timestamps are within " +
+			"the clock uncertainty interval; if slightly stale results are acceptable, read " +
+			"with AS OF SYSTEM TIME"
+	case retryCauseAborted:
+		return "the transaction was aborted by conflicting transactions; keep transactions " +
+			"short, or give this transaction a higher priority with SET TRANSACTION PRIORITY HIGH"
+	default:
+		return "retry the transaction on the client using SAVEPOINT cockroach_restart, " +
+			"which retains its priority across retries"
+	}
+}
+
+// TxnContentionAdvice implements the tree.EvalPlanner interface.
+func (p *planner) TxnContentionAdvice(ctx context.Context) ([]tree.ContentionAdvice, error) {
+	if err := p.RequireSuperUser(ctx, "access application statistics"); err != nil {
+		return nil, err
+	}
+	if p.statsCollector == nil || p.statsCollector.SQLStats() == nil {
+		return nil, errors.New("cannot access sql statistics from this context")
+	}
+	appStats := p.statsCollector.SQLStats().getStatsForApplication(p.SessionData().ApplicationName)
+	return appStats.contentionAdvice(), nil
+}
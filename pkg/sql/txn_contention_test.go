@@ -0,0 +1,72 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+func TestClassifyRetryableErr(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		msg      string
+		expected retryCause
+	}{
+		{"TransactionRetryError: retry txn (RETRY_SERIALIZABLE): \"sql txn\"", retryCauseSerializable},
+		{"TransactionRetryError: retry txn (RETRY_WRITE_TOO_OLD): \"sql txn\"", retryCauseWriteTooOld},
+		{"WriteTooOldError: write at timestamp 1.000000000,0 too old", retryCauseWriteTooOld},
+		{"ReadWithinUncertaintyIntervalError: read at time 1.000000000,0", retryCauseUncertainty},
+		{"TransactionAbortedError: txn aborted \"sql txn\"", retryCauseAborted},
+		{"restart transaction: forced", retryCauseOther},
+	}
+	for _, tc := range testCases {
+		err := roachpb.NewHandledRetryableTxnError(tc.msg, uuid.MakeV4(), roachpb.Transaction{})
+		if cause := classifyRetryableErr(err); cause != tc.expected {
+			t.Errorf("%q: expected %s, got %s", tc.msg, tc.expected, cause)
+		}
+	}
+}
+
+func TestAdviseOnRetries(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		stmt            string
+		cause           retryCause
+		bytesReadPerRow float64
+		expected        string
+	}{
+		{"INSERT INTO t VALUES (nextval('_'), _)", retryCauseSerializable, 0, "sequence"},
+		{"UPDATE t SET v = _ WHERE w = _", retryCauseWriteTooOld, 1 << 20, "index"},
+		{"UPDATE t SET v = _ WHERE k = _", retryCauseSerializable, 100, "keep the transactions short"},
+		{"SELECT nextval('_')", retryCauseSerializable, 0, "keep the transactions short"},
+		{"SELECT * FROM t", retryCauseUncertainty, 0, "AS OF SYSTEM TIME"},
+		{"UPDATE t SET v = _", retryCauseAborted, 0, "PRIORITY HIGH"},
+		{"SELECT * FROM t", retryCauseOther, 0, "cockroach_restart"},
+	}
+	for _, tc := range testCases {
+		advice := adviseOnRetries(tc.stmt, tc.cause, tc.bytesReadPerRow)
+		if !strings.Contains(advice, tc.expected) {
+			t.Errorf("%q (%s): expected advice containing %q, got %q",
+				tc.stmt, tc.cause, tc.expected, advice)
+		}
+	}
+}
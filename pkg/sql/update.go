@@ -540,6 +540,9 @@ func (u *updateNode) BatchedNext(params runParams) (bool, error) {
 		u.run.done = true
 	}
 
+	// Possibly initiate a refresh of the table statistics.
+	params.extendedEvalCtx.ExecCfg.notifyMutation(u.run.tu.tableDesc().ID, u.run.rowCount)
+
 	return u.run.rowCount > 0, nil
 }
 
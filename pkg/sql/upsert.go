@@ -338,6 +338,9 @@ func (n *upsertNode) BatchedNext(params runParams) (bool, error) {
 		n.run.done = true
 	}
 
+	// Possibly initiate a refresh of the table statistics.
+	params.extendedEvalCtx.ExecCfg.notifyMutation(n.run.tw.tableDesc().ID, n.run.tw.batchedCount())
+
 	return n.run.tw.batchedCount() > 0, nil
 }
 
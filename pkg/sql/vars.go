@@ -38,6 +38,10 @@ const (
 	PgServerVersionNum = "90500"
 )
 
+// defaultForeignKeyCascadesLimit is the default value of the
+// foreign_key_cascades_limit session variable.
+const defaultForeignKeyCascadesLimit = 10000
+
 // sessionVar provides a unified interface for performing operations on
 // variables such as the selected database, or desired syntax.
 type sessionVar struct {
@@ -374,6 +378,71 @@ var varGen = map[string]sessionVar{
 	// See https://www.postgresql.org/docs/10/static/runtime-config-client.html
 	`extra_float_digits`: nopVar,
 
+	// CockroachDB extension.
+	`foreign_key_cascades_limit`: {
+		Set: func(
+			_ context.Context, m *sessionDataMutator,
+			evalCtx *extendedEvalContext, values []tree.TypedExpr,
+		) error {
+			if len(values) != 1 {
+				return fmt.Errorf("set foreign_key_cascades_limit requires a single argument")
+			}
+			d, err := values[0].Eval(&evalCtx.EvalContext)
+			if err != nil {
+				return err
+			}
+			var limit int64
+			switch v := tree.UnwrapDatum(&evalCtx.EvalContext, d).(type) {
+			case *tree.DInt:
+				limit = int64(*v)
+			case *tree.DString:
+				limit, err = strconv.ParseInt(string(*v), 10, 64)
+				if err != nil {
+					return fmt.Errorf("set foreign_key_cascades_limit: \"%s\" not supported", *v)
+				}
+			default:
+				return fmt.Errorf("set foreign_key_cascades_limit requires an integer value: %s is a %s",
+					values[0], d.ResolvedType())
+			}
+			if limit < 0 {
+				return fmt.Errorf("foreign_key_cascades_limit cannot be negative")
+			}
+			m.SetForeignKeyCascadesLimit(limit)
+			return nil
+		},
+		Get: func(evalCtx *extendedEvalContext) string {
+			return strconv.FormatInt(evalCtx.SessionData.ForeignKeyCascadesLimit, 10)
+		},
+		Reset: func(m *sessionDataMutator) error {
+			m.SetForeignKeyCascadesLimit(defaultForeignKeyCascadesLimit)
+			return nil
+		},
+	},
+
+	// See https://www.postgresql.org/docs/10/static/runtime-config-client.html#GUC-IDLE-IN-TRANSACTION-SESSION-TIMEOUT
+	`idle_in_transaction_session_timeout`: {
+		Set: makeTimeoutSetter(
+			`idle_in_transaction_session_timeout`, (*sessionDataMutator).SetIdleInTransactionSessionTimeout),
+		Get: func(evalCtx *extendedEvalContext) string {
+			return evalCtx.SessionData.IdleInTransactionSessionTimeout.String()
+		},
+		Reset: func(m *sessionDataMutator) error {
+			m.SetIdleInTransactionSessionTimeout(0)
+			return nil
+		},
+	},
+
+	`idle_session_timeout`: {
+		Set: makeTimeoutSetter(`idle_session_timeout`, (*sessionDataMutator).SetIdleSessionTimeout),
+		Get: func(evalCtx *extendedEvalContext) string {
+			return evalCtx.SessionData.IdleSessionTimeout.String()
+		},
+		Reset: func(m *sessionDataMutator) error {
+			m.SetIdleSessionTimeout(0)
+			return nil
+		},
+	},
+
 	// Supported for PG compatibility only.
 	// See https://www.postgresql.org/docs/10/static/runtime-config-client.html
 	`intervalstyle`: {
@@ -409,6 +478,51 @@ var varGen = map[string]sessionVar{
 		},
 	},
 
+	// CockroachDB extension.
+	`optimizer_use_multicol_stats`: {
+		Set: func(
+			_ context.Context, m *sessionDataMutator,
+			evalCtx *extendedEvalContext, values []tree.TypedExpr,
+		) error {
+			s, err := getSingleBool("optimizer_use_multicol_stats", evalCtx, values)
+			if err != nil {
+				return err
+			}
+			m.SetOptimizerUseMultiColStats(bool(*s))
+
+			return nil
+		},
+		Get: func(evalCtx *extendedEvalContext) string {
+			return formatBoolAsPostgresSetting(evalCtx.SessionData.OptimizerUseMultiColStats)
+		},
+		Reset: func(m *sessionDataMutator) error {
+			m.SetOptimizerUseMultiColStats(true)
+			return nil
+		},
+	},
+
+	`optimizer_use_not_visible_indexes`: {
+		Set: func(
+			_ context.Context, m *sessionDataMutator,
+			evalCtx *extendedEvalContext, values []tree.TypedExpr,
+		) error {
+			s, err := getSingleBool("optimizer_use_not_visible_indexes", evalCtx, values)
+			if err != nil {
+				return err
+			}
+			m.SetOptimizerUseNotVisibleIndexes(bool(*s))
+
+			return nil
+		},
+		Get: func(evalCtx *extendedEvalContext) string {
+			return formatBoolAsPostgresSetting(evalCtx.SessionData.OptimizerUseNotVisibleIndexes)
+		},
+		Reset: func(m *sessionDataMutator) error {
+			m.SetOptimizerUseNotVisibleIndexes(false)
+			return nil
+		},
+	},
+
 	// CockroachDB extension (inspired by MySQL).
 	// See https://dev.mysql.com/doc/refman/5.7/en/server-system-variables.html#sysvar_sql_safe_updates
 	`sql_safe_updates`: {
@@ -455,6 +569,32 @@ var varGen = map[string]sessionVar{
 		},
 	},
 
+	// CockroachDB extension.
+	`serial_normalization`: {
+		Set: func(
+			_ context.Context, m *sessionDataMutator,
+			evalCtx *extendedEvalContext, values []tree.TypedExpr,
+		) error {
+			s, err := getStringVal(&evalCtx.EvalContext, `serial_normalization`, values)
+			if err != nil {
+				return err
+			}
+			mode, ok := sessiondata.SerialNormalizationModeFromString(s)
+			if !ok {
+				return fmt.Errorf("set serial_normalization: \"%s\" not supported", s)
+			}
+			m.SetSerialNormalizationMode(mode)
+			return nil
+		},
+		Get: func(evalCtx *extendedEvalContext) string {
+			return evalCtx.SessionData.SerialNormalizationMode.String()
+		},
+		Reset: func(m *sessionDataMutator) error {
+			m.SetSerialNormalizationMode(sessiondata.SerialUsesRowID)
+			return nil
+		},
+	},
+
 	// Supported for PG compatibility only.
 	// See https://www.postgresql.org/docs/10/static/runtime-config-preset.html#GUC-SERVER-VERSION
 	`server_version`: {
@@ -498,7 +638,7 @@ var varGen = map[string]sessionVar{
 	},
 
 	`statement_timeout`: {
-		Set: setStmtTimeout,
+		Set: makeTimeoutSetter(`statement_timeout`, (*sessionDataMutator).SetStmtTimeout),
 		Get: func(evalCtx *extendedEvalContext) string {
 			return evalCtx.SessionData.StmtTimeout.String()
 		},
@@ -604,6 +744,33 @@ var varGen = map[string]sessionVar{
 		},
 		// Setting is done by the SetTracing statement.
 	},
+
+	// CockroachDB extension.
+	`vectorize`: {
+		Set: func(
+			_ context.Context, m *sessionDataMutator,
+			evalCtx *extendedEvalContext, values []tree.TypedExpr,
+		) error {
+			s, err := getStringVal(&evalCtx.EvalContext, `vectorize`, values)
+			if err != nil {
+				return err
+			}
+			mode, ok := sessiondata.VectorizeExecModeFromString(s)
+			if !ok {
+				return fmt.Errorf("set vectorize: \"%s\" not supported", s)
+			}
+			m.SetVectorize(mode)
+
+			return nil
+		},
+		Get: func(evalCtx *extendedEvalContext) string {
+			return evalCtx.SessionData.Vectorize.String()
+		},
+		Reset: func(m *sessionDataMutator) error {
+			m.SetVectorize(sessiondata.VectorizeOff)
+			return nil
+		},
+	},
 }
 
 var varNames = func() []string {
@@ -626,33 +626,12 @@ func (n *windowNode) computeWindows(ctx context.Context, evalCtx *tree.EvalConte
 		frameRun := &tree.WindowFrameRun{}
 		if n.run.windowFrames[windowIdx] != nil {
 			frameRun.Frame = n.run.windowFrames[windowIdx]
-			// OffsetExpr's must be integer expressions not containing any variables, aggregate functions, or window functions,
-			// so we need to make sure these expressions are evaluated before using offsets.
-			bounds := frameRun.Frame.Bounds
-			if bounds.StartBound.OffsetExpr != nil {
-				typedStartOffset := bounds.StartBound.OffsetExpr.(tree.TypedExpr)
-				dStartOffset, err := typedStartOffset.Eval(evalCtx)
-				if err != nil {
-					return err
-				}
-				startOffset := int(tree.MustBeDInt(dStartOffset))
-				if startOffset < 0 {
-					return pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError, "frame starting offset must not be negative")
-				}
-				frameRun.StartBoundOffset = startOffset
-			}
-			if bounds.EndBound != nil && bounds.EndBound.OffsetExpr != nil {
-				typedEndOffset := bounds.EndBound.OffsetExpr.(tree.TypedExpr)
-				dEndOffset, err := typedEndOffset.Eval(evalCtx)
-				if err != nil {
-					return err
-				}
-				endOffset := int(tree.MustBeDInt(dEndOffset))
-				if endOffset < 0 {
-					return pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError, "frame ending offset must not be negative")
-				}
-				frameRun.EndBoundOffset = endOffset
+			startOffset, endOffset, err := evalWindowFrameOffsets(evalCtx, frameRun.Frame)
+			if err != nil {
+				return err
 			}
+			frameRun.StartBoundOffset = startOffset
+			frameRun.EndBoundOffset = endOffset
 		}
 
 		partitions := make(map[string][]tree.IndexedRow)
@@ -807,6 +786,39 @@ func (n *windowNode) computeWindows(ctx context.Context, evalCtx *tree.EvalConte
 	return nil
 }
 
+// evalWindowFrameOffsets evaluates the offsets of the bounds of the given
+// window frame. OffsetExpr's must be integer expressions not containing any
+// variables, aggregate functions, or window functions, so we need to make sure
+// these expressions are evaluated before using offsets.
+func evalWindowFrameOffsets(
+	evalCtx *tree.EvalContext, frame *tree.WindowFrame,
+) (startOffset, endOffset int, err error) {
+	bounds := frame.Bounds
+	if bounds.StartBound.OffsetExpr != nil {
+		typedStartOffset := bounds.StartBound.OffsetExpr.(tree.TypedExpr)
+		dStartOffset, err := typedStartOffset.Eval(evalCtx)
+		if err != nil {
+			return 0, 0, err
+		}
+		startOffset = int(tree.MustBeDInt(dStartOffset))
+		if startOffset < 0 {
+			return 0, 0, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError, "frame starting offset must not be negative")
+		}
+	}
+	if bounds.EndBound != nil && bounds.EndBound.OffsetExpr != nil {
+		typedEndOffset := bounds.EndBound.OffsetExpr.(tree.TypedExpr)
+		dEndOffset, err := typedEndOffset.Eval(evalCtx)
+		if err != nil {
+			return 0, 0, err
+		}
+		endOffset = int(tree.MustBeDInt(dEndOffset))
+		if endOffset < 0 {
+			return 0, 0, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError, "frame ending offset must not be negative")
+		}
+	}
+	return startOffset, endOffset, nil
+}
+
 // populateValues populates n.run.values with final datum values after computing
 // window result values in n.run.windowValues.
 func (n *windowNode) populateValues(ctx context.Context, evalCtx *tree.EvalContext) error {
@@ -865,6 +877,68 @@ func (n *windowNode) populateValues(ctx context.Context, evalCtx *tree.EvalConte
 	return nil
 }
 
+// distSQLRenders returns the render expressions of the windowNode to be
+// evaluated on top of the windowers of a DistSQL plan. The IndexedVars in the
+// returned expressions refer to the columns of the wrapped plan followed by
+// one column per window function, in n.funcs order.
+func (n *windowNode) distSQLRenders() ([]tree.TypedExpr, error) {
+	sourceCols := planColumns(n.plan)
+	colTypes := make([]types.T, len(sourceCols)+len(n.funcs))
+	for i, col := range sourceCols {
+		colTypes[i] = col.Typ
+	}
+	for i, windowFn := range n.funcs {
+		colTypes[len(sourceCols)+i] = windowFn.ResolvedType()
+	}
+	ivarHelper := tree.MakeTypesOnlyIndexedVarHelper(colTypes)
+
+	// IndexedVars created through n.ivarHelper refer to wrapped columns through
+	// colContainer; the other IndexedVars stand in for aggregate functions and
+	// refer to wrapped columns through aggContainer.
+	colVars := n.ivarHelper.GetIndexedVars()
+	replaceExprsAboveWindowing := func(expr tree.Expr) (error, bool, tree.Expr) {
+		switch t := expr.(type) {
+		case *windowFuncHolder:
+			return nil, false, ivarHelper.IndexedVar(len(sourceCols) + t.funcIdx)
+		case *tree.IndexedVar:
+			idxMap := n.aggContainer.idxMap
+			if t.Idx < len(colVars) && t == &colVars[t.Idx] {
+				idxMap = n.colContainer.idxMap
+			}
+			return nil, false, ivarHelper.IndexedVar(idxMap[t.Idx])
+		default:
+			return nil, true, expr
+		}
+	}
+
+	renders := make([]tree.TypedExpr, len(n.windowRender))
+	curColIdx := 0
+	curFnIdx := 0
+	for i, render := range n.windowRender {
+		if render == nil {
+			// The column is propagated directly from the wrapped planNode.
+			renders[i] = ivarHelper.IndexedVar(curColIdx)
+			curColIdx++
+			continue
+		}
+		// Skip the columns used as arguments to the window functions of this
+		// render; see populateValues.
+		for ; curFnIdx < len(n.funcs); curFnIdx++ {
+			windowFn := n.funcs[curFnIdx]
+			if windowFn.argIdxStart != curColIdx {
+				break
+			}
+			curColIdx += windowFn.argCount
+		}
+		expr, err := tree.SimpleVisit(render, replaceExprsAboveWindowing)
+		if err != nil {
+			return nil, err
+		}
+		renders[i] = expr.(tree.TypedExpr)
+	}
+	return renders, nil
+}
+
 type extractWindowFuncsVisitor struct {
 	n *windowNode
 
@@ -19,6 +19,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 )
 
@@ -38,21 +39,46 @@ import (
 //
 // Resolving a CTE name works by iterating through the stack from the top down
 // until the name is found.
+//
+// A CTE can be referenced any number of times, and is planned in one of two
+// ways:
+//
+// - a CTE defined by a query is planned inline: every reference gets its own
+//   plan for the CTE's statement, as if the statement had been written out in
+//   place of the reference.
+// - a CTE whose statement modifies data, directly or through a nested WITH
+//   clause or statement source, is buffered: it is run exactly once and to
+//   completion, ahead of the main query, whether or not it is referenced,
+//   and every reference reads the buffered results. The
+//   buffering reuses the subquery machinery: the statement is planned as a
+//   subquery evaluated in execModeAllRows, and references are cteScanNodes
+//   that iterate over the subquery's result.
 
 // cteNameEnvironment is the stack of environment frames.
 type cteNameEnvironment []cteNameEnvironmentFrame
 
 // cteNameEnvironmentFrame is a map from CTE name to datasource.
-type cteNameEnvironmentFrame map[tree.Name]cteSource
+type cteNameEnvironmentFrame map[tree.Name]*cteSource
 
 // cteSource is the value part of an entry in an environment frame. It holds
-// the plan that will be used to retrieve the data that's named by the CTE.
+// what is needed to plan the references to the CTE.
 type cteSource struct {
+	// stmt is the statement that defines the CTE.
+	stmt tree.Statement
+	// env is the name environment that stmt was planned in, used to plan it
+	// again for later references to an inline CTE. It includes the CTEs
+	// defined before this one in the same WITH clause, but not the ones
+	// defined after it.
+	env cteNameEnvironment
+	// plan is the plan for stmt built when the WITH clause was entered, for
+	// an inline CTE. It is handed to the first reference to the CTE, and is
+	// nil after that.
 	plan planNode
-	// used is set to true if this CTE has been used as a statement source. It's
-	// only around to prevent multiple use of a CTE, which is currently not
-	// supported.
-	used bool
+	// buffered is the subquery that evaluates stmt ahead of the main query,
+	// for a buffered CTE.
+	buffered *tree.Subquery
+	// columns are the result columns of stmt.
+	columns sqlbase.ResultColumns
 	// alias holds the name of the CTE and the renaming of its columns, if
 	// present.
 	alias tree.AliasClause
@@ -66,8 +92,17 @@ func (e cteNameEnvironment) pop() cteNameEnvironment {
 	return e[:len(e)-1]
 }
 
-func popCteNameEnvironment(p *planner) {
-	p.curPlan.cteNameEnvironment = p.curPlan.cteNameEnvironment.pop()
+// popCTENameEnvironment pops the environment frame pushed by initWith. The
+// plans of inline CTEs that were never referenced are closed.
+func (p *planner) popCTENameEnvironment(ctx context.Context) {
+	env := p.curPlan.cteNameEnvironment
+	for _, cteSource := range env[len(env)-1] {
+		if cteSource.plan != nil {
+			cteSource.plan.Close(ctx)
+			cteSource.plan = nil
+		}
+	}
+	p.curPlan.cteNameEnvironment = env.pop()
 }
 
 // initWith pushes a new environment frame onto the planner's CTE name
@@ -77,7 +112,8 @@ func popCteNameEnvironment(p *planner) {
 func (p *planner) initWith(ctx context.Context, with *tree.With) (func(p *planner), error) {
 	if with != nil {
 		frame := make(cteNameEnvironmentFrame)
-		p.curPlan.cteNameEnvironment = p.curPlan.cteNameEnvironment.push(frame)
+		outer := p.curPlan.cteNameEnvironment
+		p.curPlan.cteNameEnvironment = outer.push(frame)
 		for _, cte := range with.CTEList {
 			if _, ok := frame[cte.Name.Alias]; ok {
 				return nil, pgerror.NewErrorf(
@@ -85,21 +121,97 @@ func (p *planner) initWith(ctx context.Context, with *tree.With) (func(p *planne
 					"WITH query name %s specified more than once",
 					cte.Name.Alias)
 			}
+			// Snapshot the CTEs defined so far, so that planning the statement
+			// again for a later reference resolves the same names.
+			defined := make(cteNameEnvironmentFrame, len(frame))
+			for name, cteSource := range frame {
+				defined[name] = cteSource
+			}
+			env := append(outer[:len(outer):len(outer)], defined)
+
+			numBuffered := p.curPlan.numBufferedCTEs
 			ctePlan, err := p.newPlan(ctx, cte.Stmt, nil)
 			if err != nil {
 				return nil, err
 			}
-			frame[cte.Name.Alias] = cteSource{plan: ctePlan, alias: cte.Name}
+			cteSource := &cteSource{
+				stmt:    cte.Stmt,
+				env:     env,
+				columns: planColumns(ctePlan),
+				alias:   cte.Name,
+			}
+			if p.curPlan.numBufferedCTEs > numBuffered || planModifiesData(ctx, ctePlan) {
+				cteSource.buffered = p.bufferCTE(cte.Stmt, ctePlan)
+			} else {
+				cteSource.plan = ctePlan
+			}
+			frame[cte.Name.Alias] = cteSource
 		}
-		return popCteNameEnvironment, nil
+		return func(p *planner) { p.popCTENameEnvironment(ctx) }, nil
 	}
 	return nil, nil
 }
 
+// bufferCTE adds the plan of a CTE that modifies data to the sub-query plans,
+// so that it is run ahead of the main query and its rows are kept for the
+// references to the CTE. It returns the subquery whose result holds the rows.
+//
+// The subquery stands for SELECT * FROM [stmt], which is what EXPLAIN shows.
+func (p *planner) bufferCTE(stmt tree.Statement, plan planNode) *tree.Subquery {
+	sub := &tree.Subquery{
+		Select: &tree.ParenSelect{Select: &tree.Select{Select: &tree.SelectClause{
+			Exprs: tree.SelectExprs{tree.StarSelectExpr()},
+			From:  &tree.From{Tables: tree.TableExprs{&tree.StatementSource{Statement: stmt}}},
+		}}},
+	}
+	// The subquery is evaluated to a tuple of rows, which are tuples
+	// themselves unless there is exactly one column; see extractSubquery.
+	cols := planColumns(plan)
+	if len(cols) == 1 {
+		sub.SetType(cols[0].Typ)
+	} else {
+		colTypes := types.TTuple{
+			Types:  make([]types.T, len(cols)),
+			Labels: make([]string, len(cols)),
+		}
+		for i, col := range cols {
+			colTypes.Types[i] = col.Typ
+			colTypes.Labels[i] = col.Name
+		}
+		sub.SetType(colTypes)
+	}
+	p.curPlan.numBufferedCTEs++
+	p.curPlan.subqueryPlans = append(p.curPlan.subqueryPlans, subquery{
+		subquery: sub,
+		execMode: execModeAllRows,
+		plan:     plan,
+	})
+	sub.Idx = len(p.curPlan.subqueryPlans)
+	return sub
+}
+
+// planModifiesData returns whether the plan contains a statement that
+// modifies data.
+func planModifiesData(ctx context.Context, plan planNode) bool {
+	modifies := false
+	_ = walkPlan(ctx, plan, planObserver{
+		enterNode: func(_ context.Context, _ string, plan planNode) (bool, error) {
+			switch plan.(type) {
+			case *insertNode, *upsertNode, *updateNode, *deleteNode:
+				modifies = true
+			}
+			return !modifies, nil
+		},
+	})
+	return modifies
+}
+
 // getCTEDataSource looks up the table name in the planner's CTE name
 // environment, returning the planDataSource corresponding to the CTE if it was
 // found. The second return parameter returns true if a CTE was found.
-func (p *planner) getCTEDataSource(tn *tree.TableName) (planDataSource, bool, error) {
+func (p *planner) getCTEDataSource(
+	ctx context.Context, tn *tree.TableName,
+) (planDataSource, bool, error) {
 	if p.curPlan.cteNameEnvironment == nil {
 		return planDataSource{}, false, nil
 	}
@@ -111,29 +223,83 @@ func (p *planner) getCTEDataSource(tn *tree.TableName) (planDataSource, bool, er
 	for i := range p.curPlan.cteNameEnvironment {
 		frame := p.curPlan.cteNameEnvironment[len(p.curPlan.cteNameEnvironment)-1-i]
 		if cteSource, ok := frame[tn.TableName]; ok {
-			if cteSource.used {
-				// TODO(jordan): figure out how to lift this restriction.
-				// CTE expressions that are used more than once will need to be
-				// pre-evaluated like subqueries, I think.
-				return planDataSource{}, false, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
-					"unsupported multiple use of CTE clause %q", tree.ErrString(tn))
-			}
-			cteSource.used = true
-			frame[tn.TableName] = cteSource
-			plan := cteSource.plan
-			cols := planColumns(plan)
-			if len(cols) == 0 {
+			if len(cteSource.columns) == 0 {
 				return planDataSource{}, false, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
 					"WITH clause %q does not have a RETURNING clause", tree.ErrString(tn))
 			}
+			plan, err := p.planCTEReference(ctx, cteSource)
+			if err != nil {
+				return planDataSource{}, false, err
+			}
 			dataSource := planDataSource{
 				info: sqlbase.NewSourceInfoForSingleTable(*tn, planColumns(plan)),
 				plan: plan,
 			}
-			var err error
 			dataSource, err = renameSource(dataSource, cteSource.alias, false)
 			return dataSource, err == nil, err
 		}
 	}
 	return planDataSource{}, false, nil
 }
+
+// planCTEReference returns the plan for one reference to a CTE.
+func (p *planner) planCTEReference(ctx context.Context, cteSource *cteSource) (planNode, error) {
+	if cteSource.buffered != nil {
+		// Each reference gets its own copy of the columns, which the
+		// optimizations may annotate.
+		columns := append(sqlbase.ResultColumns(nil), cteSource.columns...)
+		return &cteScanNode{sub: cteSource.buffered, columns: columns}, nil
+	}
+	if cteSource.plan != nil {
+		plan := cteSource.plan
+		cteSource.plan = nil
+		return plan, nil
+	}
+	// The CTE was referenced before: plan its statement again, in the
+	// environment it was defined in.
+	defer func(env cteNameEnvironment) { p.curPlan.cteNameEnvironment = env }(p.curPlan.cteNameEnvironment)
+	p.curPlan.cteNameEnvironment = cteSource.env
+	return p.newPlan(ctx, cteSource.stmt, nil)
+}
+
+// cteScanNode reads the rows of a buffered CTE from the result of the
+// subquery that evaluated it.
+type cteScanNode struct {
+	sub     *tree.Subquery
+	columns sqlbase.ResultColumns
+
+	run struct {
+		rows   tree.Datums
+		rowIdx int
+		values tree.Datums
+	}
+}
+
+func (n *cteScanNode) startExec(params runParams) error {
+	result, err := params.p.EvalSubquery(n.sub)
+	if err != nil {
+		return err
+	}
+	n.run.rows = result.(*tree.DTuple).D
+	n.run.rowIdx = -1
+	n.run.values = make(tree.Datums, len(n.columns))
+	return nil
+}
+
+func (n *cteScanNode) Next(params runParams) (bool, error) {
+	n.run.rowIdx++
+	if n.run.rowIdx >= len(n.run.rows) {
+		return false, nil
+	}
+	row := n.run.rows[n.run.rowIdx]
+	if len(n.columns) == 1 {
+		n.run.values[0] = row
+	} else {
+		copy(n.run.values, row.(*tree.DTuple).D)
+	}
+	return true, nil
+}
+
+func (n *cteScanNode) Values() tree.Datums { return n.run.values }
+
+func (n *cteScanNode) Close(context.Context) {}
@@ -189,6 +189,19 @@ func TestValidSetShowZones(t *testing.T) {
 	sqlutils.DeleteZoneConfig(t, sqlDB, "TABLE t")
 	sqlutils.VerifyZoneConfigForTarget(t, sqlDB, "TABLE t", defaultRow)
 
+	// Ensure zone configs can be set with parameters instead of YAML.
+	sqlDB.Exec(t, "ALTER TABLE t CONFIGURE ZONE USING gc.ttlseconds = 42")
+	sqlutils.VerifyZoneConfigForTarget(t, sqlDB, "TABLE t", tableRow)
+	// A parameter set to NULL is reset to its default.
+	sqlDB.Exec(t, "ALTER TABLE t CONFIGURE ZONE USING gc.ttlseconds = NULL")
+	sqlutils.VerifyZoneConfigForTarget(t, sqlDB, "TABLE t", sqlutils.ZoneRow{
+		ID:           tableRow.ID,
+		CLISpecifier: tableRow.CLISpecifier,
+		Config:       config.DefaultZoneConfig(),
+	})
+	sqlDB.Exec(t, "ALTER TABLE t CONFIGURE ZONE DISCARD")
+	sqlutils.VerifyZoneConfigForTarget(t, sqlDB, "TABLE t", defaultRow)
+
 	// Ensure zone configs are read transactionally instead of from the cached
 	// system config.
 	txn, err := db.Begin()
@@ -203,7 +216,7 @@ func TestValidSetShowZones(t *testing.T) {
 	sqlutils.VerifyZoneConfigForTarget(t, sqlDB, "TABLE d.t", tableRow)
 
 	sqlDB.Exec(t, "DROP TABLE d.t")
-	_, err = sqlDB.DB.Exec("EXPERIMENTAL SHOW ZONE CONFIGURATION FOR TABLE d.t")
+	_, err = sqlDB.DB.Exec("SHOW ZONE CONFIGURATION FOR TABLE d.t")
 	if !testutils.IsError(err, `relation "d.t" does not exist`) {
 		t.Errorf("expected SHOW ZONE CONFIGURATION to fail on dropped table, but got %q", err)
 	}
@@ -237,6 +250,26 @@ func TestInvalidSetShowZones(t *testing.T) {
 			"ALTER RANGE foo EXPERIMENTAL CONFIGURE ZONE ''",
 			`"foo" is not a built-in zone`,
 		},
+		{
+			"ALTER RANGE meta EXPERIMENTAL CONFIGURE ZONE 'num_replicas: 4'",
+			"num_replicas for .meta must be odd, got 4",
+		},
+		{
+			"ALTER RANGE liveness EXPERIMENTAL CONFIGURE ZONE 'num_replicas: 6'",
+			"num_replicas for .liveness must be odd, got 6",
+		},
+		{
+			"ALTER DATABASE system EXPERIMENTAL CONFIGURE ZONE 'num_replicas: 4'",
+			"num_replicas for system must be odd, got 4",
+		},
+		{
+			"ALTER DATABASE system EXPERIMENTAL CONFIGURE ZONE '{constraints: [-region=us], experimental_lease_preferences: [[+region=us]]}'",
+			"lease preference \\+region=us for system contradicts constraint -region=us",
+		},
+		{
+			"ALTER RANGE meta EXPERIMENTAL CONFIGURE ZONE '{constraints: [+ssd], experimental_lease_preferences: [[-ssd]]}'",
+			"lease preference -ssd for .meta contradicts constraint \\+ssd",
+		},
 		{
 			"ALTER DATABASE foo EXPERIMENTAL CONFIGURE ZONE ''",
 			`database "foo" does not exist`,
@@ -249,6 +282,30 @@ func TestInvalidSetShowZones(t *testing.T) {
 			"ALTER TABLE foo EXPERIMENTAL CONFIGURE ZONE ''",
 			`relation "foo" does not exist`,
 		},
+		{
+			"ALTER RANGE default CONFIGURE ZONE DISCARD",
+			"cannot remove default zone",
+		},
+		{
+			"ALTER RANGE default CONFIGURE ZONE USING foo = 1",
+			`unsupported zone config parameter: "foo"`,
+		},
+		{
+			"ALTER RANGE default CONFIGURE ZONE USING num_replicas = 3, num_replicas = 5",
+			`duplicate zone config parameter: "num_replicas"`,
+		},
+		{
+			"ALTER RANGE default CONFIGURE ZONE USING constraints = '{+region=us: two}'",
+			"could not parse zone config",
+		},
+		{
+			"ALTER RANGE default CONFIGURE ZONE USING constraints = e'[]\\nnum_replicas: 1'",
+			"could not parse zone config",
+		},
+		{
+			"ALTER RANGE meta CONFIGURE ZONE USING num_replicas = 4",
+			"num_replicas for .meta must be odd, got 4",
+		},
 		{
 			"EXPERIMENTAL SHOW ZONE CONFIGURATION FOR RANGE foo",
 			`"foo" is not a built-in zone`,
@@ -271,3 +328,37 @@ func TestInvalidSetShowZones(t *testing.T) {
 		}
 	}
 }
+
+// TestSetSystemRangesZones verifies that the ranges the cluster depends on can
+// be given a higher replication factor than user data.
+func TestSetSystemRangesZones(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	params, _ := tests.CreateTestServerParams()
+	s, db, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(context.TODO())
+
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlutils.RemoveAllZoneConfigs(t, sqlDB)
+
+	for _, tc := range []struct {
+		target       string
+		cliSpecifier string
+		id           uint32
+		numReplicas  int32
+	}{
+		{"RANGE meta", ".meta", keys.MetaRangesID, 5},
+		{"RANGE liveness", ".liveness", keys.LivenessRangesID, 7},
+		{"RANGE system", ".system", keys.SystemRangesID, 5},
+		{"DATABASE system", "system", keys.SystemDatabaseID, 5},
+	} {
+		sqlutils.SetZoneConfig(t, sqlDB, tc.target, fmt.Sprintf("num_replicas: %d", tc.numReplicas))
+		zone := config.DefaultZoneConfig()
+		zone.NumReplicas = tc.numReplicas
+		sqlutils.VerifyZoneConfigForTarget(t, sqlDB, tc.target, sqlutils.ZoneRow{
+			ID:           tc.id,
+			CLISpecifier: tc.cliSpecifier,
+			Config:       zone,
+		})
+	}
+}
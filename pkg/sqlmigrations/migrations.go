@@ -190,6 +190,36 @@ var backwardCompatibleMigrations = []migrationDescriptor{
 		name:   "add progress to system.jobs",
 		workFn: addJobsProgress,
 	},
+	{
+		// Introduced in v2.1.
+		name:             "create system.index_usage_statistics table",
+		workFn:           createIndexUsageStatsTable,
+		newDescriptorIDs: staticIDs(keys.IndexUsageStatsTableID),
+	},
+	{
+		// Introduced in v2.1.
+		name:             "create system.scheduled_jobs table",
+		workFn:           createScheduledJobsTable,
+		newDescriptorIDs: staticIDs(keys.ScheduledJobsTableID),
+	},
+	{
+		// Introduced in v2.1.
+		name:             "create system.replication_reports table",
+		workFn:           createReplicationReportsTable,
+		newDescriptorIDs: staticIDs(keys.ReplicationReportsTableID),
+	},
+	{
+		// Introduced in v2.1.
+		name:             "create system.statement_statistics table",
+		workFn:           createStatementStatsTable,
+		newDescriptorIDs: staticIDs(keys.StatementStatsTableID),
+	},
+	{
+		// Introduced in v2.1.
+		name:             "create system.join_tokens table",
+		workFn:           createJoinTokensTable,
+		newDescriptorIDs: staticIDs(keys.JoinTokensTableID),
+	},
 }
 
 func staticIDs(ids ...sqlbase.ID) func(ctx context.Context, db db) ([]sqlbase.ID, error) {
@@ -841,3 +871,23 @@ func addJobsProgress(ctx context.Context, r runner) error {
 		return txn.Put(ctx, sqlbase.MakeDescMetadataKey(desc.ID), sqlbase.WrapDescriptor(desc))
 	})
 }
+
+func createIndexUsageStatsTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, sqlbase.IndexUsageStatsTable)
+}
+
+func createScheduledJobsTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, sqlbase.ScheduledJobsTable)
+}
+
+func createReplicationReportsTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, sqlbase.ReplicationReportsTable)
+}
+
+func createStatementStatsTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, sqlbase.StatementStatsTable)
+}
+
+func createJoinTokensTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, sqlbase.JoinTokensTable)
+}
@@ -0,0 +1,617 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"container/heap"
+	"context"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/elastic/gosigar"
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// admissionMaxReadAmplification is the read amplification above which a store
+// considers its disks overloaded.
+var admissionMaxReadAmplification = settings.RegisterValidatedIntSetting(
+	"kv.admission.max_read_amplification",
+	"read amplification above which a store is considered overloaded and queues its background "+
+		"work behind foreground traffic (0 disables)",
+	60,
+	func(v int64) error {
+		if v < 0 {
+			return errors.Errorf("cannot set kv.admission.max_read_amplification to a negative value: %d", v)
+		}
+		return nil
+	},
+)
+
+// admissionMaxCPUUtilization is the fraction of the machine's CPU used by the
+// process above which a store considers the node overloaded.
+var admissionMaxCPUUtilization = settings.RegisterValidatedFloatSetting(
+	"kv.admission.max_cpu_utilization",
+	"fraction of the available CPU used by the node above which a store is considered overloaded "+
+		"and queues its background work behind foreground traffic (0 disables)",
+	0.9,
+	func(v float64) error {
+		if v < 0 {
+			return errors.Errorf("cannot set kv.admission.max_cpu_utilization to a negative value: %f", v)
+		}
+		return nil
+	},
+)
+
+// admissionOverloadConcurrency is the number of background requests that an
+// overloaded store evaluates concurrently.
+var admissionOverloadConcurrency = settings.RegisterValidatedIntSetting(
+	"kv.admission.overload_concurrency",
+	"maximum number of snapshot, GC, backup and bulk IO requests that an overloaded store "+
+		"processes concurrently",
+	2,
+	func(v int64) error {
+		if v < 1 {
+			return errors.Errorf("kv.admission.overload_concurrency must be at least 1: %d", v)
+		}
+		return nil
+	},
+)
+
+// admissionMaxDelay bounds how long a request is queued, so that background
+// work isn't starved by a store that stays overloaded.
+var admissionMaxDelay = settings.RegisterNonNegativeDurationSetting(
+	"kv.admission.max_delay",
+	"maximum time for which an overloaded store queues a snapshot, GC, backup or bulk IO request",
+	time.Minute,
+)
+
+// admissionRecheckInterval is the interval at which a store checks whether
+// it is overloaded. The read amplification is only updated when the store
+// metrics are computed, every 10s, but the CPU utilization is sampled anew
+// every time.
+const admissionRecheckInterval = time.Second
+
+// workClass classifies the work a store receives for admission. The classes
+// are ordered by decreasing priority.
+type workClass int
+
+const (
+	// workClassRaft is Raft traffic, including the snapshots Raft needs to
+	// catch up a replica.
+	workClassRaft workClass = iota
+	// workClassSystem is work on the system ranges (meta ranges, node
+	// liveness, system tables), which the whole cluster depends on.
+	workClassSystem
+	// workClassForeground is work on the user ranges on behalf of SQL
+	// clients.
+	workClassForeground
+	// workClassSnapshot is the application of the snapshots sent to
+	// rebalance replicas.
+	workClassSnapshot
+	// workClassGC is garbage collection of old MVCC versions and txn records.
+	workClassGC
+	// workClassBackup is the export of data by BACKUP.
+	workClassBackup
+	// workClassBulk is the ingestion of SSTables by IMPORT and RESTORE.
+	workClassBulk
+)
+
+var workClassNames = [...]string{
+	workClassRaft:       "raft",
+	workClassSystem:     "system",
+	workClassForeground: "foreground",
+	workClassSnapshot:   "snapshot",
+	workClassGC:         "gc",
+	workClassBackup:     "backup",
+	workClassBulk:       "bulk",
+}
+
+func (c workClass) String() string {
+	return workClassNames[c]
+}
+
+// queued returns whether work of the class is queued while the store is
+// overloaded. Raft, system and foreground work never is.
+func (c workClass) queued() bool {
+	return c >= workClassSnapshot
+}
+
+// classifyBatch returns the work class of a batch.
+func classifyBatch(ba *roachpb.BatchRequest) workClass {
+	class := workClassForeground
+	for _, union := range ba.Requests {
+		reqClass := workClassForeground
+		switch union.GetInner().Method() {
+		case roachpb.AddSSTable, roachpb.Import:
+			reqClass = workClassBulk
+		case roachpb.Export:
+			reqClass = workClassBackup
+		case roachpb.GC:
+			reqClass = workClassGC
+		}
+		if reqClass > class {
+			class = reqClass
+		}
+	}
+	if !class.queued() {
+		// Requests addressed to range-local keys are counted as system work,
+		// which only matters for the metrics: neither class is ever queued.
+		if len(ba.Requests) > 0 &&
+			ba.Requests[0].GetInner().Header().Key.Compare(keys.UserTableDataMin) < 0 {
+			return workClassSystem
+		}
+		return class
+	}
+	// Background work on the system ranges isn't queued either.
+	if rs, err := keys.Range(*ba); err == nil && rs.Key.Less(roachpb.RKey(keys.UserTableDataMin)) {
+		return workClassSystem
+	}
+	return class
+}
+
+// classifySnapshot returns the work class of a snapshot.
+func classifySnapshot(header *SnapshotRequest_Header) workClass {
+	if header.Priority == SnapshotRequest_RECOVERY {
+		return workClassRaft
+	}
+	return workClassSnapshot
+}
+
+var (
+	metaAdmissionOverloaded = metric.Metadata{
+		Name:        "admission.overloaded",
+		Help:        "Whether the store is overloaded and queues its background work (0 or 1)",
+		Measurement: "Overloaded",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaAdmissionRaft = metric.Metadata{
+		Name:        "admission.admitted.raft",
+		Help:        "Number of Raft messages and recovery snapshots received by the store",
+		Measurement: "Requests",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaAdmissionSystem = metric.Metadata{
+		Name:        "admission.admitted.system",
+		Help:        "Number of batches on system ranges admitted by the store",
+		Measurement: "Batches",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaAdmissionForeground = metric.Metadata{
+		Name:        "admission.admitted.foreground",
+		Help:        "Number of foreground batches admitted by the store",
+		Measurement: "Batches",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaAdmissionSnapshot = metric.Metadata{
+		Name:        "admission.admitted.snapshot",
+		Help:        "Number of rebalancing snapshots admitted by the store",
+		Measurement: "Snapshots",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaAdmissionGC = metric.Metadata{
+		Name:        "admission.admitted.gc",
+		Help:        "Number of GC batches admitted by the store",
+		Measurement: "Batches",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaAdmissionBackup = metric.Metadata{
+		Name:        "admission.admitted.backup",
+		Help:        "Number of backup batches admitted by the store",
+		Measurement: "Batches",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaAdmissionBulk = metric.Metadata{
+		Name:        "admission.admitted.bulk",
+		Help:        "Number of bulk IO batches admitted by the store",
+		Measurement: "Batches",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaAdmissionDelayedSnapshot = metric.Metadata{
+		Name:        "admission.delayed.snapshot",
+		Help:        "Number of rebalancing snapshots queued because the store was overloaded",
+		Measurement: "Snapshots",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaAdmissionDelayedGC = metric.Metadata{
+		Name:        "admission.delayed.gc",
+		Help:        "Number of GC batches queued because the store was overloaded",
+		Measurement: "Batches",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaAdmissionDelayedBackup = metric.Metadata{
+		Name:        "admission.delayed.backup",
+		Help:        "Number of backup batches queued because the store was overloaded",
+		Measurement: "Batches",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaAdmissionDelayedBulk = metric.Metadata{
+		Name:        "admission.delayed.bulk",
+		Help:        "Number of bulk IO batches queued because the store was overloaded",
+		Measurement: "Batches",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaAdmissionWaitingSnapshot = metric.Metadata{
+		Name:        "admission.waiting.snapshot",
+		Help:        "Number of rebalancing snapshots queued by the store",
+		Measurement: "Snapshots",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaAdmissionWaitingGC = metric.Metadata{
+		Name:        "admission.waiting.gc",
+		Help:        "Number of GC batches queued by the store",
+		Measurement: "Batches",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaAdmissionWaitingBackup = metric.Metadata{
+		Name:        "admission.waiting.backup",
+		Help:        "Number of backup batches queued by the store",
+		Measurement: "Batches",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaAdmissionWaitingBulk = metric.Metadata{
+		Name:        "admission.waiting.bulk",
+		Help:        "Number of bulk IO batches queued by the store",
+		Measurement: "Batches",
+		Unit:        metric.Unit_COUNT,
+	}
+)
+
+// AdmissionMetrics is the set of metrics for the admission of work by a
+// store.
+type AdmissionMetrics struct {
+	Overloaded *metric.Gauge
+
+	AdmittedRaft       *metric.Counter
+	AdmittedSystem     *metric.Counter
+	AdmittedForeground *metric.Counter
+	AdmittedSnapshot   *metric.Counter
+	AdmittedGC         *metric.Counter
+	AdmittedBackup     *metric.Counter
+	AdmittedBulk       *metric.Counter
+
+	DelayedSnapshot *metric.Counter
+	DelayedGC       *metric.Counter
+	DelayedBackup   *metric.Counter
+	DelayedBulk     *metric.Counter
+
+	WaitingSnapshot *metric.Gauge
+	WaitingGC       *metric.Gauge
+	WaitingBackup   *metric.Gauge
+	WaitingBulk     *metric.Gauge
+}
+
+// MetricStruct implements the metric.Struct interface.
+func (AdmissionMetrics) MetricStruct() {}
+
+var _ metric.Struct = AdmissionMetrics{}
+
+func makeAdmissionMetrics() AdmissionMetrics {
+	return AdmissionMetrics{
+		Overloaded: metric.NewGauge(metaAdmissionOverloaded),
+
+		AdmittedRaft:       metric.NewCounter(metaAdmissionRaft),
+		AdmittedSystem:     metric.NewCounter(metaAdmissionSystem),
+		AdmittedForeground: metric.NewCounter(metaAdmissionForeground),
+		AdmittedSnapshot:   metric.NewCounter(metaAdmissionSnapshot),
+		AdmittedGC:         metric.NewCounter(metaAdmissionGC),
+		AdmittedBackup:     metric.NewCounter(metaAdmissionBackup),
+		AdmittedBulk:       metric.NewCounter(metaAdmissionBulk),
+
+		DelayedSnapshot: metric.NewCounter(metaAdmissionDelayedSnapshot),
+		DelayedGC:       metric.NewCounter(metaAdmissionDelayedGC),
+		DelayedBackup:   metric.NewCounter(metaAdmissionDelayedBackup),
+		DelayedBulk:     metric.NewCounter(metaAdmissionDelayedBulk),
+
+		WaitingSnapshot: metric.NewGauge(metaAdmissionWaitingSnapshot),
+		WaitingGC:       metric.NewGauge(metaAdmissionWaitingGC),
+		WaitingBackup:   metric.NewGauge(metaAdmissionWaitingBackup),
+		WaitingBulk:     metric.NewGauge(metaAdmissionWaitingBulk),
+	}
+}
+
+// classMetrics returns the metrics of a work class. The delayed and waiting
+// metrics are nil for the classes that are never queued.
+func (m *AdmissionMetrics) classMetrics(
+	class workClass,
+) (admitted, delayed *metric.Counter, waiting *metric.Gauge) {
+	switch class {
+	case workClassRaft:
+		return m.AdmittedRaft, nil, nil
+	case workClassSystem:
+		return m.AdmittedSystem, nil, nil
+	case workClassForeground:
+		return m.AdmittedForeground, nil, nil
+	case workClassSnapshot:
+		return m.AdmittedSnapshot, m.DelayedSnapshot, m.WaitingSnapshot
+	case workClassGC:
+		return m.AdmittedGC, m.DelayedGC, m.WaitingGC
+	case workClassBackup:
+		return m.AdmittedBackup, m.DelayedBackup, m.WaitingBackup
+	case workClassBulk:
+		return m.AdmittedBulk, m.DelayedBulk, m.WaitingBulk
+	default:
+		panic(errors.Errorf("unknown work class %d", class))
+	}
+}
+
+// admissionWaiter is a request queued by the admissionController.
+type admissionWaiter struct {
+	class workClass
+	seq   int64
+	start time.Time
+	// granted is closed when the request is admitted.
+	granted chan struct{}
+	// index is the position of the waiter in the queue, or -1 once it was
+	// removed from it.
+	index int
+}
+
+// admissionQueue is a heap of the queued requests, which orders them by
+// class, and in the order in which they arrived within a class.
+type admissionQueue []*admissionWaiter
+
+var _ heap.Interface = &admissionQueue{}
+
+func (q admissionQueue) Len() int { return len(q) }
+
+func (q admissionQueue) Less(i, j int) bool {
+	if q[i].class != q[j].class {
+		return q[i].class < q[j].class
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q admissionQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *admissionQueue) Push(x interface{}) {
+	w := x.(*admissionWaiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+
+func (q *admissionQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	w.index = -1
+	*q = old[:n-1]
+	return w
+}
+
+// admissionController decides when the work received by a store is
+// processed. Raft, system and foreground work is always admitted right away.
+// Snapshots for rebalancing, GC, backup and bulk IO work is admitted right
+// away too unless the store is overloaded, as indicated by its read
+// amplification or by the CPU utilization of the node. While it is, at most
+// kv.admission.overload_concurrency of these requests are processed at a
+// time and the others are queued, to be admitted in the order of their
+// classes' priority as the ones in progress finish. A request that was
+// queued for kv.admission.max_delay is admitted regardless, one per
+// admissionRecheckInterval, so that background work isn't starved.
+//
+// Requests are only queued before they're evaluated, while they hold no
+// latches, so that they never hold up the work they're yielding to.
+type admissionController struct {
+	sv      *settings.Values
+	readAmp func() int64
+	cpu     func() float64
+	metrics AdmissionMetrics
+
+	mu struct {
+		syncutil.Mutex
+		overloaded bool
+		// running is the number of admitted requests of the queued classes
+		// that aren't done yet.
+		running int64
+		waiters admissionQueue
+		seq     int64
+	}
+}
+
+// newAdmissionController creates an admissionController. readAmp returns the
+// read amplification of the store, and cpu the CPU utilization of the node
+// since the previous call.
+func newAdmissionController(
+	sv *settings.Values, readAmp func() int64, cpu func() float64,
+) *admissionController {
+	return &admissionController{
+		sv:      sv,
+		readAmp: readAmp,
+		cpu:     cpu,
+		metrics: makeAdmissionMetrics(),
+	}
+}
+
+// start runs the loop that checks whether the store is overloaded.
+func (ac *admissionController) start(ctx context.Context, stopper *stop.Stopper) {
+	stopper.RunWorker(ctx, func(ctx context.Context) {
+		ticker := time.NewTicker(admissionRecheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				ac.tick()
+			case <-stopper.ShouldStop():
+				return
+			}
+		}
+	})
+}
+
+// tick checks whether the store is overloaded and admits the queued requests
+// that can be admitted as a result, as well as the ones that waited for too
+// long.
+func (ac *admissionController) tick() {
+	overloaded := false
+	if limit := admissionMaxReadAmplification.Get(ac.sv); limit > 0 && ac.readAmp() > limit {
+		overloaded = true
+	}
+	// The CPU utilization is sampled regardless, so that each sample covers
+	// one interval.
+	if limit := admissionMaxCPUUtilization.Get(ac.sv); ac.cpu() > limit && limit > 0 {
+		overloaded = true
+	}
+	if overloaded {
+		ac.metrics.Overloaded.Update(1)
+	} else {
+		ac.metrics.Overloaded.Update(0)
+	}
+
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	ac.mu.overloaded = overloaded
+	ac.grantLocked()
+	// Admit the request that has been queued the longest if it has been
+	// queued for too long, even if that exceeds the concurrency limit. Only
+	// one such request is admitted per tick, so that the store's backlog
+	// doesn't pile onto it all at once when max_delay is short.
+	var oldest *admissionWaiter
+	for _, w := range ac.mu.waiters {
+		if oldest == nil || w.start.Before(oldest.start) {
+			oldest = w
+		}
+	}
+	if oldest != nil && timeutil.Since(oldest.start) >= admissionMaxDelay.Get(ac.sv) {
+		heap.Remove(&ac.mu.waiters, oldest.index)
+		ac.admitLocked(oldest)
+	}
+}
+
+// canAdmitLocked returns whether one more request of the queued classes can
+// be admitted.
+func (ac *admissionController) canAdmitLocked() bool {
+	return !ac.mu.overloaded || ac.mu.running < admissionOverloadConcurrency.Get(ac.sv)
+}
+
+// admitLocked admits a waiter that was removed from the queue.
+func (ac *admissionController) admitLocked(w *admissionWaiter) {
+	ac.mu.running++
+	close(w.granted)
+}
+
+// grantLocked admits as many queued requests as possible, in order.
+func (ac *admissionController) grantLocked() {
+	for len(ac.mu.waiters) > 0 && ac.canAdmitLocked() {
+		ac.admitLocked(heap.Pop(&ac.mu.waiters).(*admissionWaiter))
+	}
+}
+
+// admit blocks until work of the given class can be processed. Every
+// successful call must be followed by a call to done once the work is
+// finished.
+func (ac *admissionController) admit(
+	ctx context.Context, stopper *stop.Stopper, class workClass,
+) error {
+	admitted, delayed, waiting := ac.metrics.classMetrics(class)
+	if !class.queued() {
+		admitted.Inc(1)
+		return nil
+	}
+
+	ac.mu.Lock()
+	if len(ac.mu.waiters) == 0 && ac.canAdmitLocked() {
+		ac.mu.running++
+		ac.mu.Unlock()
+		admitted.Inc(1)
+		return nil
+	}
+	ac.mu.seq++
+	w := &admissionWaiter{
+		class:   class,
+		seq:     ac.mu.seq,
+		start:   timeutil.Now(),
+		granted: make(chan struct{}),
+	}
+	heap.Push(&ac.mu.waiters, w)
+	ac.mu.Unlock()
+
+	delayed.Inc(1)
+	waiting.Inc(1)
+	defer waiting.Dec(1)
+	log.VEventf(ctx, 2, "store overloaded; queuing %s request", class)
+
+	var err error
+	select {
+	case <-w.granted:
+		admitted.Inc(1)
+		return nil
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-stopper.ShouldQuiesce():
+		err = &roachpb.NodeUnavailableError{}
+	}
+
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	if w.index >= 0 {
+		heap.Remove(&ac.mu.waiters, w.index)
+	} else {
+		// The request was admitted in the meantime; hand its slot over.
+		ac.mu.running--
+		ac.grantLocked()
+	}
+	return err
+}
+
+// done is called when work of the given class that was admitted is finished.
+func (ac *admissionController) done(class workClass) {
+	if !class.queued() {
+		return
+	}
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	ac.mu.running--
+	ac.grantLocked()
+}
+
+// cpuSampler computes the CPU utilization of the process.
+type cpuSampler struct {
+	lastNanos    int64
+	lastCPUNanos int64
+}
+
+// sample returns the fraction of the machine's CPU that the process used
+// since the previous call.
+func (s *cpuSampler) sample() float64 {
+	var cpu gosigar.ProcTime
+	if err := cpu.Get(os.Getpid()); err != nil {
+		return 0
+	}
+	now := timeutil.Now().UnixNano()
+	// cpu.{User,Sys} are in milliseconds, convert to nanoseconds.
+	cpuNanos := int64(cpu.User+cpu.Sys) * 1e6
+	var utilization float64
+	if s.lastNanos != 0 && now > s.lastNanos {
+		utilization = float64(cpuNanos-s.lastCPUNanos) /
+			float64(now-s.lastNanos) / float64(runtime.NumCPU())
+	}
+	s.lastNanos, s.lastCPUNanos = now, cpuNanos
+	return utilization
+}
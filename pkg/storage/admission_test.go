@@ -0,0 +1,184 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+)
+
+func TestClassifyBatch(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	userKey := roachpb.Key(keys.MakeTablePrefix(keys.MinUserDescID))
+	systemKey := roachpb.Key(keys.MakeTablePrefix(keys.NamespaceTableID))
+	testCases := []struct {
+		req      roachpb.Request
+		expected workClass
+	}{
+		{roachpb.NewGet(userKey), workClassForeground},
+		{roachpb.NewGet(systemKey), workClassSystem},
+		{roachpb.NewGet(keys.NodeLivenessKey(1)), workClassSystem},
+		{&roachpb.GCRequest{RequestHeader: roachpb.RequestHeader{Key: userKey, EndKey: userKey.PrefixEnd()}}, workClassGC},
+		{&roachpb.AddSSTableRequest{RequestHeader: roachpb.RequestHeader{Key: userKey, EndKey: userKey.PrefixEnd()}}, workClassBulk},
+		{&roachpb.ImportRequest{RequestHeader: roachpb.RequestHeader{Key: userKey, EndKey: userKey.PrefixEnd()}}, workClassBulk},
+		{&roachpb.ExportRequest{RequestHeader: roachpb.RequestHeader{Key: userKey, EndKey: userKey.PrefixEnd()}}, workClassBackup},
+		{&roachpb.GCRequest{RequestHeader: roachpb.RequestHeader{Key: systemKey, EndKey: systemKey.PrefixEnd()}}, workClassSystem},
+	}
+	for _, tc := range testCases {
+		var ba roachpb.BatchRequest
+		ba.Add(tc.req)
+		if class := classifyBatch(&ba); class != tc.expected {
+			t.Errorf("%s: expected work class %s, got %s", tc.req.Method(), tc.expected, class)
+		}
+	}
+}
+
+func TestAdmissionController(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+
+	st := cluster.MakeTestingClusterSettings()
+	admissionMaxReadAmplification.Override(&st.SV, 10)
+	admissionMaxCPUUtilization.Override(&st.SV, 0.5)
+	admissionOverloadConcurrency.Override(&st.SV, 1)
+	admissionMaxDelay.Override(&st.SV, time.Hour)
+
+	var readAmp int64 = 5
+	cpu := 0.1
+	ac := newAdmissionController(&st.SV,
+		func() int64 { return atomic.LoadInt64(&readAmp) },
+		func() float64 { return cpu },
+	)
+	ctx := context.Background()
+
+	// Nothing is queued while the store isn't overloaded.
+	ac.tick()
+	for _, class := range []workClass{workClassForeground, workClassBulk, workClassBulk} {
+		if err := ac.admit(ctx, stopper, class); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ac.done(workClassBulk)
+	ac.done(workClassBulk)
+
+	// The CPU utilization overloads the store. A single background request
+	// is processed at a time, while foreground work is still admitted.
+	cpu = 0.8
+	ac.tick()
+	if v := ac.metrics.Overloaded.Value(); v != 1 {
+		t.Fatalf("expected the store to be overloaded, got %d", v)
+	}
+	if err := ac.admit(ctx, stopper, workClassBulk); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.admit(ctx, stopper, workClassForeground); err != nil {
+		t.Fatal(err)
+	}
+
+	// The requests queued behind the bulk request are admitted in the order
+	// of their classes' priority.
+	admitted := make(chan workClass, 3)
+	for _, class := range []workClass{workClassBackup, workClassGC, workClassSnapshot} {
+		class := class
+		_, _, waiting := ac.metrics.classMetrics(class)
+		go func() {
+			if err := ac.admit(ctx, stopper, class); err != nil {
+				t.Error(err)
+			}
+			admitted <- class
+		}()
+		testutils.SucceedsSoon(t, func() error {
+			if v := waiting.Value(); v != 1 {
+				return errors.Errorf("expected 1 waiting %s request, got %d", class, v)
+			}
+			return nil
+		})
+	}
+	ac.done(workClassBulk)
+	for _, expected := range []workClass{workClassSnapshot, workClassGC, workClassBackup} {
+		if class := <-admitted; class != expected {
+			t.Fatalf("expected %s request to be admitted, got %s", expected, class)
+		}
+		select {
+		case class := <-admitted:
+			t.Fatalf("%s request admitted while another request is in progress", class)
+		case <-time.After(10 * time.Millisecond):
+		}
+		ac.done(expected)
+	}
+
+	// A request that waited for too long is admitted regardless.
+	atomic.StoreInt64(&readAmp, 15)
+	cpu = 0.1
+	ac.tick()
+	if err := ac.admit(ctx, stopper, workClassGC); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		go func() {
+			if err := ac.admit(ctx, stopper, workClassBulk); err != nil {
+				t.Error(err)
+			}
+			admitted <- workClassBulk
+		}()
+	}
+	testutils.SucceedsSoon(t, func() error {
+		if v := ac.metrics.WaitingBulk.Value(); v != 2 {
+			return errors.Errorf("expected 2 waiting bulk requests, got %d", v)
+		}
+		return nil
+	})
+	admissionMaxDelay.Override(&st.SV, 0)
+	// Requests which waited for too long are admitted one per tick, so that
+	// they don't all pile onto the overloaded store at once.
+	for i := 0; i < 2; i++ {
+		ac.tick()
+		<-admitted
+		select {
+		case <-admitted:
+			t.Fatal("more than one request admitted by a single tick")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	ac.done(workClassGC)
+	ac.done(workClassBulk)
+	ac.done(workClassBulk)
+
+	if c := ac.metrics.DelayedBulk.Count(); c != 2 {
+		t.Errorf("expected 2 delayed bulk requests, got %d", c)
+	}
+	if c := ac.metrics.AdmittedBulk.Count(); c != 5 {
+		t.Errorf("expected 5 admitted bulk requests, got %d", c)
+	}
+	if c := ac.metrics.DelayedGC.Count(); c != 1 {
+		t.Errorf("expected 1 delayed GC request, got %d", c)
+	}
+	if c := ac.metrics.AdmittedForeground.Count(); c != 2 {
+		t.Errorf("expected 2 admitted foreground requests, got %d", c)
+	}
+}
@@ -51,6 +51,8 @@ const (
 	removeDeadReplicaPriority             float64 = 1000
 	removeDecommissioningReplicaPriority  float64 = 200
 	removeExtraReplicaPriority            float64 = 100
+	removeLearnerPriority                 float64 = 50
+	addLearnerPriority                    float64 = 20
 )
 
 // MinLeaseTransferStatsDuration configures the minimum amount of time a
@@ -101,6 +103,8 @@ const (
 	AllocatorRemoveDead
 	AllocatorRemoveDecommissioning
 	AllocatorConsiderRebalance
+	AllocatorAddLearner
+	AllocatorRemoveLearner
 )
 
 var allocatorActionNames = map[AllocatorAction]string{
@@ -110,6 +114,8 @@ var allocatorActionNames = map[AllocatorAction]string{
 	AllocatorRemoveDead:            "remove dead",
 	AllocatorRemoveDecommissioning: "remove decommissioning",
 	AllocatorConsiderRebalance:     "consider rebalance",
+	AllocatorAddLearner:            "add learner",
+	AllocatorRemoveLearner:         "remove learner",
 }
 
 func (a AllocatorAction) String() string {
@@ -171,9 +177,10 @@ func makeAllocatorRand(source rand.Source) allocatorRand {
 // RangeInfo contains the information needed by the allocator to make
 // rebalancing decisions for a given range.
 type RangeInfo struct {
-	Desc            *roachpb.RangeDescriptor
-	LogicalBytes    int64
-	WritesPerSecond float64
+	Desc             *roachpb.RangeDescriptor
+	LogicalBytes     int64
+	QueriesPerSecond float64
+	WritesPerSecond  float64
 }
 
 func rangeInfoForRepl(repl *Replica, desc *roachpb.RangeDescriptor) RangeInfo {
@@ -181,6 +188,11 @@ func rangeInfoForRepl(repl *Replica, desc *roachpb.RangeDescriptor) RangeInfo {
 		Desc:         desc,
 		LogicalBytes: repl.GetMVCCStats().Total(),
 	}
+	if repl.leaseholderStats != nil {
+		if queriesPerSecond, dur := repl.leaseholderStats.avgQPS(); dur >= MinStatsDuration {
+			info.QueriesPerSecond = queriesPerSecond
+		}
+	}
 	if writesPerSecond, dur := repl.writeStats.avgQPS(); dur >= MinStatsDuration {
 		info.WritesPerSecond = writesPerSecond
 	}
@@ -229,9 +241,13 @@ func (a *Allocator) ComputeAction(
 		return AllocatorNoop, 0
 	}
 
+	// Learners don't count towards quorum, so the voters are repaired first
+	// and the learners are only considered once the voters are in order.
+	voters := rangeInfo.Desc.Voters()
+
 	// TODO(mrtracy): Handle non-homogeneous and mismatched attribute sets.
 	need := int(zone.NumReplicas)
-	have := len(rangeInfo.Desc.Replicas)
+	have := len(voters)
 	quorum := computeQuorum(need)
 	if have < need {
 		// Range is under-replicated, and should add an additional replica.
@@ -242,7 +258,7 @@ func (a *Allocator) ComputeAction(
 		return AllocatorAdd, priority
 	}
 
-	decommissioningReplicas := a.storePool.decommissioningReplicas(rangeInfo.Desc.RangeID, rangeInfo.Desc.Replicas)
+	decommissioningReplicas := a.storePool.decommissioningReplicas(rangeInfo.Desc.RangeID, voters)
 	if have == need && len(decommissioningReplicas) > 0 {
 		// Range has decommissioning replica(s). We should up-replicate to add
 		// another replica. The decommissioning replica(s) will be down-replicated
@@ -253,7 +269,7 @@ func (a *Allocator) ComputeAction(
 		return AllocatorAdd, priority
 	}
 
-	liveReplicas, deadReplicas := a.storePool.liveAndDeadReplicas(rangeInfo.Desc.RangeID, rangeInfo.Desc.Replicas)
+	liveReplicas, deadReplicas := a.storePool.liveAndDeadReplicas(rangeInfo.Desc.RangeID, voters)
 	if len(liveReplicas) < quorum {
 		// Do not take any removal action if we do not have a quorum of live
 		// replicas.
@@ -312,10 +328,95 @@ func (a *Allocator) ComputeAction(
 		return AllocatorRemove, priority
 	}
 
+	learners := rangeInfo.Desc.Learners()
+	if invalid := a.invalidLearners(zone, rangeInfo.Desc); len(invalid) > 0 {
+		// The range has learners on dead or decommissioning stores, or on stores
+		// that no longer satisfy the learner constraints.
+		log.VEventf(ctx, 3, "AllocatorRemoveLearner - invalid=%d, priority=%.2f",
+			len(invalid), removeLearnerPriority)
+		return AllocatorRemoveLearner, removeLearnerPriority
+	}
+	if needLearners := int(zone.NumLearners); len(learners) < needLearners &&
+		a.storePool.st.Version.IsActive(cluster.VersionLearnerReplicas) {
+		log.VEventf(ctx, 3, "AllocatorAddLearner - need=%d, have=%d, priority=%.2f",
+			needLearners, len(learners), addLearnerPriority)
+		return AllocatorAddLearner, addLearnerPriority
+	} else if len(learners) > needLearners {
+		log.VEventf(ctx, 3, "AllocatorRemoveLearner - need=%d, have=%d, priority=%.2f",
+			needLearners, len(learners), removeLearnerPriority)
+		return AllocatorRemoveLearner, removeLearnerPriority
+	}
+
 	// Nothing needs to be done, but we may want to rebalance.
 	return AllocatorConsiderRebalance, 0
 }
 
+// learnerZone returns the zone config to use when placing the learners of a
+// range governed by zone. Learners are only subject to the zone's learner
+// constraints.
+func learnerZone(zone config.ZoneConfig) config.ZoneConfig {
+	lz := zone
+	lz.Constraints = nil
+	if len(zone.LearnerConstraints) > 0 {
+		lz.Constraints = []config.Constraints{{Constraints: zone.LearnerConstraints}}
+	}
+	lz.LeasePreferences = nil
+	return lz
+}
+
+// invalidLearners returns the learners of the range which should be removed
+// regardless of the number of learners the zone asks for: those on dead or
+// decommissioning stores and those on stores which don't satisfy the zone's
+// learner constraints.
+func (a *Allocator) invalidLearners(
+	zone config.ZoneConfig, desc *roachpb.RangeDescriptor,
+) []roachpb.ReplicaDescriptor {
+	learners := desc.Learners()
+	if len(learners) == 0 {
+		return nil
+	}
+	_, invalid := a.storePool.liveAndDeadReplicas(desc.RangeID, learners)
+	invalid = append(invalid, a.storePool.decommissioningReplicas(desc.RangeID, learners)...)
+	for _, learner := range learners {
+		storeDesc, ok := a.storePool.getStoreDescriptor(learner.StoreID)
+		if !ok {
+			continue
+		}
+		for _, constraint := range zone.LearnerConstraints {
+			if !config.StoreMatchesConstraint(storeDesc, constraint) {
+				invalid = append(invalid, learner)
+				break
+			}
+		}
+	}
+	return invalid
+}
+
+// excludeStoresWithReplicas returns the stores in sl that don't hold any of
+// the given replicas.
+func excludeStoresWithReplicas(sl StoreList, repls []roachpb.ReplicaDescriptor) StoreList {
+	var descs []roachpb.StoreDescriptor
+	for _, s := range sl.stores {
+		if !storeHasReplica(s.StoreID, repls) {
+			descs = append(descs, s)
+		}
+	}
+	return makeStoreList(descs)
+}
+
+// AllocateLearnerTarget returns a suitable store for a new learner replica of
+// the range. It is the equivalent of AllocateTarget for learners.
+func (a *Allocator) AllocateLearnerTarget(
+	ctx context.Context,
+	zone config.ZoneConfig,
+	rangeInfo RangeInfo,
+	disableStatsBasedRebalancing bool,
+) (*roachpb.StoreDescriptor, string, error) {
+	return a.AllocateTarget(
+		ctx, learnerZone(zone), rangeInfo.Desc.Replicas, rangeInfo, disableStatsBasedRebalancing,
+	)
+}
+
 type decisionDetails struct {
 	Target               string
 	Existing             string  `json:",omitempty"`
@@ -338,7 +439,7 @@ func (a *Allocator) AllocateTarget(
 	sl, aliveStoreCount, throttledStoreCount := a.storePool.getStoreList(rangeInfo.Desc.RangeID, storeFilterThrottled)
 
 	analyzedConstraints := analyzeConstraints(
-		ctx, a.storePool.getStoreDescriptor, rangeInfo.Desc.Replicas, zone)
+		ctx, a.storePool.getStoreDescriptor, rangeInfo.Desc.Voters(), zone)
 	options := a.scorerOptions(disableStatsBasedRebalancing)
 	candidates := allocateCandidates(
 		sl, analyzedConstraints, existing, rangeInfo, a.storePool.getLocalities(existing), options,
@@ -414,7 +515,7 @@ func (a Allocator) RemoveTarget(
 	sl, _, _ := a.storePool.getStoreListFromIDs(existingStoreIDs, roachpb.RangeID(0), storeFilterNone)
 
 	analyzedConstraints := analyzeConstraints(
-		ctx, a.storePool.getStoreDescriptor, rangeInfo.Desc.Replicas, zone)
+		ctx, a.storePool.getStoreDescriptor, rangeInfo.Desc.Voters(), zone)
 	options := a.scorerOptions(disableStatsBasedRebalancing)
 	rankedCandidates := removeCandidates(
 		sl,
@@ -473,6 +574,15 @@ func (a Allocator) RebalanceTarget(
 ) (*roachpb.StoreDescriptor, string) {
 	sl, _, _ := a.storePool.getStoreList(rangeInfo.Desc.RangeID, filter)
 
+	// Learners are placed independently of the voters and aren't rebalanced
+	// here. Rebalance the voters among the stores that don't hold a learner.
+	if learners := rangeInfo.Desc.Learners(); len(learners) > 0 {
+		votersDesc := *rangeInfo.Desc
+		votersDesc.Replicas = rangeInfo.Desc.Voters()
+		rangeInfo.Desc = &votersDesc
+		sl = excludeStoresWithReplicas(sl, learners)
+	}
+
 	// We're going to add another replica to the range which will change the
 	// quorum size. Verify that the number of existing live replicas is sufficient
 	// to meet the new quorum. For a range configured for 3 replicas, this will
@@ -502,7 +612,7 @@ func (a Allocator) RebalanceTarget(
 	}
 
 	analyzedConstraints := analyzeConstraints(
-		ctx, a.storePool.getStoreDescriptor, rangeInfo.Desc.Replicas, zone)
+		ctx, a.storePool.getStoreDescriptor, rangeInfo.Desc.Voters(), zone)
 	options := a.scorerOptions(disableStatsBasedRebalancing)
 	results := rebalanceCandidates(
 		ctx,
@@ -598,9 +708,30 @@ func (a *Allocator) scorerOptions(disableStatsBasedRebalancing bool) scorerOptio
 		statsBasedRebalancingEnabled: statsBasedRebalancingEnabled(a.storePool.st, disableStatsBasedRebalancing),
 		rangeRebalanceThreshold:      rangeRebalanceThreshold.Get(&a.storePool.st.SV),
 		statRebalanceThreshold:       statRebalanceThreshold.Get(&a.storePool.st.SV),
+		maxReadAmplification:         maxReadAmplification.Get(&a.storePool.st.SV),
 	}
 }
 
+// excludeHighReadAmplification removes the replicas on stores whose read
+// amplification is too high for them to take on leases, except for the one on
+// the leaseholder's store, which the lease decisions still need.
+func (a *Allocator) excludeHighReadAmplification(
+	existing []roachpb.ReplicaDescriptor, leaseStoreID roachpb.StoreID,
+) []roachpb.ReplicaDescriptor {
+	maxReadAmp := maxReadAmplification.Get(&a.storePool.st.SV)
+	filtered := make([]roachpb.ReplicaDescriptor, 0, len(existing))
+	for _, repl := range existing {
+		if repl.StoreID != leaseStoreID {
+			storeDesc, ok := a.storePool.getStoreDescriptor(repl.StoreID)
+			if ok && !readAmplificationCheck(storeDesc, maxReadAmp) {
+				continue
+			}
+		}
+		filtered = append(filtered, repl)
+	}
+	return filtered
+}
+
 // TransferLeaseTarget returns a suitable replica to transfer the range lease
 // to from the provided list. It excludes the current lease holder replica
 // unless asked to do otherwise by the checkTransferLeaseSource parameter.
@@ -679,14 +810,22 @@ func (a *Allocator) TransferLeaseTarget(
 		}
 	}
 
-	// Only consider live, non-draining replicas.
+	// Only consider live, non-draining replicas on stores that can take on
+	// leases.
 	existing, _ = a.storePool.liveAndDeadReplicas(rangeID, existing)
+	existing = a.excludeHighReadAmplification(existing, leaseStoreID)
 
 	// Short-circuit if there are no valid targets out there.
 	if len(existing) == 0 || (len(existing) == 1 && existing[0].StoreID == leaseStoreID) {
 		return roachpb.ReplicaDescriptor{}
 	}
 
+	// Move the lease off of a store whose read amplification is too high,
+	// regardless of how the leases are balanced.
+	if !readAmplificationCheck(source, maxReadAmplification.Get(&a.storePool.st.SV)) {
+		checkTransferLeaseSource = false
+	}
+
 	// Try to pick a replica to transfer the lease to while also determining
 	// whether we actually should be transferring the lease. The transfer
 	// decision is only needed if we've been asked to check the source.
@@ -783,14 +922,22 @@ func (a *Allocator) ShouldTransferLease(
 	sl = sl.filter(zone.Constraints)
 	log.VEventf(ctx, 3, "ShouldTransferLease (lease-holder=%d):\n%s", leaseStoreID, sl)
 
-	// Only consider live, non-draining replicas.
+	// Only consider live, non-draining replicas on stores that can take on
+	// leases.
 	existing, _ = a.storePool.liveAndDeadReplicas(rangeID, existing)
+	existing = a.excludeHighReadAmplification(existing, leaseStoreID)
 
 	// Short-circuit if there are no valid targets out there.
 	if len(existing) == 0 || (len(existing) == 1 && existing[0].StoreID == source.StoreID) {
 		return false
 	}
 
+	if !readAmplificationCheck(source, maxReadAmplification.Get(&a.storePool.st.SV)) {
+		log.VEventf(ctx, 3, "ShouldTransferLease (lease-holder=%d): read amplification %d",
+			leaseStoreID, source.Capacity.ReadAmplification)
+		return true
+	}
+
 	transferDec, _ := a.shouldTransferLeaseUsingStats(ctx, sl, source, existing, stats)
 	var result bool
 	switch transferDec {
@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/pkg/errors"
 )
 
 const (
@@ -89,11 +90,28 @@ var statRebalanceThreshold = settings.RegisterNonNegativeFloatSetting(
 	0.20,
 )
 
+// maxReadAmplification is the read amplification above which a store's LSM is
+// considered unhealthy, which usually happens while bulk ingestions fill level
+// 0 with sstables faster than they can be compacted away. Such a store doesn't
+// receive new replicas or leases, and sheds its leases, until it recovers.
+var maxReadAmplification = settings.RegisterValidatedIntSetting(
+	"kv.allocator.max_read_amplification",
+	"read amplification above which a store stops receiving replicas and leases and sheds its leases (0 to disable)",
+	40,
+	func(v int64) error {
+		if v < 0 {
+			return errors.Errorf("cannot set kv.allocator.max_read_amplification to a negative value: %d", v)
+		}
+		return nil
+	},
+)
+
 type scorerOptions struct {
 	deterministic                bool
 	statsBasedRebalancingEnabled bool
 	rangeRebalanceThreshold      float64
 	statRebalanceThreshold       float64
+	maxReadAmplification         int64
 }
 
 type balanceDimensions struct {
@@ -440,6 +458,9 @@ func allocateCandidates(
 		if !maxCapacityCheck(s) {
 			continue
 		}
+		if !readAmplificationCheck(s, options.maxReadAmplification) {
+			continue
+		}
 		diversityScore := diversityAllocateScore(s, existingNodeLocalities)
 		balanceScore := balanceScore(sl, s.Capacity, rangeInfo, options)
 		candidates = append(candidates, candidate{
@@ -740,6 +761,11 @@ func rebalanceCandidates(
 					s, rangeInfo, cand.balanceScore, comparable.sl)
 				continue
 			}
+			if !readAmplificationCheck(s, options.maxReadAmplification) {
+				log.VEventf(ctx, 3, "not considering %+v as a candidate for range %+v: read amplification %d",
+					s, rangeInfo, s.Capacity.ReadAmplification)
+				continue
+			}
 			cand.rangeCount = int(s.Capacity.RangeCount)
 			candidates = append(candidates, cand)
 		}
@@ -1538,6 +1564,13 @@ func maxCapacityCheck(store roachpb.StoreDescriptor) bool {
 	return store.Capacity.FractionUsed() < maxFractionUsedThreshold
 }
 
+// readAmplificationCheck returns true if the store's read amplification is low
+// enough for it to receive new replicas and leases. A maxReadAmp of 0 disables
+// the check.
+func readAmplificationCheck(store roachpb.StoreDescriptor, maxReadAmp int64) bool {
+	return maxReadAmp == 0 || int64(store.Capacity.ReadAmplification) <= maxReadAmp
+}
+
 // rebalanceToMaxCapacityCheck returns true if the store has enough room to
 // accept a rebalance. The bar for this is stricter than for whether a store
 // has enough room to accept a necessary replica (i.e. via AllocateCandidates).
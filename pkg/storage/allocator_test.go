@@ -1626,6 +1626,115 @@ func TestAllocatorShouldTransferLeaseDraining(t *testing.T) {
 	}
 }
 
+// TestAllocatorReadAmplification verifies that stores whose read
+// amplification is above kv.allocator.max_read_amplification don't receive
+// replicas or leases, and shed their leases.
+func TestAllocatorReadAmplification(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper, g, _, a, _ := createTestAllocator( /* deterministic */ true)
+	defer stopper.Stop(context.Background())
+
+	// 3 stores with the same number of ranges and leases, where store 1 has a
+	// high read amplification.
+	var stores []*roachpb.StoreDescriptor
+	for i := 1; i <= 3; i++ {
+		readAmp := int32(5)
+		if i == 1 {
+			readAmp = 100
+		}
+		stores = append(stores, &roachpb.StoreDescriptor{
+			StoreID: roachpb.StoreID(i),
+			Node:    roachpb.NodeDescriptor{NodeID: roachpb.NodeID(i)},
+			Capacity: roachpb.StoreCapacity{
+				RangeCount:        10,
+				LeaseCount:        10,
+				ReadAmplification: readAmp,
+			},
+		})
+	}
+	sg := gossiputil.NewStoreGossiper(g)
+	sg.GossipStores(stores, t)
+
+	for i := 0; i < 10; i++ {
+		result, _, err := a.AllocateTarget(
+			context.Background(),
+			simpleZoneConfig,
+			[]roachpb.ReplicaDescriptor{},
+			firstRangeInfo,
+			false,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.StoreID == 1 {
+			t.Fatalf("expected store 1 not to be chosen: %+v", result)
+		}
+	}
+
+	shouldTransferTestCases := []struct {
+		leaseholder roachpb.StoreID
+		existing    []roachpb.ReplicaDescriptor
+		expected    bool
+	}{
+		{leaseholder: 1, existing: replicas(1, 2, 3), expected: true},
+		{leaseholder: 1, existing: replicas(1), expected: false},
+		{leaseholder: 2, existing: replicas(1, 2, 3), expected: false},
+	}
+	for _, c := range shouldTransferTestCases {
+		t.Run("", func(t *testing.T) {
+			result := a.ShouldTransferLease(
+				context.Background(),
+				config.ZoneConfig{},
+				c.existing,
+				c.leaseholder,
+				0,
+				nil, /* replicaStats */
+			)
+			if c.expected != result {
+				t.Fatalf("expected %v, but found %v", c.expected, result)
+			}
+		})
+	}
+
+	transferTargetTestCases := []struct {
+		leaseholder roachpb.StoreID
+		existing    []roachpb.ReplicaDescriptor
+		check       bool
+		expected    roachpb.StoreID
+	}{
+		{leaseholder: 1, existing: replicas(1, 2, 3), check: true, expected: 2},
+		{leaseholder: 1, existing: replicas(1, 3), check: true, expected: 3},
+		{leaseholder: 2, existing: replicas(1, 2), check: false, expected: 0},
+		{leaseholder: 2, existing: replicas(1, 2, 3), check: false, expected: 3},
+	}
+	for _, c := range transferTargetTestCases {
+		t.Run("", func(t *testing.T) {
+			target := a.TransferLeaseTarget(
+				context.Background(),
+				config.ZoneConfig{},
+				c.existing,
+				c.leaseholder,
+				0,
+				nil, /* replicaStats */
+				c.check,
+				true,  /* checkCandidateFullness */
+				false, /* alwaysAllowDecisionWithoutStats */
+			)
+			if c.expected != target.StoreID {
+				t.Fatalf("expected %d, but found %d", c.expected, target.StoreID)
+			}
+		})
+	}
+
+	// Once the check is disabled, store 1 keeps its leases.
+	maxReadAmplification.Override(&a.storePool.st.SV, 0)
+	if a.ShouldTransferLease(
+		context.Background(), config.ZoneConfig{}, replicas(1, 2, 3), 1, 0, nil, /* replicaStats */
+	) {
+		t.Fatal("expected store 1 to keep its lease")
+	}
+}
+
 func TestAllocatorLeasePreferences(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	stopper, g, _, a, _ := createTestAllocator( /* deterministic */ true)
@@ -4731,6 +4840,114 @@ func TestAllocatorComputeActionDecommission(t *testing.T) {
 	}
 }
 
+// TestAllocatorComputeActionLearners verifies that learners don't count
+// towards the voters a range needs and that the number of learners is
+// repaired once the voters are in order.
+func TestAllocatorComputeActionLearners(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	voters := []roachpb.ReplicaDescriptor{
+		{StoreID: 1, NodeID: 1, ReplicaID: 1},
+		{StoreID: 2, NodeID: 2, ReplicaID: 2},
+		{StoreID: 3, NodeID: 3, ReplicaID: 3},
+	}
+	learner := func(id int) roachpb.ReplicaDescriptor {
+		return roachpb.ReplicaDescriptor{
+			StoreID:   roachpb.StoreID(id),
+			NodeID:    roachpb.NodeID(id),
+			ReplicaID: roachpb.ReplicaID(id),
+			Type:      roachpb.ReplicaType_LEARNER.Enum(),
+		}
+	}
+	withLearners := func(repls []roachpb.ReplicaDescriptor, ids ...int) []roachpb.ReplicaDescriptor {
+		res := append([]roachpb.ReplicaDescriptor(nil), repls...)
+		for _, id := range ids {
+			res = append(res, learner(id))
+		}
+		return res
+	}
+
+	testCases := []struct {
+		zone           config.ZoneConfig
+		replicas       []roachpb.ReplicaDescriptor
+		expectedAction AllocatorAction
+		live           []roachpb.StoreID
+		dead           []roachpb.StoreID
+	}{
+		// Missing a learner.
+		{
+			zone:           config.ZoneConfig{NumReplicas: 3, NumLearners: 1},
+			replicas:       voters,
+			expectedAction: AllocatorAddLearner,
+			live:           []roachpb.StoreID{1, 2, 3, 4},
+		},
+		// The learner doesn't stand in for a missing voter.
+		{
+			zone:           config.ZoneConfig{NumReplicas: 3, NumLearners: 1},
+			replicas:       withLearners(voters[:2], 4),
+			expectedAction: AllocatorAdd,
+			live:           []roachpb.StoreID{1, 2, 3, 4},
+		},
+		// All good.
+		{
+			zone:           config.ZoneConfig{NumReplicas: 3, NumLearners: 1},
+			replicas:       withLearners(voters, 4),
+			expectedAction: AllocatorConsiderRebalance,
+			live:           []roachpb.StoreID{1, 2, 3, 4},
+		},
+		// One learner too many.
+		{
+			zone:           config.ZoneConfig{NumReplicas: 3, NumLearners: 1},
+			replicas:       withLearners(voters, 4, 5),
+			expectedAction: AllocatorRemoveLearner,
+			live:           []roachpb.StoreID{1, 2, 3, 4, 5},
+		},
+		// The learner is on a dead store.
+		{
+			zone:           config.ZoneConfig{NumReplicas: 3, NumLearners: 1},
+			replicas:       withLearners(voters, 4),
+			expectedAction: AllocatorRemoveLearner,
+			live:           []roachpb.StoreID{1, 2, 3},
+			dead:           []roachpb.StoreID{4},
+		},
+		// The learner doesn't satisfy the learner constraints.
+		{
+			zone: config.ZoneConfig{
+				NumReplicas: 3,
+				NumLearners: 1,
+				LearnerConstraints: []config.Constraint{
+					{Key: "region", Value: "eu", Type: config.Constraint_REQUIRED},
+				},
+			},
+			replicas:       withLearners(voters, 4),
+			expectedAction: AllocatorRemoveLearner,
+			live:           []roachpb.StoreID{1, 2, 3, 4},
+		},
+		// A dead learner doesn't prevent removing a voter.
+		{
+			zone:           config.ZoneConfig{NumReplicas: 1},
+			replicas:       withLearners(voters, 4),
+			expectedAction: AllocatorRemove,
+			live:           []roachpb.StoreID{1, 2, 3},
+			dead:           []roachpb.StoreID{4},
+		},
+	}
+
+	stopper, _, sp, a, _ := createTestAllocator( /* deterministic */ false)
+	ctx := context.Background()
+	defer stopper.Stop(ctx)
+
+	for i, tcase := range testCases {
+		mockStorePool(sp, tcase.live, tcase.dead, nil, nil, nil)
+
+		desc := roachpb.RangeDescriptor{Replicas: tcase.replicas}
+		action, _ := a.ComputeAction(ctx, tcase.zone, RangeInfo{Desc: &desc}, false)
+		if tcase.expectedAction != action {
+			t.Errorf("%d: expected action %s, got %s", i, tcase.expectedAction, action)
+		}
+	}
+}
+
 // TestAllocatorComputeActionNoStorePool verifies that
 // ComputeAction returns AllocatorNoop when storePool is nil.
 func TestAllocatorComputeActionNoStorePool(t *testing.T) {
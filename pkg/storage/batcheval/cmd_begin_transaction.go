@@ -88,7 +88,7 @@ func BeginTransaction(
 			// txn.
 			return result.Result{}, roachpb.NewTransactionAbortedError()
 
-		case roachpb.PENDING:
+		case roachpb.PENDING, roachpb.STAGING:
 			if h.Txn.Epoch > tmpTxn.Epoch {
 				// On a transaction retry there will be an extant txn record
 				// but this run should have an upgraded epoch. The extant txn
@@ -67,6 +67,14 @@ func ClearRange(
 	args := cArgs.Args.(*roachpb.ClearRangeRequest)
 	from := engine.MVCCKey{Key: args.Key}
 	to := engine.MVCCKey{Key: args.EndKey}
+	return clearSpan(ctx, batch, cArgs, from, to)
+}
+
+// clearSpan wipes all MVCC versions of the keys in [from, to), adjusting the
+// MVCC stats accordingly.
+func clearSpan(
+	ctx context.Context, batch engine.ReadWriter, cArgs CommandArgs, from, to engine.MVCCKey,
+) (result.Result, error) {
 	var pd result.Result
 
 	// Before clearing, compute the delta in MVCCStats.
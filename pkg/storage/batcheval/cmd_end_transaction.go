@@ -208,7 +208,7 @@ func evalEndTransaction(
 		reply.Txn.Intents = args.IntentSpans
 		return result.FromEndTxn(reply.Txn, true /* alwaysReturn */, args.Poison), roachpb.NewTransactionAbortedError()
 
-	case roachpb.PENDING:
+	case roachpb.PENDING, roachpb.STAGING:
 		if h.Txn.Epoch < reply.Txn.Epoch {
 			// TODO(tschottdorf): this leaves the Txn record (and more
 			// importantly, intents) dangling; we can't currently write on
@@ -236,6 +236,9 @@ func evalEndTransaction(
 
 	// Update the existing txn with the supplied txn.
 	reply.Txn.Update(h.Txn)
+	// Any writes which were in flight when the record was staged have been
+	// accounted for by whoever is finalizing the transaction.
+	reply.Txn.InFlightWrites = nil
 
 	var pd result.Result
 
@@ -262,6 +265,21 @@ func evalEndTransaction(
 				"transaction deadline exceeded")
 		}
 
+		// If some of the transaction's writes are still in flight, stage the
+		// commit instead. The transaction is implicitly committed once all of
+		// these writes succeed, and is made explicitly committed afterwards
+		// by its coordinator or by a pusher recovering it. Intents are left
+		// in place until then.
+		if len(args.InFlightWrites) > 0 {
+			if args.InternalCommitTrigger != nil {
+				return result.Result{}, errors.Errorf("cannot stage a commit with a commit trigger: %s", reply.Txn)
+			}
+			reply.Txn.Status = roachpb.STAGING
+			reply.Txn.InFlightWrites = args.InFlightWrites
+			reply.Txn.Intents = args.IntentSpans
+			return result.Result{}, engine.MVCCPutProto(ctx, batch, ms, key, hlc.Timestamp{}, nil, reply.Txn)
+		}
+
 		reply.Txn.Status = roachpb.COMMITTED
 
 		// Merge triggers must run before intent resolution as the merge trigger
@@ -24,6 +24,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/spanset"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/pkg/errors"
 )
 
 func init() {
@@ -45,6 +47,11 @@ func declareKeysGC(
 	if gcr.Threshold != (hlc.Timestamp{}) {
 		spans.Add(spanset.SpanReadWrite, roachpb.Span{Key: keys.RangeLastGCKey(header.RangeID)})
 	}
+	// Clearing the whole range must not race with writes to it, which the
+	// MVCC stats it relies on wouldn't reflect yet.
+	if gcr.ClearRange {
+		spans.Add(spanset.SpanReadWrite, gcr.Span())
+	}
 	if gcr.TxnSpanGCThreshold != (hlc.Timestamp{}) {
 		spans.Add(spanset.SpanReadWrite, roachpb.Span{
 			// TODO(bdarnell): since this must be checked by all
@@ -93,11 +100,29 @@ func GC(
 
 	var newThreshold hlc.Timestamp
 	if args.Threshold != (hlc.Timestamp{}) {
+		// The GC queue holds the threshold below the protected timestamps it
+		// knows about, but its view of them may be stale.
+		if protected := cArgs.EvalCtx.GetProtectedTimestamp(); protected != (hlc.Timestamp{}) &&
+			!args.Threshold.Less(protected) {
+			return result.Result{}, errors.Errorf(
+				"GC threshold %s would remove data protected at %s", args.Threshold, protected)
+		}
 		oldThreshold := cArgs.EvalCtx.GetGCThreshold()
 		newThreshold = oldThreshold
 		newThreshold.Forward(args.Threshold)
 	}
 
+	var pd result.Result
+	if args.ClearRange {
+		if newThreshold == (hlc.Timestamp{}) {
+			return result.Result{}, errors.New("GC ClearRange requires a GC threshold")
+		}
+		var err error
+		if pd, err = gcClearRange(ctx, batch, cArgs, newThreshold); err != nil {
+			return result.Result{}, err
+		}
+	}
+
 	var newTxnSpanGCThreshold hlc.Timestamp
 	if args.TxnSpanGCThreshold != (hlc.Timestamp{}) {
 		oldTxnSpanGCThreshold := cArgs.EvalCtx.GetTxnSpanGCThreshold()
@@ -105,7 +130,6 @@ func GC(
 		newTxnSpanGCThreshold.Forward(args.TxnSpanGCThreshold)
 	}
 
-	var pd result.Result
 	stateLoader := MakeStateLoader(cArgs.EvalCtx)
 
 	// Don't write these keys unless we have to. We also don't declare these
@@ -135,3 +159,32 @@ func GC(
 	}
 	return pd, nil
 }
+
+// gcClearRange removes all the versions of all the user keys in the range, for
+// a GC request with ClearRange set. This is only allowed if none of them can
+// be read at or above the GC threshold, which is the case if there are no live
+// keys or intents left in the range and it hasn't been written to since.
+func gcClearRange(
+	ctx context.Context, batch engine.ReadWriter, cArgs CommandArgs, threshold hlc.Timestamp,
+) (result.Result, error) {
+	args := cArgs.Args.(*roachpb.GCRequest)
+	desc := cArgs.EvalCtx.Desc()
+	if !desc.StartKey.Equal(args.Key) || !desc.EndKey.Equal(args.EndKey) {
+		return result.Result{}, errors.Errorf(
+			"GC ClearRange of %s must span the whole range %s", args.Span(), desc)
+	}
+	ms := cArgs.EvalCtx.GetMVCCStats()
+	if ms.ContainsEstimates || ms.LiveCount != 0 || ms.IntentCount != 0 ||
+		ms.LastUpdateNanos >= threshold.WallTime {
+		return result.Result{}, errors.Errorf(
+			"range %s still has data at or above the GC threshold %s", desc, threshold)
+	}
+
+	// The first range also holds the range-local keys, which aren't cleared.
+	from, to := engine.MVCCKey{Key: args.Key}, engine.MVCCKey{Key: args.EndKey}
+	if from.Key.Compare(keys.LocalMax) < 0 {
+		from.Key = keys.LocalMax
+	}
+	log.VEventf(ctx, 2, "clearing [%s,%s) below the GC threshold %s", from.Key, to.Key, threshold)
+	return clearSpan(ctx, batch, cArgs, from, to)
+}
@@ -127,8 +127,10 @@ func GetSnapshotForMerge(
 	eng := engine.NewInMem(roachpb.Attributes{}, 1<<20)
 	defer eng.Close()
 
-	// TODO(benesch): This command reads the whole replica into memory. We'll need
-	// to be more careful when merging large ranges.
+	// This command reads the whole replica into memory. Register the data with
+	// the store's evaluation memory budget as it's read, so that merging a
+	// range which is too large fails the merge instead of exhausting the
+	// node's memory.
 	snapBatch := eng.NewBatch()
 	defer snapBatch.Close()
 
@@ -140,13 +142,17 @@ func GetSnapshotForMerge(
 		} else if !ok {
 			break
 		}
-		if err := snapBatch.Put(iter.Key(), iter.Value()); err != nil {
+		key, value := iter.Key(), iter.Value()
+		if err := GrowEvalMemory(ctx, cArgs, int64(key.EncodedSize()+len(value))); err != nil {
+			return result.Result{}, err
+		}
+		if err := snapBatch.Put(key, value); err != nil {
 			return result.Result{}, err
 		}
 	}
 	reply.Data = snapBatch.Repr()
 
-	return result.Result{
-		Local: result.LocalResult{SetMerging: true},
-	}, nil
+	// Have the replica block all traffic until the merge completes; see the
+	// trigger handler registered in package storage.
+	return result.WithTrigger(roachpb.GetSnapshotForMerge, nil /* payload */), nil
 }
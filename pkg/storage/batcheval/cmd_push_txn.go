@@ -153,7 +153,16 @@ func PushTxn(
 	// Start with the persisted transaction record as final transaction.
 	reply.PusheeTxn = existTxn.Clone()
 
-	// If already committed or aborted, return success.
+	// A STAGING transaction may already be implicitly committed, so it can be
+	// neither aborted nor pushed. Until it expires, the pusher has to wait
+	// for its coordinator to finalize it. After that, the pusher recovers it
+	// by checking whether all of its in-flight writes succeeded.
+	if reply.PusheeTxn.Status == roachpb.STAGING && !txnwait.IsExpired(args.Now, &reply.PusheeTxn) {
+		return result.Result{}, roachpb.NewTransactionPushError(reply.PusheeTxn)
+	}
+
+	// If already committed or aborted, return success. An expired STAGING
+	// transaction is returned as is for the pusher to recover.
 	if reply.PusheeTxn.Status != roachpb.PENDING {
 		// Trivial noop.
 		return result.Result{}, nil
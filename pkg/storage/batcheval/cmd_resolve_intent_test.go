@@ -43,6 +43,7 @@ type mockEvalCtx struct {
 	stats           enginepb.MVCCStats
 	abortSpan       *abortspan.AbortSpan
 	gcThreshold     hlc.Timestamp
+	engine          engine.Engine
 }
 
 func (m *mockEvalCtx) String() string {
@@ -58,7 +59,10 @@ func (m *mockEvalCtx) Tracer() opentracing.Tracer {
 	panic("unimplemented")
 }
 func (m *mockEvalCtx) Engine() engine.Engine {
-	panic("unimplemented")
+	if m.engine == nil {
+		panic("unimplemented")
+	}
+	return m.engine
 }
 func (m *mockEvalCtx) Clock() *hlc.Clock {
 	return m.clock
@@ -108,6 +112,9 @@ func (m *mockEvalCtx) GetGCThreshold() hlc.Timestamp {
 func (m *mockEvalCtx) GetTxnSpanGCThreshold() hlc.Timestamp {
 	panic("unimplemented")
 }
+func (m *mockEvalCtx) GetProtectedTimestamp() hlc.Timestamp {
+	panic("unimplemented")
+}
 func (m *mockEvalCtx) GetLastReplicaGCTimestamp(context.Context) (hlc.Timestamp, error) {
 	panic("unimplemented")
 }
@@ -37,16 +37,31 @@ func ReverseScan(
 	h := cArgs.Header
 	reply := resp.(*roachpb.ReverseScanResponse)
 
-	rows, resumeSpan, intents, err := engine.MVCCReverseScan(ctx, batch, args.Key, args.EndKey,
-		cArgs.MaxKeys, h.Timestamp, h.ReadConsistency == roachpb.CONSISTENT, h.Txn)
+	rows, resumeSpan, intents, err := engine.MVCCScanWithReadahead(ctx, batch, args.Key, args.EndKey,
+		cArgs.MaxKeys, h.Timestamp, h.ReadConsistency == roachpb.CONSISTENT, h.Txn,
+		true /* reverse */, scanReadaheadSize(ctx, cArgs, args.Span()))
 	if err != nil {
 		return result.Result{}, err
 	}
 
+	resumeReason := roachpb.RESUME_KEY_LIMIT
+	n, numBytes := rowsWithinTargetBytes(rows, cArgs.TargetBytes)
+	if n < len(rows) {
+		// The rows are in descending order, so the remaining keys are those
+		// up to and including the first one which isn't returned.
+		resumeSpan = &roachpb.Span{Key: args.Key, EndKey: rows[n].Key.Next()}
+		resumeReason = roachpb.RESUME_BYTE_LIMIT
+		rows = rows[:n]
+	}
+	if err := GrowEvalMemory(ctx, cArgs, numBytes); err != nil {
+		return result.Result{}, err
+	}
+
 	reply.NumKeys = int64(len(rows))
+	reply.NumBytes = numBytes
 	if resumeSpan != nil {
 		reply.ResumeSpan = resumeSpan
-		reply.ResumeReason = roachpb.RESUME_KEY_LIMIT
+		reply.ResumeReason = resumeReason
 	}
 	reply.Rows = rows
 	if h.ReadConsistency == roachpb.READ_UNCOMMITTED {
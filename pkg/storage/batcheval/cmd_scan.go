@@ -18,10 +18,24 @@ import (
 	"context"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/storage/batcheval/result"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
 )
 
+// scanMaxReadaheadSize is the maximum number of bytes read ahead by the
+// iterators serving scans which are expected to return many keys.
+var scanMaxReadaheadSize = settings.RegisterByteSizeSetting(
+	"kv.scan.max_readahead_size",
+	"maximum number of bytes read ahead from disk by scans which are expected to be long (0 disables readahead)",
+	2<<20,
+)
+
+// scanMinReadaheadSize is the readahead below which a scan is considered
+// too short to benefit from it.
+const scanMinReadaheadSize = 256 << 10
+
 func init() {
 	RegisterCommand(roachpb.Scan, DefaultDeclareKeys, Scan)
 }
@@ -37,16 +51,29 @@ func Scan(
 	h := cArgs.Header
 	reply := resp.(*roachpb.ScanResponse)
 
-	rows, resumeSpan, intents, err := engine.MVCCScan(ctx, batch, args.Key, args.EndKey,
-		cArgs.MaxKeys, h.Timestamp, h.ReadConsistency == roachpb.CONSISTENT, h.Txn)
+	rows, resumeSpan, intents, err := engine.MVCCScanWithReadahead(ctx, batch, args.Key, args.EndKey,
+		cArgs.MaxKeys, h.Timestamp, h.ReadConsistency == roachpb.CONSISTENT, h.Txn,
+		false /* reverse */, scanReadaheadSize(ctx, cArgs, args.Span()))
 	if err != nil {
 		return result.Result{}, err
 	}
 
+	resumeReason := roachpb.RESUME_KEY_LIMIT
+	n, numBytes := rowsWithinTargetBytes(rows, cArgs.TargetBytes)
+	if n < len(rows) {
+		resumeSpan = &roachpb.Span{Key: rows[n].Key, EndKey: args.EndKey}
+		resumeReason = roachpb.RESUME_BYTE_LIMIT
+		rows = rows[:n]
+	}
+	if err := GrowEvalMemory(ctx, cArgs, numBytes); err != nil {
+		return result.Result{}, err
+	}
+
 	reply.NumKeys = int64(len(rows))
+	reply.NumBytes = numBytes
 	if resumeSpan != nil {
 		reply.ResumeSpan = resumeSpan
-		reply.ResumeReason = roachpb.RESUME_KEY_LIMIT
+		reply.ResumeReason = resumeReason
 	}
 	reply.Rows = rows
 	if h.ReadConsistency == roachpb.READ_UNCOMMITTED {
@@ -54,3 +81,61 @@ func Scan(
 	}
 	return result.FromIntents(intents, args), err
 }
+
+// rowsWithinTargetBytes returns how many of the leading rows can be returned
+// without going past targetBytes, along with their size in bytes. The row
+// which reaches the target is included, so at least one row is returned. A
+// zero targetBytes means that all rows can be returned.
+func rowsWithinTargetBytes(rows []roachpb.KeyValue, targetBytes int64) (int, int64) {
+	var numBytes int64
+	for i := range rows {
+		if targetBytes > 0 && numBytes >= targetBytes {
+			return i, numBytes
+		}
+		numBytes += int64(len(rows[i].Key) + len(rows[i].Value.RawBytes))
+	}
+	return len(rows), numBytes
+}
+
+// scanReadaheadSize returns the number of bytes to read ahead when serving a
+// scan of the given span, based on the number of keys the client expects the
+// scan to return and the average size of the keys in the range. No readahead
+// is requested unless the client expects a long scan, and the readahead is
+// capped by the approximate on-disk size of the span, so that short scans
+// (in particular lookups of a single row) don't pay for it.
+func scanReadaheadSize(ctx context.Context, cArgs CommandArgs, span roachpb.Span) int64 {
+	expectedKeys := cArgs.Header.ExpectedScanKeys
+	if expectedKeys <= 0 {
+		return 0
+	}
+	if cArgs.MaxKeys > 0 && cArgs.MaxKeys < expectedKeys {
+		expectedKeys = cArgs.MaxKeys
+	}
+	maxSize := scanMaxReadaheadSize.Get(&cArgs.EvalCtx.ClusterSettings().SV)
+	if maxSize < scanMinReadaheadSize {
+		return 0
+	}
+	ms := cArgs.EvalCtx.GetMVCCStats()
+	if ms.KeyCount <= 0 {
+		return 0
+	}
+	size := expectedKeys * ((ms.KeyBytes + ms.ValBytes) / ms.KeyCount)
+	if size < scanMinReadaheadSize {
+		return 0
+	}
+	if size > maxSize {
+		size = maxSize
+	}
+	diskBytes, err := cArgs.EvalCtx.Engine().ApproximateDiskBytes(span.Key, span.EndKey)
+	if err != nil {
+		log.Warningf(ctx, "unable to determine size of %s: %s", span, err)
+		return 0
+	}
+	if int64(diskBytes) < size {
+		size = int64(diskBytes)
+	}
+	if size < scanMinReadaheadSize {
+		return 0
+	}
+	return size
+}
@@ -0,0 +1,79 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package batcheval
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/batcheval/result"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	RegisterCommand(roachpb.ScanAndLock, DefaultDeclareKeys, ScanAndLock)
+}
+
+// ScanAndLock scans the key range specified by start key through end key in
+// ascending order up to some maximum number of results, and locks every key
+// it returns on behalf of the transaction. A key is locked by writing an
+// intent which carries the key's existing value, so the transaction's view of
+// the key is unchanged while any other transaction which wants to write it
+// has to wait for (or push) the lock holder, just as it would for any other
+// intent. The intents are resolved along with the transaction's other writes.
+func ScanAndLock(
+	ctx context.Context, batch engine.ReadWriter, cArgs CommandArgs, resp roachpb.Response,
+) (result.Result, error) {
+	args := cArgs.Args.(*roachpb.ScanAndLockRequest)
+	h := cArgs.Header
+	reply := resp.(*roachpb.ScanAndLockResponse)
+
+	if h.Txn == nil {
+		return result.Result{}, errors.Errorf("no transaction specified to %s", args.Method())
+	}
+
+	rows, resumeSpan, _, err := engine.MVCCScan(ctx, batch, args.Key, args.EndKey,
+		cArgs.MaxKeys, h.Timestamp, true /* consistent */, h.Txn)
+	if err != nil {
+		return result.Result{}, err
+	}
+
+	resumeReason := roachpb.RESUME_KEY_LIMIT
+	n, numBytes := rowsWithinTargetBytes(rows, cArgs.TargetBytes)
+	if n < len(rows) {
+		resumeSpan = &roachpb.Span{Key: rows[n].Key, EndKey: args.EndKey}
+		resumeReason = roachpb.RESUME_BYTE_LIMIT
+		rows = rows[:n]
+	}
+
+	for _, row := range rows {
+		value := row.Value
+		value.Timestamp = hlc.Timestamp{}
+		if err := engine.MVCCPut(ctx, batch, cArgs.Stats, row.Key, h.Timestamp, value, h.Txn); err != nil {
+			return result.Result{}, err
+		}
+	}
+
+	reply.NumKeys = int64(len(rows))
+	reply.NumBytes = numBytes
+	if resumeSpan != nil {
+		reply.ResumeSpan = resumeSpan
+		reply.ResumeReason = resumeReason
+	}
+	reply.Rows = rows
+	return result.Result{}, nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package batcheval
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/randutil"
+)
+
+func TestScanReadaheadSize(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+	st := cluster.MakeTestingClusterSettings()
+	eng, err := engine.NewRocksDB(engine.RocksDBConfig{Settings: st, Dir: dir}, engine.RocksDBCache{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer eng.Close()
+
+	// Write 64 keys of 64 KiB each and flush them, so that the span they
+	// cover is about 4 MiB on disk.
+	ctx := context.Background()
+	rng := rand.New(rand.NewSource(1))
+	key := func(i int) roachpb.Key {
+		return roachpb.Key(fmt.Sprintf("k%02d", i))
+	}
+	const numKeys, valueSize = 64, 64 << 10
+	for i := 0; i < numKeys; i++ {
+		value := roachpb.MakeValueFromBytes(randutil.RandBytes(rng, valueSize))
+		if err := engine.MVCCPut(ctx, eng, nil, key(i), hlc.Timestamp{WallTime: 1}, value, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := eng.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	evalCtx := &mockEvalCtx{clusterSettings: st, engine: eng}
+	evalCtx.stats.KeyCount = numKeys
+	evalCtx.stats.ValBytes = numKeys * valueSize
+
+	fullSpan := roachpb.Span{Key: key(0), EndKey: key(numKeys)}
+	testCases := []struct {
+		name         string
+		expectedKeys int64
+		maxKeys      int64
+		span         roachpb.Span
+		maxSize      int64
+		expected     int64
+	}{
+		{"no hint", 0, math.MaxInt64, fullSpan, 2 << 20, 0},
+		{"short scan", 2, math.MaxInt64, fullSpan, 2 << 20, 0},
+		{"long scan", 1000, math.MaxInt64, fullSpan, 2 << 20, 2 << 20},
+		{"limited scan", 1000, 2, fullSpan, 2 << 20, 0},
+		{"single key", 1000, math.MaxInt64, roachpb.Span{Key: key(1), EndKey: key(2)}, 2 << 20, 0},
+		{"disabled", 1000, math.MaxInt64, fullSpan, 0, 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scanMaxReadaheadSize.Override(&st.SV, tc.maxSize)
+			cArgs := CommandArgs{
+				EvalCtx: evalCtx,
+				Header:  roachpb.Header{ExpectedScanKeys: tc.expectedKeys},
+				MaxKeys: tc.maxKeys,
+			}
+			if size := scanReadaheadSize(ctx, cArgs, tc.span); size != tc.expected {
+				t.Errorf("expected readahead of %d bytes, got %d", tc.expected, size)
+			}
+		})
+	}
+}
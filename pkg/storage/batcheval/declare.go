@@ -21,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/storage/spanset"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
 )
 
 // DefaultDeclareKeys is the default implementation of Command.DeclareKeys
@@ -57,7 +58,15 @@ type CommandArgs struct {
 	// that many keys. Commands using this feature should also set
 	// NumKeys and ResumeSpan in their responses.
 	MaxKeys int64
+	// If TargetBytes is non-zero, Scan and ReverseScan should stop once the
+	// rows they return reach that many bytes. Commands using this feature
+	// should also set NumBytes and ResumeSpan in their responses.
+	TargetBytes int64
 
 	// *Stats should be mutated to reflect any writes made by the command.
 	Stats *enginepb.MVCCStats
+
+	// EvalMemAcc, if set, is the account against which the memory allocated
+	// while evaluating the batch is registered. Use GrowEvalMemory.
+	EvalMemAcc *mon.BoundAccount
 }
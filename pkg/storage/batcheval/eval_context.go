@@ -30,6 +30,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/txnwait"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/limit"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
 )
 
 // Limiters is the collection of per-store limits used during cmd evaluation.
@@ -37,6 +38,9 @@ type Limiters struct {
 	BulkIOWriteRate   *rate.Limiter
 	ConcurrentImports limit.ConcurrentRequestLimiter
 	ConcurrentExports limit.ConcurrentRequestLimiter
+	// EvalMemory, if set, is the monitor that commands which allocate memory
+	// proportional to the data they read register their allocations with.
+	EvalMemory *mon.BytesMonitor
 }
 
 // EvalContext is the interface through which command evaluation accesses the
@@ -70,6 +74,10 @@ type EvalContext interface {
 	GetMVCCStats() enginepb.MVCCStats
 	GetGCThreshold() hlc.Timestamp
 	GetTxnSpanGCThreshold() hlc.Timestamp
+	// GetProtectedTimestamp returns the earliest timestamp protected by a
+	// protected timestamp record overlapping the range, or the zero
+	// timestamp if there's none.
+	GetProtectedTimestamp() hlc.Timestamp
 	GetLastReplicaGCTimestamp(context.Context) (hlc.Timestamp, error)
 	GetLease() (roachpb.Lease, *roachpb.Lease)
 }
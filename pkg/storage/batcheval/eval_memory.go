@@ -0,0 +1,37 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package batcheval
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+)
+
+// GrowEvalMemory registers size bytes allocated while evaluating a command
+// with the store's evaluation memory monitor. The allocation is released
+// once the whole batch has been evaluated. If the store's budget would be
+// exceeded, an EvalMemoryBudgetExceededError is returned, and the command
+// should give up instead of allocating more.
+func GrowEvalMemory(ctx context.Context, cArgs CommandArgs, size int64) error {
+	if cArgs.EvalMemAcc == nil {
+		return nil
+	}
+	if err := cArgs.EvalMemAcc.Grow(ctx, size); err != nil {
+		return roachpb.NewEvalMemoryBudgetExceededError(
+			size, cArgs.EvalMemAcc.Monitor().AllocBytes())
+	}
+	return nil
+}
@@ -66,9 +66,11 @@ type LocalResult struct {
 	MaybeAddToSplitQueue bool
 	// Call MaybeGossipNodeLiveness with the specified Span, if set.
 	MaybeGossipNodeLiveness *roachpb.Span
-	// Indicate that all traffic must be blocked until the merge transaction
-	// between this range and its left neighbor completes.
-	SetMerging bool
+
+	// Triggers are handed to the handlers registered for their method. This
+	// is a pointer to allow the zero (and as an unwelcome side effect, all)
+	// values to be compared.
+	Triggers *[]Trigger
 
 	// Set when transaction record(s) are updated, after calls to
 	// EndTransaction or PushTxn. This is a pointer to allow the zero
@@ -76,16 +78,17 @@ type LocalResult struct {
 	UpdatedTxns *[]*roachpb.Transaction
 }
 
-// DetachSetMerging returns and falsifies the SetMerging flag from the local
-// result.
-func (lResult *LocalResult) DetachSetMerging() bool {
+// DetachTriggers returns (and removes) the triggers from the local result.
+func (lResult *LocalResult) DetachTriggers() []Trigger {
 	if lResult == nil {
-		return false
-	} else if lResult.SetMerging {
-		lResult.SetMerging = false
-		return true
+		return nil
 	}
-	return false
+	var r []Trigger
+	if lResult.Triggers != nil {
+		r = *lResult.Triggers
+	}
+	lResult.Triggers = nil
+	return r
 }
 
 // DetachIntents returns (and removes) those intents from the
@@ -323,7 +326,6 @@ func (p *Result) MergeAndDestroy(q Result) error {
 	coalesceBool(&p.Local.GossipFirstRange, &q.Local.GossipFirstRange)
 	coalesceBool(&p.Local.MaybeGossipSystemConfig, &q.Local.MaybeGossipSystemConfig)
 	coalesceBool(&p.Local.MaybeAddToSplitQueue, &q.Local.MaybeAddToSplitQueue)
-	coalesceBool(&p.Local.SetMerging, &q.Local.SetMerging)
 
 	if q.Local.UpdatedTxns != nil {
 		if p.Local.UpdatedTxns == nil {
@@ -334,6 +336,15 @@ func (p *Result) MergeAndDestroy(q Result) error {
 	}
 	q.Local.UpdatedTxns = nil
 
+	if q.Local.Triggers != nil {
+		if p.Local.Triggers == nil {
+			p.Local.Triggers = q.Local.Triggers
+		} else {
+			*p.Local.Triggers = append(*p.Local.Triggers, *q.Local.Triggers...)
+		}
+	}
+	q.Local.Triggers = nil
+
 	if !q.IsZero() {
 		log.Fatalf(context.TODO(), "unhandled EvalResult: %s", pretty.Diff(q, Result{}))
 	}
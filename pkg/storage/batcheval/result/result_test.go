@@ -18,6 +18,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 )
@@ -62,3 +63,26 @@ func TestEvalResultIsZero(t *testing.T) {
 		}
 	}
 }
+
+func TestMergeAndDestroyTriggers(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	p := WithTrigger(roachpb.GetSnapshotForMerge, nil /* payload */)
+	if err := p.MergeAndDestroy(Result{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.MergeAndDestroy(WithTrigger(roachpb.EndTransaction, 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Trigger{
+		{Method: roachpb.GetSnapshotForMerge},
+		{Method: roachpb.EndTransaction, Payload: 1},
+	}
+	if triggers := p.Local.DetachTriggers(); !reflect.DeepEqual(expected, triggers) {
+		t.Fatalf("expected triggers %v, got %v", expected, triggers)
+	}
+	if !p.IsZero() {
+		t.Fatalf("expected result to be zero after detaching triggers, got %+v", p)
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package result
+
+import "github.com/cockroachdb/cockroach/pkg/roachpb"
+
+// A Trigger is a side effect of a command which is handled, once the command
+// has been applied, by the handlers registered for the command's method on the
+// node that evaluated it. Triggers let subsystems react to commands without
+// LocalResult needing a dedicated field for every consumer.
+type Trigger struct {
+	// Method is the method of the command which returned the trigger and
+	// determines the handlers that are run for it.
+	Method roachpb.Method
+	// Payload is passed to the handlers and is specific to Method. May be nil.
+	Payload interface{}
+}
+
+// WithTrigger returns a Result carrying a trigger for the given method and
+// payload.
+func WithTrigger(method roachpb.Method, payload interface{}) Result {
+	return Result{
+		Local: LocalResult{
+			Triggers: &[]Trigger{{Method: method, Payload: payload}},
+		},
+	}
+}
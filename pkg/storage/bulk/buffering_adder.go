@@ -0,0 +1,219 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package bulk contains the shared machinery used by the bulk writers, like
+// IMPORT and RESTORE, to ingest key values with AddSSTable.
+package bulk
+
+import (
+	"bytes"
+	"context"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/pkg/errors"
+)
+
+// maxAddSSTableRetries is the number of times an AddSSTable request with an
+// ambiguous result is retried.
+const maxAddSSTableRetries = 10
+
+// BufferingAdder accepts key values in any order and ingests them with
+// AddSSTable. The key values are buffered in memory until they reach the
+// buffer size, at which point they're sorted and ingested as sstables of at
+// most the sstable size. A request for a span that's no longer a single range
+// is split and retried.
+//
+// If splitAndScatter is set, each sstable is first split off into its own
+// range, which is scattered, so that the ingestion of a large sorted buffer
+// is spread across the cluster instead of going to one range after another.
+//
+// A BufferingAdder isn't safe for concurrent use.
+type BufferingAdder struct {
+	db              *client.DB
+	bufferSize      int64
+	sstSize         int64
+	splitAndScatter bool
+
+	// kvs are the buffered key values, and kvsSize their total size.
+	kvs     mvccKeyValues
+	kvsSize int64
+
+	// ingestedDataSize is the total size of the key values ingested so far.
+	ingestedDataSize int64
+}
+
+// NewBufferingAdder makes a BufferingAdder that buffers up to bufferSize bytes
+// of key values and ingests them as sstables of up to sstSize bytes.
+func NewBufferingAdder(
+	db *client.DB, bufferSize, sstSize int64, splitAndScatter bool,
+) *BufferingAdder {
+	return &BufferingAdder{
+		db:              db,
+		bufferSize:      bufferSize,
+		sstSize:         sstSize,
+		splitAndScatter: splitAndScatter,
+	}
+}
+
+type mvccKeyValues []engine.MVCCKeyValue
+
+func (kvs mvccKeyValues) Len() int           { return len(kvs) }
+func (kvs mvccKeyValues) Less(i, j int) bool { return kvs[i].Key.Less(kvs[j].Key) }
+func (kvs mvccKeyValues) Swap(i, j int)      { kvs[i], kvs[j] = kvs[j], kvs[i] }
+
+// Add buffers a key value, flushing the buffer if it's full. The key and value
+// are copied.
+func (b *BufferingAdder) Add(ctx context.Context, key engine.MVCCKey, value []byte) error {
+	key.Key = append(roachpb.Key(nil), key.Key...)
+	b.kvs = append(b.kvs, engine.MVCCKeyValue{Key: key, Value: append([]byte(nil), value...)})
+	b.kvsSize += int64(len(key.Key) + len(value))
+	if b.kvsSize >= b.bufferSize {
+		return b.Flush(ctx)
+	}
+	return nil
+}
+
+// Reset discards the buffered key values.
+func (b *BufferingAdder) Reset() {
+	b.kvs, b.kvsSize = b.kvs[:0], 0
+}
+
+// IngestedDataSize returns the total size of the key values ingested so far.
+func (b *BufferingAdder) IngestedDataSize() int64 {
+	return b.ingestedDataSize
+}
+
+// Flush ingests the buffered key values. The same key value can be added more
+// than once, but it's an error to add different values for the same key and
+// timestamp.
+func (b *BufferingAdder) Flush(ctx context.Context) error {
+	if len(b.kvs) == 0 {
+		return nil
+	}
+	sort.Sort(b.kvs)
+
+	// Remove the duplicates.
+	kvs := b.kvs[:1]
+	for _, kv := range b.kvs[1:] {
+		if last := kvs[len(kvs)-1]; kv.Key.Equal(last.Key) {
+			if !bytes.Equal(kv.Value, last.Value) {
+				return errors.Errorf("different values added for key %s", kv.Key)
+			}
+			continue
+		}
+		kvs = append(kvs, kv)
+	}
+
+	// Cut the key values into sstables. The versions of a key are kept in the
+	// same sstable, so that a split never falls between them.
+	start := 0
+	var size int64
+	for i, kv := range kvs {
+		if i > start && size >= b.sstSize && !kv.Key.Key.Equal(kvs[i-1].Key.Key) {
+			if err := b.ingest(ctx, kvs[start:i]); err != nil {
+				return err
+			}
+			start, size = i, 0
+		}
+		size += int64(len(kv.Key.Key) + len(kv.Value))
+	}
+	if err := b.ingest(ctx, kvs[start:]); err != nil {
+		return err
+	}
+	b.Reset()
+	return nil
+}
+
+// ingest ingests a sorted chunk of key values as one sstable, after splitting
+// it off into its own range and scattering it if requested.
+func (b *BufferingAdder) ingest(ctx context.Context, kvs mvccKeyValues) error {
+	if len(kvs) == 0 {
+		return nil
+	}
+	start, end := kvs[0].Key.Key, kvs[len(kvs)-1].Key.Key.Next()
+
+	if b.splitAndScatter {
+		splitKey, err := keys.EnsureSafeSplitKey(start)
+		if err != nil {
+			// Not a SQL key, so it can be split anywhere.
+			splitKey = start
+		}
+		if err := b.db.AdminSplit(ctx, splitKey, splitKey); err != nil {
+			return err
+		}
+		log.VEventf(ctx, 1, "scattering [%s,%s)", start, end)
+		scatterReq := &roachpb.AdminScatterRequest{
+			RequestHeader: roachpb.RequestHeader{Key: start, EndKey: end},
+		}
+		if _, pErr := client.SendWrapped(ctx, b.db.NonTransactionalSender(), scatterReq); pErr != nil {
+			// Scattering only affects throughput, and is still too unreliable to
+			// fail the ingestion when it fails.
+			log.Errorf(ctx, "failed to scatter [%s,%s): %s", start, end, pErr.GoError())
+		}
+	}
+
+	return b.addSSTable(ctx, kvs)
+}
+
+// addSSTable sends the given sorted key values in an AddSSTable request. If the
+// request's span no longer belongs to a single range, the key values are split
+// at the end of the range and both halves are sent again.
+func (b *BufferingAdder) addSSTable(ctx context.Context, kvs mvccKeyValues) error {
+	sst, err := engine.MakeRocksDBSstFileWriter()
+	if err != nil {
+		return err
+	}
+	defer sst.Close()
+	for _, kv := range kvs {
+		if err := sst.Add(kv); err != nil {
+			return errors.Wrapf(err, "adding key %s", kv.Key)
+		}
+	}
+	data, err := sst.Finish()
+	if err != nil {
+		return err
+	}
+
+	start, end := kvs[0].Key.Key, kvs[len(kvs)-1].Key.Key.Next()
+	for i := 0; ; i++ {
+		log.VEventf(ctx, 2, "sending AddSSTable [%s,%s)", start, end)
+		err := b.db.AddSSTable(ctx, start, end, data)
+		if err == nil {
+			b.ingestedDataSize += sst.DataSize
+			return nil
+		}
+		if m, ok := errors.Cause(err).(*roachpb.RangeKeyMismatchError); ok {
+			split := m.MismatchedRange.EndKey.AsRawKey()
+			n := sort.Search(len(kvs), func(i int) bool {
+				return bytes.Compare(kvs[i].Key.Key, split) >= 0
+			})
+			if n == 0 || n == len(kvs) {
+				return errors.Wrapf(err, "addsstable [%s,%s)", start, end)
+			}
+			if err := b.addSSTable(ctx, kvs[:n]); err != nil {
+				return err
+			}
+			return b.addSSTable(ctx, kvs[n:])
+		}
+		if _, ok := err.(*roachpb.AmbiguousResultError); i == maxAddSSTableRetries || !ok {
+			return errors.Wrapf(err, "addsstable [%s,%s)", start, end)
+		}
+		log.Warningf(ctx, "addsstable [%s,%s) attempt %d failed: %+v", start, end, i, err)
+	}
+}
@@ -164,6 +164,67 @@ func TestStoreRangeLeaseSwitcheroo(t *testing.T) {
 	}
 }
 
+// TestStoreRangeLeaseExpirationLeasesOnly verifies that a range's lease is
+// switched between epoch-based and expiration-based as
+// kv.expiration_leases_only.enabled changes.
+func TestStoreRangeLeaseExpirationLeasesOnly(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	sc := storage.TestStoreConfig(nil)
+	sc.EnableEpochRangeLeases = true
+	mtc := &multiTestContext{storeConfig: &sc}
+	defer mtc.Stop()
+	mtc.Start(t, 1)
+
+	splitKey := roachpb.Key("a")
+	splitArgs := adminSplitArgs(splitKey)
+	if _, pErr := client.SendWrapped(context.Background(), mtc.distSenders[0], splitArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	// Allow the lease to expire and send a command to ensure we re-acquire it
+	// as an epoch-based lease.
+	mtc.advanceClock(context.TODO())
+	if _, err := mtc.dbs[0].Inc(context.TODO(), splitKey, 1); err != nil {
+		t.Fatalf("failed to increment: %s", err)
+	}
+
+	repl := mtc.stores[0].LookupReplica(roachpb.RKey(splitKey), nil)
+	expectLeaseType := func(expected roachpb.LeaseType) {
+		t.Helper()
+		testutils.SucceedsSoon(t, func() error {
+			// The lease is switched asynchronously by the first command that
+			// notices the setting change.
+			if _, err := mtc.dbs[0].Inc(context.TODO(), splitKey, 1); err != nil {
+				return err
+			}
+			if lease, _ := repl.GetLease(); lease.Type() != expected {
+				return errors.New("lease type not switched yet")
+			}
+			return nil
+		})
+	}
+	expectLeaseType(roachpb.LeaseEpoch)
+
+	// Switch the range to an expiration-based lease, without waiting for the
+	// epoch-based lease to go away.
+	st := mtc.stores[0].ClusterSettings()
+	storage.ExpirationLeasesOnly.Override(&st.SV, true)
+	expectLeaseType(roachpb.LeaseExpiration)
+
+	// The lease renewer keeps the expiration-based lease alive on its own.
+	lease, _ := repl.GetLease()
+	testutils.SucceedsSoon(t, func() error {
+		if cur, _ := repl.GetLease(); !lease.GetExpiration().Less(cur.GetExpiration()) {
+			return errors.New("lease not renewed yet")
+		}
+		return nil
+	})
+
+	// And back to an epoch-based lease.
+	storage.ExpirationLeasesOnly.Override(&st.SV, false)
+	expectLeaseType(roachpb.LeaseEpoch)
+}
+
 // TestStoreGossipSystemData verifies that the system-config and node-liveness
 // data is gossiped at startup.
 func TestStoreGossipSystemData(t *testing.T) {
@@ -1214,6 +1214,41 @@ func TestStoreRangeUpReplicate(t *testing.T) {
 	}
 }
 
+// TestStoreRangeUpReplicateIngestsSnapshot verifies that the user data of a
+// snapshot sent to a new replica is ingested as an SST when the snapshot is
+// large enough.
+func TestStoreRangeUpReplicateIngestsSnapshot(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	sc := storage.TestStoreConfig(nil)
+	sc.TestingKnobs.DisableSplitQueue = true
+	storage.SnapshotIngestMinSize.Override(&sc.Settings.SV, 1)
+	mtc := &multiTestContext{storeConfig: &sc}
+	defer mtc.Stop()
+	mtc.Start(t, 2)
+
+	key := roachpb.Key("a")
+	incArgs := incrementArgs(key, 5)
+	if _, err := client.SendWrapped(
+		context.Background(), mtc.stores[0].TestSender(), incArgs); err != nil {
+		t.Fatal(err)
+	}
+
+	mtc.replicateRange(1, 1)
+	mtc.waitForValues(key, []int64{5, 5})
+
+	if n := mtc.stores[1].Metrics().RangeSnapshotsIngested.Count(); n == 0 {
+		t.Fatal("expected the snapshot data to be ingested")
+	}
+	// The ingested data is accounted for like the rest of the snapshot.
+	repl := mtc.stores[1].LookupReplica(roachpb.RKey(key), nil)
+	if repl == nil {
+		t.Fatal("expected a replica on the new store")
+	}
+	if ms := repl.GetMVCCStats(); ms.KeyCount == 0 {
+		t.Fatalf("expected the replica to have keys, got %+v", ms)
+	}
+}
+
 // TestStoreRangeCorruptionChangeReplicas verifies that the replication queue
 // will notice corrupted replicas and replace them.
 func TestStoreRangeCorruptionChangeReplicas(t *testing.T) {
@@ -3141,6 +3176,22 @@ func TestReplicaLazyLoad(t *testing.T) {
 	if replica.RaftStatus() != nil {
 		t.Fatalf("expected replica Raft group to be uninitialized")
 	}
+	if err := mtc.stores[0].ComputeMetrics(context.Background(), 0); err != nil {
+		t.Fatal(err)
+	}
+	if dormant := mtc.stores[0].Metrics().DormantCount.Value(); dormant < 1 {
+		t.Fatalf("expected at least one dormant replica, got %d", dormant)
+	}
+
+	// A request creates the Raft group.
+	if _, err := client.SendWrapped(
+		context.Background(), mtc.stores[0].TestSender(), getArgs(splitKey),
+	); err != nil {
+		t.Fatal(err)
+	}
+	if replica.RaftStatus() == nil {
+		t.Fatalf("expected replica Raft group to be initialized")
+	}
 }
 
 func TestReplicateReAddAfterDown(t *testing.T) {
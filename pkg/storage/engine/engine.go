@@ -150,6 +150,12 @@ type IterOptions struct {
 	// [start, end] time range. If you must guarantee that you never see a key
 	// outside of the time bounds, perform your own filtering.
 	MinTimestampHint, MaxTimestampHint hlc.Timestamp
+	// ReadaheadSize, if positive, is the number of bytes the iterator reads
+	// ahead when it reads from disk. Readahead speeds up long sequential scans
+	// but wastes IO on short ones, so it should only be set for iterators
+	// which are expected to scan a large amount of data. Iterators which set
+	// ReadaheadSize are not cached by batches and read-only engines.
+	ReadaheadSize int64
 }
 
 // Reader is the read interface to an engine's data.
@@ -1393,7 +1393,7 @@ func MVCCIncrement(
 		}
 
 		// Check for overflow and underflow.
-		if willOverflow(int64Val, inc) {
+		if WillOverflow(int64Val, inc) {
 			// Return the old value, since we've failed to modify it.
 			newInt64Val = int64Val
 			return nil, &roachpb.IntegerOverflowError{
@@ -1868,6 +1868,33 @@ func MVCCReverseScan(
 		consistent, false /* tombstones */, txn, true /* reverse */)
 }
 
+// MVCCScanWithReadahead is like MVCCScan, or MVCCReverseScan if reverse is
+// set, but the iterator reads ahead readaheadSize bytes when it reads from
+// disk. See IterOptions.ReadaheadSize.
+func MVCCScanWithReadahead(
+	ctx context.Context,
+	engine Reader,
+	key,
+	endKey roachpb.Key,
+	max int64,
+	timestamp hlc.Timestamp,
+	consistent bool,
+	txn *roachpb.Transaction,
+	reverse bool,
+	readaheadSize int64,
+) ([]roachpb.KeyValue, *roachpb.Span, []roachpb.Intent, error) {
+	var iter Iterator
+	if readaheadSize > 0 && max != 0 && len(endKey) != 0 {
+		iter = engine.NewIterator(IterOptions{
+			UpperBound:    endKey,
+			ReadaheadSize: readaheadSize,
+		})
+		defer iter.Close()
+	}
+	return mvccScanInternal(ctx, engine, iter, key, endKey, max, timestamp,
+		consistent, false /* tombstones */, txn, reverse)
+}
+
 // MVCCIterate iterates over the key range [start,end). At each step of the
 // iteration, f() is invoked with the current key/value pair. If f returns
 // true (done) or an error, the iteration stops and the error is propagated.
@@ -2609,9 +2636,9 @@ func MVCCFindSplitKey(
 	return keys.EnsureSafeSplitKey(splitKey.Key)
 }
 
-// willOverflow returns true iff adding both inputs would under- or overflow
+// WillOverflow returns true iff adding both inputs would under- or overflow
 // the 64 bit integer range.
-func willOverflow(a, b int64) bool {
+func WillOverflow(a, b int64) bool {
 	// Morally MinInt64 < a+b < MaxInt64, but without overflows.
 	// First make sure that a <= b. If not, swap them.
 	if a > b {
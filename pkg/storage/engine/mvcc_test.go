@@ -1369,6 +1369,52 @@ func TestMVCCScan(t *testing.T) {
 	}
 }
 
+// TestMVCCScanWithReadahead verifies that scans which read ahead return the
+// same results as regular scans, both on an engine and on a batch, whose
+// cached iterators must not be affected.
+func TestMVCCScanWithReadahead(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	engine := createTestEngine()
+	defer engine.Close()
+
+	ctx := context.Background()
+	for i, key := range []roachpb.Key{testKey1, testKey2, testKey3, testKey4} {
+		if err := MVCCPut(ctx, engine, nil, key, hlc.Timestamp{WallTime: 1}, value1, nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := MVCCPut(ctx, engine, nil, key, hlc.Timestamp{WallTime: int64(i + 2)}, value2, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	batch := engine.NewBatch()
+	defer batch.Close()
+	for _, reader := range []Reader{engine, batch} {
+		for _, reverse := range []bool{false, true} {
+			for _, max := range []int64{1, math.MaxInt64} {
+				ts := hlc.Timestamp{WallTime: 3}
+				scan := MVCCScan
+				if reverse {
+					scan = MVCCReverseScan
+				}
+				expKVs, expResume, _, err := scan(ctx, reader, testKey1, keyMax, max, ts, true, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				kvs, resume, _, err := MVCCScanWithReadahead(
+					ctx, reader, testKey1, keyMax, max, ts, true, nil, reverse, 1<<20)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(kvs, expKVs) || !reflect.DeepEqual(resume, expResume) {
+					t.Errorf("reverse=%t max=%d: expected %v (resume %v), got %v (resume %v)",
+						reverse, max, expKVs, expResume, kvs, resume)
+				}
+			}
+		}
+	}
+}
+
 func TestMVCCScanMaxNum(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	engine := createTestEngine()
@@ -4022,8 +4068,8 @@ func TestWillOverflow(t *testing.T) {
 	}
 
 	for i, c := range testCases {
-		if willOverflow(c.a, c.b) != c.overflow ||
-			willOverflow(c.b, c.a) != c.overflow {
+		if WillOverflow(c.a, c.b) != c.overflow ||
+			WillOverflow(c.b, c.a) != c.overflow {
 			t.Errorf("%d: overflow recognition error", i)
 		}
 	}
@@ -69,6 +69,22 @@ var minWALSyncInterval = settings.RegisterDurationSetting(
 	0*time.Millisecond,
 )
 
+var slowSyncThreshold = settings.RegisterNonNegativeDurationSetting(
+	"rocksdb.slow_sync_threshold",
+	"syncs of the RocksDB WAL that take longer than this are logged as warnings (0 to disable)",
+	time.Second,
+)
+
+// MaxSyncDuration is the longest a sync of the RocksDB WAL can take before
+// the process is crashed. A disk that stalls for this long is more harmful
+// than a dead node: the node keeps its leases and liveness, but can't serve
+// any writes.
+var MaxSyncDuration = settings.RegisterNonNegativeDurationSetting(
+	"rocksdb.max_sync_duration",
+	"syncs of the RocksDB WAL that take longer than this crash the process (0 to disable)",
+	60*time.Second,
+)
+
 var rocksdbConcurrency = envutil.EnvOrDefaultInt(
 	"COCKROACH_ROCKSDB_CONCURRENCY", func() int {
 		// Use up to min(numCPU, 4) threads for background RocksDB compactions per
@@ -409,7 +425,14 @@ type RocksDBCache struct {
 // cache is refcounted internally and starts out with a refcount of one (i.e.
 // Release() should be called after having used the cache).
 func NewRocksDBCache(cacheSize int64) RocksDBCache {
-	return RocksDBCache{cache: C.DBNewCache(C.uint64_t(cacheSize))}
+	return NewRocksDBCacheWithShardBits(cacheSize, 0 /* default */)
+}
+
+// NewRocksDBCacheWithShardBits is like NewRocksDBCache, but splits the cache
+// into 2^shardBits shards rather than the default number. More shards reduce
+// contention on the cache's locks at the cost of a less accurate LRU.
+func NewRocksDBCacheWithShardBits(cacheSize int64, shardBits int) RocksDBCache {
+	return RocksDBCache{cache: C.DBNewCache(C.uint64_t(cacheSize), C.int(shardBits))}
 }
 
 func (c RocksDBCache) ref() RocksDBCache {
@@ -462,6 +485,11 @@ type RocksDBConfig struct {
 	// ExtraOptions is a serialized protobuf set by Go CCL code and passed through
 	// to C CCL code.
 	ExtraOptions []byte
+	// PersistentCachePath, if set, is a directory on a fast local device in
+	// which RocksDB keeps a secondary cache of up to PersistentCacheSize bytes
+	// of blocks evicted from the block cache.
+	PersistentCachePath string
+	PersistentCacheSize int64
 }
 
 // RocksDB is a wrapper around a RocksDB database instance.
@@ -484,6 +512,8 @@ type RocksDB struct {
 		cond    sync.Cond
 		closed  bool
 		pending []*rocksDBBatch
+		// onSync, if set, is called with the duration of every WAL sync.
+		onSync func(time.Duration)
 	}
 
 	iters struct {
@@ -599,6 +629,19 @@ func (r *RocksDB) open() error {
 		existingVersion = versionCurrent
 	}
 
+	if r.cfg.PersistentCachePath != "" {
+		if len(r.cfg.Dir) == 0 {
+			return errors.New("a secondary cache cannot be used with an in memory rocksdb instance")
+		}
+		if r.cfg.UseFileRegistry {
+			// The secondary cache stores blocks unencrypted.
+			return errors.New("a secondary cache cannot be used with encryption-at-rest")
+		}
+		if r.cfg.PersistentCacheSize <= 0 {
+			return errors.Errorf("invalid secondary cache size %d", r.cfg.PersistentCacheSize)
+		}
+	}
+
 	maxOpenFiles := uint64(RecommendedMaxOpenFiles)
 	if r.cfg.MaxOpenFiles != 0 {
 		maxOpenFiles = r.cfg.MaxOpenFiles
@@ -614,6 +657,9 @@ func (r *RocksDB) open() error {
 			read_only:         C.bool(r.cfg.ReadOnly),
 			rocksdb_options:   goToCSlice([]byte(r.cfg.RocksDBOptions)),
 			extra_options:     goToCSlice(r.cfg.ExtraOptions),
+
+			persistent_cache_path: goToCSlice([]byte(r.cfg.PersistentCachePath)),
+			persistent_cache_size: C.uint64_t(r.cfg.PersistentCacheSize),
 		})
 	if err := statusToError(status); err != nil {
 		return errors.Wrap(err, "could not open rocksdb instance")
@@ -663,12 +709,13 @@ func (r *RocksDB) syncLoop() {
 
 		pending := s.pending
 		s.pending = nil
+		onSync := s.onSync
 
 		s.Unlock()
 
 		var err error
 		if r.cfg.Dir != "" {
-			err = statusToError(C.DBSyncWAL(r.rdb))
+			err = r.syncWAL(onSync)
 			lastSync = timeutil.Now()
 		}
 
@@ -681,6 +728,46 @@ func (r *RocksDB) syncLoop() {
 	}
 }
 
+// syncWAL syncs the WAL to disk, reporting the duration of the sync to
+// onSync, if set. Slow syncs are logged, and syncs that take longer than
+// rocksdb.max_sync_duration crash the process, as they're indicative of a
+// stalled disk.
+func (r *RocksDB) syncWAL(onSync func(time.Duration)) error {
+	ctx := context.TODO()
+	var warnAfter, maxDuration time.Duration
+	if r.cfg.Settings != nil {
+		warnAfter = slowSyncThreshold.Get(&r.cfg.Settings.SV)
+		maxDuration = MaxSyncDuration.Get(&r.cfg.Settings.SV)
+	}
+	if maxDuration > 0 {
+		watchdog := time.AfterFunc(maxDuration, func() {
+			log.Fatalf(ctx, "disk stall detected: sync of the RocksDB WAL in %s has been "+
+				"in progress for longer than %s (rocksdb.max_sync_duration)", r.cfg.Dir, maxDuration)
+		})
+		defer watchdog.Stop()
+	}
+
+	start := timeutil.Now()
+	err := statusToError(C.DBSyncWAL(r.rdb))
+	elapsed := timeutil.Since(start)
+	if onSync != nil {
+		onSync(elapsed)
+	}
+	if warnAfter > 0 && elapsed >= warnAfter {
+		log.Warningf(ctx, "slow sync of the RocksDB WAL in %s: took %s (rocksdb.slow_sync_threshold: %s)",
+			r.cfg.Dir, elapsed, warnAfter)
+	}
+	return err
+}
+
+// SetSyncListener sets a function called with the duration of every sync of
+// the WAL, which is used to track the sync latency of the store.
+func (r *RocksDB) SetSyncListener(onSync func(time.Duration)) {
+	r.syncer.Lock()
+	defer r.syncer.Unlock()
+	r.syncer.onSync = onSync
+}
+
 // Close closes the database by deallocating the underlying handle.
 func (r *RocksDB) Close() {
 	if r.rdb == nil {
@@ -989,8 +1076,8 @@ func (r *rocksDBReadOnly) NewIterator(opts IterOptions) Iterator {
 	if r.isClosed {
 		panic("using a closed rocksDBReadOnly")
 	}
-	if opts.MinTimestampHint != (hlc.Timestamp{}) {
-		// Iterators that specify timestamp bounds cannot be cached.
+	if opts.MinTimestampHint != (hlc.Timestamp{}) || opts.ReadaheadSize > 0 {
+		// Iterators that specify timestamp bounds or readahead cannot be cached.
 		return newRocksDBIterator(r.parent.rdb, opts, r, r.parent)
 	}
 	iter := &r.normalIter
@@ -1219,8 +1306,8 @@ func (r *distinctBatch) Close() {
 // batch. A panic will be thrown if multiple prefix or normal (non-prefix)
 // iterators are used simultaneously on the same batch.
 func (r *distinctBatch) NewIterator(opts IterOptions) Iterator {
-	if opts.MinTimestampHint != (hlc.Timestamp{}) {
-		// Iterators that specify timestamp bounds cannot be cached.
+	if opts.MinTimestampHint != (hlc.Timestamp{}) || opts.ReadaheadSize > 0 {
+		// Iterators that specify timestamp bounds or readahead cannot be cached.
 		if r.writeOnly {
 			return newRocksDBIterator(r.parent.rdb, opts, r, r.parent)
 		}
@@ -1617,8 +1704,8 @@ func (r *rocksDBBatch) NewIterator(opts IterOptions) Iterator {
 		panic("distinct batch open")
 	}
 
-	if opts.MinTimestampHint != (hlc.Timestamp{}) {
-		// Iterators that specify timestamp bounds cannot be cached.
+	if opts.MinTimestampHint != (hlc.Timestamp{}) || opts.ReadaheadSize > 0 {
+		// Iterators that specify timestamp bounds or readahead cannot be cached.
 		r.ensureBatch()
 		iter := &batchIterator{batch: r}
 		iter.iter.init(r.batch, opts, r, r.parent)
@@ -1895,6 +1982,9 @@ func (r *rocksDBIterator) setOptions(opts IterOptions) {
 	if opts.MinTimestampHint != (hlc.Timestamp{}) || opts.MaxTimestampHint != (hlc.Timestamp{}) {
 		panic("iterator with timestamp hints cannot be reused")
 	}
+	if opts.ReadaheadSize > 0 {
+		panic("iterator with readahead cannot be reused")
+	}
 	if !opts.Prefix && len(opts.UpperBound) == 0 {
 		panic("iterator must set prefix or upper bound")
 	}
@@ -2302,6 +2392,7 @@ func goToCIterOptions(opts IterOptions) C.DBIterOptions {
 		min_timestamp_hint: goToCTimestamp(opts.MinTimestampHint),
 		max_timestamp_hint: goToCTimestamp(opts.MaxTimestampHint),
 		with_stats:         C.bool(opts.WithStats),
+		readahead_size:     C.int64_t(opts.ReadaheadSize),
 	}
 }
 
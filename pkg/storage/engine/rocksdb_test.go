@@ -25,6 +25,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strconv"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -1256,6 +1257,54 @@ func TestRocksDBFileNotFoundError(t *testing.T) {
 	}
 }
 
+func TestRocksDBSyncListener(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	dir, dirCleanup := testutils.TempDir(t)
+	defer dirCleanup()
+
+	db, err := NewRocksDB(
+		RocksDBConfig{
+			Settings: cluster.MakeTestingClusterSettings(),
+			Dir:      dir,
+		},
+		RocksDBCache{},
+	)
+	if err != nil {
+		t.Fatalf("could not create new rocksdb db instance at %s: %v", dir, err)
+	}
+	defer db.Close()
+
+	var syncs int32
+	db.SetSyncListener(func(time.Duration) {
+		atomic.AddInt32(&syncs, 1)
+	})
+
+	b := db.NewBatch()
+	defer b.Close()
+	if err := b.Put(mvccKey("a"), []byte("value")); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Commit(false /* sync */); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&syncs); n != 0 {
+		t.Fatalf("expected no syncs after an unsynced commit, got %d", n)
+	}
+
+	b2 := db.NewBatch()
+	defer b2.Close()
+	if err := b2.Put(mvccKey("b"), []byte("value")); err != nil {
+		t.Fatal(err)
+	}
+	if err := b2.Commit(true /* sync */); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&syncs); n != 1 {
+		t.Fatalf("expected 1 sync after a synced commit, got %d", n)
+	}
+}
+
 // Verify that range tombstones do not result in sstables that cover an
 // exessively large portion of the key space.
 func TestRocksDBDeleteRangeCompaction(t *testing.T) {
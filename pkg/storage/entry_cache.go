@@ -21,9 +21,67 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/util/cache"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 )
 
+var (
+	metaRaftEntryCacheBytes = metric.Metadata{
+		Name:        "raft.entrycache.bytes",
+		Help:        "Aggregate size of all Raft entries in the Raft entry cache",
+		Measurement: "Storage",
+		Unit:        metric.Unit_BYTES,
+	}
+	metaRaftEntryCacheSize = metric.Metadata{
+		Name:        "raft.entrycache.size",
+		Help:        "Number of Raft entries in the Raft entry cache",
+		Measurement: "Entry Count",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaRaftEntryCacheHits = metric.Metadata{
+		Name:        "raft.entrycache.hits",
+		Help:        "Number of lookups in the Raft entry cache that found all the requested entries",
+		Measurement: "Lookups",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaRaftEntryCacheMisses = metric.Metadata{
+		Name:        "raft.entrycache.misses",
+		Help:        "Number of lookups in the Raft entry cache that had to fall back to the engine",
+		Measurement: "Lookups",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaRaftEntryCacheEvictions = metric.Metadata{
+		Name:        "raft.entrycache.evictions",
+		Help:        "Number of Raft entries evicted from the Raft entry cache to stay within its size limit",
+		Measurement: "Entry Count",
+		Unit:        metric.Unit_COUNT,
+	}
+)
+
+// raftEntryCacheMetrics is the set of metrics for a raftEntryCache.
+type raftEntryCacheMetrics struct {
+	Bytes     *metric.Gauge
+	Size      *metric.Gauge
+	Hits      *metric.Counter
+	Misses    *metric.Counter
+	Evictions *metric.Counter
+}
+
+// MetricStruct implements the metric.Struct interface.
+func (raftEntryCacheMetrics) MetricStruct() {}
+
+var _ metric.Struct = raftEntryCacheMetrics{}
+
+func makeRaftEntryCacheMetrics() raftEntryCacheMetrics {
+	return raftEntryCacheMetrics{
+		Bytes:     metric.NewGauge(metaRaftEntryCacheBytes),
+		Size:      metric.NewGauge(metaRaftEntryCacheSize),
+		Hits:      metric.NewCounter(metaRaftEntryCacheHits),
+		Misses:    metric.NewCounter(metaRaftEntryCacheMisses),
+		Evictions: metric.NewCounter(metaRaftEntryCacheEvictions),
+	}
+}
+
 type entryCacheKey struct {
 	RangeID roachpb.RangeID
 	Index   uint64
@@ -47,9 +105,10 @@ func (a *entryCacheKey) Compare(b llrb.Comparable) int {
 	}
 }
 
-// A raftEntryCache maintains a global cache of Raft group log entries. The
-// cache mostly prevents unnecessary reads from disk of recently-written log
-// entries between log append and application to the FSM.
+// A raftEntryCache maintains a cache of Raft group log entries, shared by
+// all the replicas of a store and bounded in size. The cache mostly prevents
+// unnecessary reads from disk of recently-written log entries between log
+// append and application to the FSM.
 //
 // This cache stores entries with sideloaded proposals inlined (i.e. ready to
 // be sent to followers).
@@ -59,13 +118,15 @@ type raftEntryCache struct {
 	cache          *cache.OrderedCache // LRU cache of log entries, keyed by rangeID / log index
 	fromKey        entryCacheKey       // used to avoid allocations on lookup
 	toKey          entryCacheKey       // ^^^
+	metrics        raftEntryCacheMetrics
 }
 
 // newRaftEntryCache returns a new RaftEntryCache with the given
 // maximum size in bytes.
 func newRaftEntryCache(maxBytes uint64) *raftEntryCache {
 	rec := &raftEntryCache{
-		cache: cache.NewOrderedCache(cache.Config{Policy: cache.CacheLRU}),
+		cache:   cache.NewOrderedCache(cache.Config{Policy: cache.CacheLRU}),
+		metrics: makeRaftEntryCacheMetrics(),
 	}
 	// The raft entry cache mutex will be held when the ShouldEvict
 	// and OnEvicted callbacks are invoked.
@@ -75,7 +136,11 @@ func newRaftEntryCache(maxBytes uint64) *raftEntryCache {
 	// in the cache to prevent the case where a very large entry isn't able
 	// to be cached at all.
 	rec.cache.Config.ShouldEvict = func(n int, k, v interface{}) bool {
-		return rec.bytes > maxBytes && n >= 1
+		if rec.bytes > maxBytes && n >= 1 {
+			rec.metrics.Evictions.Inc(1)
+			return true
+		}
+		return false
 	}
 	rec.cache.Config.OnEvicted = func(k, v interface{}) {
 		ent := v.(*raftpb.Entry)
@@ -85,6 +150,13 @@ func newRaftEntryCache(maxBytes uint64) *raftEntryCache {
 	return rec
 }
 
+// updateGaugesLocked updates the size metrics of the cache. The cache mutex
+// must be held.
+func (rec *raftEntryCache) updateGaugesLocked() {
+	rec.metrics.Bytes.Update(int64(rec.bytes))
+	rec.metrics.Size.Update(int64(rec.cache.Len()))
+}
+
 func (rec *raftEntryCache) makeCacheEntry(key entryCacheKey, value raftpb.Entry) *cache.Entry {
 	alloc := struct {
 		key   entryCacheKey
@@ -113,6 +185,7 @@ func (rec *raftEntryCache) addEntries(rangeID roachpb.RangeID, ents []raftpb.Ent
 		entry := rec.makeCacheEntry(entryCacheKey{RangeID: rangeID, Index: e.Index}, e)
 		rec.cache.AddEntry(entry)
 	}
+	rec.updateGaugesLocked()
 }
 
 // getTerm returns the term for the specified index and true for the second
@@ -124,12 +197,15 @@ func (rec *raftEntryCache) getTerm(rangeID roachpb.RangeID, index uint64) (uint6
 	rec.fromKey = entryCacheKey{RangeID: rangeID, Index: index}
 	k, v, ok := rec.cache.Ceil(&rec.fromKey)
 	if !ok {
+		rec.metrics.Misses.Inc(1)
 		return 0, false
 	}
 	ecKey := k.(*entryCacheKey)
 	if ecKey.RangeID != rangeID || ecKey.Index != index {
+		rec.metrics.Misses.Inc(1)
 		return 0, false
 	}
+	rec.metrics.Hits.Inc(1)
 	ent := v.(*raftpb.Entry)
 	return ent.Term, true
 }
@@ -146,6 +222,7 @@ func (rec *raftEntryCache) getEntries(
 	defer rec.Unlock()
 	var bytes uint64
 	nextIndex := lo
+	full := false
 
 	rec.fromKey = entryCacheKey{RangeID: rangeID, Index: lo}
 	rec.toKey = entryCacheKey{RangeID: rangeID, Index: hi}
@@ -159,11 +236,19 @@ func (rec *raftEntryCache) getEntries(
 		bytes += uint64(ent.Size())
 		nextIndex++
 		if maxBytes > 0 && bytes > maxBytes {
+			full = true
 			return true
 		}
 		return false
 	}, &rec.fromKey, &rec.toKey)
 
+	// The lookup is a hit if the caller doesn't have to read any entry from
+	// the engine.
+	if nextIndex == hi || full {
+		rec.metrics.Hits.Inc(1)
+	} else {
+		rec.metrics.Misses.Inc(1)
+	}
 	return ents, bytes, nextIndex
 }
 
@@ -185,6 +270,7 @@ func (rec *raftEntryCache) delEntries(rangeID roachpb.RangeID, lo, hi uint64) {
 	for _, e := range cacheEnts {
 		rec.cache.DelEntry(e)
 	}
+	rec.updateGaugesLocked()
 }
 
 // clearTo clears the entries in the cache for specified range up to,
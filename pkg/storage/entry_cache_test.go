@@ -140,6 +140,41 @@ func TestEntryCacheEviction(t *testing.T) {
 	}
 }
 
+func TestEntryCacheMetrics(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	rangeID := roachpb.RangeID(1)
+	rec := newRaftEntryCache(100)
+	ents := []raftpb.Entry{newEntry(1, 40), newEntry(2, 40)}
+	rec.addEntries(rangeID, ents)
+	m := rec.metrics
+	if size, bytes := m.Size.Value(), m.Bytes.Value(); size != 2 ||
+		bytes != int64(ents[0].Size()+ents[1].Size()) {
+		t.Fatalf("unexpected cache size %d (%d bytes)", size, bytes)
+	}
+
+	rec.getEntries(nil, rangeID, 1, 3, 0) // hit
+	rec.getEntries(nil, rangeID, 1, 4, 0) // miss
+	rec.getTerm(rangeID, 2)               // hit
+	rec.getTerm(rangeID, 3)               // miss
+	if hits, misses := m.Hits.Count(), m.Misses.Count(); hits != 2 || misses != 2 {
+		t.Fatalf("expected 2 hits and 2 misses, got %d and %d", hits, misses)
+	}
+
+	// Add another entry to evict the first.
+	rec.addEntries(rangeID, []raftpb.Entry{newEntry(3, 40)})
+	if evictions, size := m.Evictions.Count(), m.Size.Value(); evictions != 1 || size != 2 {
+		t.Fatalf("expected 1 eviction and 2 entries, got %d and %d", evictions, size)
+	}
+
+	// Deletions aren't evictions.
+	rec.delEntries(rangeID, 2, 4)
+	if evictions, size, bytes := m.Evictions.Count(), m.Size.Value(), m.Bytes.Value(); evictions != 1 ||
+		size != 0 || bytes != 0 {
+		t.Fatalf("expected 1 eviction and an empty cache, got %d and %d entries (%d bytes)",
+			evictions, size, bytes)
+	}
+}
+
 func BenchmarkEntryCacheClearTo(b *testing.B) {
 	rangeID := roachpb.RangeID(1)
 	ents := make([]raftpb.Entry, 1000)
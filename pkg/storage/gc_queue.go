@@ -23,12 +23,14 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/config"
 	"github.com/cockroachdb/cockroach/pkg/gossip"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/storage/abortspan"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
@@ -40,6 +42,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
 
@@ -61,8 +64,33 @@ const (
 	// gcKeyVersionChunkBytes is the threshold size for splitting
 	// GCRequests into multiple batches.
 	gcKeyVersionChunkBytes = base.ChunkRaftCommandThresholdBytes
+
+	// gcKeysBurst is the burst of the limiter of the rate at which the GC
+	// queue deletes keys.
+	gcKeysBurst = 1000
+)
+
+// gcMaxKeysPerSecond throttles the GC queue, so that the deletion of large
+// amounts of old versions doesn't hurt the foreground traffic.
+var gcMaxKeysPerSecond = settings.RegisterValidatedIntSetting(
+	"kv.gc.max_keys_per_second",
+	"maximum number of keys per second that the GC queue of each store deletes (0 for no limit)",
+	0,
+	func(v int64) error {
+		if v < 0 {
+			return errors.Errorf("cannot set kv.gc.max_keys_per_second to a negative value: %d", v)
+		}
+		return nil
+	},
 )
 
+func gcKeysLimit(sv *settings.Values) rate.Limit {
+	if n := gcMaxKeysPerSecond.Get(sv); n > 0 {
+		return rate.Limit(n)
+	}
+	return rate.Inf
+}
+
 // gcQueue manages a queue of replicas slated to be scanned in their
 // entirety using the MVCC versions iterator. The gc queue manages the
 // following tasks:
@@ -78,11 +106,20 @@ const (
 // single priority. If any task is overdue, shouldQueue returns true.
 type gcQueue struct {
 	*baseQueue
+	// keysLimiter limits the rate at which keys are deleted, as per
+	// kv.gc.max_keys_per_second.
+	keysLimiter *rate.Limiter
 }
 
 // newGCQueue returns a new instance of gcQueue.
 func newGCQueue(store *Store, gossip *gossip.Gossip) *gcQueue {
-	gcq := &gcQueue{}
+	sv := &store.cfg.Settings.SV
+	gcq := &gcQueue{
+		keysLimiter: rate.NewLimiter(gcKeysLimit(sv), gcKeysBurst),
+	}
+	gcMaxKeysPerSecond.SetOnChange(sv, func() {
+		gcq.keysLimiter.SetLimit(gcKeysLimit(sv))
+	})
 	gcq.baseQueue = newBaseQueue(
 		"gc", gcq, store, gossip,
 		queueConfig{
@@ -171,11 +208,16 @@ func makeGCQueueScore(
 		log.Errorf(ctx, "could not find zone config for range %s: %s", repl, err)
 		return gcQueueScore{}
 	}
+	maxThreshold, ok := protectedGCThreshold(repl, time.Duration(zone.GC.TTLSeconds)*time.Second)
+	if !ok {
+		// GC won't advance the threshold until the records have been read.
+		maxThreshold = gcThreshold
+	}
 	// Use desc.RangeID for fuzzing the final score, so that different ranges
 	// have slightly different priorities and even symmetrical workloads don't
 	// trigger GC at the same time.
 	r := makeGCQueueScoreImpl(
-		ctx, int64(desc.RangeID), now, ms, zone.GC.TTLSeconds,
+		ctx, int64(desc.RangeID), now, ms, zone.GC.TTLSeconds, maxThreshold,
 	)
 	if (gcThreshold != hlc.Timestamp{}) {
 		r.LikelyLastGC = time.Duration(now.WallTime - gcThreshold.Add(r.TTL.Nanoseconds(), 0).WallTime)
@@ -183,6 +225,71 @@ func makeGCQueueScore(
 	return r
 }
 
+// untilEligible estimates how long it takes for the old versions of a
+// replica to make it eligible for GC, assuming that it isn't written to in the
+// meantime. It returns zero if the replica is already eligible, and -1 if it
+// isn't expected to become eligible, e.g. because it has no old versions.
+func (r gcQueueScore) untilEligible() time.Duration {
+	if r.ShouldQueue {
+		return 0
+	}
+	if r.GCBytes <= 0 || r.DeadFraction <= 0 || r.FuzzFactor <= 0 {
+		return -1
+	}
+	// GCByteAge grows by GCBytes every second, and the replica is queued once
+	// FuzzFactor*DeadFraction*GCByteAge/(TTL*(1+GCBytes)) exceeds the
+	// threshold.
+	target := gcKeyScoreThreshold / (r.FuzzFactor * r.DeadFraction) * r.TTL.Seconds() * (1 + float64(r.GCBytes))
+	secs := (target - float64(r.GCByteAge)) / float64(r.GCBytes)
+	if secs <= 0 {
+		return 0
+	}
+	if secs >= math.MaxInt64/float64(time.Second) {
+		return -1
+	}
+	return time.Duration(secs * float64(time.Second))
+}
+
+// GCProgress describes the state of a replica with respect to the GC queue.
+type GCProgress struct {
+	// Score is the priority of the replica in the GC queue.
+	Score float64
+	// ShouldQueue is set if the replica is eligible for GC.
+	ShouldQueue bool
+	// LastRun is the last time the GC queue processed the replica, or zero if
+	// it never did.
+	LastRun hlc.Timestamp
+	// BytesAwaitingGC is the size of the non-live data of the replica, which
+	// GC removes once it's older than the TTL.
+	BytesAwaitingGC int64
+	// TTL is the GC TTL of the replica's zone.
+	TTL time.Duration
+	// UntilEligible is an estimate of the time until the replica becomes
+	// eligible for GC if it isn't written to in the meantime, or -1 if it
+	// isn't expected to become eligible.
+	UntilEligible time.Duration
+}
+
+// GCProgress returns the state of the replica with respect to the GC queue.
+// It's only meaningful on the leaseholder, which runs the GC queue.
+func (r *Replica) GCProgress(
+	ctx context.Context, now hlc.Timestamp, sysCfg config.SystemConfig,
+) (GCProgress, error) {
+	lastRun, err := r.getQueueLastProcessed(ctx, "gc")
+	if err != nil {
+		return GCProgress{}, err
+	}
+	score := makeGCQueueScore(ctx, r, now, sysCfg)
+	return GCProgress{
+		Score:           score.FinalScore,
+		ShouldQueue:     score.ShouldQueue,
+		LastRun:         lastRun,
+		BytesAwaitingGC: score.GCBytes,
+		TTL:             score.TTL,
+		UntilEligible:   score.untilEligible(),
+	}, nil
+}
+
 // makeGCQueueScoreImpl is used to compute when to trigger the GC Queue. It's
 // important that we don't queue a replica before a relevant amount of data is
 // actually deletable, or the queue might run in a tight loop. To this end, we
@@ -272,7 +379,12 @@ func makeGCQueueScore(
 // ttl*GCBytes`, and that a decent trigger for GC is a multiple of
 // `ttl*GCBytes`.
 func makeGCQueueScoreImpl(
-	ctx context.Context, fuzzSeed int64, now hlc.Timestamp, ms enginepb.MVCCStats, ttlSeconds int32,
+	ctx context.Context,
+	fuzzSeed int64,
+	now hlc.Timestamp,
+	ms enginepb.MVCCStats,
+	ttlSeconds int32,
+	maxThreshold hlc.Timestamp,
 ) gcQueueScore {
 	ms.Forward(now.WallTime)
 	var r gcQueueScore
@@ -285,7 +397,16 @@ func makeGCQueueScoreImpl(
 		r.TTL = time.Second
 	}
 
-	r.GCByteAge = ms.GCByteAge(now.WallTime)
+	// GC can't advance the threshold past maxThreshold, which keeps the data
+	// protected by the protected timestamp records, so it can only remove what
+	// it would have removed at maxThreshold+TTL. Score the values as of then,
+	// or a range whose garbage is protected would be queued over and over
+	// without GC making any progress.
+	valuesNow := now
+	if maxThreshold.Less(now.Add(-r.TTL.Nanoseconds(), 0)) {
+		valuesNow = maxThreshold.Add(r.TTL.Nanoseconds(), 0)
+	}
+	r.GCByteAge = ms.GCByteAge(valuesNow.WallTime)
 	r.GCBytes = ms.GCBytes()
 
 	// If we GC'ed now, we can expect to delete at least this much GCByteAge.
@@ -370,7 +491,7 @@ func processLocalKeyRange(
 	handleTxnIntents := func(key roachpb.Key, txn *roachpb.Transaction) error {
 		// If the transaction needs to be pushed or there are intents to
 		// resolve, invoke the cleanup function.
-		if txn.Status == roachpb.PENDING || len(txn.Intents) > 0 {
+		if !txn.Status.IsFinalized() || len(txn.Intents) > 0 {
 			return cleanupTxnIntentsAsyncFn(ctx, txn, roachpb.AsIntents(txn.Intents, txn))
 		}
 		gcKeys = append(gcKeys, roachpb.GCRequest_GCKey{Key: key}) // zero timestamp
@@ -389,7 +510,7 @@ func processLocalKeyRange(
 
 		// The transaction record should be considered for removal.
 		switch txn.Status {
-		case roachpb.PENDING:
+		case roachpb.PENDING, roachpb.STAGING:
 			infoMu.TransactionSpanGCPending++
 		case roachpb.ABORTED:
 			infoMu.TransactionSpanGCAborted++
@@ -503,7 +624,14 @@ func (gcq *gcQueue) process(ctx context.Context, repl *Replica, sysCfg config.Sy
 	}
 
 	log.Eventf(ctx, "processing replica with score %s", r)
-	return gcq.processImpl(ctx, repl, sysCfg, now)
+	if err := gcq.processImpl(ctx, repl, sysCfg, now); err != nil {
+		return err
+	}
+	// Record the run, which is reported by the GC progress of the replica.
+	if err := repl.setQueueLastProcessed(ctx, gcq.name, now); err != nil {
+		log.VErrEventf(ctx, 2, "failed to update last processed time: %v", err)
+	}
+	return nil
 }
 
 // NoopGCer implements GCer by doing nothing.
@@ -518,8 +646,10 @@ func (NoopGCer) SetGCThreshold(context.Context, GCThreshold) error { return nil
 func (NoopGCer) GC(context.Context, []roachpb.GCRequest_GCKey) error { return nil }
 
 type replicaGCer struct {
-	repl  *Replica
-	count int32 // update atomically
+	repl *Replica
+	// limiter, if set, limits the rate at which keys are deleted.
+	limiter *rate.Limiter
+	count   int32 // update atomically
 }
 
 var _ GCer = &replicaGCer{}
@@ -562,17 +692,41 @@ func (r *replicaGCer) GC(ctx context.Context, keys []roachpb.GCRequest_GCKey) er
 	if len(keys) == 0 {
 		return nil
 	}
+	if err := r.throttle(ctx, len(keys)); err != nil {
+		return err
+	}
 	req := r.template()
 	req.Keys = keys
 	return r.send(ctx, req)
 }
 
+// throttle waits until the limiter allows n more keys to be deleted.
+func (r *replicaGCer) throttle(ctx context.Context, n int) error {
+	if r.limiter == nil || r.limiter.Limit() == rate.Inf {
+		return nil
+	}
+	begin := timeutil.Now()
+	defer func() {
+		r.repl.store.metrics.GCThrottledNanos.Inc(timeutil.Since(begin).Nanoseconds())
+	}()
+	// The limiter rejects requests for more than its burst.
+	for n > 0 {
+		chunk := n
+		if chunk > gcKeysBurst {
+			chunk = gcKeysBurst
+		}
+		if err := r.limiter.WaitN(ctx, chunk); err != nil {
+			return err
+		}
+		n -= chunk
+	}
+	return nil
+}
+
 func (gcq *gcQueue) processImpl(
 	ctx context.Context, repl *Replica, sysCfg config.SystemConfig, now hlc.Timestamp,
 ) error {
-	snap := repl.store.Engine().NewSnapshot()
 	desc := repl.Desc()
-	defer snap.Close()
 
 	// Lookup the GC policy for the zone containing this key range.
 	zone, err := sysCfg.GetZoneConfigForKey(desc.StartKey)
@@ -580,7 +734,42 @@ func (gcq *gcQueue) processImpl(
 		return errors.Errorf("could not find zone config for range %s: %s", repl, err)
 	}
 
-	info, err := RunGC(ctx, desc, snap, now, zone.GC, &replicaGCer{repl: repl},
+	maxThreshold, ok := protectedGCThreshold(repl, time.Duration(zone.GC.TTLSeconds)*time.Second)
+	if !ok {
+		// Keep cleaning up intents and transactions, but without advancing
+		// the GC threshold.
+		log.Event(ctx, "protected timestamps not read yet; not advancing the GC threshold")
+		maxThreshold = repl.GetGCThreshold()
+	}
+
+	// If the range has no live data left and hasn't been written to since
+	// the GC threshold, which its MVCC stats tell without scanning it, clear
+	// all of its data at once instead of GC'ing it key by key. The rest of
+	// the GC run then only has the range-local data left to clean up.
+	threshold := engine.MakeGarbageCollector(now, zone.GC).Threshold
+	if maxThreshold.Less(threshold) {
+		threshold = maxThreshold
+	}
+	gcer := &replicaGCer{repl: repl, limiter: gcq.keysLimiter}
+	if ms := repl.GetMVCCStats(); canGCClearRange(ms, threshold) {
+		req := gcer.template()
+		req.Threshold = threshold
+		req.ClearRange = true
+		if err := gcer.send(ctx, req); err != nil {
+			// The range was probably written to in the meantime.
+			log.VEventf(ctx, 1, "failed to clear range: %s", err)
+			gcq.store.metrics.GCClearRangeFailed.Inc(1)
+		} else {
+			log.Eventf(ctx, "cleared %s of data below the GC threshold %s",
+				humanizeutil.IBytes(ms.Total()), threshold)
+			gcq.store.metrics.GCClearRangeSuccess.Inc(1)
+		}
+	}
+
+	snap := repl.store.Engine().NewSnapshot()
+	defer snap.Close()
+
+	info, err := RunGC(ctx, desc, snap, now, zone.GC, maxThreshold, gcer,
 		func(ctx context.Context, intents []roachpb.Intent) error {
 			intentCount, err := repl.store.intentResolver.cleanupIntents(ctx, intents, now, roachpb.PUSH_ABORT)
 			if err == nil {
@@ -607,6 +796,37 @@ func (gcq *gcQueue) processImpl(
 	return nil
 }
 
+// canGCClearRange returns whether the MVCC stats of a range show that none of
+// its data can be read at or above the given GC threshold, so that all of it
+// can be cleared at once. This is the case after all of the range's keys have
+// been deleted, like after a DROP TABLE, and the deletions have expired.
+func canGCClearRange(ms enginepb.MVCCStats, threshold hlc.Timestamp) bool {
+	return !ms.ContainsEstimates && ms.LiveCount == 0 && ms.IntentCount == 0 &&
+		ms.KeyCount > 0 && ms.LastUpdateNanos < threshold.WallTime
+}
+
+// protectedGCThreshold returns the highest GC threshold that keeps the data
+// of the replica protected by the protected timestamp records, given the
+// replica's GC TTL. It returns false if the records haven't been read yet, in
+// which case no data can be assumed to be unprotected.
+func protectedGCThreshold(repl *Replica, ttl time.Duration) (hlc.Timestamp, bool) {
+	cache := repl.store.cfg.ProtectedTimestampCache
+	if cache == nil {
+		return hlc.MaxTimestamp, true
+	}
+	protected, readAt := cache.Protected(repl.Desc().RSpan().AsRawSpanWithNoLocals())
+	if readAt == (hlc.Timestamp{}) {
+		return hlc.Timestamp{}, false
+	}
+	// A record written since the records were read protects a timestamp no
+	// older than the TTL at the time it was written, which is above this.
+	threshold := readAt.Add(-ttl.Nanoseconds(), 0)
+	if protected != (hlc.Timestamp{}) && !threshold.Less(protected) {
+		threshold = protected.Prev()
+	}
+	return threshold, true
+}
+
 // GCInfo contains statistics and insights from a GC run.
 type GCInfo struct {
 	// Now is the timestamp used for age computations.
@@ -639,7 +859,8 @@ type GCInfo struct {
 	// ResolveTotal is the total number of attempted intent resolutions in
 	// this cycle.
 	ResolveTotal int
-	// Threshold is the computed expiration timestamp. Equal to `Now - Policy`.
+	// Threshold is the computed expiration timestamp. Equal to `Now - Policy`,
+	// unless held back by the maximum threshold.
 	Threshold hlc.Timestamp
 	// AffectedVersionsKeyBytes is the number of (fully encoded) bytes deleted from keys in the storage engine.
 	// Note that this does not account for compression that the storage engine uses to store data on disk. Real
@@ -688,13 +909,16 @@ type GCer interface {
 // to run garbage collection once on all implicated spans,
 // cleanupIntentsFn to resolve intents synchronously, and
 // cleanupTxnIntentsAsyncFn to asynchronously cleanup intents and
-// associated transaction record on success.
+// associated transaction record on success. The GC threshold is held
+// at or below maxThreshold, which keeps the data protected by the
+// protected timestamp records.
 func RunGC(
 	ctx context.Context,
 	desc *roachpb.RangeDescriptor,
 	snap engine.Reader,
 	now hlc.Timestamp,
 	policy config.GCPolicy,
+	maxThreshold hlc.Timestamp,
 	gcer GCer,
 	cleanupIntentsFn cleanupIntentsFunc,
 	cleanupTxnIntentsAsyncFn cleanupTxnIntentsAsyncFunc,
@@ -712,6 +936,9 @@ func RunGC(
 	txnExp := now.Add(-storagebase.TxnCleanupThreshold.Nanoseconds(), 0)
 
 	gc := engine.MakeGarbageCollector(now, policy)
+	if maxThreshold.Less(gc.Threshold) {
+		gc.Threshold = maxThreshold
+	}
 	infoMu.Threshold = gc.Threshold
 	infoMu.TxnSpanGCThreshold = txnExp
 
@@ -27,10 +27,12 @@ import (
 	"github.com/pkg/errors"
 	"golang.org/x/sync/syncmap"
 
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -108,7 +110,7 @@ func TestGCQueueMakeGCScoreInvariantQuick(t *testing.T) {
 			GCBytesAge:      gcByteAge,
 		}
 		now := initialNow.Add(timePassed.Nanoseconds(), 0)
-		r := makeGCQueueScoreImpl(ctx, int64(seed), now, ms, ttlSec)
+		r := makeGCQueueScoreImpl(ctx, int64(seed), now, ms, ttlSec, hlc.MaxTimestamp)
 		wouldHaveToDeleteSomething := gcBytes*int64(ttlSec) < ms.GCByteAge(now.WallTime)
 		result := !r.ShouldQueue || wouldHaveToDeleteSomething
 		if !result {
@@ -129,7 +131,7 @@ func TestGCQueueMakeGCScoreAnomalousStats(t *testing.T) {
 			LiveBytes:         int64(liveBytes),
 			ValBytes:          int64(valBytes),
 			KeyBytes:          int64(keyBytes),
-		}, 60)
+		}, 60, hlc.MaxTimestamp)
 		return r.DeadFraction >= 0 && r.DeadFraction <= 1
 	}, &quick.Config{MaxCount: 1000}); err != nil {
 		t.Fatal(err)
@@ -249,7 +251,9 @@ func (cws *cachedWriteSimulator) shouldQueue(
 ) {
 	cws.t.Helper()
 	ts := hlc.Timestamp{}.Add(ms.LastUpdateNanos+after.Nanoseconds(), 0)
-	r := makeGCQueueScoreImpl(context.Background(), 0 /* seed */, ts, ms, int32(ttl.Seconds()))
+	r := makeGCQueueScoreImpl(
+		context.Background(), 0 /* seed */, ts, ms, int32(ttl.Seconds()), hlc.MaxTimestamp,
+	)
 	if fmt.Sprintf("%.2f", r.FinalScore) != fmt.Sprintf("%.2f", prio) || b != r.ShouldQueue {
 		cws.t.Errorf("expected queued=%t (is %t), prio=%.2f, got %.2f: after=%s, ttl=%s:\nms: %+v\nscore: %s",
 			b, r.ShouldQueue, prio, r.FinalScore, after, ttl, ms, r)
@@ -260,6 +264,78 @@ func (cws *cachedWriteSimulator) shouldQueue(
 // whether or not the range should be queued into the GC queue. Ranges are
 // queued for GC based on two conditions. The age of bytes available to be GC'd,
 // and the age of unresolved intents.
+// TestGCQueueScoreUntilEligible verifies that the estimated time until a
+// replica becomes eligible for GC matches its score once that time has passed.
+func TestGCQueueScoreUntilEligible(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	initialNow := hlc.Timestamp{}.Add(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano(), 0)
+	const ttlSec = 100
+
+	if d := makeGCQueueScoreImpl(ctx, 0, initialNow, enginepb.MVCCStats{
+		LastUpdateNanos: initialNow.WallTime,
+		LiveBytes:       1000,
+		KeyBytes:        100,
+		ValBytes:        900,
+	}, ttlSec, hlc.MaxTimestamp).untilEligible(); d != -1 {
+		t.Fatalf("expected no estimate without non-live data, got %s", d)
+	}
+
+	for _, seed := range []int64{0, 1, 2} {
+		ms := enginepb.MVCCStats{
+			LastUpdateNanos: initialNow.WallTime,
+			LiveBytes:       100,
+			KeyBytes:        100,
+			ValBytes:        900,
+		}
+		r := makeGCQueueScoreImpl(ctx, seed, initialNow, ms, ttlSec, hlc.MaxTimestamp)
+		if r.ShouldQueue {
+			t.Fatalf("%d: unexpectedly eligible: %s", seed, r)
+		}
+		d := r.untilEligible()
+		if d <= 0 {
+			t.Fatalf("%d: expected a positive estimate, got %s", seed, d)
+		}
+		before := initialNow.Add(int64(float64(d)*0.99), 0)
+		if r := makeGCQueueScoreImpl(ctx, seed, before, ms, ttlSec, hlc.MaxTimestamp); r.ShouldQueue {
+			t.Errorf("%d: eligible before the estimate of %s: %s", seed, d, r)
+		}
+		after := initialNow.Add(int64(float64(d)*1.01), 0)
+		r = makeGCQueueScoreImpl(ctx, seed, after, ms, ttlSec, hlc.MaxTimestamp)
+		if !r.ShouldQueue {
+			t.Errorf("%d: not eligible after the estimate of %s: %s", seed, d, r)
+		}
+		if d := r.untilEligible(); d != 0 {
+			t.Errorf("%d: expected a zero estimate once eligible, got %s", seed, d)
+		}
+	}
+}
+
+// TestGCQueueMakeGCScoreProtected verifies that a replica isn't queued for GC
+// if all of its garbage is protected by protected timestamp records.
+func TestGCQueueMakeGCScoreProtected(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	initialNow := hlc.Timestamp{}.Add(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano(), 0)
+	const ttlSec = 100
+	ms := enginepb.MVCCStats{
+		LastUpdateNanos: initialNow.WallTime,
+		LiveBytes:       100,
+		KeyBytes:        100,
+		ValBytes:        900,
+	}
+	// Long enough for the garbage to be eligible without protection.
+	now := initialNow.Add(100*ttlSec*time.Second.Nanoseconds(), 0)
+	if r := makeGCQueueScoreImpl(ctx, 0, now, ms, ttlSec, hlc.MaxTimestamp); !r.ShouldQueue {
+		t.Fatalf("expected to be queued without protection: %s", r)
+	}
+	// The garbage was all written before the protected timestamp, so GC
+	// can't remove any of it.
+	if r := makeGCQueueScoreImpl(ctx, 0, now, ms, ttlSec, initialNow); r.ShouldQueue {
+		t.Fatalf("unexpectedly queued with all garbage protected: %s", r)
+	}
+}
+
 func TestGCQueueMakeGCScoreRealistic(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -507,7 +583,7 @@ func TestGCQueueProcess(t *testing.T) {
 
 		ctx := context.Background()
 		now := tc.Clock().Now()
-		return RunGC(ctx, desc, snap, now, zone.GC,
+		return RunGC(ctx, desc, snap, now, zone.GC, hlc.MaxTimestamp,
 			NoopGCer{},
 			func(ctx context.Context, intents []roachpb.Intent) error {
 				return nil
@@ -935,6 +1011,169 @@ func TestGCQueueLastProcessedTimestamps(t *testing.T) {
 	})
 }
 
+// TestGCQueueProtectedTimestamp verifies that the GC queue holds the GC
+// threshold below the protected timestamp records overlapping a range, and
+// that GC requests which would remove protected data are rejected.
+func TestGCQueueProtectedTimestamp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	manual := hlc.NewManualClock(123)
+	tsc := TestStoreConfig(hlc.NewClock(manual.UnixNano, time.Nanosecond))
+	tc := testContext{manualClock: manual}
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+	tc.StartWithStoreConfig(t, stopper, tsc)
+
+	cache := protectedts.NewCache(tc.store.DB(), tc.store.ClusterSettings())
+	tc.store.cfg.ProtectedTimestampCache = cache
+
+	// Write two versions of a key.
+	key := roachpb.Key("a")
+	put := func(value string) hlc.Timestamp {
+		ts := tc.Clock().Now()
+		pArgs := putArgs(key, []byte(value))
+		if _, pErr := client.SendWrappedWith(ctx, tc.Sender(), roachpb.Header{Timestamp: ts}, &pArgs); pErr != nil {
+			t.Fatal(pErr)
+		}
+		return ts
+	}
+	ts1 := put("value1")
+	put("value2")
+
+	record := protectedts.Record{
+		Timestamp: ts1,
+		Spans:     []roachpb.Span{{Key: key, EndKey: key.Next()}},
+	}
+	if err := tc.store.DB().Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		return protectedts.Protect(ctx, txn, &record)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, ok := tc.gossip.GetSystemConfig()
+	if !ok {
+		t.Fatal("config not set")
+	}
+	zone, err := cfg.GetZoneConfigForKey(roachpb.RKey(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcQ := newGCQueue(tc.store, tc.gossip)
+	runGC := func() {
+		t.Helper()
+		tc.manualClock.Increment(int64(zone.GC.TTLSeconds)*1E9 + 1)
+		if err := cache.Refresh(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if err := gcQ.processImpl(ctx, tc.repl, cfg, tc.Clock().Now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	readAtTS1 := func() *roachpb.Value {
+		t.Helper()
+		value, _, err := engine.MVCCGet(ctx, tc.engine, key, ts1, true /* consistent */, nil /* txn */)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return value
+	}
+
+	// The record holds the GC threshold just below its timestamp, so the
+	// first version can still be read.
+	runGC()
+	if threshold := tc.repl.GetGCThreshold(); threshold != ts1.Prev() {
+		t.Fatalf("expected GC threshold %s, got %s", ts1.Prev(), threshold)
+	}
+	if readAtTS1() == nil {
+		t.Fatal("expected the protected version to be kept")
+	}
+
+	// A GC request which ignores the record is rejected.
+	gArgs := gcArgs(key, key.Next())
+	gArgs.Threshold = tc.Clock().Now()
+	if _, pErr := client.SendWrapped(ctx, tc.Sender(), &gArgs); !testutils.IsPError(pErr, "would remove data protected") {
+		t.Fatalf("expected the GC request to be rejected, got %v", pErr)
+	}
+
+	// Once the record is released, the first version is removed.
+	if err := tc.store.DB().Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		return protectedts.Release(ctx, txn, record.ID)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	runGC()
+	if threshold := tc.repl.GetGCThreshold(); !ts1.Less(threshold) {
+		t.Fatalf("expected GC threshold above %s, got %s", ts1, threshold)
+	}
+	if value := readAtTS1(); value != nil {
+		t.Fatalf("expected the released version to be removed, got %v", value)
+	}
+}
+
+// TestGCQueueClearRange verifies that the GC queue clears all the data of a
+// range at once after all of its keys have been deleted and the deletions
+// have expired, and that it can't be done while there's live data.
+func TestGCQueueClearRange(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	tc := testContext{bootstrapMode: bootstrapRangeOnly}
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+	tc.Start(t, stopper)
+
+	userKeys := []roachpb.Key{roachpb.Key("a"), roachpb.Key("b")}
+	for _, key := range userKeys {
+		pArgs := putArgs(key, []byte("value"))
+		if _, pErr := client.SendWrapped(ctx, tc.Sender(), &pArgs); pErr != nil {
+			t.Fatal(pErr)
+		}
+	}
+
+	desc := tc.repl.Desc()
+	gArgs := gcArgs(desc.StartKey, desc.EndKey)
+	gArgs.Threshold = tc.Clock().Now()
+	gArgs.ClearRange = true
+	if _, pErr := client.SendWrapped(ctx, tc.Sender(), &gArgs); !testutils.IsPError(pErr, "still has data") {
+		t.Fatalf("expected the range not to be cleared with live data, got %v", pErr)
+	}
+
+	for _, key := range userKeys {
+		dArgs := deleteArgs(key)
+		if _, pErr := client.SendWrapped(ctx, tc.Sender(), &dArgs); pErr != nil {
+			t.Fatal(pErr)
+		}
+	}
+
+	cfg, ok := tc.gossip.GetSystemConfig()
+	if !ok {
+		t.Fatal("config not set")
+	}
+	zone, err := cfg.GetZoneConfigForKey(desc.StartKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tc.manualClock.Increment(int64(zone.GC.TTLSeconds)*1E9 + 1)
+	gcQ := newGCQueue(tc.store, tc.gossip)
+	if err := gcQ.processImpl(ctx, tc.repl, cfg, tc.Clock().Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := tc.store.metrics.GCClearRangeSuccess.Count(); n != 1 {
+		t.Fatalf("expected the range to be cleared once, got %d", n)
+	}
+	if ms := tc.repl.GetMVCCStats(); ms.KeyCount != 0 || ms.ValCount != 0 {
+		t.Fatalf("expected no keys left, got %+v", ms)
+	}
+	if err := tc.engine.Iterate(
+		engine.MakeMVCCMetadataKey(keys.LocalMax), engine.MakeMVCCMetadataKey(roachpb.KeyMax),
+		func(kv engine.MVCCKeyValue) (bool, error) {
+			return false, errors.Errorf("unexpected key %s left", kv.Key)
+		},
+	); err != nil {
+		t.Fatal(err)
+	}
+}
+
 // TestGCQueueChunkRequests verifies that many intents are chunked
 // into separate batches. This is verified both for many different
 // keys and also for many different versions of keys.
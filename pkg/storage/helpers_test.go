@@ -41,6 +41,9 @@ import (
 	"github.com/rubyist/circuitbreaker"
 )
 
+// SnapshotIngestMinSize exposes kv.snapshot_ingest.min_size to tests.
+var SnapshotIngestMinSize = snapshotIngestMinSize
+
 // AddReplica adds the replica to the store's replica map and to the sorted
 // replicasByKey slice. To be used only by unittests.
 func (s *Store) AddReplica(repl *Replica) error {
@@ -582,6 +582,19 @@ func (ir *intentResolver) maybePushTransactions(
 		log.Eventf(ctx, "%s is now %s", txn.ID, txn.Status)
 	}
 
+	// A STAGING pushee is only returned once it has expired. Its intents
+	// can't be resolved until we've determined whether it committed.
+	for id, txn := range pushedTxns {
+		if txn.Status != roachpb.STAGING {
+			continue
+		}
+		recoveredTxn, err := ir.recoverStagingTxn(ctx, &txn)
+		if err != nil {
+			return nil, roachpb.NewError(err)
+		}
+		pushedTxns[id] = *recoveredTxn
+	}
+
 	var resolveIntents []roachpb.Intent
 	for _, intent := range pushIntents {
 		pushee, ok := pushedTxns[intent.Txn.ID]
@@ -798,6 +811,21 @@ func (ir *intentResolver) cleanupTxnIntentsOnGCAsync(
 				}
 			}
 
+			// Likewise, an expired STAGING transaction has to be recovered.
+			if txn.Status == roachpb.STAGING {
+				if !txnwait.IsExpired(now, txn) {
+					log.VErrEventf(ctx, 3, "cannot recover a STAGING transaction which is not expired: %s", txn)
+					return
+				}
+				recoveredTxn, err := ir.recoverStagingTxn(ctx, txn)
+				if err != nil {
+					log.VErrEventf(ctx, 2, "failed to recover STAGING, expired txn (%s): %s", txn, err)
+					return
+				}
+				txn = recoveredTxn
+				intents = roachpb.AsIntents(txn.Intents, txn)
+			}
+
 			if err := ir.cleanupFinishedTxnIntents(ctx, txn, intents, now, false /* poison */); err != nil {
 				log.Warningf(ctx, "failed to cleanup transaction intents: %s", err)
 			} else {
@@ -807,6 +835,55 @@ func (ir *intentResolver) cleanupTxnIntentsOnGCAsync(
 	)
 }
 
+// recoverStagingTxn determines whether an expired STAGING transaction was
+// implicitly committed, which is the case if all of its in-flight writes
+// succeeded, and finalizes its record accordingly. Any in-flight write which
+// is not found is prevented from ever succeeding, so the outcome can't change
+// underneath us. Returns the finalized transaction.
+func (ir *intentResolver) recoverStagingTxn(
+	ctx context.Context, txn *roachpb.Transaction,
+) (*roachpb.Transaction, error) {
+	b := &client.Batch{}
+	for _, w := range txn.InFlightWrites {
+		meta := txn.TxnMeta
+		meta.Sequence = w.Sequence
+		b.AddRawRequest(&roachpb.QueryIntentRequest{
+			RequestHeader: roachpb.RequestHeader{Key: w.Key},
+			Txn:           meta,
+			IfMissing:     roachpb.QueryIntentRequest_PREVENT,
+		})
+	}
+	if err := ir.store.DB().Run(ctx, b); err != nil {
+		return nil, errors.Wrapf(err, "failed to query in-flight writes of %s", txn)
+	}
+	commit := true
+	for _, resp := range b.RawResponse().Responses {
+		if !resp.GetInner().(*roachpb.QueryIntentResponse).FoundIntent {
+			commit = false
+			break
+		}
+	}
+	log.VEventf(ctx, 2, "recovering STAGING txn %s: commit=%t", txn.ID.Short(), commit)
+
+	// Finalize the transaction record on the transaction's behalf.
+	hTxn := txn.Clone()
+	hTxn.Status = roachpb.PENDING
+	hTxn.InFlightWrites = nil
+	resp, pErr := client.SendWrappedWith(
+		ctx, ir.store.DB().GetFactory().NonTransactionalSender(), roachpb.Header{Txn: &hTxn},
+		&roachpb.EndTransactionRequest{
+			RequestHeader: roachpb.RequestHeader{Key: txn.Key},
+			Commit:        commit,
+			IntentSpans:   txn.Intents,
+		},
+	)
+	if pErr != nil {
+		return nil, errors.Wrapf(pErr.GoError(), "failed to finalize %s", txn)
+	}
+	ir.store.metrics.TxnRecoveries.Inc(1)
+	return resp.Header().Txn, nil
+}
+
 // cleanupFinishedTxnIntents cleans up extant intents owned by a
 // single transaction and when all intents have been successfully
 // resolved, the transaction record is GC'ed.
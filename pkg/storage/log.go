@@ -148,7 +148,7 @@ func (s *Store) logChange(
 	var logType RangeLogEventType
 	var info RangeLogEvent_Info
 	switch changeType {
-	case roachpb.ADD_REPLICA:
+	case roachpb.ADD_REPLICA, roachpb.ADD_LEARNER:
 		logType = RangeLogEventType_add
 		info = RangeLogEvent_Info{
 			AddedReplica: &replica,
@@ -62,6 +62,12 @@ var (
 		Measurement: "Replicas",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaDormantCount = metric.Metadata{
+		Name:        "replicas.dormant",
+		Help:        "Number of replicas whose Raft group hasn't been created yet",
+		Measurement: "Replicas",
+		Unit:        metric.Unit_COUNT,
+	}
 
 	// Replica CommandQueue metrics. Max size metrics track the maximum value
 	// seen for all replicas during a single replica scan.
@@ -273,6 +279,12 @@ var (
 		Measurement: "Storage",
 		Unit:        metric.Unit_BYTES,
 	}
+	metaDiskFull = metric.Metadata{
+		Name:        "capacity.read_only",
+		Help:        "1 if the store is rejecting writes to user data because its disk is full",
+		Measurement: "Storage",
+		Unit:        metric.Unit_COUNT,
+	}
 
 	metaReserved = metric.Metadata{
 		Name:        "capacity.reserved",
@@ -294,12 +306,30 @@ var (
 	}
 
 	// Metrics used by the rebalancing logic that aren't already captured elsewhere.
+	metaAverageQueriesPerSecond = metric.Metadata{
+		Name:        "rebalancing.queriespersecond",
+		Help:        "Number of kv-level requests received per second by the store, averaged over a large time period as used in rebalancing decisions",
+		Measurement: "Queries/Sec",
+		Unit:        metric.Unit_COUNT,
+	}
 	metaAverageWritesPerSecond = metric.Metadata{
 		Name:        "rebalancing.writespersecond",
 		Help:        "Number of keys written (i.e. applied by raft) per second to the store, averaged over a large time period as used in rebalancing decisions",
 		Measurement: "Keys/Sec",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaRebalancingLeaseTransfers = metric.Metadata{
+		Name:        "rebalancing.lease.transfers",
+		Help:        "Number of lease transfers motivated by store-level load imbalances",
+		Measurement: "Lease Transfers",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaRebalancingRangeRebalances = metric.Metadata{
+		Name:        "rebalancing.range.rebalances",
+		Help:        "Number of range rebalance operations motivated by store-level load imbalances",
+		Measurement: "Range Rebalances",
+		Unit:        metric.Unit_COUNT,
+	}
 
 	// RocksDB metrics.
 	metaRdbBlockCacheHits = metric.Metadata{
@@ -374,6 +404,12 @@ var (
 		Measurement: "SSTables",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaRdbWALSyncLatency = metric.Metadata{
+		Name:        "rocksdb.wal-sync.latency",
+		Help:        "Latency histogram for syncing the rocksdb WAL to disk",
+		Measurement: "Latency",
+		Unit:        metric.Unit_NANOSECONDS,
+	}
 
 	// Range event metrics.
 	metaRangeSplits = metric.Metadata{
@@ -412,6 +448,24 @@ var (
 		Measurement: "Snapshots",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaRangeSnapshotsIngested = metric.Metadata{
+		Name:        "range.snapshots.ingested",
+		Help:        "Number of applied snapshots whose data was ingested as an SST",
+		Measurement: "Snapshots",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaRangeSnapshotRcvdBytesCompressed = metric.Metadata{
+		Name:        "range.snapshots.rcvd-compressed-bytes",
+		Help:        "Number of bytes of compressed snapshot data received",
+		Measurement: "Storage",
+		Unit:        metric.Unit_BYTES,
+	}
+	metaRangeSnapshotRcvdBytesUncompressed = metric.Metadata{
+		Name:        "range.snapshots.rcvd-uncompressed-bytes",
+		Help:        "Number of bytes the compressed snapshot data received decompressed to",
+		Measurement: "Storage",
+		Unit:        metric.Unit_BYTES,
+	}
 	metaRangeRaftLeaderTransfers = metric.Metadata{
 		Name:        "range.raftleadertransfers",
 		Help:        "Number of raft leader transfers",
@@ -456,6 +510,18 @@ var (
 		Measurement: "Latency",
 		Unit:        metric.Unit_NANOSECONDS,
 	}
+	metaRaftSchedulerLatencyNormal = metric.Metadata{
+		Name:        "raft.scheduler.latency.normal",
+		Help:        "Latency histogram for the user ranges waiting in the Raft scheduler queue",
+		Measurement: "Latency",
+		Unit:        metric.Unit_NANOSECONDS,
+	}
+	metaRaftSchedulerLatencyHigh = metric.Metadata{
+		Name:        "raft.scheduler.latency.high",
+		Help:        "Latency histogram for the system ranges (meta and node liveness) waiting in the Raft scheduler queue",
+		Measurement: "Latency",
+		Unit:        metric.Unit_NANOSECONDS,
+	}
 
 	// Raft message metrics.
 	metaRaftRcvdProp = metric.Metadata{
@@ -536,6 +602,18 @@ var (
 		Measurement: "Messages",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaRaftRcvdEntryBytesCompressed = metric.Metadata{
+		Name:        "raft.rcvd.entries.compressed-bytes",
+		Help:        "Number of bytes of compressed Raft entry data received",
+		Measurement: "Storage",
+		Unit:        metric.Unit_BYTES,
+	}
+	metaRaftRcvdEntryBytesUncompressed = metric.Metadata{
+		Name:        "raft.rcvd.entries.uncompressed-bytes",
+		Help:        "Number of bytes the compressed Raft entry data received decompressed to",
+		Measurement: "Storage",
+		Unit:        metric.Unit_BYTES,
+	}
 	metaRaftEnqueuedPending = metric.Metadata{
 		Name:        "raft.enqueued.pending",
 		Help:        "Number of pending outgoing messages in the Raft Transport queue",
@@ -562,6 +640,18 @@ var (
 		Measurement: "Log Entries",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaRaftLogTotalBytes = metric.Metadata{
+		Name:        "raftlog.totalbytes",
+		Help:        "Total size of the Raft logs of the replicas of the store",
+		Measurement: "Storage",
+		Unit:        metric.Unit_BYTES,
+	}
+	metaRaftLogOverBudgetCount = metric.Metadata{
+		Name:        "raftlog.overbudget",
+		Help:        "Number of Raft logs truncated without waiting for behind followers to keep the store under kv.raft_log.store_max_size",
+		Measurement: "Replicas",
+		Unit:        metric.Unit_COUNT,
+	}
 
 	// Replica queue metrics.
 	metaGCQueueSuccesses = metric.Metadata{
@@ -848,6 +938,30 @@ var (
 		Measurement: "Intent Resolutions",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaGCClearRangeSuccess = metric.Metadata{
+		Name:        "queue.gc.info.clearrangesuccess",
+		Help:        "Number of ranges whose data was cleared at once by GC",
+		Measurement: "Ranges",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaGCClearRangeFailed = metric.Metadata{
+		Name:        "queue.gc.info.clearrangefailed",
+		Help:        "Number of failed attempts by GC to clear the data of a range at once",
+		Measurement: "Ranges",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaGCThrottledNanos = metric.Metadata{
+		Name:        "queue.gc.throttlednanos",
+		Help:        "Time spent by GC waiting for kv.gc.max_keys_per_second",
+		Measurement: "Processing Time",
+		Unit:        metric.Unit_NANOSECONDS,
+	}
+	metaGCBytesAwaiting = metric.Metadata{
+		Name:        "queue.gc.bytesawaiting",
+		Help:        "Number of bytes of non-live data awaiting GC in the ranges for which this store holds the lease",
+		Measurement: "Storage",
+		Unit:        metric.Unit_BYTES,
+	}
 
 	// Intent resolver metrics.
 	metaIntentResolverAsyncThrottled = metric.Metadata{
@@ -856,6 +970,12 @@ var (
 		Measurement: "Intent Resolutions",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaIntentResolverTxnRecoveries = metric.Metadata{
+		Name:        "intentresolver.txnrecoveries",
+		Help:        "Number of expired STAGING transactions committed or aborted by recovery",
+		Measurement: "KV Transactions",
+		Unit:        metric.Unit_COUNT,
+	}
 
 	// Slow request metrics.
 	metaSlowCommandQueueRequests = metric.Metadata{
@@ -884,6 +1004,12 @@ var (
 		Measurement: "Writes",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaDiskFullRejectedRequests = metric.Metadata{
+		Name:        "requests.rejected.disk_full",
+		Help:        "Number of writes rejected because the store's disk is full",
+		Measurement: "Writes",
+		Unit:        metric.Unit_COUNT,
+	}
 
 	// AddSSTable metrics.
 	metaAddSSTableProposals = metric.Metadata{
@@ -904,6 +1030,28 @@ var (
 		Measurement: "Ingestions",
 		Unit:        metric.Unit_COUNT,
 	}
+
+	// Replay detection metrics.
+	metaReplaysDetected = metric.Metadata{
+		Name:        "requests.replays.detected",
+		Help:        "Number of retried write batches answered from the replay cache instead of being reapplied",
+		Measurement: "Requests",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaReplaysUndetermined = metric.Metadata{
+		Name:        "requests.replays.undetermined",
+		Help:        "Number of retried write batches for which it could not be determined whether they had already been applied",
+		Measurement: "Requests",
+		Unit:        metric.Unit_COUNT,
+	}
+
+	// Evaluation memory metrics.
+	metaEvalMemoryCurBytes = metric.Metadata{
+		Name:        "eval.memory.current",
+		Help:        "Bytes currently registered by commands being evaluated with the store's evaluation memory budget",
+		Measurement: "Memory",
+		Unit:        metric.Unit_BYTES,
+	}
 )
 
 // StoreMetrics is the set of metrics for a given store.
@@ -917,6 +1065,7 @@ type StoreMetrics struct {
 	RaftLeaderNotLeaseHolderCount *metric.Gauge
 	LeaseHolderCount              *metric.Gauge
 	QuiescentCount                *metric.Gauge
+	DormantCount                  *metric.Gauge
 
 	// Replica CommandQueue metrics.
 	MaxCommandQueueSize       *metric.Gauge
@@ -959,12 +1108,16 @@ type StoreMetrics struct {
 	Capacity        *metric.Gauge
 	Available       *metric.Gauge
 	Used            *metric.Gauge
+	DiskFull        *metric.Gauge
 	Reserved        *metric.Gauge
 	SysBytes        *metric.Gauge
 	SysCount        *metric.Gauge
 
 	// Rebalancing metrics.
-	AverageWritesPerSecond *metric.GaugeFloat64
+	AverageQueriesPerSecond    *metric.GaugeFloat64
+	AverageWritesPerSecond     *metric.GaugeFloat64
+	RebalancingLeaseTransfers  *metric.Counter
+	RebalancingRangeRebalances *metric.Counter
 
 	// RocksDB metrics.
 	RdbBlockCacheHits           *metric.Gauge
@@ -979,6 +1132,7 @@ type StoreMetrics struct {
 	RdbTableReadersMemEstimate  *metric.Gauge
 	RdbReadAmplification        *metric.Gauge
 	RdbNumSSTables              *metric.Gauge
+	RdbWALSyncLatency           *metric.Histogram
 
 	// TODO(mrtracy): This should be removed as part of #4465. This is only
 	// maintained to keep the current structure of NodeStatus; it would be
@@ -992,15 +1146,22 @@ type StoreMetrics struct {
 	RangeSnapshotsGenerated         *metric.Counter
 	RangeSnapshotsNormalApplied     *metric.Counter
 	RangeSnapshotsPreemptiveApplied *metric.Counter
+	RangeSnapshotsIngested          *metric.Counter
 	RangeRaftLeaderTransfers        *metric.Counter
 
+	// Snapshot compression metrics.
+	RangeSnapshotRcvdBytesCompressed   *metric.Counter
+	RangeSnapshotRcvdBytesUncompressed *metric.Counter
+
 	// Raft processing metrics.
-	RaftTicks                *metric.Counter
-	RaftWorkingDurationNanos *metric.Counter
-	RaftTickingDurationNanos *metric.Counter
-	RaftCommandsApplied      *metric.Counter
-	RaftLogCommitLatency     *metric.Histogram
-	RaftCommandCommitLatency *metric.Histogram
+	RaftTicks                  *metric.Counter
+	RaftWorkingDurationNanos   *metric.Counter
+	RaftTickingDurationNanos   *metric.Counter
+	RaftCommandsApplied        *metric.Counter
+	RaftLogCommitLatency       *metric.Histogram
+	RaftCommandCommitLatency   *metric.Histogram
+	RaftSchedulerLatencyNormal *metric.Histogram
+	RaftSchedulerLatencyHigh   *metric.Histogram
 
 	// Raft message metrics.
 	RaftRcvdMsgProp           *metric.Counter
@@ -1017,9 +1178,15 @@ type StoreMetrics struct {
 	RaftRcvdMsgTimeoutNow     *metric.Counter
 	RaftRcvdMsgDropped        *metric.Counter
 
+	// Raft entry compression metrics.
+	RaftRcvdEntryBytesCompressed   *metric.Counter
+	RaftRcvdEntryBytesUncompressed *metric.Counter
+
 	// Raft log metrics.
 	RaftLogFollowerBehindCount *metric.Gauge
 	RaftLogTruncated           *metric.Counter
+	RaftLogTotalBytes          *metric.Gauge
+	RaftLogOverBudgetCount     *metric.Gauge
 
 	// A map for conveniently finding the appropriate metric. The individual
 	// metric references must exist as AddMetricStruct adds them by reflection
@@ -1080,9 +1247,14 @@ type StoreMetrics struct {
 	GCPushTxn                    *metric.Counter
 	GCResolveTotal               *metric.Counter
 	GCResolveSuccess             *metric.Counter
+	GCClearRangeSuccess          *metric.Counter
+	GCClearRangeFailed           *metric.Counter
+	GCThrottledNanos             *metric.Counter
+	GCBytesAwaiting              *metric.Gauge
 
 	// Intent resolver metrics.
 	IntentResolverAsyncThrottled *metric.Counter
+	TxnRecoveries                *metric.Counter
 
 	// Slow request counts.
 	SlowCommandQueueRequests *metric.Gauge
@@ -1091,6 +1263,7 @@ type StoreMetrics struct {
 
 	// Backpressure counts.
 	BackpressuredOnSplitRequests *metric.Gauge
+	DiskFullRejectedRequests     *metric.Counter
 
 	// AddSSTable stats: how many AddSSTable commands were proposed and how many
 	// were applied? How many applications required writing a copy?
@@ -1098,6 +1271,14 @@ type StoreMetrics struct {
 	AddSSTableApplications      *metric.Counter
 	AddSSTableApplicationCopies *metric.Counter
 
+	// Replay detection stats: how many retried write batches were answered
+	// from the replay cache, and how many still resulted in an ambiguous error?
+	ReplaysDetected     *metric.Counter
+	ReplaysUndetermined *metric.Counter
+
+	// Memory used by command evaluation.
+	EvalMemoryCurBytes *metric.Gauge
+
 	// Stats for efficient merges.
 	mu struct {
 		syncutil.Mutex
@@ -1117,6 +1298,7 @@ func newStoreMetrics(histogramWindow time.Duration) *StoreMetrics {
 		RaftLeaderNotLeaseHolderCount: metric.NewGauge(metaRaftLeaderNotLeaseHolderCount),
 		LeaseHolderCount:              metric.NewGauge(metaLeaseHolderCount),
 		QuiescentCount:                metric.NewGauge(metaQuiescentCount),
+		DormantCount:                  metric.NewGauge(metaDormantCount),
 
 		// Replica CommandQueue metrics.
 		MaxCommandQueueSize:       metric.NewGauge(metaMaxCommandQueueSize),
@@ -1157,12 +1339,16 @@ func newStoreMetrics(histogramWindow time.Duration) *StoreMetrics {
 		Capacity:        metric.NewGauge(metaCapacity),
 		Available:       metric.NewGauge(metaAvailable),
 		Used:            metric.NewGauge(metaUsed),
+		DiskFull:        metric.NewGauge(metaDiskFull),
 		Reserved:        metric.NewGauge(metaReserved),
 		SysBytes:        metric.NewGauge(metaSysBytes),
 		SysCount:        metric.NewGauge(metaSysCount),
 
 		// Rebalancing metrics.
-		AverageWritesPerSecond: metric.NewGaugeFloat64(metaAverageWritesPerSecond),
+		AverageQueriesPerSecond:    metric.NewGaugeFloat64(metaAverageQueriesPerSecond),
+		AverageWritesPerSecond:     metric.NewGaugeFloat64(metaAverageWritesPerSecond),
+		RebalancingLeaseTransfers:  metric.NewCounter(metaRebalancingLeaseTransfers),
+		RebalancingRangeRebalances: metric.NewCounter(metaRebalancingRangeRebalances),
 
 		// RocksDB metrics.
 		RdbBlockCacheHits:           metric.NewGauge(metaRdbBlockCacheHits),
@@ -1177,6 +1363,7 @@ func newStoreMetrics(histogramWindow time.Duration) *StoreMetrics {
 		RdbTableReadersMemEstimate:  metric.NewGauge(metaRdbTableReadersMemEstimate),
 		RdbReadAmplification:        metric.NewGauge(metaRdbReadAmplification),
 		RdbNumSSTables:              metric.NewGauge(metaRdbNumSSTables),
+		RdbWALSyncLatency:           metric.NewLatency(metaRdbWALSyncLatency, histogramWindow),
 
 		// Range event metrics.
 		RangeSplits:                     metric.NewCounter(metaRangeSplits),
@@ -1185,15 +1372,22 @@ func newStoreMetrics(histogramWindow time.Duration) *StoreMetrics {
 		RangeSnapshotsGenerated:         metric.NewCounter(metaRangeSnapshotsGenerated),
 		RangeSnapshotsNormalApplied:     metric.NewCounter(metaRangeSnapshotsNormalApplied),
 		RangeSnapshotsPreemptiveApplied: metric.NewCounter(metaRangeSnapshotsPreemptiveApplied),
+		RangeSnapshotsIngested:          metric.NewCounter(metaRangeSnapshotsIngested),
 		RangeRaftLeaderTransfers:        metric.NewCounter(metaRangeRaftLeaderTransfers),
 
+		// Snapshot compression metrics.
+		RangeSnapshotRcvdBytesCompressed:   metric.NewCounter(metaRangeSnapshotRcvdBytesCompressed),
+		RangeSnapshotRcvdBytesUncompressed: metric.NewCounter(metaRangeSnapshotRcvdBytesUncompressed),
+
 		// Raft processing metrics.
-		RaftTicks:                metric.NewCounter(metaRaftTicks),
-		RaftWorkingDurationNanos: metric.NewCounter(metaRaftWorkingDurationNanos),
-		RaftTickingDurationNanos: metric.NewCounter(metaRaftTickingDurationNanos),
-		RaftCommandsApplied:      metric.NewCounter(metaRaftCommandsApplied),
-		RaftLogCommitLatency:     metric.NewLatency(metaRaftLogCommitLatency, histogramWindow),
-		RaftCommandCommitLatency: metric.NewLatency(metaRaftCommandCommitLatency, histogramWindow),
+		RaftTicks:                  metric.NewCounter(metaRaftTicks),
+		RaftWorkingDurationNanos:   metric.NewCounter(metaRaftWorkingDurationNanos),
+		RaftTickingDurationNanos:   metric.NewCounter(metaRaftTickingDurationNanos),
+		RaftCommandsApplied:        metric.NewCounter(metaRaftCommandsApplied),
+		RaftLogCommitLatency:       metric.NewLatency(metaRaftLogCommitLatency, histogramWindow),
+		RaftCommandCommitLatency:   metric.NewLatency(metaRaftCommandCommitLatency, histogramWindow),
+		RaftSchedulerLatencyNormal: metric.NewLatency(metaRaftSchedulerLatencyNormal, histogramWindow),
+		RaftSchedulerLatencyHigh:   metric.NewLatency(metaRaftSchedulerLatencyHigh, histogramWindow),
 
 		// Raft message metrics.
 		RaftRcvdMsgProp:           metric.NewCounter(metaRaftRcvdProp),
@@ -1211,6 +1405,10 @@ func newStoreMetrics(histogramWindow time.Duration) *StoreMetrics {
 		RaftRcvdMsgDropped:        metric.NewCounter(metaRaftRcvdDropped),
 		raftRcvdMessages:          make(map[raftpb.MessageType]*metric.Counter, len(raftpb.MessageType_name)),
 
+		// Raft entry compression metrics.
+		RaftRcvdEntryBytesCompressed:   metric.NewCounter(metaRaftRcvdEntryBytesCompressed),
+		RaftRcvdEntryBytesUncompressed: metric.NewCounter(metaRaftRcvdEntryBytesUncompressed),
+
 		RaftEnqueuedPending: metric.NewGauge(metaRaftEnqueuedPending),
 
 		// This Gauge measures the number of heartbeats queued up just before
@@ -1220,6 +1418,8 @@ func newStoreMetrics(histogramWindow time.Duration) *StoreMetrics {
 		// Raft log metrics.
 		RaftLogFollowerBehindCount: metric.NewGauge(metaRaftLogFollowerBehindCount),
 		RaftLogTruncated:           metric.NewCounter(metaRaftLogTruncated),
+		RaftLogTotalBytes:          metric.NewGauge(metaRaftLogTotalBytes),
+		RaftLogOverBudgetCount:     metric.NewGauge(metaRaftLogOverBudgetCount),
 
 		// Replica queue metrics.
 		GCQueueSuccesses:                          metric.NewCounter(metaGCQueueSuccesses),
@@ -1271,9 +1471,14 @@ func newStoreMetrics(histogramWindow time.Duration) *StoreMetrics {
 		GCPushTxn:                    metric.NewCounter(metaGCPushTxn),
 		GCResolveTotal:               metric.NewCounter(metaGCResolveTotal),
 		GCResolveSuccess:             metric.NewCounter(metaGCResolveSuccess),
+		GCClearRangeSuccess:          metric.NewCounter(metaGCClearRangeSuccess),
+		GCClearRangeFailed:           metric.NewCounter(metaGCClearRangeFailed),
+		GCThrottledNanos:             metric.NewCounter(metaGCThrottledNanos),
+		GCBytesAwaiting:              metric.NewGauge(metaGCBytesAwaiting),
 
 		// Intent resolver metrics.
 		IntentResolverAsyncThrottled: metric.NewCounter(metaIntentResolverAsyncThrottled),
+		TxnRecoveries:                metric.NewCounter(metaIntentResolverTxnRecoveries),
 
 		// Wedge request counters.
 		SlowCommandQueueRequests: metric.NewGauge(metaSlowCommandQueueRequests),
@@ -1282,11 +1487,19 @@ func newStoreMetrics(histogramWindow time.Duration) *StoreMetrics {
 
 		// Backpressure counters.
 		BackpressuredOnSplitRequests: metric.NewGauge(metaBackpressuredOnSplitRequests),
+		DiskFullRejectedRequests:     metric.NewCounter(metaDiskFullRejectedRequests),
 
 		// AddSSTable proposal + applications counters.
 		AddSSTableProposals:         metric.NewCounter(metaAddSSTableProposals),
 		AddSSTableApplications:      metric.NewCounter(metaAddSSTableApplications),
 		AddSSTableApplicationCopies: metric.NewCounter(metaAddSSTableApplicationCopies),
+
+		// Replay detection counters.
+		ReplaysDetected:     metric.NewCounter(metaReplaysDetected),
+		ReplaysUndetermined: metric.NewCounter(metaReplaysUndetermined),
+
+		// Evaluation memory metrics.
+		EvalMemoryCurBytes: metric.NewGauge(metaEvalMemoryCurBytes),
 	}
 
 	sm.raftRcvdMessages[raftpb.MsgProp] = sm.RaftRcvdMsgProp
@@ -17,12 +17,80 @@ package storage
 import (
 	"context"
 
+	"github.com/pkg/errors"
+
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 )
 
+// StoreRewrite is a rewrite of the data of a store into a newer format that
+// can be run offline with `cockroach debug rewrite-store`, so that it doesn't
+// have to be run when the node starts. Once a rewrite has been run and
+// verified, it's marked as completed on the store and it's skipped from then
+// on, both by `rewrite-store` and by the node startup.
+type StoreRewrite struct {
+	// Name identifies the rewrite. It must never change, as it's stored in
+	// the completion marker.
+	Name string
+	// Description is shown to the operator.
+	Description string
+	// rewrite performs the rewrite. It must commit its progress regularly and
+	// be idempotent, so that an interrupted rewrite resumes where it stopped
+	// when it's run again.
+	rewrite func(context.Context, engine.Engine) error
+	// verify returns an error if some of the data is still in the old format.
+	verify func(context.Context, engine.Reader) error
+}
+
+// StoreRewrites is the list of the offline store rewrites, in the order in
+// which they're run.
+var StoreRewrites = []StoreRewrite{
+	legacyTombstonesRewrite,
+}
+
+// legacyTombstonesRewrite is also run when the node starts, unless it's been
+// completed.
+var legacyTombstonesRewrite = StoreRewrite{
+	Name:        "legacy-tombstones",
+	Description: "rewrite the replica tombstones stored at their legacy replicated key (#12154)",
+	rewrite:     migrateLegacyTombstones,
+	verify:      verifyNoLegacyTombstones,
+}
+
+// IsStoreRewriteDone returns whether the rewrite has been completed on the
+// store.
+func IsStoreRewriteDone(ctx context.Context, eng engine.Reader, rw StoreRewrite) (bool, error) {
+	var doneAt hlc.Timestamp
+	return engine.MVCCGetProto(ctx, eng, keys.StoreRewriteKey(rw.Name), hlc.Timestamp{},
+		true /* consistent */, nil /* txn */, &doneAt)
+}
+
+// RunStoreRewrite runs the rewrite on the store unless it's already been
+// completed, verifies its result and marks it as completed. It returns
+// whether the rewrite had already been completed.
+func RunStoreRewrite(ctx context.Context, eng engine.Engine, rw StoreRewrite) (bool, error) {
+	if done, err := IsStoreRewriteDone(ctx, eng, rw); err != nil || done {
+		return done, err
+	}
+	if err := rw.rewrite(ctx, eng); err != nil {
+		return false, errors.Wrapf(err, "rewrite %s", rw.Name)
+	}
+	if err := rw.verify(ctx, eng); err != nil {
+		return false, errors.Wrapf(err, "verifying rewrite %s", rw.Name)
+	}
+	batch := eng.NewBatch()
+	defer batch.Close()
+	doneAt := hlc.Timestamp{WallTime: timeutil.Now().UnixNano()}
+	if err := engine.MVCCPutProto(ctx, batch, nil /* ms */, keys.StoreRewriteKey(rw.Name),
+		hlc.Timestamp{}, nil /* txn */, &doneAt); err != nil {
+		return false, err
+	}
+	return false, batch.Commit(true /* sync */)
+}
+
 // clearLegacyTombstone removes the legacy tombstone for the given rangeID.
 func clearLegacyTombstone(eng engine.Writer, rangeID roachpb.RangeID) error {
 	return eng.Clear(engine.MakeMVCCMetadataKey(keys.RaftTombstoneIncorrectLegacyKey(rangeID)))
@@ -80,3 +148,13 @@ func migrateLegacyTombstones(ctx context.Context, eng engine.Engine) error {
 	}
 	return batch.Commit(true /* sync */)
 }
+
+// verifyNoLegacyTombstones returns an error if there's a legacy tombstone
+// left.
+func verifyNoLegacyTombstones(ctx context.Context, eng engine.Reader) error {
+	var tombstone roachpb.RaftTombstone
+	return IterateIDPrefixKeys(ctx, eng, keys.RaftTombstoneIncorrectLegacyKey, &tombstone,
+		func(rangeID roachpb.RangeID) (bool, error) {
+			return false, errors.Errorf("r%d has a legacy tombstone", rangeID)
+		})
+}
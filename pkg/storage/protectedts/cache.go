@@ -0,0 +1,121 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package protectedts
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// PollInterval is the interval at which each node reads the protected
+// timestamp records. The GC queue lags behind the GC TTL by up to this much.
+var PollInterval = settings.RegisterNonNegativeDurationSetting(
+	"kv.protectedts.poll_interval",
+	"the interval at which the protected timestamp records are read by each node",
+	2*time.Minute,
+)
+
+// Cache is an in-memory copy of the protected timestamp records, refreshed
+// periodically.
+type Cache struct {
+	db       *client.DB
+	settings *cluster.Settings
+
+	mu struct {
+		syncutil.RWMutex
+		records []Record
+		// readAt is the timestamp at which the records were read, or zero if
+		// they haven't been read yet.
+		readAt hlc.Timestamp
+	}
+}
+
+// NewCache makes a Cache. It's empty until it's refreshed.
+func NewCache(db *client.DB, settings *cluster.Settings) *Cache {
+	return &Cache{db: db, settings: settings}
+}
+
+// Start refreshes the cache every kv.protectedts.poll_interval until the
+// stopper quiesces.
+func (c *Cache) Start(ctx context.Context, stopper *stop.Stopper) {
+	stopper.RunWorker(ctx, func(ctx context.Context) {
+		var timer timeutil.Timer
+		defer timer.Stop()
+		for {
+			if err := c.Refresh(ctx); err != nil {
+				log.Warningf(ctx, "failed to read the protected timestamp records: %v", err)
+			}
+			timer.Reset(PollInterval.Get(&c.settings.SV))
+			select {
+			case <-stopper.ShouldQuiesce():
+				return
+			case <-timer.C:
+				timer.Read = true
+			}
+		}
+	})
+}
+
+// Refresh reads the records.
+func (c *Cache) Refresh(ctx context.Context) error {
+	var records []Record
+	var readAt hlc.Timestamp
+	if err := c.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		var err error
+		records, err = List(ctx, txn)
+		readAt = txn.OrigTimestamp()
+		return err
+	}); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mu.records, c.mu.readAt = records, readAt
+	return nil
+}
+
+// Protected returns the earliest timestamp protected by a record that
+// overlaps the given span, or the zero timestamp if there's none. It also
+// returns the timestamp at which the records were read, which is zero if
+// they haven't been read yet, in which case nothing can be assumed to be
+// unprotected.
+func (c *Cache) Protected(span roachpb.Span) (protected, readAt hlc.Timestamp) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for i := range c.mu.records {
+		r := &c.mu.records[i]
+		if protected != (hlc.Timestamp{}) && !r.Timestamp.Less(protected) {
+			continue
+		}
+		for _, sp := range r.Spans {
+			if sp.Overlaps(span) {
+				protected = r.Timestamp
+				break
+			}
+		}
+	}
+	return protected, c.mu.readAt
+}
@@ -0,0 +1,106 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package protectedts implements protected timestamp records, which keep the
+// garbage collector from removing the history of some spans that's still
+// needed by a long-running reader, like a backup or a changefeed, even once
+// it's older than the spans' GC TTL.
+//
+// The records are stored in the system keyspace, under
+// keys.ProtectedTimestampPrefix. Every node polls them into a Cache, which is
+// consulted by the GC queue, to hold back the GC threshold of the ranges, and
+// by the evaluation of GC requests, to reject thresholds that would remove
+// protected data.
+//
+// Records are only read periodically, so the GC queue never advances the GC
+// threshold past the time the records were last read minus the GC TTL. A new
+// record is therefore respected as soon as it's written, as long as its
+// timestamp is within the GC TTL of the time it's written.
+package protectedts
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/pkg/errors"
+)
+
+// ErrNotExists is returned when a record doesn't exist.
+var ErrNotExists = errors.New("protected timestamp record does not exist")
+
+// Protect writes a new record in the given transaction. The record is
+// assigned a random ID if it doesn't have one. It's an error to protect an
+// empty set of spans or the zero timestamp, or to reuse the ID of an existing
+// record.
+func Protect(ctx context.Context, txn *client.Txn, r *Record) error {
+	if r.Timestamp == (hlc.Timestamp{}) {
+		return errors.New("cannot protect the zero timestamp")
+	}
+	if len(r.Spans) == 0 {
+		return errors.New("cannot protect an empty set of spans")
+	}
+	if r.ID == (uuid.UUID{}) {
+		r.ID = uuid.MakeV4()
+	}
+	if err := txn.CPut(ctx, keys.ProtectedTimestampKey(r.ID), r, nil /* expValue */); err != nil {
+		if _, ok := err.(*roachpb.ConditionFailedError); ok {
+			return errors.Errorf("protected timestamp record %s already exists", r.ID)
+		}
+		return err
+	}
+	return nil
+}
+
+// GetRecord reads the record with the given ID, or returns ErrNotExists.
+func GetRecord(ctx context.Context, txn *client.Txn, id uuid.UUID) (*Record, error) {
+	kv, err := txn.Get(ctx, keys.ProtectedTimestampKey(id))
+	if err != nil {
+		return nil, err
+	}
+	if kv.Value == nil {
+		return nil, ErrNotExists
+	}
+	var r Record
+	if err := kv.ValueProto(&r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Release removes the record with the given ID, or returns ErrNotExists.
+func Release(ctx context.Context, txn *client.Txn, id uuid.UUID) error {
+	if _, err := GetRecord(ctx, txn, id); err != nil {
+		return err
+	}
+	return txn.Del(ctx, keys.ProtectedTimestampKey(id))
+}
+
+// List reads all the records.
+func List(ctx context.Context, txn *client.Txn) ([]Record, error) {
+	kvs, err := txn.Scan(ctx, keys.ProtectedTimestampPrefix, keys.ProtectedTimestampKeyMax, 0 /* maxRows */)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]Record, len(kvs))
+	for i := range kvs {
+		if err := kvs[i].ValueProto(&records[i]); err != nil {
+			return nil, errors.Wrapf(err, "decoding protected timestamp record at %s", kvs[i].Key)
+		}
+	}
+	return records, nil
+}
@@ -0,0 +1,141 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package protectedts_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+func span(start, end string) roachpb.Span {
+	return roachpb.Span{Key: roachpb.Key(start), EndKey: roachpb.Key(end)}
+}
+
+func TestProtectedTimestamps(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	s, _, db := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+
+	protect := func(r *protectedts.Record) error {
+		return db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			return protectedts.Protect(ctx, txn, r)
+		})
+	}
+	release := func(id uuid.UUID) error {
+		return db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			return protectedts.Release(ctx, txn, id)
+		})
+	}
+
+	ts1, ts2 := hlc.Timestamp{WallTime: 1}, hlc.Timestamp{WallTime: 2}
+	r1 := protectedts.Record{
+		Timestamp:   ts2,
+		Description: "r1",
+		Spans:       []roachpb.Span{span("a", "c"), span("x", "z")},
+	}
+	r2 := protectedts.Record{
+		Timestamp:   ts1,
+		Description: "r2",
+		Spans:       []roachpb.Span{span("b", "d")},
+	}
+	for _, r := range []*protectedts.Record{&r1, &r2} {
+		if err := protect(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := protect(&r1); !testutils.IsError(err, "already exists") {
+		t.Fatalf("expected an already exists error, got %v", err)
+	}
+	if err := protect(&protectedts.Record{Spans: r1.Spans}); !testutils.IsError(err, "zero timestamp") {
+		t.Fatalf("expected a zero timestamp error, got %v", err)
+	}
+	if err := protect(&protectedts.Record{Timestamp: ts1}); !testutils.IsError(err, "empty set of spans") {
+		t.Fatalf("expected an empty set of spans error, got %v", err)
+	}
+
+	if err := db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		r, err := protectedts.GetRecord(ctx, txn, r1.ID)
+		if err != nil {
+			return err
+		}
+		if !r.Timestamp.Equal(ts2) || r.Description != "r1" || len(r.Spans) != 2 {
+			t.Errorf("unexpected record %+v", r)
+		}
+		records, err := protectedts.List(ctx, txn)
+		if err != nil {
+			return err
+		}
+		if len(records) != 2 {
+			t.Errorf("expected 2 records, got %+v", records)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	cache := protectedts.NewCache(db, s.ClusterSettings())
+	if _, readAt := cache.Protected(span("a", "b")); readAt != (hlc.Timestamp{}) {
+		t.Fatalf("expected the records not to be read yet, got %s", readAt)
+	}
+	if err := cache.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		span     roachpb.Span
+		expected hlc.Timestamp
+	}{
+		{span("a", "b"), ts2},
+		{span("a", "bb"), ts1},
+		{span("c", "e"), ts1},
+		{span("d", "x"), hlc.Timestamp{}},
+		{span("y", "yy"), ts2},
+	} {
+		protected, readAt := cache.Protected(tc.span)
+		if protected != tc.expected {
+			t.Errorf("%s: expected %s, got %s", tc.span, tc.expected, protected)
+		}
+		if readAt == (hlc.Timestamp{}) {
+			t.Errorf("%s: expected the records to be read", tc.span)
+		}
+	}
+
+	if err := release(r2.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := release(r2.ID); err != protectedts.ErrNotExists {
+		t.Fatalf("expected %v, got %v", protectedts.ErrNotExists, err)
+	}
+	if err := cache.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if protected, _ := cache.Protected(span("c", "e")); protected != (hlc.Timestamp{}) {
+		t.Fatalf("expected no protected timestamp after the release, got %s", protected)
+	}
+	if protected, _ := cache.Protected(span("a", "bb")); protected != ts2 {
+		t.Fatalf("expected %s, got %s", ts2, protected)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/golang/snappy"
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+)
+
+// raftCompression is the algorithm used to compress Raft log entries and
+// snapshot data sent to other nodes.
+var raftCompression = settings.RegisterEnumSetting(
+	"kv.raft.compression",
+	"algorithm used to compress large Raft log entries and snapshot data sent between nodes",
+	"snappy",
+	map[int64]string{
+		int64(CompressionType_NONE):   "none",
+		int64(CompressionType_SNAPPY): "snappy",
+	},
+)
+
+// raftCompressionMinEntryBytes is the total size of the entry payloads in a
+// Raft message below which they are sent uncompressed. Compressing the small
+// entries which make up the bulk of Raft traffic costs CPU on both ends for
+// little gain.
+var raftCompressionMinEntryBytes = settings.RegisterByteSizeSetting(
+	"kv.raft.compression.min_entry_bytes",
+	"minimum total size of the entries in a Raft message for them to be compressed",
+	16<<10,
+)
+
+// wireCompression returns the compression to apply to Raft entries and
+// snapshot data sent to other nodes, which is NONE until every node in the
+// cluster is able to decompress it.
+func wireCompression(st *cluster.Settings) CompressionType {
+	if !st.Version.IsActive(cluster.VersionRaftCompression) {
+		return CompressionType_NONE
+	}
+	return CompressionType(raftCompression.Get(&st.SV))
+}
+
+// compress compresses data using the given algorithm.
+func compress(ct CompressionType, data []byte) ([]byte, error) {
+	switch ct {
+	case CompressionType_NONE:
+		return data, nil
+	case CompressionType_SNAPPY:
+		return snappy.Encode(nil, data), nil
+	default:
+		return nil, errors.Errorf("unknown compression type %s", ct)
+	}
+}
+
+// decompress decompresses data which was compressed using the given
+// algorithm.
+func decompress(ct CompressionType, data []byte) ([]byte, error) {
+	switch ct {
+	case CompressionType_NONE:
+		return data, nil
+	case CompressionType_SNAPPY:
+		return snappy.Decode(nil, data)
+	default:
+		return nil, errors.Errorf("unknown compression type %s", ct)
+	}
+}
+
+// maybeCompressRaftEntries compresses the data of the entries in the
+// request's message if compression is enabled and the entries are large
+// enough to make it worthwhile. The entries are copied rather than modified
+// in place, since the message's entries are shared with the Raft log.
+func maybeCompressRaftEntries(st *cluster.Settings, req *RaftMessageRequest) error {
+	ct := wireCompression(st)
+	if ct == CompressionType_NONE {
+		return nil
+	}
+	var size int64
+	for i := range req.Message.Entries {
+		size += int64(len(req.Message.Entries[i].Data))
+	}
+	if size < raftCompressionMinEntryBytes.Get(&st.SV) {
+		return nil
+	}
+
+	entries := make([]raftpb.Entry, len(req.Message.Entries))
+	for i, ent := range req.Message.Entries {
+		if len(ent.Data) > 0 {
+			var err error
+			if ent.Data, err = compress(ct, ent.Data); err != nil {
+				return err
+			}
+		}
+		entries[i] = ent
+	}
+	req.Message.Entries = entries
+	req.EntryCompression = ct
+	return nil
+}
+
+// decompressRaftEntries reverses maybeCompressRaftEntries, returning the total
+// size of the entries' data before and after decompression.
+func decompressRaftEntries(req *RaftMessageRequest) (compressed, uncompressed int64, _ error) {
+	if req.EntryCompression == CompressionType_NONE {
+		return 0, 0, nil
+	}
+	for i := range req.Message.Entries {
+		ent := &req.Message.Entries[i]
+		if len(ent.Data) == 0 {
+			continue
+		}
+		compressed += int64(len(ent.Data))
+		data, err := decompress(req.EntryCompression, ent.Data)
+		if err != nil {
+			return 0, 0, errors.Wrapf(err, "decompressing entry %d", ent.Index)
+		}
+		ent.Data = data
+		uncompressed += int64(len(ent.Data))
+	}
+	req.EntryCompression = CompressionType_NONE
+	return compressed, uncompressed, nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/coreos/etcd/raft/raftpb"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestRaftEntryCompression(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	makeReq := func(sizes ...int) *RaftMessageRequest {
+		req := &RaftMessageRequest{Message: raftpb.Message{Type: raftpb.MsgApp}}
+		for i, size := range sizes {
+			req.Message.Entries = append(req.Message.Entries, raftpb.Entry{
+				Index: uint64(i + 1),
+				Data:  bytes.Repeat([]byte{byte('a' + i)}, size),
+			})
+		}
+		return req
+	}
+
+	st := cluster.MakeTestingClusterSettings()
+	raftCompressionMinEntryBytes.Override(&st.SV, 1<<10)
+
+	testCases := []struct {
+		name        string
+		sizes       []int
+		compression CompressionType
+		expected    CompressionType
+	}{
+		{"small", []int{100, 200}, CompressionType_SNAPPY, CompressionType_NONE},
+		{"large", []int{1 << 10, 100}, CompressionType_SNAPPY, CompressionType_SNAPPY},
+		{"empty entry", []int{0, 2 << 10}, CompressionType_SNAPPY, CompressionType_SNAPPY},
+		{"disabled", []int{2 << 10}, CompressionType_NONE, CompressionType_NONE},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			raftCompression.Override(&st.SV, int64(tc.compression))
+
+			req := makeReq(tc.sizes...)
+			orig := req.Message.Entries
+			exp := makeReq(tc.sizes...).Message.Entries
+			if err := maybeCompressRaftEntries(st, req); err != nil {
+				t.Fatal(err)
+			}
+			if req.EntryCompression != tc.expected {
+				t.Fatalf("expected compression %s, got %s", tc.expected, req.EntryCompression)
+			}
+			// The original entries, which are shared with the Raft log, must
+			// not have been modified.
+			if !reflect.DeepEqual(orig, exp) {
+				t.Fatal("compression modified the original entries")
+			}
+			if tc.expected != CompressionType_NONE {
+				var compressedSize int
+				for _, ent := range req.Message.Entries {
+					compressedSize += len(ent.Data)
+				}
+				if compressedSize >= len(exp[0].Data)+len(exp[1].Data) {
+					t.Errorf("expected entries to shrink, got %d bytes", compressedSize)
+				}
+			}
+
+			compressed, uncompressed, err := decompressRaftEntries(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if req.EntryCompression != CompressionType_NONE {
+				t.Errorf("expected entries to be marked as decompressed")
+			}
+			if tc.expected != CompressionType_NONE {
+				if compressed == 0 || uncompressed <= compressed {
+					t.Errorf("unexpected sizes: compressed=%d uncompressed=%d", compressed, uncompressed)
+				}
+			}
+			for i := range exp {
+				if !bytes.Equal(req.Message.Entries[i].Data, exp[i].Data) {
+					t.Errorf("entry %d: data does not round trip", i)
+				}
+			}
+		})
+	}
+}
+
+func TestRaftEntryCompressionVersionGate(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	st := cluster.MakeTestingClusterSettingsWithVersion(
+		cluster.VersionByKey(cluster.VersionLoadBasedRebalancing),
+		cluster.VersionByKey(cluster.VersionLoadBasedRebalancing),
+	)
+	raftCompressionMinEntryBytes.Override(&st.SV, 0)
+	if ct := wireCompression(st); ct != CompressionType_NONE {
+		t.Fatalf("expected no compression before all nodes support it, got %s", ct)
+	}
+
+	req := &RaftMessageRequest{Message: raftpb.Message{
+		Entries: []raftpb.Entry{{Index: 1, Data: bytes.Repeat([]byte("a"), 4<<10)}},
+	}}
+	if err := maybeCompressRaftEntries(st, req); err != nil {
+		t.Fatal(err)
+	}
+	if req.EntryCompression != CompressionType_NONE {
+		t.Fatalf("expected entries to be sent uncompressed, got %s", req.EntryCompression)
+	}
+}
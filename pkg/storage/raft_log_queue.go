@@ -26,9 +26,11 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/gossip"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 )
 
 const (
@@ -52,11 +54,30 @@ const (
 // raftLogMaxSize limits the maximum size of the Raft log.
 var raftLogMaxSize = envutil.EnvOrDefaultInt64("COCKROACH_RAFT_LOG_MAX_SIZE", 4<<20 /* 4 MB */)
 
+// raftLogStoreMaxSize is the budget for the total size of the Raft logs of
+// the replicas of a store. When it's exceeded, the largest logs are truncated
+// up to the quorum commit index even if that means that behind followers will
+// need a snapshot.
+var raftLogStoreMaxSize = settings.RegisterByteSizeSetting(
+	"kv.raft_log.store_max_size",
+	"the total size of the Raft logs of a store above which the largest logs are truncated "+
+		"without waiting for behind followers (0 to disable)",
+	4<<30, /* 4 GiB */
+)
+
 // raftLogQueue manages a queue of replicas slated to have their raft logs
 // truncated by removing unneeded entries.
 type raftLogQueue struct {
 	*baseQueue
 	db *client.DB
+
+	mu struct {
+		syncutil.Mutex
+		// overBudget is the set of the ranges whose Raft log is truncated up to
+		// the quorum commit index to bring the store back under
+		// kv.raft_log.store_max_size. See updateStoreBudget.
+		overBudget map[roachpb.RangeID]struct{}
+	}
 }
 
 // newRaftLogQueue returns a new instance of raftLogQueue.
@@ -81,6 +102,70 @@ func newRaftLogQueue(store *Store, db *client.DB, gossip *gossip.Gossip) *raftLo
 	return rlq
 }
 
+// updateStoreBudget decides, for all the replicas of the store at once,
+// which Raft logs have to be truncated to bring the total size of the logs of
+// the store under kv.raft_log.store_max_size: the largest logs of the ranges
+// led by the store are picked until the excess is covered, and queued. Their
+// truncation still waits for a quorum of the replicas and for the pending
+// snapshots, but not for the behind followers. The decisions hold until the
+// next call, which is made whenever the store metrics are computed.
+func (rlq *raftLogQueue) updateStoreBudget(ctx context.Context) {
+	type raftLog struct {
+		repl *Replica
+		size int64
+	}
+	var logs []raftLog
+	var total int64
+	newStoreReplicaVisitor(rlq.store).Visit(func(r *Replica) bool {
+		r.mu.RLock()
+		size := r.mu.raftLogSize
+		leader := r.mu.replicaID != 0 && r.mu.leaderID == r.mu.replicaID
+		r.mu.RUnlock()
+		total += size
+		// Only the leader can truncate the log of a range.
+		if leader && size > 0 {
+			logs = append(logs, raftLog{repl: r, size: size})
+		}
+		return true // more
+	})
+	rlq.store.metrics.RaftLogTotalBytes.Update(total)
+
+	overBudget := make(map[roachpb.RangeID]struct{})
+	var toQueue []raftLog
+	if budget := raftLogStoreMaxSize.Get(&rlq.store.cfg.Settings.SV); budget > 0 && total > budget {
+		sort.Slice(logs, func(i, j int) bool { return logs[i].size > logs[j].size })
+		for _, l := range logs {
+			if total <= budget {
+				break
+			}
+			overBudget[l.repl.RangeID] = struct{}{}
+			toQueue = append(toQueue, l)
+			total -= l.size
+		}
+		log.VEventf(ctx, 1, "Raft logs over budget by %d bytes, truncating %d of them",
+			total-budget, len(toQueue))
+	}
+	rlq.mu.Lock()
+	rlq.mu.overBudget = overBudget
+	rlq.mu.Unlock()
+	rlq.store.metrics.RaftLogOverBudgetCount.Update(int64(len(overBudget)))
+
+	for _, l := range toQueue {
+		if _, err := rlq.Add(l.repl, float64(l.size)); err != nil {
+			log.VEventf(ctx, 2, "unable to queue %s: %s", l.repl, err)
+		}
+	}
+}
+
+// isOverBudget returns whether the Raft log of the range has to be truncated
+// to bring the store under its Raft log budget.
+func (rlq *raftLogQueue) isOverBudget(rangeID roachpb.RangeID) bool {
+	rlq.mu.Lock()
+	defer rlq.mu.Unlock()
+	_, ok := rlq.mu.overBudget[rangeID]
+	return ok
+}
+
 func shouldTruncate(truncatableIndexes uint64, raftLogSize int64) bool {
 	return truncatableIndexes >= RaftLogQueueStaleThreshold ||
 		(truncatableIndexes > 0 && raftLogSize >= RaftLogQueueStaleSize)
@@ -105,6 +190,8 @@ func getTruncatableIndexes(ctx context.Context, r *Replica) (uint64, uint64, int
 		return 0, 0, 0, nil
 	}
 
+	overBudget := r.store.raftLogQueue != nil && r.store.raftLogQueue.isOverBudget(rangeID)
+
 	r.mu.Lock()
 	raftLogSize := r.mu.raftLogSize
 	// We target the raft log size at the size of the replicated data. When
@@ -121,6 +208,10 @@ func getTruncatableIndexes(ctx context.Context, r *Replica) (uint64, uint64, int
 	if targetSize > raftLogMaxSize {
 		targetSize = raftLogMaxSize
 	}
+	if overBudget {
+		// Truncate up to the quorum commit index. See updateStoreBudget.
+		targetSize = 0
+	}
 	firstIndex, err := r.raftFirstIndexLocked()
 	pendingSnapshotIndex := r.mu.pendingSnapshotIndex
 	lastIndex := r.mu.lastIndex
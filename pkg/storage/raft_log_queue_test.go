@@ -250,6 +250,50 @@ func TestGetTruncatableIndexes(t *testing.T) {
 	}
 }
 
+// TestRaftLogQueueStoreBudget verifies that the Raft logs are marked for
+// truncation when the store exceeds its Raft log budget.
+func TestRaftLogQueueStoreBudget(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	store, _ := createTestStore(t, stopper)
+	store.SetRaftLogQueueActive(false)
+
+	for i := 0; i < 10; i++ {
+		args := putArgs(roachpb.Key(fmt.Sprintf("key%02d", i)), []byte("value"))
+		if _, err := client.SendWrapped(context.Background(), store.TestSender(), &args); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rlq := store.raftLogQueue
+	rlq.updateStoreBudget(context.Background())
+	if rlq.isOverBudget(1) {
+		t.Fatal("expected r1 not to be over budget")
+	}
+	if total := store.metrics.RaftLogTotalBytes.Value(); total <= 0 {
+		t.Fatalf("expected a positive total Raft log size, got %d", total)
+	}
+
+	raftLogStoreMaxSize.Override(&store.cfg.Settings.SV, 1)
+	testutils.SucceedsSoon(t, func() error {
+		rlq.updateStoreBudget(context.Background())
+		if !rlq.isOverBudget(1) {
+			return errors.New("expected r1 to be over budget")
+		}
+		return nil
+	})
+	if n := store.metrics.RaftLogOverBudgetCount.Value(); n != 1 {
+		t.Fatalf("expected 1 Raft log over budget, got %d", n)
+	}
+
+	raftLogStoreMaxSize.Override(&store.cfg.Settings.SV, 0)
+	rlq.updateStoreBudget(context.Background())
+	if rlq.isOverBudget(1) {
+		t.Fatal("expected the budget to be disabled")
+	}
+}
+
 // TestProactiveRaftLogTruncate verifies that we proactively truncate the raft
 // log even when replica scanning is disabled.
 func TestProactiveRaftLogTruncate(t *testing.T) {
@@ -0,0 +1,76 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package rangefeed
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+// LogicalOp is a logical MVCC operation applied to a range's data. The
+// operations are derived from the write batches of the Raft commands applied
+// to the range and are fed to its Processor in the order in which they were
+// applied.
+type LogicalOp interface {
+	logicalOp()
+}
+
+// WriteValueOp is the addition of a committed value, as is performed by
+// non-transactional writes.
+type WriteValueOp struct {
+	Key       roachpb.Key
+	Timestamp hlc.Timestamp
+	// Value is the encoded roachpb.Value, as found in Value.RawBytes.
+	Value []byte
+}
+
+// WriteIntentOp is the addition of an intent by a transaction that did not
+// previously have an intent on the key.
+type WriteIntentOp struct {
+	TxnID     uuid.UUID
+	TxnKey    []byte
+	Timestamp hlc.Timestamp
+}
+
+// UpdateIntentOp is the movement of a transaction's intent to a new
+// timestamp, as happens when the transaction rewrites the key or when the
+// intent is resolved while the transaction is still pending. It is also used
+// to inform the Processor that a transaction was pushed to a new timestamp.
+type UpdateIntentOp struct {
+	TxnID     uuid.UUID
+	Timestamp hlc.Timestamp
+}
+
+// CommitIntentOp is the resolution of a committed transaction's intent,
+// turning its provisional value into a committed value.
+type CommitIntentOp struct {
+	TxnID     uuid.UUID
+	Key       roachpb.Key
+	Timestamp hlc.Timestamp
+	// Value is the encoded roachpb.Value, as found in Value.RawBytes.
+	Value []byte
+}
+
+// AbortIntentOp is the removal of an aborted transaction's intent.
+type AbortIntentOp struct {
+	TxnID uuid.UUID
+}
+
+func (*WriteValueOp) logicalOp()   {}
+func (*WriteIntentOp) logicalOp()  {}
+func (*UpdateIntentOp) logicalOp() {}
+func (*CommitIntentOp) logicalOp() {}
+func (*AbortIntentOp) logicalOp()  {}
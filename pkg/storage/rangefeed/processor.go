@@ -0,0 +1,565 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package rangefeed implements the server side of rangefeeds: long-lived
+// streams of the committed writes to a span of a range, interspersed with
+// checkpoints carrying the range's resolved timestamp.
+package rangefeed
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+)
+
+const (
+	// defaultPushTxnsInterval is the default interval at which a Processor
+	// will push all transactions in the unresolvedIntentQueue that are above
+	// the age specified by PushTxnsAge.
+	defaultPushTxnsInterval = 250 * time.Millisecond
+	// defaultPushTxnsAge is the default age at which a Processor will begin
+	// to consider a transaction old enough to push.
+	defaultPushTxnsAge = 10 * time.Second
+)
+
+// TxnPusher is capable of pushing transactions to a new timestamp and
+// cleaning up the intents of transactions that are found to be committed or
+// aborted.
+type TxnPusher interface {
+	// PushTxns attempts to push the specified transactions to a new
+	// timestamp. It returns the resulting transaction protos.
+	PushTxns(context.Context, []enginepb.TxnMeta, hlc.Timestamp) ([]roachpb.Transaction, error)
+	// CleanupTxnIntentsAsync asynchronously cleans up intents owned by the
+	// specified transactions, which must be committed or aborted.
+	CleanupTxnIntentsAsync(context.Context, []roachpb.Transaction) error
+}
+
+// Config encompasses the configuration required to create a Processor.
+type Config struct {
+	log.AmbientContext
+	Clock *hlc.Clock
+	Span  roachpb.RSpan
+
+	// TxnPusher, if set, is used to push the transactions whose intents hold
+	// back the resolved timestamp for longer than PushTxnsAge, every
+	// PushTxnsInterval.
+	TxnPusher        TxnPusher
+	PushTxnsInterval time.Duration
+	PushTxnsAge      time.Duration
+
+	// EventChanCap is the capacity of the channel used to pass logical
+	// operations and closed timestamps to the Processor. If the channel is
+	// full, the Processor is stopped with an error.
+	EventChanCap int
+}
+
+// SetDefaults initializes unset fields in Config to values suitable for use
+// by a Processor.
+func (sc *Config) SetDefaults() {
+	if sc.TxnPusher != nil {
+		if sc.PushTxnsInterval == 0 {
+			sc.PushTxnsInterval = defaultPushTxnsInterval
+		}
+		if sc.PushTxnsAge == 0 {
+			sc.PushTxnsAge = defaultPushTxnsAge
+		}
+	}
+}
+
+// Processor manages a set of rangefeed registrations and handles the routing
+// of logical updates to these registrations. While routing logical updates
+// it also computes the resolved timestamp of the range and periodically
+// publishes it to all registrations in checkpoint events.
+//
+// Processor is the central structure of a range's rangefeed machinery. It
+// runs a single goroutine, started with Start, which consumes the logical
+// operations applied to the range and the range's closed timestamps in the
+// order in which they were handed to it. All methods are safe to call from
+// any goroutine.
+type Processor struct {
+	Config
+	reg registry
+	rts resolvedTimestamp
+
+	regC     chan *registration
+	unregC   chan *registration
+	lenReqC  chan struct{}
+	lenResC  chan int
+	eventC   chan event
+	stopC    chan *roachpb.Error
+	stoppedC chan struct{}
+}
+
+// event is a union of different event types that the Processor goroutine
+// needs to be informed of. It is used so that all events can be sent over
+// the same channel, which is necessary to prevent reordering.
+type event struct {
+	ops     []LogicalOp
+	ct      hlc.Timestamp
+	initRTS bool
+	syncC   chan struct{}
+}
+
+// NewProcessor creates a new rangefeed Processor. The corresponding goroutine
+// should be launched using the Start method.
+func NewProcessor(cfg Config) *Processor {
+	cfg.SetDefaults()
+	cfg.AmbientContext.AddLogTag("rangefeed", nil)
+	return &Processor{
+		Config: cfg,
+		reg:    makeRegistry(),
+		rts:    makeResolvedTimestamp(),
+
+		regC:     make(chan *registration),
+		unregC:   make(chan *registration),
+		lenReqC:  make(chan struct{}),
+		lenResC:  make(chan int),
+		eventC:   make(chan event, cfg.EventChanCap),
+		stopC:    make(chan *roachpb.Error, 1),
+		stoppedC: make(chan struct{}),
+	}
+}
+
+// Start launches a goroutine to process rangefeed events and send them to
+// registrations.
+//
+// The provided iterator is used to initialize the rangefeed's resolved
+// timestamp. It must be able to observe all intents in the range at the time
+// the Processor starts. It is closed by the Processor once the initial scan
+// completes.
+func (p *Processor) Start(stopper *stop.Stopper, rtsIter engine.SimpleIterator) {
+	ctx := p.AnnotateCtx(context.Background())
+	stopper.RunWorker(ctx, func(ctx context.Context) {
+		defer close(p.stoppedC)
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		// Launch an async task to scan over the range's data and initialize
+		// the resolved timestamp.
+		if err := stopper.RunAsyncTask(ctx, "rangefeed: init resolved ts",
+			func(ctx context.Context) {
+				defer rtsIter.Close()
+				if err := p.initResolvedTS(ctx, rtsIter); err != nil {
+					p.StopWithErr(roachpb.NewError(err))
+				}
+			},
+		); err != nil {
+			rtsIter.Close()
+			return
+		}
+
+		// txnPushTicker periodically pushes the transactions of old unresolved
+		// intents.
+		var txnPushTickerC <-chan time.Time
+		var txnPushAttemptC chan struct{}
+		if p.TxnPusher != nil {
+			txnPushTicker := time.NewTicker(p.PushTxnsInterval)
+			defer txnPushTicker.Stop()
+			txnPushTickerC = txnPushTicker.C
+		}
+
+		for {
+			select {
+			case r := <-p.regC:
+				if !p.Span.AsRawSpanWithNoLocals().Contains(r.span) {
+					log.Fatalf(ctx, "registration %s not in Processor's key range %v", r.span, p.Span)
+				}
+				p.reg.Register(r)
+				// Publish the current resolved timestamp, which the registration
+				// will send once it has caught up.
+				if p.rts.IsInit() && !p.rts.Get().IsEmpty() {
+					r.publish(p.newCheckpointEvent())
+				}
+				if err := stopper.RunAsyncTask(ctx, "rangefeed: output loop",
+					func(ctx context.Context) {
+						pErr := r.outputLoop(ctx)
+						r.errC <- pErr
+						select {
+						case p.unregC <- r:
+						case <-p.stoppedC:
+						}
+					},
+				); err != nil {
+					if r.catchUpIter != nil {
+						r.catchUpIter.Close()
+					}
+					p.reg.Unregister(r)
+					r.errC <- roachpb.NewError(err)
+				}
+
+			case r := <-p.unregC:
+				p.reg.Unregister(r)
+
+			case <-p.lenReqC:
+				p.lenResC <- p.reg.Len()
+
+			case e := <-p.eventC:
+				p.consumeEvent(ctx, e)
+
+			case <-txnPushTickerC:
+				// Don't perform transaction push attempts until the resolved
+				// timestamp has been initialized, or while one is in progress.
+				if !p.rts.IsInit() || txnPushAttemptC != nil {
+					continue
+				}
+				now := p.Clock.Now()
+				before := now.Add(-p.PushTxnsAge.Nanoseconds(), 0)
+				oldTxns := p.rts.intentQ.Before(before)
+				if len(oldTxns) == 0 {
+					continue
+				}
+				toPush := make([]enginepb.TxnMeta, len(oldTxns))
+				for i, txn := range oldTxns {
+					toPush[i] = txn.asTxnMeta()
+				}
+				txnPushAttemptC = make(chan struct{})
+				doneC := txnPushAttemptC
+				if err := stopper.RunAsyncTask(ctx, "rangefeed: pushing old txns",
+					func(ctx context.Context) {
+						defer close(doneC)
+						if err := p.pushOldTxns(ctx, toPush, now); err != nil {
+							log.Warningf(ctx, "failed to push old transactions: %s", err)
+						}
+					},
+				); err != nil {
+					close(doneC)
+				}
+
+			case <-txnPushAttemptC:
+				txnPushAttemptC = nil
+
+			case pErr := <-p.stopC:
+				p.reg.DisconnectWithErr(pErr)
+				return
+
+			case <-stopper.ShouldQuiesce():
+				p.reg.DisconnectWithErr(roachpb.NewError(&roachpb.NodeUnavailableError{}))
+				return
+			}
+		}
+	})
+}
+
+// Stop shuts down the processor and closes all registrations. Safe to call on
+// nil Processor. It is not valid to restart a processor after it has been
+// stopped.
+func (p *Processor) Stop() {
+	p.StopWithErr(nil)
+}
+
+// StopWithErr shuts down the processor and closes all registrations with the
+// specified error. Safe to call on nil Processor. It is not valid to restart
+// a processor after it has been stopped.
+func (p *Processor) StopWithErr(pErr *roachpb.Error) {
+	if p == nil {
+		return
+	}
+	select {
+	case p.stopC <- pErr:
+	default:
+		// Already stopping.
+	}
+}
+
+// Stopped returns a channel that is closed once the Processor has stopped.
+func (p *Processor) Stopped() <-chan struct{} {
+	return p.stoppedC
+}
+
+// Register registers the stream over the specified span of keys. The
+// registration will not observe any events that were consumed before this
+// method was called. It is undefined whether the registration will observe
+// events that are consumed concurrently with this call. The channel will be
+// provided an error when the registration closes.
+//
+// The optionally provided "catch-up" iterator is used to read changes from
+// the engine which occurred after the provided start timestamp. It is closed
+// once the catch-up scan completes.
+//
+// Register returns false if the Processor has already been stopped, in
+// which case the catch-up iterator is not closed.
+func (p *Processor) Register(
+	span roachpb.RSpan,
+	startTS hlc.Timestamp,
+	catchUpIter engine.SimpleIterator,
+	stream Stream,
+	errC chan<- *roachpb.Error,
+) bool {
+	r := newRegistration(
+		span.AsRawSpanWithNoLocals(), startTS, catchUpIter, p.EventChanCap, stream, errC,
+	)
+	select {
+	case p.regC <- r:
+		return true
+	case <-p.stoppedC:
+		return false
+	}
+}
+
+// Len returns the number of registrations attached to the processor.
+func (p *Processor) Len() int {
+	if p == nil {
+		return 0
+	}
+	select {
+	case p.lenReqC <- struct{}{}:
+		return <-p.lenResC
+	case <-p.stoppedC:
+		return 0
+	}
+}
+
+// ConsumeLogicalOps informs the rangefeed processor of the set of logical
+// operations applied to the range. It returns false if the Processor was
+// stopped, either before the call or because its event buffer overflowed.
+// Safe to call on nil Processor.
+func (p *Processor) ConsumeLogicalOps(ops ...LogicalOp) bool {
+	if p == nil {
+		return true
+	}
+	if len(ops) == 0 {
+		return true
+	}
+	return p.sendEvent(event{ops: ops})
+}
+
+// ForwardClosedTS indicates that the closed timestamp that serves as the
+// basis for the rangefeed processor's resolved timestamp has advanced. It
+// returns false if the Processor was stopped, either before the call or
+// because its event buffer overflowed. Safe to call on nil Processor.
+func (p *Processor) ForwardClosedTS(closedTS hlc.Timestamp) bool {
+	if p == nil {
+		return true
+	}
+	if closedTS.IsEmpty() {
+		return true
+	}
+	return p.sendEvent(event{ct: closedTS})
+}
+
+// sendEvent informs the Processor of a new event. If the Processor's event
+// buffer is full, the Processor is stopped with an error: blocking here
+// would hold up the application of Raft commands to the range.
+func (p *Processor) sendEvent(e event) bool {
+	select {
+	case p.eventC <- e:
+		return true
+	case <-p.stoppedC:
+		return false
+	default:
+		p.StopWithErr(roachpb.NewErrorf("rangefeed buffer overflow"))
+		return false
+	}
+}
+
+// sendEventBlocking is like sendEvent, but it blocks until the event is
+// handed to the Processor instead of stopping the Processor if its event
+// buffer is full. It is used by the Processor's own asynchronous tasks.
+func (p *Processor) sendEventBlocking(ctx context.Context, e event) bool {
+	select {
+	case p.eventC <- e:
+		return true
+	case <-p.stoppedC:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// syncEventC synchronizes access to the Processor goroutine, allowing the
+// caller to establish causality with actions taken by the Processor goroutine.
+// It does so by flushing the event pipeline. It is used in tests.
+func (p *Processor) syncEventC() {
+	syncC := make(chan struct{})
+	select {
+	case p.eventC <- event{syncC: syncC}:
+		select {
+		case <-syncC:
+		case <-p.stoppedC:
+		}
+	case <-p.stoppedC:
+	}
+}
+
+func (p *Processor) consumeEvent(ctx context.Context, e event) {
+	switch {
+	case len(e.ops) > 0:
+		p.consumeLogicalOps(ctx, e.ops)
+	case !e.ct.IsEmpty():
+		p.forwardClosedTS(ctx, e.ct)
+	case e.initRTS:
+		if p.rts.Init() {
+			p.publishCheckpoint(ctx)
+		}
+	case e.syncC != nil:
+		close(e.syncC)
+	default:
+		panic(fmt.Sprintf("missing event variant: %+v", e))
+	}
+}
+
+func (p *Processor) consumeLogicalOps(ctx context.Context, ops []LogicalOp) {
+	for _, op := range ops {
+		// Publish the logical op's value, if it has one.
+		switch t := op.(type) {
+		case *WriteValueOp:
+			p.publishValue(ctx, t.Key, t.Timestamp, t.Value)
+		case *CommitIntentOp:
+			p.publishValue(ctx, t.Key, t.Timestamp, t.Value)
+		}
+
+		// Determine whether the operation caused the resolved timestamp to
+		// move forward. If so, publish a checkpoint.
+		if p.rts.ConsumeLogicalOp(op) {
+			p.publishCheckpoint(ctx)
+		}
+	}
+}
+
+func (p *Processor) forwardClosedTS(ctx context.Context, newClosedTS hlc.Timestamp) {
+	if p.rts.ForwardClosedTS(newClosedTS) {
+		p.publishCheckpoint(ctx)
+	}
+}
+
+func (p *Processor) publishValue(
+	ctx context.Context, key roachpb.Key, timestamp hlc.Timestamp, value []byte,
+) {
+	if !p.Span.ContainsKey(roachpb.RKey(key)) {
+		log.Fatalf(ctx, "key %v not in Processor's key range %v", key, p.Span)
+	}
+
+	var event roachpb.RangeFeedEvent
+	event.SetValue(&roachpb.RangeFeedValue{
+		Key: key,
+		Value: roachpb.Value{
+			RawBytes:  value,
+			Timestamp: timestamp,
+		},
+	})
+	p.reg.PublishToOverlapping(roachpb.Span{Key: key}, &event)
+}
+
+func (p *Processor) publishCheckpoint(ctx context.Context) {
+	event := p.newCheckpointEvent()
+	p.reg.PublishToOverlapping(p.Span.AsRawSpanWithNoLocals(), event)
+}
+
+func (p *Processor) newCheckpointEvent() *roachpb.RangeFeedEvent {
+	// Create a RangeFeedCheckpoint over the Processor's entire span. The
+	// resolved timestamp applies to all of it, so consumers listening on a
+	// narrower span can simply intersect the two.
+	var event roachpb.RangeFeedEvent
+	event.SetValue(&roachpb.RangeFeedCheckpoint{
+		Span:       p.Span.AsRawSpanWithNoLocals(),
+		ResolvedTS: p.rts.Get(),
+	})
+	return &event
+}
+
+// initResolvedTS scans over the range's data for intents and informs the
+// Processor of each of them before letting it initialize its resolved
+// timestamp.
+func (p *Processor) initResolvedTS(ctx context.Context, it engine.SimpleIterator) error {
+	var meta enginepb.MVCCMetadata
+	endKey := engine.MakeMVCCMetadataKey(p.Span.EndKey.AsRawKey())
+	var ops []LogicalOp
+	for it.Seek(engine.MakeMVCCMetadataKey(p.Span.Key.AsRawKey())); ; it.NextKey() {
+		if ok, err := it.Valid(); err != nil {
+			return err
+		} else if !ok {
+			break
+		}
+		unsafeKey := it.UnsafeKey()
+		if !unsafeKey.Less(endKey) {
+			break
+		}
+		if unsafeKey.IsValue() {
+			// Only meta keys can carry intents, and they're always sorted
+			// before the versions of their key.
+			continue
+		}
+		if err := protoutil.Unmarshal(it.UnsafeValue(), &meta); err != nil {
+			return err
+		}
+		if meta.Txn == nil {
+			continue
+		}
+		ops = append(ops, &WriteIntentOp{
+			TxnID:     meta.Txn.ID,
+			TxnKey:    append([]byte(nil), meta.Txn.Key...),
+			Timestamp: meta.Txn.Timestamp,
+		})
+		const batchSize = 64
+		if len(ops) == batchSize {
+			if !p.sendEventBlocking(ctx, event{ops: ops}) {
+				return nil
+			}
+			ops = nil
+		}
+	}
+	if len(ops) > 0 {
+		if !p.sendEventBlocking(ctx, event{ops: ops}) {
+			return nil
+		}
+	}
+	p.sendEventBlocking(ctx, event{initRTS: true})
+	return nil
+}
+
+// pushOldTxns pushes the provided transactions to the provided timestamp. It
+// then informs the Processor of the new timestamps of the pending
+// transactions and cleans up the intents of the finished ones, whose
+// resolution is in turn observed by the Processor.
+func (p *Processor) pushOldTxns(
+	ctx context.Context, txns []enginepb.TxnMeta, ts hlc.Timestamp,
+) error {
+	pushed, err := p.TxnPusher.PushTxns(ctx, txns, ts)
+	if err != nil {
+		return err
+	}
+
+	var ops []LogicalOp
+	var finished []roachpb.Transaction
+	for _, txn := range pushed {
+		switch txn.Status {
+		case roachpb.PENDING:
+			// The transaction can no longer commit below its new timestamp, so
+			// neither can any of its intents.
+			ops = append(ops, &UpdateIntentOp{
+				TxnID:     txn.ID,
+				Timestamp: txn.Timestamp,
+			})
+		case roachpb.COMMITTED, roachpb.ABORTED:
+			finished = append(finished, txn)
+		}
+	}
+	if len(ops) > 0 {
+		if !p.sendEventBlocking(ctx, event{ops: ops}) {
+			return nil
+		}
+	}
+	if len(finished) > 0 {
+		return p.TxnPusher.CleanupTxnIntentsAsync(ctx, finished)
+	}
+	return nil
+}
@@ -0,0 +1,290 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package rangefeed
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+type testStream struct {
+	ctx context.Context
+	mu  struct {
+		syncutil.Mutex
+		events []*roachpb.RangeFeedEvent
+	}
+}
+
+func newTestStream() *testStream {
+	return &testStream{ctx: context.Background()}
+}
+
+func (s *testStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *testStream) Send(e *roachpb.RangeFeedEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mu.events = append(s.mu.events, e)
+	return nil
+}
+
+func (s *testStream) Events() []*roachpb.RangeFeedEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	es := s.mu.events
+	s.mu.events = nil
+	return es
+}
+
+func valueEvent(key string, ts hlc.Timestamp, val string) *roachpb.RangeFeedEvent {
+	var event roachpb.RangeFeedEvent
+	event.SetValue(&roachpb.RangeFeedValue{
+		Key: roachpb.Key(key),
+		Value: roachpb.Value{
+			RawBytes:  []byte(val),
+			Timestamp: ts,
+		},
+	})
+	return &event
+}
+
+func checkpointEvent(span roachpb.RSpan, ts hlc.Timestamp) *roachpb.RangeFeedEvent {
+	var event roachpb.RangeFeedEvent
+	event.SetValue(&roachpb.RangeFeedCheckpoint{
+		Span:       span.AsRawSpanWithNoLocals(),
+		ResolvedTS: ts,
+	})
+	return &event
+}
+
+// waitForEvents waits until the stream has received as many events as
+// expected and checks that they match the expected events.
+func waitForEvents(t *testing.T, s *testStream, exp []*roachpb.RangeFeedEvent) {
+	t.Helper()
+	var actual []*roachpb.RangeFeedEvent
+	testutils.SucceedsSoon(t, func() error {
+		actual = append(actual, s.Events()...)
+		if len(actual) < len(exp) {
+			return errors.Errorf("expected %d events, found %d", len(exp), len(actual))
+		}
+		return nil
+	})
+	require.Equal(t, exp, actual)
+}
+
+func newTestProcessor(
+	stopper *stop.Stopper, eng engine.Engine,
+) (*Processor, roachpb.RSpan) {
+	span := roachpb.RSpan{Key: roachpb.RKey("a"), EndKey: roachpb.RKey("z")}
+	p := NewProcessor(Config{
+		AmbientContext: log.AmbientContext{},
+		Clock:          hlc.NewClock(hlc.UnixNano, time.Nanosecond),
+		Span:           span,
+		EventChanCap:   16,
+	})
+	p.Start(stopper, eng.NewIterator(engine.IterOptions{UpperBound: span.EndKey.AsRawKey()}))
+	return p, span
+}
+
+func TestProcessorBasic(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	eng := engine.NewInMem(roachpb.Attributes{}, 1<<20)
+	defer eng.Close()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+
+	// Write an intent that exists before the Processor starts.
+	txn1 := roachpb.MakeTransaction("txn1", roachpb.Key("a"), 0, enginepb.SERIALIZABLE, makeTS(5, 0), 0)
+	if err := engine.MVCCPut(
+		ctx, eng, nil, roachpb.Key("c"), txn1.Timestamp, roachpb.MakeValueFromString("c1"), &txn1,
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	p, span := newTestProcessor(stopper, eng)
+	defer p.Stop()
+
+	// Register a stream over part of the range.
+	s1 := newTestStream()
+	errC1 := make(chan *roachpb.Error, 1)
+	require.True(t, p.Register(
+		roachpb.RSpan{Key: roachpb.RKey("b"), EndKey: roachpb.RKey("m")},
+		hlc.Timestamp{}, nil, s1, errC1,
+	))
+	require.Equal(t, 1, p.Len())
+
+	// Values inside and outside of the registration's span.
+	require.True(t, p.ConsumeLogicalOps(
+		&WriteValueOp{Key: roachpb.Key("b"), Timestamp: makeTS(6, 0), Value: []byte("b1")},
+		&WriteValueOp{Key: roachpb.Key("n"), Timestamp: makeTS(6, 0), Value: []byte("n1")},
+	))
+
+	// The closed timestamp is held back by the intent.
+	require.True(t, p.ForwardClosedTS(makeTS(10, 0)))
+	p.syncEventC()
+	waitForEvents(t, s1, []*roachpb.RangeFeedEvent{
+		valueEvent("b", makeTS(6, 0), "b1"),
+		checkpointEvent(span, makeTS(4, math.MaxInt32)),
+	})
+
+	// Committing the intent publishes its value and lets the resolved
+	// timestamp catch up to the closed timestamp.
+	require.True(t, p.ConsumeLogicalOps(&CommitIntentOp{
+		TxnID: txn1.ID, Key: roachpb.Key("c"), Timestamp: makeTS(7, 0), Value: []byte("c1"),
+	}))
+	p.syncEventC()
+	waitForEvents(t, s1, []*roachpb.RangeFeedEvent{
+		valueEvent("c", makeTS(7, 0), "c1"),
+		checkpointEvent(span, makeTS(10, 0)),
+	})
+
+	// A new intent doesn't regress the resolved timestamp, and aborting it
+	// doesn't publish anything.
+	txn2 := uuid.MakeV4()
+	require.True(t, p.ConsumeLogicalOps(
+		&WriteIntentOp{TxnID: txn2, TxnKey: []byte("d"), Timestamp: makeTS(12, 0)},
+	))
+	require.True(t, p.ForwardClosedTS(makeTS(15, 0)))
+	require.True(t, p.ConsumeLogicalOps(&AbortIntentOp{TxnID: txn2}))
+	p.syncEventC()
+	waitForEvents(t, s1, []*roachpb.RangeFeedEvent{
+		checkpointEvent(span, makeTS(11, math.MaxInt32)),
+		checkpointEvent(span, makeTS(15, 0)),
+	})
+
+	// Stopping the Processor disconnects the registration.
+	p.StopWithErr(roachpb.NewErrorf("stop"))
+	pErr := <-errC1
+	require.Regexp(t, "stop", pErr.String())
+	<-p.Stopped()
+	require.False(t, p.ConsumeLogicalOps(&AbortIntentOp{TxnID: txn2}))
+	require.Equal(t, 0, p.Len())
+}
+
+func TestProcessorCatchUpScan(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	eng := engine.NewInMem(roachpb.Attributes{}, 1<<20)
+	defer eng.Close()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+
+	for _, kv := range []struct {
+		key string
+		ts  hlc.Timestamp
+	}{
+		{"b", makeTS(3, 0)},
+		{"b", makeTS(7, 0)},
+		{"c", makeTS(4, 0)},
+		{"d", makeTS(8, 0)},
+		{"y", makeTS(8, 0)},
+	} {
+		if err := engine.MVCCPut(
+			ctx, eng, nil, roachpb.Key(kv.key), kv.ts, roachpb.MakeValueFromString(kv.key), nil,
+		); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// An intent, which isn't part of the catch-up scan.
+	txn := roachpb.MakeTransaction("txn", roachpb.Key("a"), 0, enginepb.SERIALIZABLE, makeTS(9, 0), 0)
+	if err := engine.MVCCPut(
+		ctx, eng, nil, roachpb.Key("c"), txn.Timestamp, roachpb.MakeValueFromString("c2"), &txn,
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	p, _ := newTestProcessor(stopper, eng)
+	defer p.Stop()
+
+	s := newTestStream()
+	errC := make(chan *roachpb.Error, 1)
+	catchUpIter := eng.NewIterator(engine.IterOptions{UpperBound: roachpb.Key("m")})
+	require.True(t, p.Register(
+		roachpb.RSpan{Key: roachpb.RKey("a"), EndKey: roachpb.RKey("m")},
+		makeTS(3, 0), catchUpIter, s, errC,
+	))
+	require.True(t, p.ConsumeLogicalOps(
+		&WriteValueOp{Key: roachpb.Key("e"), Timestamp: makeTS(10, 0), Value: []byte("e")},
+	))
+	p.syncEventC()
+
+	// The catch-up scan publishes the values above the start timestamp in
+	// the registration's span, newest first for each key, before any live
+	// values.
+	type keyTS struct {
+		key string
+		ts  hlc.Timestamp
+	}
+	exp := []keyTS{
+		{"b", makeTS(7, 0)},
+		{"c", makeTS(4, 0)},
+		{"d", makeTS(8, 0)},
+		{"e", makeTS(10, 0)},
+	}
+	var actual []keyTS
+	testutils.SucceedsSoon(t, func() error {
+		for _, e := range s.Events() {
+			actual = append(actual, keyTS{string(e.Val.Key), e.Val.Value.Timestamp})
+		}
+		if len(actual) < len(exp) {
+			return errors.Errorf("expected %d events, found %d", len(exp), len(actual))
+		}
+		return nil
+	})
+	require.Equal(t, exp, actual)
+}
+
+func TestProcessorBufferOverflow(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	eng := engine.NewInMem(roachpb.Attributes{}, 1<<20)
+	defer eng.Close()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+
+	p, _ := newTestProcessor(stopper, eng)
+
+	// Block the Processor goroutine by not reading the response to a Len
+	// request, so that its event channel fills up.
+	p.lenReqC <- struct{}{}
+	ops := make([]bool, 0, p.EventChanCap+1)
+	for i := 0; i <= p.EventChanCap; i++ {
+		ops = append(ops, p.ConsumeLogicalOps(
+			&WriteValueOp{Key: roachpb.Key("b"), Timestamp: makeTS(int64(i+1), 0)},
+		))
+	}
+	<-p.lenResC
+	require.False(t, ops[len(ops)-1])
+	<-p.Stopped()
+}
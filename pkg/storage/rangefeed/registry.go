@@ -0,0 +1,256 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package rangefeed
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+)
+
+// Stream is an object capable of transmitting RangeFeedEvents.
+type Stream interface {
+	// Context returns the context for this stream.
+	Context() context.Context
+	// Send blocks until it sends m, the stream is done, or the stream breaks.
+	// Send must be safe to call on the same stream in different goroutines.
+	Send(*roachpb.RangeFeedEvent) error
+}
+
+// registration is an instance of a rangefeed subscriber who has registered to
+// receive updates for a specific range of keys. Updates are delivered to its
+// stream by an output loop running in its own goroutine, so that a slow
+// consumer doesn't hold up the Processor or the other registrations.
+//
+// Events are buffered between the Processor and the output loop. If the
+// buffer fills up, the registration is disconnected with an error and the
+// consumer is expected to reconnect, catching up on the events it missed.
+type registration struct {
+	// Input.
+	span        roachpb.Span
+	startTS     hlc.Timestamp
+	catchUpIter engine.SimpleIterator
+
+	// Output.
+	stream Stream
+	errC   chan<- *roachpb.Error
+
+	// Internal. buf and doneC are used to communicate between the Processor
+	// goroutine and the output loop. The other fields are only accessed by
+	// the Processor goroutine.
+	buf          chan *roachpb.RangeFeedEvent
+	doneC        chan struct{}
+	doneErr      *roachpb.Error
+	disconnected bool
+}
+
+func newRegistration(
+	span roachpb.Span,
+	startTS hlc.Timestamp,
+	catchUpIter engine.SimpleIterator,
+	bufferSz int,
+	stream Stream,
+	errC chan<- *roachpb.Error,
+) *registration {
+	return &registration{
+		span:        span,
+		startTS:     startTS,
+		catchUpIter: catchUpIter,
+		stream:      stream,
+		errC:        errC,
+		buf:         make(chan *roachpb.RangeFeedEvent, bufferSz),
+		doneC:       make(chan struct{}),
+	}
+}
+
+// publish attempts to send a single event to the output buffer for this
+// registration. If the output buffer is full, the registration is
+// disconnected.
+func (r *registration) publish(event *roachpb.RangeFeedEvent) {
+	if r.disconnected {
+		return
+	}
+	select {
+	case r.buf <- event:
+	default:
+		r.disconnect(roachpb.NewErrorf("rangefeed buffer overflow"))
+	}
+}
+
+// disconnect cancels the output loop of the registration, which will in turn
+// return the provided error to the registration's consumer. It is idempotent.
+func (r *registration) disconnect(pErr *roachpb.Error) {
+	if r.disconnected {
+		return
+	}
+	r.disconnected = true
+	r.doneErr = pErr
+	close(r.doneC)
+}
+
+// outputLoop runs the catch-up scan of the registration, if any, and then
+// sends the buffered events to the registration's stream until the
+// registration is disconnected or the stream breaks. It returns the error to
+// hand to the registration's consumer.
+func (r *registration) outputLoop(ctx context.Context) *roachpb.Error {
+	if r.catchUpIter != nil {
+		err := r.runCatchUpScan()
+		r.catchUpIter.Close()
+		r.catchUpIter = nil
+		if err != nil {
+			return roachpb.NewError(errors.Wrap(err, "catch-up scan failed"))
+		}
+	}
+
+	for {
+		select {
+		case event := <-r.buf:
+			if err := r.stream.Send(event); err != nil {
+				return roachpb.NewError(err)
+			}
+		case <-r.doneC:
+			return r.doneErr
+		case <-ctx.Done():
+			// The Processor cancels the context after disconnecting all of its
+			// registrations, so prefer the error they were disconnected with.
+			select {
+			case <-r.doneC:
+				return r.doneErr
+			default:
+				return roachpb.NewError(ctx.Err())
+			}
+		case <-r.stream.Context().Done():
+			return roachpb.NewError(r.stream.Context().Err())
+		}
+	}
+}
+
+// runCatchUpScan sends the committed values in the registration's span with
+// timestamps above its start timestamp, as read from the snapshot of the
+// range's data that was taken when the registration was created. Values for
+// each key are sent from newest to oldest. Intents are skipped: if they're
+// committed, their values will be published once they're resolved.
+func (r *registration) runCatchUpScan() error {
+	var meta enginepb.MVCCMetadata
+	var intentKey engine.MVCCKey
+	it := r.catchUpIter
+	for it.Seek(engine.MakeMVCCMetadataKey(r.span.Key)); ; {
+		if ok, err := it.Valid(); err != nil {
+			return err
+		} else if !ok {
+			break
+		}
+		unsafeKey := it.UnsafeKey()
+		if !unsafeKey.Less(engine.MakeMVCCMetadataKey(r.span.EndKey)) {
+			break
+		}
+		if !unsafeKey.IsValue() {
+			if err := protoutil.Unmarshal(it.UnsafeValue(), &meta); err != nil {
+				return errors.Wrapf(err, "unmarshaling mvcc meta: %v", unsafeKey)
+			}
+			if meta.IsInline() {
+				// Inline values are not MVCC values and are not published.
+				it.NextKey()
+				continue
+			}
+			if meta.Txn != nil {
+				// Skip the provisional value of the intent.
+				intentKey = engine.MVCCKey{
+					Key:       append(intentKey.Key[:0], unsafeKey.Key...),
+					Timestamp: hlc.Timestamp(meta.Timestamp),
+				}
+			}
+			it.Next()
+			continue
+		}
+		if !r.startTS.Less(unsafeKey.Timestamp) {
+			// This and all older versions of the key are at or below the
+			// start timestamp.
+			it.NextKey()
+			continue
+		}
+		if unsafeKey.Equal(intentKey) {
+			it.Next()
+			continue
+		}
+		var event roachpb.RangeFeedEvent
+		event.SetValue(&roachpb.RangeFeedValue{
+			Key: append(roachpb.Key(nil), unsafeKey.Key...),
+			Value: roachpb.Value{
+				RawBytes:  append([]byte(nil), it.UnsafeValue()...),
+				Timestamp: unsafeKey.Timestamp,
+			},
+		})
+		if err := r.stream.Send(&event); err != nil {
+			return err
+		}
+		it.Next()
+	}
+	return nil
+}
+
+// registry holds the set of registrations of a Processor and routes events
+// to the registrations interested in them. Ranges are expected to have few
+// registrations, so events are routed with a linear scan over all of them.
+type registry struct {
+	regs map[*registration]struct{}
+}
+
+func makeRegistry() registry {
+	return registry{
+		regs: make(map[*registration]struct{}),
+	}
+}
+
+// Len returns the number of registrations in the registry.
+func (reg *registry) Len() int {
+	return len(reg.regs)
+}
+
+// Register adds the provided registration to the registry.
+func (reg *registry) Register(r *registration) {
+	reg.regs[r] = struct{}{}
+}
+
+// Unregister removes the provided registration from the registry. It is a
+// no-op if the registration is not in the registry.
+func (reg *registry) Unregister(r *registration) {
+	delete(reg.regs, r)
+}
+
+// PublishToOverlapping publishes the provided event to all registrations
+// whose span overlaps the provided span.
+func (reg *registry) PublishToOverlapping(span roachpb.Span, event *roachpb.RangeFeedEvent) {
+	for r := range reg.regs {
+		if r.span.Overlaps(span) {
+			r.publish(event)
+		}
+	}
+}
+
+// DisconnectWithErr disconnects all registrations with the provided error and
+// removes them from the registry.
+func (reg *registry) DisconnectWithErr(pErr *roachpb.Error) {
+	for r := range reg.regs {
+		r.disconnect(pErr)
+		delete(reg.regs, r)
+	}
+}
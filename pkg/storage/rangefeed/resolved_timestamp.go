@@ -0,0 +1,305 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package rangefeed
+
+import (
+	"bytes"
+	"container/heap"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+// A rangefeed's "resolved timestamp" is the timestamp at or below which no
+// new committed values will be published on the rangefeed: every value with
+// a timestamp at or below it that will ever exist in the range has already
+// been emitted.
+//
+// The resolved timestamp is bounded by two things:
+// 1. the range's closed timestamp, below which no new writes are permitted
+//    to be evaluated. Closing a timestamp only prevents new intents and
+//    values from appearing below it.
+// 2. the timestamps of the unresolved intents in the range. An intent below
+//    the closed timestamp may still be committed at its timestamp and turned
+//    into a value that has not yet been published.
+//
+// The resolved timestamp is therefore the minimum of the closed timestamp and
+// the timestamp immediately preceding the oldest unresolved intent. Intents
+// are tracked per transaction: only the oldest timestamp of each transaction
+// with intents in the range matters, along with the number of such intents so
+// that the transaction can be forgotten once all of them are resolved.
+//
+// The resolved timestamp is only computed once the tracked intents have been
+// initialized from a scan over the range's data. Before then, operations on
+// intents may be consumed before the scan comes across the intents they
+// refer to, so their reference counts are allowed to go negative.
+type resolvedTimestamp struct {
+	init       bool
+	closedTS   hlc.Timestamp
+	resolvedTS hlc.Timestamp
+	intentQ    unresolvedIntentQueue
+}
+
+func makeResolvedTimestamp() resolvedTimestamp {
+	return resolvedTimestamp{
+		intentQ: makeUnresolvedIntentQueue(),
+	}
+}
+
+// Get returns the current value of the resolved timestamp.
+func (rts *resolvedTimestamp) Get() hlc.Timestamp {
+	return rts.resolvedTS
+}
+
+// IsInit returns whether the resolved timestamp has been initialized.
+func (rts *resolvedTimestamp) IsInit() bool {
+	return rts.init
+}
+
+// Init informs the resolved timestamp that all intents in the range have
+// been accounted for. It returns whether the resolved timestamp moved
+// forward.
+func (rts *resolvedTimestamp) Init() bool {
+	rts.init = true
+	rts.intentQ.AllowNegRefCount(false)
+	return rts.recompute()
+}
+
+// ForwardClosedTS indicates that the closed timestamp of the range has moved
+// forward. It returns whether the resolved timestamp moved forward.
+func (rts *resolvedTimestamp) ForwardClosedTS(newClosedTS hlc.Timestamp) bool {
+	if !rts.closedTS.Forward(newClosedTS) {
+		return false
+	}
+	return rts.recompute()
+}
+
+// ConsumeLogicalOp informs the resolved timestamp of a logical operation
+// applied to the range. It returns whether the resolved timestamp moved
+// forward.
+func (rts *resolvedTimestamp) ConsumeLogicalOp(op LogicalOp) bool {
+	switch t := op.(type) {
+	case *WriteValueOp:
+		// A committed value has no effect on the resolved timestamp.
+		return false
+	case *WriteIntentOp:
+		rts.intentQ.IncRef(t.TxnID, t.TxnKey, t.Timestamp)
+		return rts.recompute()
+	case *UpdateIntentOp:
+		rts.intentQ.UpdateTS(t.TxnID, t.Timestamp)
+		return rts.recompute()
+	case *CommitIntentOp:
+		rts.intentQ.DecRef(t.TxnID, t.Timestamp)
+		return rts.recompute()
+	case *AbortIntentOp:
+		rts.intentQ.DecRef(t.TxnID, hlc.Timestamp{})
+		return rts.recompute()
+	default:
+		panic(fmt.Sprintf("unknown logical op %T", t))
+	}
+}
+
+// recompute computes the resolved timestamp based on the closed timestamp
+// and the oldest unresolved intent. It returns whether the resolved
+// timestamp moved forward.
+func (rts *resolvedTimestamp) recompute() bool {
+	if !rts.IsInit() {
+		return false
+	}
+	newTS := rts.closedTS
+	if txn := rts.intentQ.Oldest(); txn != nil {
+		if intentTS := txn.timestamp.Prev(); intentTS.Less(newTS) {
+			newTS = intentTS
+		}
+	}
+	// The resolved timestamp never regresses. A new intent can only be
+	// written above the closed timestamp, so it can't be below the resolved
+	// timestamp either.
+	return rts.resolvedTS.Forward(newTS)
+}
+
+// unresolvedTxn is a transaction with unresolved intents in the range.
+type unresolvedTxn struct {
+	txnID     uuid.UUID
+	txnKey    []byte
+	timestamp hlc.Timestamp
+	refCount  int // count of unresolved intents in the range
+
+	index int // index in the unresolvedTxnHeap
+}
+
+// asTxnMeta returns a TxnMeta representation of the transaction.
+func (t *unresolvedTxn) asTxnMeta() enginepb.TxnMeta {
+	return enginepb.TxnMeta{
+		ID:        t.txnID,
+		Key:       t.txnKey,
+		Timestamp: t.timestamp,
+	}
+}
+
+// unresolvedTxnHeap is a min-heap of unresolvedTxns ordered by timestamp. It
+// implements heap.Interface.
+type unresolvedTxnHeap []*unresolvedTxn
+
+func (h unresolvedTxnHeap) Len() int { return len(h) }
+
+func (h unresolvedTxnHeap) Less(i, j int) bool {
+	if h[i].timestamp == h[j].timestamp {
+		// Order by txnID to make the order deterministic.
+		return bytes.Compare(h[i].txnID.GetBytes(), h[j].txnID.GetBytes()) < 0
+	}
+	return h[i].timestamp.Less(h[j].timestamp)
+}
+
+func (h unresolvedTxnHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *unresolvedTxnHeap) Push(x interface{}) {
+	txn := x.(*unresolvedTxn)
+	txn.index = len(*h)
+	*h = append(*h, txn)
+}
+
+func (h *unresolvedTxnHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	txn := old[n-1]
+	txn.index = -1
+	old[n-1] = nil
+	*h = old[:n-1]
+	return txn
+}
+
+// unresolvedIntentQueue tracks the transactions with unresolved intents in
+// the range, ordered by the timestamps of their intents.
+type unresolvedIntentQueue struct {
+	txns             map[uuid.UUID]*unresolvedTxn
+	minHeap          unresolvedTxnHeap
+	allowNegRefCount bool
+}
+
+func makeUnresolvedIntentQueue() unresolvedIntentQueue {
+	return unresolvedIntentQueue{
+		txns:             make(map[uuid.UUID]*unresolvedTxn),
+		allowNegRefCount: true,
+	}
+}
+
+// Len returns the number of transactions being tracked.
+func (uiq *unresolvedIntentQueue) Len() int {
+	return uiq.minHeap.Len()
+}
+
+// Oldest returns the oldest transaction with unresolved intents, or nil if
+// there are none. It must not be called while negative reference counts are
+// allowed.
+func (uiq *unresolvedIntentQueue) Oldest() *unresolvedTxn {
+	if uiq.allowNegRefCount {
+		panic("Oldest called while negative reference counts are allowed")
+	}
+	if uiq.minHeap.Len() == 0 {
+		return nil
+	}
+	return uiq.minHeap[0]
+}
+
+// Before returns all transactions with unresolved intents that have a
+// timestamp before the provided timestamp.
+func (uiq *unresolvedIntentQueue) Before(ts hlc.Timestamp) []*unresolvedTxn {
+	var txns []*unresolvedTxn
+	for _, txn := range uiq.minHeap {
+		if txn.refCount > 0 && txn.timestamp.Less(ts) {
+			txns = append(txns, txn)
+		}
+	}
+	return txns
+}
+
+// IncRef increments the reference count of the transaction, starting to
+// track it if it wasn't already.
+func (uiq *unresolvedIntentQueue) IncRef(txnID uuid.UUID, txnKey []byte, ts hlc.Timestamp) {
+	uiq.updateTxn(txnID, txnKey, ts, +1)
+}
+
+// DecRef decrements the reference count of the transaction, forgetting it
+// once all of its intents have been resolved.
+func (uiq *unresolvedIntentQueue) DecRef(txnID uuid.UUID, ts hlc.Timestamp) {
+	uiq.updateTxn(txnID, nil, ts, -1)
+}
+
+// UpdateTS forwards the timestamp of the transaction's intents, if the
+// transaction is being tracked.
+func (uiq *unresolvedIntentQueue) UpdateTS(txnID uuid.UUID, ts hlc.Timestamp) {
+	uiq.updateTxn(txnID, nil, ts, 0)
+}
+
+func (uiq *unresolvedIntentQueue) updateTxn(
+	txnID uuid.UUID, txnKey []byte, ts hlc.Timestamp, delta int,
+) {
+	txn, ok := uiq.txns[txnID]
+	if !ok {
+		if delta == 0 || (delta < 0 && !uiq.allowNegRefCount) {
+			// Nothing to update, or an intent of a transaction that was
+			// already forgotten, as happens when its intents are resolved
+			// more than once.
+			return
+		}
+		txn = &unresolvedTxn{
+			txnID:     txnID,
+			txnKey:    txnKey,
+			timestamp: ts,
+			refCount:  delta,
+		}
+		uiq.txns[txnID] = txn
+		heap.Push(&uiq.minHeap, txn)
+		return
+	}
+	txn.refCount += delta
+	if txn.txnKey == nil {
+		txn.txnKey = txnKey
+	}
+	if txn.refCount == 0 || (txn.refCount < 0 && !uiq.allowNegRefCount) {
+		uiq.remove(txn)
+		return
+	}
+	if txn.timestamp.Forward(ts) {
+		heap.Fix(&uiq.minHeap, txn.index)
+	}
+}
+
+func (uiq *unresolvedIntentQueue) remove(txn *unresolvedTxn) {
+	delete(uiq.txns, txn.txnID)
+	heap.Remove(&uiq.minHeap, txn.index)
+}
+
+// AllowNegRefCount sets whether transactions are allowed to have negative
+// reference counts. When disallowed, all transactions with negative
+// reference counts are forgotten.
+func (uiq *unresolvedIntentQueue) AllowNegRefCount(b bool) {
+	uiq.allowNegRefCount = b
+	if b {
+		return
+	}
+	for _, txn := range uiq.txns {
+		if txn.refCount <= 0 {
+			uiq.remove(txn)
+		}
+	}
+}
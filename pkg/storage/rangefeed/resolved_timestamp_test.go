@@ -0,0 +1,163 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package rangefeed
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+func makeTS(walltime int64, logical int32) hlc.Timestamp {
+	return hlc.Timestamp{
+		WallTime: walltime,
+		Logical:  logical,
+	}
+}
+
+func TestUnresolvedIntentQueue(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	uiq := makeUnresolvedIntentQueue()
+	uiq.AllowNegRefCount(false)
+
+	// Test empty queue.
+	require.Equal(t, 0, uiq.Len())
+	require.Nil(t, uiq.Oldest())
+	require.Nil(t, uiq.Before(makeTS(10, 0)))
+
+	// Increment a non-existent txn.
+	txn1 := uuid.MakeV4()
+	uiq.IncRef(txn1, nil, makeTS(10, 1))
+	require.Equal(t, 1, uiq.Len())
+	require.Equal(t, txn1, uiq.Oldest().txnID)
+	require.Equal(t, makeTS(10, 1), uiq.Oldest().timestamp)
+	require.Equal(t, 1, uiq.Oldest().refCount)
+	require.Len(t, uiq.Before(makeTS(10, 2)), 1)
+	require.Len(t, uiq.Before(makeTS(10, 1)), 0)
+
+	// Decrement a non-existent txn.
+	txn2 := uuid.MakeV4()
+	uiq.DecRef(txn2, makeTS(10, 1))
+	require.Equal(t, 1, uiq.Len())
+
+	// Update a non-existent txn.
+	uiq.UpdateTS(txn2, makeTS(10, 1))
+	require.Equal(t, 1, uiq.Len())
+
+	// Add a second, older txn.
+	uiq.IncRef(txn2, nil, makeTS(5, 0))
+	require.Equal(t, 2, uiq.Len())
+	require.Equal(t, txn2, uiq.Oldest().txnID)
+
+	// Increment the second txn again; its timestamp doesn't regress.
+	uiq.IncRef(txn2, nil, makeTS(4, 0))
+	require.Equal(t, 2, uiq.Oldest().refCount)
+	require.Equal(t, makeTS(5, 0), uiq.Oldest().timestamp)
+
+	// Forward the second txn past the first.
+	uiq.UpdateTS(txn2, makeTS(12, 0))
+	require.Equal(t, txn1, uiq.Oldest().txnID)
+	require.Len(t, uiq.Before(makeTS(11, 0)), 1)
+	require.Len(t, uiq.Before(makeTS(13, 0)), 2)
+
+	// Resolve the first txn's only intent.
+	uiq.DecRef(txn1, makeTS(10, 1))
+	require.Equal(t, 1, uiq.Len())
+	require.Equal(t, txn2, uiq.Oldest().txnID)
+
+	// Resolve the second txn's intents.
+	uiq.DecRef(txn2, makeTS(12, 0))
+	require.Equal(t, 1, uiq.Len())
+	uiq.DecRef(txn2, makeTS(12, 0))
+	require.Equal(t, 0, uiq.Len())
+	require.Nil(t, uiq.Oldest())
+}
+
+func TestUnresolvedIntentQueueNegRefCount(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	uiq := makeUnresolvedIntentQueue()
+
+	// A txn's intent is resolved before the initial scan finds it.
+	txn1 := uuid.MakeV4()
+	uiq.DecRef(txn1, makeTS(10, 0))
+	require.Equal(t, 1, uiq.Len())
+	uiq.IncRef(txn1, nil, makeTS(5, 0))
+	require.Equal(t, 0, uiq.Len())
+
+	// Two intents of a txn are resolved, and only one is then found.
+	txn2 := uuid.MakeV4()
+	uiq.DecRef(txn2, makeTS(10, 0))
+	uiq.DecRef(txn2, makeTS(10, 0))
+	uiq.IncRef(txn2, nil, makeTS(5, 0))
+	require.Equal(t, 1, uiq.Len())
+
+	// A txn whose intent is written after the initial scan started.
+	txn3 := uuid.MakeV4()
+	uiq.IncRef(txn3, nil, makeTS(15, 0))
+	require.Equal(t, 2, uiq.Len())
+
+	// Disallowing negative reference counts forgets txn2.
+	uiq.AllowNegRefCount(false)
+	require.Equal(t, 1, uiq.Len())
+	require.Equal(t, txn3, uiq.Oldest().txnID)
+}
+
+func TestResolvedTimestamp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	rts := makeResolvedTimestamp()
+
+	// Nothing is published before initialization.
+	txn1 := uuid.MakeV4()
+	require.False(t, rts.ConsumeLogicalOp(&WriteIntentOp{TxnID: txn1, Timestamp: makeTS(10, 0)}))
+	require.False(t, rts.ForwardClosedTS(makeTS(15, 0)))
+	require.Equal(t, hlc.Timestamp{}, rts.Get())
+
+	// Initialization accounts for both the closed timestamp and the intent.
+	require.True(t, rts.Init())
+	require.Equal(t, makeTS(9, math.MaxInt32), rts.Get())
+
+	// Committed values don't affect the resolved timestamp.
+	require.False(t, rts.ConsumeLogicalOp(&WriteValueOp{Timestamp: makeTS(16, 0)}))
+
+	// A second txn's intent above the closed timestamp holds nothing back.
+	txn2 := uuid.MakeV4()
+	require.False(t, rts.ConsumeLogicalOp(&WriteIntentOp{TxnID: txn2, Timestamp: makeTS(18, 0)}))
+
+	// Committing the first txn's intent moves the resolved timestamp up to
+	// the closed timestamp.
+	require.True(t, rts.ConsumeLogicalOp(&CommitIntentOp{TxnID: txn1, Timestamp: makeTS(10, 0)}))
+	require.Equal(t, makeTS(15, 0), rts.Get())
+
+	// Closing a later timestamp is held back by the second txn.
+	require.True(t, rts.ForwardClosedTS(makeTS(20, 0)))
+	require.Equal(t, makeTS(17, math.MaxInt32), rts.Get())
+
+	// Pushing the second txn moves the resolved timestamp forward.
+	require.True(t, rts.ConsumeLogicalOp(&UpdateIntentOp{TxnID: txn2, Timestamp: makeTS(19, 0)}))
+	require.Equal(t, makeTS(18, math.MaxInt32), rts.Get())
+
+	// Aborting the second txn's intent leaves only the closed timestamp.
+	require.True(t, rts.ConsumeLogicalOp(&AbortIntentOp{TxnID: txn2}))
+	require.Equal(t, makeTS(20, 0), rts.Get())
+
+	// The closed timestamp never regresses.
+	require.False(t, rts.ForwardClosedTS(makeTS(19, 0)))
+	require.Equal(t, makeTS(20, 0), rts.Get())
+}
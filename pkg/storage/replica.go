@@ -48,7 +48,9 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/batcheval/result"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/storage/rangefeed"
 	"github.com/cockroachdb/cockroach/pkg/storage/spanset"
+	"github.com/cockroachdb/cockroach/pkg/storage/split"
 	"github.com/cockroachdb/cockroach/pkg/storage/stateloader"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
 	"github.com/cockroachdb/cockroach/pkg/storage/txnwait"
@@ -57,6 +59,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
@@ -268,6 +271,10 @@ type Replica struct {
 	// writeStats tracks the number of keys written by applied raft commands
 	// in order to aid in replica rebalancing decisions.
 	writeStats *replicaStats
+	// egressStats tracks the number of response bytes returned to clients and
+	// the localities of the gateways they were returned to, so that network
+	// egress can be attributed to ranges (and tables).
+	egressStats *replicaStats
 
 	// creatingReplica is set when a replica is created as uninitialized
 	// via a raft message.
@@ -296,11 +303,29 @@ type Replica struct {
 		stateLoader stateloader.StateLoader
 		// on-disk storage for sideloaded SSTables. nil when there's no ReplicaID.
 		sideloaded sideloadStorage
+		// The rangefeed processor serving the range's rangefeeds, if any. See
+		// replica_rangefeed.go.
+		rangefeed *rangefeed.Processor
 	}
 
 	// Contains the lease history when enabled.
 	leaseHistory *leaseHistory
 
+	replayMu struct {
+		syncutil.Mutex
+		// Remembers recently applied write batches carrying a request ID.
+		// Lazily initialized on the leaseholder; see replica_replay.go.
+		cache *replayCache
+	}
+
+	// Combines concurrent batched increments of the same key on the
+	// leaseholder; see replica_increment_batcher.go.
+	incBatcher incrementBatcher
+
+	// Decides when to split the range based on its load; see
+	// replica_split_load.go.
+	loadBasedSplitter split.Decider
+
 	cmdQMu struct {
 		// Protects all fields in the cmdQMu struct.
 		//
@@ -404,6 +429,14 @@ type Replica struct {
 		// The most recently updated time for each follower of this range.
 		lastUpdateTimes map[roachpb.ReplicaID]time.Time
 
+		// descPush tracks the most recent split or merge of the range, whose
+		// resulting range descriptors are attached to batch responses until
+		// expiration. See maybeAttachRangeDescriptors.
+		descPush struct {
+			expiration time.Time
+			rightDesc  *roachpb.RangeDescriptor
+		}
+
 		// The last seen replica descriptors from incoming Raft messages. These are
 		// stored so that the replica still knows the replica descriptors for itself
 		// and for its message recipients in the circumstances when its RangeDescriptor
@@ -542,6 +575,13 @@ func (r *Replica) withRaftGroupLocked(
 			return err
 		}
 		r.mu.internalRaftGroup = raftGroup
+		if !r.mu.quiescent {
+			// The replica isn't ticked until its Raft group exists. See
+			// Store.addReplicaToRangeMapLocked.
+			r.store.unquiescedReplicas.Lock()
+			r.store.unquiescedReplicas.m[r.RangeID] = struct{}{}
+			r.store.unquiescedReplicas.Unlock()
+		}
 
 		if mayCampaignOnWake {
 			r.maybeCampaignOnWakeLocked(ctx)
@@ -633,10 +673,12 @@ func newReplica(rangeID roachpb.RangeID, store *Store) *Replica {
 	}
 	if store.cfg.StorePool != nil {
 		r.leaseholderStats = newReplicaStats(store.Clock(), store.cfg.StorePool.getNodeLocalityString)
+		r.egressStats = newReplicaStats(store.Clock(), store.cfg.StorePool.getNodeLocalityString)
 	}
 	// Pass nil for the localityOracle because we intentionally don't track the
 	// origin locality of write load.
 	r.writeStats = newReplicaStats(store.Clock(), nil)
+	split.Init(&r.loadBasedSplitter, rand.Intn, r.splitByLoadQPSThreshold)
 
 	// Init rangeStr with the range ID.
 	r.rangeStr.store(0, &roachpb.RangeDescriptor{RangeID: rangeID})
@@ -718,6 +760,7 @@ func (r *Replica) initRaftMuLockedReplicaMuLocked(
 	}
 
 	r.rangeStr.store(0, r.mu.state.Desc)
+	r.store.scheduler.SetPriority(r.RangeID, raftPriorityForDesc(r.mu.state.Desc))
 
 	r.mu.lastIndex, err = r.mu.stateLoader.LoadLastIndex(ctx, r.store.Engine())
 	if err != nil {
@@ -771,6 +814,8 @@ func (r *Replica) destroyRaftMuLocked(
 ) error {
 	startTime := timeutil.Now()
 
+	r.disconnectRangefeedWithErrRaftMuLocked(roachpb.NewError(roachpb.NewRangeNotFoundError(r.RangeID)))
+
 	// Use a more efficient write-only batch because we don't need to do any
 	// reads from the batch.
 	batch := r.store.Engine().NewWriteOnlyBatch()
@@ -1153,7 +1198,10 @@ func (r *Replica) updateProposalQuotaRaftMuLocked(
 			if progress.Match < r.mu.proposalQuotaBaseIndex {
 				continue
 			}
-			if progress.Match > 0 && progress.Match < minIndex {
+			// Learners don't hold up proposals: they don't count towards quorum
+			// and may well be in a distant region.
+			if progress.Match > 0 && progress.Match < minIndex &&
+				rep.GetType() != roachpb.ReplicaType_LEARNER {
 				minIndex = progress.Match
 			}
 			// If this is the most recently added replica and it has caught up, clear
@@ -1299,8 +1347,9 @@ func (r *Replica) leaseGoodToGo(ctx context.Context) (LeaseStatus, bool) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	if r.requiresExpiringLeaseRLocked() {
-		// Slow-path for expiration-based leases.
+	if r.requiresExpiringLeaseRLocked() || r.mu.state.Lease.Type() == roachpb.LeaseExpiration {
+		// Slow-path for expiration-based leases, including the ones that are to
+		// be switched to epoch-based leases.
 		return LeaseStatus{}, false
 	}
 
@@ -1417,11 +1466,18 @@ func (r *Replica) redirectOnOrAcquireLease(ctx context.Context) (LeaseStatus, *r
 					return r.requestLeaseLocked(ctx, status), nil
 				}
 
-				// Extend the lease if this range uses expiration-based
+				// Switch the lease to the other type if it's no longer the
+				// one this range uses, which happens when
+				// kv.expiration_leases_only.enabled changes. Otherwise,
+				// extend the lease if this range uses expiration-based
 				// leases, the lease is in need of renewal, and there's not
 				// already an extension pending.
 				_, requestPending := r.mu.pendingLeaseRequest.RequestPending()
-				if !requestPending && r.requiresExpiringLeaseRLocked() {
+				expiring := r.requiresExpiringLeaseRLocked()
+				if !requestPending && expiring != (status.Lease.Type() == roachpb.LeaseExpiration) {
+					log.VEventf(ctx, 2, "switching the type of lease %s", status.Lease)
+					_ = r.requestLeaseLocked(ctx, status)
+				} else if !requestPending && expiring {
 					renewal := status.Lease.Expiration.Add(-r.store.cfg.RangeLeaseRenewalDuration().Nanoseconds(), 0)
 					if !timestamp.Less(renewal) {
 						if log.V(2) {
@@ -1642,6 +1698,18 @@ func (r *Replica) GetTxnSpanGCThreshold() hlc.Timestamp {
 	return *r.mu.state.TxnSpanGCThreshold
 }
 
+// GetProtectedTimestamp returns the earliest timestamp protected by a
+// protected timestamp record overlapping the range, or the zero timestamp if
+// there's none.
+func (r *Replica) GetProtectedTimestamp() hlc.Timestamp {
+	cache := r.store.cfg.ProtectedTimestampCache
+	if cache == nil {
+		return hlc.Timestamp{}
+	}
+	protected, _ := cache.Protected(r.Desc().RSpan().AsRawSpanWithNoLocals())
+	return protected
+}
+
 // setDesc atomically sets the range's descriptor. This method calls
 // processRangeDescriptorUpdate() to make the Store handle the descriptor
 // update. Requires raftMu to be locked.
@@ -1680,6 +1748,9 @@ func (r *Replica) setDescWithoutProcessUpdate(desc *roachpb.RangeDescriptor) {
 
 	r.rangeStr.store(r.mu.replicaID, desc)
 	r.mu.state.Desc = desc
+	if r.store != nil {
+		r.store.scheduler.SetPriority(r.RangeID, raftPriorityForDesc(desc))
+	}
 }
 
 func maxReplicaID(desc *roachpb.RangeDescriptor) roachpb.ReplicaID {
@@ -1916,6 +1987,7 @@ func (r *Replica) Send(
 	if r.leaseholderStats != nil && ba.Header.GatewayNodeID != 0 {
 		r.leaseholderStats.record(ba.Header.GatewayNodeID)
 	}
+	r.recordLoadForSplit(ba)
 
 	// Add the range log tag.
 	ctx = r.AnnotateCtx(ctx)
@@ -1947,7 +2019,13 @@ func (r *Replica) Send(
 	var pErr *roachpb.Error
 	if useRaft {
 		log.Event(ctx, "read-write path")
-		br, pErr = r.executeWriteBatch(ctx, ba)
+		if ba.IsSingleBatchedIncrementRequest() && (ba.RequestID == nil || !ba.RequestID.Retry) {
+			// A retried batch must go through replay detection on its own.
+			br, pErr = r.incBatcher.increment(
+				ctx, r.AnnotateCtx(context.Background()), ba, r.executeWriteBatch)
+		} else {
+			br, pErr = r.executeWriteBatch(ctx, ba, nil /* combined */)
+		}
 	} else if isReadOnly {
 		log.Event(ctx, "read-only path")
 		br, pErr = r.executeReadOnlyBatch(ctx, ba)
@@ -1973,6 +2051,9 @@ func (r *Replica) Send(
 		if filter := r.store.cfg.TestingKnobs.TestingResponseFilter; filter != nil {
 			pErr = filter(ba, br)
 		}
+		if r.egressStats != nil && ba.Header.GatewayNodeID != 0 && br != nil {
+			r.egressStats.recordCount(float64(br.Size()), ba.Header.GatewayNodeID)
+		}
 	}
 	return br, pErr
 }
@@ -2055,6 +2136,8 @@ type endCmds struct {
 	repl *Replica
 	cmds batchCmdSet
 	ba   roachpb.BatchRequest
+	// combined is set if ba is a combined increment.
+	combined *combinedIncrements
 }
 
 // done removes pending commands from the command queue and updates
@@ -2067,6 +2150,15 @@ func (ec *endCmds) done(br *roachpb.BatchResponse, pErr *roachpb.Error, retry pr
 	if retry == proposalNoRetry && ec.ba.ReadConsistency == roachpb.CONSISTENT {
 		ec.repl.updateTimestampCache(&ec.ba, br, pErr)
 	}
+	// Remember the response of batches which the DistSender may retry after
+	// an ambiguous failure. This must happen before the commands are removed
+	// from the command queue, which unblocks any such retry.
+	if retry == proposalNoRetry {
+		ec.repl.maybeRecordReplay(&ec.ba, br, pErr)
+		if ec.combined != nil {
+			ec.repl.maybeRecordCombinedReplays(ec.combined, br, pErr)
+		}
+	}
 
 	if fn := ec.repl.store.cfg.TestingKnobs.OnCommandQueueAction; fn != nil {
 		fn(&ec.ba, storagebase.CommandQueueFinishExecuting)
@@ -2135,6 +2227,12 @@ func (r *Replica) updateTimestampCache(
 					end = resp.ResumeSpan.Key
 				}
 				tc.Add(start, end, ts, txnID, readOnlyUseReadCache)
+			case *roachpb.ScanAndLockRequest:
+				resp := br.Responses[i].GetInner().(*roachpb.ScanAndLockResponse)
+				if resp.ResumeSpan != nil {
+					end = resp.ResumeSpan.Key
+				}
+				tc.Add(start, end, ts, txnID, readOnlyUseReadCache)
 			case *roachpb.ReverseScanRequest:
 				resp := br.Responses[i].GetInner().(*roachpb.ReverseScanResponse)
 				if resp.ResumeSpan != nil {
@@ -2797,11 +2895,12 @@ func (r *Replica) executeReadOnlyBatch(
 	}
 	defer readOnly.Close()
 	br, result, pErr = evaluateBatch(ctx, storagebase.CmdIDKey(""), readOnly, rec, nil, ba)
+	if br != nil {
+		br.KeysRead, br.BytesRead = readStats(br)
+	}
 
-	if result.Local.DetachSetMerging() {
-		if err := r.maybeWatchForMerge(ctx); err != nil {
-			return nil, roachpb.NewError(err)
-		}
+	if err := r.handleTriggers(ctx, result.Local.DetachTriggers()); err != nil {
+		return nil, roachpb.NewError(err)
 	}
 
 	if intents := result.Local.DetachIntents(); len(intents) > 0 {
@@ -2827,6 +2926,26 @@ func (r *Replica) executeReadOnlyBatch(
 	return br, pErr
 }
 
+// readStats returns the number of keys and the number of bytes of the keys and
+// values read by the requests of a batch, as reported by the requests that
+// support limits, and by Gets.
+func readStats(br *roachpb.BatchResponse) (keysRead, bytesRead int64) {
+	for _, union := range br.Responses {
+		switch resp := union.GetInner().(type) {
+		case *roachpb.GetResponse:
+			if resp.Value != nil {
+				keysRead++
+				bytesRead += int64(len(resp.Value.RawBytes))
+			}
+		default:
+			h := resp.Header()
+			keysRead += h.NumKeys
+			bytesRead += h.NumBytes
+		}
+	}
+	return keysRead, bytesRead
+}
+
 // executeWriteBatch is the entry point for client requests which may mutate the
 // range's replicated state. Requests taking this path are ultimately
 // serialized through Raft, but pass through additional machinery whose goal is
@@ -2837,12 +2956,15 @@ func (r *Replica) executeReadOnlyBatch(
 // Internally, multiple iterations of the above process may take place
 // due to the Raft proposal failing retryably, possibly due to proposal
 // reordering or re-proposals.
+//
+// If ba is a combined increment, combined describes its members; see
+// incrementBatcher.
 func (r *Replica) executeWriteBatch(
-	ctx context.Context, ba roachpb.BatchRequest,
+	ctx context.Context, ba roachpb.BatchRequest, combined *combinedIncrements,
 ) (*roachpb.BatchResponse, *roachpb.Error) {
 	var ambiguousResult bool
 	for count := 0; ; count++ {
-		br, pErr, retry := r.tryExecuteWriteBatch(ctx, ba)
+		br, pErr, retry := r.tryExecuteWriteBatch(ctx, ba, combined)
 		switch retry {
 		case proposalIllegalLeaseIndex:
 			continue // retry
@@ -2915,13 +3037,26 @@ func (r *Replica) executeWriteBatch(
 // as this method makes the assumption that it operates on a shallow copy (see
 // call to applyTimestampCache).
 func (r *Replica) tryExecuteWriteBatch(
-	ctx context.Context, ba roachpb.BatchRequest,
+	ctx context.Context, ba roachpb.BatchRequest, combined *combinedIncrements,
 ) (br *roachpb.BatchResponse, pErr *roachpb.Error, retry proposalRetryReason) {
 	startTime := timeutil.Now()
 
 	if err := r.maybeBackpressureWriteBatch(ctx, ba); err != nil {
 		return nil, roachpb.NewError(err), proposalNoRetry
 	}
+	if err := r.checkDiskFull(ctx, ba); err != nil {
+		return nil, roachpb.NewError(err), proposalNoRetry
+	}
+
+	// An EndTransaction resolves intents once it applies, so its batch must
+	// wait for consensus.
+	if ba.AsyncConsensus {
+		if _, ok := ba.GetArg(roachpb.EndTransaction); ok {
+			return nil, roachpb.NewErrorf(
+				"cannot perform consensus asynchronously for batch containing EndTransaction: %s", ba,
+			), proposalNoRetry
+		}
+	}
 
 	spans, err := collectSpans(*r.Desc(), &ba)
 	if err != nil {
@@ -2941,6 +3076,7 @@ func (r *Replica) tryExecuteWriteBatch(
 		if err != nil {
 			return nil, roachpb.NewError(err), proposalNoRetry
 		}
+		endCmds.combined = combined
 	}
 
 	// Guarantee we remove the commands from the command queue. This is
@@ -2966,6 +3102,21 @@ func (r *Replica) tryExecuteWriteBatch(
 	}
 	r.limitTxnMaxTimestamp(ctx, &ba, status)
 
+	// Consult the replay cache for any batch the DistSender may have sent
+	// more than once: not only a retry may find that an earlier attempt has
+	// been applied, but also an earlier attempt which arrives after a retry.
+	// The command queue guarantees that any other attempt proposed here has
+	// finished applying.
+	if ba.RequestID != nil && ba.RequiresReplayDetection() {
+		if br, pErr := r.checkForReplay(ctx, &ba, lease); br != nil || pErr != nil {
+			return br, pErr, proposalNoRetry
+		}
+	}
+	if combined != nil && r.checkCombinedForReplay(ctx, combined, lease) {
+		combined.replayed = true
+		return nil, roachpb.NewErrorf("combined increment contains a replay"), proposalNoRetry
+	}
+
 	// Examine the read and write timestamp caches for preceding
 	// commands which require this command to move its timestamp
 	// forward. Or, in the case of a transactional write, the txn
@@ -3366,6 +3517,15 @@ func (r *Replica) propose(
 		return nil, nil, roachpb.NewError(err)
 	}
 
+	// Account for the proposal in the reply, so that the client can attribute
+	// the work to the statement that caused it.
+	if br := proposal.Local.Reply; br != nil {
+		br.RaftCommands = 1
+		if wb := proposal.command.WriteBatch; wb != nil {
+			br.BytesWritten = int64(len(wb.Data))
+		}
+	}
+
 	// submitProposalLocked calls withRaftGroupLocked which requires that
 	// raftMu is held. In order to maintain our lock ordering we need to lock
 	// Replica.raftMu here before locking Replica.mu.
@@ -3429,6 +3589,31 @@ func (r *Replica) propose(
 	} else if err != nil {
 		return nil, nil, roachpb.NewError(err)
 	}
+
+	// If the batch requested asynchronous consensus, signal the proposer with
+	// the evaluated response right away. The command stays in the command
+	// queue until it applies, so overlapping requests still wait for it. If it
+	// fails to apply, its writes will be missing and the transaction's
+	// coordinator finds out when it tries to prove them.
+	if ba.AsyncConsensus {
+		if ets := proposal.Local.DetachEndTxns(false /* alwaysOnly */); len(ets) != 0 {
+			// The intents of an EndTransaction can't be resolved until the
+			// command has applied; tryExecuteWriteBatch rejects such batches.
+			log.Fatalf(ctx, "cannot perform consensus asynchronously for proposal with EndTxns=%v; %s", ets, ba)
+		}
+		ch := proposal.doneCh
+		// Fork the proposal's context span so that it can outlive the
+		// proposer's context.
+		proposal.ctx, proposal.sp = tracing.ForkCtxSpan(ctx, "async consensus")
+		reply := *proposal.Local.Reply
+		reply.Responses = append([]roachpb.ResponseUnion(nil), reply.Responses...)
+		proposal.signalProposalResult(proposalResult{
+			Reply:   &reply,
+			Intents: proposal.Local.DetachIntents(),
+		})
+		return ch, func() bool { return false }, nil
+	}
+
 	// Must not use `proposal` in the closure below as a proposal which is not
 	// present in r.mu.proposals is no longer protected by the mutex. Abandoning
 	// a command only abandons the associated context. As soon as we propose a
@@ -4624,12 +4809,17 @@ func (r *Replica) sendRaftMessage(ctx context.Context, msg raftpb.Message) {
 		return
 	}
 
-	if !r.sendRaftMessageRequest(ctx, &RaftMessageRequest{
+	req := &RaftMessageRequest{
 		RangeID:     r.RangeID,
 		ToReplica:   toReplica,
 		FromReplica: fromReplica,
 		Message:     msg,
-	}) {
+	}
+	if err := maybeCompressRaftEntries(r.store.cfg.Settings, req); err != nil {
+		log.Warningf(ctx, "failed to compress entries of %s to replica %d in r%d: %s",
+			msg.Type, msg.To, r.RangeID, err)
+	}
+	if !r.sendRaftMessageRequest(ctx, req) {
 		if err := r.withRaftGroup(true, func(raftGroup *raft.RawNode) (bool, error) {
 			r.mu.droppedMessages++
 			raftGroup.ReportUnreachable(msg.To)
@@ -5063,6 +5253,10 @@ func (r *Replica) processRaftCommand(
 			}
 			response.Intents = proposal.Local.DetachIntents()
 			response.EndTxns = proposal.Local.DetachEndTxns(response.Err != nil)
+			if response.Err != nil {
+				// Triggers are only handled for commands which applied.
+				_ = proposal.Local.DetachTriggers()
+			}
 			lResult = proposal.Local
 		}
 
@@ -5361,9 +5555,16 @@ func (r *Replica) applyRaftCommand(
 		}
 		assertHS = &oldHS
 	}
+	var consumeRangefeedOps func()
+	if writeBatch != nil {
+		consumeRangefeedOps = r.handleRangefeedWriteBatchRaftMuLocked(ctx, writeBatch.Data)
+	}
 	if err := batch.Commit(false); err != nil {
 		return enginepb.MVCCStats{}, errors.Wrap(err, "could not commit batch")
 	}
+	if consumeRangefeedOps != nil {
+		consumeRangefeedOps()
+	}
 
 	if assertHS != nil {
 		// Load the HardState that was just committed (if any).
@@ -5707,6 +5908,9 @@ func evaluateBatch(
 		// remaining keys we can touch.
 		maxKeys = ba.Header.MaxSpanRequestKeys
 	}
+	// If the batch has a byte target, targetBytes keeps track of how many
+	// more bytes scans may return before the target is reached.
+	targetBytes := ba.Header.TargetBytes
 
 	// Optimize any contiguous sequences of put and conditional put ops.
 	if len(ba.Requests) >= optimizePutThreshold {
@@ -5742,6 +5946,15 @@ func evaluateBatch(
 		}
 	}
 
+	// Register the memory allocated by the commands in the batch with the
+	// store's evaluation memory monitor until the batch has been evaluated.
+	var memAcc *mon.BoundAccount
+	if evalMem := rec.GetLimiters().EvalMemory; evalMem != nil {
+		acc := evalMem.MakeBoundAccount()
+		memAcc = &acc
+		defer memAcc.Close(ctx)
+	}
+
 	var result result.Result
 	var writeTooOldErr *roachpb.Error
 	returnWriteTooOldErr := true
@@ -5782,7 +5995,18 @@ func evaluateBatch(
 		// Note that responses are populated even when an error is returned.
 		// TODO(tschottdorf): Change that. IIRC there is nontrivial use of it currently.
 		reply := br.Responses[index].GetInner()
-		curResult, pErr := evaluateCommand(ctx, idKey, index, batch, rec, ms, ba.Header, maxKeys, args, reply)
+		if ba.Header.TargetBytes > 0 && targetBytes <= 0 && roachpb.IsRange(args) {
+			// The byte target was reached by an earlier request. Don't
+			// evaluate this span request; have the client resume it instead.
+			h := reply.Header()
+			h.ResumeSpan = &roachpb.Span{Key: args.Header().Key, EndKey: args.Header().EndKey}
+			h.ResumeReason = roachpb.RESUME_BYTE_LIMIT
+			reply.SetHeader(h)
+			continue
+		}
+		curResult, pErr := evaluateCommand(
+			ctx, idKey, index, batch, rec, ms, ba.Header, maxKeys, targetBytes, memAcc, args, reply,
+		)
 
 		if err := result.MergeAndDestroy(curResult); err != nil {
 			// TODO(tschottdorf): see whether we really need to pass nontrivial
@@ -5870,6 +6094,9 @@ func evaluateBatch(
 			}
 			maxKeys -= retResults
 		}
+		if targetBytes > 0 {
+			targetBytes -= reply.Header().NumBytes
+		}
 
 		// If transactional, we use ba.Txn for each individual command and
 		// accumulate updates to it.
@@ -6249,6 +6476,9 @@ type ReplicaMetrics struct {
 
 	// Quiescent indicates whether the replica believes itself to be quiesced.
 	Quiescent bool
+	// Dormant indicates whether the replica's Raft group hasn't been created
+	// yet. Dormant replicas are also quiescent.
+	Dormant bool
 	// Ticking indicates whether the store is ticking the replica. It should be
 	// the opposite of Quiescent.
 	Ticking bool
@@ -6273,7 +6503,8 @@ func (r *Replica) Metrics(
 	r.mu.RLock()
 	raftStatus := r.raftStatusRLocked()
 	leaseStatus := r.leaseStatus(*r.mu.state.Lease, now, r.mu.minLeaseProposedTS)
-	quiescent := r.mu.quiescent || r.mu.internalRaftGroup == nil
+	dormant := r.mu.internalRaftGroup == nil
+	quiescent := r.mu.quiescent || dormant
 	desc := r.mu.state.Desc
 	r.cmdQMu.Lock()
 	cmdQMetricsLocal := r.cmdQMu.queues[spanset.SpanLocal].metrics()
@@ -6285,7 +6516,7 @@ func (r *Replica) Metrics(
 	_, ticking := r.store.unquiescedReplicas.m[r.RangeID]
 	r.store.unquiescedReplicas.Unlock()
 
-	return calcReplicaMetrics(
+	m := calcReplicaMetrics(
 		ctx,
 		now,
 		cfg,
@@ -6299,6 +6530,8 @@ func (r *Replica) Metrics(
 		cmdQMetricsLocal,
 		cmdQMetricsGlobal,
 	)
+	m.Dormant = dormant
+	return m
 }
 
 func isRaftLeader(raftStatus *raft.Status) bool {
@@ -6359,7 +6592,7 @@ func calcReplicaMetrics(
 	// unavailable ranges for each range based on the liveness table.
 	if m.RangeCounter {
 		liveReplicas := calcLiveReplicas(desc, livenessMap)
-		if liveReplicas < computeQuorum(len(desc.Replicas)) {
+		if liveReplicas < computeQuorum(len(desc.Voters())) {
 			m.Unavailable = true
 		}
 		if zoneConfig, err := cfg.GetZoneConfigForKey(desc.StartKey); err != nil {
@@ -6381,11 +6614,11 @@ func calcReplicaMetrics(
 	return m
 }
 
-// calcLiveReplicas returns a count of the live replicas; a live replica is
-// determined by checking its node in the provided liveness map.
+// calcLiveReplicas returns a count of the live voting replicas; a live replica
+// is determined by checking its node in the provided liveness map.
 func calcLiveReplicas(desc *roachpb.RangeDescriptor, livenessMap map[roachpb.NodeID]bool) int {
 	var goodReplicas int
-	for _, rd := range desc.Replicas {
+	for _, rd := range desc.Voters() {
 		if livenessMap[rd.NodeID] {
 			goodReplicas++
 		}
@@ -6425,6 +6658,21 @@ func (r *Replica) WritesPerSecond() float64 {
 	return wps
 }
 
+// EgressBytesPerSecond returns the rate at which response bytes have been
+// returned to clients by this replica, keyed by the locality of the gateway
+// node that issued the requests. Only the leaseholder serves most requests,
+// so followers will typically report little or no egress.
+func (r *Replica) EgressBytesPerSecond() map[string]float64 {
+	if r.egressStats == nil {
+		return nil
+	}
+	counts, dur := r.egressStats.perLocalityDecayingQPS()
+	if dur < MinStatsDuration {
+		return nil
+	}
+	return counts
+}
+
 // GetLeaseHistory returns the lease history stored on this replica.
 func (r *Replica) GetLeaseHistory() []roachpb.Lease {
 	if r.leaseHistory == nil {
@@ -29,12 +29,14 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/storage/batcheval"
 	"github.com/cockroachdb/cockroach/pkg/storage/batcheval/result"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/retry"
 )
@@ -52,6 +54,8 @@ func evaluateCommand(
 	ms *enginepb.MVCCStats,
 	h roachpb.Header,
 	maxKeys int64,
+	targetBytes int64,
+	memAcc *mon.BoundAccount,
 	args roachpb.Request,
 	reply roachpb.Response,
 ) (result.Result, *roachpb.Error) {
@@ -86,9 +90,11 @@ func evaluateCommand(
 			// Some commands mutate their arguments, so give each invocation
 			// its own copy (shallow to mimic earlier versions of this code
 			// in which args were passed by value instead of pointer).
-			Args:    args.ShallowCopy(),
-			MaxKeys: maxKeys,
-			Stats:   ms,
+			Args:        args.ShallowCopy(),
+			MaxKeys:     maxKeys,
+			TargetBytes: targetBytes,
+			Stats:       ms,
+			EvalMemAcc:  memAcc,
 		}
 		pd, err = cmd.Eval(ctx, batch, cArgs, reply)
 	} else {
@@ -660,7 +666,14 @@ func (r *Replica) changeReplicas(
 	updatedDesc.Replicas = append([]roachpb.ReplicaDescriptor(nil), desc.Replicas...)
 
 	switch changeType {
-	case roachpb.ADD_REPLICA:
+	case roachpb.ADD_REPLICA, roachpb.ADD_LEARNER:
+		if changeType == roachpb.ADD_LEARNER {
+			if !r.store.ClusterSettings().Version.IsActive(cluster.VersionLearnerReplicas) {
+				return errors.Errorf("%s: unable to add learner replica %v; not all nodes support learners", r, repDesc)
+			}
+			repDesc.Type = roachpb.ReplicaType_LEARNER.Enum()
+		}
+
 		// If the replica exists on the remote node, no matter in which store,
 		// abort the replica add.
 		if nodeUsed {
@@ -939,6 +952,11 @@ func updateRangeDescriptor(
 // RelocateRange relocates a given range to a given set of stores. The first
 // store in the slice becomes the new leaseholder.
 //
+// The replicas are added and removed one at a time, interleaving the two, so
+// that the range never has many more (or fewer) replicas than it will end up
+// with, which keeps the window in which a failure can make it unavailable
+// small.
+//
 // This is best-effort; if replication queues are enabled and a change in
 // membership happens at the same time, there will be errors.
 func RelocateRange(
@@ -947,12 +965,13 @@ func RelocateRange(
 	rangeDesc roachpb.RangeDescriptor,
 	targets []roachpb.ReplicationTarget,
 ) error {
-	// Step 1: Add any stores that don't already have a replica of the range.
-	//
 	// TODO(radu): we can't have multiple replicas on different stores on the same
 	// node, which can lead to some odd corner cases where we would have to first
 	// remove some replicas (currently these cases fail).
 
+	// The stores that don't already have a replica of the range. They keep the
+	// order of the targets, so the first target, which gets the lease, is
+	// added first.
 	var addTargets []roachpb.ReplicationTarget
 	for _, t := range targets {
 		found := false
@@ -967,6 +986,24 @@ func RelocateRange(
 		}
 	}
 
+	// The replicas that are not targets.
+	var removeTargets []roachpb.ReplicationTarget
+	for _, replicaDesc := range rangeDesc.Replicas {
+		found := false
+		for _, t := range targets {
+			if replicaDesc.StoreID == t.StoreID && replicaDesc.NodeID == t.NodeID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			removeTargets = append(removeTargets, roachpb.ReplicationTarget{
+				StoreID: replicaDesc.StoreID,
+				NodeID:  replicaDesc.NodeID,
+			})
+		}
+	}
+
 	canRetry := func(err error) bool {
 		whitelist := []string{
 			snapshotApplySemBusyMsg,
@@ -980,28 +1017,8 @@ func RelocateRange(
 		return false
 	}
 
-	for len(addTargets) > 0 {
-		if err := ctx.Err(); err != nil {
-			return err
-		}
-
-		target := addTargets[0]
-		if err := db.AdminChangeReplicas(
-			ctx, rangeDesc.StartKey.AsRawKey(), roachpb.ADD_REPLICA, []roachpb.ReplicationTarget{target},
-		); err != nil {
-			returnErr := errors.Wrapf(err, "while adding target %v", target)
-			if !canRetry(err) {
-				return returnErr
-			}
-			log.Warning(ctx, returnErr)
-			continue
-		}
-		addTargets = addTargets[1:]
-	}
-
-	// Step 2: Transfer the lease to the first target. This needs to happen before
-	// we remove replicas or we may try to remove the lease holder.
-
+	// The lease is transferred to the first target before removing any
+	// replica, or we may try to remove the lease holder.
 	transferLease := func() {
 		if err := db.AdminTransferLease(
 			ctx, rangeDesc.StartKey.AsRawKey(), targets[0].StoreID,
@@ -1010,45 +1027,52 @@ func RelocateRange(
 		}
 	}
 
-	transferLease()
-
-	// Step 3: Remove any replicas that are not targets.
-
-	var removeTargets []roachpb.ReplicationTarget
-	for _, replicaDesc := range rangeDesc.Replicas {
-		found := false
-		for _, t := range targets {
-			if replicaDesc.StoreID == t.StoreID && replicaDesc.NodeID == t.NodeID {
-				found = true
-				break
-			}
-		}
-		if !found {
-			removeTargets = append(removeTargets, roachpb.ReplicationTarget{
-				StoreID: replicaDesc.StoreID,
-				NodeID:  replicaDesc.NodeID,
-			})
-		}
-	}
-
-	for len(removeTargets) > 0 {
+	numReplicas := len(rangeDesc.Replicas)
+	for len(addTargets) > 0 || len(removeTargets) > 0 {
 		if err := ctx.Err(); err != nil {
 			return err
 		}
 
-		target := removeTargets[0]
-		transferLease()
-		if err := db.AdminChangeReplicas(
-			ctx, rangeDesc.StartKey.AsRawKey(), roachpb.REMOVE_REPLICA, []roachpb.ReplicationTarget{target},
-		); err != nil {
-			log.Warningf(ctx, "while removing target %v: %s", target, err)
-			if !canRetry(err) {
-				return err
+		// Add a replica unless the range already has more replicas than it
+		// will end up with, in which case one is removed first. The first
+		// target is always added first, as it needs to have a replica to
+		// receive the lease.
+		if len(addTargets) > 0 && (len(removeTargets) == 0 || numReplicas <= len(targets) ||
+			addTargets[0] == targets[0]) {
+			target := addTargets[0]
+			if err := db.AdminChangeReplicas(
+				ctx, rangeDesc.StartKey.AsRawKey(), roachpb.ADD_REPLICA, []roachpb.ReplicationTarget{target},
+			); err != nil {
+				returnErr := errors.Wrapf(err, "while adding target %v", target)
+				if !canRetry(err) {
+					return returnErr
+				}
+				log.Warning(ctx, returnErr)
+				continue
 			}
-			continue
+			addTargets = addTargets[1:]
+			numReplicas++
+		}
+
+		if len(removeTargets) > 0 {
+			target := removeTargets[0]
+			transferLease()
+			if err := db.AdminChangeReplicas(
+				ctx, rangeDesc.StartKey.AsRawKey(), roachpb.REMOVE_REPLICA, []roachpb.ReplicationTarget{target},
+			); err != nil {
+				log.Warningf(ctx, "while removing target %v: %s", target, err)
+				if !canRetry(err) {
+					return err
+				}
+				continue
+			}
+			removeTargets = removeTargets[1:]
+			numReplicas--
 		}
-		removeTargets = removeTargets[1:]
 	}
+
+	// Transfer the lease even if no replica had to be removed.
+	transferLease()
 	return ctx.Err()
 }
 
@@ -1107,13 +1131,19 @@ func (r *Replica) adminScatter(
 		}
 	}
 
+	// Report where the range ended up, so that the caller can check the
+	// placement of the replicas and leases.
 	desc := r.Desc()
+	lease, _ := r.GetLease()
 	return roachpb.AdminScatterResponse{
 		Ranges: []roachpb.AdminScatterResponse_Range{{
 			Span: roachpb.Span{
 				Key:    desc.StartKey.AsRawKey(),
 				EndKey: desc.EndKey.AsRawKey(),
 			},
+			RangeID:     desc.RangeID,
+			Replicas:    append([]roachpb.ReplicaDescriptor(nil), desc.Replicas...),
+			LeaseHolder: lease.Replica,
 		}},
 	}, nil
 }
@@ -0,0 +1,157 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+var diskFullLogLimiter = log.Every(10 * time.Second)
+
+// diskFullReadOnlyThreshold is the fraction of a store's capacity that may be
+// used before the store stops accepting writes that grow user data. The
+// allocator already stops placing replicas on, and starts moving replicas
+// off of, stores above maxFractionUsedThreshold; this is the last line of
+// defense for when rebalancing can't keep up, and it keeps the store
+// serving reads rather than letting it crash once the disk is exhausted.
+// Set to 0 to disable.
+var diskFullReadOnlyThreshold = settings.RegisterValidatedFloatSetting(
+	"kv.store.read_only_fraction_used",
+	"fraction of a store's capacity that can be used before writes to user data "+
+		"on that store are rejected, or 0 to disable",
+	0.99,
+	func(v float64) error {
+		if v != 0 && (v < maxFractionUsedThreshold || v > 1) {
+			return errors.Errorf("read only fraction must be between %.2f and 1: %f",
+				maxFractionUsedThreshold, v)
+		}
+		return nil
+	},
+)
+
+// diskGrowingReqMethods is the set of all request methods that are rejected
+// when a store's disk is full. Deletions, transaction bookkeeping, intent
+// resolution, GC and lease requests are still allowed so that the store can
+// reclaim space and ranges remain available for reads.
+var diskGrowingReqMethods = util.MakeFastIntSet(
+	int(roachpb.Put),
+	int(roachpb.InitPut),
+	int(roachpb.ConditionalPut),
+	int(roachpb.Merge),
+	int(roachpb.Increment),
+	int(roachpb.WriteBatch),
+	int(roachpb.AddSSTable),
+)
+
+// diskFullRejectableSpan contains the keys to which writes may be rejected
+// when the disk is full. System data (node liveness, range descriptors,
+// timeseries, system tables, ...) is never rejected because the cluster
+// can't function, let alone rebalance away from the store, without it.
+var diskFullRejectableSpan = roachpb.Span{
+	Key: keys.UserTableDataMin, EndKey: keys.TableDataMax,
+}
+
+// errDiskFull is returned for writes rejected because the store's disk is
+// full.
+type errDiskFull struct {
+	storeID       roachpb.StoreID
+	fractionUsed  float64
+	readOnlyLimit float64
+}
+
+func (e *errDiskFull) Error() string {
+	return fmt.Sprintf(
+		"store %d is read only: %.2f%% of capacity used exceeds the %.2f%% limit "+
+			"(kv.store.read_only_fraction_used)",
+		e.storeID, e.fractionUsed*100, e.readOnlyLimit*100,
+	)
+}
+
+// canRejectBatchOnFullDisk returns whether the provided BatchRequest may be
+// rejected while the store's disk is full. A batch is rejectable if any of
+// its requests grows user data.
+func canRejectBatchOnFullDisk(ba roachpb.BatchRequest) bool {
+	for _, union := range ba.Requests {
+		req := union.GetInner()
+		if !diskGrowingReqMethods.Contains(int(req.Method())) {
+			continue
+		}
+		if diskFullRejectableSpan.Overlaps(req.Header().Span()) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDiskFull returns whether the store has exceeded
+// kv.store.read_only_fraction_used and is rejecting writes to user data.
+func (s *Store) IsDiskFull() bool {
+	return atomic.LoadInt32(&s.diskFull) == 1
+}
+
+// updateDiskFull recomputes whether the store's disk is full based on the
+// provided capacity. It is called periodically along with the capacity
+// metrics.
+func (s *Store) updateDiskFull(ctx context.Context, capacity roachpb.StoreCapacity) {
+	limit := diskFullReadOnlyThreshold.Get(&s.cfg.Settings.SV)
+	fractionUsed := capacity.FractionUsed()
+	full := limit != 0 && fractionUsed > limit
+
+	var v int32
+	if full {
+		v = 1
+	}
+	s.metrics.DiskFull.Update(int64(v))
+	if old := atomic.SwapInt32(&s.diskFull, v); old != v {
+		if full {
+			log.Errorf(ctx, "%.2f%% of capacity used exceeds %.2f%%; rejecting writes to user data "+
+				"until space is reclaimed or replicas are rebalanced away", fractionUsed*100, limit*100)
+		} else {
+			log.Infof(ctx, "%.2f%% of capacity used; accepting writes again", fractionUsed*100)
+		}
+	}
+}
+
+// checkDiskFull returns an error if the batch would grow user data on a store
+// whose disk is full.
+func (r *Replica) checkDiskFull(ctx context.Context, ba roachpb.BatchRequest) error {
+	if !r.store.IsDiskFull() || !canRejectBatchOnFullDisk(ba) {
+		return nil
+	}
+	r.store.metrics.DiskFullRejectedRequests.Inc(1)
+	if diskFullLogLimiter.ShouldLog() {
+		log.Warningf(ctx, "rejecting batch %s: disk full", ba)
+	}
+	var fractionUsed float64
+	if desc, err := r.store.Descriptor(); err == nil {
+		fractionUsed = desc.Capacity.FractionUsed()
+	}
+	return &errDiskFull{
+		storeID:       r.store.StoreID(),
+		fractionUsed:  fractionUsed,
+		readOnlyLimit: diskFullReadOnlyThreshold.Get(&r.store.cfg.Settings.SV),
+	}
+}
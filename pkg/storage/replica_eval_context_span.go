@@ -158,6 +158,12 @@ func (rec SpanSetReplicaEvalContext) GetTxnSpanGCThreshold() hlc.Timestamp {
 	return rec.i.GetTxnSpanGCThreshold()
 }
 
+// GetProtectedTimestamp returns the earliest timestamp protected by a
+// protected timestamp record overlapping the Range.
+func (rec SpanSetReplicaEvalContext) GetProtectedTimestamp() hlc.Timestamp {
+	return rec.i.GetProtectedTimestamp()
+}
+
 // String implements Stringer.
 func (rec SpanSetReplicaEvalContext) String() string {
 	return rec.i.String()
@@ -0,0 +1,242 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// An incrementBatcher combines concurrent batched increments of the same key
+// (see IncrementRequest.Batched) into a single increment, so that a hot
+// counter such as a SQL sequence costs one Raft command per group of
+// concurrent callers instead of one per caller.
+//
+// It uses a group commit scheme: while an increment of a key is in flight,
+// increments of that key which arrive queue up in a pending group. When the
+// in-flight increment completes, the first member of the pending group sends
+// a single increment carrying the sum of the group's deltas, and every member
+// of the group derives its own result from the combined new value.
+//
+// A member sends its increment on its own instead if adding its delta would
+// overflow the combined delta. All members do if the combined increment
+// overflows the counter, or if one of them turns out to have been applied
+// already (see combinedIncrements).
+type incrementBatcher struct {
+	mu struct {
+		syncutil.Mutex
+		// pending holds, for each key, the group which is accepting new
+		// members. It is created lazily.
+		pending map[string]*incrementGroup
+		// inflight holds, for each key, the group whose increment is being
+		// sent, if any.
+		inflight map[string]*incrementGroup
+	}
+}
+
+// An incrementGroup is a set of increments of the same key which are sent
+// together.
+type incrementGroup struct {
+	members []roachpb.BatchRequest
+	// combinedIdx maps each member to its index in combined, or to -1 if the
+	// member has to send its increment on its own.
+	combinedIdx []int
+	combined    combinedIncrements
+	// done is closed once br and pErr are set.
+	done chan struct{}
+	br   *roachpb.BatchResponse
+	pErr *roachpb.Error
+}
+
+// combinedIncrements describes the members of a combined increment. The
+// combined batch carries no request ID of its own. Instead, the replica checks
+// the members' IDs for replays and records each member's own response under
+// its ID, so that a member which the DistSender sends again is detected like
+// any other batch.
+type combinedIncrements struct {
+	// ids holds the members' request IDs, or nil for members without one.
+	ids    []*roachpb.RequestID
+	deltas []int64
+	// replayed is set by the replica, in which case the combined increment
+	// hasn't been proposed, if a member has been applied already.
+	replayed bool
+}
+
+// memberResponse returns the response for the member at index idx, given the
+// response to the combined increment. The combined increment applies the
+// deltas in order, so the value the member observed is the combined new value
+// minus the deltas which follow it.
+func (c *combinedIncrements) memberResponse(
+	br *roachpb.BatchResponse, idx int,
+) *roachpb.BatchResponse {
+	resp := *br.Responses[0].GetInner().(*roachpb.IncrementResponse)
+	for _, delta := range c.deltas[idx+1:] {
+		resp.NewValue -= delta
+	}
+	memberBr := *br
+	memberBr.Responses = make([]roachpb.ResponseUnion, 1)
+	memberBr.Responses[0].MustSetInner(&resp)
+	return &memberBr
+}
+
+// incrementSendFunc sends an increment batch, which combines the members
+// described by combined if it is not nil.
+type incrementSendFunc func(
+	ctx context.Context, ba roachpb.BatchRequest, combined *combinedIncrements,
+) (*roachpb.BatchResponse, *roachpb.Error)
+
+// increment sends the single batched IncrementRequest in ba through send,
+// possibly combined with concurrent batched increments of the same key. A
+// combined increment is sent on behalf of the whole group, so it is sent in
+// sendCtx, which must not be canceled along with the context of any member.
+func (b *incrementBatcher) increment(
+	ctx, sendCtx context.Context, ba roachpb.BatchRequest, send incrementSendFunc,
+) (*roachpb.BatchResponse, *roachpb.Error) {
+	key := string(ba.Requests[0].GetInner().(*roachpb.IncrementRequest).Key)
+
+	b.mu.Lock()
+	if b.mu.pending == nil {
+		b.mu.pending = map[string]*incrementGroup{}
+		b.mu.inflight = map[string]*incrementGroup{}
+	}
+	if g, ok := b.mu.pending[key]; ok {
+		// Join the group which is waiting for the in-flight increment.
+		idx := len(g.members)
+		g.members = append(g.members, ba)
+		b.mu.Unlock()
+		return g.wait(ctx, idx, send)
+	}
+	g := &incrementGroup{members: []roachpb.BatchRequest{ba}, done: make(chan struct{})}
+	inflight := b.mu.inflight[key]
+	if inflight != nil {
+		b.mu.pending[key] = g
+	} else {
+		b.mu.inflight[key] = g
+	}
+	b.mu.Unlock()
+
+	if inflight != nil {
+		// Wait for the in-flight increment before sending this group, allowing
+		// more increments to join it in the meantime. The result doesn't
+		// matter; ours is evaluated independently.
+		<-inflight.done
+		b.mu.Lock()
+		delete(b.mu.pending, key)
+		b.mu.inflight[key] = g
+		b.mu.Unlock()
+	}
+
+	// Once the group has been removed from pending, its members are fixed.
+	if len(g.members) == 1 {
+		g.br, g.pErr = send(ctx, ba, nil /* combined */)
+	} else {
+		g.send(sendCtx, send)
+	}
+
+	b.mu.Lock()
+	delete(b.mu.inflight, key)
+	b.mu.Unlock()
+	close(g.done)
+
+	return g.result(ctx, 0, send)
+}
+
+// send combines the group's increments and sends them.
+func (g *incrementGroup) send(ctx context.Context, send incrementSendFunc) {
+	req := g.members[0].Requests[0].GetInner().(*roachpb.IncrementRequest)
+	sendSplit := func(reason string) {
+		log.VEventf(ctx, 2, "sending %d increments of %s separately: %s",
+			len(g.members), req.Key, reason)
+		for i := range g.combinedIdx {
+			g.combinedIdx[i] = -1
+		}
+	}
+
+	var sum int64
+	g.combinedIdx = make([]int, len(g.members))
+	for i, ba := range g.members {
+		delta := ba.Requests[0].GetInner().(*roachpb.IncrementRequest).Increment
+		if engine.WillOverflow(sum, delta) {
+			g.combinedIdx[i] = -1
+			continue
+		}
+		sum += delta
+		g.combinedIdx[i] = len(g.combined.deltas)
+		g.combined.ids = append(g.combined.ids, ba.RequestID)
+		g.combined.deltas = append(g.combined.deltas, delta)
+	}
+	log.VEventf(ctx, 2, "combining %d of %d increments of %s",
+		len(g.combined.deltas), len(g.members), req.Key)
+
+	ba := g.members[0]
+	combined := *req
+	combined.Increment = sum
+	ba.Requests = []roachpb.RequestUnion{{}}
+	ba.Requests[0].MustSetInner(&combined)
+	// The members' request IDs are checked and recorded through combined.
+	ba.RequestID = nil
+	g.br, g.pErr = send(ctx, ba, &g.combined)
+
+	if g.combined.replayed {
+		sendSplit("a member has already been applied")
+	} else if g.pErr != nil {
+		if _, ok := g.pErr.GetDetail().(*roachpb.IntegerOverflowError); ok {
+			// Some of the members may succeed on their own.
+			sendSplit("combined increment overflows")
+		}
+	}
+}
+
+// wait blocks until the group's increment has been sent and returns the
+// result for the member at index idx.
+func (g *incrementGroup) wait(
+	ctx context.Context, idx int, send incrementSendFunc,
+) (*roachpb.BatchResponse, *roachpb.Error) {
+	select {
+	case <-g.done:
+		return g.result(ctx, idx, send)
+	case <-ctx.Done():
+		// The increment will still be sent on behalf of the group.
+		return nil, roachpb.NewError(roachpb.NewAmbiguousResultError(
+			errors.Wrap(ctx.Err(), "batched increment").Error()))
+	}
+}
+
+// result returns the response for the member at index idx, sending the
+// member's increment on its own if it wasn't combined.
+func (g *incrementGroup) result(
+	ctx context.Context, idx int, send incrementSendFunc,
+) (*roachpb.BatchResponse, *roachpb.Error) {
+	if len(g.members) == 1 {
+		return g.br, g.pErr
+	}
+	combinedIdx := g.combinedIdx[idx]
+	if combinedIdx < 0 {
+		return send(ctx, g.members[idx], nil /* combined */)
+	}
+	if g.pErr != nil {
+		// Every member of the group receives its own copy of the error, which
+		// callers are free to modify.
+		pErr := *g.pErr
+		return nil, &pErr
+	}
+	return g.combined.memberResponse(g.br, combinedIdx), nil
+}
@@ -0,0 +1,217 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+func TestIncrementBatcher(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	h := newIncrementBatcherTestHarness()
+
+	// The first increment is sent on its own and blocks in send.
+	go h.increment(1)
+	<-h.started
+
+	// Increments arriving while it is in flight form a single group.
+	const numWaiters = 10
+	for i := 0; i < numWaiters; i++ {
+		go h.increment(10)
+	}
+	h.waitForPending(t, numWaiters)
+	close(h.release)
+
+	var values []int
+	for i := 0; i < numWaiters+1; i++ {
+		res := <-h.resultC
+		if res.pErr != nil {
+			t.Fatal(res.pErr)
+		}
+		values = append(values, int(res.newValue))
+	}
+	sort.Ints(values)
+	expValues := []int{1}
+	for i := 1; i <= numWaiters; i++ {
+		expValues = append(expValues, 1+10*i)
+	}
+	if !reflect.DeepEqual(values, expValues) {
+		t.Errorf("expected values %v, got %v", expValues, values)
+	}
+	if exp := []int64{1, 10 * numWaiters}; !reflect.DeepEqual(h.sent, exp) {
+		t.Errorf("expected sent increments %v, got %v", exp, h.sent)
+	}
+
+	// The combined increment carries the request IDs of all of its members.
+	if len(h.combined) != 1 || len(h.combined[0].ids) != numWaiters {
+		t.Fatalf("expected one combined increment of %d members, got %+v", numWaiters, h.combined)
+	}
+	seen := map[uuid.UUID]bool{}
+	for _, id := range h.combined[0].ids {
+		if id == nil || seen[id.ID] {
+			t.Fatalf("expected distinct request IDs, got %v", h.combined[0].ids)
+		}
+		seen[id.ID] = true
+	}
+
+	h.b.mu.Lock()
+	defer h.b.mu.Unlock()
+	if len(h.b.mu.pending) != 0 || len(h.b.mu.inflight) != 0 {
+		t.Errorf("expected no pending or in-flight increments, got %v and %v",
+			h.b.mu.pending, h.b.mu.inflight)
+	}
+}
+
+// TestIncrementBatcherOverflow verifies that an increment whose delta would
+// overflow the combined delta is sent on its own.
+func TestIncrementBatcherOverflow(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	h := newIncrementBatcherTestHarness()
+	go h.increment(-100)
+	<-h.started
+
+	// Queue up the increments one at a time so that their order in the group
+	// is known.
+	deltas := []int64{math.MaxInt64, 1, -1}
+	for i, delta := range deltas {
+		go h.increment(delta)
+		h.waitForPending(t, i+1)
+	}
+	close(h.release)
+
+	var values []int64
+	for i := 0; i < len(deltas)+1; i++ {
+		res := <-h.resultC
+		if res.pErr != nil {
+			t.Fatal(res.pErr)
+		}
+		values = append(values, res.newValue)
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	// The combined increment of MaxInt64 and -1 observes MaxInt64-100 and
+	// MaxInt64-101, after which the increment of 1 is applied.
+	if exp := []int64{
+		-100, math.MaxInt64 - 101, math.MaxInt64 - 100, math.MaxInt64 - 100,
+	}; !reflect.DeepEqual(values, exp) {
+		t.Errorf("expected values %v, got %v", exp, values)
+	}
+	if exp := []int64{-100, math.MaxInt64 - 1, 1}; !reflect.DeepEqual(h.sent, exp) {
+		t.Errorf("expected sent increments %v, got %v", exp, h.sent)
+	}
+}
+
+type incrementBatcherTestResult struct {
+	newValue int64
+	pErr     *roachpb.Error
+}
+
+// incrementBatcherTestHarness sends increments of a single key through an
+// incrementBatcher to an in-memory counter. The first increment sent blocks
+// until release is closed.
+type incrementBatcherTestHarness struct {
+	b       incrementBatcher
+	key     roachpb.Key
+	started chan struct{}
+	release chan struct{}
+	resultC chan incrementBatcherTestResult
+
+	mu       syncutil.Mutex
+	value    int64
+	sent     []int64
+	combined []combinedIncrements
+}
+
+func newIncrementBatcherTestHarness() *incrementBatcherTestHarness {
+	return &incrementBatcherTestHarness{
+		key:     roachpb.Key("a"),
+		started: make(chan struct{}, 1),
+		release: make(chan struct{}),
+		resultC: make(chan incrementBatcherTestResult),
+	}
+}
+
+func (h *incrementBatcherTestHarness) send(
+	_ context.Context, ba roachpb.BatchRequest, combined *combinedIncrements,
+) (*roachpb.BatchResponse, *roachpb.Error) {
+	delta := ba.Requests[0].GetInner().(*roachpb.IncrementRequest).Increment
+	h.mu.Lock()
+	first := len(h.sent) == 0
+	h.sent = append(h.sent, delta)
+	if combined != nil {
+		h.combined = append(h.combined, *combined)
+	}
+	h.mu.Unlock()
+	if first {
+		h.started <- struct{}{}
+		<-h.release
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if engine.WillOverflow(h.value, delta) {
+		return nil, roachpb.NewError(&roachpb.IntegerOverflowError{
+			Key: h.key, CurrentValue: h.value, IncrementValue: delta,
+		})
+	}
+	h.value += delta
+	br := &roachpb.BatchResponse{}
+	br.Add(&roachpb.IncrementResponse{NewValue: h.value})
+	return br, nil
+}
+
+func (h *incrementBatcherTestHarness) increment(delta int64) {
+	var ba roachpb.BatchRequest
+	ba.RequestID = &roachpb.RequestID{ID: uuid.MakeV4()}
+	ba.Add(&roachpb.IncrementRequest{
+		RequestHeader: roachpb.RequestHeader{Key: h.key},
+		Increment:     delta,
+		Batched:       true,
+	})
+	ctx := context.Background()
+	br, pErr := h.b.increment(ctx, ctx, ba, h.send)
+	var res incrementBatcherTestResult
+	if pErr != nil {
+		res.pErr = pErr
+	} else {
+		res.newValue = br.Responses[0].GetInner().(*roachpb.IncrementResponse).NewValue
+	}
+	h.resultC <- res
+}
+
+// waitForPending waits for the pending group to have n members.
+func (h *incrementBatcherTestHarness) waitForPending(t *testing.T, n int) {
+	testutils.SucceedsSoon(t, func() error {
+		h.b.mu.Lock()
+		defer h.b.mu.Unlock()
+		if g, ok := h.b.mu.pending[string(h.key)]; !ok || len(g.members) != n {
+			return errors.New("waiting for increments to queue up")
+		}
+		return nil
+	})
+}
@@ -24,6 +24,7 @@ import (
 
 	"github.com/coreos/etcd/raft"
 	"github.com/kr/pretty"
+	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 	"golang.org/x/time/rate"
 
@@ -39,6 +40,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 )
 
 // ProposalData is data about a command which allows it to be
@@ -73,6 +75,12 @@ type ProposalData struct {
 	// Attention: this channel is not to be signaled directly downstream of Raft.
 	// Always use ProposalData.finishApplication().
 	doneCh chan proposalResult
+	// signaled is set once doneCh has been signaled. For a command proposed
+	// with AsyncConsensus, this happens before the command applies.
+	signaled bool
+	// sp is the span forked from the proposer's context for a command whose
+	// proposer was signaled before it applied. It is finished on application.
+	sp opentracing.Span
 
 	// Local contains the results of evaluating the request
 	// tying the upstream evaluation of the request to the
@@ -113,6 +121,21 @@ func (proposal *ProposalData) finishApplication(pr proposalResult) {
 		proposal.endCmds.done(pr.Reply, pr.Err, pr.ProposalRetry)
 		proposal.endCmds = nil
 	}
+	proposal.signalProposalResult(pr)
+	if proposal.sp != nil {
+		tracing.FinishSpan(proposal.sp)
+		proposal.sp = nil
+	}
+}
+
+// signalProposalResult sends pr on the proposal's done channel, unblocking the
+// proposer, unless the proposer has already been signaled. A proposer which was
+// signaled early doesn't learn about the outcome of the command's application.
+func (proposal *ProposalData) signalProposalResult(pr proposalResult) {
+	if proposal.signaled {
+		return
+	}
+	proposal.signaled = true
 	proposal.doneCh <- pr
 	close(proposal.doneCh)
 }
@@ -228,6 +251,13 @@ func (r *Replica) leasePostApply(ctx context.Context, newLease roachpb.Lease) {
 		}
 	}
 
+	if leaseChangingHands {
+		// Release the replay cache, which only vouches for the batches applied
+		// under the current lease, whether or not this replica holds the new
+		// lease.
+		r.clearReplayCache()
+	}
+
 	// Sanity check to make sure that the lease sequence is moving in the right
 	// direction.
 	if s1, s2 := prevLease.Sequence, newLease.Sequence; s1 != 0 {
@@ -652,6 +682,7 @@ func (r *Replica) handleReplicatedEvalResult(
 	// of the ContainsEstimates hack.
 
 	if rResult.Split != nil {
+		r.disconnectRangefeedOnBoundsChangeRaftMuLocked(&rResult.Split.LeftDesc)
 		splitPostApply(
 			r.AnnotateCtx(ctx),
 			rResult.Split.RHSDelta,
@@ -662,6 +693,7 @@ func (r *Replica) handleReplicatedEvalResult(
 	}
 
 	if rResult.Merge != nil {
+		r.disconnectRangefeedOnBoundsChangeRaftMuLocked(&rResult.Merge.LeftDesc)
 		if err := r.store.MergeRange(ctx, r, rResult.Merge.LeftDesc.EndKey,
 			rResult.Merge.RightDesc,
 		); err != nil {
@@ -827,6 +859,12 @@ func (r *Replica) handleLocalEvalResult(ctx context.Context, lResult result.Loca
 		}
 	}
 
+	if triggers := lResult.DetachTriggers(); len(triggers) > 0 {
+		if err := r.handleTriggers(ctx, triggers); err != nil {
+			log.Error(ctx, err)
+		}
+	}
+
 	if (lResult != result.LocalResult{}) {
 		log.Fatalf(ctx, "unhandled field in LocalEvalResult: %s", pretty.Diff(lResult, result.LocalResult{}))
 	}
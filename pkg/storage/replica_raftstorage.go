@@ -66,12 +66,21 @@ func (r *replicaRaftStorage) InitialState() (raftpb.HardState, raftpb.ConfState,
 	if raft.IsEmptyHardState(hs) || err != nil {
 		return raftpb.HardState{}, raftpb.ConfState{}, err
 	}
+	return hs, confStateFromDesc(r.mu.state.Desc), nil
+}
+
+// confStateFromDesc synthesizes the Raft configuration described by the
+// replicas of the given range descriptor.
+func confStateFromDesc(desc *roachpb.RangeDescriptor) raftpb.ConfState {
 	var cs raftpb.ConfState
-	for _, rep := range r.mu.state.Desc.Replicas {
-		cs.Nodes = append(cs.Nodes, uint64(rep.ReplicaID))
+	for _, rep := range desc.Replicas {
+		if rep.GetType() == roachpb.ReplicaType_LEARNER {
+			cs.Learners = append(cs.Learners, uint64(rep.ReplicaID))
+		} else {
+			cs.Nodes = append(cs.Nodes, uint64(rep.ReplicaID))
+		}
 	}
-
-	return hs, cs, nil
+	return cs
 }
 
 // Entries implements the raft.Storage interface. Note that maxBytes is advisory
@@ -458,6 +467,9 @@ type IncomingSnapshot struct {
 	// The replica state at the time the snapshot was generated (never nil).
 	State    *storagebase.ReplicaState
 	snapType string
+	// sst, if set, holds the user data of the snapshot, which is then not part
+	// of the batches.
+	sst *snapshotSST
 }
 
 // snapshot creates an OutgoingSnapshot containing a rocksdb snapshot for the
@@ -497,10 +509,7 @@ func snapshot(
 	}
 
 	// Synthesize our raftpb.ConfState from desc.
-	var cs raftpb.ConfState
-	for _, rep := range desc.Replicas {
-		cs.Nodes = append(cs.Nodes, uint64(rep.ReplicaID))
-	}
+	cs := confStateFromDesc(&desc)
 
 	term, err := term(ctx, rsl, snap, rangeID, eCache, appliedIndex)
 	if err != nil {
@@ -677,6 +686,46 @@ func clearRangeData(
 	return nil
 }
 
+// clearRangeMetadata is like clearRangeData with destroyData set, except that
+// it leaves the user data of the range alone.
+func clearRangeMetadata(desc *roachpb.RangeDescriptor, eng engine.Engine, batch engine.Batch) error {
+	iter := eng.NewIterator(engine.IterOptions{UpperBound: desc.EndKey.AsRawKey()})
+	defer iter.Close()
+	keyRanges := rditer.MakeAllKeyRanges(desc)
+	for _, keyRange := range keyRanges[:len(keyRanges)-1] {
+		if err := batch.ClearIterRange(iter, keyRange.Start, keyRange.End); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ingestSnapshotSST ingests the SST holding the user data of a snapshot for
+// the replica, which isn't initialized. The span of the user data is cleared
+// first: it can only hold data left behind by an earlier attempt to apply a
+// snapshot, which was interrupted before the replica got initialized.
+func (r *Replica) ingestSnapshotSST(
+	ctx context.Context, desc *roachpb.RangeDescriptor, sst *snapshotSST,
+) error {
+	eng := r.store.Engine()
+	keyRanges := rditer.MakeAllKeyRanges(desc)
+	dataRange := keyRanges[len(keyRanges)-1]
+	batch := eng.NewWriteOnlyBatch()
+	defer batch.Close()
+	if err := batch.ClearRange(dataRange.Start, dataRange.End); err != nil {
+		return err
+	}
+	if err := batch.Commit(false /* sync */); err != nil {
+		return err
+	}
+	const modify = true
+	if err := eng.IngestExternalFiles(ctx, []string{sst.path}, modify); err != nil {
+		return err
+	}
+	sst.ingested = true
+	return nil
+}
+
 // applySnapshot updates the replica based on the given snapshot and associated
 // HardState. All snapshots must pass through Raft for correctness, i.e. the
 // parameters to this method must be taken from a raft.Ready. It is the caller's
@@ -693,6 +742,7 @@ func (r *Replica) applySnapshot(
 	r.mu.RLock()
 	replicaID := r.mu.replicaID
 	keyCount := r.mu.state.Stats.KeyCount
+	initialized := r.mu.state.Desc.IsInitialized()
 	r.mu.RUnlock()
 
 	snapType := inSnap.snapType
@@ -720,6 +770,7 @@ func (r *Replica) applySnapshot(
 	}
 
 	var stats struct {
+		ingest  time.Time
 		clear   time.Time
 		batch   time.Time
 		entries time.Time
@@ -734,19 +785,42 @@ func (r *Replica) applySnapshot(
 		size += len(e)
 	}
 
+	var sstSize int
+	if inSnap.sst != nil {
+		sstSize = len(inSnap.sst.data)
+	}
 	log.Infof(ctx, "applying %s snapshot at index %d "+
-		"(id=%s, encoded size=%d, %d rocksdb batches, %d log entries)",
+		"(id=%s, encoded size=%d, %d rocksdb batches, sst size=%d, %d log entries)",
 		snapType, snap.Metadata.Index, inSnap.SnapUUID.Short(),
-		size, len(inSnap.Batches), len(inSnap.LogEntries))
-	defer func(start time.Time) {
+		size, len(inSnap.Batches), sstSize, len(inSnap.LogEntries))
+	start := timeutil.Now()
+	stats.ingest = start
+	defer func() {
 		now := timeutil.Now()
-		log.Infof(ctx, "applied %s snapshot in %0.0fms [clear=%0.0fms batch=%0.0fms entries=%0.0fms commit=%0.0fms]",
+		log.Infof(ctx, "applied %s snapshot in %0.0fms [ingest=%0.0fms clear=%0.0fms batch=%0.0fms entries=%0.0fms commit=%0.0fms]",
 			snapType, now.Sub(start).Seconds()*1000,
-			stats.clear.Sub(start).Seconds()*1000,
+			stats.ingest.Sub(start).Seconds()*1000,
+			stats.clear.Sub(stats.ingest).Seconds()*1000,
 			stats.batch.Sub(stats.clear).Seconds()*1000,
 			stats.entries.Sub(stats.batch).Seconds()*1000,
 			stats.commit.Sub(stats.entries).Seconds()*1000)
-	}(timeutil.Now())
+	}()
+
+	// Ingest the user data of the snapshot if it was split out into an SST
+	// when the snapshot was received. The replica may have been initialized
+	// since then, in which case its existing data needs to be deleted
+	// atomically with the application of the snapshot, and the SST is written
+	// through the batch below instead.
+	ingested := false
+	if inSnap.sst != nil && !initialized {
+		if err := r.ingestSnapshotSST(ctx, s.Desc, inSnap.sst); err != nil {
+			log.Warningf(ctx, "failed to ingest snapshot data, writing it through a batch instead: %v", err)
+		} else {
+			ingested = true
+			r.store.metrics.RangeSnapshotsIngested.Inc(1)
+		}
+	}
+	stats.ingest = timeutil.Now()
 
 	// Use a more efficient write-only batch because we don't need to do any
 	// reads from the batch.
@@ -756,7 +830,12 @@ func (r *Replica) applySnapshot(
 	// Delete everything in the range and recreate it from the snapshot.
 	// We need to delete any old Raft log entries here because any log entries
 	// that predate the snapshot will be orphaned and never truncated or GC'd.
-	if err := clearRangeData(ctx, s.Desc, keyCount, r.store.Engine(), batch, true /* destroyData */); err != nil {
+	// If the user data was ingested, it's the only data in its span already.
+	if ingested {
+		if err := clearRangeMetadata(s.Desc, r.store.Engine(), batch); err != nil {
+			return err
+		}
+	} else if err := clearRangeData(ctx, s.Desc, keyCount, r.store.Engine(), batch, true /* destroyData */); err != nil {
 		return err
 	}
 	stats.clear = timeutil.Now()
@@ -767,6 +846,11 @@ func (r *Replica) applySnapshot(
 			return err
 		}
 	}
+	if inSnap.sst != nil && !ingested {
+		if err := inSnap.sst.applyTo(batch); err != nil {
+			return err
+		}
+	}
 
 	// Nodes running v2.0 and earlier may send an incorrect Raft tombstone (see
 	// #12154) that was supposed to be unreplicated. Simply remove it.
@@ -26,6 +26,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
@@ -33,6 +34,18 @@ import (
 
 var leaseStatusLogLimiter = log.Every(5 * time.Second)
 
+// ExpirationLeasesOnly controls whether all ranges use expiration-based leases.
+// The store's lease renewer then proactively renews them all in one loop, so
+// the leases don't depend on the node liveness heartbeats. This suits small,
+// low-traffic clusters, where a lease renewal per range is cheaper than
+// keeping every range's availability tied to the liveness range. Existing
+// leases switch to the new type the next time they're used.
+var ExpirationLeasesOnly = settings.RegisterBoolSetting(
+	"kv.expiration_leases_only.enabled",
+	"only use expiration-based range leases, proactively renewed by each store, instead of epoch-based leases tied to node liveness",
+	false,
+)
+
 // leaseRequestHandle is a handle to an asynchronous lease request.
 type leaseRequestHandle struct {
 	p *pendingLeaseRequest
@@ -501,6 +514,7 @@ func (r *Replica) leaseStatus(
 // held.
 func (r *Replica) requiresExpiringLeaseRLocked() bool {
 	return r.store.cfg.NodeLiveness == nil || !r.store.cfg.EnableEpochRangeLeases ||
+		ExpirationLeasesOnly.Get(&r.store.cfg.Settings.SV) ||
 		r.mu.state.Desc.StartKey.Less(roachpb.RKey(keys.NodeLivenessKeyMax))
 }
 
@@ -529,6 +543,11 @@ func (r *Replica) requestLeaseLocked(ctx context.Context, status LeaseStatus) *l
 		return r.mu.pendingLeaseRequest.newResolvedHandle(roachpb.NewError(
 			newNotLeaseHolderError(nil, r.store.StoreID(), r.mu.state.Desc)))
 	}
+	if repDesc.GetType() == roachpb.ReplicaType_LEARNER {
+		// Learners don't vote and so can't be trusted to hold the lease.
+		return r.mu.pendingLeaseRequest.newResolvedHandle(roachpb.NewError(
+			newNotLeaseHolderError(nil, r.store.StoreID(), r.mu.state.Desc)))
+	}
 	return r.mu.pendingLeaseRequest.InitOrJoinRequest(
 		ctx, repDesc, status, r.mu.state.Desc.StartKey.AsRawKey(), false /* transfer */)
 }
@@ -574,6 +593,9 @@ func (r *Replica) AdminTransferLease(ctx context.Context, target roachpb.StoreID
 		if nextLeaseHolder, ok = desc.GetReplicaDescriptor(target); !ok {
 			return nil, nil, errors.Errorf("unable to find store %d in range %+v", target, desc)
 		}
+		if nextLeaseHolder.GetType() == roachpb.ReplicaType_LEARNER {
+			return nil, nil, errors.Errorf("unable to transfer lease to learner replica %s", nextLeaseHolder)
+		}
 
 		if nextLease, ok := r.mu.pendingLeaseRequest.RequestPending(); ok &&
 			nextLease.Replica != nextLeaseHolder {
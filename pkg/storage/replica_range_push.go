@@ -0,0 +1,67 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+// rangeDescriptorPushDuration is how long after a split or merge the
+// leaseholder of the surviving left-hand range attaches the updated range
+// descriptors to the responses it sends. Clients insert them into their range
+// descriptor caches, so that after a wave of splits or merges they don't each
+// have to discover their stale descriptors through RangeKeyMismatchErrors or
+// RangeNotFoundErrors and the lookups and retries that follow.
+var rangeDescriptorPushDuration = settings.RegisterNonNegativeDurationSetting(
+	"kv.range_descriptor_push.duration",
+	"amount of time after a split or merge during which the updated range "+
+		"descriptors are sent to clients along with responses, or 0 to disable",
+	10*time.Second,
+)
+
+// recordRangeBoundaryChange is called after a split or merge changed the
+// bounds of the range. rightDesc is the descriptor of the right-hand side of
+// a split, and nil for a merge.
+func (r *Replica) recordRangeBoundaryChange(rightDesc *roachpb.RangeDescriptor) {
+	d := rangeDescriptorPushDuration.Get(&r.store.cfg.Settings.SV)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if d == 0 {
+		r.mu.descPush.expiration = time.Time{}
+		r.mu.descPush.rightDesc = nil
+		return
+	}
+	r.mu.descPush.expiration = r.store.Clock().PhysicalTime().Add(d)
+	r.mu.descPush.rightDesc = rightDesc
+}
+
+// maybeAttachRangeDescriptors attaches the replica's descriptor to the batch
+// response if the range's bounds changed recently, along with the descriptor
+// of the right-hand side if it was a split.
+func (r *Replica) maybeAttachRangeDescriptors(br *roachpb.BatchResponse) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.mu.descPush.expiration.IsZero() ||
+		r.store.Clock().PhysicalTime().After(r.mu.descPush.expiration) {
+		return
+	}
+	br.RangeDescriptors = append(br.RangeDescriptors, *r.mu.state.Desc)
+	if rightDesc := r.mu.descPush.rightDesc; rightDesc != nil {
+		br.RangeDescriptors = append(br.RangeDescriptors, *rightDesc)
+	}
+}
@@ -0,0 +1,510 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/storage/rangefeed"
+	"github.com/cockroachdb/cockroach/pkg/storage/spanset"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+// RangefeedEnabled is a cluster setting that enables rangefeed requests.
+var RangefeedEnabled = settings.RegisterBoolSetting(
+	"kv.rangefeed.enabled",
+	"if set, rangefeed registration is enabled",
+	false,
+)
+
+const (
+	// rangefeedCloseInterval is the interval at which the leaseholder of a
+	// range with a rangefeed closes a new timestamp on the range.
+	rangefeedCloseInterval = 200 * time.Millisecond
+	// rangefeedCloseLag is how far behind the current time the timestamps
+	// closed for rangefeeds are. Writes below a closed timestamp are pushed
+	// above it, so this trades off the latency of resolved timestamps
+	// against the likelihood of forcing transactions to retry.
+	rangefeedCloseLag = 3 * time.Second
+	// rangefeedEventChanCap is the capacity of the buffers between the
+	// application of Raft commands and a range's rangefeed Processor, and
+	// between the Processor and each registration.
+	rangefeedEventChanCap = 4096
+)
+
+// lockedRangefeedStream is an implementation of rangefeed.Stream which
+// provides support for concurrent calls to Send. Note that the default
+// implementation of grpc.Stream is not safe for concurrent calls to Send.
+type lockedRangefeedStream struct {
+	wrapped rangefeed.Stream
+	sendMu  syncutil.Mutex
+}
+
+func (s *lockedRangefeedStream) Context() context.Context {
+	return s.wrapped.Context()
+}
+
+func (s *lockedRangefeedStream) Send(e *roachpb.RangeFeedEvent) error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	return s.wrapped.Send(e)
+}
+
+// RangeFeed registers a rangefeed over the specified span. It sends updates
+// to the provided stream and returns with an optional error when the
+// rangefeed is complete.
+//
+// Rangefeeds are served by the range's leaseholder, which is the replica
+// that can close timestamps on the range. The rangefeed is disconnected with
+// an error whenever it can no longer be served by this replica: when the
+// replica loses its lease or is removed, or when the range splits or merges.
+// The consumer is expected to reconnect, with a start timestamp of the last
+// resolved timestamp it received, to the range(s) now covering its span.
+func (r *Replica) RangeFeed(
+	args *roachpb.RangeFeedRequest, stream rangefeed.Stream,
+) *roachpb.Error {
+	if !RangefeedEnabled.Get(&r.store.cfg.Settings.SV) {
+		return roachpb.NewErrorf("rangefeeds require the kv.rangefeed.enabled setting")
+	}
+	ctx := r.AnnotateCtx(stream.Context())
+
+	if keys.IsLocal(args.Span.Key) {
+		return roachpb.NewErrorf("rangefeeds are not supported over local keys: %s", args.Span)
+	}
+	rSpan := roachpb.RSpan{Key: roachpb.RKey(args.Span.Key), EndKey: roachpb.RKey(args.Span.EndKey)}
+	if err := r.requestCanProceed(rSpan, args.Timestamp); err != nil {
+		return roachpb.NewError(err)
+	}
+	if _, pErr := r.redirectOnOrAcquireLease(ctx); pErr != nil {
+		return pErr
+	}
+
+	lockedStream := &lockedRangefeedStream{wrapped: stream}
+	errC := make(chan *roachpb.Error, 1)
+
+	r.raftMu.Lock()
+	p, registered := r.registerWithRangefeedRaftMuLocked(ctx, rSpan, args.Timestamp, lockedStream, errC)
+	if !registered {
+		// The Processor stopped on its own before the stream could register
+		// with it. Replace it and try again.
+		r.raftMu.rangefeed = nil
+		p, registered = r.registerWithRangefeedRaftMuLocked(ctx, rSpan, args.Timestamp, lockedStream, errC)
+	}
+	r.raftMu.Unlock()
+	if !registered {
+		return roachpb.NewError(&roachpb.NodeUnavailableError{})
+	}
+
+	pErr := <-errC
+
+	// Tear down the Processor once its last registration is gone.
+	r.raftMu.Lock()
+	if r.raftMu.rangefeed == p && p.Len() == 0 {
+		r.disconnectRangefeedWithErrRaftMuLocked(nil)
+	}
+	r.raftMu.Unlock()
+	return pErr
+}
+
+// registerWithRangefeedRaftMuLocked registers the stream with the replica's
+// rangefeed Processor, creating the Processor if this is the range's first
+// rangefeed. The catch-up iterator is created under raftMu so that it
+// observes exactly the writes that were applied before the registration. It
+// returns false if the Processor was stopped.
+func (r *Replica) registerWithRangefeedRaftMuLocked(
+	ctx context.Context,
+	span roachpb.RSpan,
+	startTS hlc.Timestamp,
+	stream rangefeed.Stream,
+	errC chan<- *roachpb.Error,
+) (*rangefeed.Processor, bool) {
+	p := r.maybeInitRangefeedRaftMuLocked(ctx)
+	catchUpIter := r.Engine().NewIterator(engine.IterOptions{
+		UpperBound: span.EndKey.AsRawKey(),
+	})
+	if !p.Register(span, startTS, catchUpIter, stream, errC) {
+		catchUpIter.Close()
+		return p, false
+	}
+	return p, true
+}
+
+// maybeInitRangefeedRaftMuLocked initializes a rangefeed Processor for the
+// replica if one does not already exist and returns it.
+func (r *Replica) maybeInitRangefeedRaftMuLocked(ctx context.Context) *rangefeed.Processor {
+	if p := r.raftMu.rangefeed; p != nil {
+		return p
+	}
+
+	cfg := rangefeed.Config{
+		AmbientContext: r.AmbientContext,
+		Clock:          r.Clock(),
+		Span:           r.Desc().RSpan(),
+		TxnPusher:      &rangefeedTxnPusher{ir: r.store.intentResolver, r: r},
+		EventChanCap:   rangefeedEventChanCap,
+	}
+	p := rangefeed.NewProcessor(cfg)
+	rtsIter := r.Engine().NewIterator(engine.IterOptions{
+		UpperBound: cfg.Span.EndKey.AsRawKey(),
+	})
+	p.Start(r.store.Stopper(), rtsIter)
+	r.raftMu.rangefeed = p
+
+	// Close timestamps on the range for as long as the Processor runs.
+	if err := r.store.Stopper().RunAsyncTask(
+		r.AnnotateCtx(context.Background()), "storage.Replica: closing rangefeed timestamps",
+		func(ctx context.Context) {
+			r.runRangefeedCloser(ctx, p)
+		},
+	); err != nil {
+		p.StopWithErr(roachpb.NewError(err))
+	}
+	return p
+}
+
+// disconnectRangefeedWithErrRaftMuLocked stops the replica's rangefeed
+// Processor, if any, disconnecting all of its registrations with the provided
+// error.
+func (r *Replica) disconnectRangefeedWithErrRaftMuLocked(pErr *roachpb.Error) {
+	p := r.raftMu.rangefeed
+	if p == nil {
+		return
+	}
+	p.StopWithErr(pErr)
+	r.raftMu.rangefeed = nil
+}
+
+// disconnectRangefeedOnBoundsChangeRaftMuLocked stops the replica's
+// rangefeed Processor, if any, when a split or merge changes the bounds of the
+// range to those of newDesc. The rangefeeds' consumers reconnect to the ranges
+// now covering their spans.
+func (r *Replica) disconnectRangefeedOnBoundsChangeRaftMuLocked(newDesc *roachpb.RangeDescriptor) {
+	if r.raftMu.rangefeed == nil {
+		return
+	}
+	span := r.Desc().RSpan()
+	r.disconnectRangefeedWithErrRaftMuLocked(roachpb.NewError(roachpb.NewRangeKeyMismatchError(
+		span.Key.AsRawKey(), span.EndKey.AsRawKey(), newDesc,
+	)))
+}
+
+// handleRangefeedWriteBatchRaftMuLocked informs the replica's rangefeed
+// Processor, if any, of the logical operations performed by the provided
+// write batch, which must not have been applied yet. The operations are only
+// computed here and handed to the Processor by the returned function, which
+// must be called once the batch has been committed.
+func (r *Replica) handleRangefeedWriteBatchRaftMuLocked(
+	ctx context.Context, writeBatch []byte,
+) func() {
+	p := r.raftMu.rangefeed
+	if p == nil || len(writeBatch) == 0 {
+		return func() {}
+	}
+	ops, err := logicalOpsFromWriteBatch(writeBatch, r.Engine())
+	if err != nil {
+		log.Errorf(ctx, "unable to compute rangefeed logical ops: %s", err)
+		r.disconnectRangefeedWithErrRaftMuLocked(roachpb.NewError(err))
+		return func() {}
+	}
+	return func() {
+		if !p.ConsumeLogicalOps(ops...) {
+			// The Processor was stopped, possibly because it fell behind.
+			if r.raftMu.rangefeed == p {
+				r.raftMu.rangefeed = nil
+			}
+		}
+	}
+}
+
+// batchKeyMutations are the mutations of a single user key found in a write
+// batch.
+type batchKeyMutations struct {
+	key     roachpb.Key
+	metaPut *enginepb.MVCCMetadata
+	metaDel bool
+	// The versioned values put and deleted, in the order in which they
+	// appear in the batch.
+	versionPuts []engine.MVCCKeyValue
+	versionDels []hlc.Timestamp
+}
+
+// logicalOpsFromWriteBatch derives the logical MVCC operations performed by
+// a write batch from its mutations to the MVCC keys of the range and the
+// state of those keys before the batch is applied, as read from reader.
+//
+// Intents are written, updated and resolved through their MVCC metadata key.
+// Writing a metadata key with a transaction when there was no intent is a
+// new intent, and writing one over the transaction's own intent moves the
+// intent. Removing an intent's metadata key either commits the intent, in
+// which case its provisional value stays in place or is moved to the commit
+// timestamp, or aborts it, in which case the provisional value is removed.
+// Values written without metadata keys are non-transactional writes.
+func logicalOpsFromWriteBatch(
+	writeBatch []byte, reader engine.Reader,
+) ([]rangefeed.LogicalOp, error) {
+	batchReader, err := engine.NewRocksDBBatchReader(writeBatch)
+	if err != nil {
+		return nil, err
+	}
+
+	var order []string
+	muts := make(map[string]*batchKeyMutations)
+	for batchReader.Next() {
+		switch batchReader.BatchType() {
+		case engine.BatchTypeValue, engine.BatchTypeDeletion:
+		default:
+			// Merges are only used for non-MVCC data.
+			continue
+		}
+		mvccKey, err := batchReader.MVCCKey()
+		if err != nil {
+			return nil, err
+		}
+		// The keys and values point into the write batch, which outlives
+		// neither the application of the command nor the logical ops.
+		mvccKey.Key = append(roachpb.Key(nil), mvccKey.Key...)
+		if keys.IsLocal(mvccKey.Key) {
+			continue
+		}
+		m, ok := muts[string(mvccKey.Key)]
+		if !ok {
+			m = &batchKeyMutations{key: mvccKey.Key}
+			muts[string(mvccKey.Key)] = m
+			order = append(order, string(mvccKey.Key))
+		}
+		isPut := batchReader.BatchType() == engine.BatchTypeValue
+		switch {
+		case !mvccKey.IsValue() && isPut:
+			m.metaPut = &enginepb.MVCCMetadata{}
+			if err := protoutil.Unmarshal(batchReader.Value(), m.metaPut); err != nil {
+				return nil, errors.Wrapf(err, "unmarshaling mvcc meta: %s", mvccKey)
+			}
+			m.metaDel = false
+		case !mvccKey.IsValue():
+			m.metaPut = nil
+			m.metaDel = true
+		case isPut:
+			m.versionPuts = append(m.versionPuts, engine.MVCCKeyValue{
+				Key:   mvccKey,
+				Value: append([]byte(nil), batchReader.Value()...),
+			})
+		default:
+			m.versionDels = append(m.versionDels, mvccKey.Timestamp)
+		}
+	}
+	if err := batchReader.Error(); err != nil {
+		return nil, err
+	}
+
+	var ops []rangefeed.LogicalOp
+	for _, k := range order {
+		m := muts[k]
+		if m.metaPut != nil && m.metaPut.IsInline() {
+			// Inline values are not MVCC values.
+			continue
+		}
+		var prevMeta enginepb.MVCCMetadata
+		ok, _, _, err := reader.GetProto(engine.MakeMVCCMetadataKey(m.key), &prevMeta)
+		if err != nil {
+			return nil, err
+		}
+		prevIntent := ok && prevMeta.Txn != nil
+
+		switch {
+		case m.metaPut != nil && m.metaPut.Txn != nil:
+			txn := m.metaPut.Txn
+			if prevIntent && prevMeta.Txn.ID == txn.ID {
+				ops = append(ops, &rangefeed.UpdateIntentOp{
+					TxnID:     txn.ID,
+					Timestamp: txn.Timestamp,
+				})
+			} else {
+				ops = append(ops, &rangefeed.WriteIntentOp{
+					TxnID:     txn.ID,
+					TxnKey:    txn.Key,
+					Timestamp: txn.Timestamp,
+				})
+			}
+
+		case prevIntent && m.metaDel:
+			txnID := prevMeta.Txn.ID
+			intentTS := hlc.Timestamp(prevMeta.Timestamp)
+			if n := len(m.versionPuts); n > 0 {
+				// The intent was committed at a new timestamp.
+				put := m.versionPuts[n-1]
+				ops = append(ops, &rangefeed.CommitIntentOp{
+					TxnID:     txnID,
+					Key:       m.key,
+					Timestamp: put.Key.Timestamp,
+					Value:     put.Value,
+				})
+				continue
+			}
+			aborted := false
+			for _, ts := range m.versionDels {
+				if ts == intentTS {
+					aborted = true
+				}
+			}
+			if aborted {
+				ops = append(ops, &rangefeed.AbortIntentOp{TxnID: txnID})
+				continue
+			}
+			// The intent was committed at its timestamp, leaving its
+			// provisional value in place.
+			value, err := reader.Get(engine.MVCCKey{Key: m.key, Timestamp: intentTS})
+			if err != nil {
+				return nil, err
+			}
+			ops = append(ops, &rangefeed.CommitIntentOp{
+				TxnID:     txnID,
+				Key:       m.key,
+				Timestamp: intentTS,
+				Value:     value,
+			})
+
+		case m.metaPut == nil && !m.metaDel:
+			for _, put := range m.versionPuts {
+				ops = append(ops, &rangefeed.WriteValueOp{
+					Key:       m.key,
+					Timestamp: put.Key.Timestamp,
+					Value:     put.Value,
+				})
+			}
+		}
+	}
+	return ops, nil
+}
+
+// runRangefeedCloser periodically closes a new timestamp on the range and
+// informs the rangefeed Processor of it, until the Processor stops.
+func (r *Replica) runRangefeedCloser(ctx context.Context, p *rangefeed.Processor) {
+	ticker := time.NewTicker(rangefeedCloseInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			closedTS, pErr := r.closeRangefeedTimestamp(ctx)
+			if pErr != nil {
+				p.StopWithErr(pErr)
+				return
+			}
+			r.raftMu.Lock()
+			if r.raftMu.rangefeed == p {
+				p.ForwardClosedTS(closedTS)
+			}
+			r.raftMu.Unlock()
+		case <-p.Stopped():
+			return
+		case <-r.store.Stopper().ShouldQuiesce():
+			return
+		}
+	}
+}
+
+// closeRangefeedTimestamp closes a timestamp on the range: once it returns,
+// all writes to the range below the returned timestamp have been applied and
+// no new ones can be evaluated, because the timestamp cache forces them above
+// it. This is achieved by waiting in the command queue, like a read of the
+// entire range at the timestamp would, for the in-flight writes below the
+// timestamp, and then recording such a read in the timestamp cache.
+//
+// Only the leaseholder can close timestamps. Its successors start with a
+// timestamp cache low water mark above any timestamp it could have closed.
+func (r *Replica) closeRangefeedTimestamp(ctx context.Context) (hlc.Timestamp, *roachpb.Error) {
+	now := r.Clock().Now()
+	if !r.OwnsValidLease(now) {
+		lease, _ := r.GetLease()
+		return hlc.Timestamp{}, roachpb.NewError(
+			newNotLeaseHolderError(&lease, r.store.StoreID(), r.Desc()))
+	}
+	closedTS := now.Add(-rangefeedCloseLag.Nanoseconds(), 0)
+
+	span := r.Desc().RSpan().AsRawSpanWithNoLocals()
+	var ba roachpb.BatchRequest
+	ba.Timestamp = closedTS
+	ba.Add(&roachpb.ScanRequest{RequestHeader: roachpb.RequestHeaderFromSpan(span)})
+	var spans spanset.SpanSet
+	spans.Add(spanset.SpanReadOnly, span)
+	ec, err := r.beginCmds(ctx, &ba, &spans)
+	if err != nil {
+		return hlc.Timestamp{}, roachpb.NewError(err)
+	}
+	r.store.tsCache.Add(span.Key, span.EndKey, closedTS, uuid.UUID{}, true /* readCache */)
+	r.removeCmdsFromCommandQueue(ec.cmds)
+	return closedTS, nil
+}
+
+// rangefeedTxnPusher is a rangefeed.TxnPusher that pushes transactions and
+// resolves intents through the store's intentResolver.
+type rangefeedTxnPusher struct {
+	ir *intentResolver
+	r  *Replica
+}
+
+// PushTxns is part of the rangefeed.TxnPusher interface. It performs a
+// high-priority push at the specified timestamp to each of the specified
+// transactions.
+func (tp *rangefeedTxnPusher) PushTxns(
+	ctx context.Context, txns []enginepb.TxnMeta, ts hlc.Timestamp,
+) ([]roachpb.Transaction, error) {
+	intents := make([]roachpb.Intent, len(txns))
+	for i, txn := range txns {
+		intents[i] = roachpb.Intent{Span: roachpb.Span{Key: txn.Key}, Txn: txn}
+	}
+	h := roachpb.Header{Timestamp: ts}
+	pushed, pErr := tp.ir.maybePushTransactions(
+		ctx, intents, h, roachpb.PUSH_TIMESTAMP, false, /* skipIfInFlight */
+	)
+	if pErr != nil {
+		return nil, pErr.GoError()
+	}
+	res := make([]roachpb.Transaction, len(pushed))
+	for i, intent := range pushed {
+		res[i] = roachpb.Transaction{TxnMeta: intent.Txn, Status: intent.Status}
+	}
+	return res, nil
+}
+
+// CleanupTxnIntentsAsync is part of the rangefeed.TxnPusher interface. It
+// resolves all of the provided transactions' intents in the range.
+func (tp *rangefeedTxnPusher) CleanupTxnIntentsAsync(
+	ctx context.Context, txns []roachpb.Transaction,
+) error {
+	span := tp.r.Desc().RSpan().AsRawSpanWithNoLocals()
+	intents := make([]roachpb.Intent, len(txns))
+	for i, txn := range txns {
+		intents[i] = roachpb.Intent{Span: span, Txn: txn.TxnMeta, Status: txn.Status}
+	}
+	return tp.ir.runAsyncTask(ctx, tp.r, false /* allowSyncProcessing */, func(ctx context.Context) {
+		if err := tp.ir.resolveIntents(
+			ctx, intents, ResolveOptions{Wait: false, Poison: true},
+		); err != nil {
+			log.Warningf(ctx, "failed to resolve intents of finished transactions: %s", err)
+		}
+	})
+}
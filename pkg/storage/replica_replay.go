@@ -0,0 +1,204 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/cache"
+	"github.com/cockroachdb/cockroach/pkg/util/envutil"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// replayCacheMaxEntries controls how many applied write batches each
+// leaseholder remembers for the purpose of replay detection.
+var replayCacheMaxEntries = envutil.EnvOrDefaultInt("COCKROACH_REPLAY_CACHE_SIZE", 128)
+
+// A replayCache remembers the responses of recently applied write batches
+// carrying a roachpb.RequestID, so that a batch retried by the DistSender
+// after an ambiguous RPC failure is not applied a second time.
+//
+// A cache hit is always authoritative. A miss only proves that the batch has
+// not been applied if the cache has been tracking applied batches since the
+// batch was first issued; this is the case if the batch was issued after
+// trustedSince, after the start of the current lease, and no entry issued
+// after the batch has been evicted.
+type replayCache struct {
+	syncutil.Mutex
+	// trustedSince is the timestamp before which the cache cannot vouch for
+	// the absence of a batch. It is initialized when the cache is created and
+	// ratcheted forward whenever an entry is evicted.
+	trustedSince hlc.Timestamp
+	entries      *cache.UnorderedCache
+}
+
+type replayCacheEntry struct {
+	issued hlc.Timestamp
+	br     *roachpb.BatchResponse
+}
+
+func newReplayCache(now hlc.Timestamp) *replayCache {
+	rc := &replayCache{trustedSince: now}
+	rc.entries = cache.NewUnorderedCache(cache.Config{
+		Policy: cache.CacheFIFO,
+		ShouldEvict: func(size int, _, _ interface{}) bool {
+			return size > replayCacheMaxEntries
+		},
+		OnEvicted: func(_, value interface{}) {
+			// Called with rc locked.
+			rc.trustedSince.Forward(value.(*replayCacheEntry).issued)
+		},
+	})
+	return rc
+}
+
+// add records the response of an applied batch.
+func (rc *replayCache) add(id roachpb.RequestID, br *roachpb.BatchResponse) {
+	rc.Lock()
+	defer rc.Unlock()
+	rc.entries.Add(id.ID, &replayCacheEntry{issued: id.Issued, br: br})
+}
+
+// lookup returns the response of the batch identified by id if it has been
+// applied. If it returns nil, trusted indicates whether the cache can rule
+// out that the batch has been applied at or after the provided lower bound
+// on the time at which the batch could have been applied.
+func (rc *replayCache) lookup(
+	id roachpb.RequestID, since hlc.Timestamp,
+) (br *roachpb.BatchResponse, trusted bool) {
+	rc.Lock()
+	defer rc.Unlock()
+	if v, ok := rc.entries.Get(id.ID); ok {
+		return v.(*replayCacheEntry).br, true
+	}
+	since.Forward(rc.trustedSince)
+	return nil, since.Less(id.Issued)
+}
+
+// getReplayCache returns the replica's replay cache, creating it if
+// necessary.
+func (r *Replica) getReplayCache() *replayCache {
+	r.replayMu.Lock()
+	defer r.replayMu.Unlock()
+	if r.replayMu.cache == nil {
+		r.replayMu.cache = newReplayCache(r.store.Clock().Now())
+	}
+	return r.replayMu.cache
+}
+
+// clearReplayCache releases the replica's replay cache. It is called when the
+// lease changes hands, as the cache only vouches for batches applied under the
+// current lease.
+func (r *Replica) clearReplayCache() {
+	r.replayMu.Lock()
+	defer r.replayMu.Unlock()
+	r.replayMu.cache = nil
+}
+
+// maybeRecordReplay remembers the response of a successfully applied write
+// batch carrying a request ID.
+func (r *Replica) maybeRecordReplay(
+	ba *roachpb.BatchRequest, br *roachpb.BatchResponse, pErr *roachpb.Error,
+) {
+	if ba.RequestID == nil || pErr != nil || br == nil || replayCacheMaxEntries <= 0 ||
+		!ba.RequiresReplayDetection() {
+		return
+	}
+	// The response is handed back to the client, which is free to mutate
+	// it, so keep a copy of our own.
+	r.getReplayCache().add(*ba.RequestID, protoutil.Clone(br).(*roachpb.BatchResponse))
+}
+
+// checkForReplay is called for a write batch carrying a request ID, which the
+// DistSender may have sent more than once. It must be called with the batch's
+// spans held in the command queue, which guarantees that any other attempt
+// proposed here has finished applying. It returns the response of another
+// attempt if one has been applied, and nil if the batch is safe to evaluate.
+//
+// If the cache cannot rule out that another attempt has been applied, a retry
+// gets an AmbiguousResultError, but the first attempt is evaluated: failing it
+// would fail every batch in flight across a lease change, and a retry which
+// overtook it is only missed if it applied under a previous lease or its
+// entry has since been evicted.
+func (r *Replica) checkForReplay(
+	ctx context.Context, ba *roachpb.BatchRequest, lease roachpb.Lease,
+) (*roachpb.BatchResponse, *roachpb.Error) {
+	id := *ba.RequestID
+	br, trusted := r.getReplayCache().lookup(id, r.replayCacheSince(lease))
+	if br != nil {
+		r.store.metrics.ReplaysDetected.Inc(1)
+		log.VEventf(ctx, 2, "detected replay of request %s", id.ID.Short())
+		return protoutil.Clone(br).(*roachpb.BatchResponse), nil
+	}
+	if !trusted && id.Retry {
+		r.store.metrics.ReplaysUndetermined.Inc(1)
+		return nil, roachpb.NewError(roachpb.NewAmbiguousResultError(fmt.Sprintf(
+			"unable to determine whether request %s issued at %s has been applied",
+			id.ID.Short(), id.Issued)))
+	}
+	return nil, nil
+}
+
+// checkCombinedForReplay returns true if a member of a combined increment has
+// been applied already, in which case the combined increment must not be
+// proposed. Like checkForReplay, it must be called with the increment's key
+// held in the command queue. Retries are never combined, so a member which the
+// cache cannot vouch for is evaluated.
+func (r *Replica) checkCombinedForReplay(
+	ctx context.Context, c *combinedIncrements, lease roachpb.Lease,
+) bool {
+	rc := r.getReplayCache()
+	since := r.replayCacheSince(lease)
+	for _, id := range c.ids {
+		if id == nil {
+			continue
+		}
+		if br, _ := rc.lookup(*id, since); br != nil {
+			log.VEventf(ctx, 2, "detected replay of combined request %s", id.ID.Short())
+			return true
+		}
+	}
+	return false
+}
+
+// maybeRecordCombinedReplays remembers the response of each member of a
+// successfully applied combined increment under the member's request ID.
+func (r *Replica) maybeRecordCombinedReplays(
+	c *combinedIncrements, br *roachpb.BatchResponse, pErr *roachpb.Error,
+) {
+	if pErr != nil || br == nil || replayCacheMaxEntries <= 0 {
+		return
+	}
+	rc := r.getReplayCache()
+	for i, id := range c.ids {
+		if id != nil {
+			rc.add(*id, c.memberResponse(br, i))
+		}
+	}
+}
+
+// replayCacheSince returns a lower bound on the time at which a batch applied
+// under the current lease could have been applied.
+func (r *Replica) replayCacheSince(lease roachpb.Lease) hlc.Timestamp {
+	// Any attempt which applied under a previous lease would have applied
+	// before the start of the current one. Account for clock offset between
+	// the gateway which issued the batch and the leaseholders.
+	return lease.Start.Add(r.store.Clock().MaxOffset().Nanoseconds(), 0)
+}
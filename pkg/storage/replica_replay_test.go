@@ -0,0 +1,129 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+func TestReplayCache(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ts := func(wallTime int64) hlc.Timestamp {
+		return hlc.Timestamp{WallTime: wallTime}
+	}
+	makeID := func(issued int64) roachpb.RequestID {
+		return roachpb.RequestID{ID: uuid.MakeV4(), Issued: ts(issued)}
+	}
+
+	rc := newReplayCache(ts(10))
+
+	// A batch issued before the cache was created can't be ruled out.
+	if br, trusted := rc.lookup(makeID(5), ts(0)); br != nil || trusted {
+		t.Fatalf("expected untrusted miss, got %v, %t", br, trusted)
+	}
+	// Nor can a batch issued before the lower bound passed by the caller.
+	if br, trusted := rc.lookup(makeID(15), ts(20)); br != nil || trusted {
+		t.Fatalf("expected untrusted miss, got %v, %t", br, trusted)
+	}
+	// A batch issued after both is known not to have been applied.
+	if br, trusted := rc.lookup(makeID(15), ts(0)); br != nil || !trusted {
+		t.Fatalf("expected trusted miss, got %v, %t", br, trusted)
+	}
+
+	// Fill the cache.
+	ids := make([]roachpb.RequestID, replayCacheMaxEntries)
+	for i := range ids {
+		ids[i] = makeID(int64(20 + i))
+		rc.add(ids[i], &roachpb.BatchResponse{})
+	}
+	for i, id := range ids {
+		if br, _ := rc.lookup(id, ts(0)); br == nil {
+			t.Fatalf("%d: expected hit for %s", i, id.ID)
+		}
+	}
+
+	// Overflow it. The oldest entry is evicted, after which the cache can no
+	// longer vouch for batches issued before the evicted one.
+	rc.add(makeID(1000), &roachpb.BatchResponse{})
+	if br, trusted := rc.lookup(ids[0], ts(0)); br != nil || trusted {
+		t.Fatalf("expected untrusted miss for evicted entry, got %v, %t", br, trusted)
+	}
+	if br, _ := rc.lookup(ids[1], ts(0)); br == nil {
+		t.Fatalf("expected hit for %s", ids[1].ID)
+	}
+	if br, trusted := rc.lookup(makeID(int64(20+len(ids))), ts(0)); br != nil || !trusted {
+		t.Fatalf("expected trusted miss, got %v, %t", br, trusted)
+	}
+}
+
+// TestReplicaReplayOutOfOrder verifies that the first attempt of a batch is
+// not applied if it arrives after a retry of the batch has been applied.
+func TestReplicaReplayOutOfOrder(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	tc := testContext{}
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	tc.Start(t, stopper)
+
+	// Make sure the replay cache tracks the batch from the time it's issued.
+	tc.repl.getReplayCache()
+	tc.manualClock.Increment(10)
+	id := roachpb.RequestID{ID: uuid.MakeV4(), Issued: tc.Clock().Now()}
+
+	key := roachpb.Key("a")
+	send := func(retry bool) int64 {
+		t.Helper()
+		attempt := id
+		attempt.Retry = retry
+		inc := incrementArgs(key, 1)
+		resp, pErr := client.SendWrappedWith(
+			context.TODO(), tc.Sender(), roachpb.Header{RequestID: &attempt}, &inc,
+		)
+		if pErr != nil {
+			t.Fatal(pErr)
+		}
+		return resp.(*roachpb.IncrementResponse).NewValue
+	}
+
+	// The retry overtakes the first attempt and is applied.
+	if v := send(true /* retry */); v != 1 {
+		t.Fatalf("expected 1, got %d", v)
+	}
+	// The first attempt gets the retry's response instead of being applied.
+	if v := send(false /* retry */); v != 1 {
+		t.Fatalf("expected 1, got %d", v)
+	}
+	if n := tc.store.metrics.ReplaysDetected.Count(); n != 1 {
+		t.Fatalf("expected 1 replay detected, got %d", n)
+	}
+
+	inc := incrementArgs(key, 0)
+	resp, pErr := client.SendWrapped(context.TODO(), tc.Sender(), &inc)
+	if pErr != nil {
+		t.Fatal(pErr)
+	}
+	if v := resp.(*roachpb.IncrementResponse).NewValue; v != 1 {
+		t.Fatalf("expected the increment to be applied once, got %d", v)
+	}
+}
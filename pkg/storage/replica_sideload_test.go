@@ -688,16 +688,26 @@ func TestRaftSSTableSideloadingProposal(t *testing.T) {
 }
 
 type mockSender struct {
-	logEntries [][]byte
-	done       bool
+	compression CompressionType
+	logEntries  [][]byte
+	done        bool
 }
 
 func (mr *mockSender) Send(req *SnapshotRequest) error {
+	if req.Header != nil {
+		mr.compression = req.Header.Compression
+	}
 	if req.LogEntries != nil {
 		if mr.logEntries != nil {
 			return errors.New("already have log entries")
 		}
-		mr.logEntries = req.LogEntries
+		for _, ent := range req.LogEntries {
+			ent, err := decompress(mr.compression, ent)
+			if err != nil {
+				return err
+			}
+			mr.logEntries = append(mr.logEntries, ent)
+		}
 	}
 	return nil
 }
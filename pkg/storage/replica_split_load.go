@@ -0,0 +1,95 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// SplitByLoadEnabled wraps "kv.range_split.by_load_enabled".
+var SplitByLoadEnabled = settings.RegisterBoolSetting(
+	"kv.range_split.by_load_enabled",
+	"allow automatic splits of ranges based on where load is concentrated",
+	true,
+)
+
+// SplitByLoadQPSThreshold wraps "kv.range_split.load_qps_threshold".
+var SplitByLoadQPSThreshold = settings.RegisterIntSetting(
+	"kv.range_split.load_qps_threshold",
+	"the QPS over which the range becomes a candidate for load based splitting",
+	250,
+)
+
+// splitByLoadEnabled returns whether load-based splitting is enabled.
+func (r *Replica) splitByLoadEnabled() bool {
+	return SplitByLoadEnabled.Get(&r.store.cfg.Settings.SV) &&
+		!r.store.TestingKnobs().DisableLoadBasedSplitting
+}
+
+// splitByLoadQPSThreshold returns the number of requests per second above
+// which the replica is considered for load-based splitting.
+func (r *Replica) splitByLoadQPSThreshold() float64 {
+	return float64(SplitByLoadQPSThreshold.Get(&r.store.cfg.Settings.SV))
+}
+
+// recordLoadForSplit records the batch with the load-based splitter and
+// queues the replica in the split queue once a split key has been found.
+func (r *Replica) recordLoadForSplit(ba roachpb.BatchRequest) {
+	if !r.splitByLoadEnabled() {
+		return
+	}
+	if r.loadBasedSplitter.Record(timeutil.Now(), len(ba.Requests), func() roachpb.Span {
+		rspan, err := keys.Range(ba)
+		if err != nil {
+			return roachpb.Span{}
+		}
+		return rspan.AsRawSpanWithNoLocals()
+	}) {
+		r.store.splitQueue.MaybeAdd(r, r.store.Clock().Now())
+	}
+}
+
+// loadSplitKey returns the key at which to split the range to balance its
+// load, or nil if the range shouldn't be split by load. The key returned by
+// the splitter is adjusted so that it doesn't split a SQL row.
+func (r *Replica) loadSplitKey() roachpb.Key {
+	if !r.splitByLoadEnabled() {
+		return nil
+	}
+	splitKey := r.loadBasedSplitter.MaybeSplitKey(timeutil.Now())
+	if splitKey == nil {
+		return nil
+	}
+	if _, _, err := keys.DecodeTablePrefix(splitKey); err == nil {
+		if splitKey, err = keys.EnsureSafeSplitKey(splitKey); err != nil {
+			return nil
+		}
+	}
+	desc := r.Desc()
+	if rkey, err := keys.Addr(splitKey); err != nil || !rkey.Equal(splitKey) ||
+		!desc.ContainsKey(rkey) || desc.StartKey.Equal(rkey) {
+		return nil
+	}
+	return splitKey
+}
+
+// LoadSplitStats returns the number of requests per second tracked by the
+// load-based splitter and the key at which it would split the range, if any.
+func (r *Replica) LoadSplitStats() (float64, roachpb.Key) {
+	return r.loadBasedSplitter.LastQPS(timeutil.Now()), r.loadSplitKey()
+}
@@ -4087,6 +4087,67 @@ func TestEndTransactionBeforeHeartbeat(t *testing.T) {
 	}
 }
 
+// TestEndTransactionStaging verifies that an EndTransaction carrying
+// in-flight writes stages the transaction record, that a STAGING record
+// which hasn't expired can't be pushed, and that it can still be committed
+// explicitly.
+func TestEndTransactionStaging(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer setTxnAutoGC(false)()
+	tc := testContext{}
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	tc.Start(t, stopper)
+
+	key := roachpb.Key("a")
+	txn := newTransaction("test", key, 1, enginepb.SERIALIZABLE, tc.Clock())
+	_, btH := beginTxnArgs(key, txn)
+	put := putArgs(key, key)
+	assignSeqNumsForReqs(txn, &put)
+	if _, pErr := maybeWrapWithBeginTransaction(context.Background(), tc.Sender(), btH, &put); pErr != nil {
+		t.Fatal(pErr)
+	}
+	txn.Writing = true
+
+	// Stage the commit.
+	args, h := endTxnArgs(txn, true /* commit */)
+	args.IntentSpans = []roachpb.Span{{Key: key}}
+	args.InFlightWrites = []roachpb.SequencedWrite{{Key: key, Sequence: txn.Sequence}}
+	assignSeqNumsForReqs(txn, &args)
+	resp, pErr := tc.SendWrappedWith(h, &args)
+	if pErr != nil {
+		t.Fatal(pErr)
+	}
+	if reply := resp.(*roachpb.EndTransactionResponse); reply.Txn.Status != roachpb.STAGING {
+		t.Fatalf("expected transaction status to be %s; got %s", roachpb.STAGING, reply.Txn.Status)
+	}
+
+	// A push of the live STAGING transaction fails, regardless of priority.
+	pusher := newTransaction("test", key, 1, enginepb.SERIALIZABLE, tc.Clock())
+	pusher.Priority = roachpb.MaxTxnPriority
+	pArgs := pushTxnArgs(pusher, txn, roachpb.PUSH_ABORT)
+	pArgs.Now = tc.Clock().Now()
+	if _, pErr := tc.SendWrapped(&pArgs); !testutils.IsPError(pErr, "failed to push") {
+		t.Fatalf("expected push error; got %v", pErr)
+	}
+
+	// The coordinator makes the commit explicit.
+	args, h = endTxnArgs(txn, true /* commit */)
+	args.IntentSpans = []roachpb.Span{{Key: key}}
+	assignSeqNumsForReqs(txn, &args)
+	resp, pErr = tc.SendWrappedWith(h, &args)
+	if pErr != nil {
+		t.Fatal(pErr)
+	}
+	reply := resp.(*roachpb.EndTransactionResponse)
+	if reply.Txn.Status != roachpb.COMMITTED {
+		t.Fatalf("expected transaction status to be %s; got %s", roachpb.COMMITTED, reply.Txn.Status)
+	}
+	if len(reply.Txn.InFlightWrites) != 0 {
+		t.Fatalf("expected no in-flight writes; got %v", reply.Txn.InFlightWrites)
+	}
+}
+
 // TestEndTransactionAfterHeartbeat verifies that a transaction
 // can be committed/aborted after being heartbeat.
 func TestEndTransactionAfterHeartbeat(t *testing.T) {
@@ -7362,7 +7423,7 @@ func TestReplicaCancelRaft(t *testing.T) {
 			if err := ba.SetActiveTimestamp(tc.Clock().Now); err != nil {
 				t.Fatal(err)
 			}
-			_, pErr := tc.repl.executeWriteBatch(ctx, ba)
+			_, pErr := tc.repl.executeWriteBatch(ctx, ba, nil /* combined */)
 			if cancelEarly {
 				if !testutils.IsPError(pErr, context.Canceled.Error()) {
 					t.Fatalf("expected canceled error; got %v", pErr)
@@ -7419,7 +7480,7 @@ func TestReplicaTryAbandon(t *testing.T) {
 	if err := ba.SetActiveTimestamp(tc.Clock().Now); err != nil {
 		t.Fatal(err)
 	}
-	_, pErr := tc.repl.executeWriteBatch(ctx, ba)
+	_, pErr := tc.repl.executeWriteBatch(ctx, ba, nil /* combined */)
 	if pErr == nil {
 		t.Fatalf("expected failure, but found success")
 	}
@@ -7811,6 +7872,7 @@ func TestReplicaRetryRaftProposal(t *testing.T) {
 		br, pErr, retry := tc.repl.tryExecuteWriteBatch(
 			context.WithValue(ctx, magicKey{}, "foo"),
 			ba,
+			nil, /* combined */
 		)
 		if retry != proposalIllegalLeaseIndex {
 			t.Fatalf("expected retry from illegal lease index, but got (%v, %v, %d)", br, pErr, retry)
@@ -7825,6 +7887,7 @@ func TestReplicaRetryRaftProposal(t *testing.T) {
 		br, pErr := tc.repl.executeWriteBatch(
 			context.WithValue(ctx, magicKey{}, "foo"),
 			ba,
+			nil, /* combined */
 		)
 		if pErr != nil {
 			t.Fatal(pErr)
@@ -7858,6 +7921,7 @@ func TestReplicaRetryRaftProposal(t *testing.T) {
 		_, pErr := tc.repl.executeWriteBatch(
 			context.WithValue(ctx, magicKey{}, "foo"),
 			ba,
+			nil, /* combined */
 		)
 		if pErr != nil {
 			t.Fatal(pErr)
@@ -10274,3 +10338,33 @@ func TestReplicaShouldCampaignOnWake(t *testing.T) {
 		}
 	}
 }
+
+// TestReplicaEgressStats verifies that the size of responses returned to
+// clients is attributed to the gateway node that sent the request.
+func TestReplicaEgressStats(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	tc := testContext{}
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	tc.Start(t, stopper)
+
+	key := roachpb.Key("a")
+	pArgs := putArgs(key, []byte("value"))
+	if _, pErr := tc.SendWrapped(&pArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+	// Requests without a gateway node aren't tracked.
+	if counts, _ := tc.repl.egressStats.perLocalityDecayingQPS(); len(counts) != 0 {
+		t.Fatalf("expected no egress to be recorded, got %v", counts)
+	}
+
+	gArgs := getArgs(key)
+	if _, pErr := tc.SendWrappedWith(roachpb.Header{GatewayNodeID: 5}, &gArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+	// Node 5 is unknown to the store pool, so its locality is empty.
+	counts, _ := tc.repl.egressStats.perLocalityDecayingQPS()
+	if len(counts) != 1 || counts[""] <= 0 {
+		t.Fatalf("expected egress to be recorded for the gateway, got %v", counts)
+	}
+}
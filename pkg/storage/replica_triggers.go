@@ -0,0 +1,63 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/batcheval/result"
+)
+
+// A TriggerHandler handles a trigger returned by a command on the replica
+// which evaluated the command. For commands which are proposed to Raft, it
+// runs once the command has applied; for read-only commands, it runs once the
+// command has been evaluated, and an error fails the request.
+//
+// Handlers for commands proposed to Raft are called while the replica's
+// raftMu is held, so they must not block.
+type TriggerHandler func(ctx context.Context, r *Replica, t result.Trigger) error
+
+var triggerHandlers = make(map[roachpb.Method][]TriggerHandler)
+
+// RegisterTriggerHandler registers a handler for the triggers returned by
+// commands with the given method. Handlers for the same method run in the
+// order in which they were registered. It must only be called before any
+// evaluation takes place, e.g. from an init function.
+func RegisterTriggerHandler(method roachpb.Method, h TriggerHandler) {
+	triggerHandlers[method] = append(triggerHandlers[method], h)
+}
+
+// handleTriggers runs the registered handlers for each of the triggers,
+// stopping at the first error.
+func (r *Replica) handleTriggers(ctx context.Context, triggers []result.Trigger) error {
+	for _, t := range triggers {
+		for _, h := range triggerHandlers[t.Method] {
+			if err := h(ctx, r, t); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func init() {
+	RegisterTriggerHandler(roachpb.GetSnapshotForMerge,
+		func(ctx context.Context, r *Replica, _ result.Trigger) error {
+			// Block all traffic until the merge transaction between this range
+			// and its left neighbor completes.
+			return r.maybeWatchForMerge(ctx)
+		})
+}
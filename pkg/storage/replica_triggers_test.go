@@ -0,0 +1,76 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/batcheval/result"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/pkg/errors"
+)
+
+func TestReplicaHandleTriggers(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	defer func(orig map[roachpb.Method][]TriggerHandler) {
+		triggerHandlers = orig
+	}(triggerHandlers)
+	triggerHandlers = make(map[roachpb.Method][]TriggerHandler)
+
+	var handled []string
+	handler := func(name string) TriggerHandler {
+		return func(_ context.Context, _ *Replica, t result.Trigger) error {
+			handled = append(handled, name)
+			if t.Payload != nil {
+				return t.Payload.(error)
+			}
+			return nil
+		}
+	}
+	RegisterTriggerHandler(roachpb.Put, handler("put1"))
+	RegisterTriggerHandler(roachpb.Put, handler("put2"))
+	RegisterTriggerHandler(roachpb.Delete, handler("delete"))
+
+	ctx := context.Background()
+	var r *Replica
+	if err := r.handleTriggers(ctx, []result.Trigger{
+		{Method: roachpb.Delete},
+		{Method: roachpb.Scan},
+		{Method: roachpb.Put},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"delete", "put1", "put2"}; !reflect.DeepEqual(expected, handled) {
+		t.Fatalf("expected handlers %v to run, got %v", expected, handled)
+	}
+
+	// Handling stops at the first error.
+	handled = nil
+	err := r.handleTriggers(ctx, []result.Trigger{
+		{Method: roachpb.Put, Payload: errors.New("boom")},
+		{Method: roachpb.Delete},
+	})
+	if !testutils.IsError(err, "boom") {
+		t.Fatalf("expected error, got %v", err)
+	}
+	if expected := []string{"put1"}; !reflect.DeepEqual(expected, handled) {
+		t.Fatalf("expected handlers %v to run, got %v", expected, handled)
+	}
+}
@@ -207,7 +207,7 @@ func (rq *replicateQueue) shouldQueue(
 	if lease, _ := repl.GetLease(); repl.IsLeaseValid(lease, now) {
 		if rq.canTransferLease() &&
 			rq.allocator.ShouldTransferLease(
-				ctx, zone, desc.Replicas, lease.Replica.StoreID, desc.RangeID, repl.leaseholderStats) {
+				ctx, zone, desc.Voters(), lease.Replica.StoreID, desc.RangeID, repl.leaseholderStats) {
 			log.VEventf(ctx, 2, "lease transfer needed, enqueuing")
 			return true, 0
 		}
@@ -260,12 +260,13 @@ func (rq *replicateQueue) processOneChange(
 	disableStatsBasedRebalancing bool,
 ) (requeue bool, _ error) {
 	desc := repl.Desc()
+	voters := desc.Voters()
 
 	// Avoid taking action if the range has too many dead replicas to make
 	// quorum.
-	liveReplicas, deadReplicas := rq.allocator.storePool.liveAndDeadReplicas(desc.RangeID, desc.Replicas)
+	liveReplicas, deadReplicas := rq.allocator.storePool.liveAndDeadReplicas(desc.RangeID, voters)
 	{
-		quorum := computeQuorum(len(desc.Replicas))
+		quorum := computeQuorum(len(voters))
 		if lr := len(liveReplicas); lr < quorum {
 			return false, errors.Errorf(
 				"range requires a replication change, but lacks a quorum of live replicas (%d/%d)", lr, quorum)
@@ -299,7 +300,7 @@ func (rq *replicateQueue) processOneChange(
 		}
 
 		need := int(zone.NumReplicas)
-		willHave := len(desc.Replicas) + 1
+		willHave := len(voters) + 1
 
 		// Only up-replicate if there are suitable allocation targets such
 		// that, either the replication goal is met, or it is possible to get to the
@@ -352,9 +353,9 @@ func (rq *replicateQueue) processOneChange(
 		if timeutil.Since(lastAddedTime) > newReplicaGracePeriod {
 			lastReplAdded = 0
 		}
-		candidates := filterUnremovableReplicas(repl.RaftStatus(), desc.Replicas, lastReplAdded)
+		candidates := filterUnremovableReplicas(repl.RaftStatus(), voters, lastReplAdded)
 		log.VEventf(ctx, 3, "filtered unremovable replicas from %v to get %v as candidates for removal",
-			desc.Replicas, candidates)
+			voters, candidates)
 		if len(candidates) == 0 {
 			return false, errors.Errorf("no removable replicas from range that needs a removal: %s",
 				rangeRaftProgress(repl.RaftStatus(), desc.Replicas))
@@ -406,7 +407,7 @@ func (rq *replicateQueue) processOneChange(
 		}
 	case AllocatorRemoveDecommissioning:
 		log.VEventf(ctx, 1, "removing a decommissioning replica")
-		decommissioningReplicas := rq.allocator.storePool.decommissioningReplicas(desc.RangeID, desc.Replicas)
+		decommissioningReplicas := rq.allocator.storePool.decommissioningReplicas(desc.RangeID, voters)
 		if len(decommissioningReplicas) == 0 {
 			log.VEventf(ctx, 1, "range of replica %s was identified as having decommissioning replicas, "+
 				"but no decommissioning replicas were found", repl)
@@ -466,6 +467,59 @@ func (rq *replicateQueue) processOneChange(
 		); err != nil {
 			return false, err
 		}
+	case AllocatorAddLearner:
+		log.VEventf(ctx, 1, "adding a new learner")
+		newStore, details, err := rq.allocator.AllocateLearnerTarget(
+			ctx,
+			zone,
+			rangeInfo,
+			disableStatsBasedRebalancing,
+		)
+		if err != nil {
+			return false, err
+		}
+		newLearner := roachpb.ReplicationTarget{
+			NodeID:  newStore.Node.NodeID,
+			StoreID: newStore.StoreID,
+		}
+		rq.metrics.AddReplicaCount.Inc(1)
+		log.VEventf(ctx, 1, "adding learner %+v: %s",
+			newLearner, rangeRaftProgress(repl.RaftStatus(), desc.Replicas))
+		if err := rq.addLearner(
+			ctx, repl, newLearner, desc, ReasonRangeUnderReplicated, details, dryRun,
+		); err != nil {
+			return false, err
+		}
+	case AllocatorRemoveLearner:
+		log.VEventf(ctx, 1, "removing a learner")
+		var removeLearner roachpb.ReplicaDescriptor
+		var details string
+		if invalid := rq.allocator.invalidLearners(zone, desc); len(invalid) > 0 {
+			removeLearner = invalid[0]
+		} else {
+			learners := desc.Learners()
+			if len(learners) == 0 {
+				log.VEventf(ctx, 1, "range of replica %s was identified as having extra learners, "+
+					"but no learners were found", repl)
+				break
+			}
+			removeLearner, details, err = rq.allocator.RemoveTarget(
+				ctx, learnerZone(zone), learners, rangeInfo, disableStatsBasedRebalancing)
+			if err != nil {
+				return false, err
+			}
+		}
+		rq.metrics.RemoveReplicaCount.Inc(1)
+		log.VEventf(ctx, 1, "removing learner %+v", removeLearner)
+		target := roachpb.ReplicationTarget{
+			NodeID:  removeLearner.NodeID,
+			StoreID: removeLearner.StoreID,
+		}
+		if err := rq.removeReplica(
+			ctx, repl, target, desc, ReasonRangeOverReplicated, details, dryRun,
+		); err != nil {
+			return false, err
+		}
 	case AllocatorConsiderRebalance:
 		// The Noop case will result if this replica was queued in order to
 		// rebalance. Attempt to find a rebalancing target.
@@ -544,7 +598,7 @@ func (rq *replicateQueue) transferLease(
 	zone config.ZoneConfig,
 	opts transferLeaseOptions,
 ) (bool, error) {
-	candidates := filterBehindReplicas(repl.RaftStatus(), desc.Replicas, 0 /* brandNewReplicaID */)
+	candidates := filterBehindReplicas(repl.RaftStatus(), desc.Voters(), 0 /* brandNewReplicaID */)
 	if target := rq.allocator.TransferLeaseTarget(
 		ctx,
 		zone,
@@ -591,6 +645,28 @@ func (rq *replicateQueue) addReplica(
 	return nil
 }
 
+func (rq *replicateQueue) addLearner(
+	ctx context.Context,
+	repl *Replica,
+	target roachpb.ReplicationTarget,
+	desc *roachpb.RangeDescriptor,
+	reason RangeLogEventReason,
+	details string,
+	dryRun bool,
+) error {
+	if dryRun {
+		return nil
+	}
+	if err := repl.changeReplicas(
+		ctx, roachpb.ADD_LEARNER, target, desc, SnapshotRequest_RECOVERY, reason, details,
+	); err != nil {
+		return err
+	}
+	rangeInfo := rangeInfoForRepl(repl, desc)
+	rq.allocator.storePool.updateLocalStoreAfterRebalance(target.StoreID, rangeInfo, roachpb.ADD_LEARNER)
+	return nil
+}
+
 func (rq *replicateQueue) removeReplica(
 	ctx context.Context,
 	repl *Replica,
@@ -0,0 +1,263 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// ReplicationReportsInterval is the interval at which the replication
+// reports are regenerated.
+var ReplicationReportsInterval = settings.RegisterNonNegativeDurationSetting(
+	"kv.replication_reports.interval",
+	"the frequency at which the replication reports in system.replication_reports are generated (0 disables)",
+	time.Minute,
+)
+
+// The kinds of replication reports. The subject of a constraint report is
+// the constraint, and the subject of a critical locality report is the
+// locality. The other reports have no subject.
+const (
+	reportUnavailable      = "unavailable"
+	reportUnderReplicated  = "under_replicated"
+	reportOverReplicated   = "over_replicated"
+	reportConstraint       = "constraint"
+	reportCriticalLocality = "critical_locality"
+)
+
+// replicationReportBatchSize is the number of rows written to
+// system.replication_reports per statement.
+const replicationReportBatchSize = 100
+
+type replicationReportKey struct {
+	objectID uint32
+	report   string
+	subject  string
+}
+
+// replicationReport counts, for every object and kind of report, the ranges
+// that are in violation.
+type replicationReport map[replicationReportKey]int
+
+// add records a range for the given report. A range that isn't in violation
+// still creates the row, so that the report lists what was checked.
+func (rr replicationReport) add(objectID uint32, report, subject string, violation bool) {
+	key := replicationReportKey{objectID: objectID, report: report, subject: subject}
+	if violation {
+		rr[key]++
+	} else if _, ok := rr[key]; !ok {
+		rr[key] = 0
+	}
+}
+
+// addRange checks the replicas of a range against the zone config that
+// applies to it.
+func (rr replicationReport) addRange(
+	objectID uint32,
+	desc *roachpb.RangeDescriptor,
+	zone config.ZoneConfig,
+	getStoreDesc func(roachpb.StoreID) (roachpb.StoreDescriptor, bool),
+	isLive func(roachpb.NodeID) bool,
+) {
+	replicas := desc.Replicas
+	quorum := computeQuorum(len(replicas))
+	var live int
+	for _, r := range replicas {
+		if isLive(r.NodeID) {
+			live++
+		}
+	}
+	rr.add(objectID, reportUnavailable, "", live < quorum)
+	rr.add(objectID, reportUnderReplicated, "", live < int(zone.NumReplicas))
+	rr.add(objectID, reportOverReplicated, "", len(replicas) > int(zone.NumReplicas))
+
+	stores := make([]roachpb.StoreDescriptor, 0, len(replicas))
+	for _, r := range replicas {
+		if store, ok := getStoreDesc(r.StoreID); ok {
+			stores = append(stores, store)
+		}
+	}
+
+	for _, c := range zone.Constraints {
+		var matching int
+		for _, store := range stores {
+			if subConstraintsCheck(store, c.Constraints) {
+				matching++
+			}
+		}
+		// Constraints without a number of replicas apply to all of them.
+		required := int(c.NumReplicas)
+		if required == 0 {
+			required = len(replicas)
+		}
+		rr.add(objectID, reportConstraint, constraintsString(c), matching < required)
+	}
+
+	// A locality is critical for the range if losing all of its nodes would
+	// make the range lose quorum. Every prefix of the tiers of a locality is a
+	// locality of its own, e.g. region=us is a locality of region=us,zone=a.
+	localities := make(map[string]int)
+	for _, store := range stores {
+		tiers := store.Node.Locality.Tiers
+		for i := range tiers {
+			localities[roachpb.Locality{Tiers: tiers[:i+1]}.String()]++
+		}
+	}
+	for locality, n := range localities {
+		if len(replicas)-n < quorum {
+			rr.add(objectID, reportCriticalLocality, locality, true)
+		}
+	}
+}
+
+// constraintsString formats a conjunction of constraints like the zone config
+// YAML does, e.g. "+region=us,-ssd:2".
+func constraintsString(c config.Constraints) string {
+	var buf bytes.Buffer
+	for i, constraint := range c.Constraints {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(constraint.String())
+	}
+	if c.NumReplicas > 0 {
+		fmt.Fprintf(&buf, ":%d", c.NumReplicas)
+	}
+	return buf.String()
+}
+
+// startReplicationReporter starts a goroutine that periodically generates the
+// replication reports if the store holds the lease of the first range, so
+// that a single store in the cluster does it.
+func (s *Store) startReplicationReporter(ctx context.Context) {
+	s.stopper.RunWorker(ctx, func(ctx context.Context) {
+		var timer timeutil.Timer
+		defer timer.Stop()
+		for {
+			// While the reports are disabled, check the setting every minute.
+			interval := ReplicationReportsInterval.Get(&s.cfg.Settings.SV)
+			if interval == 0 {
+				interval = time.Minute
+			}
+			timer.Reset(interval)
+			select {
+			case <-s.stopper.ShouldQuiesce():
+				return
+			case <-timer.C:
+				timer.Read = true
+			}
+
+			if ReplicationReportsInterval.Get(&s.cfg.Settings.SV) == 0 {
+				continue
+			}
+			repl := s.LookupReplica(roachpb.RKeyMin, nil)
+			if repl == nil || !repl.OwnsValidLease(s.cfg.Clock.Now()) {
+				continue
+			}
+			if err := s.generateReplicationReport(ctx); err != nil {
+				log.Warningf(ctx, "failed to generate the replication reports: %v", err)
+			}
+		}
+	})
+}
+
+// generateReplicationReport scans the range descriptors in meta2, checks
+// them against their zone configs and replaces the contents of
+// system.replication_reports with the result.
+func (s *Store) generateReplicationReport(ctx context.Context) error {
+	sysCfg, ok := s.cfg.Gossip.GetSystemConfig()
+	if !ok {
+		return errors.New("system config not yet available")
+	}
+	liveMap := s.cfg.NodeLiveness.GetIsLiveMap()
+	isLive := func(nodeID roachpb.NodeID) bool { return liveMap[nodeID] }
+
+	var report replicationReport
+	if err := s.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		const pageSize = 10000
+		report = make(replicationReport)
+		return txn.Iterate(ctx, keys.Meta2Prefix, keys.MetaMax, pageSize,
+			func(rows []client.KeyValue) error {
+				var desc roachpb.RangeDescriptor
+				for _, row := range rows {
+					if err := row.ValueProto(&desc); err != nil {
+						return errors.Wrapf(err, "%s: unable to unmarshal range descriptor", row.Key)
+					}
+					zone, err := sysCfg.GetZoneConfigForKey(desc.StartKey)
+					if err != nil {
+						return err
+					}
+					objectID, _ := config.ObjectIDForKey(desc.StartKey)
+					report.addRange(objectID, &desc, zone, s.cfg.StorePool.getStoreDescriptor, isLive)
+				}
+				return nil
+			})
+	}); err != nil {
+		return err
+	}
+	return s.saveReplicationReport(ctx, report, timeutil.Now())
+}
+
+// saveReplicationReport replaces the contents of system.replication_reports
+// with the given report.
+func (s *Store) saveReplicationReport(
+	ctx context.Context, report replicationReport, generated time.Time,
+) error {
+	return s.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		if _, err := s.cfg.SQLExecutor.Exec(
+			ctx, "delete-replication-reports", txn, `DELETE FROM system.replication_reports`,
+		); err != nil {
+			return err
+		}
+
+		var values []string
+		var args []interface{}
+		flush := func() error {
+			if len(values) == 0 {
+				return nil
+			}
+			stmt := `INSERT INTO system.replication_reports (object_id, report, subject, ranges, generated) VALUES ` +
+				strings.Join(values, ", ")
+			_, err := s.cfg.SQLExecutor.Exec(ctx, "insert-replication-reports", txn, stmt, args...)
+			values, args = values[:0], args[:0]
+			return err
+		}
+		for key, ranges := range report {
+			n := len(args)
+			values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5))
+			args = append(args, key.objectID, key.report, key.subject, ranges, generated)
+			if len(values) == replicationReportBatchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+		return flush()
+	})
+}
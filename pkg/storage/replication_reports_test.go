@@ -0,0 +1,107 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestReplicationReport(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	// Stores 1 and 2 are in us-east, store 3 in us-west, and store 4 in eu.
+	localities := map[roachpb.StoreID]string{
+		1: "region=us,zone=east",
+		2: "region=us,zone=east",
+		3: "region=us,zone=west",
+		4: "region=eu,zone=west",
+	}
+	getStoreDesc := func(storeID roachpb.StoreID) (roachpb.StoreDescriptor, bool) {
+		var locality roachpb.Locality
+		if err := locality.Set(localities[storeID]); err != nil {
+			t.Fatal(err)
+		}
+		return roachpb.StoreDescriptor{
+			StoreID: storeID,
+			Node:    roachpb.NodeDescriptor{NodeID: roachpb.NodeID(storeID), Locality: locality},
+		}, true
+	}
+	// Node 4 is dead.
+	isLive := func(nodeID roachpb.NodeID) bool { return nodeID != 4 }
+	rangeOn := func(storeIDs ...roachpb.StoreID) *roachpb.RangeDescriptor {
+		desc := &roachpb.RangeDescriptor{}
+		for _, id := range storeIDs {
+			desc.Replicas = append(desc.Replicas, roachpb.ReplicaDescriptor{
+				NodeID: roachpb.NodeID(id), StoreID: id,
+			})
+		}
+		return desc
+	}
+
+	zone := config.ZoneConfig{
+		NumReplicas: 3,
+		Constraints: []config.Constraints{
+			{Constraints: []config.Constraint{{Type: config.Constraint_REQUIRED, Key: "region", Value: "us"}}},
+		},
+	}
+	rr := make(replicationReport)
+	rr.addRange(50, rangeOn(1, 2, 3), zone, getStoreDesc, isLive)
+	rr.addRange(50, rangeOn(1, 3, 4), zone, getStoreDesc, isLive)
+	rr.addRange(50, rangeOn(3, 4), zone, getStoreDesc, isLive)
+	rr.addRange(51, rangeOn(1, 2, 3, 4), config.ZoneConfig{NumReplicas: 3}, getStoreDesc, isLive)
+
+	expected := replicationReport{
+		{50, reportUnavailable, ""}:          1,
+		{50, reportUnderReplicated, ""}:      2,
+		{50, reportOverReplicated, ""}:       0,
+		{50, reportConstraint, "+region=us"}: 2,
+		// Losing us-east makes the first range lose quorum, and losing us
+		// makes the first two lose quorum. The third range has already lost
+		// quorum, so all of its localities are critical.
+		{50, reportCriticalLocality, "region=us"}:           3,
+		{50, reportCriticalLocality, "region=us,zone=east"}: 1,
+		{50, reportCriticalLocality, "region=us,zone=west"}: 1,
+		{50, reportCriticalLocality, "region=eu"}:           1,
+		{50, reportCriticalLocality, "region=eu,zone=west"}: 1,
+		{51, reportUnavailable, ""}:                         0,
+		{51, reportUnderReplicated, ""}:                     0,
+		{51, reportOverReplicated, ""}:                      1,
+		{51, reportCriticalLocality, "region=us"}:           1,
+		{51, reportCriticalLocality, "region=us,zone=east"}: 1,
+	}
+	if !reflect.DeepEqual(rr, expected) {
+		t.Fatalf("expected\n%v\ngot\n%v", expected, rr)
+	}
+}
+
+func TestConstraintsString(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	c := config.Constraints{
+		NumReplicas: 2,
+		Constraints: []config.Constraint{
+			{Type: config.Constraint_REQUIRED, Key: "region", Value: "us"},
+			{Type: config.Constraint_PROHIBITED, Value: "ssd"},
+		},
+	}
+	if s := constraintsString(c); s != "+region=us,-ssd:2" {
+		t.Fatalf("unexpected constraints string %q", s)
+	}
+}
@@ -19,11 +19,15 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 )
 
 const rangeIDChunkSize = 1000
@@ -124,16 +128,64 @@ const (
 	stateRaftTick
 )
 
+// raftPriority is the priority class of a range in the Raft scheduler.
+type raftPriority int
+
+const (
+	// raftPriorityNormal is the priority of the user ranges.
+	raftPriorityNormal raftPriority = iota
+	// raftPriorityHigh is the priority of the ranges that the whole cluster
+	// depends on (the meta ranges and the node liveness range), which are
+	// processed ahead of the others when the scheduler is overloaded so that
+	// the nodes don't lose their liveness and the ranges can still be
+	// addressed.
+	raftPriorityHigh
+
+	numRaftPriorities
+)
+
+// raftSchedulerHighPriorityBurst is the maximum number of high priority
+// ranges processed by a worker in a row while normal priority ranges are
+// waiting, so that a busy system range can't starve the user ranges.
+const raftSchedulerHighPriorityBurst = 16
+
+// raftPriorityForDesc returns the priority class of the range with the given
+// descriptor.
+func raftPriorityForDesc(desc *roachpb.RangeDescriptor) raftPriority {
+	if desc == nil || !desc.IsInitialized() {
+		return raftPriorityNormal
+	}
+	if desc.StartKey.Less(roachpb.RKey(keys.Meta2KeyMax)) {
+		return raftPriorityHigh
+	}
+	if desc.StartKey.Less(roachpb.RKey(keys.NodeLivenessKeyMax)) &&
+		roachpb.RKey(keys.NodeLivenessPrefix).Less(desc.EndKey) {
+		return raftPriorityHigh
+	}
+	return raftPriorityNormal
+}
+
 type raftScheduler struct {
 	processor  raftProcessor
 	numWorkers int
+	// latency holds, for each priority class, the histogram of the time spent
+	// by the ranges in the queue. It's nil if the scheduler has no metrics.
+	latency [numRaftPriorities]*metric.Histogram
 
 	mu struct {
 		syncutil.Mutex
-		cond    *sync.Cond
-		queue   rangeIDQueue
-		state   map[roachpb.RangeID]raftScheduleState
-		stopped bool
+		cond   *sync.Cond
+		queues [numRaftPriorities]rangeIDQueue
+		state  map[roachpb.RangeID]raftScheduleState
+		// highPriority is the set of the ranges of priority raftPriorityHigh.
+		highPriority map[roachpb.RangeID]struct{}
+		// queuedAt is the time at which each queued range was pushed in its
+		// queue.
+		queuedAt map[roachpb.RangeID]time.Time
+		// highPopped is the number of high priority ranges popped in a row
+		// while normal priority ranges were waiting.
+		highPopped int
+		stopped    bool
 	}
 
 	done sync.WaitGroup
@@ -146,11 +198,67 @@ func newRaftScheduler(
 		processor:  processor,
 		numWorkers: numWorkers,
 	}
+	if metrics != nil {
+		s.latency[raftPriorityNormal] = metrics.RaftSchedulerLatencyNormal
+		s.latency[raftPriorityHigh] = metrics.RaftSchedulerLatencyHigh
+	}
 	s.mu.cond = sync.NewCond(&s.mu.Mutex)
 	s.mu.state = make(map[roachpb.RangeID]raftScheduleState)
+	s.mu.highPriority = make(map[roachpb.RangeID]struct{})
+	s.mu.queuedAt = make(map[roachpb.RangeID]time.Time)
 	return s
 }
 
+// SetPriority sets the priority class of the range. It takes effect the next
+// time the range is queued.
+func (s *raftScheduler) SetPriority(id roachpb.RangeID, pri raftPriority) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if pri == raftPriorityHigh {
+		s.mu.highPriority[id] = struct{}{}
+	} else {
+		delete(s.mu.highPriority, id)
+	}
+}
+
+func (s *raftScheduler) priorityLocked(id roachpb.RangeID) raftPriority {
+	if _, ok := s.mu.highPriority[id]; ok {
+		return raftPriorityHigh
+	}
+	return raftPriorityNormal
+}
+
+// pushLocked pushes the range in the queue of its priority class.
+func (s *raftScheduler) pushLocked(id roachpb.RangeID) {
+	s.mu.queues[s.priorityLocked(id)].PushBack(id)
+	s.mu.queuedAt[id] = timeutil.Now()
+}
+
+// popLocked pops the next range to process: a high priority range if there's
+// one, unless raftSchedulerHighPriorityBurst of them have been processed in a
+// row while normal priority ranges were waiting.
+func (s *raftScheduler) popLocked() (roachpb.RangeID, bool) {
+	high, normal := &s.mu.queues[raftPriorityHigh], &s.mu.queues[raftPriorityNormal]
+	pri := raftPriorityNormal
+	if high.Len() > 0 && (normal.Len() == 0 || s.mu.highPopped < raftSchedulerHighPriorityBurst) {
+		pri = raftPriorityHigh
+	}
+	id, ok := s.mu.queues[pri].PopFront()
+	if !ok {
+		return 0, false
+	}
+	if pri == raftPriorityHigh && normal.Len() > 0 {
+		s.mu.highPopped++
+	} else {
+		s.mu.highPopped = 0
+	}
+	if h := s.latency[pri]; h != nil {
+		h.RecordValue(timeutil.Since(s.mu.queuedAt[id]).Nanoseconds())
+	}
+	delete(s.mu.queuedAt, id)
+	return id, true
+}
+
 func (s *raftScheduler) Start(ctx context.Context, stopper *stop.Stopper) {
 	stopper.RunWorker(ctx, func(ctx context.Context) {
 		<-stopper.ShouldStop()
@@ -191,7 +299,7 @@ func (s *raftScheduler) worker(ctx context.Context) {
 				return
 			}
 			var ok bool
-			if id, ok = s.mu.queue.PopFront(); ok {
+			if id, ok = s.popLocked(); ok {
 				break
 			}
 			s.mu.cond.Wait()
@@ -235,7 +343,7 @@ func (s *raftScheduler) worker(ctx context.Context) {
 		} else {
 			// There was a concurrent call to one of the Enqueue* methods. Queue the
 			// range ID for further processing.
-			s.mu.queue.PushBack(id)
+			s.pushLocked(id)
 			s.mu.cond.Signal()
 		}
 	}
@@ -251,7 +359,7 @@ func (s *raftScheduler) enqueue1Locked(addState raftScheduleState, id roachpb.Ra
 	if newState&stateQueued == 0 {
 		newState |= stateQueued
 		queued++
-		s.mu.queue.PushBack(id)
+		s.pushLocked(id)
 	}
 	s.mu.state[id] = newState
 	return queued
@@ -23,6 +23,7 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
@@ -245,3 +246,76 @@ func TestSchedulerBuffering(t *testing.T) {
 		})
 	}
 }
+
+// Verify that the high priority ranges are processed first, but that they
+// can't starve the normal priority ranges.
+func TestSchedulerPriority(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s := newRaftScheduler(log.AmbientContext{Tracer: tracing.NewTracer()}, nil, newTestProcessor(), 1)
+	pop := func() []roachpb.RangeID {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		var ids []roachpb.RangeID
+		for {
+			id, ok := s.popLocked()
+			if !ok {
+				return ids
+			}
+			delete(s.mu.state, id)
+			ids = append(ids, id)
+		}
+	}
+
+	s.SetPriority(10, raftPriorityHigh)
+	s.SetPriority(11, raftPriorityHigh)
+	s.enqueueN(stateRaftTick, 1, 10, 2, 11, 3)
+	if ids, expected := pop(), []roachpb.RangeID{10, 11, 1, 2, 3}; fmt.Sprint(ids) != fmt.Sprint(expected) {
+		t.Fatalf("expected %v, got %v", expected, ids)
+	}
+
+	s.SetPriority(11, raftPriorityNormal)
+	s.enqueueN(stateRaftTick, 11, 10)
+	if ids, expected := pop(), []roachpb.RangeID{10, 11}; fmt.Sprint(ids) != fmt.Sprint(expected) {
+		t.Fatalf("expected %v, got %v", expected, ids)
+	}
+
+	var expected []roachpb.RangeID
+	for i := 0; i < raftSchedulerHighPriorityBurst+1; i++ {
+		id := roachpb.RangeID(100 + i)
+		s.SetPriority(id, raftPriorityHigh)
+		s.enqueue1(stateRaftTick, id)
+		if i < raftSchedulerHighPriorityBurst {
+			expected = append(expected, id)
+		}
+	}
+	s.enqueue1(stateRaftTick, 1)
+	expected = append(expected, 1, roachpb.RangeID(100+raftSchedulerHighPriorityBurst))
+	if ids := pop(); fmt.Sprint(ids) != fmt.Sprint(expected) {
+		t.Fatalf("expected %v, got %v", expected, ids)
+	}
+}
+
+func TestRaftPriorityForDesc(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		start, end roachpb.RKey
+		expected   raftPriority
+	}{
+		{roachpb.RKeyMin, roachpb.RKey(keys.Meta2Prefix), raftPriorityHigh},
+		{roachpb.RKey(keys.Meta2Prefix), roachpb.RKey(keys.SystemPrefix), raftPriorityHigh},
+		{roachpb.RKey(keys.SystemPrefix), roachpb.RKey(keys.TimeseriesPrefix), raftPriorityHigh},
+		{roachpb.RKey(keys.TimeseriesPrefix), roachpb.RKey(keys.TimeseriesPrefix.PrefixEnd()), raftPriorityNormal},
+		{roachpb.RKey(keys.MakeTablePrefix(50)), roachpb.RKeyMax, raftPriorityNormal},
+	}
+	for _, tc := range testCases {
+		desc := &roachpb.RangeDescriptor{RangeID: 1, StartKey: tc.start, EndKey: tc.end}
+		if pri := raftPriorityForDesc(desc); pri != tc.expected {
+			t.Errorf("[%s,%s): expected priority %d, got %d", tc.start, tc.end, tc.expected, pri)
+		}
+	}
+	if pri := raftPriorityForDesc(&roachpb.RangeDescriptor{RangeID: 1}); pri != raftPriorityNormal {
+		t.Errorf("expected an uninitialized range to have a normal priority, got %d", pri)
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package split
+
+import (
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// minSplitSuggestionInterval is the minimum interval between two split
+// suggestions made by a Decider.
+const minSplitSuggestionInterval = time.Minute
+
+// A Decider collects measurements about the request activity on a range and
+// decides when the range should be split based on its load. Requests are
+// counted in one second intervals; once the number of requests per second
+// exceeds the threshold, the Decider starts sampling the requests' spans
+// using a Finder, which eventually suggests a split key.
+//
+// The zero value of a Decider is not usable; it must be set up with Init.
+type Decider struct {
+	intn         func(n int) int // supplied to Init
+	qpsThreshold func() float64  // supplied to Init
+
+	mu struct {
+		syncutil.Mutex
+		lastQPSRollover time.Time // most recent time at which the QPS was computed
+		lastQPS         float64   // requests per second as of lastQPSRollover
+		count           int64     // number of requests since lastQPSRollover
+
+		splitFinder         *Finder   // non-nil while the range is hot
+		lastSplitSuggestion time.Time // last time a split was suggested
+	}
+}
+
+// Init initializes a Decider (which is assumed to be zero). intn must return
+// a random number in [0, n) and be safe for concurrent use. qpsThreshold
+// returns the number of requests per second above which the range is
+// considered for load-based splitting.
+func Init(d *Decider, intn func(n int) int, qpsThreshold func() float64) {
+	d.intn = intn
+	d.qpsThreshold = qpsThreshold
+}
+
+// Record notifies the Decider that n requests with the span returned by the
+// span function were received at now. It returns true when a split key has
+// been found and the caller should consider splitting the range, at most
+// once per minSplitSuggestionInterval. The span function is only invoked if
+// the request is sampled, and may return an empty span if the request's span
+// is unknown.
+func (d *Decider) Record(now time.Time, n int, span func() roachpb.Span) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.recordLocked(now, n, span)
+}
+
+func (d *Decider) recordLocked(now time.Time, n int, span func() roachpb.Span) bool {
+	d.mu.count += int64(n)
+
+	// Compute the requests per second once at least a second has elapsed
+	// since the last computation.
+	if elapsed := now.Sub(d.mu.lastQPSRollover); elapsed >= time.Second {
+		if elapsed > 2*time.Second {
+			// The range was idle for a while; the requests seen since aren't
+			// representative of its load.
+			d.mu.count = 0
+		}
+		d.mu.lastQPS = float64(d.mu.count) / elapsed.Seconds()
+		d.mu.lastQPSRollover = now
+		d.mu.count = 0
+
+		// Start sampling the requests once the range is hot, and stop (and
+		// forget about the samples) as soon as it cools down.
+		if d.mu.lastQPS >= d.qpsThreshold() {
+			if d.mu.splitFinder == nil {
+				d.mu.splitFinder = NewFinder(now)
+			}
+		} else {
+			d.mu.splitFinder = nil
+		}
+	}
+
+	if d.mu.splitFinder != nil && n != 0 {
+		if s := span(); s.Key != nil {
+			d.mu.splitFinder.Record(s, d.intn)
+		}
+		if now.Sub(d.mu.lastSplitSuggestion) > minSplitSuggestionInterval &&
+			d.mu.splitFinder.Ready(now) && d.mu.splitFinder.Key() != nil {
+			d.mu.lastSplitSuggestion = now
+			return true
+		}
+	}
+	return false
+}
+
+// LastQPS returns the most recently computed number of requests per second,
+// or zero if the range hasn't received any requests recently.
+func (d *Decider) LastQPS(now time.Time) float64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.recordLocked(now, 0, nil)
+	return d.mu.lastQPS
+}
+
+// MaybeSplitKey returns a key to split the range at to balance its load, or
+// nil if the range isn't hot or no suitable key has been found (yet).
+func (d *Decider) MaybeSplitKey(now time.Time) roachpb.Key {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.recordLocked(now, 0, nil)
+	if d.mu.splitFinder != nil && d.mu.splitFinder.Ready(now) {
+		return d.mu.splitFinder.Key()
+	}
+	return nil
+}
+
+// Reset discards the state of the Decider. It is called after the range is
+// split, since the samples no longer reflect the range's bounds.
+func (d *Decider) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.mu.lastQPSRollover = time.Time{}
+	d.mu.lastQPS = 0
+	d.mu.count = 0
+	d.mu.splitFinder = nil
+	d.mu.lastSplitSuggestion = time.Time{}
+}
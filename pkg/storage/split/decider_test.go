@@ -0,0 +1,102 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package split
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestDecider(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	rng := rand.New(rand.NewSource(12))
+	var d Decider
+	Init(&d, rng.Intn, func() float64 { return 100 })
+
+	start := time.Unix(1000, 0)
+	keys := make([]roachpb.Key, 100)
+	for i := range keys {
+		keys[i] = roachpb.Key(fmt.Sprintf("k%03d", i))
+	}
+	// recordSecond records qps requests spread evenly over the keys during
+	// the given second.
+	recordSecond := func(sec int, qps int) (suggestions int) {
+		for i := 0; i < qps; i++ {
+			now := start.Add(time.Duration(sec)*time.Second +
+				time.Duration(i)*time.Second/time.Duration(qps))
+			k := keys[i%len(keys)]
+			if d.Record(now, 1, func() roachpb.Span { return roachpb.Span{Key: k} }) {
+				suggestions++
+			}
+		}
+		return suggestions
+	}
+
+	// A cool range isn't considered for splitting.
+	for sec := 0; sec < 30; sec++ {
+		if recordSecond(sec, 50) != 0 {
+			t.Fatal("unexpected split suggestion for a cool range")
+		}
+	}
+	now := start.Add(30 * time.Second)
+	if qps := d.LastQPS(now); qps < 45 || qps > 55 {
+		t.Errorf("expected ~50 qps, got %.2f", qps)
+	}
+	if k := d.MaybeSplitKey(now); k != nil {
+		t.Errorf("expected no split key for a cool range, got %s", k)
+	}
+
+	// Once the range is hot, a split key is suggested after the finder has
+	// sampled requests for long enough, but only once per minute.
+	var suggestions int
+	for sec := 30; sec < 60; sec++ {
+		suggestions += recordSecond(sec, 500)
+	}
+	if suggestions != 1 {
+		t.Errorf("expected one split suggestion, got %d", suggestions)
+	}
+	now = start.Add(60 * time.Second)
+	k := d.MaybeSplitKey(now)
+	if k == nil {
+		t.Fatal("expected a split key for a hot range")
+	}
+	if k.Compare(keys[25]) < 0 || k.Compare(keys[75]) > 0 {
+		t.Errorf("expected a split key near the middle of the load, got %s", k)
+	}
+
+	// The state is forgotten after a reset and when the range cools down.
+	d.Reset()
+	if k := d.MaybeSplitKey(now); k != nil {
+		t.Errorf("expected no split key after reset, got %s", k)
+	}
+	for sec := 60; sec < 80; sec++ {
+		recordSecond(sec, 500)
+	}
+	if k := d.MaybeSplitKey(start.Add(80 * time.Second)); k == nil {
+		t.Fatal("expected a split key for a hot range")
+	}
+	if k := d.MaybeSplitKey(start.Add(90 * time.Second)); k != nil {
+		t.Errorf("expected no split key after the range became idle, got %s", k)
+	}
+	if qps := d.LastQPS(start.Add(90 * time.Second)); qps != 0 {
+		t.Errorf("expected 0 qps for an idle range, got %.2f", qps)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package split
+
+import (
+	"bytes"
+	"math"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+)
+
+const (
+	// RecordDurationThreshold is the minimum duration for which a Finder
+	// samples requests before it is ready to suggest a split key.
+	RecordDurationThreshold = 10 * time.Second
+
+	splitKeySampleSize = 20  // size of the split key sample
+	splitKeyMinCounter = 100 // min aggregate counters before a sample is considered
+	// splitKeyThreshold is the maximum relative difference between the number
+	// of requests to the left and to the right of a sampled key for it to be
+	// used as a split key.
+	splitKeyThreshold = 0.25
+	// splitKeyContainedThreshold is the maximum fraction of requests which may
+	// span a sampled key for it to be used as a split key. Splitting at such a
+	// key would turn the requests spanning it into cross-range requests.
+	splitKeyContainedThreshold = 0.50
+)
+
+type sample struct {
+	key                    roachpb.Key
+	left, right, contained int
+}
+
+// Finder determines a split key which balances the load of a range using
+// reservoir sampling. It samples the start keys of the requests it records
+// and, for each sampled key, counts the subsequent requests which would end
+// up to its left or right (or span it) were the range split there.
+//
+// A Finder is not safe for concurrent use.
+type Finder struct {
+	startTime time.Time
+	samples   [splitKeySampleSize]sample
+	count     int
+}
+
+// NewFinder initializes a Finder which starts sampling at startTime.
+func NewFinder(startTime time.Time) *Finder {
+	return &Finder{startTime: startTime}
+}
+
+// Ready returns whether the Finder has sampled requests for long enough to
+// suggest a split key.
+func (f *Finder) Ready(now time.Time) bool {
+	return now.Sub(f.startTime) > RecordDurationThreshold
+}
+
+// Record records the span of a request. intn is used to choose which sample,
+// if any, the request replaces; it must return a random number in [0, n).
+func (f *Finder) Record(span roachpb.Span, intn func(n int) int) {
+	if f == nil {
+		return
+	}
+
+	var idx int
+	count := f.count
+	f.count++
+	if count < splitKeySampleSize {
+		idx = count
+	} else if idx = intn(count); idx >= splitKeySampleSize {
+		// The request isn't sampled, so count it against the existing samples.
+		for i := range f.samples {
+			s := &f.samples[i]
+			if bytes.Compare(s.key, span.Key) <= 0 {
+				// The request would be isolated to the right of a split at the
+				// sampled key.
+				s.right++
+			} else if len(span.EndKey) > 0 && bytes.Compare(s.key, span.EndKey) < 0 {
+				s.contained++
+			} else {
+				s.left++
+			}
+		}
+		return
+	}
+
+	// Only the start key of the span is sampled. Using a key in the middle of
+	// the span would be more accurate for ranged requests but isn't worth the
+	// complexity.
+	f.samples[idx] = sample{key: span.Key}
+}
+
+// Key returns the sampled key which best balances the requests to either side
+// of it, or nil if no sampled key is a suitable split key.
+func (f *Finder) Key() roachpb.Key {
+	if f == nil {
+		return nil
+	}
+
+	bestIdx := -1
+	bestScore := 2.0
+	for i, s := range f.samples {
+		if s.left+s.right+s.contained < splitKeyMinCounter {
+			continue
+		}
+		balanceScore := math.Abs(float64(s.left-s.right)) / float64(s.left+s.right)
+		containedScore := float64(s.contained) / float64(s.left+s.right+s.contained)
+		if balanceScore >= splitKeyThreshold || containedScore >= splitKeyContainedThreshold {
+			continue
+		}
+		if score := balanceScore + containedScore; score < bestScore {
+			bestIdx = i
+			bestScore = score
+		}
+	}
+	if bestIdx == -1 {
+		return nil
+	}
+	return f.samples[bestIdx].key
+}
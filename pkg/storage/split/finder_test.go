@@ -0,0 +1,95 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package split
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestFinderKey(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	key := func(i int) roachpb.Key {
+		return roachpb.Key(fmt.Sprintf("k%02d", i))
+	}
+	// Once the sample is full, never replace a sampled key.
+	noReplace := func(n int) int { return n }
+
+	testCases := []struct {
+		name     string
+		requests func(i int) roachpb.Span
+		count    int
+		expected roachpb.Key
+	}{
+		{
+			name:     "balanced point requests",
+			requests: func(i int) roachpb.Span { return roachpb.Span{Key: key(i % splitKeySampleSize)} },
+			count:    10 * splitKeySampleSize,
+			expected: key(splitKeySampleSize / 2),
+		},
+		{
+			name:     "too few requests",
+			requests: func(i int) roachpb.Span { return roachpb.Span{Key: key(i % splitKeySampleSize)} },
+			count:    splitKeyMinCounter / 2,
+			expected: nil,
+		},
+		{
+			name:     "single hot key",
+			requests: func(i int) roachpb.Span { return roachpb.Span{Key: key(0)} },
+			count:    10 * splitKeySampleSize,
+			expected: nil,
+		},
+		{
+			name: "spanning requests",
+			requests: func(i int) roachpb.Span {
+				if i < splitKeySampleSize {
+					return roachpb.Span{Key: key(i)}
+				}
+				return roachpb.Span{Key: key(0), EndKey: key(splitKeySampleSize)}
+			},
+			count:    10 * splitKeySampleSize,
+			expected: nil,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := NewFinder(time.Time{})
+			for i := 0; i < tc.count; i++ {
+				f.Record(tc.requests(i), noReplace)
+			}
+			if k := f.Key(); !k.Equal(tc.expected) {
+				t.Errorf("expected split key %s, got %s", tc.expected, k)
+			}
+		})
+	}
+}
+
+func TestFinderReady(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	start := time.Unix(100, 0)
+	f := NewFinder(start)
+	if f.Ready(start.Add(RecordDurationThreshold / 2)) {
+		t.Error("expected finder not to be ready")
+	}
+	if !f.Ready(start.Add(2 * RecordDurationThreshold)) {
+		t.Error("expected finder to be ready")
+	}
+}
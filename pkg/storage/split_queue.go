@@ -45,8 +45,8 @@ const (
 	splitQueueConcurrency = 4
 )
 
-// splitQueue manages a queue of ranges slated to be split due to size
-// or along intersecting zone config boundaries.
+// splitQueue manages a queue of ranges slated to be split due to size,
+// load, or along intersecting zone config boundaries.
 type splitQueue struct {
 	*baseQueue
 	db       *client.DB
@@ -87,7 +87,8 @@ func newSplitQueue(store *Store, db *client.DB, gossip *gossip.Gossip) *splitQue
 
 // shouldQueue determines whether a range should be queued for
 // splitting. This is true if the range is intersected by a zone config
-// prefix or if the range's size in bytes exceeds the limit for the zone.
+// prefix, if the range's size in bytes exceeds the limit for the zone, or if
+// the load-based splitter found a key which balances the range's load.
 func (sq *splitQueue) shouldQueue(
 	ctx context.Context, now hlc.Timestamp, repl *Replica, sysCfg config.SystemConfig,
 ) (shouldQ bool, priority float64) {
@@ -104,6 +105,12 @@ func (sq *splitQueue) shouldQueue(
 		priority += ratio
 		shouldQ = true
 	}
+
+	// Add priority if the range is hot enough to be split based on its load.
+	if repl.loadSplitKey() != nil {
+		priority++
+		shouldQ = true
+	}
 	return
 }
 
@@ -168,6 +175,25 @@ func (sq *splitQueue) processAttempt(
 		)
 		return err
 	}
+
+	// Finally, handle case of splitting due to load.
+	if splitKey := r.loadSplitKey(); splitKey != nil {
+		if _, err := r.adminSplitWithDescriptor(
+			ctx,
+			roachpb.AdminSplitRequest{
+				RequestHeader: roachpb.RequestHeader{
+					Key: splitKey,
+				},
+				SplitKey: splitKey,
+			},
+			desc,
+		); err != nil {
+			return errors.Wrapf(err, "unable to split %s at key %q", r, splitKey)
+		}
+		// The samples no longer reflect the bounds of the range.
+		r.loadBasedSplitter.Reset()
+		return nil
+	}
 	return nil
 }
 
@@ -49,6 +49,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/storage/idalloc"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
 	"github.com/cockroachdb/cockroach/pkg/storage/spanset"
 	"github.com/cockroachdb/cockroach/pkg/storage/stateloader"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
@@ -59,6 +60,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/retry"
 	"github.com/cockroachdb/cockroach/pkg/util/shuffle"
@@ -78,6 +80,10 @@ const (
 	// store's Raft log entry cache.
 	defaultRaftEntryCacheSize = 1 << 24 // 16M
 
+	// defaultEvalMemoryBudget is the default size in bytes of the memory
+	// budget for command evaluation on a store.
+	defaultEvalMemoryBudget = 1 << 29 // 512M
+
 	// replicaRequestQueueSize specifies the maximum number of requests to queue
 	// for a replica.
 	replicaRequestQueueSize = 100
@@ -95,6 +101,7 @@ const (
 var changeTypeInternalToRaft = map[roachpb.ReplicaChangeType]raftpb.ConfChangeType{
 	roachpb.ADD_REPLICA:    raftpb.ConfChangeAddNode,
 	roachpb.REMOVE_REPLICA: raftpb.ConfChangeRemoveNode,
+	roachpb.ADD_LEARNER:    raftpb.ConfChangeAddLearnerNode,
 }
 
 var storeSchedulerConcurrency = envutil.EnvOrDefaultInt(
@@ -368,10 +375,13 @@ type Store struct {
 	tsMaintenanceQueue *timeSeriesMaintenanceQueue // Time series maintenance queue
 	scanner            *replicaScanner             // Replica scanner
 	consistencyQueue   *consistencyQueue           // Replica consistency check queue
+	storeRebalancer    *StoreRebalancer            // Load-based rebalancer
 	metrics            *StoreMetrics
 	intentResolver     *intentResolver
 	raftEntryCache     *raftEntryCache
+	admission          *admissionController
 	limiters           batcheval.Limiters
+	evalMemMonitor     mon.BytesMonitor // Memory used by command evaluation
 
 	// gossipRangeCountdown and leaseRangeCountdown are countdowns of
 	// changes to range and leaseholder counts, after which the store
@@ -413,6 +423,10 @@ type Store struct {
 	// has likely improved).
 	draining atomic.Value
 
+	// diskFull is set to 1 when the fraction of the store's capacity in use
+	// exceeds kv.store.read_only_fraction_used. See updateDiskFull.
+	diskFull int32
+
 	// Locking notes: To avoid deadlocks, the following lock order must be
 	// obeyed: Replica.raftMu < Replica.readOnlyCmdMu < Store.mu < Replica.mu
 	// < Replica.unreachablesMu < Store.coalescedMu < Store.scheduler.mu.
@@ -565,6 +579,10 @@ type StoreConfig struct {
 	// maintenance queue to dispatch individual maintenance tasks.
 	TimeSeriesDataStore TimeSeriesDataStore
 
+	// ProtectedTimestampCache, if set, holds the protected timestamp records
+	// whose data the GC queue and GC requests keep.
+	ProtectedTimestampCache *protectedts.Cache
+
 	// DontRetryPushTxnFailures will propagate a push txn failure immediately
 	// instead of utilizing the txn wait queue to wait for the transaction to
 	// finish or be pushed by a higher priority contender.
@@ -609,6 +627,12 @@ type StoreConfig struct {
 	// shared by all Raft groups managed by the store.
 	RaftEntryCacheSize uint64
 
+	// EvalMemoryBudget is the number of bytes that commands which allocate
+	// memory proportional to the amount of data they read (scans, exports,
+	// snapshots for merges) may have allocated at once on the store. Commands
+	// which would exceed it fail with an EvalMemoryBudgetExceededError.
+	EvalMemoryBudget int64
+
 	// IntentResolverTaskLimit is the maximum number of asynchronous tasks that
 	// may be started by the intent resolver. -1 indicates no asynchronous tasks
 	// are allowed. 0 uses the default value (defaultIntentResolverTaskLimit)
@@ -726,11 +750,16 @@ type StoreTestingKnobs struct {
 	DisableReplicaGCQueue bool
 	// DisableReplicateQueue disables the replication queue.
 	DisableReplicateQueue bool
+	// DisableStoreRebalancer turns off the store rebalancer, which moves leases
+	// and replicas away from stores serving disproportionately high load.
+	DisableStoreRebalancer bool
 	// DisableReplicaRebalancing disables rebalancing of replicas but otherwise
 	// leaves the replicate queue operational.
 	DisableReplicaRebalancing bool
 	// DisableSplitQueue disables the split queue.
 	DisableSplitQueue bool
+	// DisableLoadBasedSplitting disables splitting ranges based on their load.
+	DisableLoadBasedSplitting bool
 	// DisableTimeSeriesMaintenanceQueue disables the time series maintenance
 	// queue.
 	DisableTimeSeriesMaintenanceQueue bool
@@ -810,6 +839,9 @@ func (sc *StoreConfig) SetDefaults() {
 	if sc.RaftEntryCacheSize == 0 {
 		sc.RaftEntryCacheSize = defaultRaftEntryCacheSize
 	}
+	if sc.EvalMemoryBudget == 0 {
+		sc.EvalMemoryBudget = defaultEvalMemoryBudget
+	}
 	if sc.IntentResolverTaskLimit == -1 || sc.TestingKnobs.ForceSyncIntentResolution {
 		sc.IntentResolverTaskLimit = 0
 	} else if sc.IntentResolverTaskLimit == 0 {
@@ -865,6 +897,10 @@ func NewStore(cfg StoreConfig, eng engine.Engine, nodeDesc *roachpb.NodeDescript
 	}
 	s.intentResolver = newIntentResolver(s, cfg.IntentResolverTaskLimit)
 	s.raftEntryCache = newRaftEntryCache(cfg.RaftEntryCacheSize)
+	s.metrics.registry.AddMetricStruct(s.raftEntryCache.metrics)
+	cpu := &cpuSampler{}
+	s.admission = newAdmissionController(&cfg.Settings.SV, s.metrics.RdbReadAmplification.Value, cpu.sample)
+	s.metrics.registry.AddMetricStruct(s.admission.metrics)
 	s.draining.Store(false)
 	s.scheduler = newRaftScheduler(s.cfg.AmbientCtx, s.metrics, s, storeSchedulerConcurrency)
 
@@ -887,6 +923,12 @@ func NewStore(cfg StoreConfig, eng engine.Engine, nodeDesc *roachpb.NodeDescript
 	s.tsCache = tscache.New(cfg.Clock, cfg.TimestampCachePageSize, tsCacheMetrics)
 	s.metrics.registry.AddMetricStruct(tsCacheMetrics)
 
+	if rocksdb, ok := s.engine.(*engine.RocksDB); ok {
+		rocksdb.SetSyncListener(func(d time.Duration) {
+			s.metrics.RdbWALSyncLatency.RecordValue(d.Nanoseconds())
+		})
+	}
+
 	s.compactor = compactor.NewCompactor(
 		s.cfg.Settings,
 		s.engine.(engine.WithSSTables),
@@ -918,6 +960,20 @@ func NewStore(cfg StoreConfig, eng engine.Engine, nodeDesc *roachpb.NodeDescript
 	ExportRequestsLimit.SetOnChange(&cfg.Settings.SV, func() {
 		s.limiters.ConcurrentExports.SetLimit(int(ExportRequestsLimit.Get(&cfg.Settings.SV)))
 	})
+	s.evalMemMonitor = mon.MakeMonitor(
+		"eval",
+		mon.MemoryResource,
+		s.metrics.EvalMemoryCurBytes,
+		nil, /* maxHist */
+		-1,  /* increment: use default block size */
+		math.MaxInt64,
+		cfg.Settings,
+	)
+	s.evalMemMonitor.Start(
+		s.AnnotateCtx(context.Background()), nil, /* pool */
+		mon.MakeStandaloneBudget(cfg.EvalMemoryBudget),
+	)
+	s.limiters.EvalMemory = &s.evalMemMonitor
 
 	if s.cfg.Gossip != nil {
 		// Add range scanner and configure with queues.
@@ -942,6 +998,8 @@ func NewStore(cfg StoreConfig, eng engine.Engine, nodeDesc *roachpb.NodeDescript
 			)
 			s.scanner.AddQueues(s.tsMaintenanceQueue)
 		}
+
+		s.storeRebalancer = NewStoreRebalancer(s.cfg.AmbientCtx, cfg.Settings, s)
 	}
 
 	if cfg.TestingKnobs.DisableGCQueue {
@@ -1331,8 +1389,11 @@ func (s *Store) Start(ctx context.Context, stopper *stop.Stopper) error {
 	// binary version so that we can assume local data never contains legacy range
 	// tombstones. For simplicity, we do it on *every* boot. Should this be found
 	// to impact startup times too much, we can make it only run the first time
-	// this binary version is booted.
-	{
+	// this binary version is booted. The migration is skipped if it has been
+	// run offline with `cockroach debug rewrite-store`.
+	if done, err := IsStoreRewriteDone(ctx, s.engine, legacyTombstonesRewrite); err != nil {
+		return err
+	} else if !done {
 		tBegin := timeutil.Now()
 		if err := migrateLegacyTombstones(ctx, s.engine); err != nil {
 			return errors.Wrapf(err, "migrating legacy tombstones for %v", s.engine)
@@ -1409,6 +1470,10 @@ func (s *Store) Start(ctx context.Context, stopper *stop.Stopper) error {
 	s.cfg.Transport.Listen(s.StoreID(), s)
 	s.processRaft(ctx)
 
+	// Start watching for overload, to queue background work behind the
+	// foreground traffic.
+	s.admission.start(ctx, s.stopper)
+
 	// Gossip is only ever nil while bootstrapping a cluster and
 	// in unittests.
 	if s.cfg.Gossip != nil {
@@ -1446,6 +1511,18 @@ func (s *Store) Start(ctx context.Context, stopper *stop.Stopper) error {
 			}
 		})
 
+		// Start the store rebalancer, which moves load away from this store when
+		// it is serving considerably more than the rest of the cluster.
+		if !s.cfg.TestingKnobs.DisableStoreRebalancer {
+			s.storeRebalancer.Start(s.AnnotateCtx(context.Background()), s.stopper)
+		}
+
+		// Start the generation of the replication reports. It only does any
+		// work while the store holds the lease of the first range.
+		if s.cfg.SQLExecutor != nil && s.cfg.NodeLiveness != nil {
+			s.startReplicationReporter(s.AnnotateCtx(context.Background()))
+		}
+
 		// Run metrics computation up front to populate initial statistics.
 		if err = s.ComputeMetrics(ctx, -1); err != nil {
 			log.Infof(ctx, "%s: failed initial metrics computation: %s", s, err)
@@ -1592,7 +1669,7 @@ func (s *Store) startLeaseRenewer(ctx context.Context) {
 		for {
 			for repl := range repls {
 				annotatedCtx := repl.AnnotateCtx(ctx)
-				_, pErr := repl.redirectOnOrAcquireLease(annotatedCtx)
+				status, pErr := repl.redirectOnOrAcquireLease(annotatedCtx)
 				if pErr != nil {
 					if _, ok := pErr.GetDetail().(*roachpb.NotLeaseHolderError); !ok {
 						log.Warningf(annotatedCtx, "failed to proactively renew lease: %s", pErr)
@@ -1600,6 +1677,11 @@ func (s *Store) startLeaseRenewer(ctx context.Context) {
 					delete(repls, repl)
 					continue
 				}
+				if status.Lease.Type() == roachpb.LeaseEpoch {
+					// The lease was switched to an epoch-based lease, which
+					// doesn't need to be renewed.
+					delete(repls, repl)
+				}
 			}
 
 			if len(repls) > 0 {
@@ -2249,6 +2331,8 @@ func (s *Store) SplitRange(ctx context.Context, origRng, newRng *Replica) error
 	copyDesc := *origDesc
 	copyDesc.EndKey = append([]byte(nil), newDesc.StartKey...)
 	origRng.setDescWithoutProcessUpdate(&copyDesc)
+	rightDesc := *newDesc
+	origRng.recordRangeBoundaryChange(&rightDesc)
 
 	// Clear the LHS txn wait queue, to redirect to the RHS if
 	// appropriate. We do this after setDescWithoutProcessUpdate
@@ -2260,6 +2344,9 @@ func (s *Store) SplitRange(ctx context.Context, origRng, newRng *Replica) error
 	// spans that are now owned by the new range.
 	origRng.leaseholderStats.resetRequestCounts()
 	origRng.writeStats.splitRequestCounts(newRng.writeStats)
+	if origRng.egressStats != nil {
+		origRng.egressStats.splitRequestCounts(newRng.egressStats)
+	}
 
 	if kr := s.mu.replicasByKey.ReplaceOrInsert(origRng); kr != nil {
 		return errors.Errorf("replicasByKey unexpectedly contains %s when inserting replica %s", kr, origRng)
@@ -2334,6 +2421,9 @@ func (s *Store) MergeRange(
 		// logic that depends on them.
 		leftRepl.writeStats.resetRequestCounts()
 	}
+	if leftRepl.egressStats != nil {
+		leftRepl.egressStats.resetRequestCounts()
+	}
 
 	// TODO(benesch): drain the RHS txn wait queue.
 
@@ -2342,7 +2432,11 @@ func (s *Store) MergeRange(
 	// Update the end key of the subsuming range.
 	copy := *leftDesc
 	copy.EndKey = updatedEndKey
-	return leftRepl.setDesc(&copy)
+	if err := leftRepl.setDesc(&copy); err != nil {
+		return err
+	}
+	leftRepl.recordRangeBoundaryChange(nil /* rightDesc */)
+	return nil
 }
 
 // addReplicaInternalLocked adds the replica to the replicas map and the
@@ -2425,13 +2519,15 @@ func (s *Store) removePlaceholderLocked(ctx context.Context, rngID roachpb.Range
 
 // addReplicaToRangeMapLocked adds the replica to the replicas map.
 // addReplicaToRangeMapLocked requires that the store lock is held.
+//
+// The replica isn't ticked until its Raft group is created (see
+// Replica.withRaftGroupLocked), which happens lazily when it receives a
+// request or a Raft message. With many replicas, ticking the dormant ones
+// would make every tick after a restart visit all of them for nothing.
 func (s *Store) addReplicaToRangeMapLocked(repl *Replica) error {
 	if _, loaded := s.mu.replicas.LoadOrStore(int64(repl.RangeID), unsafe.Pointer(repl)); loaded {
 		return errors.Errorf("%s: replica already exists", repl)
 	}
-	s.unquiescedReplicas.Lock()
-	s.unquiescedReplicas.m[repl.RangeID] = struct{}{}
-	s.unquiescedReplicas.Unlock()
 	return nil
 }
 
@@ -2610,12 +2706,20 @@ func (s *Store) Capacity() (roachpb.StoreCapacity, error) {
 	now := s.cfg.Clock.Now()
 	var leaseCount int32
 	var logicalBytes int64
+	var totalQueriesPerSecond float64
 	var totalWritesPerSecond float64
 	bytesPerReplica := make([]float64, 0, capacity.RangeCount)
 	writesPerReplica := make([]float64, 0, capacity.RangeCount)
 	newStoreReplicaVisitor(s).Visit(func(r *Replica) bool {
 		if r.OwnsValidLease(now) {
 			leaseCount++
+			// Only the leaseholder knows about all of the range's traffic, so
+			// only leaseholders contribute to the store's QPS.
+			if r.leaseholderStats != nil {
+				if qps, dur := r.leaseholderStats.avgQPS(); dur >= MinStatsDuration {
+					totalQueriesPerSecond += qps
+				}
+			}
 		}
 		mvccStats := r.GetMVCCStats()
 		logicalBytes += mvccStats.Total()
@@ -2631,9 +2735,13 @@ func (s *Store) Capacity() (roachpb.StoreCapacity, error) {
 	})
 	capacity.LeaseCount = leaseCount
 	capacity.LogicalBytes = logicalBytes
+	capacity.QueriesPerSecond = totalQueriesPerSecond
 	capacity.WritesPerSecond = totalWritesPerSecond
 	capacity.BytesPerReplica = roachpb.PercentilesFromData(bytesPerReplica)
 	capacity.WritesPerReplica = roachpb.PercentilesFromData(writesPerReplica)
+	// The read amplification is refreshed with the other RocksDB metrics in
+	// ComputeMetrics, so it may be a few seconds stale.
+	capacity.ReadAmplification = int32(s.metrics.RdbReadAmplification.Value())
 	s.recordNewWritesPerSecond(totalWritesPerSecond)
 
 	return capacity, nil
@@ -2747,6 +2855,14 @@ func (s *Store) Send(
 		}
 	}
 
+	// Background work waits here while the store is overloaded, before its
+	// timestamp is set.
+	class := classifyBatch(&ba)
+	if err := s.admission.admit(ctx, s.stopper, class); err != nil {
+		return nil, roachpb.NewError(err)
+	}
+	defer s.admission.done(class)
+
 	if err := ba.SetActiveTimestamp(s.Clock().Now); err != nil {
 		return nil, roachpb.NewError(err)
 	}
@@ -2899,6 +3015,7 @@ func (s *Store) Send(
 		}
 		br, pErr = repl.Send(ctx, ba)
 		if pErr == nil {
+			repl.maybeAttachRangeDescriptors(br)
 			return br, nil
 		}
 
@@ -2987,6 +3104,27 @@ func (s *Store) Send(
 	}
 }
 
+// RangeFeed registers a rangefeed over the specified span. It sends updates to
+// the provided stream and returns with an optional error when the rangefeed is
+// complete.
+func (s *Store) RangeFeed(
+	args *roachpb.RangeFeedRequest, stream roachpb.Internal_RangeFeedServer,
+) *roachpb.Error {
+	if err := verifyKeys(args.Span.Key, args.Span.EndKey, true); err != nil {
+		return roachpb.NewError(err)
+	}
+
+	// Get range and add command to the range for execution.
+	repl, err := s.GetReplica(args.RangeID)
+	if err != nil {
+		return roachpb.NewError(err)
+	}
+	if !repl.IsInitialized() {
+		return roachpb.NewError(roachpb.NewRangeNotFoundError(args.RangeID))
+	}
+	return repl.RangeFeed(args, stream)
+}
+
 // maybeWaitForPushee potentially diverts the incoming request to
 // the txnwait.Queue, where it will wait for updates to the target
 // transaction.
@@ -3104,6 +3242,9 @@ func (s *Store) uncoalesceBeats(
 func (s *Store) HandleRaftRequest(
 	ctx context.Context, req *RaftMessageRequest, respStream RaftMessageResponseStream,
 ) *roachpb.Error {
+	// Raft traffic is never delayed, but it's accounted for like the other
+	// work the store receives.
+	s.admission.metrics.AdmittedRaft.Inc(1)
 	if len(req.Heartbeats)+len(req.HeartbeatResps) > 0 {
 		if req.RangeID != 0 {
 			log.Fatalf(ctx, "coalesced heartbeats must have rangeID == 0")
@@ -3129,6 +3270,15 @@ func (s *Store) HandleRaftUncoalescedRequest(
 	// count them.
 	s.metrics.raftRcvdMessages[req.Message.Type].Inc(1)
 
+	if req.EntryCompression != CompressionType_NONE {
+		compressed, uncompressed, err := decompressRaftEntries(req)
+		if err != nil {
+			return roachpb.NewError(err)
+		}
+		s.metrics.RaftRcvdEntryBytesCompressed.Inc(compressed)
+		s.metrics.RaftRcvdEntryBytesUncompressed.Inc(uncompressed)
+	}
+
 	if respStream == nil {
 		return s.processRaftRequestAndReady(ctx, req)
 	}
@@ -3951,6 +4101,7 @@ func (s *Store) updateCapacityGauges() error {
 	s.metrics.Capacity.Update(desc.Capacity.Capacity)
 	s.metrics.Available.Update(desc.Capacity.Available)
 	s.metrics.Used.Update(desc.Capacity.Used)
+	s.updateDiskFull(s.AnnotateCtx(context.Background()), desc.Capacity)
 
 	return nil
 }
@@ -3974,7 +4125,10 @@ func (s *Store) updateReplicationGauges(ctx context.Context) error {
 		leaseEpochCount               int64
 		raftLeaderNotLeaseHolderCount int64
 		quiescentCount                int64
+		dormantCount                  int64
+		averageQueriesPerSecond       float64
 		averageWritesPerSecond        float64
+		gcBytesAwaiting               int64
 
 		rangeCount                int64
 		unavailableRangeCount     int64
@@ -4005,10 +4159,19 @@ func (s *Store) updateReplicationGauges(ctx context.Context) error {
 			case roachpb.LeaseEpoch:
 				leaseEpochCount++
 			}
+			if rep.leaseholderStats != nil {
+				if qps, dur := rep.leaseholderStats.avgQPS(); dur >= MinStatsDuration {
+					averageQueriesPerSecond += qps
+				}
+			}
+			gcBytesAwaiting += rep.GetMVCCStats().GCBytes()
 		}
 		if metrics.Quiescent {
 			quiescentCount++
 		}
+		if metrics.Dormant {
+			dormantCount++
+		}
 		if metrics.RangeCounter {
 			rangeCount++
 			if metrics.Unavailable {
@@ -4031,7 +4194,10 @@ func (s *Store) updateReplicationGauges(ctx context.Context) error {
 	s.metrics.LeaseExpirationCount.Update(leaseExpirationCount)
 	s.metrics.LeaseEpochCount.Update(leaseEpochCount)
 	s.metrics.QuiescentCount.Update(quiescentCount)
+	s.metrics.DormantCount.Update(dormantCount)
+	s.metrics.AverageQueriesPerSecond.Update(averageQueriesPerSecond)
 	s.metrics.AverageWritesPerSecond.Update(averageWritesPerSecond)
+	s.metrics.GCBytesAwaiting.Update(gcBytesAwaiting)
 	s.recordNewWritesPerSecond(averageWritesPerSecond)
 
 	s.metrics.RangeCount.Update(rangeCount)
@@ -4124,6 +4290,7 @@ func (s *Store) ComputeMetrics(ctx context.Context, tick int) error {
 	if err := s.updateCommandQueueGauges(); err != nil {
 		return err
 	}
+	s.raftLogQueue.updateStoreBudget(ctx)
 
 	// Get the latest RocksDB stats.
 	stats, err := s.engine.GetStats()
@@ -328,7 +328,7 @@ func (sp *StorePool) updateLocalStoreAfterRebalance(
 		return
 	}
 	switch changeType {
-	case roachpb.ADD_REPLICA:
+	case roachpb.ADD_REPLICA, roachpb.ADD_LEARNER:
 		detail.desc.Capacity.LogicalBytes += rangeInfo.LogicalBytes
 		detail.desc.Capacity.WritesPerSecond += rangeInfo.WritesPerSecond
 	case roachpb.REMOVE_REPLICA:
@@ -346,6 +346,39 @@ func (sp *StorePool) updateLocalStoreAfterRebalance(
 	sp.detailsMu.storeDetails[storeID] = &detail
 }
 
+// updateLocalStoresAfterLeaseTransfer is used to update the local copies of
+// the source and target stores immediately after a lease transfer, so that
+// further rebalancing decisions made before the stores' descriptors are next
+// gossiped account for the load which has moved.
+func (sp *StorePool) updateLocalStoresAfterLeaseTransfer(
+	from roachpb.StoreID, to roachpb.StoreID, rangeQPS float64,
+) {
+	sp.detailsMu.Lock()
+	defer sp.detailsMu.Unlock()
+
+	fromDetail := *sp.getStoreDetailLocked(from)
+	if fromDetail.desc != nil {
+		fromDesc := *fromDetail.desc
+		fromDesc.Capacity.LeaseCount--
+		if fromDesc.Capacity.QueriesPerSecond < rangeQPS {
+			fromDesc.Capacity.QueriesPerSecond = 0
+		} else {
+			fromDesc.Capacity.QueriesPerSecond -= rangeQPS
+		}
+		fromDetail.desc = &fromDesc
+		sp.detailsMu.storeDetails[from] = &fromDetail
+	}
+
+	toDetail := *sp.getStoreDetailLocked(to)
+	if toDetail.desc != nil {
+		toDesc := *toDetail.desc
+		toDesc.Capacity.LeaseCount++
+		toDesc.Capacity.QueriesPerSecond += rangeQPS
+		toDetail.desc = &toDesc
+		sp.detailsMu.storeDetails[to] = &toDetail
+	}
+}
+
 // newStoreDetail makes a new storeDetail struct. It sets index to be -1 to
 // ensure that it will be processed by a queue immediately.
 func newStoreDetail() *storeDetail {
@@ -485,6 +518,10 @@ type StoreList struct {
 	// candidateWritesPerSecond tracks writes-per-second stats for stores that are
 	// eligible to be rebalance targets.
 	candidateWritesPerSecond stat
+
+	// candidateQueriesPerSecond tracks queries-per-second stats for stores that
+	// are eligible to be rebalance targets.
+	candidateQueriesPerSecond stat
 }
 
 // Generates a new store list based on the passed in descriptors. It will
@@ -498,6 +535,7 @@ func makeStoreList(descriptors []roachpb.StoreDescriptor) StoreList {
 		sl.candidateLeases.update(float64(desc.Capacity.LeaseCount))
 		sl.candidateLogicalBytes.update(float64(desc.Capacity.LogicalBytes))
 		sl.candidateWritesPerSecond.update(desc.Capacity.WritesPerSecond)
+		sl.candidateQueriesPerSecond.update(desc.Capacity.QueriesPerSecond)
 	}
 	return sl
 }
@@ -505,20 +543,24 @@ func makeStoreList(descriptors []roachpb.StoreDescriptor) StoreList {
 func (sl StoreList) String() string {
 	var buf bytes.Buffer
 	fmt.Fprintf(&buf,
-		"  candidate: avg-ranges=%v avg-leases=%v avg-disk-usage=%v avg-writes-per-second=%v",
+		"  candidate: avg-ranges=%v avg-leases=%v avg-disk-usage=%v avg-writes-per-second=%v "+
+			"avg-queries-per-second=%v",
 		sl.candidateRanges.mean,
 		sl.candidateLeases.mean,
 		humanizeutil.IBytes(int64(sl.candidateLogicalBytes.mean)),
-		sl.candidateWritesPerSecond.mean)
+		sl.candidateWritesPerSecond.mean,
+		sl.candidateQueriesPerSecond.mean)
 	if len(sl.stores) > 0 {
 		fmt.Fprintf(&buf, "\n")
 	} else {
 		fmt.Fprintf(&buf, " <no candidates>")
 	}
 	for _, desc := range sl.stores {
-		fmt.Fprintf(&buf, "  %d: ranges=%d leases=%d disk-usage=%s writes-per-second=%.2f\n",
+		fmt.Fprintf(&buf,
+			"  %d: ranges=%d leases=%d disk-usage=%s writes-per-second=%.2f queries-per-second=%.2f\n",
 			desc.StoreID, desc.Capacity.RangeCount,
-			desc.Capacity.LeaseCount, humanizeutil.IBytes(desc.Capacity.LogicalBytes), desc.Capacity.WritesPerSecond)
+			desc.Capacity.LeaseCount, humanizeutil.IBytes(desc.Capacity.LogicalBytes),
+			desc.Capacity.WritesPerSecond, desc.Capacity.QueriesPerSecond)
 	}
 	return buf.String()
 }
@@ -0,0 +1,378 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+)
+
+// storeRebalancerTimerDuration is how frequently the store rebalancer checks
+// whether its store is overloaded relative to the rest of the cluster.
+const storeRebalancerTimerDuration = time.Minute
+
+// LBRebalancingMode controls how a store rebalances load it is serving.
+type LBRebalancingMode int64
+
+const (
+	// LBRebalancingOff means that we do not do store-level rebalancing
+	// based on load statistics.
+	LBRebalancingOff LBRebalancingMode = iota
+	// LBRebalancingLeasesOnly means that we rebalance leases based on
+	// store-level QPS imbalances.
+	LBRebalancingLeasesOnly
+	// LBRebalancingLeasesAndReplicas means that we rebalance both leases and
+	// replicas based on store-level QPS imbalances.
+	LBRebalancingLeasesAndReplicas
+)
+
+// LoadBasedRebalancingMode controls whether stores move leases and replicas
+// away when they serve considerably more QPS than the cluster average.
+var LoadBasedRebalancingMode = settings.RegisterEnumSetting(
+	"kv.allocator.load_based_rebalancing",
+	"whether to rebalance based on the distribution of QPS across stores",
+	"leases",
+	map[int64]string{
+		int64(LBRebalancingOff):               "off",
+		int64(LBRebalancingLeasesOnly):        "leases",
+		int64(LBRebalancingLeasesAndReplicas): "leases and replicas",
+	},
+)
+
+// qpsRebalanceThreshold is the fraction above the mean store QPS at which a
+// store is considered overloaded. Stores shed load until they are back under
+// this threshold.
+var qpsRebalanceThreshold = settings.RegisterNonNegativeFloatSetting(
+	"kv.allocator.qps_rebalance_threshold",
+	"minimum fraction above the mean a store's QPS can be before it is considered overfull",
+	0.25,
+)
+
+// loadBasedRebalancingDryRun causes the store rebalancer to log the lease
+// transfers and replica rebalances it would have made without making them.
+var loadBasedRebalancingDryRun = settings.RegisterBoolSetting(
+	"kv.allocator.load_based_rebalancing.dry_run",
+	"if set, load-based rebalancing decisions are logged but not executed",
+	false,
+)
+
+// StoreRebalancer is responsible for examining how the associated store's load
+// compares to the load on other stores in the cluster and transferring leases
+// or replicas away if the local store is overloaded.
+//
+// This isn't implemented as a Queue because the Queues all operate on one
+// replica at a time, making a local decision about that replica. Queues don't
+// really know how the replica they're looking at compares to other replicas on
+// the store. Our goal is balancing stores, though, so it's preferable to make
+// decisions about each store and then carefully pick replicas to move that
+// will best accomplish the store-level goals.
+type StoreRebalancer struct {
+	log.AmbientContext
+	st    *cluster.Settings
+	store *Store
+}
+
+// NewStoreRebalancer creates a StoreRebalancer to work in tandem with the
+// provided store.
+func NewStoreRebalancer(
+	ambientCtx log.AmbientContext, st *cluster.Settings, store *Store,
+) *StoreRebalancer {
+	ambientCtx.AddLogTag("store-rebalancer", nil)
+	return &StoreRebalancer{
+		AmbientContext: ambientCtx,
+		st:             st,
+		store:          store,
+	}
+}
+
+// Start runs an infinite loop in a goroutine which regularly checks whether
+// the store is overloaded along any important dimension (currently only QPS)
+// and, if so, attempts to correct that by moving leases or replicas elsewhere.
+func (sr *StoreRebalancer) Start(ctx context.Context, stopper *stop.Stopper) {
+	ctx = sr.AnnotateCtx(ctx)
+
+	stopper.RunWorker(ctx, func(ctx context.Context) {
+		ticker := time.NewTicker(storeRebalancerTimerDuration)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopper.ShouldQuiesce():
+				return
+			case <-ticker.C:
+			}
+
+			mode := LBRebalancingMode(LoadBasedRebalancingMode.Get(&sr.st.SV))
+			if mode == LBRebalancingOff {
+				continue
+			}
+			if !sr.st.Version.IsActive(cluster.VersionLoadBasedRebalancing) {
+				continue
+			}
+
+			storeList, _, _ := sr.store.cfg.StorePool.getStoreList(roachpb.RangeID(0), storeFilterNone)
+			sr.rebalanceStore(ctx, mode, storeList)
+		}
+	})
+}
+
+func (sr *StoreRebalancer) rebalanceStore(
+	ctx context.Context, mode LBRebalancingMode, storeList StoreList,
+) {
+	qpsThresholdFraction := qpsRebalanceThreshold.Get(&sr.st.SV)
+	dryRun := loadBasedRebalancingDryRun.Get(&sr.st.SV)
+
+	// First check if we should transfer leases away to better balance QPS.
+	qpsMaxThreshold := overfullQPSThreshold(storeList.candidateQueriesPerSecond.mean, qpsThresholdFraction)
+
+	localDesc, ok := sr.store.cfg.StorePool.getStoreDescriptor(sr.store.StoreID())
+	if !ok {
+		log.Warningf(ctx, "StorePool missing descriptor for local store")
+		return
+	}
+	if localDesc.Capacity.QueriesPerSecond <= qpsMaxThreshold {
+		log.VEventf(ctx, 1, "local QPS %.2f is below max threshold %.2f (mean=%.2f); no rebalancing needed",
+			localDesc.Capacity.QueriesPerSecond, qpsMaxThreshold, storeList.candidateQueriesPerSecond.mean)
+		return
+	}
+
+	sysCfg, cfgOk := sr.store.cfg.Gossip.GetSystemConfig()
+	if !cfgOk {
+		log.VEventf(ctx, 1, "no system config available, unable to choose rebalance targets")
+		return
+	}
+
+	storeMap := storeListToMap(storeList)
+	localQPS := localDesc.Capacity.QueriesPerSecond
+	log.Infof(ctx, "considering load-based rebalancing to get local QPS %.2f below max threshold %.2f (mean=%.2f)",
+		localQPS, qpsMaxThreshold, storeList.candidateQueriesPerSecond.mean)
+
+	now := sr.store.Clock().Now()
+	for _, rq := range sr.hottestReplicas(now) {
+		if localQPS <= qpsMaxThreshold {
+			break
+		}
+		desc := rq.repl.Desc()
+		zone, err := sysCfg.GetZoneConfigForKey(desc.StartKey)
+		if err != nil {
+			log.Error(ctx, err)
+			continue
+		}
+
+		candidates := desc.Replicas
+		if preferred := sr.store.allocator.preferredLeaseholders(zone, candidates); len(preferred) > 0 {
+			candidates = preferred
+		}
+		if target, ok := chooseLeaseTarget(
+			candidates, storeMap, sr.store.StoreID(), rq.qps, qpsMaxThreshold,
+		); ok {
+			if dryRun {
+				log.Infof(ctx, "dry run: would transfer lease for r%d (qps=%.2f) to s%d",
+					desc.RangeID, rq.qps, target.StoreID)
+			} else {
+				log.VEventf(ctx, 1, "transferring lease for r%d (qps=%.2f) to s%d",
+					desc.RangeID, rq.qps, target.StoreID)
+				if err := rq.repl.AdminTransferLease(ctx, target.StoreID); err != nil {
+					log.Errorf(ctx, "unable to transfer lease to s%d: %s", target.StoreID, err)
+					continue
+				}
+				sr.store.metrics.RebalancingLeaseTransfers.Inc(1)
+				sr.store.cfg.StorePool.updateLocalStoresAfterLeaseTransfer(
+					sr.store.StoreID(), target.StoreID, rq.qps)
+			}
+			localQPS -= rq.qps
+			updateStoreMapQPS(storeMap, target.StoreID, rq.qps)
+			continue
+		}
+
+		if mode != LBRebalancingLeasesAndReplicas {
+			continue
+		}
+
+		// None of the range's existing replicas can take its lease without
+		// becoming overloaded themselves, so move the local replica (and with
+		// it the lease) to a store which can.
+		target, ok := chooseReplicaTarget(
+			*desc, storeMap, zone.Constraints, rq.qps, qpsMaxThreshold)
+		if !ok {
+			log.VEventf(ctx, 3, "no suitable rebalance target for r%d (qps=%.2f)", desc.RangeID, rq.qps)
+			continue
+		}
+		targets := []roachpb.ReplicationTarget{target}
+		for _, r := range desc.Replicas {
+			if r.StoreID != sr.store.StoreID() {
+				targets = append(targets, roachpb.ReplicationTarget{NodeID: r.NodeID, StoreID: r.StoreID})
+			}
+		}
+		if dryRun {
+			log.Infof(ctx, "dry run: would relocate r%d (qps=%.2f) from s%d to s%d",
+				desc.RangeID, rq.qps, sr.store.StoreID(), target.StoreID)
+		} else {
+			log.VEventf(ctx, 1, "relocating r%d (qps=%.2f) from s%d to s%d",
+				desc.RangeID, rq.qps, sr.store.StoreID(), target.StoreID)
+			if err := RelocateRange(ctx, sr.store.DB(), *desc, targets); err != nil {
+				log.Errorf(ctx, "unable to relocate r%d to %v: %s", desc.RangeID, targets, err)
+				continue
+			}
+			sr.store.metrics.RebalancingRangeRebalances.Inc(1)
+		}
+		localQPS -= rq.qps
+		updateStoreMapQPS(storeMap, target.StoreID, rq.qps)
+	}
+
+	if localQPS > qpsMaxThreshold {
+		log.Infof(ctx, "ran out of ranges to rebalance; local QPS %.2f is still above max threshold %.2f",
+			localQPS, qpsMaxThreshold)
+	}
+}
+
+// replicaWithQPS pairs a replica with the QPS it is serving as leaseholder.
+type replicaWithQPS struct {
+	repl *Replica
+	qps  float64
+}
+
+// hottestReplicas returns the replicas whose leases the store holds, ordered
+// by decreasing QPS. Replicas without sufficient stats are omitted.
+func (sr *StoreRebalancer) hottestReplicas(now hlc.Timestamp) []replicaWithQPS {
+	var replicas []replicaWithQPS
+	newStoreReplicaVisitor(sr.store).Visit(func(r *Replica) bool {
+		if !r.OwnsValidLease(now) || r.leaseholderStats == nil {
+			return true
+		}
+		if qps, dur := r.leaseholderStats.avgQPS(); dur >= MinStatsDuration && qps > 0 {
+			replicas = append(replicas, replicaWithQPS{repl: r, qps: qps})
+		}
+		return true
+	})
+	sort.Slice(replicas, func(i, j int) bool {
+		return replicas[i].qps > replicas[j].qps
+	})
+	return replicas
+}
+
+// overfullQPSThreshold returns the QPS above which a store is considered
+// overloaded.
+func overfullQPSThreshold(mean float64, thresholdFraction float64) float64 {
+	return mean * (1 + thresholdFraction)
+}
+
+func storeListToMap(sl StoreList) map[roachpb.StoreID]*roachpb.StoreDescriptor {
+	storeMap := make(map[roachpb.StoreID]*roachpb.StoreDescriptor, len(sl.stores))
+	for i := range sl.stores {
+		storeMap[sl.stores[i].StoreID] = &sl.stores[i]
+	}
+	return storeMap
+}
+
+func updateStoreMapQPS(
+	storeMap map[roachpb.StoreID]*roachpb.StoreDescriptor, storeID roachpb.StoreID, qps float64,
+) {
+	if desc, ok := storeMap[storeID]; ok {
+		desc.Capacity.QueriesPerSecond += qps
+	}
+}
+
+// chooseLeaseTarget picks the existing replica of a range with the given QPS
+// whose store has the lowest QPS, provided that receiving the lease would not
+// push that store's QPS above maxQPS. Moving a range which would overload its
+// new leaseholder only moves the problem elsewhere.
+func chooseLeaseTarget(
+	candidates []roachpb.ReplicaDescriptor,
+	storeMap map[roachpb.StoreID]*roachpb.StoreDescriptor,
+	localStoreID roachpb.StoreID,
+	rangeQPS float64,
+	maxQPS float64,
+) (roachpb.ReplicaDescriptor, bool) {
+	var target roachpb.ReplicaDescriptor
+	var targetQPS float64
+	found := false
+	for _, candidate := range candidates {
+		if candidate.StoreID == localStoreID {
+			continue
+		}
+		storeDesc, ok := storeMap[candidate.StoreID]
+		if !ok {
+			// The store isn't live or we don't know about it yet.
+			continue
+		}
+		qps := storeDesc.Capacity.QueriesPerSecond
+		if qps+rangeQPS > maxQPS {
+			continue
+		}
+		if !found || qps < targetQPS {
+			target, targetQPS, found = candidate, qps, true
+		}
+	}
+	return target, found
+}
+
+// chooseReplicaTarget picks the store with the lowest QPS which could take
+// over the local replica (and lease) of the given range without its QPS
+// exceeding maxQPS. The store must satisfy the range's constraints and must
+// not be on a node which already holds one of the range's replicas.
+func chooseReplicaTarget(
+	desc roachpb.RangeDescriptor,
+	storeMap map[roachpb.StoreID]*roachpb.StoreDescriptor,
+	constraints []config.Constraints,
+	rangeQPS float64,
+	maxQPS float64,
+) (roachpb.ReplicationTarget, bool) {
+	// Iterate in a deterministic order so that ties are broken consistently.
+	storeIDs := make(roachpb.StoreIDSlice, 0, len(storeMap))
+	for storeID := range storeMap {
+		storeIDs = append(storeIDs, storeID)
+	}
+	sort.Sort(storeIDs)
+
+	var target roachpb.ReplicationTarget
+	var targetQPS float64
+	found := false
+	for _, storeID := range storeIDs {
+		storeDesc := storeMap[storeID]
+		if nodeHasReplica(storeDesc.Node.NodeID, desc.Replicas) {
+			continue
+		}
+		if !maxCapacityCheck(*storeDesc) || !constraintsCheck(*storeDesc, constraints) {
+			continue
+		}
+		qps := storeDesc.Capacity.QueriesPerSecond
+		if qps+rangeQPS > maxQPS {
+			continue
+		}
+		if !found || qps < targetQPS {
+			target = roachpb.ReplicationTarget{NodeID: storeDesc.Node.NodeID, StoreID: storeID}
+			targetQPS, found = qps, true
+		}
+	}
+	return target, found
+}
+
+func nodeHasReplica(nodeID roachpb.NodeID, replicas []roachpb.ReplicaDescriptor) bool {
+	for _, r := range replicas {
+		if r.NodeID == nodeID {
+			return true
+		}
+	}
+	return false
+}
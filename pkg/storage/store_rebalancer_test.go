@@ -0,0 +1,150 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// makeQPSStoreList returns a store list containing one store per element of
+// qps, with store (and node) IDs starting at 1. Stores with odd IDs have the
+// attribute "odd".
+func makeQPSStoreList(qps ...float64) StoreList {
+	var descs []roachpb.StoreDescriptor
+	for i, q := range qps {
+		id := i + 1
+		desc := roachpb.StoreDescriptor{
+			StoreID:  roachpb.StoreID(id),
+			Node:     roachpb.NodeDescriptor{NodeID: roachpb.NodeID(id)},
+			Capacity: roachpb.StoreCapacity{QueriesPerSecond: q},
+		}
+		if id%2 == 1 {
+			desc.Attrs.Attrs = []string{"odd"}
+		}
+		descs = append(descs, desc)
+	}
+	return makeStoreList(descs)
+}
+
+func makeReplicas(storeIDs ...roachpb.StoreID) []roachpb.ReplicaDescriptor {
+	var replicas []roachpb.ReplicaDescriptor
+	for i, id := range storeIDs {
+		replicas = append(replicas, roachpb.ReplicaDescriptor{
+			NodeID:    roachpb.NodeID(id),
+			StoreID:   id,
+			ReplicaID: roachpb.ReplicaID(i + 1),
+		})
+	}
+	return replicas
+}
+
+func TestStoreListQueriesPerSecond(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	sl := makeQPSStoreList(100, 200, 600)
+	if mean := sl.candidateQueriesPerSecond.mean; mean != 300 {
+		t.Errorf("expected mean QPS 300, got %.2f", mean)
+	}
+	if exp, threshold := 375.0, overfullQPSThreshold(300, 0.25); threshold != exp {
+		t.Errorf("expected threshold %.2f, got %.2f", exp, threshold)
+	}
+}
+
+func TestChooseLeaseTarget(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	// The local store is s1; the mean QPS is 400 and, with a threshold of
+	// 0.25, the max QPS is 500.
+	storeMap := storeListToMap(makeQPSStoreList(1000, 300, 100, 450, 150))
+	const maxQPS = 500
+
+	testCases := []struct {
+		replicas []roachpb.ReplicaDescriptor
+		rangeQPS float64
+		expected roachpb.StoreID
+	}{
+		// The coolest store with a replica receives the lease.
+		{makeReplicas(1, 2, 3), 100, 3},
+		{makeReplicas(1, 2, 4), 100, 2},
+		{makeReplicas(1, 4, 5), 100, 5},
+		// Every other replica would become overloaded.
+		{makeReplicas(1, 2, 4), 300, 0},
+		{makeReplicas(1, 2, 3), 450, 0},
+		// Unknown stores aren't considered.
+		{makeReplicas(1, 6, 7), 10, 0},
+		// The local store is never chosen.
+		{makeReplicas(1), 10, 0},
+	}
+	for i, tc := range testCases {
+		target, ok := chooseLeaseTarget(tc.replicas, storeMap, 1, tc.rangeQPS, maxQPS)
+		if tc.expected == 0 {
+			if ok {
+				t.Errorf("%d: expected no target, got s%d", i, target.StoreID)
+			}
+			continue
+		}
+		if !ok {
+			t.Errorf("%d: expected target s%d, got none", i, tc.expected)
+		} else if target.StoreID != tc.expected {
+			t.Errorf("%d: expected target s%d, got s%d", i, tc.expected, target.StoreID)
+		}
+	}
+}
+
+func TestChooseReplicaTarget(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	storeMap := storeListToMap(makeQPSStoreList(1000, 300, 100, 450, 150))
+	const maxQPS = 500
+	oddOnly := []config.Constraints{{
+		Constraints: []config.Constraint{{Value: "odd", Type: config.Constraint_REQUIRED}},
+	}}
+
+	testCases := []struct {
+		replicas    []roachpb.ReplicaDescriptor
+		constraints []config.Constraints
+		rangeQPS    float64
+		expected    roachpb.StoreID
+	}{
+		// The coolest store without a replica is chosen.
+		{makeReplicas(1, 2, 4), nil, 100, 3},
+		{makeReplicas(1, 2, 3), nil, 100, 5},
+		{makeReplicas(1, 3, 5), nil, 100, 2},
+		// Every store without a replica would become overloaded.
+		{makeReplicas(1, 2, 3), nil, 400, 0},
+		// The constraints rule out the even stores.
+		{makeReplicas(1, 3, 5), oddOnly, 100, 0},
+		{makeReplicas(1, 2, 4), oddOnly, 100, 3},
+	}
+	for i, tc := range testCases {
+		desc := roachpb.RangeDescriptor{RangeID: 1, Replicas: tc.replicas}
+		target, ok := chooseReplicaTarget(desc, storeMap, tc.constraints, tc.rangeQPS, maxQPS)
+		if tc.expected == 0 {
+			if ok {
+				t.Errorf("%d: expected no target, got s%d", i, target.StoreID)
+			}
+			continue
+		}
+		if !ok {
+			t.Errorf("%d: expected target s%d, got none", i, tc.expected)
+		} else if target.StoreID != tc.expected || target.NodeID != roachpb.NodeID(tc.expected) {
+			t.Errorf("%d: expected target s%d, got %+v", i, tc.expected, target)
+		}
+	}
+}
@@ -19,6 +19,8 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"os"
+	"path/filepath"
 
 	"github.com/coreos/etcd/raft/raftpb"
 	"github.com/pkg/errors"
@@ -28,6 +30,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/rditer"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -104,6 +107,20 @@ type kvBatchSnapshotStrategy struct {
 	batchSize int64
 	limiter   *rate.Limiter
 	newBatch  func() engine.Batch
+
+	// Fields used when receiving snapshots.
+	metrics *StoreMetrics
+	// ingestEngine, if set, is the engine into which the user data of the
+	// snapshot is to be ingested. The data is then written into an SST file
+	// while the snapshot is received, instead of being part of its batches.
+	ingestEngine   engine.Engine
+	ingestSettings *cluster.Settings
+	ingestLimiter  *rate.Limiter
+
+	// The number of bytes of snapshot data before and after compression, which
+	// are only tracked when the snapshot is compressed.
+	bytesUncompressed int64
+	bytesCompressed   int64
 }
 
 // Send implements the snapshotStrategy interface.
@@ -114,6 +131,14 @@ func (kvSS *kvBatchSnapshotStrategy) Receive(
 
 	var batches [][]byte
 	var logEntries [][]byte
+	var sstWriter *snapshotSSTWriter
+	if kvSS.ingestEngine != nil {
+		var err error
+		if sstWriter, err = makeSnapshotSSTWriter(header.State.Desc); err != nil {
+			return IncomingSnapshot{}, sendSnapshotError(stream, err)
+		}
+		defer sstWriter.close()
+	}
 	for {
 		req, err := stream.Recv()
 		if err != nil {
@@ -125,10 +150,23 @@ func (kvSS *kvBatchSnapshotStrategy) Receive(
 		}
 
 		if req.KVBatch != nil {
-			batches = append(batches, req.KVBatch)
+			batch, err := kvSS.decompress(header.Compression, req.KVBatch)
+			if err != nil {
+				return IncomingSnapshot{}, sendSnapshotError(stream, err)
+			}
+			if sstWriter != nil {
+				if batch, err = sstWriter.split(batch); err != nil {
+					return IncomingSnapshot{}, sendSnapshotError(stream, err)
+				}
+			}
+			batches = append(batches, batch)
 		}
-		if req.LogEntries != nil {
-			logEntries = append(logEntries, req.LogEntries...)
+		for _, ent := range req.LogEntries {
+			ent, err := kvSS.decompress(header.Compression, ent)
+			if err != nil {
+				return IncomingSnapshot{}, sendSnapshotError(stream, err)
+			}
+			logEntries = append(logEntries, ent)
 		}
 		if req.Final {
 			snapUUID, err := uuid.FromBytes(header.RaftMessageRequest.Message.Snapshot.Data)
@@ -148,6 +186,21 @@ func (kvSS *kvBatchSnapshotStrategy) Receive(
 				inSnap.snapType = snapTypePreemptive
 			}
 			kvSS.status = fmt.Sprintf("kv batches: %d, log entries: %d", len(batches), len(logEntries))
+			if sstWriter != nil {
+				inSnap.sst, err = sstWriter.finish(
+					ctx, kvSS.ingestEngine, kvSS.ingestSettings, kvSS.ingestLimiter, snapUUID,
+				)
+				if err != nil {
+					return IncomingSnapshot{}, sendSnapshotError(stream, err)
+				}
+				if inSnap.sst != nil {
+					kvSS.status += fmt.Sprintf(", sst: %s", humanizeutil.IBytes(int64(len(inSnap.sst.data))))
+				}
+			}
+			if header.Compression != CompressionType_NONE && kvSS.metrics != nil {
+				kvSS.metrics.RangeSnapshotRcvdBytesCompressed.Inc(kvSS.bytesCompressed)
+				kvSS.metrics.RangeSnapshotRcvdBytesUncompressed.Inc(kvSS.bytesUncompressed)
+			}
 			return inSnap, nil
 		}
 	}
@@ -187,7 +240,7 @@ func (kvSS *kvBatchSnapshotStrategy) Send(
 			if err := kvSS.limiter.WaitN(ctx, 1); err != nil {
 				return err
 			}
-			if err := kvSS.sendBatch(stream, b); err != nil {
+			if err := kvSS.sendBatch(stream, header, b); err != nil {
 				return err
 			}
 			b = nil
@@ -201,7 +254,7 @@ func (kvSS *kvBatchSnapshotStrategy) Send(
 		if err := kvSS.limiter.WaitN(ctx, 1); err != nil {
 			return err
 		}
-		if err := kvSS.sendBatch(stream, b); err != nil {
+		if err := kvSS.sendBatch(stream, header, b); err != nil {
 			return err
 		}
 	}
@@ -282,21 +335,191 @@ func (kvSS *kvBatchSnapshotStrategy) Send(
 			}
 		}
 	}
+	for i := range logEntries {
+		var err error
+		if logEntries[i], err = kvSS.compress(header.Compression, logEntries[i]); err != nil {
+			return err
+		}
+	}
 	kvSS.status = fmt.Sprintf("kv pairs: %d, log entries: %d", n, len(logEntries))
+	if header.Compression != CompressionType_NONE {
+		kvSS.status += fmt.Sprintf(", compressed %s to %s (%s)",
+			humanizeutil.IBytes(kvSS.bytesUncompressed), humanizeutil.IBytes(kvSS.bytesCompressed),
+			header.Compression)
+	}
 	return stream.Send(&SnapshotRequest{LogEntries: logEntries})
 }
 
 func (kvSS *kvBatchSnapshotStrategy) sendBatch(
-	stream outgoingSnapshotStream, batch engine.Batch,
+	stream outgoingSnapshotStream, header SnapshotRequest_Header, batch engine.Batch,
 ) error {
-	repr := batch.Repr()
+	repr, err := kvSS.compress(header.Compression, batch.Repr())
 	batch.Close()
+	if err != nil {
+		return err
+	}
 	return stream.Send(&SnapshotRequest{KVBatch: repr})
 }
 
+// compress compresses a piece of snapshot data for sending, keeping track of
+// the compression ratio.
+func (kvSS *kvBatchSnapshotStrategy) compress(ct CompressionType, data []byte) ([]byte, error) {
+	if ct == CompressionType_NONE {
+		return data, nil
+	}
+	compressed, err := compress(ct, data)
+	if err != nil {
+		return nil, err
+	}
+	kvSS.bytesUncompressed += int64(len(data))
+	kvSS.bytesCompressed += int64(len(compressed))
+	return compressed, nil
+}
+
+// decompress reverses compress on a piece of received snapshot data.
+func (kvSS *kvBatchSnapshotStrategy) decompress(ct CompressionType, data []byte) ([]byte, error) {
+	if ct == CompressionType_NONE {
+		return data, nil
+	}
+	decompressed, err := decompress(ct, data)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid snapshot data")
+	}
+	kvSS.bytesCompressed += int64(len(data))
+	kvSS.bytesUncompressed += int64(len(decompressed))
+	return decompressed, nil
+}
+
 // Status implements the snapshotStrategy interface.
 func (kvSS *kvBatchSnapshotStrategy) Status() string { return kvSS.status }
 
+// snapshotIngestMinSize is the size above which the user data of a snapshot
+// is ingested into the engine as an SST, instead of being written through the
+// batch that applies the snapshot. A large batch holds up the other writes to
+// the engine while it's committed, and building it holds up the Raft
+// processing of the replica, whereas the SST is built while the snapshot is
+// received. Only snapshots for replicas which aren't initialized yet are
+// ingested, since initialized replicas have data to delete atomically with
+// the application of the snapshot.
+var snapshotIngestMinSize = settings.RegisterByteSizeSetting(
+	"kv.snapshot_ingest.min_size",
+	"minimum size of a snapshot for a new replica above which its data is ingested as an SST (0 disables ingestion)",
+	4<<20,
+)
+
+// snapshotSST is an SST file holding the user data of a received snapshot.
+type snapshotSST struct {
+	path string
+	// data is the contents of the file, which is kept in case the snapshot
+	// can't be ingested and has to be written through a batch after all.
+	data []byte
+	// ingested is set once the file has been ingested, which moves it into
+	// the engine.
+	ingested bool
+}
+
+// applyTo writes the contents of the SST to the given writer.
+func (sst *snapshotSST) applyTo(w engine.Writer) error {
+	reader := engine.MakeRocksDBSstFileReader()
+	defer reader.Close()
+	if err := reader.IngestExternalFile(sst.data); err != nil {
+		return err
+	}
+	return reader.Iterate(engine.NilKey, engine.MVCCKeyMax, func(kv engine.MVCCKeyValue) (bool, error) {
+		return false, w.Put(kv.Key, kv.Value)
+	})
+}
+
+// remove deletes the SST file, unless it was ingested.
+func (sst *snapshotSST) remove(ctx context.Context, eng engine.Engine) {
+	if sst.ingested {
+		return
+	}
+	if err := eng.DeleteFile(sst.path); err != nil {
+		log.Warningf(ctx, "failed to remove snapshot SST %s: %v", sst.path, err)
+	}
+}
+
+// snapshotSSTWriter splits the user data out of the batches of a snapshot as
+// they're received, and writes it into an SST.
+type snapshotSSTWriter struct {
+	span rditer.KeyRange
+	fw   engine.RocksDBSstFileWriter
+	n    int
+}
+
+func makeSnapshotSSTWriter(desc *roachpb.RangeDescriptor) (*snapshotSSTWriter, error) {
+	fw, err := engine.MakeRocksDBSstFileWriter()
+	if err != nil {
+		return nil, err
+	}
+	keyRanges := rditer.MakeAllKeyRanges(desc)
+	return &snapshotSSTWriter{span: keyRanges[len(keyRanges)-1], fw: fw}, nil
+}
+
+// split adds the user data in the given batch to the SST, and returns a batch
+// with the remaining data, i.e. the range-local metadata of the range. The
+// data of a snapshot is sent in key order, which the SST requires.
+func (w *snapshotSSTWriter) split(repr []byte) ([]byte, error) {
+	r, err := engine.NewRocksDBBatchReader(repr)
+	if err != nil {
+		return nil, err
+	}
+	var rest engine.RocksDBBatchBuilder
+	for r.Next() {
+		if r.BatchType() != engine.BatchTypeValue {
+			return nil, errors.Errorf("unexpected entry of type %d in snapshot batch", r.BatchType())
+		}
+		key, err := r.MVCCKey()
+		if err != nil {
+			return nil, err
+		}
+		if key.Less(w.span.Start) || !key.Less(w.span.End) {
+			rest.Put(key, r.Value())
+			continue
+		}
+		if err := w.fw.Add(engine.MVCCKeyValue{Key: key, Value: r.Value()}); err != nil {
+			return nil, err
+		}
+		w.n++
+	}
+	if err := r.Error(); err != nil {
+		return nil, err
+	}
+	return rest.Finish(), nil
+}
+
+// finish writes the SST into the auxiliary directory of the engine. It
+// returns nil if the snapshot has no user data.
+func (w *snapshotSSTWriter) finish(
+	ctx context.Context,
+	eng engine.Engine,
+	st *cluster.Settings,
+	limiter *rate.Limiter,
+	snapUUID uuid.UUID,
+) (*snapshotSST, error) {
+	if w.n == 0 {
+		return nil, nil
+	}
+	data, err := w.fw.Finish()
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(eng.GetAuxiliaryDir(), "snapshots")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, snapUUID.String()+".sst")
+	if err := writeFileSyncing(ctx, path, data, eng, 0600, st, limiter); err != nil {
+		return nil, err
+	}
+	return &snapshotSST{path: path, data: data}, nil
+}
+
+func (w *snapshotSSTWriter) close() {
+	w.fw.Close()
+}
+
 // reserveSnapshot throttles incoming snapshots. The returned closure is used
 // to cleanup the reservation and release its resources. A nil cleanup function
 // and a non-empty rejectionMessage indicates the reservation was declined.
@@ -413,6 +636,14 @@ func (s *Store) canApplySnapshotLocked(
 func (s *Store) receiveSnapshot(
 	ctx context.Context, header *SnapshotRequest_Header, stream incomingSnapshotStream,
 ) error {
+	// Snapshots sent to rebalance replicas are queued while the store is
+	// overloaded, before they are reserved.
+	class := classifySnapshot(header)
+	if err := s.admission.admit(ctx, s.stopper, class); err != nil {
+		return err
+	}
+	defer s.admission.done(class)
+
 	cleanup, rejectionMsg, err := s.reserveSnapshot(ctx, header)
 	if err != nil {
 		return err
@@ -430,7 +661,8 @@ func (s *Store) receiveSnapshot(
 	// We'll perform this check again later after receiving the rest of the
 	// snapshot data - this is purely an optimization to prevent downloading
 	// a snapshot that we know we won't be able to apply.
-	if _, err := s.canApplySnapshot(ctx, header.State.Desc); err != nil {
+	placeholder, err := s.canApplySnapshot(ctx, header.State.Desc)
+	if err != nil {
 		return sendSnapshotError(stream,
 			errors.Wrapf(err, "%s,r%d: cannot apply snapshot", s, header.State.Desc.RangeID),
 		)
@@ -442,7 +674,15 @@ func (s *Store) receiveSnapshot(
 	var ss snapshotStrategy
 	switch header.Strategy {
 	case SnapshotRequest_KV_BATCH:
-		ss = &kvBatchSnapshotStrategy{}
+		kvSS := &kvBatchSnapshotStrategy{metrics: s.metrics}
+		// A placeholder is only needed if the replica isn't initialized yet.
+		minSize := snapshotIngestMinSize.Get(&s.cfg.Settings.SV)
+		if placeholder != nil && minSize > 0 && header.RangeSize >= minSize {
+			kvSS.ingestEngine = s.engine
+			kvSS.ingestSettings = s.cfg.Settings
+			kvSS.ingestLimiter = s.limiters.BulkIOWriteRate
+		}
+		ss = kvSS
 	default:
 		return sendSnapshotError(stream,
 			errors.Errorf("%s,r%d: unknown snapshot strategy: %s",
@@ -461,6 +701,9 @@ func (s *Store) receiveSnapshot(
 	if err != nil {
 		return err
 	}
+	if inSnap.sst != nil {
+		defer inSnap.sst.remove(ctx, s.engine)
+	}
 	if err := s.processRaftSnapshotRequest(ctx, &header.RaftMessageRequest, inSnap); err != nil {
 		return sendSnapshotError(stream, errors.Wrap(err.GoError(), "failed to apply snapshot"))
 	}
@@ -528,6 +771,7 @@ func sendSnapshot(
 ) error {
 	start := timeutil.Now()
 	to := header.RaftMessageRequest.ToReplica
+	header.Compression = wireCompression(st)
 	if err := stream.Send(&SnapshotRequest{Header: &header}); err != nil {
 		return err
 	}
@@ -483,6 +483,44 @@ func TestStoreInitAndBootstrap(t *testing.T) {
 	}
 }
 
+// TestRunStoreRewrite verifies that an offline rewrite is run only until it
+// completes, and that its completion is recorded on the store.
+func TestRunStoreRewrite(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	eng := engine.NewInMem(roachpb.Attributes{}, 1<<20)
+	defer eng.Close()
+
+	if err := engine.MVCCPutProto(
+		ctx, eng, nil /* ms */, keys.RaftTombstoneIncorrectLegacyKey(5), hlc.Timestamp{}, nil, /* txn */
+		&roachpb.RaftTombstone{NextReplicaID: 3},
+	); err != nil {
+		t.Fatal(err)
+	}
+	if err := legacyTombstonesRewrite.verify(ctx, eng); !testutils.IsError(err, "r5 has a legacy tombstone") {
+		t.Fatalf("expected the verification to fail, got %v", err)
+	}
+
+	for i, expDone := range []bool{false, true} {
+		done, err := RunStoreRewrite(ctx, eng, legacyTombstonesRewrite)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if done != expDone {
+			t.Fatalf("%d: expected the rewrite to be already done: %t, got %t", i, expDone, done)
+		}
+	}
+	if done, err := IsStoreRewriteDone(ctx, eng, legacyTombstonesRewrite); err != nil || !done {
+		t.Fatalf("expected the rewrite to be marked as done, got %t, %v", done, err)
+	}
+	var tombstone roachpb.RaftTombstone
+	if ok, err := engine.MVCCGetProto(
+		ctx, eng, keys.RaftTombstoneKey(5), hlc.Timestamp{}, true /* consistent */, nil /* txn */, &tombstone,
+	); err != nil || !ok || tombstone.NextReplicaID != 3 {
+		t.Fatalf("expected the tombstone to be rewritten, got %t, %+v, %v", ok, tombstone, err)
+	}
+}
+
 // TestBootstrapOfNonEmptyStore verifies bootstrap failure if engine
 // is not empty.
 func TestBootstrapOfNonEmptyStore(t *testing.T) {
@@ -948,6 +986,129 @@ func TestStoreSend(t *testing.T) {
 	}
 }
 
+// TestStoreDiskFull verifies that a store whose disk is full rejects writes
+// that grow user data while still allowing deletes and writes to system data.
+func TestStoreDiskFull(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	store, _ := createTestStore(t, stopper)
+	ctx := context.Background()
+
+	userKey := append(keys.MakeTablePrefix(keys.MinUserDescID), 'a')
+	sysKey := append(keys.MakeTablePrefix(keys.DescriptorTableID), 'a')
+
+	store.updateDiskFull(ctx, roachpb.StoreCapacity{Capacity: 100, Available: 0})
+	if !store.IsDiskFull() {
+		t.Fatal("expected store to be read only")
+	}
+	pArgs := putArgs(userKey, []byte("value"))
+	if _, pErr := client.SendWrapped(ctx, store.TestSender(), &pArgs); !testutils.IsPError(pErr, "is read only") {
+		t.Fatalf("expected disk full error, got %v", pErr)
+	}
+	dArgs := deleteArgs(userKey)
+	if _, pErr := client.SendWrapped(ctx, store.TestSender(), &dArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+	pArgs = putArgs(sysKey, []byte("value"))
+	if _, pErr := client.SendWrapped(ctx, store.TestSender(), &pArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+	if n := store.metrics.DiskFullRejectedRequests.Count(); n != 1 {
+		t.Fatalf("expected 1 rejected request, got %d", n)
+	}
+
+	store.updateDiskFull(ctx, roachpb.StoreCapacity{Capacity: 100, Available: 50})
+	if store.IsDiskFull() {
+		t.Fatal("expected store to accept writes")
+	}
+	pArgs = putArgs(userKey, []byte("value"))
+	if _, pErr := client.SendWrapped(ctx, store.TestSender(), &pArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+}
+
+// TestStoreRangeDescriptorPush verifies that the responses of a range that
+// was split recently carry the descriptors of both sides of the split.
+func TestStoreRangeDescriptorPush(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	store, manual := createTestStore(t, stopper)
+	ctx := context.Background()
+
+	get := func() *roachpb.BatchResponse {
+		t.Helper()
+		var ba roachpb.BatchRequest
+		ba.RangeID = 1
+		gArgs := getArgs([]byte("a"))
+		ba.Add(&gArgs)
+		br, pErr := store.TestSender().Send(ctx, ba)
+		if pErr != nil {
+			t.Fatal(pErr)
+		}
+		return br
+	}
+
+	if br := get(); len(br.RangeDescriptors) != 0 {
+		t.Fatalf("expected no range descriptors before split, got %v", br.RangeDescriptors)
+	}
+
+	newRng := splitTestRange(store, roachpb.RKeyMin, roachpb.RKey("b"), t)
+	repl, err := store.GetReplica(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []roachpb.RangeDescriptor{*repl.Desc(), *newRng.Desc()}
+	if br := get(); !reflect.DeepEqual(br.RangeDescriptors, expected) {
+		t.Fatalf("expected range descriptors %v, got %v", expected, br.RangeDescriptors)
+	}
+
+	manual.Increment(rangeDescriptorPushDuration.Get(&store.ClusterSettings().SV).Nanoseconds() + 1)
+	if br := get(); len(br.RangeDescriptors) != 0 {
+		t.Fatalf("expected no range descriptors after expiration, got %v", br.RangeDescriptors)
+	}
+}
+
+// TestStoreEvalMemoryBudget verifies that a scan whose result exceeds the
+// store's command evaluation memory budget fails with an
+// EvalMemoryBudgetExceededError, and that the memory of completed batches is
+// released.
+func TestStoreEvalMemoryBudget(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	cfg := TestStoreConfig(nil)
+	cfg.EvalMemoryBudget = 64 << 10 // 64K
+	store := createTestStoreWithConfig(t, stopper, &cfg)
+	ctx := context.Background()
+
+	value := bytes.Repeat([]byte("v"), 1<<10)
+	for i := 0; i < 100; i++ {
+		pArgs := putArgs(roachpb.Key(fmt.Sprintf("a%03d", i)), value)
+		if _, pErr := client.SendWrapped(ctx, store.TestSender(), &pArgs); pErr != nil {
+			t.Fatal(pErr)
+		}
+	}
+
+	// A scan of a handful of keys fits within the budget.
+	sArgs := scanArgs([]byte("a000"), []byte("a010"))
+	if _, pErr := client.SendWrapped(ctx, store.TestSender(), &sArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	// A scan of all keys does not.
+	sArgs = scanArgs([]byte("a"), []byte("b"))
+	_, pErr := client.SendWrapped(ctx, store.TestSender(), &sArgs)
+	if _, ok := pErr.GetDetail().(*roachpb.EvalMemoryBudgetExceededError); !ok {
+		t.Fatalf("expected EvalMemoryBudgetExceededError, got %v", pErr)
+	}
+
+	if cur := store.Metrics().EvalMemoryCurBytes.Value(); cur != 0 {
+		t.Fatalf("expected all evaluation memory to be released, found %d bytes", cur)
+	}
+}
+
 // TestStoreObservedTimestamp verifies that execution of a transactional
 // command on a Store always returns a timestamp observation, either per the
 // error's or the response's transaction, as well as an originating NodeID.
@@ -57,10 +57,12 @@ func ShouldPushImmediately(req *roachpb.PushTxnRequest) bool {
 
 // isPushed returns whether the PushTxn request has already been
 // fulfilled by the current transaction state. This may be true
-// for transactions with pushed timestamps.
+// for transactions with pushed timestamps. A STAGING transaction
+// has not been pushed; it has to be finalized or recovered first.
 func isPushed(req *roachpb.PushTxnRequest, txn *roachpb.Transaction) bool {
-	return (txn.Status != roachpb.PENDING ||
-		(req.PushType == roachpb.PUSH_TIMESTAMP && req.PushTo.Less(txn.Timestamp)))
+	return (txn.Status.IsFinalized() ||
+		(txn.Status == roachpb.PENDING &&
+			req.PushType == roachpb.PUSH_TIMESTAMP && req.PushTo.Less(txn.Timestamp)))
 }
 
 // TxnExpiration computes the timestamp after which the transaction will be
@@ -65,6 +65,12 @@ export function getEventDescription(e: Event$Properties): string {
     return `Zone Config Changed: User ${info.User} set the zone config for ${info.Target} to ${info.Config}`;
     case eventTypes.REMOVE_ZONE_CONFIG:
       return `Zone Config Removed: User ${info.User} removed the zone config for ${info.Target}`;
+    case eventTypes.CLUSTER_PROVISIONED:
+      const settingsText = `${(info.Settings || []).length} cluster settings`;
+      if (info.BootstrapSQL) {
+        return `Cluster Provisioned: User ${info.User} applied ${settingsText} and executed ${info.BootstrapSQL}`;
+      }
+      return `Cluster Provisioned: User ${info.User} applied ${settingsText}`;
     default:
       return `Unknown Event Type: ${e.event_type}, content: ${JSON.stringify(info, null, 2)}`;
   }
@@ -84,6 +90,8 @@ export interface EventInfo {
   Value?: string;
   Target?: string;
   Config?: string;
+  Settings?: string[];
+  BootstrapSQL?: string;
   // The following are three names for the same key (it was renamed twice).
   // All ar included for backwards compatibility.
   DroppedTables?: string[];
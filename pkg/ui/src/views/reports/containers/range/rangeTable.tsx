@@ -59,6 +59,8 @@ const rangeTableDisplayList: RangeTableRow[] = [
   { variable: "logSize", display: "Log Size", compareToLeader: false },
   { variable: "leaseHolderQPS", display: "Lease Holder QPS", compareToLeader: false },
   { variable: "keysWrittenPS", display: "Average Keys Written Per Second", compareToLeader: false },
+  { variable: "loadSplitQPS", display: "Load Split QPS", compareToLeader: false },
+  { variable: "loadSplitKey", display: "Load Split Key", compareToLeader: false },
   { variable: "approxProposalQuota", display: "Approx Proposal Quota", compareToLeader: false },
   { variable: "pendingCommands", display: "Pending Commands", compareToLeader: false },
   { variable: "droppedCommands", display: "Dropped Commands", compareToLeader: false },
@@ -480,6 +482,8 @@ export default class RangeTable extends React.Component<RangeTableProps, {}> {
         logSize: this.contentBytes(FixLong(info.state.raft_log_size)),
         leaseHolderQPS: leaseHolder ? this.createContent(info.stats.queries_per_second.toFixed(4)) : rangeTableEmptyContent,
         keysWrittenPS: this.createContent(info.stats.writes_per_second.toFixed(4)),
+        loadSplitQPS: leaseHolder ? this.createContent(info.stats.load_split_queries_per_second.toFixed(4)) : rangeTableEmptyContent,
+        loadSplitKey: leaseHolder && info.stats.load_split_key ? this.createContent(info.stats.load_split_key) : rangeTableEmptyContent,
         approxProposalQuota: raftLeader ? this.createContent(FixLong(info.state.approximate_proposal_quota)) : rangeTableEmptyContent,
         pendingCommands: this.createContent(FixLong(info.state.num_pending)),
         droppedCommands: this.createContent(
@@ -0,0 +1,221 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package cron parses cron expressions and computes the times at which they
+// fire.
+//
+// An expression has the five standard fields:
+//
+//   minute (0-59) hour (0-23) day-of-month (1-31) month (1-12) day-of-week (0-6)
+//
+// Each field is a comma-separated list of values, ranges (a-b) or *, each
+// optionally followed by a step (/n). Months and days of the week can also be
+// given by their three-letter English names, and 7 is accepted for Sunday. As
+// in Vixie cron, if both the day of the month and the day of the week are
+// restricted, a day matches if either of them does. The @yearly, @annually,
+// @monthly, @weekly, @daily, @midnight and @hourly shorthands are supported.
+package cron
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Schedule is a parsed cron expression.
+type Schedule struct {
+	// Each field is a bitmask of the values that match it.
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar are set if the day of the month and the day of the
+	// week fields start with *, in which case they don't restrict the days
+	// matched by the other one.
+	domStar, dowStar bool
+}
+
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// The day of the week accepts 7 for Sunday, which is folded into 0.
+	dowField = field{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var shorthands = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses the given cron expression.
+func Parse(expr string) (*Schedule, error) {
+	s := strings.TrimSpace(expr)
+	if strings.HasPrefix(s, "@") {
+		var ok bool
+		if s, ok = shorthands[strings.ToLower(s)]; !ok {
+			return nil, errors.Errorf("unknown cron shorthand %q", expr)
+		}
+	}
+	fields := strings.Fields(s)
+	if len(fields) != 5 {
+		return nil, errors.Errorf(
+			"cron expression %q must have 5 fields, found %d", expr, len(fields))
+	}
+
+	var sched Schedule
+	var err error
+	if sched.minute, err = minuteField.parse(fields[0]); err != nil {
+		return nil, err
+	}
+	if sched.hour, err = hourField.parse(fields[1]); err != nil {
+		return nil, err
+	}
+	if sched.dom, err = domField.parse(fields[2]); err != nil {
+		return nil, err
+	}
+	if sched.month, err = monthField.parse(fields[3]); err != nil {
+		return nil, err
+	}
+	if sched.dow, err = dowField.parse(fields[4]); err != nil {
+		return nil, err
+	}
+	if sched.dow&(1<<7) != 0 {
+		sched.dow = sched.dow&^(1<<7) | 1
+	}
+	sched.domStar = strings.HasPrefix(fields[2], "*")
+	sched.dowStar = strings.HasPrefix(fields[4], "*")
+	return &sched, nil
+}
+
+// parse returns the bitmask of the values matched by the given field.
+func (f field) parse(s string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(s, ",") {
+		rng, step := part, 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			var err error
+			rng = part[:i]
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+				return 0, errors.Errorf("invalid step in %s field: %q", f.name, part)
+			}
+		}
+
+		var lo, hi int
+		switch {
+		case rng == "*":
+			lo, hi = f.min, f.max
+		case strings.IndexByte(rng, '-') > 0:
+			i := strings.IndexByte(rng, '-')
+			var err error
+			if lo, err = f.value(rng[:i]); err != nil {
+				return 0, err
+			}
+			if hi, err = f.value(rng[i+1:]); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, errors.Errorf("invalid range in %s field: %q", f.name, part)
+			}
+		default:
+			var err error
+			if lo, err = f.value(rng); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if step > 1 {
+				// A single value with a step, like 5/15, is a range to the max.
+				hi = f.max
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// value parses a single value of the field.
+func (f field) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, errors.Errorf("invalid value in %s field: %q", f.name, s)
+	}
+	if v < f.min || v > f.max {
+		return 0, errors.Errorf(
+			"value %d out of range [%d, %d] in %s field", v, f.min, f.max, f.name)
+	}
+	return v, nil
+}
+
+// maxSearchYears bounds the search for the next matching time, so that
+// expressions that never match, like 0 0 30 2 *, don't loop forever.
+const maxSearchYears = 5
+
+// Next returns the first time strictly after t, in t's location and at the
+// start of a minute, that matches the schedule. It returns the zero time if
+// the schedule never matches.
+func (s *Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	end := t.AddDate(maxSearchYears, 0, 0)
+	for t.Before(end) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) matchDay(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,110 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+)
+
+func TestParseErrors(t *testing.T) {
+	testCases := []struct {
+		expr string
+		err  string
+	}{
+		{``, `must have 5 fields, found 0`},
+		{`* * * *`, `must have 5 fields, found 4`},
+		{`* * * * * *`, `must have 5 fields, found 6`},
+		{`@sometimes`, `unknown cron shorthand`},
+		{`60 * * * *`, `value 60 out of range \[0, 59\] in minute field`},
+		{`* 24 * * *`, `value 24 out of range \[0, 23\] in hour field`},
+		{`* * 0 * *`, `value 0 out of range \[1, 31\] in day of month field`},
+		{`* * * 13 * `, `value 13 out of range \[1, 12\] in month field`},
+		{`* * * * 8`, `value 8 out of range \[0, 7\] in day of week field`},
+		{`-1 * * * *`, `value -1 out of range \[0, 59\] in minute field`},
+		{`x * * * *`, `invalid value in minute field: "x"`},
+		{`*/0 * * * *`, `invalid step in minute field: "\*/0"`},
+		{`5-1 * * * *`, `invalid range in minute field: "5-1"`},
+		{`1- * * * *`, `invalid value in minute field: ""`},
+		{`* * * foo *`, `invalid value in month field: "foo"`},
+	}
+	for _, tc := range testCases {
+		if _, err := Parse(tc.expr); !testutils.IsError(err, tc.err) {
+			t.Errorf("%q: expected error %q, got %v", tc.expr, tc.err, err)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	// 2018-06-15 was a Friday.
+	from := time.Date(2018, 6, 15, 10, 30, 45, 0, time.UTC)
+	testCases := []struct {
+		expr     string
+		from     time.Time
+		expected string
+	}{
+		{`* * * * *`, from, `2018-06-15 10:31`},
+		{`30 * * * *`, from, `2018-06-15 11:30`},
+		{`*/15 * * * *`, from, `2018-06-15 10:45`},
+		{`5/20 * * * *`, from, `2018-06-15 10:45`},
+		{`0 0 * * *`, from, `2018-06-16 00:00`},
+		{`0 9-17/4 * * *`, from, `2018-06-15 13:00`},
+		{`0,20,40 11 * * *`, from, `2018-06-15 11:00`},
+		{`0 0 1 * *`, from, `2018-07-01 00:00`},
+		{`0 0 31 * *`, from, `2018-07-31 00:00`},
+		{`0 0 29 2 *`, from, `2020-02-29 00:00`},
+		{`0 0 * * mon`, from, `2018-06-18 00:00`},
+		{`0 0 * * 7`, from, `2018-06-17 00:00`},
+		{`0 0 * * 0`, from, `2018-06-17 00:00`},
+		{`0 0 * dec sun`, from, `2018-12-02 00:00`},
+		// If both the day of the month and of the week are restricted, either
+		// can match.
+		{`0 0 20 * 1`, from, `2018-06-18 00:00`},
+		{`0 0 16 * 1`, from, `2018-06-16 00:00`},
+		// If only one of them is, it must match.
+		{`0 0 */10 * *`, from, `2018-06-21 00:00`},
+		{`0 0 * * 1-5`, from, `2018-06-18 00:00`},
+		{`@hourly`, from, `2018-06-15 11:00`},
+		{`@daily`, from, `2018-06-16 00:00`},
+		{`@weekly`, from, `2018-06-17 00:00`},
+		{`@monthly`, from, `2018-07-01 00:00`},
+		{`@yearly`, from, `2019-01-01 00:00`},
+		// The next time is strictly after the given one.
+		{`30 10 * * *`, time.Date(2018, 6, 15, 10, 30, 0, 0, time.UTC), `2018-06-16 10:30`},
+		{`59 23 31 12 *`, time.Date(2018, 12, 31, 23, 58, 0, 0, time.UTC), `2018-12-31 23:59`},
+		{`0 0 1 1 *`, time.Date(2018, 12, 31, 23, 59, 0, 0, time.UTC), `2019-01-01 00:00`},
+	}
+	for _, tc := range testCases {
+		s, err := Parse(tc.expr)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.expr, err)
+		}
+		if next := s.Next(tc.from).Format("2006-01-02 15:04"); next != tc.expected {
+			t.Errorf("%q: expected %s, got %s", tc.expr, tc.expected, next)
+		}
+	}
+}
+
+func TestNextNever(t *testing.T) {
+	s, err := Parse(`0 0 30 2 *`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next := s.Next(time.Date(2018, 6, 15, 0, 0, 0, 0, time.UTC)); !next.IsZero() {
+		t.Errorf("expected no next time, got %s", next)
+	}
+}
@@ -75,6 +75,9 @@ const (
 	jsonEmptyArray    = jsonInvertedIndex + 1
 	jsonEmptyObject   = jsonEmptyArray + 1
 
+	arrayKeyMarker           = jsonEmptyObject + 1
+	arrayKeyDescendingMarker = arrayKeyMarker + 1
+
 	// IntMin is chosen such that the range of int tags does not overlap the
 	// ascii character set that is frequently used in testing.
 	IntMin      = 0x80 // 128
@@ -100,6 +103,19 @@ const (
 	encodedNullDesc     = 0xff
 )
 
+const (
+	// The elements of an array key are followed by a terminator which sorts
+	// before (or after, when encoded descendingly) any element, so that an
+	// array sorts before all the arrays it is a prefix of.
+	arrayKeyTerminator           byte = 0x00
+	arrayKeyDescendingTerminator byte = 0xff
+	// NULL elements can't use encodedNull and encodedNullDesc as those are the
+	// array terminators. They use the bytes of encodedNotNull and
+	// encodedNotNullDesc instead, which are never present in stored keys.
+	ascendingNullWithinArrayKey  byte = 0x01
+	descendingNullWithinArrayKey byte = 0xfe
+)
+
 const (
 	// EncodedDurationMaxLen is the largest number of bytes used when encoding a
 	// Duration.
@@ -733,6 +749,129 @@ func EncodeJSONEmptyObject(b []byte) []byte {
 	return append(b, escape, escapedTerm, jsonEmptyObject)
 }
 
+// EncodeArrayKeyAscending appends the marker of an ascendingly encoded array
+// key to b. It must be followed by the key encodings of the elements, in the
+// same direction, and by EncodeArrayKeyTerminator.
+func EncodeArrayKeyAscending(b []byte) []byte {
+	return append(b, arrayKeyMarker)
+}
+
+// EncodeArrayKeyDescending is the descending version of
+// EncodeArrayKeyAscending.
+func EncodeArrayKeyDescending(b []byte) []byte {
+	return append(b, arrayKeyDescendingMarker)
+}
+
+// EncodeArrayKeyTerminator appends the terminator of an array key encoded in
+// the given direction to b.
+func EncodeArrayKeyTerminator(b []byte, dir Direction) []byte {
+	if dir == Ascending {
+		return append(b, arrayKeyTerminator)
+	}
+	return append(b, arrayKeyDescendingTerminator)
+}
+
+// EncodeNullWithinArrayKey appends the encoding of a NULL element of an array
+// key encoded in the given direction to b.
+func EncodeNullWithinArrayKey(b []byte, dir Direction) []byte {
+	if dir == Ascending {
+		return append(b, ascendingNullWithinArrayKey)
+	}
+	return append(b, descendingNullWithinArrayKey)
+}
+
+// ValidateAndConsumeArrayKeyMarker checks that b starts with the marker of an
+// array key encoded in the given direction, and returns the remaining buffer.
+func ValidateAndConsumeArrayKeyMarker(b []byte, dir Direction) ([]byte, error) {
+	marker := arrayKeyMarker
+	if dir == Descending {
+		marker = arrayKeyDescendingMarker
+	}
+	if len(b) == 0 || b[0] != marker {
+		return nil, errors.Errorf("did not find array key marker %#x in buffer %#x", marker, b)
+	}
+	return b[1:], nil
+}
+
+// IsArrayKeyDone returns whether b starts with the terminator of an array key
+// encoded in the given direction, in which case there are no more elements.
+func IsArrayKeyDone(b []byte, dir Direction) bool {
+	if len(b) == 0 {
+		return false
+	}
+	if dir == Ascending {
+		return b[0] == arrayKeyTerminator
+	}
+	return b[0] == arrayKeyDescendingTerminator
+}
+
+// IsNextByteArrayEncodedNull returns whether the next element of an array key
+// encoded in the given direction is NULL.
+func IsNextByteArrayEncodedNull(b []byte, dir Direction) bool {
+	if len(b) == 0 {
+		return false
+	}
+	if dir == Ascending {
+		return b[0] == ascendingNullWithinArrayKey
+	}
+	return b[0] == descendingNullWithinArrayKey
+}
+
+// getArrayKeyLength returns the length of the array key at the start of b,
+// including its marker and terminator.
+func getArrayKeyLength(b []byte, dir Direction) (int, error) {
+	n := 1
+	for {
+		if n >= len(b) {
+			return 0, errors.Errorf("did not find array key terminator in buffer %#x", b)
+		}
+		if IsArrayKeyDone(b[n:], dir) {
+			return n + 1, nil
+		}
+		if IsNextByteArrayEncodedNull(b[n:], dir) {
+			n++
+			continue
+		}
+		l, err := PeekLength(b[n:])
+		if err != nil {
+			return 0, err
+		}
+		n += l
+	}
+}
+
+// prettyPrintArrayKey returns a string representation of the array key at the
+// start of b, along with the remaining buffer.
+func prettyPrintArrayKey(dir Direction, b []byte) ([]byte, string, error) {
+	b, err := ValidateAndConsumeArrayKeyMarker(b, dir)
+	if err != nil {
+		return b, "", err
+	}
+	var buf bytes.Buffer
+	buf.WriteString("ARRAY[")
+	for i := 0; !IsArrayKeyDone(b, dir); i++ {
+		if len(b) == 0 {
+			return b, "", errors.Errorf("did not find array key terminator")
+		}
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if IsNextByteArrayEncodedNull(b, dir) {
+			buf.WriteString("NULL")
+			b = b[1:]
+			continue
+		}
+		var s string
+		b, s, err = prettyPrintFirstValue(dir, b)
+		if err != nil {
+			return b, "", err
+		}
+		buf.WriteString(s)
+	}
+	buf.WriteByte(']')
+	return b[1:], buf.String(), nil
+}
+
 // EncodeStringDescending is the descending version of EncodeStringAscending.
 func EncodeStringDescending(b []byte, s string) []byte {
 	if len(s) == 0 {
@@ -1145,6 +1284,10 @@ func PeekLength(b []byte) (int, error) {
 		return getBytesLength(b, ascendingEscapes)
 	case jsonInvertedIndex:
 		return getJSONInvertedIndexKeyLength(b)
+	case arrayKeyMarker:
+		return getArrayKeyLength(b, Ascending)
+	case arrayKeyDescendingMarker:
+		return getArrayKeyLength(b, Descending)
 	case bytesDescMarker:
 		return getBytesLength(b, descendingEscapes)
 	case timeMarker:
@@ -1363,6 +1506,10 @@ func prettyPrintFirstValue(dir Direction, b []byte) ([]byte, string, error) {
 				return b[1:], "[]", nil
 			case jsonEmptyObject:
 				return b[1:], "{}", nil
+			case arrayKeyMarker:
+				return prettyPrintArrayKey(Ascending, b)
+			case arrayKeyDescendingMarker:
+				return prettyPrintArrayKey(Descending, b)
 			}
 		}
 		// This shouldn't ever happen, but if it does, return an empty slice.